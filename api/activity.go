@@ -63,6 +63,8 @@ const (
 
 	// ActivityDatabaseRecoveryPITRDone is the type for performing PITR on the database successfully.
 	ActivityDatabaseRecoveryPITRDone ActivityType = "bb.database.recovery.pitr.done"
+	// ActivityDatabaseBackupVerificationFailed is the type for failing to verify the database backup by a test restore.
+	ActivityDatabaseBackupVerificationFailed ActivityType = "bb.database.backup.verification.failed"
 )
 
 // ActivityLevel is the level of activities.
@@ -192,6 +194,15 @@ type ActivityProjectDatabaseTransferPayload struct {
 	DatabaseName string `json:"databaseName,omitempty"`
 }
 
+// ActivityDatabaseBackupVerificationFailedPayload is the API message payloads for failed backup verifications.
+type ActivityDatabaseBackupVerificationFailedPayload struct {
+	DatabaseID int `json:"databaseId,omitempty"`
+	BackupID   int `json:"backupId,omitempty"`
+	// Used by activity table to display info without paying the join cost
+	DatabaseName string `json:"databaseName,omitempty"`
+	BackupName   string `json:"backupName,omitempty"`
+}
+
 // ActivitySQLEditorQueryPayload is the API message payloads for the executed query info.
 type ActivitySQLEditorQueryPayload struct {
 	// Used by activity table to display info without paying the join cost
@@ -16,6 +16,8 @@ const (
 	AnomalyDatabaseBackupPolicyViolation AnomalyType = "bb.anomaly.database.backup.policy-violation"
 	// AnomalyDatabaseBackupMissing is the anomaly type for missing backups.
 	AnomalyDatabaseBackupMissing AnomalyType = "bb.anomaly.database.backup.missing"
+	// AnomalyDatabaseBackupVerificationFailed is the anomaly type for backups failing the test restore.
+	AnomalyDatabaseBackupVerificationFailed AnomalyType = "bb.anomaly.database.backup.verification-failed"
 	// AnomalyDatabaseConnection is the anomaly type for database connections.
 	AnomalyDatabaseConnection AnomalyType = "bb.anomaly.database.connection"
 	// AnomalyDatabaseSchemaDrift is the anomaly type for database schema drifts.
@@ -41,6 +43,8 @@ func AnomalySeverityFromType(anomalyType AnomalyType) AnomalySeverity {
 		return AnomalySeverityMedium
 	case AnomalyDatabaseBackupMissing:
 		return AnomalySeverityHigh
+	case AnomalyDatabaseBackupVerificationFailed:
+		return AnomalySeverityHigh
 	case AnomalyInstanceConnection:
 	case AnomalyInstanceMigrationSchema:
 	case AnomalyDatabaseConnection:
@@ -61,6 +65,8 @@ type AnomalyDatabaseBackupPolicyViolationPayload struct {
 	EnvironmentID          int                      `json:"environmentId,omitempty"`
 	ExpectedBackupSchedule BackupPlanPolicySchedule `json:"expectedSchedule,omitempty"`
 	ActualBackupSchedule   BackupPlanPolicySchedule `json:"actualSchedule,omitempty"`
+	// MissingCronScheduleList is the cron backup schedules required by the policy but missing in the backup setting.
+	MissingCronScheduleList []BackupSchedule `json:"missingCronScheduleList,omitempty"`
 }
 
 // AnomalyDatabaseBackupMissingPayload is the API message for missing backup payloads.
@@ -70,6 +76,14 @@ type AnomalyDatabaseBackupMissingPayload struct {
 	LastBackupTs int64 `json:"lastBackupTs,omitempty"`
 }
 
+// AnomalyDatabaseBackupVerificationFailedPayload is the API message for backup verification failure payloads.
+type AnomalyDatabaseBackupVerificationFailedPayload struct {
+	BackupID   int    `json:"backupId,omitempty"`
+	BackupName string `json:"backupName,omitempty"`
+	// Verification failure detail
+	Detail string `json:"detail,omitempty"`
+}
+
 // AnomalyDatabaseConnectionPayload is the API message for database connection payloads.
 type AnomalyDatabaseConnectionPayload struct {
 	// Connection failure detail
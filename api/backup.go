@@ -3,7 +3,11 @@ package api
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/pkg/errors"
+	"github.com/robfig/cron/v3"
 	"go.uber.org/zap/zapcore"
 )
 
@@ -42,12 +46,38 @@ type BackupStorageBackend string
 const (
 	// BackupStorageBackendLocal is the local storage backend for a backup.
 	BackupStorageBackendLocal BackupStorageBackend = "LOCAL"
-	// BackupStorageBackendS3 is the AWS S3 storage backend for a backup. Not used yet.
+	// BackupStorageBackendS3 is the AWS S3 storage backend for a backup, including S3 compatible services such as MinIO.
 	BackupStorageBackendS3 BackupStorageBackend = "S3"
-	// BackupStorageBackendGCS is the Google Cloud Storage (GCS) storage backend for a backup. Not used yet.
+	// BackupStorageBackendGCS is the Google Cloud Storage (GCS) storage backend for a backup.
 	BackupStorageBackendGCS BackupStorageBackend = "GCS"
 	// BackupStorageBackendOSS is the AliCloud Object Storage Service (OSS) storage backend for a backup. Not used yet.
 	BackupStorageBackendOSS BackupStorageBackend = "OSS"
+	// BackupStorageBackendAzure is the Azure Blob Storage backend for a backup.
+	BackupStorageBackendAzure BackupStorageBackend = "AZURE"
+	// BackupStorageBackendFileSystem is the file system storage backend for a backup, e.g., a mounted NFS share.
+	BackupStorageBackendFileSystem BackupStorageBackend = "FILESYSTEM"
+)
+
+// BackupCompression is the compression algorithm of a backup artifact.
+type BackupCompression string
+
+const (
+	// BackupCompressionNone means the backup artifact is not compressed.
+	BackupCompressionNone BackupCompression = "NONE"
+	// BackupCompressionGzip is the gzip compression.
+	BackupCompressionGzip BackupCompression = "GZIP"
+	// BackupCompressionZstd is the zstd compression.
+	BackupCompressionZstd BackupCompression = "ZSTD"
+)
+
+// BackupEncryption is the encryption algorithm of a backup artifact.
+type BackupEncryption string
+
+const (
+	// BackupEncryptionNone means the backup artifact is not encrypted.
+	BackupEncryptionNone BackupEncryption = "NONE"
+	// BackupEncryptionAES256GCM is the client-side AES-256-GCM encryption.
+	BackupEncryptionAES256GCM BackupEncryption = "AES_256_GCM"
 )
 
 // BinlogInfo is the binlog coordination for MySQL.
@@ -61,6 +91,15 @@ func (b BinlogInfo) IsEmpty() bool {
 	return b == BinlogInfo{}
 }
 
+// WALInfo is the base backup and WAL coordination for PostgreSQL.
+type WALInfo struct {
+	// BaseBackupPath is the path of the base backup of the whole instance, relative to the data directory or the bucket.
+	BaseBackupPath string `json:"baseBackupPath"`
+	// StartWALFileName is the WAL segment at the beginning of the base backup.
+	// The recovery replays the archived WAL from this segment.
+	StartWALFileName string `json:"startWALFileName"`
+}
+
 // BackupPayload contains backup related database specific info, it differs for different database types.
 // It is encoded in JSON and stored in the backup table.
 type BackupPayload struct {
@@ -69,6 +108,19 @@ type BackupPayload struct {
 	// It is recorded within the same transaction as the dump so that the binlog position is consistent with the dump.
 	// Please refer to https://github.com/bytebase/bytebase/blob/main/docs/design/pitr-mysql.md#full-backup for details.
 	BinlogInfo BinlogInfo `json:"binlogInfo"`
+
+	// PostgreSQL related fields
+	// WALInfo is recorded when a base backup of the instance is taken along with the database backup for PITR.
+	WALInfo *WALInfo `json:"walInfo,omitempty"`
+
+	// Schedule is the cron expression of the backup schedule taking the automatic backup.
+	// It is empty for the backups taken by the hour and day of week schedule of the backup setting.
+	Schedule string `json:"schedule,omitempty"`
+
+	// Compression and Encryption are the codec of the backup artifact.
+	// They are empty for the plain backups taken before the codec is introduced.
+	Compression BackupCompression `json:"compression,omitempty"`
+	Encryption  BackupEncryption  `json:"encryption,omitempty"`
 }
 
 // Backup is the API message for a backup.
@@ -198,6 +250,118 @@ type BackupSetting struct {
 	RetentionPeriodTs int `jsonapi:"attr,retentionPeriodTs"`
 	// HookURL is the callback url to be requested (using HTTP GET) after a successful backup.
 	HookURL string `jsonapi:"attr,hookUrl"`
+	// ScheduleList is the additional backup schedules in cron expressions, each with its own retention period.
+	ScheduleList []BackupSchedule `jsonapi:"attr,scheduleList"`
+}
+
+// GetRetentionPeriodTs returns the retention period of the backup taken by the schedule.
+// The retention period of the backup setting is used if the schedule is not found.
+func (bs *BackupSetting) GetRetentionPeriodTs(schedule string) int {
+	if schedule != "" {
+		for _, s := range bs.ScheduleList {
+			if s.Cron == schedule {
+				return s.RetentionPeriodTs
+			}
+		}
+	}
+	return bs.RetentionPeriodTs
+}
+
+// BackupSchedule is a backup schedule in a cron expression.
+type BackupSchedule struct {
+	// Cron is the standard cron expression in UTC, e.g., "0 * * * *" for hourly backups and "0 0 1 * *" for monthly backups.
+	Cron string `json:"cron" jsonapi:"attr,cron"`
+	// RetentionPeriodTs is the period that backup data taken by the schedule is kept.
+	// 0 means unset and we do not delete data.
+	RetentionPeriodTs int `json:"retentionPeriodTs" jsonapi:"attr,retentionPeriodTs"`
+}
+
+// ParseBackupScheduleCron parses the cron expression of a backup schedule.
+func ParseBackupScheduleCron(expr string) (cron.Schedule, error) {
+	schedule, err := cron.ParseStandard(strings.TrimSpace(expr))
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid backup schedule cron expression %q", expr)
+	}
+	return schedule, nil
+}
+
+// ValidateBackupScheduleList validates the cron expressions and retention periods of the backup schedules.
+func ValidateBackupScheduleList(scheduleList []BackupSchedule) error {
+	seen := make(map[string]bool)
+	for _, schedule := range scheduleList {
+		if _, err := ParseBackupScheduleCron(schedule.Cron); err != nil {
+			return err
+		}
+		if schedule.RetentionPeriodTs < 0 {
+			return errors.Errorf("invalid retention period %d of backup schedule %q", schedule.RetentionPeriodTs, schedule.Cron)
+		}
+		if seen[schedule.Cron] {
+			return errors.Errorf("duplicate backup schedule %q", schedule.Cron)
+		}
+		seen[schedule.Cron] = true
+	}
+	return nil
+}
+
+const (
+	// We sample the fire times of the cron expression from a fixed time to find the max interval between two backups.
+	// The sampling window covers more than a year so that the yearly schedules are detected as well.
+	backupScheduleSampleWindow   = 400 * 24 * time.Hour
+	backupScheduleSampleMaxCount = 2000
+)
+
+// GetBackupScheduleFrequency returns the backup plan policy schedule satisfied by the cron expression.
+func GetBackupScheduleFrequency(expr string) (BackupPlanPolicySchedule, error) {
+	schedule, err := ParseBackupScheduleCron(expr)
+	if err != nil {
+		return BackupPlanPolicyScheduleUnset, err
+	}
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(backupScheduleSampleWindow)
+	var maxInterval time.Duration
+	prev := schedule.Next(start)
+	count := 0
+	for count < backupScheduleSampleMaxCount {
+		next := schedule.Next(prev)
+		if next.IsZero() || next.After(end) {
+			break
+		}
+		if interval := next.Sub(prev); interval > maxInterval {
+			maxInterval = interval
+		}
+		prev = next
+		count++
+	}
+	if count == 0 {
+		// Fired at most once in the sampling window.
+		return BackupPlanPolicyScheduleUnset, nil
+	}
+	return GetBackupPlanPolicyScheduleByInterval(maxInterval), nil
+}
+
+// GetBackupSettingSchedule returns the most frequent schedule of the backup setting with the hour and day of week schedule,
+// and the additional cron schedules.
+func GetBackupSettingSchedule(enabled bool, hour, dayOfWeek int, scheduleList []BackupSchedule) BackupPlanPolicySchedule {
+	if !enabled {
+		return BackupPlanPolicyScheduleUnset
+	}
+	ret := BackupPlanPolicyScheduleUnset
+	if hour != -1 {
+		ret = BackupPlanPolicyScheduleWeekly
+		if dayOfWeek == -1 {
+			ret = BackupPlanPolicyScheduleDaily
+		}
+	}
+	for _, s := range scheduleList {
+		frequency, err := GetBackupScheduleFrequency(s.Cron)
+		if err != nil {
+			continue
+		}
+		if frequency != BackupPlanPolicyScheduleUnset && frequency.Satisfies(ret) {
+			ret = frequency
+		}
+	}
+	return ret
 }
 
 // BackupSettingFind is the message to get a backup settings.
@@ -224,11 +388,12 @@ type BackupSettingUpsert struct {
 	EnvironmentID int
 
 	// Domain specific fields
-	Enabled           bool   `jsonapi:"attr,enabled"`
-	Hour              int    `jsonapi:"attr,hour"`
-	DayOfWeek         int    `jsonapi:"attr,dayOfWeek"`
-	RetentionPeriodTs int    `jsonapi:"attr,retentionPeriodTs"`
-	HookURL           string `jsonapi:"attr,hookUrl"`
+	Enabled           bool             `jsonapi:"attr,enabled"`
+	Hour              int              `jsonapi:"attr,hour"`
+	DayOfWeek         int              `jsonapi:"attr,dayOfWeek"`
+	RetentionPeriodTs int              `jsonapi:"attr,retentionPeriodTs"`
+	HookURL           string           `jsonapi:"attr,hookUrl"`
+	ScheduleList      []BackupSchedule `jsonapi:"attr,scheduleList"`
 }
 
 // BackupSettingsMatch is the message to find backup settings matching the conditions.
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetBackupScheduleFrequency(t *testing.T) {
+	tests := []struct {
+		cron    string
+		want    BackupPlanPolicySchedule
+		errPart string
+	}{
+		{"15 * * * *", BackupPlanPolicyScheduleHourly, ""},
+		{"*/5 * * * *", BackupPlanPolicyScheduleHourly, ""},
+		{"0 */6 * * *", BackupPlanPolicyScheduleDaily, ""},
+		{"0 2 * * *", BackupPlanPolicyScheduleDaily, ""},
+		{"0 2 * * 1-5", BackupPlanPolicyScheduleWeekly, ""},
+		{"30 1 * * 0", BackupPlanPolicyScheduleWeekly, ""},
+		{"0 0 1 * *", BackupPlanPolicyScheduleMonthly, ""},
+		{"0 0 1 */3 *", BackupPlanPolicyScheduleUnset, ""},
+		{"0 0 1 1 *", BackupPlanPolicyScheduleUnset, ""},
+		{"0 0 31 2 *", BackupPlanPolicyScheduleUnset, ""},
+		{"61 * * * *", BackupPlanPolicyScheduleUnset, "invalid backup schedule cron expression"},
+	}
+
+	for _, test := range tests {
+		got, err := GetBackupScheduleFrequency(test.cron)
+		if test.errPart != "" {
+			require.ErrorContains(t, err, test.errPart, test.cron)
+			continue
+		}
+		require.NoError(t, err, test.cron)
+		require.Equal(t, test.want, got, test.cron)
+	}
+}
+
+func TestBackupPlanPolicyScheduleSatisfies(t *testing.T) {
+	tests := []struct {
+		actual   BackupPlanPolicySchedule
+		expected BackupPlanPolicySchedule
+		want     bool
+	}{
+		{BackupPlanPolicyScheduleUnset, BackupPlanPolicyScheduleUnset, true},
+		{BackupPlanPolicyScheduleUnset, BackupPlanPolicyScheduleMonthly, false},
+		{BackupPlanPolicyScheduleWeekly, BackupPlanPolicyScheduleMonthly, true},
+		{BackupPlanPolicyScheduleWeekly, BackupPlanPolicyScheduleDaily, false},
+		{BackupPlanPolicyScheduleDaily, BackupPlanPolicyScheduleDaily, true},
+		{BackupPlanPolicyScheduleDaily, BackupPlanPolicyScheduleHourly, false},
+		{BackupPlanPolicyScheduleHourly, BackupPlanPolicyScheduleWeekly, true},
+	}
+
+	for _, test := range tests {
+		require.Equal(t, test.want, test.actual.Satisfies(test.expected), "%s satisfies %s", test.actual, test.expected)
+	}
+}
+
+func TestGetBackupSettingSchedule(t *testing.T) {
+	tests := []struct {
+		name         string
+		enabled      bool
+		hour         int
+		dayOfWeek    int
+		scheduleList []BackupSchedule
+		want         BackupPlanPolicySchedule
+	}{
+		{"disabled", false, 0, -1, []BackupSchedule{{Cron: "0 * * * *"}}, BackupPlanPolicyScheduleUnset},
+		{"weekly", true, 3, 2, nil, BackupPlanPolicyScheduleWeekly},
+		{"daily", true, 3, -1, nil, BackupPlanPolicyScheduleDaily},
+		{"hourlyCron", true, 3, 2, []BackupSchedule{{Cron: "0 0 1 * *"}, {Cron: "0 * * * *"}}, BackupPlanPolicyScheduleHourly},
+		{"monthlyCronOnly", true, -1, -1, []BackupSchedule{{Cron: "0 0 1 * *"}}, BackupPlanPolicyScheduleMonthly},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := GetBackupSettingSchedule(test.enabled, test.hour, test.dayOfWeek, test.scheduleList)
+			require.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestGetMissingCronScheduleList(t *testing.T) {
+	policy := &BackupPlanPolicy{
+		Schedule: BackupPlanPolicyScheduleDaily,
+		CronScheduleList: []BackupSchedule{
+			{Cron: "0 0 1 * *", RetentionPeriodTs: 365 * 86400},
+			{Cron: "0 * * * *"},
+		},
+	}
+	tests := []struct {
+		name         string
+		scheduleList []BackupSchedule
+		want         []BackupSchedule
+	}{
+		{
+			name:         "none",
+			scheduleList: nil,
+			want:         policy.CronScheduleList,
+		},
+		{
+			name: "shortRetention",
+			scheduleList: []BackupSchedule{
+				{Cron: "0 0 1 * *", RetentionPeriodTs: 30 * 86400},
+				{Cron: "0 * * * *", RetentionPeriodTs: 86400},
+			},
+			want: []BackupSchedule{{Cron: "0 0 1 * *", RetentionPeriodTs: 365 * 86400}},
+		},
+		{
+			name: "satisfied",
+			scheduleList: []BackupSchedule{
+				{Cron: "0 * * * *", RetentionPeriodTs: 86400},
+				{Cron: "0 0 1 * *"},
+			},
+			want: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := policy.GetMissingCronScheduleList(test.scheduleList)
+			require.Equal(t, test.want, got)
+		})
+	}
+}
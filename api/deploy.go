@@ -51,15 +51,18 @@ type LabelSelector struct {
 }
 
 // OperatorType is the type of label selector requirement operator.
-// Valid operators are In, Exists.
-// Note: NotIn and DoesNotExist are not supported initially.
+// Valid operators are In, NotIn, Exists and DoesNotExist.
 type OperatorType string
 
 const (
 	// InOperatorType is the operator type for In.
 	InOperatorType OperatorType = "In"
+	// NotInOperatorType is the operator type for NotIn.
+	NotInOperatorType OperatorType = "NotIn"
 	// ExistsOperatorType is the operator type for Exists.
 	ExistsOperatorType OperatorType = "Exists"
+	// DoesNotExistOperatorType is the operator type for DoesNotExist.
+	DoesNotExistOperatorType OperatorType = "DoesNotExist"
 )
 
 // LabelSelectorRequirement is the API message for label selector.
@@ -114,11 +117,11 @@ func ValidateAndGetDeploymentSchedule(payload string) (*DeploymentSchedule, erro
 		hasEnv := false
 		for _, e := range d.Spec.Selector.MatchExpressions {
 			switch e.Operator {
-			case InOperatorType:
+			case InOperatorType, NotInOperatorType:
 				if len(e.Values) == 0 {
 					return nil, common.Errorf(common.Invalid, "expression key %q with %q operator should have at least one value", e.Key, e.Operator)
 				}
-			case ExistsOperatorType:
+			case ExistsOperatorType, DoesNotExistOperatorType:
 				if len(e.Values) > 0 {
 					return nil, common.Errorf(common.Invalid, "expression key %q with %q operator shouldn't have values", e.Key, e.Operator)
 				}
@@ -77,6 +77,51 @@ func TestGetDeploymentSchedule(t *testing.T) {
 			`{"deployments":[{"name":"deployment1","spec":{"selector":{"matchExpressions":[{"key":"bb.environment","operator":"In","values":["prod"]},{"key":"location","operator":"Exists","values":["us-central1","europe-west1"]}]}}}]}`,
 			nil,
 			"operator shouldn't have values",
+		}, {
+			"notInAndDoesNotExistOperators",
+			`{"deployments":[{"name":"deployment1","spec":{"selector":{"matchExpressions":[{"key":"bb.environment","operator":"In","values":["prod"]},{"key":"location","operator":"NotIn","values":["eu"]},{"key":"bb.tenant","operator":"DoesNotExist"}]}}}]}`,
+			&DeploymentSchedule{
+				Deployments: []*Deployment{
+					{
+						Name: "deployment1",
+						Spec: &DeploymentSpec{
+							Selector: &LabelSelector{
+								MatchExpressions: []*LabelSelectorRequirement{
+									{
+										Key:      "bb.environment",
+										Operator: "In",
+										Values:   []string{"prod"},
+									}, {
+										Key:      "location",
+										Operator: "NotIn",
+										Values:   []string{"eu"},
+									}, {
+										Key:      "bb.tenant",
+										Operator: "DoesNotExist",
+										Values:   nil,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"",
+		}, {
+			"notInOperatorWithNoValue",
+			`{"deployments":[{"name":"deployment1","spec":{"selector":{"matchExpressions":[{"key":"bb.environment","operator":"In","values":["prod"]},{"key":"location","operator":"NotIn"}]}}}]}`,
+			nil,
+			"operator should have at least one value",
+		}, {
+			"doesNotExistOperatorWithValues",
+			`{"deployments":[{"name":"deployment1","spec":{"selector":{"matchExpressions":[{"key":"bb.environment","operator":"In","values":["prod"]},{"key":"location","operator":"DoesNotExist","values":["eu"]}]}}}]}`,
+			nil,
+			"operator shouldn't have values",
+		}, {
+			"environmentNotInOperator",
+			`{"deployments":[{"name":"deployment1","spec":{"selector":{"matchExpressions":[{"key":"bb.environment","operator":"NotIn","values":["prod"]}]}}}]}`,
+			nil,
+			"should must use operator",
 		}, {
 			"invalidOperator",
 			`{"deployments":[{"name":"deployment1","spec":{"selector":{"matchExpressions":[{"key":"bb.environment","operator":"In","values":["prod"]},{"key":"location","operator":"invalid"}]}}}]}`,
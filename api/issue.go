@@ -89,6 +89,35 @@ type Issue struct {
 	Payload        string       `jsonapi:"attr,payload"`
 }
 
+// IssuePayload is the API message for the issue payload that is used by the backend.
+type IssuePayload struct {
+	// VCSPullRequest is set if the issue is created from a VCS pull request.
+	VCSPullRequest *IssueVCSPullRequestPayload `json:"vcsPullRequest,omitempty"`
+}
+
+// IssueVCSPullRequestPayload is the API message for the VCS pull request that the issue is created from.
+type IssueVCSPullRequestPayload struct {
+	// RepositoryID is the ID of Bytebase's own repository resource.
+	RepositoryID  int    `json:"repositoryId"`
+	PullRequestID string `json:"pullRequestId"`
+	URL           string `json:"url"`
+	HeadCommitID  string `json:"headCommitId"`
+	// Draft is true until the pull request is merged, the tasks of a draft issue can't be approved.
+	Draft bool `json:"draft"`
+	// ReportedCommitID is the head commit whose task check results have been reported to the pull request.
+	ReportedCommitID string `json:"reportedCommitId"`
+	// FileList is the migration files of the issue in the pull request, which locates the task check results in the files.
+	FileList []*IssueVCSPullRequestFile `json:"fileList,omitempty"`
+}
+
+// IssueVCSPullRequestFile is a migration file of the issue in the pull request.
+type IssueVCSPullRequestFile struct {
+	// Path is the file path in the repository.
+	Path string `json:"path"`
+	// SchemaVersion is the schema version parsed from the file path, which is the schema version of the tasks created from the file.
+	SchemaVersion string `json:"schemaVersion"`
+}
+
 // IssueResponse is the API message for an issue response.
 type IssueResponse struct {
 	Issues    []*Issue `jsonapi:"relation,issues"`
@@ -251,6 +280,8 @@ type IssuePatch struct {
 	Description *string `jsonapi:"attr,description"`
 	AssigneeID  *int    `jsonapi:"attr,assigneeId"`
 	Payload     *string `jsonapi:"attr,payload"`
+	// VCSPullRequest is only set by the server to update the pull request that the issue is created from.
+	VCSPullRequest *IssueVCSPullRequestPayload
 }
 
 // IssueStatusPatch is the API message for patching status of an issue.
@@ -70,8 +70,11 @@ const (
 	FeatureDataSource FeatureType = "bb.feature.data-source"
 	// FeatureVCSSQLReviewWorkflow allows user to enable the SQL review CI in VCS workflow.
 	FeatureVCSSQLReviewWorkflow FeatureType = "bb.feature.vcs-sql-review"
+	// FeatureVCSPullRequestWorkflow allows user to create draft issues from the pull requests in VCS workflow,
+	// and promote the issue after the pull request is merged.
+	FeatureVCSPullRequestWorkflow FeatureType = "bb.feature.vcs-pull-request-workflow"
 
-	// FeatureGhost allows user to use gh-ost for MySQL database migration.
+	// FeatureGhost allows user to use gh-ost for MySQL database migration, and the trigger-based online schema change for Postgres.
 	FeatureGhost FeatureType = "bb.feature.ghost"
 
 	// FeaturePITR allows user to perform point-in-time recovery for databases.
@@ -114,6 +117,10 @@ const (
 	// Currently, we only support GitLab EE/CE auth.
 	Feature3rdPartyAuth FeatureType = "bb.feature.3rd-party-auth"
 
+	// FeatureLDAP allows user to authenticate (login) against the LDAP directory, e.g., Active Directory,
+	// and authorize (sync workspace role and project member) from the LDAP groups.
+	FeatureLDAP FeatureType = "bb.feature.ldap"
+
 	// FeatureReadReplicaConnection allows user to set a read replica connection
 	// including host and port to data source.
 	FeatureReadReplicaConnection FeatureType = "bb.feature.read-replica-connection"
@@ -157,6 +164,8 @@ func (e FeatureType) Name() string {
 		return "RBAC"
 	case Feature3rdPartyAuth:
 		return "3rd party auth"
+	case FeatureLDAP:
+		return "LDAP"
 	case FeatureReadReplicaConnection:
 		return "Read replica connection"
 	case FeatureBranding:
@@ -165,6 +174,8 @@ func (e FeatureType) Name() string {
 		return "Environment tier"
 	case FeatureVCSSQLReviewWorkflow:
 		return "VCS SQL review workflow"
+	case FeatureVCSPullRequestWorkflow:
+		return "VCS pull request workflow"
 	}
 	return ""
 }
@@ -188,30 +199,34 @@ func (e FeatureType) minimumSupportedPlan() PlanType {
 
 // FeatureMatrix is a map from the a particular feature to the respective enablement of a particular plan.
 var FeatureMatrix = map[FeatureType][3]bool{
-	FeatureSchemaDrift:           {false, true, true},
-	FeatureTaskScheduleTime:      {false, true, true},
-	FeatureLGTM:                  {false, false, true},
-	FeatureMultiTenancy:          {false, false, true},
-	FeatureDBAWorkflow:           {false, false, true},
-	FeatureDataSource:            {false, false, false},
-	FeatureGhost:                 {false, true, true},
-	FeaturePITR:                  {false, true, true},
-	FeatureSyncSchema:            {false, false, true},
-	FeatureApprovalPolicy:        {false, true, true},
-	FeatureBackupPolicy:          {false, true, true},
-	FeatureSQLReviewPolicy:       {false, true, true},
-	FeatureRBAC:                  {false, true, true},
-	Feature3rdPartyAuth:          {false, true, true},
-	FeatureReadReplicaConnection: {false, false, true},
-	FeatureBranding:              {false, false, true},
-	FeatureEnvironmentTierPolicy: {false, false, true},
-	FeatureVCSSQLReviewWorkflow:  {false, false, true},
+	FeatureSchemaDrift:            {false, true, true},
+	FeatureTaskScheduleTime:       {false, true, true},
+	FeatureLGTM:                   {false, false, true},
+	FeatureMultiTenancy:           {false, false, true},
+	FeatureDBAWorkflow:            {false, false, true},
+	FeatureDataSource:             {false, false, false},
+	FeatureGhost:                  {false, true, true},
+	FeaturePITR:                   {false, true, true},
+	FeatureSyncSchema:             {false, false, true},
+	FeatureApprovalPolicy:         {false, true, true},
+	FeatureBackupPolicy:           {false, true, true},
+	FeatureSQLReviewPolicy:        {false, true, true},
+	FeatureRBAC:                   {false, true, true},
+	Feature3rdPartyAuth:           {false, true, true},
+	FeatureLDAP:                   {false, false, true},
+	FeatureReadReplicaConnection:  {false, false, true},
+	FeatureBranding:               {false, false, true},
+	FeatureEnvironmentTierPolicy:  {false, false, true},
+	FeatureVCSSQLReviewWorkflow:   {false, false, true},
+	FeatureVCSPullRequestWorkflow: {false, false, true},
 }
 
 // FeatureFlight is the flight map for features.
 // We can disable the hidden feature here. After the feature is released, we can set the bool to true to enable the feature.
 var FeatureFlight = map[FeatureType]bool{
-	FeatureVCSSQLReviewWorkflow: false,
+	FeatureVCSSQLReviewWorkflow:   false,
+	FeatureVCSPullRequestWorkflow: false,
+	FeatureLDAP:                   false,
 }
 
 // Plan is the API message for a plan.
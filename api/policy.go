@@ -2,6 +2,7 @@ package api
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -52,6 +53,10 @@ const (
 	BackupPlanPolicyScheduleDaily BackupPlanPolicySchedule = "DAILY"
 	// BackupPlanPolicyScheduleWeekly is WEEKLY backup plan policy value.
 	BackupPlanPolicyScheduleWeekly BackupPlanPolicySchedule = "WEEKLY"
+	// BackupPlanPolicyScheduleHourly is HOURLY backup plan policy value.
+	BackupPlanPolicyScheduleHourly BackupPlanPolicySchedule = "HOURLY"
+	// BackupPlanPolicyScheduleMonthly is MONTHLY backup plan policy value.
+	BackupPlanPolicyScheduleMonthly BackupPlanPolicySchedule = "MONTHLY"
 
 	// EnvironmentTierValueProtected is PROTECTED environment tier value.
 	EnvironmentTierValueProtected EnvironmentTierValue = "PROTECTED"
@@ -174,11 +179,79 @@ func (p *AssigneeGroup) String() (string, error) {
 	return string(s), nil
 }
 
+// backupPlanPolicyScheduleIntervals are the max intervals between two backups of the schedules, from the most frequent to the least.
+var backupPlanPolicyScheduleIntervals = []struct {
+	schedule BackupPlanPolicySchedule
+	interval time.Duration
+}{
+	{BackupPlanPolicyScheduleHourly, time.Hour},
+	{BackupPlanPolicyScheduleDaily, 24 * time.Hour},
+	{BackupPlanPolicyScheduleWeekly, 7 * 24 * time.Hour},
+	{BackupPlanPolicyScheduleMonthly, 31 * 24 * time.Hour},
+}
+
+// Interval returns the max interval between two backups of the schedule, and 0 for UNSET.
+func (s BackupPlanPolicySchedule) Interval() time.Duration {
+	for _, v := range backupPlanPolicyScheduleIntervals {
+		if v.schedule == s {
+			return v.interval
+		}
+	}
+	return 0
+}
+
+// Satisfies returns whether the backups are taken at least as frequently as the expected schedule.
+func (s BackupPlanPolicySchedule) Satisfies(expected BackupPlanPolicySchedule) bool {
+	if expected.Interval() == 0 {
+		return true
+	}
+	return s.Interval() != 0 && s.Interval() <= expected.Interval()
+}
+
+// GetBackupPlanPolicyScheduleByInterval returns the least frequent schedule which is satisfied by backing up every interval.
+// It returns UNSET if the interval is longer than the MONTHLY schedule.
+func GetBackupPlanPolicyScheduleByInterval(interval time.Duration) BackupPlanPolicySchedule {
+	for _, v := range backupPlanPolicyScheduleIntervals {
+		if interval <= v.interval {
+			return v.schedule
+		}
+	}
+	return BackupPlanPolicyScheduleUnset
+}
+
 // BackupPlanPolicy is the policy configuration for backup plan.
 type BackupPlanPolicy struct {
 	Schedule BackupPlanPolicySchedule `json:"schedule"`
 	// RetentionPeriodTs is the minimum allowed period that backup data is kept for databases in an environment.
 	RetentionPeriodTs int `json:"retentionPeriodTs"`
+	// CronScheduleList is the backup schedules in cron expressions that databases in an environment must have,
+	// and the retention period of each schedule is the minimum allowed period that its backup data is kept.
+	CronScheduleList []BackupSchedule `json:"cronScheduleList,omitempty"`
+}
+
+// GetMissingCronScheduleList returns the cron schedules required by the policy but missing in the schedule list,
+// including the schedules whose retention period is shorter than required.
+func (bp *BackupPlanPolicy) GetMissingCronScheduleList(scheduleList []BackupSchedule) []BackupSchedule {
+	var missingList []BackupSchedule
+	for _, expected := range bp.CronScheduleList {
+		found := false
+		for _, schedule := range scheduleList {
+			if schedule.Cron != expected.Cron {
+				continue
+			}
+			// The unset retention period of the policy means no requirement, and that of the backup setting means keeping the backups forever.
+			if expected.RetentionPeriodTs == BackupRetentionPeriodUnset ||
+				schedule.RetentionPeriodTs == BackupRetentionPeriodUnset ||
+				schedule.RetentionPeriodTs >= expected.RetentionPeriodTs {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missingList = append(missingList, expected)
+		}
+	}
+	return missingList
 }
 
 func (bp *BackupPlanPolicy) String() (string, error) {
@@ -264,9 +337,14 @@ func ValidatePolicy(pType PolicyType, payload string) error {
 		if err != nil {
 			return err
 		}
-		if bp.Schedule != BackupPlanPolicyScheduleUnset && bp.Schedule != BackupPlanPolicyScheduleDaily && bp.Schedule != BackupPlanPolicyScheduleWeekly {
+		switch bp.Schedule {
+		case BackupPlanPolicyScheduleUnset, BackupPlanPolicyScheduleHourly, BackupPlanPolicyScheduleDaily, BackupPlanPolicyScheduleWeekly, BackupPlanPolicyScheduleMonthly:
+		default:
 			return errors.Errorf("invalid backup plan policy schedule: %q", bp.Schedule)
 		}
+		if err := ValidateBackupScheduleList(bp.CronScheduleList); err != nil {
+			return err
+		}
 	case PolicyTypeSQLReview:
 		sr, err := UnmarshalSQLReviewPolicy(payload)
 		if err != nil {
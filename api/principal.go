@@ -27,6 +27,8 @@ const (
 	PrincipalAuthProviderGitlabSelfHost PrincipalAuthProvider = "GITLAB_SELF_HOST"
 	// PrincipalAuthProviderGitHubCom is the GitHub.com authentication provider.
 	PrincipalAuthProviderGitHubCom PrincipalAuthProvider = "GITHUB_COM"
+	// PrincipalAuthProviderLDAP is the LDAP authentication provider, e.g., Active Directory.
+	PrincipalAuthProviderLDAP PrincipalAuthProvider = "LDAP"
 )
 
 // Principal is the API message for principals.
@@ -155,6 +155,10 @@ var (
 	LocationToken = "{{LOCATION}}"
 	// TenantToken is the token for tenant.
 	TenantToken = "{{TENANT}}"
+	// VersionToken is the token for migration version.
+	VersionToken = "{{VERSION}}"
+	// IssueIDToken is the token for issue ID.
+	IssueIDToken = "{{ISSUE_ID}}"
 
 	// boolean indicates whether it's a required or optional token.
 	repositoryFilePathTemplateTokens = map[string]bool{
@@ -175,6 +179,12 @@ var (
 		DBNameToken:      true,
 		EnvironmentToken: false,
 	}
+	changelogCommitMessageTemplateTokens = map[string]bool{
+		DBNameToken:      false,
+		EnvironmentToken: false,
+		VersionToken:     false,
+		IssueIDToken:     false,
+	}
 	allowedProjectDBNameTemplateTokens = map[string]bool{
 		DBNameToken:   true,
 		LocationToken: true,
@@ -220,10 +230,31 @@ func ValidateRepositoryFilePathTemplate(filePathTemplate string, tenantMode Proj
 
 // ValidateRepositorySchemaPathTemplate validates the repository schema path template.
 func ValidateRepositorySchemaPathTemplate(schemaPathTemplate string, tenantMode ProjectTenantMode) error {
-	if schemaPathTemplate == "" {
+	return validateRepositoryPathTemplate(schemaPathTemplate, "schema path template", tenantMode)
+}
+
+// ValidateRepositoryChangelogPathTemplate validates the repository changelog path template.
+// The changelog is written per database, so it accepts the same tokens as the schema path template.
+func ValidateRepositoryChangelogPathTemplate(changelogPathTemplate string, tenantMode ProjectTenantMode) error {
+	return validateRepositoryPathTemplate(changelogPathTemplate, "changelog path template", tenantMode)
+}
+
+// ValidateRepositoryChangelogCommitMessageTemplate validates the repository changelog commit message template.
+func ValidateRepositoryChangelogCommitMessageTemplate(commitMessageTemplate string) error {
+	tokens, _ := common.ParseTemplateTokens(commitMessageTemplate)
+	for _, token := range tokens {
+		if _, ok := changelogCommitMessageTemplateTokens[token]; !ok {
+			return errors.Errorf("unknown token %s in changelog commit message template", token)
+		}
+	}
+	return nil
+}
+
+func validateRepositoryPathTemplate(pathTemplate string, templateName string, tenantMode ProjectTenantMode) error {
+	if pathTemplate == "" {
 		return nil
 	}
-	tokens, _ := common.ParseTemplateTokens(schemaPathTemplate)
+	tokens, _ := common.ParseTemplateTokens(pathTemplate)
 	tokenMap := make(map[string]bool)
 	for _, token := range tokens {
 		tokenMap[token] = true
@@ -237,13 +268,13 @@ func ValidateRepositorySchemaPathTemplate(schemaPathTemplate string, tenantMode
 	for token, required := range schemaPathTemplateTokens {
 		if required {
 			if _, ok := tokenMap[token]; !ok {
-				return errors.Errorf("missing %s in schema path template", token)
+				return errors.Errorf("missing %s in %s", token, templateName)
 			}
 		}
 	}
 	for token := range tokenMap {
 		if _, ok := schemaPathTemplateTokens[token]; !ok {
-			return errors.Errorf("unknown token %s in schema path template", token)
+			return errors.Errorf("unknown token %s in %s", token, templateName)
 		}
 	}
 	return nil
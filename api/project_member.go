@@ -17,6 +17,14 @@ const (
 	ProjectRoleProviderGitLabSelfHost ProjectRoleProvider = "GITLAB_SELF_HOST"
 	// ProjectRoleProviderGitHubCom indicates the role provider is the GitHub.com.
 	ProjectRoleProviderGitHubCom ProjectRoleProvider = "GITHUB_COM"
+	// ProjectRoleProviderGiteaSelfHost indicates the role provider is the Gitea
+	// self-hosted.
+	ProjectRoleProviderGiteaSelfHost ProjectRoleProvider = "GITEA_SELF_HOST"
+	// ProjectRoleProviderBitbucketServer indicates the role provider is the
+	// Bitbucket Server.
+	ProjectRoleProviderBitbucketServer ProjectRoleProvider = "BITBUCKET_SERVER"
+	// ProjectRoleProviderLDAP indicates the role provider is the LDAP group sync.
+	ProjectRoleProviderLDAP ProjectRoleProvider = "LDAP"
 )
 
 // ProjectRoleProviderPayload is the payload for role provider.
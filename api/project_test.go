@@ -153,6 +153,77 @@ func TestValidateRepositorySchemaPathTemplate(t *testing.T) {
 	}
 }
 
+func TestValidateRepositoryChangelogPathTemplate(t *testing.T) {
+	tests := []struct {
+		name       string
+		template   string
+		tenantMode ProjectTenantMode
+		errPart    string
+	}{
+		{
+			"OK",
+			"{{ENV_NAME}}/{{DB_NAME}}/CHANGELOG.md",
+			TenantModeDisabled,
+			"",
+		}, {
+			"MissingDBName",
+			"CHANGELOG.md",
+			TenantModeDisabled,
+			"missing {{DB_NAME}} in changelog path template",
+		}, {
+			"UnknownToken",
+			"{{DB_NAME}}/{{VERSION}}.md",
+			TenantModeDisabled,
+			"unknown token {{VERSION}} in changelog path template",
+		}, {
+			"Tenant mode {{ENV_NAME}}",
+			"{{ENV_NAME}}/{{DB_NAME}}/CHANGELOG.md",
+			TenantModeTenant,
+			"not allowed in the template",
+		},
+	}
+
+	for _, test := range tests {
+		err := ValidateRepositoryChangelogPathTemplate(test.template, test.tenantMode)
+		if test.errPart == "" {
+			require.NoError(t, err)
+		} else {
+			require.Contains(t, err.Error(), test.errPart)
+		}
+	}
+}
+
+func TestValidateRepositoryChangelogCommitMessageTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		errPart  string
+	}{
+		{
+			"OK",
+			"chore: update changelog of {{DB_NAME}} in {{ENV_NAME}} to {{VERSION}} (issue {{ISSUE_ID}})",
+			"",
+		}, {
+			"OK without tokens",
+			"chore: update changelog",
+			"",
+		}, {
+			"UnknownToken",
+			"chore: update changelog of {{TYPE}}",
+			"unknown token {{TYPE}}",
+		},
+	}
+
+	for _, test := range tests {
+		err := ValidateRepositoryChangelogCommitMessageTemplate(test.template)
+		if test.errPart == "" {
+			require.NoError(t, err)
+		} else {
+			require.Contains(t, err.Error(), test.errPart)
+		}
+	}
+}
+
 func TestValidateProjectDBNameTemplate(t *testing.T) {
 	tests := []struct {
 		name     string
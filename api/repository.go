@@ -42,11 +42,20 @@ type Repository struct {
 	// We don't need to persist it in the storage,
 	// only return this value if we need to auto-create the pull request for users.
 	SQLReviewCIPullRequestURL string `jsonapi:"attr,sqlReviewCIPullRequestURL"`
-	ExternalID                string `jsonapi:"attr,externalId"`
-	ExternalWebhookID         string
-	WebhookURLHost            string
-	WebhookEndpointID         string
-	WebhookSecretToken        string
+	// Create draft issues from the pull requests targeting the branch filter instead of the pushes,
+	// and promote the issue to be runnable after the pull request is merged.
+	EnablePullRequestWorkflow bool `jsonapi:"attr,enablePullRequestWorkflow"`
+	// The file path template for the changelog auto-generated by Bytebase after each migration.
+	// If empty, then Bytebase won't auto generate it.
+	ChangelogPathTemplate string `jsonapi:"attr,changelogPathTemplate"`
+	// The commit message template for writing back the changelog.
+	// If empty, then the default commit message is used.
+	ChangelogCommitMessageTemplate string `jsonapi:"attr,changelogCommitMessageTemplate"`
+	ExternalID                     string `jsonapi:"attr,externalId"`
+	ExternalWebhookID              string
+	WebhookURLHost                 string
+	WebhookEndpointID              string
+	WebhookSecretToken             string
 	// These will be exclusively used on the server side and we don't return it to the client.
 	AccessToken  string
 	ExpiresTs    int64
@@ -73,7 +82,13 @@ type RepositoryCreate struct {
 	SchemaPathTemplate string `jsonapi:"attr,schemaPathTemplate"`
 	SheetPathTemplate  string `jsonapi:"attr,sheetPathTemplate"`
 	EnableSQLReviewCI  bool   `jsonapi:"attr,enableSQLReviewCI"`
-	ExternalID         string `jsonapi:"attr,externalId"`
+	// EnablePullRequestWorkflow is the flag to create draft issues from the pull requests.
+	EnablePullRequestWorkflow bool `jsonapi:"attr,enablePullRequestWorkflow"`
+	// ChangelogPathTemplate is the file path template for the changelog.
+	ChangelogPathTemplate string `jsonapi:"attr,changelogPathTemplate"`
+	// ChangelogCommitMessageTemplate is the commit message template for writing back the changelog.
+	ChangelogCommitMessageTemplate string `jsonapi:"attr,changelogCommitMessageTemplate"`
+	ExternalID                     string `jsonapi:"attr,externalId"`
 	// Token belonged by the user linking the project to the VCS repository. We store this token together
 	// with the refresh token in the new repository record so we can use it to call VCS API on
 	// behalf of that user to perform tasks such as webhook CRUD later.
@@ -124,9 +139,15 @@ type RepositoryPatch struct {
 	SchemaPathTemplate *string `jsonapi:"attr,schemaPathTemplate"`
 	SheetPathTemplate  *string `jsonapi:"attr,sheetPathTemplate"`
 	EnableSQLReviewCI  *bool   `jsonapi:"attr,enableSQLReviewCI"`
-	AccessToken        *string
-	ExpiresTs          *int64
-	RefreshToken       *string
+	// EnablePullRequestWorkflow is the flag to create draft issues from the pull requests.
+	EnablePullRequestWorkflow *bool `jsonapi:"attr,enablePullRequestWorkflow"`
+	// ChangelogPathTemplate is the file path template for the changelog.
+	ChangelogPathTemplate *string `jsonapi:"attr,changelogPathTemplate"`
+	// ChangelogCommitMessageTemplate is the commit message template for writing back the changelog.
+	ChangelogCommitMessageTemplate *string `jsonapi:"attr,changelogCommitMessageTemplate"`
+	AccessToken                    *string
+	ExpiresTs                      *int64
+	RefreshToken                   *string
 }
 
 // RepositoryDelete is the API message for deleting a repository.
@@ -2,6 +2,9 @@ package api
 
 import (
 	"encoding/json"
+
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/plugin/ldap"
 )
 
 // SettingName is the name of a setting.
@@ -16,8 +19,101 @@ const (
 	SettingWorkspaceID SettingName = "bb.workspace.id"
 	// SettingEnterpriseLicense is the setting name for enterprise license.
 	SettingEnterpriseLicense SettingName = "bb.enterprise.license"
+	// SettingBackupArtifact is the setting name for the compression and encryption of backup artifacts.
+	SettingBackupArtifact SettingName = "bb.backup.artifact"
+	// SettingBackupVerification is the setting name for the scheduled backup verification.
+	SettingBackupVerification SettingName = "bb.backup.verification"
+	// SettingPITR is the setting name for the PostgreSQL instances opted in to PITR.
+	SettingPITR SettingName = "bb.backup.pitr"
+	// SettingLockImpactThreshold is the setting name for the thresholds of the lock impact check.
+	SettingLockImpactThreshold SettingName = "bb.lock-impact.threshold"
+	// SettingLDAP is the setting name for the LDAP authentication and group sync.
+	SettingLDAP SettingName = "bb.auth.ldap"
 )
 
+// BackupArtifactSetting is the value of the SettingBackupArtifact setting in JSON.
+type BackupArtifactSetting struct {
+	Compression BackupCompression `json:"compression"`
+	Encryption  BackupEncryption  `json:"encryption"`
+	// EncryptionKey is the base64 encoded 256-bit key.
+	// EncryptionKeyFile is the path of the file containing the base64 encoded key, which takes precedence over EncryptionKey.
+	EncryptionKey     string `json:"encryptionKey,omitempty"`
+	EncryptionKeyFile string `json:"encryptionKeyFile,omitempty"`
+}
+
+// BackupVerificationSetting is the value of the SettingBackupVerification setting in JSON.
+type BackupVerificationSetting struct {
+	Enabled bool `json:"enabled"`
+	// IntervalTs is the minimum interval in seconds between two verifications of the same database.
+	IntervalTs int `json:"intervalTs"`
+	// SandboxInstanceID is the instance to restore the backups into.
+	// The backups are restored into the instance of the source database if it is unset.
+	SandboxInstanceID int `json:"sandboxInstanceId,omitempty"`
+}
+
+// PITRSetting is the value of the SettingPITR setting in JSON.
+// The PITR of PostgreSQL takes base backups of the whole instance and streams its WAL, so each instance opts in to it explicitly.
+type PITRSetting struct {
+	InstanceList []*PITRInstanceSetting `json:"instanceList"`
+}
+
+// PITRInstanceSetting is the PITR setting of a PostgreSQL instance.
+type PITRInstanceSetting struct {
+	InstanceID int `json:"instanceId"`
+	// ReplicationSlot streams the WAL through a replication slot, so that the server retains the WAL not yet archived while Bytebase is down.
+	// It requires max_slot_wal_keep_size to be set on the server to bound the retained WAL.
+	ReplicationSlot bool `json:"replicationSlot"`
+}
+
+// LockImpactThresholdSetting is the value of the SettingLockImpactThreshold setting in JSON.
+// The lock impact check warns the statements blocking the writes on a table while scanning or rewriting it,
+// if the table exceeds any of the thresholds. A threshold of 0 disables it.
+type LockImpactThresholdSetting struct {
+	RowCount int64 `json:"rowCount"`
+	// DataSize is the threshold of the table data size in bytes.
+	DataSize int64 `json:"dataSize"`
+	// DurationTs is the threshold of the estimated duration in seconds.
+	DurationTs int64 `json:"durationTs"`
+}
+
+// LDAPSetting is the value of the SettingLDAP setting in JSON.
+type LDAPSetting struct {
+	Enabled          bool                  `json:"enabled"`
+	Host             string                `json:"host"`
+	Port             int                   `json:"port"`
+	SecurityProtocol ldap.SecurityProtocol `json:"securityProtocol"`
+	SkipTLSVerify    bool                  `json:"skipTlsVerify"`
+	BindDN           string                `json:"bindDn"`
+	BindPassword     string                `json:"bindPassword"`
+	BaseDN           string                `json:"baseDn"`
+	// UserFilter is the filter to find the user by the username entered at login, e.g., "(sAMAccountName=%s)".
+	UserFilter     string `json:"userFilter"`
+	EmailAttribute string `json:"emailAttribute,omitempty"`
+	NameAttribute  string `json:"nameAttribute,omitempty"`
+	// AllowLinkExistingPrincipal allows linking the LDAP users to the existing principals not created via LDAP
+	// by the same email. It's off by default, because the LDAP user would take over the existing principal.
+	AllowLinkExistingPrincipal bool `json:"allowLinkExistingPrincipal"`
+	// GroupSyncIntervalTs is the minimum interval in seconds between two group syncs.
+	GroupSyncIntervalTs int `json:"groupSyncIntervalTs"`
+	// RoleMappingList maps the LDAP groups to the workspace roles.
+	RoleMappingList []*LDAPRoleMapping `json:"roleMappingList,omitempty"`
+	// ProjectMappingList maps the LDAP groups to the project members.
+	ProjectMappingList []*LDAPProjectMapping `json:"projectMappingList,omitempty"`
+}
+
+// LDAPRoleMapping grants the workspace role to the members of the LDAP group.
+type LDAPRoleMapping struct {
+	GroupDN string `json:"groupDn"`
+	Role    Role   `json:"role"`
+}
+
+// LDAPProjectMapping grants the project role to the members of the LDAP group.
+type LDAPProjectMapping struct {
+	GroupDN   string             `json:"groupDn"`
+	ProjectID int                `json:"projectId"`
+	Role      common.ProjectRole `json:"role"`
+}
+
 // Setting is the API message for a setting.
 type Setting struct {
 	ID int `jsonapi:"primary,setting"`
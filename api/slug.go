@@ -30,3 +30,8 @@ func EnvSlug(env *Environment) string {
 func ProjectWebhookSlug(projectWebhook *ProjectWebhook) string {
 	return fmt.Sprintf("%s-%d", slug.Make(projectWebhook.Name), projectWebhook.ID)
 }
+
+// DatabaseSlug is the slug formatter for databases.
+func DatabaseSlug(database *Database) string {
+	return fmt.Sprintf("%s-%d", slug.Make(database.Name), database.ID)
+}
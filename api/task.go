@@ -135,6 +135,8 @@ type TaskDatabaseDataUpdatePayload struct {
 // TaskDatabaseBackupPayload is the task payload for database backup.
 type TaskDatabaseBackupPayload struct {
 	BackupID int `json:"backupId,omitempty"`
+	// Schedule is the cron expression of the backup schedule triggering the backup, empty for the default schedule.
+	Schedule string `json:"schedule,omitempty"`
 }
 
 // Task is the API message for a task.
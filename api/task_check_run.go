@@ -69,6 +69,10 @@ const (
 	TaskCheckDatabaseStatementAdvise TaskCheckType = "bb.task-check.database.statement.advise"
 	// TaskCheckDatabaseStatementType is the task check type for statement type.
 	TaskCheckDatabaseStatementType TaskCheckType = "bb.task-check.database.statement.type"
+	// TaskCheckDatabaseStatementDryRun is the task check type for dry running the statement.
+	TaskCheckDatabaseStatementDryRun TaskCheckType = "bb.task-check.database.statement.dry-run"
+	// TaskCheckDatabaseStatementLockImpact is the task check type for the lock and rewrite impact of the statement.
+	TaskCheckDatabaseStatementLockImpact TaskCheckType = "bb.task-check.database.statement.lock-impact"
 	// TaskCheckDatabaseConnect is the task check type for database connection.
 	TaskCheckDatabaseConnect TaskCheckType = "bb.task-check.database.connect"
 	// TaskCheckInstanceMigrationSchema is the task check type for migrating schemas.
@@ -79,6 +83,8 @@ const (
 	TaskCheckIssueLGTM TaskCheckType = "bb.task-check.issue.lgtm"
 	// TaskCheckPITRMySQL is the task check type for MySQL PITR.
 	TaskCheckPITRMySQL TaskCheckType = "bb.task-check.pitr.mysql"
+	// TaskCheckPITRPostgres is the task check type for PostgreSQL PITR.
+	TaskCheckPITRPostgres TaskCheckType = "bb.task-check.pitr.pg"
 )
 
 // TaskCheckEarliestAllowedTimePayload is the task check payload for earliest allowed time.
@@ -107,6 +113,18 @@ type TaskCheckDatabaseStatementTypePayload struct {
 	Collation string `json:"collation,omitempty"`
 }
 
+// TaskCheckDatabaseStatementDryRunPayload is the task check payload for dry running the statement.
+type TaskCheckDatabaseStatementDryRunPayload struct {
+	Statement string  `json:"statement,omitempty"`
+	DbType    db.Type `json:"dbType,omitempty"`
+}
+
+// TaskCheckDatabaseStatementLockImpactPayload is the task check payload for the lock and rewrite impact of the statement.
+type TaskCheckDatabaseStatementLockImpactPayload struct {
+	Statement string  `json:"statement,omitempty"`
+	DbType    db.Type `json:"dbType,omitempty"`
+}
+
 // Namespace is the namespace for task check result.
 type Namespace string
 
@@ -124,6 +142,8 @@ type TaskCheckResult struct {
 	Status    TaskCheckStatus `json:"status,omitempty"`
 	Title     string          `json:"title,omitempty"`
 	Content   string          `json:"content,omitempty"`
+	// Line is the line of the statement where the problem is found, zero if unknown.
+	Line int `json:"line,omitempty"`
 }
 
 // TaskCheckRunResultPayload is the result payload of a task check run.
@@ -243,3 +263,23 @@ func IsStatementTypeCheckSupported(dbType db.Type) bool {
 		return false
 	}
 }
+
+// IsStatementDryRunSupported checks the engine type if statement dry run supports it.
+func IsStatementDryRunSupported(dbType db.Type) bool {
+	switch dbType {
+	case db.Postgres, db.MySQL:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsStatementLockImpactSupported checks the engine type if statement lock impact check supports it.
+func IsStatementLockImpactSupported(dbType db.Type) bool {
+	switch dbType {
+	case db.Postgres, db.MySQL:
+		return true
+	default:
+		return false
+	}
+}
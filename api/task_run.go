@@ -27,6 +27,8 @@ type TaskRunResultPayload struct {
 	Detail      string `json:"detail,omitempty"`
 	MigrationID int64  `json:"migrationId,omitempty"`
 	Version     string `json:"version,omitempty"`
+	// RollbackStatement is the statement to revert the row changes made by the data update task.
+	RollbackStatement string `json:"rollbackStatement,omitempty"`
 }
 
 // TaskRun is the API message for a task run.
@@ -0,0 +1,86 @@
+// Package cmd is the command surface of Bytebase bb tool provided by bytebase.com.
+package cmd
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/bytebase/bytebase/plugin/parser"
+)
+
+func newFormatCmd() *cobra.Command {
+	var (
+		engineType string
+		file       string
+		write      bool
+
+		// Format options.
+		keywordCase   string
+		indentWidth   int
+		commaPosition string
+	)
+	formatCmd := &cobra.Command{
+		Use:   "format",
+		Short: "Formats the SQL statements.",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			var engine parser.EngineType
+			switch strings.ToLower(engineType) {
+			case "mysql":
+				engine = parser.MySQL
+			case "tidb":
+				engine = parser.TiDB
+			case "postgres", "postgresql":
+				engine = parser.Postgres
+			default:
+				return errors.Errorf("unsupported database type %q", engineType)
+			}
+			if write && file == "" {
+				return errors.Errorf("--write requires --file")
+			}
+
+			var statement []byte
+			var err error
+			if file != "" {
+				statement, err = os.ReadFile(file)
+				if err != nil {
+					return errors.Wrapf(err, "failed to read file %q", file)
+				}
+			} else {
+				statement, err = io.ReadAll(cmd.InOrStdin())
+				if err != nil {
+					return errors.Wrap(err, "failed to read from stdin")
+				}
+			}
+
+			formatted, err := parser.Format(engine, string(statement), parser.FormatOption{
+				KeywordCase:   parser.KeywordCase(strings.ToUpper(keywordCase)),
+				IndentWidth:   indentWidth,
+				CommaPosition: parser.CommaPosition(strings.ToUpper(commaPosition)),
+			})
+			if err != nil {
+				return errors.Wrap(err, "failed to format")
+			}
+
+			if write {
+				if err := os.WriteFile(file, []byte(formatted), 0644); err != nil {
+					return errors.Wrapf(err, "failed to write file %q", file)
+				}
+				return nil
+			}
+			_, err = io.WriteString(cmd.OutOrStdout(), formatted)
+			return err
+		},
+	}
+
+	formatCmd.Flags().StringVar(&engineType, "type", "mysql", "Database type of the statements: mysql, tidb or postgres.")
+	formatCmd.Flags().StringVar(&file, "file", "", "File of the statements to format. Read from stdin if unspecified.")
+	formatCmd.Flags().BoolVar(&write, "write", false, "Write the result to the file instead of stdout.")
+	formatCmd.Flags().StringVar(&keywordCase, "keyword-case", string(parser.KeywordCaseUpper), "Case of the keywords: upper, lower or preserve.")
+	formatCmd.Flags().IntVar(&indentWidth, "indent-width", parser.DefaultFormatIndentWidth, "Number of spaces for one level of indentation.")
+	formatCmd.Flags().StringVar(&commaPosition, "comma-position", string(parser.CommaPositionTrailing), "Position of the commas in the lists broken into lines: trailing or leading.")
+	return formatCmd
+}
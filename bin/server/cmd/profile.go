@@ -18,10 +18,6 @@ func getBaseProfile() server.Profile {
 		}
 		demoDataDir = fmt.Sprintf("demo/%s", demoName)
 	}
-	backupStorageBackend := api.BackupStorageBackendLocal
-	if flags.backupBucket != "" {
-		backupStorageBackend = api.BackupStorageBackendS3
-	}
 	// Using flags.port + 1 as our datastore port
 	datastorePort := flags.port + 1
 
@@ -36,10 +32,11 @@ func getBaseProfile() server.Profile {
 		GitCommit:            gitcommit,
 		PgURL:                flags.pgURL,
 		DisableMetric:        flags.disableMetric,
-		BackupStorageBackend: backupStorageBackend,
+		BackupStorageBackend: flags.backupStorageBackend,
 		BackupRegion:         flags.backupRegion,
 		BackupBucket:         flags.backupBucket,
 		BackupCredentialFile: flags.backupCredential,
+		BackupEndpoint:       flags.backupEndpoint,
 	}
 }
 
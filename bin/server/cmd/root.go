@@ -16,14 +16,21 @@ import (
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
 
+	"github.com/bytebase/bytebase/api"
 	"github.com/bytebase/bytebase/common"
 	"github.com/bytebase/bytebase/common/log"
 	"github.com/bytebase/bytebase/server"
 
 	// Register clickhouse driver.
 	_ "github.com/bytebase/bytebase/plugin/db/clickhouse"
+	// Register mongodb driver.
+	_ "github.com/bytebase/bytebase/plugin/db/mongodb"
+	// Register mssql driver.
+	_ "github.com/bytebase/bytebase/plugin/db/mssql"
 	// Register mysql driver.
 	_ "github.com/bytebase/bytebase/plugin/db/mysql"
+	// Register oracle driver.
+	_ "github.com/bytebase/bytebase/plugin/db/oracle"
 	// Register postgres driver.
 	_ "github.com/bytebase/bytebase/plugin/db/pg"
 	// Register snowflake driver.
@@ -40,6 +47,8 @@ import (
 	// Register postgresql advisor.
 	_ "github.com/bytebase/bytebase/plugin/advisor/pg"
 
+	// Register clickhouse differ driver.
+	_ "github.com/bytebase/bytebase/plugin/parser/differ/clickhouse"
 	// Register mysql differ driver.
 	_ "github.com/bytebase/bytebase/plugin/parser/differ/mysql"
 	// Register postgres differ driver.
@@ -116,6 +125,9 @@ var (
 		backupRegion     string
 		backupBucket     string
 		backupCredential string
+		backupEndpoint   string
+		// backupStorageBackend is derived from the scheme of backupBucket.
+		backupStorageBackend api.BackupStorageBackend
 	}
 
 	rootCmd = &cobra.Command{
@@ -159,10 +171,10 @@ func init() {
 	rootCmd.PersistentFlags().BoolVar(&flags.disableMetric, "disable-metric", false, "disable the metric collector")
 
 	// Cloud backup related flags.
-	// TODO(dragonly): Add GCS usages when it's supported.
-	rootCmd.PersistentFlags().StringVar(&flags.backupBucket, "backup-bucket", "", "bucket where Bytebase stores backup data, e.g., s3://example-bucket. When provided, Bytebase will store data to the S3 bucket.")
+	rootCmd.PersistentFlags().StringVar(&flags.backupBucket, "backup-bucket", "", "bucket where Bytebase stores backup data, e.g., s3://example-bucket for AWS S3, gs://example-bucket for Google Cloud Storage, azblob://example-container for Azure Blob Storage, or file:///mnt/nfs/backup for a mounted file system. When provided, Bytebase will store data to the bucket.")
 	rootCmd.PersistentFlags().StringVar(&flags.backupRegion, "backup-region", "", "region of the backup bucket, e.g., us-west-2 for AWS S3.")
-	rootCmd.PersistentFlags().StringVar(&flags.backupCredential, "backup-credential", "", "credentials file to use for the backup bucket. It should be the same format as the AWS/GCP credential files.")
+	rootCmd.PersistentFlags().StringVar(&flags.backupCredential, "backup-credential", "", "credentials file to use for the backup bucket. It should be the AWS credential file for AWS S3, the service account key file for Google Cloud Storage, or a file containing the connection string for Azure Blob Storage.")
+	rootCmd.PersistentFlags().StringVar(&flags.backupEndpoint, "backup-endpoint", "", "optional endpoint of the S3 compatible service for the backup bucket, e.g., http://localhost:9000 for MinIO.")
 }
 
 // -----------------------------------Command Line Config END--------------------------------------
@@ -213,19 +225,58 @@ func checkDataDir() error {
 	return nil
 }
 
+// backupBucketSchemes maps the scheme of the --backup-bucket URI to the storage backend.
+var backupBucketSchemes = []struct {
+	scheme         string
+	storageBackend api.BackupStorageBackend
+}{
+	{scheme: "s3://", storageBackend: api.BackupStorageBackendS3},
+	{scheme: "gs://", storageBackend: api.BackupStorageBackendGCS},
+	{scheme: "azblob://", storageBackend: api.BackupStorageBackendAzure},
+	{scheme: "file://", storageBackend: api.BackupStorageBackendFileSystem},
+}
+
 func checkCloudBackupFlags() error {
+	flags.backupStorageBackend = api.BackupStorageBackendLocal
 	if flags.backupBucket == "" {
 		return nil
 	}
-	if !strings.HasPrefix(flags.backupBucket, "s3://") {
-		return errors.Errorf("only support bucket URI starting with s3://")
+	for _, s := range backupBucketSchemes {
+		if strings.HasPrefix(flags.backupBucket, s.scheme) {
+			flags.backupBucket = strings.TrimPrefix(flags.backupBucket, s.scheme)
+			flags.backupStorageBackend = s.storageBackend
+			break
+		}
+	}
+	if flags.backupBucket == "" {
+		return errors.Errorf("must specify the bucket name in --backup-bucket")
 	}
-	flags.backupBucket = strings.TrimPrefix(flags.backupBucket, "s3://")
-	if flags.backupCredential == "" {
-		return errors.Errorf("must specify --backup-credential when --backup-bucket is present")
+	switch flags.backupStorageBackend {
+	case api.BackupStorageBackendS3:
+		if flags.backupCredential == "" {
+			return errors.Errorf("must specify --backup-credential for AWS S3 backup")
+		}
+		if flags.backupRegion == "" {
+			return errors.Errorf("must specify --backup-region for AWS S3 backup")
+		}
+	case api.BackupStorageBackendGCS:
+		// The credential is optional, Google Cloud Storage falls back to the application default credentials.
+	case api.BackupStorageBackendAzure:
+		if flags.backupCredential == "" {
+			return errors.Errorf("must specify --backup-credential for Azure Blob Storage backup")
+		}
+	case api.BackupStorageBackendFileSystem:
+		if !filepath.IsAbs(flags.backupBucket) {
+			return errors.Errorf("the backup directory %q must be an absolute path", flags.backupBucket)
+		}
+		if _, err := os.Stat(flags.backupBucket); err != nil {
+			return errors.Wrapf(err, "unable to access the backup directory %s", flags.backupBucket)
+		}
+	default:
+		return errors.Errorf("only support bucket URI starting with s3://, gs://, azblob:// or file://")
 	}
-	if flags.backupRegion == "" {
-		return errors.Errorf("must specify --backup-region for AWS S3 backup")
+	if flags.backupEndpoint != "" && flags.backupStorageBackend != api.BackupStorageBackendS3 {
+		return errors.Errorf("--backup-endpoint is only supported for AWS S3 compatible services")
 	}
 	return nil
 }
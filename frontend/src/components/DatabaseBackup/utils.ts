@@ -2,7 +2,7 @@ import { BackupPlanPolicySchedule, BackupSetting } from "@/types";
 
 export type BackupSettingEdit = Pick<
   BackupSetting,
-  "enabled" | "dayOfWeek" | "hour" | "retentionPeriodTs"
+  "enabled" | "dayOfWeek" | "hour" | "retentionPeriodTs" | "scheduleList"
 >;
 
 export const PLAN_SCHEDULES: BackupPlanPolicySchedule[] = [
@@ -11,6 +11,18 @@ export const PLAN_SCHEDULES: BackupPlanPolicySchedule[] = [
   "DAILY",
 ];
 
+// SCHEDULE_LEVELS lists the schedules from the least frequent to the most frequent.
+export const SCHEDULE_LEVELS: BackupPlanPolicySchedule[] = [
+  "UNSET",
+  "MONTHLY",
+  "WEEKLY",
+  "DAILY",
+  "HOURLY",
+];
+
+// HOURLY_CRON matches the cron expressions firing every hour, e.g., "15 * * * *".
+const HOURLY_CRON = /^\s*\d+\s+\*\s+\*\s+\*\s+\*\s*$/;
+
 export const AVAILABLE_DAYS_OF_WEEK = [...Array(7).keys()]; // [0...6]
 export const AVAILABLE_HOURS_OF_DAY = [...Array(24).keys()]; // [0...23]
 
@@ -22,12 +34,14 @@ export function parseScheduleFromBackupSetting(
   backupSetting: BackupSettingEdit
 ) {
   if (!backupSetting.enabled) return "UNSET";
+  const scheduleList = backupSetting.scheduleList ?? [];
+  if (scheduleList.some((s) => HOURLY_CRON.test(s.cron))) return "HOURLY";
   if (backupSetting.dayOfWeek === -1) return "DAILY";
   return "WEEKLY";
 }
 
 export function levelOfSchedule(schedule: BackupPlanPolicySchedule) {
-  return PLAN_SCHEDULES.indexOf(schedule) || 0;
+  return Math.max(SCHEDULE_LEVELS.indexOf(schedule), 0);
 }
 
 export function localToUTC(hour: number, dayOfWeek: number) {
@@ -17,13 +17,30 @@ import {
 import { useI18n } from "vue-i18n";
 
 export const MIN_PITR_SUPPORT_MYSQL_VERSION = "8.0.0";
+// The base backups of PostgreSQL are recovered by the bundled PostgreSQL 14 binaries.
+export const PITR_SUPPORT_POSTGRES_MAJOR_VERSION = "14";
+
+const isPITRSupportedEngineVersion = (
+  engine: string,
+  engineVersion: string
+): boolean => {
+  if (engine === "MYSQL") {
+    return (
+      semverCompare(
+        engineVersion.split("-")[0],
+        MIN_PITR_SUPPORT_MYSQL_VERSION
+      ) >= 0
+    );
+  }
+  if (engine === "POSTGRES") {
+    return engineVersion.split(".")[0] === PITR_SUPPORT_POSTGRES_MAJOR_VERSION;
+  }
+  return false;
+};
 
 export const isPITRAvailableOnInstance = (instance: Instance): boolean => {
   const { engine, engineVersion } = instance;
-  return (
-    engine === "MYSQL" &&
-    semverCompare(engineVersion, MIN_PITR_SUPPORT_MYSQL_VERSION) >= 0
-  );
+  return isPITRSupportedEngineVersion(engine, engineVersion);
 };
 
 export const usePITRLogic = (database: Ref<Database>) => {
@@ -40,13 +57,7 @@ export const usePITRLogic = (database: Ref<Database>) => {
 
   const pitrAvailable = computed((): { result: boolean; message: string } => {
     const { engine, engineVersion } = database.value.instance;
-    if (
-      engine === "MYSQL" &&
-      semverCompare(
-        engineVersion.split("-")[0],
-        MIN_PITR_SUPPORT_MYSQL_VERSION
-      ) >= 0
-    ) {
+    if (isPITRSupportedEngineVersion(engine, engineVersion)) {
       if (doneBackupList.value.length > 0) {
         return { result: true, message: "ok" };
       }
@@ -55,6 +66,15 @@ export const usePITRLogic = (database: Ref<Database>) => {
         message: t("database.pitr.no-available-backup"),
       };
     }
+    if (engine === "POSTGRES") {
+      return {
+        result: false,
+        message: t("database.pitr.supported-engine-and-major-version", {
+          engine: "PostgreSQL",
+          major_version: PITR_SUPPORT_POSTGRES_MAJOR_VERSION,
+        }),
+      };
+    }
     return {
       result: false,
       message: t("database.pitr.minimum-supported-engine-and-version", {
@@ -197,7 +197,10 @@ export const useBackupStore = defineStore("backup", {
 
     async upsertBackupSettingByEnvironmentId(
       environmentId: EnvironmentId,
-      backupSettingUpsert: Omit<BackupSettingUpsert, "databaseId">
+      backupSettingUpsert: Omit<
+        BackupSettingUpsert,
+        "databaseId" | "scheduleList"
+      >
     ) {
       const url = `/api/environment/${environmentId}/backup-setting`;
       await axios.patch(url, {
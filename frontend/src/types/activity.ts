@@ -31,7 +31,9 @@ export type ProjectActivityType =
   | "bb.project.member.delete"
   | "bb.project.member.role.update";
 
-export type DatabaseActivityType = "bb.database.recovery.pitr.done";
+export type DatabaseActivityType =
+  | "bb.database.recovery.pitr.done"
+  | "bb.database.backup.verification.failed";
 
 export type SQLEditorActivityType = "bb.sql-editor.query";
 
@@ -80,6 +82,8 @@ export function activityName(type: ActivityType): string {
       return t("activity.type.project-member-role-update");
     case "bb.database.recovery.pitr.done":
       return t("activity.type.database-recovery-pitr-done");
+    case "bb.database.backup.verification.failed":
+      return t("activity.type.database-backup-verification-failed");
   }
   console.assert(false, `undefined text for activity type "${type}"`);
   return "";
@@ -1,6 +1,7 @@
 import {
   AnomalyId,
   BackupPlanPolicySchedule,
+  BackupSchedule,
   Database,
   DatabaseId,
   EnvironmentId,
@@ -15,6 +16,7 @@ export type AnomalyType =
   | "bb.anomaly.instance.migration-schema"
   | "bb.anomaly.database.backup.policy-violation"
   | "bb.anomaly.database.backup.missing"
+  | "bb.anomaly.database.backup.verification-failed"
   | "bb.anomaly.database.connection"
   | "bb.anomaly.database.schema.drift";
 
@@ -26,6 +28,7 @@ export type AnomalyDatabaseBackupPolicyViolationPayload = {
   environmentId: EnvironmentId;
   expectedSchedule: BackupPlanPolicySchedule;
   actualSchedule: BackupPlanPolicySchedule;
+  missingCronScheduleList?: BackupSchedule[];
 };
 
 export type AnomalyDatabaseBackupMissingPayload = {
@@ -33,6 +36,12 @@ export type AnomalyDatabaseBackupMissingPayload = {
   lastBackupTs: number;
 };
 
+export type AnomalyDatabaseBackupVerificationFailedPayload = {
+  backupId: number;
+  backupName: string;
+  detail: string;
+};
+
 export type AnomalyDatabaseConnectionPayload = {
   detail: string;
 };
@@ -46,6 +55,7 @@ export type AnomalyDatabaseSchemaDriftPayload = {
 export type AnomalyPayload =
   | AnomalyDatabaseBackupPolicyViolationPayload
   | AnomalyDatabaseBackupMissingPayload
+  | AnomalyDatabaseBackupVerificationFailedPayload
   | AnomalyDatabaseConnectionPayload
   | AnomalyDatabaseSchemaDriftPayload;
 
@@ -5,7 +5,13 @@ export type BackupStatus = "PENDING_CREATE" | "DONE" | "FAILED";
 
 export type BackupType = "MANUAL" | "AUTOMATIC" | "PITR";
 
-export type BackupStorageBackend = "LOCAL" | "S3" | "GCS";
+export type BackupStorageBackend =
+  | "LOCAL"
+  | "S3"
+  | "GCS"
+  | "OSS"
+  | "AZURE"
+  | "FILESYSTEM";
 
 // Backup
 export type Backup = {
@@ -56,6 +62,8 @@ export type BackupSetting = {
   dayOfWeek: number;
   retentionPeriodTs: number;
   hookUrl: string;
+  // Additional backup schedules in cron expressions, each with its own retention period.
+  scheduleList: BackupSchedule[];
 };
 
 export type BackupSettingUpsert = {
@@ -68,4 +76,11 @@ export type BackupSettingUpsert = {
   dayOfWeek: number;
   retentionPeriodTs: number;
   hookUrl: string;
+  scheduleList: BackupSchedule[];
+};
+
+// Backup schedule in a standard cron expression in UTC.
+export type BackupSchedule = {
+  cron: string;
+  retentionPeriodTs: number;
 };
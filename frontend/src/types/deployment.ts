@@ -46,4 +46,4 @@ export type LabelSelectorRequirement = {
   values: LabelValueType[];
 };
 
-export type OperatorType = "In" | "Exists";
+export type OperatorType = "In" | "NotIn" | "Exists" | "DoesNotExist";
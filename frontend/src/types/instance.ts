@@ -7,7 +7,10 @@ import { VCSPushEvent } from "./vcs";
 
 export type EngineType =
   | "CLICKHOUSE"
+  | "MONGODB"
+  | "MSSQL"
   | "MYSQL"
+  | "ORACLE"
   | "POSTGRES"
   | "SNOWFLAKE"
   | "TIDB";
@@ -15,11 +18,18 @@ export type EngineType =
 export function defaultCharset(type: EngineType): string {
   switch (type) {
     case "CLICKHOUSE":
+    case "MONGODB":
     case "SNOWFLAKE":
       return "";
+    // SQL Server has no character set, the code page is determined by the collation.
+    case "MSSQL":
+      return "";
     case "MYSQL":
     case "TIDB":
       return "utf8mb4";
+    // Bytebase does not create databases for Oracle.
+    case "ORACLE":
+      return "";
     case "POSTGRES":
       return "UTF8";
   }
@@ -28,11 +38,17 @@ export function defaultCharset(type: EngineType): string {
 export function defaultCollation(type: EngineType): string {
   switch (type) {
     case "CLICKHOUSE":
+    case "MONGODB":
     case "SNOWFLAKE":
       return "";
+    // SQL Server uses the instance default collation if the collation is not specified.
+    case "MSSQL":
+      return "";
     case "MYSQL":
     case "TIDB":
       return "utf8mb4_general_ci";
+    case "ORACLE":
+      return "";
     // For postgres, we don't explicitly specify a default since the default might be UNSET (denoted by "C").
     // If that's the case, setting an explicit default such as "en_US.UTF-8" might fail if the instance doesn't
     // install it.
@@ -41,6 +57,11 @@ export function defaultCollation(type: EngineType): string {
   }
 }
 
+// Some engines have no statement creating an empty database, so the databases are created outside of Bytebase.
+export function isCreateDatabaseSupported(type: EngineType): boolean {
+  return type !== "MONGODB" && type !== "ORACLE";
+}
+
 export type Instance = {
   id: InstanceId;
 
@@ -55,6 +55,27 @@ export function openWindowForOAuth(
       "location=yes,left=200,top=200,height=640,width=480,scrollbars=yes,status=yes"
     );
   }
+  if (vcsType == "GITEA_SELF_HOST") {
+    // Gitea OAuth2 applications are granted all the permissions of the user.
+    return window.open(
+      `${endpoint}?client_id=${applicationId}&redirect_uri=${encodeURIComponent(
+        redirectUrl()
+      )}&state=${stateQueryParameter}&response_type=code`,
+      "oauth",
+      "location=yes,left=200,top=200,height=640,width=480,scrollbars=yes,status=yes"
+    );
+  }
+  if (vcsType == "BITBUCKET_SERVER") {
+    // Bitbucket Server OAuth scopes: https://confluence.atlassian.com/bitbucketserver/bitbucket-oauth-2-0-provider-api-1108483661.html
+    // We need the REPO_ADMIN scope to manage webhooks of the repository.
+    return window.open(
+      `${endpoint}?client_id=${applicationId}&redirect_uri=${encodeURIComponent(
+        redirectUrl()
+      )}&state=${stateQueryParameter}&response_type=code&scope=REPO_ADMIN`,
+      "oauth",
+      "location=yes,left=200,top=200,height=640,width=480,scrollbars=yes,status=yes"
+    );
+  }
   // GITLAB_SELF_HOST
   // GitLab OAuth App scopes: https://docs.gitlab.com/ee/integration/oauth_provider.html#authorized-applications
   return window.open(
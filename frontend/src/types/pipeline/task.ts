@@ -193,6 +193,7 @@ export type TaskRunResultPayload = {
   detail: string;
   migrationId?: MigrationHistoryId;
   version?: string;
+  rollbackStatement?: string;
 };
 
 export type TaskRun = {
@@ -222,11 +223,14 @@ export type TaskCheckType =
   | "bb.task-check.database.statement.compatibility"
   | "bb.task-check.database.statement.advise"
   | "bb.task-check.database.statement.type"
+  | "bb.task-check.database.statement.dry-run"
+  | "bb.task-check.database.statement.lock-impact"
   | "bb.task-check.database.connect"
   | "bb.task-check.instance.migration-schema"
   | "bb.task-check.database.ghost.sync"
   | "bb.task-check.issue.lgtm"
-  | "bb.task-check.pitr.mysql";
+  | "bb.task-check.pitr.mysql"
+  | "bb.task-check.pitr.pg";
 
 export type TaskCheckDatabaseStatementAdvisePayload = {
   statement: string;
@@ -259,6 +263,7 @@ export type TaskCheckResult = {
   title: string;
   content: string;
   namespace: TaskCheckNamespace;
+  line?: number;
 };
 
 export type TaskCheckRunResultPayload = {
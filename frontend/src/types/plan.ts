@@ -15,6 +15,7 @@ export type FeatureType =
   | "bb.feature.data-source"
   | "bb.feature.online-migration"
   | "bb.feature.vcs-sql-review"
+  | "bb.feature.vcs-pull-request-workflow"
   // Policy Control
   | "bb.feature.approval-policy"
   | "bb.feature.backup-policy"
@@ -23,6 +24,7 @@ export type FeatureType =
   // Admin & Security
   | "bb.feature.rbac"
   | "bb.feature.3rd-party-auth"
+  | "bb.feature.ldap"
   | "bb.feature.read-replica-connection"
   // Branding
   | "bb.feature.branding";
@@ -85,6 +87,7 @@ export const FEATURE_MATRIX: Map<FeatureType, boolean[]> = new Map([
   ["bb.feature.data-source", [false, false, false]],
   ["bb.feature.online-migration", [false, true, true]],
   ["bb.feature.vcs-sql-review", [false, false, true]],
+  ["bb.feature.vcs-pull-request-workflow", [false, false, true]],
   // Policy Control
   ["bb.feature.approval-policy", [false, true, true]],
   ["bb.feature.backup-policy", [false, true, true]],
@@ -93,6 +96,7 @@ export const FEATURE_MATRIX: Map<FeatureType, boolean[]> = new Map([
   // Admin & Security
   ["bb.feature.rbac", [false, true, true]],
   ["bb.feature.3rd-party-auth", [false, true, true]],
+  ["bb.feature.ldap", [false, false, true]],
   ["bb.feature.read-replica-connection", [false, false, true]],
   // Branding
   ["bb.feature.branding", [false, false, true]],
@@ -1,4 +1,5 @@
 import {
+  BackupSchedule,
   RowStatus,
   Environment,
   IssueType,
@@ -35,10 +36,17 @@ export type EnvironmentTierPolicyPayload = {
 
 export const DefaultEnvironmentTier: EnvironmentTier = "UNPROTECTED";
 
-export type BackupPlanPolicySchedule = "UNSET" | "DAILY" | "WEEKLY";
+export type BackupPlanPolicySchedule =
+  | "UNSET"
+  | "HOURLY"
+  | "DAILY"
+  | "WEEKLY"
+  | "MONTHLY";
 
 export type BackupPlanPolicyPayload = {
   schedule: BackupPlanPolicySchedule;
+  // The cron backup schedules every database in the environment must have.
+  cronScheduleList?: BackupSchedule[];
 };
 
 export const DefaultSchedulePolicy: BackupPlanPolicySchedule = "UNSET";
@@ -16,6 +16,9 @@ export type ProjectTenantMode = "DISABLED" | "TENANT";
 export type ProjectRoleProvider =
   | "GITLAB_SELF_HOST"
   | "GITHUB_COM"
+  | "GITEA_SELF_HOST"
+  | "BITBUCKET_SERVER"
+  | "LDAP"
   | "BYTEBASE";
 
 export type SchemaChangeType = "DDL" | "SDL";
@@ -82,6 +82,15 @@ export const PROJECT_HOOK_ACTIVITY_ITEM_LIST: () => ProjectWebhookActivityItem[]
       label: t("project.webhook.activity-item.issue-comment-creation.label"),
       activity: "bb.issue.comment.create",
     },
+    {
+      title: t(
+        "project.webhook.activity-item.backup-verification-failure.title"
+      ),
+      label: t(
+        "project.webhook.activity-item.backup-verification-failure.label"
+      ),
+      activity: "bb.database.backup.verification.failed",
+    },
   ];
 
 // Project Member
@@ -31,6 +31,9 @@ export type Repository = {
   sheetPathTemplate: string;
   enableSQLReviewCI: boolean;
   sqlReviewCIPullRequestURL: string;
+  enablePullRequestWorkflow: boolean;
+  changelogPathTemplate: string;
+  changelogCommitMessageTemplate: string;
   // e.g. In GitLab, this is the corresponding project id.
   externalId: string;
 };
@@ -49,6 +52,9 @@ export type RepositoryCreate = {
   schemaPathTemplate: string;
   sheetPathTemplate: string;
   enableSQLReviewCI: boolean;
+  enablePullRequestWorkflow: boolean;
+  changelogPathTemplate: string;
+  changelogCommitMessageTemplate: string;
   externalId: string;
   accessToken: string;
   expiresTs: number;
@@ -62,6 +68,9 @@ export type RepositoryPatch = {
   schemaPathTemplate?: string;
   sheetPathTemplate?: string;
   enableSQLReviewCI?: boolean;
+  enablePullRequestWorkflow?: boolean;
+  changelogPathTemplate?: string;
+  changelogCommitMessageTemplate?: string;
 };
 
 export type RepositoryConfig = {
@@ -71,6 +80,9 @@ export type RepositoryConfig = {
   schemaPathTemplate: string;
   sheetPathTemplate: string;
   enableSQLReviewCI: boolean;
+  enablePullRequestWorkflow: boolean;
+  changelogPathTemplate: string;
+  changelogCommitMessageTemplate: string;
 };
 
 export type ExternalRepositoryInfo = {
@@ -106,6 +118,16 @@ export function baseDirectoryWebUrl(
     if (!isEmpty(repository.baseDirectory)) {
       url += `/${repository.baseDirectory}`;
     }
+  } else if (repository.vcs.type == "GITEA_SELF_HOST") {
+    url = `${repository.webUrl}/src/branch/${repository.branchFilter}`;
+    if (!isEmpty(repository.baseDirectory)) {
+      url += `/${repository.baseDirectory}`;
+    }
+  } else if (repository.vcs.type == "BITBUCKET_SERVER") {
+    url = repository.webUrl;
+    if (!isEmpty(repository.baseDirectory)) {
+      url += `/${repository.baseDirectory}`;
+    }
   }
   if (url) {
     // Replace the patterns in the filePathTemplate if possible.
@@ -11,7 +11,12 @@ import {
 
 export type SheetVisibility = "PRIVATE" | "PROJECT" | "PUBLIC";
 
-export type SheetSource = "BYTEBASE" | "GITLAB_SELF_HOST" | "GITHUB_COM";
+export type SheetSource =
+  | "BYTEBASE"
+  | "GITLAB_SELF_HOST"
+  | "GITHUB_COM"
+  | "GITEA_SELF_HOST"
+  | "BITBUCKET_SERVER";
 
 export type SheetType = "SQL";
 
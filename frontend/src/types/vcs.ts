@@ -1,7 +1,11 @@
 import { VCSId } from "./id";
 import { Principal } from "./principal";
 
-export type VCSType = "GITLAB_SELF_HOST" | "GITHUB_COM";
+export type VCSType =
+  | "GITLAB_SELF_HOST"
+  | "GITHUB_COM"
+  | "GITEA_SELF_HOST"
+  | "BITBUCKET_SERVER";
 
 export interface VCSConfig {
   type: VCSType;
@@ -78,6 +82,10 @@ export function isValidVCSApplicationIdOrSecret(
     return /^[a-zA-Z0-9_]{64}$/.test(str);
   } else if (vcsType == "GITHUB_COM") {
     return /^[a-zA-Z0-9_]{20}$|^[a-zA-Z0-9_]{40}$/.test(str);
+  } else if (vcsType == "GITEA_SELF_HOST") {
+    return /^[a-zA-Z0-9_=-]{36,64}$/.test(str);
+  } else if (vcsType == "BITBUCKET_SERVER") {
+    return /^[a-zA-Z0-9_]{32,64}$/.test(str);
   }
   return false;
 }
@@ -56,6 +56,7 @@ export function sortDatabaseList(
 }
 
 const MIN_GHOST_SUPPORT_MYSQL_VERSION = "5.7.0";
+const MIN_GHOST_SUPPORT_POSTGRES_MAJOR_VERSION = 10;
 
 export function allowGhostMigration(databaseList: Database[]): boolean {
   const groupByEnvironment = groupBy(
@@ -71,6 +72,12 @@ export function allowGhostMigration(databaseList: Database[]): boolean {
   }
 
   return databaseList.every((db) => {
+    if (db.instance.engine === "POSTGRES") {
+      return (
+        parseInt(db.instance.engineVersion.split(".")[0], 10) >=
+        MIN_GHOST_SUPPORT_POSTGRES_MAJOR_VERSION
+      );
+    }
     return (
       db.instance.engine === "MYSQL" &&
       semverCompare(
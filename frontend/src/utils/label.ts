@@ -147,8 +147,12 @@ export const isDatabaseMatchesSelector = (
     switch (rule.operator) {
       case "In":
         return checkLabelIn(database, rule);
+      case "NotIn":
+        return checkLabelNotIn(database, rule);
       case "Exists":
         return checkLabelExists(database, rule);
+      case "DoesNotExist":
+        return !checkLabelExists(database, rule);
       default:
         // unknown operators are taken as mismatch
         console.warn(`known operator "${rule.operator}"`);
@@ -167,6 +171,17 @@ const checkLabelIn = (
   return rule.values.some((value) => value === label.value);
 };
 
+const checkLabelNotIn = (
+  db: Database,
+  rule: LabelSelectorRequirement
+): boolean => {
+  const label = db.labels.find((label) => label.key === rule.key);
+  // databases without the label are taken as match
+  if (!label) return true;
+
+  return rule.values.every((value) => value !== label.value);
+};
+
 const checkLabelExists = (
   db: Database,
   rule: LabelSelectorRequirement
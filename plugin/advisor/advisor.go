@@ -236,6 +236,36 @@ const (
 
 	// PostgreSQLIndexKeyNumberLimit is an advisor type for postgresql index key number limit.
 	PostgreSQLIndexKeyNumberLimit Type = "bb.plugin.advisor.postgresql.index.key-number-limit"
+
+	// PostgreSQLIndexTotalNumberLimit is an advisor type for PostgreSQL index total number limit.
+	PostgreSQLIndexTotalNumberLimit Type = "bb.plugin.advisor.postgresql.index.total-number-limit"
+
+	// PostgreSQLTableCommentConvention is an advisor type for PostgreSQL table comment convention.
+	PostgreSQLTableCommentConvention Type = "bb.plugin.advisor.postgresql.table.comment"
+
+	// PostgreSQLColumnCommentConvention is an advisor type for PostgreSQL column comment convention.
+	PostgreSQLColumnCommentConvention Type = "bb.plugin.advisor.postgresql.column.comment"
+
+	// PostgreSQLColumnTypeRestriction is an advisor type for PostgreSQL column type restriction.
+	PostgreSQLColumnTypeRestriction Type = "bb.plugin.advisor.postgresql.column.type-restriction"
+
+	// PostgreSQLColumnDisallowChangingType is an advisor type for PostgreSQL disallow changing column type.
+	PostgreSQLColumnDisallowChangingType Type = "bb.plugin.advisor.postgresql.column.disallow-changing-type"
+
+	// PostgreSQLColumnSetDefaultForNotNull is an advisor type for PostgreSQL set default value for not null column.
+	PostgreSQLColumnSetDefaultForNotNull Type = "bb.plugin.advisor.postgresql.column.set-default-for-not-null"
+
+	// PostgreSQLDatabaseAllowDropIfEmpty is an advisor type for PostgreSQL only allow drop empty database.
+	PostgreSQLDatabaseAllowDropIfEmpty Type = "bb.plugin.advisor.postgresql.database.drop-empty-database"
+
+	// PostgreSQLStatementDisallowCommit is an advisor type for PostgreSQL to disallow commit.
+	PostgreSQLStatementDisallowCommit Type = "bb.plugin.advisor.postgresql.statement.disallow-commit"
+
+	// PostgreSQLMergeAlterTable is an advisor type for PostgreSQL no redundant ALTER TABLE statements.
+	PostgreSQLMergeAlterTable Type = "bb.plugin.advisor.postgresql.statement.merge-alter-table"
+
+	// PostgreSQLInsertDisallowOrderByRand is an advisor type for PostgreSQL to disallow order by random in INSERT statements.
+	PostgreSQLInsertDisallowOrderByRand Type = "bb.plugin.advisor.postgresql.insert.disallow-order-by-rand"
 )
 
 // Advice is the result of an advisor.
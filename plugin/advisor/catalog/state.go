@@ -122,6 +122,9 @@ type DatabaseState struct {
 	dbType       db.Type
 	schemaSet    schemaStateMap
 	deleted      bool
+	// searchPath is the schema search path set by SET search_path for PostgreSQL.
+	// The nil searchPath means the default search path, i.e., the "public" schema.
+	searchPath []string
 }
 
 // HasNoTable returns true if the current database has no table.
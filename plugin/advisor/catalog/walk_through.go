@@ -85,6 +85,16 @@ const (
 	ErrorTypeInsertSpecifiedColumnTwice = 602
 	// ErrorTypeInsertNullIntoNotNullColumn is the error that insert NULL into NOT NULL columns.
 	ErrorTypeInsertNullIntoNotNullColumn = 603
+
+	// 701 ~ 799 schema error type.
+
+	// ErrorTypeSchemaExists is the error that schema exists.
+	ErrorTypeSchemaExists = 701
+
+	// 801 ~ 899 view error type.
+
+	// ErrorTypeViewNotExists is the error that view does not exist.
+	ErrorTypeViewNotExists = 801
 )
 
 // WalkThroughError is the error for walking-through.
@@ -118,6 +128,14 @@ func NewIndexNotExistsError(tableName string, indexName string) *WalkThroughErro
 	}
 }
 
+// NewIndexNotExistsInSchemaError returns a new ErrorTypeIndexNotExists for the index in the PostgreSQL schema.
+func NewIndexNotExistsInSchemaError(schemaName string, indexName string) *WalkThroughError {
+	return &WalkThroughError{
+		Type:    ErrorTypeIndexNotExists,
+		Content: fmt.Sprintf("Index `%s` does not exist in schema `%s`", indexName, schemaName),
+	}
+}
+
 // NewIndexExistsError returns a new ErrorTypeIndexExists.
 func NewIndexExistsError(tableName string, indexName string) *WalkThroughError {
 	return &WalkThroughError{
@@ -150,6 +168,14 @@ func NewTableExistsError(tableName string) *WalkThroughError {
 	}
 }
 
+// NewViewNotExistsError returns a new ErrorTypeViewNotExists.
+func NewViewNotExistsError(viewName string) *WalkThroughError {
+	return &WalkThroughError{
+		Type:    ErrorTypeViewNotExists,
+		Content: fmt.Sprintf("View `%s` does not exist", viewName),
+	}
+}
+
 // Error implements the error interface.
 func (e *WalkThroughError) Error() string {
 	return e.Content
@@ -157,6 +183,9 @@ func (e *WalkThroughError) Error() string {
 
 // WalkThrough will collect the catalog schema in the databaseState as it walks through the stmts.
 func (d *DatabaseState) WalkThrough(stmts string) error {
+	if d.dbType == db.Postgres {
+		return d.pgWalkThrough(stmts)
+	}
 	if d.dbType != db.MySQL && d.dbType != db.TiDB {
 		return &WalkThroughError{
 			Type:    ErrorTypeUnsupported,
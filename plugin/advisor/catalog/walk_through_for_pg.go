@@ -0,0 +1,869 @@
+package catalog
+
+// This file implements the walk-through for PostgreSQL.
+// PostgreSQL organizes the tables, views and indexes in schemas. The names of tables, views and indexes share
+// the same namespace in a schema. The statements without the schema name create objects in the first schema of
+// the search path, and look up objects in the schemas of the search path in order. The search path defaults to
+// the "public" schema and follows the SET search_path statements.
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bytebase/bytebase/plugin/parser"
+	"github.com/bytebase/bytebase/plugin/parser/ast"
+)
+
+const (
+	// PostgreSQLPublicSchema is the default schema for PostgreSQL.
+	PostgreSQLPublicSchema = "public"
+
+	pgIndexTypeBtree = "btree"
+
+	pgSearchPathVariable = "search_path"
+)
+
+func (d *DatabaseState) pgWalkThrough(stmts string) error {
+	nodeList, err := parser.Parse(parser.Postgres, parser.ParseContext{}, stmts)
+	if err != nil {
+		return NewParseError(err.Error())
+	}
+
+	for _, node := range nodeList {
+		if err := d.pgChangeState(node); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d *DatabaseState) pgChangeState(in ast.Node) (err *WalkThroughError) {
+	defer func() {
+		if err == nil {
+			return
+		}
+		if err.Line == 0 {
+			err.Line = in.LastLine()
+		}
+	}()
+	if d.deleted {
+		return &WalkThroughError{
+			Type:    ErrorTypeDatabaseIsDeleted,
+			Content: fmt.Sprintf("Database `%s` is deleted", d.name),
+		}
+	}
+
+	switch node := in.(type) {
+	case *ast.CreateSchemaStmt:
+		return d.pgCreateSchema(node)
+	case *ast.CreateTableStmt:
+		return d.pgCreateTable(node)
+	case *ast.DropTableStmt:
+		return d.pgDropTable(node)
+	case *ast.AlterTableStmt:
+		return d.pgAlterTable(node)
+	case *ast.CreateIndexStmt:
+		return d.pgCreateIndex(node)
+	case *ast.DropIndexStmt:
+		return d.pgDropIndex(node)
+	case *ast.RenameIndexStmt:
+		return d.pgRenameIndex(node)
+	case *ast.CreateViewStmt:
+		return d.pgCreateView(node)
+	case *ast.InsertStmt:
+		return d.pgCheckRelationExists(node.Table)
+	case *ast.UpdateStmt:
+		return d.pgCheckRelationExists(node.Table)
+	case *ast.DeleteStmt:
+		return d.pgCheckRelationExists(node.Table)
+	case *ast.VariableSetStmt:
+		d.pgSetVariable(node)
+		return nil
+	case *ast.TruncateStmt:
+		for _, table := range node.TableList {
+			if _, _, err := d.pgFindTableState(table, false /* createIncompleteTable */); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return nil
+	}
+}
+
+func (d *DatabaseState) pgCreateSchema(node *ast.CreateSchemaStmt) *WalkThroughError {
+	name := node.Name
+	// CREATE SCHEMA AUTHORIZATION role creates the schema named after the role.
+	if name == "" {
+		name = node.Owner
+	}
+	if _, exists := d.schemaSet[name]; exists {
+		if node.IfNotExists {
+			return nil
+		}
+		return &WalkThroughError{
+			Type:    ErrorTypeSchemaExists,
+			Content: fmt.Sprintf("Schema `%s` already exists", name),
+		}
+	}
+	d.createSchema(name)
+	return nil
+}
+
+func (d *DatabaseState) pgCreateTable(node *ast.CreateTableStmt) *WalkThroughError {
+	schema, err := d.pgFindSchema(node.Name)
+	if err != nil {
+		return err
+	}
+
+	if schema.pgRelationExists(node.Name.Name) {
+		if node.IfNotExists {
+			return nil
+		}
+		return NewTableExistsError(pgQualifiedName(schema.name, node.Name.Name))
+	}
+
+	table := &TableState{
+		name:      node.Name.Name,
+		tableType: newEmptyStringPointer(),
+		engine:    newEmptyStringPointer(),
+		collation: newEmptyStringPointer(),
+		comment:   newEmptyStringPointer(),
+		columnSet: make(columnStateMap),
+		indexSet:  make(indexStateMap),
+	}
+	schema.tableSet[table.name] = table
+
+	for _, column := range node.ColumnList {
+		if err := d.pgCreateColumn(schema, table, column); err != nil {
+			err.Line = column.LastLine()
+			return err
+		}
+	}
+
+	for _, constraint := range node.ConstraintList {
+		if err := d.pgCreateConstraint(schema, table, constraint); err != nil {
+			err.Line = constraint.LastLine()
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d *DatabaseState) pgDropTable(node *ast.DropTableStmt) *WalkThroughError {
+	for _, tableDef := range node.TableList {
+		schema, err := d.pgLookupSchema(tableDef, func(schema *SchemaState) bool {
+			if tableDef.Type == ast.TableTypeView {
+				_, exists := schema.viewSet[tableDef.Name]
+				return exists
+			}
+			_, exists := schema.tableSet[tableDef.Name]
+			return exists
+		})
+		if err != nil {
+			return err
+		}
+
+		if tableDef.Type == ast.TableTypeView {
+			if _, exists := schema.viewSet[tableDef.Name]; !exists {
+				if node.IfExists || !schema.ctx.CheckIntegrity {
+					continue
+				}
+				return NewViewNotExistsError(pgQualifiedName(schema.name, tableDef.Name))
+			}
+			delete(schema.viewSet, tableDef.Name)
+			continue
+		}
+
+		if _, exists := schema.tableSet[tableDef.Name]; !exists {
+			if node.IfExists || !schema.ctx.CheckIntegrity {
+				continue
+			}
+			return NewTableNotExistsError(pgQualifiedName(schema.name, tableDef.Name))
+		}
+		delete(schema.tableSet, tableDef.Name)
+	}
+	return nil
+}
+
+func (d *DatabaseState) pgAlterTable(node *ast.AlterTableStmt) *WalkThroughError {
+	if node.Table.Type == ast.TableTypeView {
+		return d.pgAlterView(node)
+	}
+
+	schema, table, err := d.pgFindTableState(node.Table, true /* createIncompleteTable */)
+	if err != nil {
+		if node.IfExists && err.Type == ErrorTypeTableNotExists {
+			return nil
+		}
+		return err
+	}
+
+	for _, item := range node.AlterItemList {
+		switch cmd := item.(type) {
+		case *ast.AddColumnListStmt:
+			for _, column := range cmd.ColumnList {
+				if _, exists := table.columnSet[column.ColumnName]; exists && cmd.IfNotExists {
+					continue
+				}
+				if err := d.pgCreateColumn(schema, table, column); err != nil {
+					return err
+				}
+			}
+		case *ast.DropColumnStmt:
+			if _, exists := table.columnSet[cmd.ColumnName]; !exists && cmd.IfExists {
+				continue
+			}
+			if err := table.pgDropColumn(schema.ctx, cmd.ColumnName); err != nil {
+				return err
+			}
+		case *ast.AddConstraintStmt:
+			if err := d.pgCreateConstraint(schema, table, cmd.Constraint); err != nil {
+				return err
+			}
+		case *ast.DropConstraintStmt:
+			// The PRIMARY KEY and UNIQUE constraints are stored as indexes.
+			// We do not deal with other constraints, because the catalog doesn't record them.
+			delete(table.indexSet, cmd.ConstraintName)
+		case *ast.RenameConstraintStmt:
+			if _, exists := table.indexSet[cmd.ConstraintName]; exists {
+				if err := schema.pgRenameIndex(table, cmd.ConstraintName, cmd.NewName); err != nil {
+					return err
+				}
+			}
+		case *ast.SetNotNullStmt:
+			column, err := table.pgFindColumnState(schema.ctx, cmd.ColumnName)
+			if err != nil {
+				return err
+			}
+			column.nullable = newFalsePointer()
+		case *ast.DropNotNullStmt:
+			column, err := table.pgFindColumnState(schema.ctx, cmd.ColumnName)
+			if err != nil {
+				return err
+			}
+			column.nullable = newTruePointer()
+		case *ast.AlterColumnTypeStmt:
+			column, err := table.pgFindColumnState(schema.ctx, cmd.ColumnName)
+			if err != nil {
+				return err
+			}
+			column.columnType = newStringPointer(pgDataTypeString(cmd.Type))
+		case *ast.RenameColumnStmt:
+			if err := table.renameColumn(schema.ctx, cmd.ColumnName, cmd.NewName); err != nil {
+				return err
+			}
+		case *ast.RenameTableStmt:
+			if err := schema.pgRenameTable(table, cmd.NewName); err != nil {
+				return err
+			}
+		case *ast.SetSchemaStmt:
+			if err := d.pgSetTableSchema(schema, table, cmd.NewSchema); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (d *DatabaseState) pgAlterView(node *ast.AlterTableStmt) *WalkThroughError {
+	schema, err := d.pgLookupSchema(node.Table, func(schema *SchemaState) bool {
+		_, exists := schema.viewSet[node.Table.Name]
+		return exists
+	})
+	if err != nil {
+		return err
+	}
+
+	view, exists := schema.viewSet[node.Table.Name]
+	if !exists {
+		if node.IfExists {
+			return nil
+		}
+		if schema.ctx.CheckIntegrity {
+			return NewViewNotExistsError(pgQualifiedName(schema.name, node.Table.Name))
+		}
+		view = &ViewState{name: node.Table.Name}
+		schema.viewSet[view.name] = view
+	}
+
+	for _, item := range node.AlterItemList {
+		switch cmd := item.(type) {
+		case *ast.RenameTableStmt:
+			if cmd.NewName == view.name {
+				continue
+			}
+			if schema.pgRelationExists(cmd.NewName) {
+				return NewTableExistsError(pgQualifiedName(schema.name, cmd.NewName))
+			}
+			delete(schema.viewSet, view.name)
+			view.name = cmd.NewName
+			schema.viewSet[view.name] = view
+		case *ast.SetSchemaStmt:
+			target := d.pgGetOrCreateSchema(cmd.NewSchema)
+			if target == schema {
+				continue
+			}
+			if target.pgRelationExists(view.name) {
+				return NewTableExistsError(pgQualifiedName(target.name, view.name))
+			}
+			delete(schema.viewSet, view.name)
+			target.viewSet[view.name] = view
+			schema = target
+		}
+	}
+	return nil
+}
+
+func (d *DatabaseState) pgCreateView(node *ast.CreateViewStmt) *WalkThroughError {
+	schema, err := d.pgFindSchema(node.Name)
+	if err != nil {
+		return err
+	}
+
+	if view, exists := schema.viewSet[node.Name.Name]; exists {
+		if !node.Replace {
+			return NewTableExistsError(pgQualifiedName(schema.name, node.Name.Name))
+		}
+		view.definition = newStringPointer(node.Definition)
+		return nil
+	}
+	if schema.pgRelationExists(node.Name.Name) {
+		return NewTableExistsError(pgQualifiedName(schema.name, node.Name.Name))
+	}
+
+	schema.viewSet[node.Name.Name] = &ViewState{
+		name:       node.Name.Name,
+		definition: newStringPointer(node.Definition),
+		comment:    newEmptyStringPointer(),
+	}
+	return nil
+}
+
+func (d *DatabaseState) pgCreateIndex(node *ast.CreateIndexStmt) *WalkThroughError {
+	schema, table, err := d.pgFindTableState(node.Index.Table, true /* createIncompleteTable */)
+	if err != nil {
+		return err
+	}
+
+	var keyList []string
+	for _, key := range node.Index.KeyList {
+		if key.Type == ast.IndexKeyTypeColumn {
+			if _, exists := table.columnSet[key.Key]; !exists && schema.ctx.CheckIntegrity {
+				return NewColumnNotExistsError(table.name, key.Key)
+			}
+		}
+		keyList = append(keyList, key.Key)
+	}
+
+	name := node.Index.Name
+	if name == "" {
+		suffix := "idx"
+		if node.Index.Unique {
+			suffix = "key"
+		}
+		name = schema.pgGenerateRelationName(table.name, keyList, suffix)
+	} else if schema.pgRelationExists(name) {
+		if node.IfNotExists {
+			return nil
+		}
+		return NewIndexExistsError(table.name, name)
+	}
+
+	table.indexSet[name] = &IndexState{
+		name:           name,
+		expressionList: keyList,
+		indextype:      newStringPointer(pgIndexTypeBtree),
+		unique:         newBoolPointer(node.Index.Unique),
+		primary:        newFalsePointer(),
+		visible:        newTruePointer(),
+		comment:        newEmptyStringPointer(),
+	}
+	return nil
+}
+
+func (d *DatabaseState) pgDropIndex(node *ast.DropIndexStmt) *WalkThroughError {
+	for _, index := range node.IndexList {
+		schemaName := ""
+		if index.Table != nil {
+			schemaName = index.Table.Schema
+		}
+		schema := d.pgLookupIndexSchema(schemaName, index.Name)
+
+		table := schema.pgFindIndexTable(index.Name)
+		if table == nil {
+			if node.IfExists || !schema.ctx.CheckIntegrity {
+				continue
+			}
+			return NewIndexNotExistsInSchemaError(schema.name, index.Name)
+		}
+		delete(table.indexSet, index.Name)
+	}
+	return nil
+}
+
+func (d *DatabaseState) pgRenameIndex(node *ast.RenameIndexStmt) *WalkThroughError {
+	schemaName := ""
+	if node.Table != nil {
+		schemaName = node.Table.Schema
+	}
+	schema := d.pgLookupIndexSchema(schemaName, node.IndexName)
+
+	table := schema.pgFindIndexTable(node.IndexName)
+	if table == nil {
+		if schema.ctx.CheckIntegrity {
+			return NewIndexNotExistsInSchemaError(schema.name, node.IndexName)
+		}
+		// We don't know the table of the index, so ignore it.
+		return nil
+	}
+	return schema.pgRenameIndex(table, node.IndexName, node.NewName)
+}
+
+func (d *DatabaseState) pgCheckRelationExists(tableDef *ast.TableDef) *WalkThroughError {
+	schema, err := d.pgLookupSchema(tableDef, func(schema *SchemaState) bool {
+		return schema.pgRelationExists(tableDef.Name)
+	})
+	if err != nil {
+		return err
+	}
+	if _, exists := schema.viewSet[tableDef.Name]; exists {
+		return nil
+	}
+	if _, exists := schema.tableSet[tableDef.Name]; !exists && schema.ctx.CheckIntegrity {
+		return NewTableNotExistsError(pgQualifiedName(schema.name, tableDef.Name))
+	}
+	return nil
+}
+
+func (d *DatabaseState) pgCreateColumn(schema *SchemaState, table *TableState, column *ast.ColumnDef) *WalkThroughError {
+	if _, exists := table.columnSet[column.ColumnName]; exists {
+		return &WalkThroughError{
+			Type:    ErrorTypeColumnExists,
+			Content: fmt.Sprintf("Column `%s` already exists in table `%s`", column.ColumnName, table.name),
+		}
+	}
+
+	pos := len(table.columnSet) + 1
+	col := &ColumnState{
+		name:         column.ColumnName,
+		position:     &pos,
+		defaultValue: nil,
+		nullable:     newTruePointer(),
+		columnType:   newStringPointer(pgDataTypeString(column.Type)),
+		characterSet: newEmptyStringPointer(),
+		collation:    newEmptyStringPointer(),
+		comment:      newEmptyStringPointer(),
+	}
+	table.columnSet[col.name] = col
+
+	for _, constraint := range column.ConstraintList {
+		if err := d.pgCreateConstraint(schema, table, constraint); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *DatabaseState) pgCreateConstraint(schema *SchemaState, table *TableState, constraint *ast.ConstraintDef) *WalkThroughError {
+	switch constraint.Type {
+	case ast.ConstraintTypePrimary:
+		keyList, err := table.pgValidateKeyList(schema.ctx, constraint.KeyList, true /* primary */)
+		if err != nil {
+			return err
+		}
+		name := constraint.Name
+		if name == "" {
+			name = schema.pgGenerateRelationName(table.name, nil /* keyList */, "pkey")
+		}
+		return schema.pgCreateIndex(table, name, keyList, true /* unique */, true /* primary */)
+	case ast.ConstraintTypeUnique:
+		keyList, err := table.pgValidateKeyList(schema.ctx, constraint.KeyList, false /* primary */)
+		if err != nil {
+			return err
+		}
+		name := constraint.Name
+		if name == "" {
+			name = schema.pgGenerateRelationName(table.name, keyList, "key")
+		}
+		return schema.pgCreateIndex(table, name, keyList, true /* unique */, false /* primary */)
+	case ast.ConstraintTypePrimaryUsingIndex, ast.ConstraintTypeUniqueUsingIndex:
+		return schema.pgCreateConstraintUsingIndex(table, constraint)
+	case ast.ConstraintTypeNotNull:
+		for _, key := range constraint.KeyList {
+			if column, exists := table.columnSet[key]; exists {
+				column.nullable = newFalsePointer()
+			}
+		}
+	case ast.ConstraintTypeForeign:
+		if _, err := table.pgValidateKeyList(schema.ctx, constraint.KeyList, false /* primary */); err != nil {
+			return err
+		}
+		_, referencedTable, err := d.pgFindTableState(constraint.Foreign.Table, false /* createIncompleteTable */)
+		if err != nil {
+			return err
+		}
+		if referencedTable != nil {
+			if _, err := referencedTable.pgValidateKeyList(schema.ctx, constraint.Foreign.ColumnList, false /* primary */); err != nil {
+				return err
+			}
+		}
+	case ast.ConstraintTypeCheck:
+		// we do not deal with CHECK constraints
+	case ast.ConstraintTypeDefault:
+		// we do not deal with DEFAULT, because the AST doesn't keep the default expression
+	}
+	return nil
+}
+
+func (d *DatabaseState) pgSetTableSchema(schema *SchemaState, table *TableState, newSchema string) *WalkThroughError {
+	target := d.pgGetOrCreateSchema(newSchema)
+	if target == schema {
+		return nil
+	}
+	if target.pgRelationExists(table.name) {
+		return NewTableExistsError(pgQualifiedName(target.name, table.name))
+	}
+	// The indexes are moved to the new schema along with the table.
+	for indexName := range table.indexSet {
+		if target.pgRelationExists(indexName) {
+			return NewIndexExistsError(table.name, indexName)
+		}
+	}
+	delete(schema.tableSet, table.name)
+	target.tableSet[table.name] = table
+	return nil
+}
+
+func (d *DatabaseState) pgSetVariable(node *ast.VariableSetStmt) {
+	if node.Name != pgSearchPathVariable {
+		return
+	}
+	if len(node.ValueList) == 0 {
+		// SET search_path TO DEFAULT and RESET search_path.
+		d.searchPath = nil
+		return
+	}
+	searchPath := []string{}
+	for _, value := range node.ValueList {
+		// SET search_path = 's1, s2' sets the schemas in a single string.
+		for _, name := range strings.Split(value, ",") {
+			name = strings.Trim(strings.TrimSpace(name), `"`)
+			// We don't know the current user, and the system schemas are not in the catalog.
+			if name == "" || name == "$user" || name == "pg_catalog" || strings.HasPrefix(name, "pg_temp") {
+				continue
+			}
+			searchPath = append(searchPath, name)
+		}
+	}
+	d.searchPath = searchPath
+}
+
+// pgCreationSchemaName returns the schema to create objects without the schema name in, i.e., the first schema of the search path.
+// We fall back to the "public" schema if the search path has no schema to create in.
+func (d *DatabaseState) pgCreationSchemaName() string {
+	if len(d.searchPath) == 0 {
+		return PostgreSQLPublicSchema
+	}
+	return d.searchPath[0]
+}
+
+// pgFindSchema finds the schema to create the table in, the empty schema name means the first schema of the search path.
+// The catalog only records the schemas with tables or views, so we create the schema if it does not exist.
+func (d *DatabaseState) pgFindSchema(tableDef *ast.TableDef) (*SchemaState, *WalkThroughError) {
+	if tableDef.Database != "" && tableDef.Database != d.name {
+		return nil, NewAccessOtherDatabaseError(d.name, tableDef.Database)
+	}
+	return d.pgGetOrCreateSchema(tableDef.Schema), nil
+}
+
+// pgLookupSchema finds the schema of the existing table or view.
+// For the name without the schema name, it returns the first schema in the search path for which exists returns true,
+// or the schema to create in if there is no such schema.
+func (d *DatabaseState) pgLookupSchema(tableDef *ast.TableDef, exists func(*SchemaState) bool) (*SchemaState, *WalkThroughError) {
+	if tableDef.Database != "" && tableDef.Database != d.name {
+		return nil, NewAccessOtherDatabaseError(d.name, tableDef.Database)
+	}
+	if tableDef.Schema != "" {
+		return d.pgGetOrCreateSchema(tableDef.Schema), nil
+	}
+	for _, name := range d.pgSearchPath() {
+		if schema, ok := d.schemaSet[name]; ok && exists(schema) {
+			return schema, nil
+		}
+	}
+	return d.pgGetOrCreateSchema(""), nil
+}
+
+// pgLookupIndexSchema finds the schema of the existing index, the same as pgLookupSchema.
+func (d *DatabaseState) pgLookupIndexSchema(schemaName string, indexName string) *SchemaState {
+	if schemaName != "" {
+		return d.pgGetOrCreateSchema(schemaName)
+	}
+	for _, name := range d.pgSearchPath() {
+		if schema, ok := d.schemaSet[name]; ok && schema.pgFindIndexTable(indexName) != nil {
+			return schema
+		}
+	}
+	return d.pgGetOrCreateSchema("")
+}
+
+func (d *DatabaseState) pgSearchPath() []string {
+	if d.searchPath == nil {
+		return []string{PostgreSQLPublicSchema}
+	}
+	return d.searchPath
+}
+
+func (d *DatabaseState) pgGetOrCreateSchema(name string) *SchemaState {
+	if name == "" {
+		name = d.pgCreationSchemaName()
+	}
+	schema, exists := d.schemaSet[name]
+	if !exists {
+		schema = d.createSchema(name)
+	}
+	return schema
+}
+
+func (d *DatabaseState) pgFindTableState(tableDef *ast.TableDef, createIncompleteTable bool) (*SchemaState, *TableState, *WalkThroughError) {
+	schema, err := d.pgLookupSchema(tableDef, func(schema *SchemaState) bool {
+		_, exists := schema.tableSet[tableDef.Name]
+		return exists
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	table, exists := schema.tableSet[tableDef.Name]
+	if !exists {
+		if schema.ctx.CheckIntegrity {
+			return nil, nil, NewTableNotExistsError(pgQualifiedName(schema.name, tableDef.Name))
+		}
+		if !createIncompleteTable {
+			return schema, nil, nil
+		}
+		table = schema.createIncompleteTable(tableDef.Name)
+	}
+	return schema, table, nil
+}
+
+// pgRelationExists returns true if there is a table, view or index with the name in the schema.
+func (s *SchemaState) pgRelationExists(name string) bool {
+	if _, exists := s.tableSet[name]; exists {
+		return true
+	}
+	if _, exists := s.viewSet[name]; exists {
+		return true
+	}
+	return s.pgFindIndexTable(name) != nil
+}
+
+// pgFindIndexTable returns the table of the index, or nil if the index does not exist in the schema.
+func (s *SchemaState) pgFindIndexTable(indexName string) *TableState {
+	for _, table := range s.tableSet {
+		if _, exists := table.indexSet[indexName]; exists {
+			return table
+		}
+	}
+	return nil
+}
+
+// pgGenerateRelationName generates the name for the index created without name, in the same way as PostgreSQL.
+// The name is made up of the table name, the key names and the suffix, such as "tbl_a_b_key".
+// PostgreSQL appends a number to the name if it's taken, such as "tbl_a_b_key1".
+func (s *SchemaState) pgGenerateRelationName(tableName string, keyList []string, suffix string) string {
+	nameList := []string{tableName}
+	for _, key := range keyList {
+		// The key of the expression is empty.
+		if key == "" {
+			key = "expr"
+		}
+		nameList = append(nameList, key)
+	}
+	prefix := strings.Join(nameList, "_")
+
+	name := fmt.Sprintf("%s_%s", prefix, suffix)
+	for i := 1; s.pgRelationExists(name); i++ {
+		name = fmt.Sprintf("%s_%s%d", prefix, suffix, i)
+	}
+	return name
+}
+
+func (s *SchemaState) pgCreateIndex(table *TableState, name string, keyList []string, unique bool, primary bool) *WalkThroughError {
+	if primary && table.pgPrimaryKey() != nil {
+		return &WalkThroughError{
+			Type:    ErrorTypePrimaryKeyExists,
+			Content: fmt.Sprintf("Primary key exists in table `%s`", table.name),
+		}
+	}
+	if s.pgRelationExists(name) {
+		return NewIndexExistsError(table.name, name)
+	}
+
+	table.indexSet[name] = &IndexState{
+		name:           name,
+		expressionList: keyList,
+		indextype:      newStringPointer(pgIndexTypeBtree),
+		unique:         newBoolPointer(unique),
+		primary:        newBoolPointer(primary),
+		visible:        newTruePointer(),
+		comment:        newEmptyStringPointer(),
+	}
+	return nil
+}
+
+// pgCreateConstraintUsingIndex turns the existing unique index into the PRIMARY KEY or UNIQUE constraint.
+// The index is renamed to the constraint name if the constraint name is specified.
+func (s *SchemaState) pgCreateConstraintUsingIndex(table *TableState, constraint *ast.ConstraintDef) *WalkThroughError {
+	primary := constraint.Type == ast.ConstraintTypePrimaryUsingIndex
+	if primary && table.pgPrimaryKey() != nil {
+		return &WalkThroughError{
+			Type:    ErrorTypePrimaryKeyExists,
+			Content: fmt.Sprintf("Primary key exists in table `%s`", table.name),
+		}
+	}
+
+	index, exists := table.indexSet[constraint.IndexName]
+	if !exists {
+		if s.ctx.CheckIntegrity {
+			return NewIndexNotExistsError(table.name, constraint.IndexName)
+		}
+		index = table.createIncompleteIndex(constraint.IndexName)
+	}
+	index.unique = newTruePointer()
+	if primary {
+		index.primary = newTruePointer()
+		for _, key := range index.expressionList {
+			if column, exists := table.columnSet[key]; exists {
+				column.nullable = newFalsePointer()
+			}
+		}
+	}
+
+	if constraint.Name != "" {
+		return s.pgRenameIndex(table, index.name, constraint.Name)
+	}
+	return nil
+}
+
+func (s *SchemaState) pgRenameIndex(table *TableState, oldName string, newName string) *WalkThroughError {
+	if oldName == newName {
+		return nil
+	}
+	if s.pgRelationExists(newName) {
+		return NewIndexExistsError(table.name, newName)
+	}
+
+	index := table.indexSet[oldName]
+	delete(table.indexSet, oldName)
+	index.name = newName
+	table.indexSet[newName] = index
+	return nil
+}
+
+func (s *SchemaState) pgRenameTable(table *TableState, newName string) *WalkThroughError {
+	if table.name == newName {
+		return nil
+	}
+	if s.pgRelationExists(newName) {
+		return NewTableExistsError(pgQualifiedName(s.name, newName))
+	}
+
+	delete(s.tableSet, table.name)
+	table.name = newName
+	s.tableSet[newName] = table
+	return nil
+}
+
+func (t *TableState) pgPrimaryKey() *IndexState {
+	for _, index := range t.indexSet {
+		if index.Primary() {
+			return index
+		}
+	}
+	return nil
+}
+
+func (t *TableState) pgFindColumnState(ctx *FinderContext, columnName string) (*ColumnState, *WalkThroughError) {
+	column, exists := t.columnSet[columnName]
+	if !exists {
+		if ctx.CheckIntegrity {
+			return nil, NewColumnNotExistsError(t.name, columnName)
+		}
+		column = t.createIncompleteColumn(columnName)
+	}
+	return column, nil
+}
+
+func (t *TableState) pgValidateKeyList(ctx *FinderContext, keyList []string, primary bool) ([]string, *WalkThroughError) {
+	for _, key := range keyList {
+		column, exists := t.columnSet[key]
+		if !exists {
+			if ctx.CheckIntegrity {
+				return nil, NewColumnNotExistsError(t.name, key)
+			}
+			continue
+		}
+		if primary {
+			column.nullable = newFalsePointer()
+		}
+	}
+	return copyStringSlice(keyList), nil
+}
+
+// pgDropColumn drops the column in the table.
+// Unlike MySQL, PostgreSQL drops the indexes and constraints involving the column rather than shrinking them,
+// and allows dropping all columns of a table.
+func (t *TableState) pgDropColumn(ctx *FinderContext, columnName string) *WalkThroughError {
+	column, exists := t.columnSet[columnName]
+	if !exists {
+		if ctx.CheckIntegrity {
+			return NewColumnNotExistsError(t.name, columnName)
+		}
+		return nil
+	}
+
+	for _, index := range t.indexSet {
+		for _, key := range index.expressionList {
+			if key == columnName {
+				delete(t.indexSet, index.name)
+				break
+			}
+		}
+	}
+
+	if column.position != nil {
+		for _, col := range t.columnSet {
+			if col.position != nil && *col.position > *column.position {
+				*col.position--
+			}
+		}
+	}
+
+	delete(t.columnSet, columnName)
+	return nil
+}
+
+// pgDataTypeString returns the data type in lower case, such as "int4" and "varchar".
+// The type modifiers of the unconverted data types are lost in the AST.
+func pgDataTypeString(tp ast.DataType) string {
+	switch tp := tp.(type) {
+	case nil:
+		return ""
+	case *ast.UnconvertedDataType:
+		return strings.Join(tp.Name, ".")
+	}
+	res, err := parser.Deparse(parser.Postgres, parser.DeparseContext{}, tp)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(res)
+}
+
+func pgQualifiedName(schemaName string, name string) string {
+	return fmt.Sprintf("%s.%s", schemaName, name)
+}
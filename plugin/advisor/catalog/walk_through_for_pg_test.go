@@ -0,0 +1,302 @@
+package catalog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bytebase/bytebase/plugin/advisor/db"
+	// Register postgresql parser engine.
+	_ "github.com/bytebase/bytebase/plugin/parser/engine/pg"
+)
+
+func TestPGWalkThrough(t *testing.T) {
+	originTable := &Database{
+		Name:   "test",
+		DbType: db.Postgres,
+		SchemaList: []*Schema{
+			{
+				Name: "public",
+				TableList: []*Table{
+					{
+						Name: "t",
+						ColumnList: []*Column{
+							{Name: "id", Position: 1, Type: "integer"},
+						},
+						IndexList: []*Index{
+							{
+								Name:           "t_pkey",
+								ExpressionList: []string{"id"},
+								Type:           "btree",
+								Unique:         true,
+								Primary:        true,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	tests := []testData{
+		{
+			origin: &Database{
+				Name:   "test",
+				DbType: db.Postgres,
+			},
+			statement: `
+				UPDATE t SET a = 1;
+			`,
+			err: &WalkThroughError{
+				Type:    ErrorTypeTableNotExists,
+				Content: "Table `public.t` does not exist",
+				Line:    2,
+			},
+		},
+		{
+			origin: originTable,
+			statement: `
+				DROP TABLE s.t;
+			`,
+			err: &WalkThroughError{
+				Type:    ErrorTypeTableNotExists,
+				Content: "Table `s.t` does not exist",
+				Line:    2,
+			},
+		},
+		{
+			origin: originTable,
+			statement: `
+				ALTER TABLE t ADD COLUMN name text;
+				ALTER TABLE public.t ADD COLUMN name text;
+			`,
+			err: &WalkThroughError{
+				Type:    ErrorTypeColumnExists,
+				Content: "Column `name` already exists in table `t`",
+				Line:    3,
+			},
+		},
+		{
+			origin: originTable,
+			statement: `
+				ALTER TABLE t ADD CONSTRAINT t_pk PRIMARY KEY (id);
+			`,
+			err: &WalkThroughError{
+				Type:    ErrorTypePrimaryKeyExists,
+				Content: "Primary key exists in table `t`",
+				Line:    2,
+			},
+		},
+		{
+			origin: originTable,
+			statement: `
+				CREATE TABLE t2(a int);
+				CREATE INDEX t_pkey ON t2(a);
+			`,
+			err: &WalkThroughError{
+				Type:    ErrorTypeIndexExists,
+				Content: "Index `t_pkey` already exists in table `t2`",
+				Line:    3,
+			},
+		},
+		{
+			origin: originTable,
+			statement: `
+				CREATE TABLE t2(
+					a int,
+					b int REFERENCES t(c)
+				);
+			`,
+			err: &WalkThroughError{
+				Type:    ErrorTypeColumnNotExists,
+				Content: "Column `c` does not exist in table `t`",
+				Line:    4,
+			},
+		},
+		{
+			origin: originTable,
+			statement: `
+				CREATE SCHEMA public;
+			`,
+			err: &WalkThroughError{
+				Type:    ErrorTypeSchemaExists,
+				Content: "Schema `public` already exists",
+				Line:    2,
+			},
+		},
+		{
+			origin: originTable,
+			statement: `
+				CREATE VIEW t AS SELECT 1;
+			`,
+			err: &WalkThroughError{
+				Type:    ErrorTypeTableExists,
+				Content: "Table `public.t` already exists",
+				Line:    2,
+			},
+		},
+		{
+			origin: originTable,
+			statement: `
+				DROP VIEW IF EXISTS v1;
+				DROP VIEW v2;
+			`,
+			err: &WalkThroughError{
+				Type:    ErrorTypeViewNotExists,
+				Content: "View `public.v2` does not exist",
+				Line:    3,
+			},
+		},
+		{
+			origin: originTable,
+			statement: `
+				DROP INDEX IF EXISTS s.t_pkey;
+				DROP INDEX s.t_pkey;
+			`,
+			err: &WalkThroughError{
+				Type:    ErrorTypeIndexNotExists,
+				Content: "Index `t_pkey` does not exist in schema `s`",
+				Line:    3,
+			},
+		},
+		{
+			origin: originTable,
+			statement: `
+				CREATE SCHEMA s;
+				CREATE TABLE s.t(
+					id int PRIMARY KEY,
+					name varchar(20) NOT NULL,
+					UNIQUE (name)
+				);
+				CREATE INDEX ON s.t(name);
+				ALTER TABLE s.t ADD COLUMN IF NOT EXISTS id int, ADD COLUMN age int, DROP COLUMN name;
+				ALTER TABLE s.t RENAME COLUMN age TO years;
+				CREATE UNIQUE INDEX ON s.t(years);
+				ALTER INDEX s.t_pkey RENAME TO pk_t;
+				CREATE VIEW s.v AS SELECT id FROM s.t;
+				DROP TABLE t;
+			`,
+			want: &Database{
+				Name:   "test",
+				DbType: db.Postgres,
+				SchemaList: []*Schema{
+					{
+						Name: "public",
+					},
+					{
+						Name: "s",
+						TableList: []*Table{
+							{
+								Name: "t",
+								ColumnList: []*Column{
+									{
+										Name:     "id",
+										Position: 1,
+										Nullable: false,
+										Type:     "int4",
+									},
+									{
+										Name:     "years",
+										Position: 2,
+										Nullable: true,
+										Type:     "int4",
+									},
+								},
+								IndexList: []*Index{
+									{
+										Name:           "pk_t",
+										ExpressionList: []string{"id"},
+										Type:           "btree",
+										Unique:         true,
+										Primary:        true,
+										Visible:        true,
+									},
+									{
+										Name:           "t_years_key",
+										ExpressionList: []string{"years"},
+										Type:           "btree",
+										Unique:         true,
+										Visible:        true,
+									},
+								},
+							},
+						},
+						ViewList: []*View{
+							{
+								Name:       "v",
+								Definition: "SELECT id FROM s.t",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		state := newDatabaseState(test.origin, &FinderContext{CheckIntegrity: true})
+		err := state.WalkThrough(test.statement)
+		if test.err != nil {
+			require.Equal(t, test.err, err, test.statement)
+			continue
+		}
+		require.NoError(t, err, test.statement)
+		want := newDatabaseState(test.want, &FinderContext{CheckIntegrity: true})
+		require.Equal(t, want, state, test.statement)
+	}
+}
+
+func TestPGWalkThroughForIncompleteOriginalCatalog(t *testing.T) {
+	finder := NewEmptyFinder(&FinderContext{CheckIntegrity: false}, db.Postgres)
+	err := finder.WalkThrough(`
+		DROP TABLE t1;
+		ALTER TABLE t2 ADD CONSTRAINT uk_t2_a UNIQUE (a);
+		INSERT INTO t3 VALUES (1);
+	`)
+	require.NoError(t, err)
+
+	_, index := finder.Final.FindIndex(&IndexFind{SchemaName: "public", IndexName: "uk_t2_a"})
+	require.NotNil(t, index)
+	require.Equal(t, []string{"a"}, index.ExpressionList())
+	require.True(t, index.Unique())
+	require.Nil(t, finder.Final.FindTable(&TableFind{SchemaName: "public", TableName: "t3"}))
+}
+
+func TestPGWalkThroughWithSearchPath(t *testing.T) {
+	finder := NewEmptyFinder(&FinderContext{CheckIntegrity: true}, db.Postgres)
+	err := finder.WalkThrough(`
+		CREATE SCHEMA s1;
+		SET search_path TO "$user", s1, public;
+		CREATE TABLE t1(a int);
+		CREATE INDEX idx_a ON t1(a);
+		SET search_path = 's2, public';
+		CREATE TABLE t2(b int);
+		RESET search_path;
+		ALTER TABLE s1.t1 ADD COLUMN c int;
+		SET search_path TO s1, public;
+		INSERT INTO t1 VALUES (1, 2);
+		ALTER INDEX idx_a RENAME TO idx_t1_a;
+	`)
+	require.NoError(t, err)
+
+	require.NotNil(t, finder.Final.FindTable(&TableFind{SchemaName: "s1", TableName: "t1"}))
+	require.Nil(t, finder.Final.FindTable(&TableFind{SchemaName: "public", TableName: "t1"}))
+	require.NotNil(t, finder.Final.FindTable(&TableFind{SchemaName: "s2", TableName: "t2"}))
+	tableName, index := finder.Final.FindIndex(&IndexFind{SchemaName: "s1", IndexName: "idx_t1_a"})
+	require.Equal(t, "t1", tableName)
+	require.NotNil(t, index)
+
+	finder = NewEmptyFinder(&FinderContext{CheckIntegrity: true}, db.Postgres)
+	err = finder.WalkThrough(`
+		CREATE SCHEMA s1;
+		CREATE TABLE s1.t1(a int);
+		SET search_path TO s1;
+		INSERT INTO t1 VALUES (1);
+		RESET search_path;
+		INSERT INTO t1 VALUES (1);
+	`)
+	require.Equal(t, &WalkThroughError{
+		Type:    ErrorTypeTableNotExists,
+		Content: "Table `public.t1` does not exist",
+		Line:    7,
+	}, err)
+}
@@ -82,6 +82,7 @@ const (
 	DatabaseNotEmpty   Code = 701
 	NotCurrentDatabase Code = 702
 	DatabaseIsDeleted  Code = 703
+	SchemaExists       Code = 704
 
 	// 801 ~ 899 index error code.
 	NotUseIndex                Code = 801
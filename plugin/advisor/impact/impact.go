@@ -0,0 +1,196 @@
+// Package impact analyzes the lock and rewrite impact of the migration statements before they are deployed.
+//
+// Each statement touching an existing table is classified by the lock it takes on the table, i.e. whether it blocks
+// the reads or writes, and by the work it does on the table data, i.e. metadata-only, a full scan or a full rewrite.
+// The duration of the scan or rewrite is estimated from the synced table statistics.
+package impact
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/blang/semver/v4"
+	"github.com/pkg/errors"
+
+	"github.com/bytebase/bytebase/plugin/advisor/catalog"
+	advisorDB "github.com/bytebase/bytebase/plugin/advisor/db"
+)
+
+// LockLevel is the level of the lock taken on the table by a statement.
+type LockLevel string
+
+const (
+	// LockLevelNone is the lock level which blocks neither the reads nor the writes on the table.
+	// The statement may still hold a brief exclusive lock at the start and the end.
+	LockLevelNone LockLevel = "NONE"
+	// LockLevelShared is the lock level which allows the reads but blocks the writes on the table.
+	LockLevelShared LockLevel = "SHARED"
+	// LockLevelExclusive is the lock level which blocks both the reads and the writes on the table.
+	LockLevelExclusive LockLevel = "EXCLUSIVE"
+)
+
+func (l LockLevel) level() int {
+	switch l {
+	case LockLevelNone:
+		return 0
+	case LockLevelShared:
+		return 1
+	case LockLevelExclusive:
+		return 2
+	}
+	return -1
+}
+
+// Operation is the work done on the table data by a statement.
+type Operation string
+
+const (
+	// OperationMetadata is the operation which only changes the metadata of the table.
+	OperationMetadata Operation = "METADATA"
+	// OperationScan is the operation which scans the whole table without rewriting it, e.g. building an index or validating a constraint.
+	OperationScan Operation = "SCAN"
+	// OperationRewrite is the operation which rewrites the whole table and its indexes.
+	OperationRewrite Operation = "REWRITE"
+)
+
+func (o Operation) level() int {
+	switch o {
+	case OperationMetadata:
+		return 0
+	case OperationScan:
+		return 1
+	case OperationRewrite:
+		return 2
+	}
+	return -1
+}
+
+const (
+	// The rough throughputs used to estimate the duration of the table scans and rewrites.
+	// The actual throughput varies with the hardware and the workload a lot, so the estimation is only an order of magnitude.
+	scanBytesPerSecond    = 128 << 20
+	scanRowsPerSecond     = 500_000
+	rewriteBytesPerSecond = 32 << 20
+	rewriteRowsPerSecond  = 100_000
+)
+
+var (
+	versionRegexp = regexp.MustCompile(`^\d+(\.\d+){0,2}`)
+)
+
+// Context is the context for the impact analysis.
+type Context struct {
+	DbType advisorDB.Type
+	// EngineVersion is the version of the database server, e.g. "8.0.28" or "14.5".
+	EngineVersion string
+	// Database is the synced schema of the database with the table statistics. It can be nil.
+	Database *catalog.Database
+}
+
+// Impact is the lock and rewrite impact of a statement.
+type Impact struct {
+	Statement string
+	// Line is the last line of the statement.
+	Line   int
+	Schema string
+	Table  string
+	Lock   LockLevel
+	// LockMode is the engine specific lock mode, e.g. "ACCESS EXCLUSIVE" for Postgres and "ALGORITHM=INPLACE, LOCK=NONE" for MySQL.
+	LockMode  string
+	Operation Operation
+	// ReasonList explains the lock and operation of each part of the statement.
+	ReasonList []string
+	// RowCount and DataSize are the synced statistics of the table, which are 0 if the table is not synced.
+	RowCount int64
+	DataSize int64
+	// EstimatedDuration is the estimated duration of scanning or rewriting the table.
+	EstimatedDuration time.Duration
+}
+
+// Analyze analyzes the lock and rewrite impact of the statements touching the existing tables.
+func Analyze(ctx Context, statement string) ([]*Impact, error) {
+	var impactList []*Impact
+	var err error
+	switch ctx.DbType {
+	case advisorDB.MySQL:
+		impactList, err = analyzeMySQL(ctx, statement)
+	case advisorDB.Postgres:
+		impactList, err = analyzePostgres(ctx, statement)
+	default:
+		return nil, errors.Errorf("lock impact analysis is not supported for %s", ctx.DbType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, impact := range impactList {
+		if table := findTable(ctx.Database, impact.Schema, impact.Table); table != nil {
+			impact.RowCount = table.RowCount
+			impact.DataSize = table.DataSize
+			impact.EstimatedDuration = estimateDuration(impact.Operation, table)
+		}
+	}
+	return impactList, nil
+}
+
+// raise raises the lock level and the operation of the impact, and records the reason.
+func (impact *Impact) raise(lock LockLevel, operation Operation, reason string) {
+	if lock.level() > impact.Lock.level() {
+		impact.Lock = lock
+	}
+	if operation.level() > impact.Operation.level() {
+		impact.Operation = operation
+	}
+	impact.ReasonList = append(impact.ReasonList, reason)
+}
+
+// estimateDuration estimates the duration of the operation on the table from its statistics.
+func estimateDuration(operation Operation, table *catalog.Table) time.Duration {
+	var bytesPerSecond, rowsPerSecond float64
+	size := table.DataSize
+	switch operation {
+	case OperationScan:
+		bytesPerSecond, rowsPerSecond = scanBytesPerSecond, scanRowsPerSecond
+	case OperationRewrite:
+		bytesPerSecond, rowsPerSecond = rewriteBytesPerSecond, rewriteRowsPerSecond
+		// The indexes are rebuilt as well.
+		size += table.IndexSize
+	default:
+		return 0
+	}
+	// The statistics may be stale or partially supported, so take the larger estimation of the two.
+	seconds := float64(size) / bytesPerSecond
+	if rowSeconds := float64(table.RowCount) / rowsPerSecond; rowSeconds > seconds {
+		seconds = rowSeconds
+	}
+	return time.Duration(seconds * float64(time.Second)).Round(time.Second)
+}
+
+// findTable finds the table in the database, and returns nil if not found.
+func findTable(database *catalog.Database, schemaName, tableName string) *catalog.Table {
+	if database == nil {
+		return nil
+	}
+	for _, schema := range database.SchemaList {
+		if schema.Name != schemaName {
+			continue
+		}
+		for _, table := range schema.TableList {
+			if table.Name == tableName {
+				return table
+			}
+		}
+	}
+	return nil
+}
+
+// parseVersion parses the leading version number of the engine version, e.g. "5.7.38-log" and "14.5 (Debian 14.5-1)".
+// It returns the zero version if the version is unknown, and the callers should assume the oldest behavior then.
+func parseVersion(engineVersion string) semver.Version {
+	v, err := semver.ParseTolerant(versionRegexp.FindString(strings.TrimSpace(engineVersion)))
+	if err != nil {
+		return semver.Version{}
+	}
+	return v
+}
@@ -0,0 +1,59 @@
+package impact
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bytebase/bytebase/plugin/advisor/catalog"
+)
+
+func TestEstimateDuration(t *testing.T) {
+	tests := []struct {
+		operation Operation
+		table     *catalog.Table
+		want      time.Duration
+	}{
+		{
+			operation: OperationMetadata,
+			table:     &catalog.Table{RowCount: 100_000_000, DataSize: 100 << 30},
+			want:      0,
+		},
+		{
+			operation: OperationScan,
+			table:     &catalog.Table{RowCount: 1000, DataSize: 1280 << 20},
+			want:      10 * time.Second,
+		},
+		{
+			operation: OperationScan,
+			table:     &catalog.Table{RowCount: 5_000_000, DataSize: 128 << 20},
+			want:      10 * time.Second,
+		},
+		{
+			operation: OperationRewrite,
+			table:     &catalog.Table{RowCount: 1000, DataSize: 256 << 20, IndexSize: 64 << 20},
+			want:      10 * time.Second,
+		},
+	}
+
+	for _, test := range tests {
+		require.Equal(t, test.want, estimateDuration(test.operation, test.table), test.operation)
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{version: "8.0.28", want: "8.0.28"},
+		{version: "5.7.38-log", want: "5.7.38"},
+		{version: "14.5 (Debian 14.5-1.pgdg110+1)", want: "14.5.0"},
+		{version: "", want: "0.0.0"},
+	}
+
+	for _, test := range tests {
+		require.Equal(t, test.want, parseVersion(test.version).String(), test.version)
+	}
+}
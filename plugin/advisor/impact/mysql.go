@@ -0,0 +1,522 @@
+package impact
+
+// This file implements the impact analysis for MySQL.
+// The algorithms and locks of the ALTER TABLE operations follow the online DDL operations of InnoDB,
+// https://dev.mysql.com/doc/refman/8.0/en/innodb-online-ddl-operations.html, and the strongest algorithm
+// of the operations is taken for the whole statement.
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	tidbparser "github.com/pingcap/tidb/parser"
+	tidbast "github.com/pingcap/tidb/parser/ast"
+	tidbmysql "github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/parser/types"
+	"github.com/pkg/errors"
+
+	"github.com/bytebase/bytebase/plugin/advisor/catalog"
+	"github.com/bytebase/bytebase/plugin/parser"
+)
+
+// mysqlAlgorithm is the ALTER TABLE algorithm of MySQL, ordered by cost.
+type mysqlAlgorithm int
+
+const (
+	mysqlAlgorithmUnknown mysqlAlgorithm = iota - 1
+	mysqlAlgorithmInstant
+	mysqlAlgorithmInplace
+	mysqlAlgorithmCopy
+)
+
+var (
+	mysqlVersion57     = semver.MustParse("5.7.0")
+	mysqlVersion80     = semver.MustParse("8.0.0")
+	mysqlVersion8012   = semver.MustParse("8.0.12")
+	mysqlVersion8028   = semver.MustParse("8.0.28")
+	mysqlVersion8029   = semver.MustParse("8.0.29")
+	mysqlCharsetMaxLen = map[string]int{
+		"ascii":   1,
+		"binary":  1,
+		"latin1":  1,
+		"utf8":    3,
+		"utf8mb3": 3,
+		"utf8mb4": 4,
+	}
+)
+
+func (a mysqlAlgorithm) String() string {
+	switch a {
+	case mysqlAlgorithmInstant:
+		return "INSTANT"
+	case mysqlAlgorithmInplace:
+		return "INPLACE"
+	case mysqlAlgorithmCopy:
+		return "COPY"
+	}
+	return ""
+}
+
+type mysqlAnalyzer struct {
+	version  semver.Version
+	database *catalog.Database
+}
+
+// mysqlImpact tracks the strongest MySQL algorithm of the impact.
+type mysqlImpact struct {
+	*Impact
+	algorithm mysqlAlgorithm
+}
+
+func (a *mysqlAnalyzer) newImpact(table *tidbast.TableName) *mysqlImpact {
+	impact := &mysqlImpact{
+		Impact: &Impact{
+			Table:     table.Name.O,
+			Lock:      LockLevelNone,
+			Operation: OperationMetadata,
+		},
+		algorithm: mysqlAlgorithmUnknown,
+	}
+	// The synced schema only covers the current database.
+	if a.database != nil && table.Schema.O != "" && table.Schema.O != a.database.Name {
+		impact.Schema = table.Schema.O
+	}
+	return impact
+}
+
+func (impact *mysqlImpact) raise(algorithm mysqlAlgorithm, lock LockLevel, operation Operation, reason string) {
+	if algorithm > impact.algorithm {
+		impact.algorithm = algorithm
+	}
+	impact.Impact.raise(lock, operation, reason)
+	switch impact.algorithm {
+	case mysqlAlgorithmUnknown:
+		impact.LockMode = fmt.Sprintf("LOCK=%s", impact.Lock)
+	case mysqlAlgorithmInstant:
+		impact.LockMode = "ALGORITHM=INSTANT"
+	default:
+		impact.LockMode = fmt.Sprintf("ALGORITHM=%s, LOCK=%s", impact.algorithm, impact.Lock)
+	}
+}
+
+func analyzeMySQL(ctx Context, statement string) ([]*Impact, error) {
+	singleSQLs, err := parser.SplitMultiSQL(parser.MySQL, statement)
+	if err != nil {
+		return nil, err
+	}
+
+	analyzer := &mysqlAnalyzer{
+		version:  parseVersion(ctx.EngineVersion),
+		database: ctx.Database,
+	}
+	var impactList []*Impact
+	for _, singleSQL := range singleSQLs {
+		nodes, _, err := tidbparser.New().Parse(singleSQL.Text, "", "")
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse the statement at line %d", singleSQL.LastLine)
+		}
+		for _, node := range nodes {
+			for _, impact := range analyzer.analyze(node) {
+				impact.Statement = strings.TrimSpace(singleSQL.Text)
+				impact.Line = singleSQL.LastLine
+				impactList = append(impactList, impact.Impact)
+			}
+		}
+	}
+	return impactList, nil
+}
+
+// analyze returns the impacts of the statement on the existing tables, one for each table.
+func (a *mysqlAnalyzer) analyze(node tidbast.StmtNode) []*mysqlImpact {
+	switch n := node.(type) {
+	case *tidbast.AlterTableStmt:
+		return []*mysqlImpact{a.analyzeAlterTable(n)}
+	case *tidbast.CreateIndexStmt:
+		impact := a.newImpact(n.Table)
+		a.analyzeAddIndex(impact, n.KeyType == tidbast.IndexKeyTypeFullText, n.KeyType == tidbast.IndexKeyTypeSpatial)
+		return []*mysqlImpact{impact}
+	case *tidbast.DropIndexStmt:
+		impact := a.newImpact(n.Table)
+		impact.raise(mysqlAlgorithmInplace, LockLevelNone, OperationMetadata, fmt.Sprintf("DROP INDEX %q only changes the metadata", n.IndexName))
+		return []*mysqlImpact{impact}
+	case *tidbast.RenameTableStmt:
+		var impactList []*mysqlImpact
+		for _, tableToTable := range n.TableToTables {
+			impact := a.newImpact(tableToTable.OldTable)
+			impact.raise(a.instantSince(mysqlVersion80), LockLevelNone, OperationMetadata, "RENAME TABLE only changes the metadata")
+			impactList = append(impactList, impact)
+		}
+		return impactList
+	case *tidbast.TruncateTableStmt:
+		impact := a.newImpact(n.Table)
+		impact.raise(mysqlAlgorithmUnknown, LockLevelExclusive, OperationMetadata, "TRUNCATE TABLE drops and recreates the table")
+		return []*mysqlImpact{impact}
+	case *tidbast.DropTableStmt:
+		if n.IsView {
+			return nil
+		}
+		var impactList []*mysqlImpact
+		for _, table := range n.Tables {
+			impact := a.newImpact(table)
+			impact.raise(mysqlAlgorithmUnknown, LockLevelExclusive, OperationMetadata, "DROP TABLE removes the table")
+			impactList = append(impactList, impact)
+		}
+		return impactList
+	}
+	return nil
+}
+
+func (a *mysqlAnalyzer) analyzeAlterTable(node *tidbast.AlterTableStmt) *mysqlImpact {
+	impact := a.newImpact(node.Table)
+	table := findTable(a.database, impact.Schema, impact.Table)
+
+	// Dropping the primary key without adding a new one in the same statement requires a table copy.
+	addPrimaryKey := false
+	for _, spec := range node.Specs {
+		if spec.Tp == tidbast.AlterTableAddConstraint && spec.Constraint != nil && spec.Constraint.Tp == tidbast.ConstraintPrimaryKey {
+			addPrimaryKey = true
+		}
+	}
+
+	requestedAlgorithm, requestedLock := tidbast.AlgorithmTypeDefault, tidbast.LockTypeDefault
+	for _, spec := range node.Specs {
+		switch spec.Tp {
+		case tidbast.AlterTableAlgorithm:
+			requestedAlgorithm = spec.Algorithm
+		case tidbast.AlterTableLock:
+			requestedLock = spec.LockType
+		case tidbast.AlterTableDropPrimaryKey:
+			if addPrimaryKey {
+				impact.raise(mysqlAlgorithmInplace, LockLevelNone, OperationRewrite, "DROP PRIMARY KEY with ADD PRIMARY KEY rebuilds the table")
+			} else {
+				impact.raise(mysqlAlgorithmCopy, LockLevelShared, OperationRewrite, "DROP PRIMARY KEY without adding a new one copies the table")
+			}
+		default:
+			a.analyzeAlterTableSpec(impact, table, spec)
+		}
+	}
+
+	// The requested algorithm and lock only take effect if they are stronger than the required ones, otherwise MySQL rejects the statement.
+	switch requestedAlgorithm {
+	case tidbast.AlgorithmTypeCopy:
+		impact.raise(mysqlAlgorithmCopy, LockLevelShared, OperationRewrite, "ALGORITHM=COPY copies the table")
+	case tidbast.AlgorithmTypeInplace, tidbast.AlgorithmTypeInstant:
+		requested := mysqlAlgorithmInplace
+		if requestedAlgorithm == tidbast.AlgorithmTypeInstant {
+			requested = mysqlAlgorithmInstant
+		}
+		if requested < impact.algorithm {
+			impact.ReasonList = append(impact.ReasonList, fmt.Sprintf("ALGORITHM=%s is requested but the statement requires ALGORITHM=%s, MySQL will reject it", requested, impact.algorithm))
+		}
+	}
+	switch requestedLock {
+	case tidbast.LockTypeShared:
+		impact.raise(impact.algorithm, LockLevelShared, impact.Operation, "LOCK=SHARED blocks the writes")
+	case tidbast.LockTypeExclusive:
+		impact.raise(impact.algorithm, LockLevelExclusive, impact.Operation, "LOCK=EXCLUSIVE blocks the reads and writes")
+	case tidbast.LockTypeNone:
+		if impact.Lock != LockLevelNone {
+			impact.ReasonList = append(impact.ReasonList, fmt.Sprintf("LOCK=NONE is requested but the statement requires LOCK=%s, MySQL will reject it", impact.Lock))
+		}
+	}
+	return impact
+}
+
+func (a *mysqlAnalyzer) analyzeAlterTableSpec(impact *mysqlImpact, table *catalog.Table, spec *tidbast.AlterTableSpec) {
+	switch spec.Tp {
+	case tidbast.AlterTableAddColumns:
+		lastColumn := spec.Position == nil || spec.Position.Tp == tidbast.ColumnPositionNone
+		for _, column := range spec.NewColumns {
+			a.analyzeAddColumn(impact, column, lastColumn)
+		}
+		for _, constraint := range spec.NewConstraints {
+			a.analyzeAddConstraint(impact, table, constraint)
+		}
+	case tidbast.AlterTableAddConstraint:
+		a.analyzeAddConstraint(impact, table, spec.Constraint)
+	case tidbast.AlterTableDropColumn:
+		if a.version.GTE(mysqlVersion8029) {
+			impact.raise(mysqlAlgorithmInstant, LockLevelNone, OperationMetadata, fmt.Sprintf("DROP COLUMN %q is instant since MySQL 8.0.29", spec.OldColumnName.Name.O))
+		} else {
+			impact.raise(mysqlAlgorithmInplace, LockLevelNone, OperationRewrite, fmt.Sprintf("DROP COLUMN %q rebuilds the table", spec.OldColumnName.Name.O))
+		}
+	case tidbast.AlterTableDropIndex, tidbast.AlterTableDropForeignKey, tidbast.AlterTableDropCheck:
+		impact.raise(mysqlAlgorithmInplace, LockLevelNone, OperationMetadata, fmt.Sprintf("dropping the index or constraint %q only changes the metadata", spec.Name))
+	case tidbast.AlterTableAlterCheck:
+		if spec.Constraint != nil && spec.Constraint.Enforced {
+			impact.raise(mysqlAlgorithmCopy, LockLevelShared, OperationRewrite, fmt.Sprintf("enforcing the CHECK constraint %q copies the table to validate the existing rows", spec.Constraint.Name))
+		} else {
+			impact.raise(mysqlAlgorithmInplace, LockLevelNone, OperationMetadata, "not enforcing the CHECK constraint only changes the metadata")
+		}
+	case tidbast.AlterTableModifyColumn, tidbast.AlterTableChangeColumn:
+		if len(spec.NewColumns) == 0 {
+			return
+		}
+		oldColumnName := spec.NewColumns[0].Name.Name.O
+		if spec.Tp == tidbast.AlterTableChangeColumn {
+			oldColumnName = spec.OldColumnName.Name.O
+		}
+		a.analyzeChangeColumn(impact, table, oldColumnName, spec.NewColumns[0], spec.Position)
+	case tidbast.AlterTableRenameColumn:
+		impact.raise(a.instantSince(mysqlVersion8028), LockLevelNone, OperationMetadata, fmt.Sprintf("RENAME COLUMN %q only changes the metadata", spec.OldColumnName.Name.O))
+	case tidbast.AlterTableRenameTable:
+		impact.raise(a.instantSince(mysqlVersion80), LockLevelNone, OperationMetadata, "RENAME only changes the metadata")
+	case tidbast.AlterTableAlterColumn:
+		impact.raise(a.instantSince(mysqlVersion80), LockLevelNone, OperationMetadata, "ALTER COLUMN SET/DROP DEFAULT only changes the metadata")
+	case tidbast.AlterTableRenameIndex, tidbast.AlterTableIndexInvisible:
+		impact.raise(a.instantSince(mysqlVersion80), LockLevelNone, OperationMetadata, "renaming the index or changing the index visibility only changes the metadata")
+	case tidbast.AlterTableForce:
+		impact.raise(mysqlAlgorithmInplace, LockLevelNone, OperationRewrite, "FORCE rebuilds the table")
+	case tidbast.AlterTableOption:
+		a.analyzeTableOption(impact, spec.Options)
+	case tidbast.AlterTableAddPartitions, tidbast.AlterTableDropPartition, tidbast.AlterTableTruncatePartition:
+		impact.raise(mysqlAlgorithmInplace, LockLevelExclusive, OperationMetadata, "adding, dropping or truncating the partitions blocks the reads and writes")
+	default:
+		impact.raise(mysqlAlgorithmCopy, LockLevelShared, OperationRewrite, "the operation copies the table")
+	}
+}
+
+func (a *mysqlAnalyzer) analyzeAddColumn(impact *mysqlImpact, column *tidbast.ColumnDef, lastColumn bool) {
+	name := column.Name.Name.O
+	for _, option := range column.Options {
+		switch option.Tp {
+		case tidbast.ColumnOptionAutoIncrement:
+			impact.raise(mysqlAlgorithmInplace, LockLevelShared, OperationRewrite, fmt.Sprintf("ADD COLUMN %q with AUTO_INCREMENT rebuilds the table and blocks the writes", name))
+			return
+		case tidbast.ColumnOptionGenerated:
+			if option.Stored {
+				impact.raise(mysqlAlgorithmCopy, LockLevelShared, OperationRewrite, fmt.Sprintf("ADD COLUMN %q as a stored generated column copies the table", name))
+				return
+			}
+		}
+	}
+	switch {
+	case a.version.GTE(mysqlVersion8029) || (a.version.GTE(mysqlVersion8012) && lastColumn):
+		impact.raise(mysqlAlgorithmInstant, LockLevelNone, OperationMetadata, fmt.Sprintf("ADD COLUMN %q is instant", name))
+	default:
+		impact.raise(mysqlAlgorithmInplace, LockLevelNone, OperationRewrite, fmt.Sprintf("ADD COLUMN %q rebuilds the table, it's instant only as the last column since MySQL 8.0.12 or at any position since MySQL 8.0.29", name))
+	}
+	for _, option := range column.Options {
+		switch option.Tp {
+		case tidbast.ColumnOptionPrimaryKey:
+			impact.raise(mysqlAlgorithmInplace, LockLevelNone, OperationRewrite, fmt.Sprintf("ADD COLUMN %q as the primary key rebuilds the table", name))
+		case tidbast.ColumnOptionUniqKey:
+			impact.raise(mysqlAlgorithmInplace, LockLevelNone, OperationScan, fmt.Sprintf("ADD COLUMN %q with a unique key builds the index", name))
+		}
+	}
+}
+
+func (a *mysqlAnalyzer) analyzeAddConstraint(impact *mysqlImpact, table *catalog.Table, constraint *tidbast.Constraint) {
+	if constraint == nil {
+		return
+	}
+	switch constraint.Tp {
+	case tidbast.ConstraintPrimaryKey:
+		impact.raise(mysqlAlgorithmInplace, LockLevelNone, OperationRewrite, "ADD PRIMARY KEY rebuilds the table")
+	case tidbast.ConstraintKey, tidbast.ConstraintIndex, tidbast.ConstraintUniq, tidbast.ConstraintUniqKey, tidbast.ConstraintUniqIndex:
+		a.analyzeAddIndex(impact, false /* fulltext */, false /* spatial */)
+	case tidbast.ConstraintFulltext:
+		if hasFulltextIndex(table) {
+			a.analyzeAddIndex(impact, true /* fulltext */, false /* spatial */)
+		} else {
+			impact.raise(mysqlAlgorithmInplace, LockLevelShared, OperationRewrite, "adding the first FULLTEXT index rebuilds the table and blocks the writes")
+		}
+	case tidbast.ConstraintForeignKey:
+		impact.raise(mysqlAlgorithmCopy, LockLevelShared, OperationRewrite, "ADD FOREIGN KEY copies the table unless foreign_key_checks is disabled")
+	case tidbast.ConstraintCheck:
+		if constraint.Enforced {
+			impact.raise(mysqlAlgorithmCopy, LockLevelShared, OperationRewrite, "ADD CHECK copies the table to validate the existing rows")
+		} else {
+			impact.raise(mysqlAlgorithmInplace, LockLevelNone, OperationMetadata, "ADD CHECK NOT ENFORCED only changes the metadata")
+		}
+	}
+}
+
+func (*mysqlAnalyzer) analyzeAddIndex(impact *mysqlImpact, fulltext, spatial bool) {
+	if fulltext || spatial {
+		impact.raise(mysqlAlgorithmInplace, LockLevelShared, OperationScan, "adding a FULLTEXT or SPATIAL index blocks the writes while building the index")
+		return
+	}
+	impact.raise(mysqlAlgorithmInplace, LockLevelNone, OperationScan, "ADD INDEX builds the index without blocking the writes")
+}
+
+func (a *mysqlAnalyzer) analyzeChangeColumn(impact *mysqlImpact, table *catalog.Table, oldColumnName string, column *tidbast.ColumnDef, position *tidbast.ColumnPosition) {
+	name := column.Name.Name.O
+	var oldColumn *catalog.Column
+	if table != nil {
+		for _, c := range table.ColumnList {
+			if strings.EqualFold(c.Name, oldColumnName) {
+				oldColumn = c
+			}
+		}
+	}
+	if oldColumn == nil {
+		impact.raise(mysqlAlgorithmCopy, LockLevelShared, OperationRewrite, fmt.Sprintf("changing the column %q copies the table if it changes the column type, the column is not found in the synced schema", oldColumnName))
+		return
+	}
+	oldType, err := parseMySQLColumnType(oldColumn.Type)
+	if err != nil {
+		impact.raise(mysqlAlgorithmCopy, LockLevelShared, OperationRewrite, fmt.Sprintf("changing the column %q copies the table if it changes the column type", oldColumnName))
+		return
+	}
+
+	nullable := true
+	for _, option := range column.Options {
+		if option.Tp == tidbast.ColumnOptionNotNull || option.Tp == tidbast.ColumnOptionPrimaryKey {
+			nullable = false
+		}
+	}
+	rebuildReason := ""
+	switch {
+	case position != nil && position.Tp != tidbast.ColumnPositionNone:
+		rebuildReason = fmt.Sprintf("reordering the column %q rebuilds the table", name)
+	case nullable != oldColumn.Nullable:
+		rebuildReason = fmt.Sprintf("changing the nullability of the column %q rebuilds the table", name)
+	}
+
+	switch {
+	case isSameMySQLType(oldType, column.Tp), isEnumExtended(oldType, column.Tp):
+		if rebuildReason != "" {
+			impact.raise(mysqlAlgorithmInplace, LockLevelNone, OperationRewrite, rebuildReason)
+			return
+		}
+		impact.raise(a.instantSince(mysqlVersion80), LockLevelNone, OperationMetadata, fmt.Sprintf("changing the column %q without changing the type only changes the metadata", name))
+	case a.version.GTE(mysqlVersion57) && isVarcharExtended(oldType, column.Tp, tableCharset(table)):
+		if rebuildReason != "" {
+			impact.raise(mysqlAlgorithmInplace, LockLevelNone, OperationRewrite, rebuildReason)
+			return
+		}
+		impact.raise(mysqlAlgorithmInplace, LockLevelNone, OperationMetadata, fmt.Sprintf("extending the VARCHAR column %q only changes the metadata", name))
+	default:
+		impact.raise(mysqlAlgorithmCopy, LockLevelShared, OperationRewrite, fmt.Sprintf("changing the type of the column %q from %s to %s copies the table", name, oldColumn.Type, column.Tp.CompactStr()))
+	}
+}
+
+func (a *mysqlAnalyzer) analyzeTableOption(impact *mysqlImpact, options []*tidbast.TableOption) {
+	for _, option := range options {
+		switch option.Tp {
+		case tidbast.TableOptionEngine:
+			impact.raise(mysqlAlgorithmCopy, LockLevelShared, OperationRewrite, "changing the ENGINE copies the table")
+		case tidbast.TableOptionCharset, tidbast.TableOptionCollate:
+			if option.UintValue == tidbast.TableOptionCharsetWithConvertTo {
+				impact.raise(mysqlAlgorithmCopy, LockLevelShared, OperationRewrite, "CONVERT TO CHARACTER SET copies the table")
+			} else {
+				impact.raise(mysqlAlgorithmInplace, LockLevelNone, OperationMetadata, "changing the default character set only changes the metadata")
+			}
+		case tidbast.TableOptionRowFormat, tidbast.TableOptionKeyBlockSize, tidbast.TableOptionCompression:
+			impact.raise(mysqlAlgorithmInplace, LockLevelNone, OperationRewrite, "changing the ROW_FORMAT, KEY_BLOCK_SIZE or COMPRESSION rebuilds the table")
+		case tidbast.TableOptionComment:
+			impact.raise(a.instantSince(mysqlVersion80), LockLevelNone, OperationMetadata, "changing the COMMENT only changes the metadata")
+		default:
+			impact.raise(mysqlAlgorithmInplace, LockLevelNone, OperationMetadata, "changing the table option only changes the metadata")
+		}
+	}
+}
+
+// instantSince returns INSTANT for the version since which the metadata-only operation is instant, and INPLACE for the older versions.
+func (a *mysqlAnalyzer) instantSince(version semver.Version) mysqlAlgorithm {
+	if a.version.GTE(version) {
+		return mysqlAlgorithmInstant
+	}
+	return mysqlAlgorithmInplace
+}
+
+// parseMySQLColumnType parses the column type of the synced schema, e.g. "varchar(20)" and "int unsigned".
+func parseMySQLColumnType(columnType string) (*types.FieldType, error) {
+	nodes, _, err := tidbparser.New().Parse(fmt.Sprintf("CREATE TABLE t (c %s)", columnType), "", "")
+	if err != nil {
+		return nil, err
+	}
+	if len(nodes) != 1 {
+		return nil, errors.Errorf("invalid column type %q", columnType)
+	}
+	createTable, ok := nodes[0].(*tidbast.CreateTableStmt)
+	if !ok || len(createTable.Cols) != 1 {
+		return nil, errors.Errorf("invalid column type %q", columnType)
+	}
+	return createTable.Cols[0].Tp, nil
+}
+
+func isIntegerType(tp byte) bool {
+	switch tp {
+	case tidbmysql.TypeTiny, tidbmysql.TypeShort, tidbmysql.TypeInt24, tidbmysql.TypeLong, tidbmysql.TypeLonglong:
+		return true
+	}
+	return false
+}
+
+// isSameMySQLType returns true if the column types are the same, ignoring the display width of the integer types.
+func isSameMySQLType(oldType, newType *types.FieldType) bool {
+	if oldType.GetType() != newType.GetType() || tidbmysql.HasUnsignedFlag(oldType.GetFlag()) != tidbmysql.HasUnsignedFlag(newType.GetFlag()) {
+		return false
+	}
+	if newType.GetCharset() != "" && !strings.EqualFold(oldType.GetCharset(), newType.GetCharset()) {
+		return false
+	}
+	if !isIntegerType(newType.GetType()) && (oldType.GetFlen() != newType.GetFlen() || oldType.GetDecimal() != newType.GetDecimal()) {
+		return false
+	}
+	return strings.Join(oldType.GetElems(), ",") == strings.Join(newType.GetElems(), ",")
+}
+
+// isEnumExtended returns true if the ENUM or SET members are appended to the end without changing the storage size.
+func isEnumExtended(oldType, newType *types.FieldType) bool {
+	if oldType.GetType() != newType.GetType() || (newType.GetType() != tidbmysql.TypeEnum && newType.GetType() != tidbmysql.TypeSet) {
+		return false
+	}
+	oldElems, newElems := oldType.GetElems(), newType.GetElems()
+	if len(newElems) < len(oldElems) {
+		return false
+	}
+	for i, elem := range oldElems {
+		if newElems[i] != elem {
+			return false
+		}
+	}
+	// The storage size of ENUM changes at 256 members, and that of SET changes every 8 members.
+	if newType.GetType() == tidbmysql.TypeEnum {
+		return (len(oldElems) <= 255) == (len(newElems) <= 255)
+	}
+	return (len(oldElems)+7)/8 == (len(newElems)+7)/8
+}
+
+// isVarcharExtended returns true if the VARCHAR column is extended without changing the number of length bytes.
+func isVarcharExtended(oldType, newType *types.FieldType, charset string) bool {
+	if oldType.GetType() != tidbmysql.TypeVarchar || newType.GetType() != tidbmysql.TypeVarchar {
+		return false
+	}
+	if newType.GetCharset() != "" && !strings.EqualFold(newType.GetCharset(), charset) {
+		return false
+	}
+	maxLen, ok := mysqlCharsetMaxLen[strings.ToLower(charset)]
+	if !ok {
+		maxLen = 4
+	}
+	oldFlen, newFlen := oldType.GetFlen(), newType.GetFlen()
+	// The length of VARCHAR is stored in 1 byte up to 255 bytes, and 2 bytes otherwise.
+	return newFlen >= oldFlen && (oldFlen*maxLen <= 255) == (newFlen*maxLen <= 255)
+}
+
+// tableCharset returns the character set of the table from its collation, and returns "" if unknown.
+func tableCharset(table *catalog.Table) string {
+	if table == nil {
+		return ""
+	}
+	if i := strings.Index(table.Collation, "_"); i > 0 {
+		return table.Collation[:i]
+	}
+	return table.Collation
+}
+
+func hasFulltextIndex(table *catalog.Table) bool {
+	if table == nil {
+		return false
+	}
+	for _, index := range table.IndexList {
+		if strings.EqualFold(index.Type, "FULLTEXT") {
+			return true
+		}
+	}
+	return false
+}
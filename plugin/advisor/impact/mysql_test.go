@@ -0,0 +1,192 @@
+package impact
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bytebase/bytebase/plugin/advisor/catalog"
+	advisorDB "github.com/bytebase/bytebase/plugin/advisor/db"
+
+	// Register pingcap parser driver.
+	_ "github.com/pingcap/tidb/types/parser_driver"
+)
+
+func TestAnalyzeMySQL(t *testing.T) {
+	database := &catalog.Database{
+		Name:   "db",
+		DbType: advisorDB.MySQL,
+		SchemaList: []*catalog.Schema{
+			{
+				TableList: []*catalog.Table{
+					{
+						Name:      "t",
+						Collation: "utf8mb4_general_ci",
+						RowCount:  10_000_000,
+						DataSize:  1 << 30,
+						ColumnList: []*catalog.Column{
+							{Name: "id", Type: "int", Nullable: false},
+							{Name: "name", Type: "varchar(20)", Nullable: true},
+							{Name: "status", Type: "enum('a','b')", Nullable: true},
+						},
+					},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		statement string
+		version   string
+		lock      LockLevel
+		lockMode  string
+		operation Operation
+	}{
+		{
+			statement: "ALTER TABLE t ADD COLUMN a int",
+			version:   "8.0.28",
+			lock:      LockLevelNone,
+			lockMode:  "ALGORITHM=INSTANT",
+			operation: OperationMetadata,
+		},
+		{
+			statement: "ALTER TABLE t ADD COLUMN a int AFTER id",
+			version:   "8.0.28",
+			lock:      LockLevelNone,
+			lockMode:  "ALGORITHM=INPLACE, LOCK=NONE",
+			operation: OperationRewrite,
+		},
+		{
+			statement: "ALTER TABLE t ADD COLUMN a int AFTER id",
+			version:   "8.0.30-log",
+			lock:      LockLevelNone,
+			lockMode:  "ALGORITHM=INSTANT",
+			operation: OperationMetadata,
+		},
+		{
+			statement: "ALTER TABLE t ADD COLUMN a int",
+			version:   "5.7.38",
+			lock:      LockLevelNone,
+			lockMode:  "ALGORITHM=INPLACE, LOCK=NONE",
+			operation: OperationRewrite,
+		},
+		{
+			statement: "ALTER TABLE t DROP COLUMN name",
+			version:   "8.0.28",
+			lock:      LockLevelNone,
+			lockMode:  "ALGORITHM=INPLACE, LOCK=NONE",
+			operation: OperationRewrite,
+		},
+		{
+			statement: "ALTER TABLE t ADD INDEX idx_name (name)",
+			version:   "8.0.28",
+			lock:      LockLevelNone,
+			lockMode:  "ALGORITHM=INPLACE, LOCK=NONE",
+			operation: OperationScan,
+		},
+		{
+			statement: "ALTER TABLE t MODIFY COLUMN id bigint NOT NULL",
+			version:   "8.0.28",
+			lock:      LockLevelShared,
+			lockMode:  "ALGORITHM=COPY, LOCK=SHARED",
+			operation: OperationRewrite,
+		},
+		{
+			statement: "ALTER TABLE t MODIFY COLUMN id int(11) NOT NULL COMMENT 'id'",
+			version:   "8.0.28",
+			lock:      LockLevelNone,
+			lockMode:  "ALGORITHM=INSTANT",
+			operation: OperationMetadata,
+		},
+		{
+			statement: "ALTER TABLE t MODIFY COLUMN id int NULL",
+			version:   "8.0.28",
+			lock:      LockLevelNone,
+			lockMode:  "ALGORITHM=INPLACE, LOCK=NONE",
+			operation: OperationRewrite,
+		},
+		{
+			statement: "ALTER TABLE t CHANGE COLUMN name title varchar(50)",
+			version:   "8.0.28",
+			lock:      LockLevelNone,
+			lockMode:  "ALGORITHM=INPLACE, LOCK=NONE",
+			operation: OperationMetadata,
+		},
+		{
+			statement: "ALTER TABLE t MODIFY COLUMN name varchar(100)",
+			version:   "8.0.28",
+			lock:      LockLevelShared,
+			lockMode:  "ALGORITHM=COPY, LOCK=SHARED",
+			operation: OperationRewrite,
+		},
+		{
+			statement: "ALTER TABLE t MODIFY COLUMN status enum('a','b','c')",
+			version:   "8.0.28",
+			lock:      LockLevelNone,
+			lockMode:  "ALGORITHM=INSTANT",
+			operation: OperationMetadata,
+		},
+		{
+			statement: "ALTER TABLE t ADD CONSTRAINT fk FOREIGN KEY (id) REFERENCES p (id)",
+			version:   "8.0.28",
+			lock:      LockLevelShared,
+			lockMode:  "ALGORITHM=COPY, LOCK=SHARED",
+			operation: OperationRewrite,
+		},
+		{
+			statement: "ALTER TABLE t DROP PRIMARY KEY, ADD PRIMARY KEY (id, name)",
+			version:   "8.0.28",
+			lock:      LockLevelNone,
+			lockMode:  "ALGORITHM=INPLACE, LOCK=NONE",
+			operation: OperationRewrite,
+		},
+		{
+			statement: "ALTER TABLE t CONVERT TO CHARACTER SET utf8mb4",
+			version:   "8.0.28",
+			lock:      LockLevelShared,
+			lockMode:  "ALGORITHM=COPY, LOCK=SHARED",
+			operation: OperationRewrite,
+		},
+		{
+			statement: "ALTER TABLE t ADD INDEX idx_name (name), LOCK=EXCLUSIVE",
+			version:   "8.0.28",
+			lock:      LockLevelExclusive,
+			lockMode:  "ALGORITHM=INPLACE, LOCK=EXCLUSIVE",
+			operation: OperationScan,
+		},
+		{
+			statement: "CREATE FULLTEXT INDEX idx_name ON t (name)",
+			version:   "8.0.28",
+			lock:      LockLevelShared,
+			lockMode:  "ALGORITHM=INPLACE, LOCK=SHARED",
+			operation: OperationScan,
+		},
+		{
+			statement: "TRUNCATE TABLE t",
+			version:   "8.0.28",
+			lock:      LockLevelExclusive,
+			lockMode:  "LOCK=EXCLUSIVE",
+			operation: OperationMetadata,
+		},
+	}
+
+	for _, test := range tests {
+		impactList, err := Analyze(Context{DbType: advisorDB.MySQL, EngineVersion: test.version, Database: database}, test.statement)
+		require.NoError(t, err, test.statement)
+		require.Len(t, impactList, 1, test.statement)
+		impact := impactList[0]
+		require.Equal(t, test.lock, impact.Lock, test.statement)
+		require.Equal(t, test.lockMode, impact.LockMode, test.statement)
+		require.Equal(t, test.operation, impact.Operation, test.statement)
+		require.Equal(t, "t", impact.Table, test.statement)
+		require.Equal(t, int64(10_000_000), impact.RowCount, test.statement)
+	}
+}
+
+func TestAnalyzeMySQLRequestedAlgorithm(t *testing.T) {
+	impactList, err := Analyze(Context{DbType: advisorDB.MySQL, EngineVersion: "8.0.28"}, "ALTER TABLE t MODIFY COLUMN id bigint, ALGORITHM=INPLACE;\nCREATE TABLE t2 (id int);")
+	require.NoError(t, err)
+	require.Len(t, impactList, 1)
+	require.Equal(t, "ALGORITHM=COPY, LOCK=SHARED", impactList[0].LockMode)
+	require.Contains(t, impactList[0].ReasonList, "ALGORITHM=INPLACE is requested but the statement requires ALGORITHM=COPY, MySQL will reject it")
+	require.Equal(t, 1, impactList[0].Line)
+}
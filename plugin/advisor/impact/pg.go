@@ -0,0 +1,480 @@
+package impact
+
+// This file implements the impact analysis for Postgres.
+// The lock levels of the ALTER TABLE subcommands follow https://www.postgresql.org/docs/current/sql-altertable.html,
+// and the strongest lock of the subcommands is taken for the whole statement.
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/blang/semver/v4"
+	pgquery "github.com/pganalyze/pg_query_go/v2"
+	"github.com/pkg/errors"
+
+	"github.com/bytebase/bytebase/plugin/advisor/catalog"
+)
+
+// pgLockMode is the table-level lock mode of Postgres, ordered by strength.
+// https://www.postgresql.org/docs/current/explicit-locking.html#LOCKING-TABLES
+type pgLockMode int
+
+const (
+	pgShareUpdateExclusive pgLockMode = iota
+	pgShare
+	pgShareRowExclusive
+	pgAccessExclusive
+)
+
+const (
+	pgDefaultSchema = "public"
+)
+
+var (
+	// volatileFunctionRegexp matches the commonly used volatile functions.
+	// Adding a column with a volatile default rewrites the table even on Postgres 11+.
+	volatileFunctionRegexp = regexp.MustCompile(`(?i)\b(random|clock_timestamp|timeofday|gen_random_uuid|uuid_generate_v1|uuid_generate_v1mc|uuid_generate_v4|nextval)\s*\(`)
+	pgVersion11            = semver.MustParse("11.0.0")
+	pgVersion12            = semver.MustParse("12.0.0")
+)
+
+func (m pgLockMode) String() string {
+	switch m {
+	case pgShareUpdateExclusive:
+		return "SHARE UPDATE EXCLUSIVE"
+	case pgShare:
+		return "SHARE"
+	case pgShareRowExclusive:
+		return "SHARE ROW EXCLUSIVE"
+	case pgAccessExclusive:
+		return "ACCESS EXCLUSIVE"
+	}
+	return ""
+}
+
+// lockLevel returns the reads and writes blocked by the lock mode.
+// SHARE UPDATE EXCLUSIVE only conflicts with the schema changes and VACUUM, and ACCESS EXCLUSIVE is the only one blocking the reads.
+func (m pgLockMode) lockLevel() LockLevel {
+	switch m {
+	case pgShareUpdateExclusive:
+		return LockLevelNone
+	case pgAccessExclusive:
+		return LockLevelExclusive
+	default:
+		return LockLevelShared
+	}
+}
+
+type pgAnalyzer struct {
+	version  semver.Version
+	database *catalog.Database
+}
+
+// pgImpact tracks the strongest Postgres lock mode of the impact.
+type pgImpact struct {
+	*Impact
+	mode pgLockMode
+}
+
+func newPGImpact(relation *pgquery.RangeVar) *pgImpact {
+	schema := relation.Schemaname
+	if schema == "" {
+		schema = pgDefaultSchema
+	}
+	return &pgImpact{
+		Impact: &Impact{
+			Schema:    schema,
+			Table:     relation.Relname,
+			Lock:      LockLevelNone,
+			Operation: OperationMetadata,
+		},
+		mode: -1,
+	}
+}
+
+func (impact *pgImpact) raise(mode pgLockMode, operation Operation, reason string) {
+	if mode > impact.mode {
+		impact.mode = mode
+		impact.LockMode = mode.String()
+	}
+	impact.Impact.raise(mode.lockLevel(), operation, reason)
+}
+
+func analyzePostgres(ctx Context, statement string) ([]*Impact, error) {
+	res, err := pgquery.Parse(statement)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse the statement")
+	}
+
+	analyzer := &pgAnalyzer{
+		version:  parseVersion(ctx.EngineVersion),
+		database: ctx.Database,
+	}
+	var impactList []*Impact
+	for _, stmt := range res.Stmts {
+		start, end := int(stmt.StmtLocation), len(statement)
+		if stmt.StmtLen > 0 {
+			end = start + int(stmt.StmtLen)
+		}
+		text := strings.TrimRightFunc(statement[start:end], unicode.IsSpace)
+		// The line of the statement is the line where it ends.
+		line := strings.Count(statement[:start+len(text)], "\n") + 1
+		text = strings.TrimSpace(text)
+		for _, impact := range analyzer.analyze(stmt.Stmt) {
+			impact.Statement = text
+			impact.Line = line
+			impactList = append(impactList, impact.Impact)
+		}
+	}
+	return impactList, nil
+}
+
+// analyze returns the impacts of the statement on the existing tables, one for each table.
+func (a *pgAnalyzer) analyze(node *pgquery.Node) []*pgImpact {
+	switch n := node.Node.(type) {
+	case *pgquery.Node_AlterTableStmt:
+		if n.AlterTableStmt.Relkind != pgquery.ObjectType_OBJECT_TABLE {
+			return nil
+		}
+		impact := newPGImpact(n.AlterTableStmt.Relation)
+		for _, cmd := range n.AlterTableStmt.Cmds {
+			if alterTableCmd, ok := cmd.Node.(*pgquery.Node_AlterTableCmd); ok {
+				a.analyzeAlterTableCmd(impact, alterTableCmd.AlterTableCmd)
+			}
+		}
+		return []*pgImpact{impact}
+	case *pgquery.Node_IndexStmt:
+		impact := newPGImpact(n.IndexStmt.Relation)
+		if n.IndexStmt.Concurrent {
+			impact.raise(pgShareUpdateExclusive, OperationScan, "CREATE INDEX CONCURRENTLY builds the index without blocking the writes")
+		} else {
+			impact.raise(pgShare, OperationScan, "CREATE INDEX blocks the writes while building the index")
+		}
+		return []*pgImpact{impact}
+	case *pgquery.Node_DropStmt:
+		return a.analyzeDrop(n.DropStmt)
+	case *pgquery.Node_TruncateStmt:
+		var impactList []*pgImpact
+		for _, relation := range n.TruncateStmt.Relations {
+			if rangeVar, ok := relation.Node.(*pgquery.Node_RangeVar); ok {
+				impact := newPGImpact(rangeVar.RangeVar)
+				impact.raise(pgAccessExclusive, OperationMetadata, "TRUNCATE replaces the table with an empty one")
+				impactList = append(impactList, impact)
+			}
+		}
+		return impactList
+	case *pgquery.Node_RenameStmt:
+		switch n.RenameStmt.RenameType {
+		case pgquery.ObjectType_OBJECT_TABLE:
+		case pgquery.ObjectType_OBJECT_COLUMN, pgquery.ObjectType_OBJECT_TABCONSTRAINT:
+			if n.RenameStmt.RelationType != pgquery.ObjectType_OBJECT_TABLE {
+				return nil
+			}
+		default:
+			return nil
+		}
+		impact := newPGImpact(n.RenameStmt.Relation)
+		impact.raise(pgAccessExclusive, OperationMetadata, "RENAME only changes the metadata")
+		return []*pgImpact{impact}
+	case *pgquery.Node_VacuumStmt:
+		if !n.VacuumStmt.IsVacuumcmd {
+			return nil
+		}
+		full := false
+		for _, option := range n.VacuumStmt.Options {
+			if defElem, ok := option.Node.(*pgquery.Node_DefElem); ok && strings.EqualFold(defElem.DefElem.Defname, "full") {
+				full = true
+			}
+		}
+		var impactList []*pgImpact
+		for _, rel := range n.VacuumStmt.Rels {
+			vacuumRelation, ok := rel.Node.(*pgquery.Node_VacuumRelation)
+			if !ok || vacuumRelation.VacuumRelation.Relation == nil {
+				continue
+			}
+			impact := newPGImpact(vacuumRelation.VacuumRelation.Relation)
+			if full {
+				impact.raise(pgAccessExclusive, OperationRewrite, "VACUUM FULL rewrites the table")
+			} else {
+				impact.raise(pgShareUpdateExclusive, OperationScan, "VACUUM scans the table without blocking the reads and writes")
+			}
+			impactList = append(impactList, impact)
+		}
+		return impactList
+	case *pgquery.Node_ClusterStmt:
+		if n.ClusterStmt.Relation == nil {
+			return nil
+		}
+		impact := newPGImpact(n.ClusterStmt.Relation)
+		impact.raise(pgAccessExclusive, OperationRewrite, "CLUSTER rewrites the table in the index order")
+		return []*pgImpact{impact}
+	case *pgquery.Node_ReindexStmt:
+		if n.ReindexStmt.Kind != pgquery.ReindexObjectType_REINDEX_OBJECT_TABLE || n.ReindexStmt.Relation == nil {
+			return nil
+		}
+		impact := newPGImpact(n.ReindexStmt.Relation)
+		if n.ReindexStmt.Concurrent {
+			impact.raise(pgShareUpdateExclusive, OperationScan, "REINDEX CONCURRENTLY rebuilds the indexes without blocking the writes")
+		} else {
+			impact.raise(pgShare, OperationScan, "REINDEX blocks the writes while rebuilding the indexes")
+		}
+		return []*pgImpact{impact}
+	case *pgquery.Node_CreateTrigStmt:
+		impact := newPGImpact(n.CreateTrigStmt.Relation)
+		impact.raise(pgShareRowExclusive, OperationMetadata, "CREATE TRIGGER only changes the metadata")
+		return []*pgImpact{impact}
+	}
+	return nil
+}
+
+func (a *pgAnalyzer) analyzeDrop(stmt *pgquery.DropStmt) []*pgImpact {
+	var impactList []*pgImpact
+	for _, object := range stmt.Objects {
+		list, ok := object.Node.(*pgquery.Node_List)
+		if !ok {
+			continue
+		}
+		var nameList []string
+		for _, item := range list.List.Items {
+			if str, ok := item.Node.(*pgquery.Node_String_); ok {
+				nameList = append(nameList, str.String_.Str)
+			}
+		}
+		if len(nameList) == 0 {
+			continue
+		}
+		relation := &pgquery.RangeVar{Relname: nameList[len(nameList)-1]}
+		if len(nameList) > 1 {
+			relation.Schemaname = nameList[len(nameList)-2]
+		}
+
+		switch stmt.RemoveType {
+		case pgquery.ObjectType_OBJECT_TABLE:
+			impact := newPGImpact(relation)
+			impact.raise(pgAccessExclusive, OperationMetadata, "DROP TABLE removes the table")
+			impactList = append(impactList, impact)
+		case pgquery.ObjectType_OBJECT_INDEX:
+			// The lock is taken on the table of the index.
+			impact := newPGImpact(relation)
+			impact.Table = a.findIndexTable(impact.Schema, relation.Relname)
+			if impact.Table == "" {
+				continue
+			}
+			if stmt.Concurrent {
+				impact.raise(pgShareUpdateExclusive, OperationMetadata, fmt.Sprintf("DROP INDEX CONCURRENTLY drops the index %q without blocking the reads and writes", relation.Relname))
+			} else {
+				impact.raise(pgAccessExclusive, OperationMetadata, fmt.Sprintf("DROP INDEX drops the index %q", relation.Relname))
+			}
+			impactList = append(impactList, impact)
+		}
+	}
+	return impactList
+}
+
+func (a *pgAnalyzer) analyzeAlterTableCmd(impact *pgImpact, cmd *pgquery.AlterTableCmd) {
+	switch cmd.Subtype {
+	case pgquery.AlterTableType_AT_AddColumn, pgquery.AlterTableType_AT_AddColumnRecurse:
+		columnDef, ok := cmd.Def.Node.(*pgquery.Node_ColumnDef)
+		if !ok {
+			impact.raise(pgAccessExclusive, OperationMetadata, "ADD COLUMN")
+			return
+		}
+		a.analyzeAddColumn(impact, columnDef.ColumnDef)
+	case pgquery.AlterTableType_AT_ColumnDefault:
+		impact.raise(pgAccessExclusive, OperationMetadata, fmt.Sprintf("ALTER COLUMN %q SET/DROP DEFAULT only changes the metadata", cmd.Name))
+	case pgquery.AlterTableType_AT_DropNotNull:
+		impact.raise(pgAccessExclusive, OperationMetadata, fmt.Sprintf("ALTER COLUMN %q DROP NOT NULL only changes the metadata", cmd.Name))
+	case pgquery.AlterTableType_AT_SetNotNull:
+		impact.raise(pgAccessExclusive, OperationScan, fmt.Sprintf("ALTER COLUMN %q SET NOT NULL scans the table to verify the existing rows, unless a valid CHECK constraint proves it on Postgres 12+", cmd.Name))
+	case pgquery.AlterTableType_AT_SetStatistics, pgquery.AlterTableType_AT_SetOptions, pgquery.AlterTableType_AT_ResetOptions,
+		pgquery.AlterTableType_AT_SetRelOptions, pgquery.AlterTableType_AT_ResetRelOptions, pgquery.AlterTableType_AT_ClusterOn, pgquery.AlterTableType_AT_DropCluster:
+		impact.raise(pgShareUpdateExclusive, OperationMetadata, "changing the statistics, storage parameters or cluster index only changes the metadata")
+	case pgquery.AlterTableType_AT_DropColumn, pgquery.AlterTableType_AT_DropColumnRecurse:
+		impact.raise(pgAccessExclusive, OperationMetadata, fmt.Sprintf("DROP COLUMN %q only marks the column as dropped", cmd.Name))
+	case pgquery.AlterTableType_AT_AddConstraint, pgquery.AlterTableType_AT_AddConstraintRecurse:
+		constraint, ok := cmd.Def.Node.(*pgquery.Node_Constraint)
+		if !ok {
+			impact.raise(pgAccessExclusive, OperationScan, "ADD CONSTRAINT")
+			return
+		}
+		a.analyzeAddConstraint(impact, constraint.Constraint)
+	case pgquery.AlterTableType_AT_AddIndexConstraint:
+		impact.raise(pgAccessExclusive, OperationMetadata, "ADD CONSTRAINT USING INDEX reuses the existing index")
+	case pgquery.AlterTableType_AT_ValidateConstraint, pgquery.AlterTableType_AT_ValidateConstraintRecurse:
+		impact.raise(pgShareUpdateExclusive, OperationScan, fmt.Sprintf("VALIDATE CONSTRAINT %q scans the table without blocking the reads and writes", cmd.Name))
+	case pgquery.AlterTableType_AT_DropConstraint, pgquery.AlterTableType_AT_DropConstraintRecurse:
+		impact.raise(pgAccessExclusive, OperationMetadata, fmt.Sprintf("DROP CONSTRAINT %q only changes the metadata", cmd.Name))
+	case pgquery.AlterTableType_AT_AlterColumnType:
+		columnDef, ok := cmd.Def.Node.(*pgquery.Node_ColumnDef)
+		if ok && columnDef.ColumnDef.RawDefault == nil && a.isBinaryCoercible(impact.Impact, cmd.Name, columnDef.ColumnDef.TypeName) {
+			impact.raise(pgAccessExclusive, OperationMetadata, fmt.Sprintf("ALTER COLUMN %q TYPE is binary coercible and only changes the metadata", cmd.Name))
+			return
+		}
+		impact.raise(pgAccessExclusive, OperationRewrite, fmt.Sprintf("ALTER COLUMN %q TYPE rewrites the table", cmd.Name))
+	case pgquery.AlterTableType_AT_SetLogged, pgquery.AlterTableType_AT_SetUnLogged:
+		impact.raise(pgAccessExclusive, OperationRewrite, "SET LOGGED/UNLOGGED rewrites the table")
+	case pgquery.AlterTableType_AT_SetTableSpace:
+		impact.raise(pgAccessExclusive, OperationRewrite, "SET TABLESPACE copies the table to the new tablespace")
+	case pgquery.AlterTableType_AT_EnableTrig, pgquery.AlterTableType_AT_EnableAlwaysTrig, pgquery.AlterTableType_AT_EnableReplicaTrig,
+		pgquery.AlterTableType_AT_DisableTrig, pgquery.AlterTableType_AT_EnableTrigAll, pgquery.AlterTableType_AT_DisableTrigAll,
+		pgquery.AlterTableType_AT_EnableTrigUser, pgquery.AlterTableType_AT_DisableTrigUser:
+		impact.raise(pgShareRowExclusive, OperationMetadata, "ENABLE/DISABLE TRIGGER only changes the metadata")
+	case pgquery.AlterTableType_AT_AttachPartition:
+		mode := pgAccessExclusive
+		if a.version.GTE(pgVersion12) {
+			mode = pgShareUpdateExclusive
+		}
+		impact.raise(mode, OperationMetadata, "ATTACH PARTITION scans the attached partition to verify the partition constraint, unless a valid CHECK constraint proves it")
+	default:
+		impact.raise(pgAccessExclusive, OperationMetadata, "the subcommand only changes the metadata")
+	}
+}
+
+func (a *pgAnalyzer) analyzeAddColumn(impact *pgImpact, columnDef *pgquery.ColumnDef) {
+	name := columnDef.Colname
+	if isSerialType(columnDef.TypeName) {
+		impact.raise(pgAccessExclusive, OperationRewrite, fmt.Sprintf("ADD COLUMN %q of the serial type rewrites the table to fill the sequence values", name))
+		return
+	}
+	operation, reason := OperationMetadata, fmt.Sprintf("ADD COLUMN %q only changes the metadata", name)
+	for _, node := range columnDef.Constraints {
+		constraint, ok := node.Node.(*pgquery.Node_Constraint)
+		if !ok {
+			continue
+		}
+		switch constraint.Constraint.Contype {
+		case pgquery.ConstrType_CONSTR_DEFAULT:
+			switch {
+			case a.version.LT(pgVersion11) && !isNullConstant(constraint.Constraint.RawExpr):
+				operation, reason = OperationRewrite, fmt.Sprintf("ADD COLUMN %q with a default rewrites the table before Postgres 11", name)
+			case isVolatileExpression(constraint.Constraint.RawExpr):
+				operation, reason = OperationRewrite, fmt.Sprintf("ADD COLUMN %q with a volatile default rewrites the table", name)
+			}
+		case pgquery.ConstrType_CONSTR_IDENTITY:
+			operation, reason = OperationRewrite, fmt.Sprintf("ADD COLUMN %q as an identity column rewrites the table to fill the sequence values", name)
+		case pgquery.ConstrType_CONSTR_GENERATED:
+			operation, reason = OperationRewrite, fmt.Sprintf("ADD COLUMN %q as a stored generated column rewrites the table", name)
+		case pgquery.ConstrType_CONSTR_PRIMARY, pgquery.ConstrType_CONSTR_UNIQUE, pgquery.ConstrType_CONSTR_CHECK, pgquery.ConstrType_CONSTR_FOREIGN:
+			a.analyzeAddConstraint(impact, constraint.Constraint)
+		}
+	}
+	impact.raise(pgAccessExclusive, operation, reason)
+}
+
+func (*pgAnalyzer) analyzeAddConstraint(impact *pgImpact, constraint *pgquery.Constraint) {
+	switch constraint.Contype {
+	case pgquery.ConstrType_CONSTR_FOREIGN:
+		if constraint.SkipValidation {
+			impact.raise(pgShareRowExclusive, OperationMetadata, "ADD FOREIGN KEY NOT VALID skips the validation of the existing rows")
+			return
+		}
+		impact.raise(pgShareRowExclusive, OperationScan, "ADD FOREIGN KEY scans the table to validate the existing rows, consider adding it as NOT VALID and validating it separately")
+	case pgquery.ConstrType_CONSTR_CHECK:
+		if constraint.SkipValidation {
+			impact.raise(pgAccessExclusive, OperationMetadata, "ADD CHECK NOT VALID skips the validation of the existing rows")
+			return
+		}
+		impact.raise(pgAccessExclusive, OperationScan, "ADD CHECK scans the table to validate the existing rows, consider adding it as NOT VALID and validating it separately")
+	case pgquery.ConstrType_CONSTR_PRIMARY, pgquery.ConstrType_CONSTR_UNIQUE, pgquery.ConstrType_CONSTR_EXCLUSION:
+		if constraint.Indexname != "" {
+			impact.raise(pgAccessExclusive, OperationMetadata, "ADD CONSTRAINT USING INDEX reuses the existing index")
+			return
+		}
+		impact.raise(pgAccessExclusive, OperationScan, "ADD PRIMARY KEY/UNIQUE/EXCLUDE builds the index while blocking the reads and writes, consider building the index concurrently first")
+	default:
+		impact.raise(pgAccessExclusive, OperationMetadata, "ADD CONSTRAINT only changes the metadata")
+	}
+}
+
+// isBinaryCoercible returns true if the column type change doesn't need a table rewrite.
+// Only the common cases of converting to an unbounded text type are recognized.
+func (a *pgAnalyzer) isBinaryCoercible(impact *Impact, columnName string, typeName *pgquery.TypeName) bool {
+	newType := getTypeName(typeName)
+	if len(typeName.Typmods) > 0 || (newType != "text" && newType != "varchar") {
+		return false
+	}
+	table := findTable(a.database, impact.Schema, impact.Table)
+	if table == nil {
+		return false
+	}
+	for _, column := range table.ColumnList {
+		if column.Name == columnName {
+			return column.Type == "text" || column.Type == "character varying"
+		}
+	}
+	return false
+}
+
+// findIndexTable returns the table of the index, and returns "" if not found.
+func (a *pgAnalyzer) findIndexTable(schemaName, indexName string) string {
+	if a.database == nil {
+		return ""
+	}
+	for _, schema := range a.database.SchemaList {
+		if schema.Name != schemaName {
+			continue
+		}
+		for _, table := range schema.TableList {
+			for _, index := range table.IndexList {
+				if index.Name == indexName {
+					return table.Name
+				}
+			}
+		}
+	}
+	return ""
+}
+
+func getTypeName(typeName *pgquery.TypeName) string {
+	if typeName == nil || len(typeName.Names) == 0 {
+		return ""
+	}
+	if str, ok := typeName.Names[len(typeName.Names)-1].Node.(*pgquery.Node_String_); ok {
+		return strings.ToLower(str.String_.Str)
+	}
+	return ""
+}
+
+func isSerialType(typeName *pgquery.TypeName) bool {
+	switch getTypeName(typeName) {
+	case "serial", "serial2", "serial4", "serial8", "smallserial", "bigserial":
+		return true
+	}
+	return false
+}
+
+func isNullConstant(expr *pgquery.Node) bool {
+	if expr == nil {
+		return true
+	}
+	switch n := expr.Node.(type) {
+	case *pgquery.Node_Null:
+		return true
+	case *pgquery.Node_AConst:
+		_, ok := n.AConst.Val.GetNode().(*pgquery.Node_Null)
+		return ok
+	}
+	return false
+}
+
+// isVolatileExpression returns true if the expression calls the commonly used volatile functions.
+func isVolatileExpression(expr *pgquery.Node) bool {
+	if expr == nil {
+		return false
+	}
+	text, err := pgquery.Deparse(&pgquery.ParseResult{
+		Stmts: []*pgquery.RawStmt{
+			{Stmt: &pgquery.Node{Node: &pgquery.Node_SelectStmt{SelectStmt: &pgquery.SelectStmt{
+				TargetList: []*pgquery.Node{pgquery.MakeResTargetNodeWithVal(expr, -1)},
+				Op:         pgquery.SetOperation_SETOP_NONE,
+			}}}},
+		},
+	})
+	if err != nil {
+		// Assume the worst if we cannot tell.
+		return true
+	}
+	return volatileFunctionRegexp.MatchString(text)
+}
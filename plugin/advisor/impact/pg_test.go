@@ -0,0 +1,201 @@
+package impact
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bytebase/bytebase/plugin/advisor/catalog"
+	advisorDB "github.com/bytebase/bytebase/plugin/advisor/db"
+)
+
+func TestAnalyzePostgres(t *testing.T) {
+	database := &catalog.Database{
+		Name:   "db",
+		DbType: advisorDB.Postgres,
+		SchemaList: []*catalog.Schema{
+			{
+				Name: "public",
+				TableList: []*catalog.Table{
+					{
+						Name:     "t",
+						RowCount: 10_000_000,
+						DataSize: 1 << 30,
+						ColumnList: []*catalog.Column{
+							{Name: "name", Type: "character varying"},
+							{Name: "id", Type: "integer"},
+						},
+						IndexList: []*catalog.Index{
+							{Name: "idx_t_name"},
+						},
+					},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		statement string
+		version   string
+		lock      LockLevel
+		lockMode  string
+		operation Operation
+		table     string
+	}{
+		{
+			statement: "ALTER TABLE t ADD COLUMN a int",
+			lock:      LockLevelExclusive,
+			lockMode:  "ACCESS EXCLUSIVE",
+			operation: OperationMetadata,
+			table:     "t",
+		},
+		{
+			statement: "ALTER TABLE t ADD COLUMN a int DEFAULT 0",
+			version:   "14.5",
+			lock:      LockLevelExclusive,
+			lockMode:  "ACCESS EXCLUSIVE",
+			operation: OperationMetadata,
+			table:     "t",
+		},
+		{
+			statement: "ALTER TABLE t ADD COLUMN a int DEFAULT 0",
+			version:   "10.21",
+			lock:      LockLevelExclusive,
+			lockMode:  "ACCESS EXCLUSIVE",
+			operation: OperationRewrite,
+			table:     "t",
+		},
+		{
+			statement: "ALTER TABLE t ADD COLUMN a timestamptz DEFAULT clock_timestamp()",
+			version:   "14.5",
+			lock:      LockLevelExclusive,
+			lockMode:  "ACCESS EXCLUSIVE",
+			operation: OperationRewrite,
+			table:     "t",
+		},
+		{
+			statement: "ALTER TABLE t ADD COLUMN a bigserial",
+			version:   "14.5",
+			lock:      LockLevelExclusive,
+			lockMode:  "ACCESS EXCLUSIVE",
+			operation: OperationRewrite,
+			table:     "t",
+		},
+		{
+			statement: "ALTER TABLE t ALTER COLUMN id TYPE bigint",
+			lock:      LockLevelExclusive,
+			lockMode:  "ACCESS EXCLUSIVE",
+			operation: OperationRewrite,
+			table:     "t",
+		},
+		{
+			statement: "ALTER TABLE t ALTER COLUMN name TYPE text",
+			lock:      LockLevelExclusive,
+			lockMode:  "ACCESS EXCLUSIVE",
+			operation: OperationMetadata,
+			table:     "t",
+		},
+		{
+			statement: "ALTER TABLE t ALTER COLUMN name SET NOT NULL",
+			lock:      LockLevelExclusive,
+			lockMode:  "ACCESS EXCLUSIVE",
+			operation: OperationScan,
+			table:     "t",
+		},
+		{
+			statement: "ALTER TABLE t ADD CONSTRAINT fk FOREIGN KEY (id) REFERENCES p (id)",
+			lock:      LockLevelShared,
+			lockMode:  "SHARE ROW EXCLUSIVE",
+			operation: OperationScan,
+			table:     "t",
+		},
+		{
+			statement: "ALTER TABLE t ADD CONSTRAINT fk FOREIGN KEY (id) REFERENCES p (id) NOT VALID",
+			lock:      LockLevelShared,
+			lockMode:  "SHARE ROW EXCLUSIVE",
+			operation: OperationMetadata,
+			table:     "t",
+		},
+		{
+			statement: "ALTER TABLE t VALIDATE CONSTRAINT fk",
+			lock:      LockLevelNone,
+			lockMode:  "SHARE UPDATE EXCLUSIVE",
+			operation: OperationScan,
+			table:     "t",
+		},
+		{
+			statement: "ALTER TABLE t SET (fillfactor = 70), ALTER COLUMN id TYPE bigint",
+			lock:      LockLevelExclusive,
+			lockMode:  "ACCESS EXCLUSIVE",
+			operation: OperationRewrite,
+			table:     "t",
+		},
+		{
+			statement: "CREATE INDEX idx_t_id ON t (id)",
+			lock:      LockLevelShared,
+			lockMode:  "SHARE",
+			operation: OperationScan,
+			table:     "t",
+		},
+		{
+			statement: "CREATE INDEX CONCURRENTLY idx_t_id ON t (id)",
+			lock:      LockLevelNone,
+			lockMode:  "SHARE UPDATE EXCLUSIVE",
+			operation: OperationScan,
+			table:     "t",
+		},
+		{
+			statement: "DROP INDEX idx_t_name",
+			lock:      LockLevelExclusive,
+			lockMode:  "ACCESS EXCLUSIVE",
+			operation: OperationMetadata,
+			table:     "t",
+		},
+		{
+			statement: "VACUUM FULL t",
+			lock:      LockLevelExclusive,
+			lockMode:  "ACCESS EXCLUSIVE",
+			operation: OperationRewrite,
+			table:     "t",
+		},
+		{
+			statement: "ALTER TABLE t RENAME COLUMN name TO title",
+			lock:      LockLevelExclusive,
+			lockMode:  "ACCESS EXCLUSIVE",
+			operation: OperationMetadata,
+			table:     "t",
+		},
+	}
+
+	for _, test := range tests {
+		impactList, err := Analyze(Context{DbType: advisorDB.Postgres, EngineVersion: test.version, Database: database}, test.statement)
+		require.NoError(t, err, test.statement)
+		require.Len(t, impactList, 1, test.statement)
+		impact := impactList[0]
+		require.Equal(t, test.lock, impact.Lock, test.statement)
+		require.Equal(t, test.lockMode, impact.LockMode, test.statement)
+		require.Equal(t, test.operation, impact.Operation, test.statement)
+		require.Equal(t, test.table, impact.Table, test.statement)
+		require.Equal(t, int64(10_000_000), impact.RowCount, test.statement)
+	}
+}
+
+func TestAnalyzePostgresStatementList(t *testing.T) {
+	statement := `CREATE TABLE p (id int);
+-- Add the index.
+CREATE INDEX idx_t_id
+  ON t (id);
+SELECT 1;
+ALTER TABLE s.t2 DROP COLUMN a;`
+	impactList, err := Analyze(Context{DbType: advisorDB.Postgres}, statement)
+	require.NoError(t, err)
+	require.Len(t, impactList, 2)
+	require.Equal(t, "public", impactList[0].Schema)
+	require.Equal(t, "t", impactList[0].Table)
+	require.Equal(t, 4, impactList[0].Line)
+	require.Equal(t, "-- Add the index.\nCREATE INDEX idx_t_id\n  ON t (id)", impactList[0].Statement)
+	require.Equal(t, "s", impactList[1].Schema)
+	require.Equal(t, "t2", impactList[1].Table)
+	require.Equal(t, 6, impactList[1].Line)
+	require.Equal(t, OperationMetadata, impactList[1].Operation)
+	require.Equal(t, int64(0), impactList[1].RowCount)
+}
@@ -0,0 +1,139 @@
+package pg
+
+import (
+	"fmt"
+
+	"github.com/bytebase/bytebase/plugin/advisor"
+	"github.com/bytebase/bytebase/plugin/advisor/db"
+	"github.com/bytebase/bytebase/plugin/parser/ast"
+)
+
+var (
+	_ advisor.Advisor = (*ColumnCommentConventionAdvisor)(nil)
+	_ ast.Visitor     = (*columnCommentConventionChecker)(nil)
+)
+
+func init() {
+	advisor.Register(db.Postgres, advisor.PostgreSQLColumnCommentConvention, &ColumnCommentConventionAdvisor{})
+}
+
+// ColumnCommentConventionAdvisor is the advisor checking for column comment convention.
+type ColumnCommentConventionAdvisor struct {
+}
+
+// Check checks for column comment convention.
+func (*ColumnCommentConventionAdvisor) Check(ctx advisor.Context, statement string) ([]advisor.Advice, error) {
+	stmts, errAdvice := parseStatement(statement)
+	if errAdvice != nil {
+		return errAdvice, nil
+	}
+
+	level, err := advisor.NewStatusBySQLReviewRuleLevel(ctx.Rule.Level)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := advisor.UnmarshalCommentConventionRulePayload(ctx.Rule.Payload)
+	if err != nil {
+		return nil, err
+	}
+	checker := &columnCommentConventionChecker{
+		level:           level,
+		title:           string(ctx.Rule.Type),
+		required:        payload.Required,
+		maxLength:       payload.MaxLength,
+		commentedColumn: make(map[columnName]bool),
+	}
+
+	for _, stmt := range stmts {
+		ast.Walk(checker, stmt)
+	}
+
+	return checker.generateAdvice(), nil
+}
+
+type columnCommentConventionChecker struct {
+	adviceList        []advisor.Advice
+	level             advisor.Status
+	title             string
+	required          bool
+	maxLength         int
+	createdColumnList []columnCreation
+	commentedColumn   map[columnName]bool
+}
+
+type columnCreation struct {
+	name columnName
+	line int
+}
+
+// Visit implements the ast.Visitor interface.
+func (checker *columnCommentConventionChecker) Visit(node ast.Node) ast.Visitor {
+	switch n := node.(type) {
+	// CREATE TABLE
+	case *ast.CreateTableStmt:
+		for _, column := range n.ColumnList {
+			checker.createdColumnList = append(checker.createdColumnList, columnCreation{
+				name: convertToColumnName(n.Name, column.ColumnName),
+				line: column.LastLine(),
+			})
+		}
+	// ALTER TABLE ADD COLUMN
+	case *ast.AlterTableStmt:
+		for _, item := range n.AlterItemList {
+			if addColumn, ok := item.(*ast.AddColumnListStmt); ok {
+				for _, column := range addColumn.ColumnList {
+					checker.createdColumnList = append(checker.createdColumnList, columnCreation{
+						name: convertToColumnName(n.Table, column.ColumnName),
+						line: n.LastLine(),
+					})
+				}
+			}
+		}
+	// COMMENT ON COLUMN
+	case *ast.CommentStmt:
+		column, ok := n.Object.(*ast.ColumnNameDef)
+		if n.Type != ast.ObjectTypeColumn || !ok {
+			break
+		}
+		name := convertToColumnName(column.Table, column.ColumnName)
+		checker.commentedColumn[name] = n.Comment != ""
+		if checker.maxLength >= 0 && len(n.Comment) > checker.maxLength {
+			checker.adviceList = append(checker.adviceList, advisor.Advice{
+				Status:  checker.level,
+				Code:    advisor.ColumnCommentTooLong,
+				Title:   checker.title,
+				Content: fmt.Sprintf("The length of column \"%s\" in %s comment should be within %d characters", name.column, name.normalizeTableName(), checker.maxLength),
+				Line:    n.LastLine(),
+			})
+		}
+	}
+	return checker
+}
+
+// generateAdvice generates the advice list.
+// PostgreSQL has no inline column comment, so the column created in the statements needs the COMMENT ON COLUMN statement in the same SQL.
+func (checker *columnCommentConventionChecker) generateAdvice() []advisor.Advice {
+	if checker.required {
+		for _, column := range checker.createdColumnList {
+			if !checker.commentedColumn[column.name] {
+				checker.adviceList = append(checker.adviceList, advisor.Advice{
+					Status:  checker.level,
+					Code:    advisor.NoColumnComment,
+					Title:   checker.title,
+					Content: fmt.Sprintf("Column \"%s\" in %s requires comments", column.name.column, column.name.normalizeTableName()),
+					Line:    column.line,
+				})
+			}
+		}
+	}
+
+	if len(checker.adviceList) == 0 {
+		checker.adviceList = append(checker.adviceList, advisor.Advice{
+			Status:  advisor.Success,
+			Code:    advisor.Ok,
+			Title:   "OK",
+			Content: "",
+		})
+	}
+	return checker.adviceList
+}
@@ -0,0 +1,83 @@
+package pg
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bytebase/bytebase/plugin/advisor"
+)
+
+func TestColumnCommentConvention(t *testing.T) {
+	tests := []advisor.TestCase{
+		{
+			Statement: `
+				CREATE TABLE t(a int, b int);
+				COMMENT ON COLUMN t.a IS 'comments';
+				COMMENT ON COLUMN public.t.b IS 'comments';`,
+			Want: []advisor.Advice{
+				{
+					Status:  advisor.Success,
+					Code:    advisor.Ok,
+					Title:   "OK",
+					Content: "",
+				},
+			},
+		},
+		{
+			Statement: `
+				CREATE TABLE t(
+					a int,
+					b int
+				);
+				COMMENT ON COLUMN t.a IS 'comments';`,
+			Want: []advisor.Advice{
+				{
+					Status:  advisor.Warn,
+					Code:    advisor.NoColumnComment,
+					Title:   "column.comment",
+					Content: `Column "b" in "public"."t" requires comments`,
+					Line:    4,
+				},
+			},
+		},
+		{
+			Statement: `
+				ALTER TABLE tech_book ADD COLUMN a int;
+				COMMENT ON COLUMN tech_book.a IS 'some very very very very very very long comments';`,
+			Want: []advisor.Advice{
+				{
+					Status:  advisor.Warn,
+					Code:    advisor.ColumnCommentTooLong,
+					Title:   "column.comment",
+					Content: `The length of column "a" in "public"."tech_book" comment should be within 20 characters`,
+					Line:    3,
+				},
+			},
+		},
+		{
+			Statement: "ALTER TABLE tech_book ADD COLUMN a int",
+			Want: []advisor.Advice{
+				{
+					Status:  advisor.Warn,
+					Code:    advisor.NoColumnComment,
+					Title:   "column.comment",
+					Content: `Column "a" in "public"."tech_book" requires comments`,
+					Line:    1,
+				},
+			},
+		},
+	}
+
+	payload, err := json.Marshal(advisor.CommentConventionRulePayload{
+		Required:  true,
+		MaxLength: 20,
+	})
+	require.NoError(t, err)
+	advisor.RunSQLReviewRuleTests(t, tests, &ColumnCommentConventionAdvisor{}, &advisor.SQLReviewRule{
+		Type:    advisor.SchemaRuleColumnCommentConvention,
+		Level:   advisor.SchemaRuleLevelWarning,
+		Payload: string(payload),
+	}, advisor.MockPostgreSQLDatabase)
+}
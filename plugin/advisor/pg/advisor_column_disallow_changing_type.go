@@ -0,0 +1,78 @@
+package pg
+
+import (
+	"fmt"
+
+	"github.com/bytebase/bytebase/plugin/advisor"
+	"github.com/bytebase/bytebase/plugin/advisor/db"
+	"github.com/bytebase/bytebase/plugin/parser/ast"
+)
+
+var (
+	_ advisor.Advisor = (*ColumnDisallowChangingTypeAdvisor)(nil)
+	_ ast.Visitor     = (*columnDisallowChangingTypeChecker)(nil)
+)
+
+func init() {
+	advisor.Register(db.Postgres, advisor.PostgreSQLColumnDisallowChangingType, &ColumnDisallowChangingTypeAdvisor{})
+}
+
+// ColumnDisallowChangingTypeAdvisor is the advisor checking for disallow changing column type.
+type ColumnDisallowChangingTypeAdvisor struct {
+}
+
+// Check checks for disallow changing column type.
+func (*ColumnDisallowChangingTypeAdvisor) Check(ctx advisor.Context, statement string) ([]advisor.Advice, error) {
+	stmts, errAdvice := parseStatement(statement)
+	if errAdvice != nil {
+		return errAdvice, nil
+	}
+
+	level, err := advisor.NewStatusBySQLReviewRuleLevel(ctx.Rule.Level)
+	if err != nil {
+		return nil, err
+	}
+	checker := &columnDisallowChangingTypeChecker{
+		level: level,
+		title: string(ctx.Rule.Type),
+	}
+
+	for _, stmt := range stmts {
+		ast.Walk(checker, stmt)
+	}
+
+	if len(checker.adviceList) == 0 {
+		checker.adviceList = append(checker.adviceList, advisor.Advice{
+			Status:  advisor.Success,
+			Code:    advisor.Ok,
+			Title:   "OK",
+			Content: "",
+		})
+	}
+	return checker.adviceList, nil
+}
+
+type columnDisallowChangingTypeChecker struct {
+	adviceList []advisor.Advice
+	level      advisor.Status
+	title      string
+}
+
+// Visit implements the ast.Visitor interface.
+func (checker *columnDisallowChangingTypeChecker) Visit(node ast.Node) ast.Visitor {
+	if n, ok := node.(*ast.AlterTableStmt); ok {
+		for _, item := range n.AlterItemList {
+			if _, ok := item.(*ast.AlterColumnTypeStmt); ok {
+				checker.adviceList = append(checker.adviceList, advisor.Advice{
+					Status:  checker.level,
+					Code:    advisor.ChangeColumnType,
+					Title:   checker.title,
+					Content: fmt.Sprintf("\"%s\" changes column type", n.Text()),
+					Line:    n.LastLine(),
+				})
+				break
+			}
+		}
+	}
+	return checker
+}
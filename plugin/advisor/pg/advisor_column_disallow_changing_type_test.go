@@ -0,0 +1,41 @@
+package pg
+
+import (
+	"testing"
+
+	"github.com/bytebase/bytebase/plugin/advisor"
+)
+
+func TestColumnDisallowChangingType(t *testing.T) {
+	tests := []advisor.TestCase{
+		{
+			Statement: "ALTER TABLE tech_book ALTER COLUMN id TYPE bigint",
+			Want: []advisor.Advice{
+				{
+					Status:  advisor.Warn,
+					Code:    advisor.ChangeColumnType,
+					Title:   "column.disallow-change-type",
+					Content: "\"ALTER TABLE tech_book ALTER COLUMN id TYPE bigint\" changes column type",
+					Line:    1,
+				},
+			},
+		},
+		{
+			Statement: "ALTER TABLE tech_book ALTER COLUMN id SET NOT NULL",
+			Want: []advisor.Advice{
+				{
+					Status:  advisor.Success,
+					Code:    advisor.Ok,
+					Title:   "OK",
+					Content: "",
+				},
+			},
+		},
+	}
+
+	advisor.RunSQLReviewRuleTests(t, tests, &ColumnDisallowChangingTypeAdvisor{}, &advisor.SQLReviewRule{
+		Type:    advisor.SchemaRuleColumnDisallowChangeType,
+		Level:   advisor.SchemaRuleLevelWarning,
+		Payload: "",
+	}, advisor.MockPostgreSQLDatabase)
+}
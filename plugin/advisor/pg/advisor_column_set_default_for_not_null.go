@@ -0,0 +1,131 @@
+package pg
+
+import (
+	"fmt"
+
+	"github.com/bytebase/bytebase/plugin/advisor"
+	"github.com/bytebase/bytebase/plugin/advisor/db"
+	"github.com/bytebase/bytebase/plugin/parser/ast"
+)
+
+var (
+	_ advisor.Advisor = (*ColumnSetDefaultForNotNullAdvisor)(nil)
+	_ ast.Visitor     = (*columnSetDefaultForNotNullChecker)(nil)
+)
+
+func init() {
+	advisor.Register(db.Postgres, advisor.PostgreSQLColumnSetDefaultForNotNull, &ColumnSetDefaultForNotNullAdvisor{})
+}
+
+// ColumnSetDefaultForNotNullAdvisor is the advisor checking for set default value for not null column.
+type ColumnSetDefaultForNotNullAdvisor struct {
+}
+
+// Check checks for set default value for not null column.
+func (*ColumnSetDefaultForNotNullAdvisor) Check(ctx advisor.Context, statement string) ([]advisor.Advice, error) {
+	stmts, errAdvice := parseStatement(statement)
+	if errAdvice != nil {
+		return errAdvice, nil
+	}
+
+	level, err := advisor.NewStatusBySQLReviewRuleLevel(ctx.Rule.Level)
+	if err != nil {
+		return nil, err
+	}
+	checker := &columnSetDefaultForNotNullChecker{
+		level: level,
+		title: string(ctx.Rule.Type),
+	}
+
+	for _, stmt := range stmts {
+		ast.Walk(checker, stmt)
+	}
+
+	if len(checker.adviceList) == 0 {
+		checker.adviceList = append(checker.adviceList, advisor.Advice{
+			Status:  advisor.Success,
+			Code:    advisor.Ok,
+			Title:   "OK",
+			Content: "",
+		})
+	}
+	return checker.adviceList, nil
+}
+
+type columnSetDefaultForNotNullChecker struct {
+	adviceList []advisor.Advice
+	level      advisor.Status
+	title      string
+}
+
+// Visit implements the ast.Visitor interface.
+func (checker *columnSetDefaultForNotNullChecker) Visit(node ast.Node) ast.Visitor {
+	var notNullColumnWithNoDefault []columnName
+	var lineList []int
+	switch n := node.(type) {
+	// CREATE TABLE
+	case *ast.CreateTableStmt:
+		pkColumn := make(map[string]bool)
+		for _, constraint := range n.ConstraintList {
+			if constraint.Type == ast.ConstraintTypePrimary {
+				for _, key := range constraint.KeyList {
+					pkColumn[key] = true
+				}
+			}
+		}
+
+		for _, column := range n.ColumnList {
+			notNull := pkColumn[column.ColumnName] || isNotNullColumn(column)
+			if notNull && !hasDefault(column) {
+				notNullColumnWithNoDefault = append(notNullColumnWithNoDefault, convertToColumnName(n.Name, column.ColumnName))
+				lineList = append(lineList, column.LastLine())
+			}
+		}
+	// ALTER TABLE ADD COLUMN
+	case *ast.AlterTableStmt:
+		for _, item := range n.AlterItemList {
+			if addColumn, ok := item.(*ast.AddColumnListStmt); ok {
+				for _, column := range addColumn.ColumnList {
+					if isNotNullColumn(column) && !hasDefault(column) {
+						notNullColumnWithNoDefault = append(notNullColumnWithNoDefault, convertToColumnName(n.Table, column.ColumnName))
+						lineList = append(lineList, n.LastLine())
+					}
+				}
+			}
+		}
+	}
+
+	for i, column := range notNullColumnWithNoDefault {
+		checker.adviceList = append(checker.adviceList, advisor.Advice{
+			Status:  checker.level,
+			Code:    advisor.NotNullColumnWithNullDefault,
+			Title:   checker.title,
+			Content: fmt.Sprintf("Column \"%s\" in %s is NOT NULL but has NULL default value", column.column, column.normalizeTableName()),
+			Line:    lineList[i],
+		})
+	}
+	return checker
+}
+
+func isNotNullColumn(column *ast.ColumnDef) bool {
+	for _, constraint := range column.ConstraintList {
+		if constraint.Type == ast.ConstraintTypeNotNull || constraint.Type == ast.ConstraintTypePrimary {
+			return true
+		}
+	}
+	return false
+}
+
+// hasDefault returns whether the column has a default value.
+// The serial types always have an implicit default value from the sequence.
+func hasDefault(column *ast.ColumnDef) bool {
+	if _, ok := column.Type.(*ast.Serial); ok {
+		return true
+	}
+	for _, constraint := range column.ConstraintList {
+		if constraint.Type == ast.ConstraintTypeDefault {
+			return true
+		}
+	}
+	return false
+}
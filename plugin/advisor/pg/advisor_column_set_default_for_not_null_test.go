@@ -0,0 +1,71 @@
+package pg
+
+import (
+	"testing"
+
+	"github.com/bytebase/bytebase/plugin/advisor"
+)
+
+func TestColumnSetDefaultForNotNull(t *testing.T) {
+	tests := []advisor.TestCase{
+		{
+			Statement: `
+				CREATE TABLE t(
+					a int NOT NULL,
+					b int NOT NULL DEFAULT 1,
+					c int,
+					d serial PRIMARY KEY
+				)`,
+			Want: []advisor.Advice{
+				{
+					Status:  advisor.Warn,
+					Code:    advisor.NotNullColumnWithNullDefault,
+					Title:   "column.set-default-for-not-null",
+					Content: `Column "a" in "public"."t" is NOT NULL but has NULL default value`,
+					Line:    3,
+				},
+			},
+		},
+		{
+			Statement: "CREATE TABLE t(a int, b int, PRIMARY KEY (a))",
+			Want: []advisor.Advice{
+				{
+					Status:  advisor.Warn,
+					Code:    advisor.NotNullColumnWithNullDefault,
+					Title:   "column.set-default-for-not-null",
+					Content: `Column "a" in "public"."t" is NOT NULL but has NULL default value`,
+					Line:    1,
+				},
+			},
+		},
+		{
+			Statement: "ALTER TABLE tech_book ADD COLUMN a int NOT NULL, ADD COLUMN b int NOT NULL DEFAULT 0",
+			Want: []advisor.Advice{
+				{
+					Status:  advisor.Warn,
+					Code:    advisor.NotNullColumnWithNullDefault,
+					Title:   "column.set-default-for-not-null",
+					Content: `Column "a" in "public"."tech_book" is NOT NULL but has NULL default value`,
+					Line:    1,
+				},
+			},
+		},
+		{
+			Statement: "ALTER TABLE tech_book ADD COLUMN a int",
+			Want: []advisor.Advice{
+				{
+					Status:  advisor.Success,
+					Code:    advisor.Ok,
+					Title:   "OK",
+					Content: "",
+				},
+			},
+		},
+	}
+
+	advisor.RunSQLReviewRuleTests(t, tests, &ColumnSetDefaultForNotNullAdvisor{}, &advisor.SQLReviewRule{
+		Type:    advisor.SchemaRuleColumnSetDefaultForNotNull,
+		Level:   advisor.SchemaRuleLevelWarning,
+		Payload: "",
+	}, advisor.MockPostgreSQLDatabase)
+}
@@ -0,0 +1,107 @@
+package pg
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bytebase/bytebase/plugin/advisor"
+	"github.com/bytebase/bytebase/plugin/advisor/db"
+	"github.com/bytebase/bytebase/plugin/parser/ast"
+)
+
+var (
+	_ advisor.Advisor = (*ColumnTypeRestrictionAdvisor)(nil)
+	_ ast.Visitor     = (*columnTypeRestrictionChecker)(nil)
+)
+
+func init() {
+	advisor.Register(db.Postgres, advisor.PostgreSQLColumnTypeRestriction, &ColumnTypeRestrictionAdvisor{})
+}
+
+// ColumnTypeRestrictionAdvisor is the advisor checking for column type restriction.
+type ColumnTypeRestrictionAdvisor struct {
+}
+
+// Check checks for column type restriction.
+func (*ColumnTypeRestrictionAdvisor) Check(ctx advisor.Context, statement string) ([]advisor.Advice, error) {
+	stmts, errAdvice := parseStatement(statement)
+	if errAdvice != nil {
+		return errAdvice, nil
+	}
+
+	level, err := advisor.NewStatusBySQLReviewRuleLevel(ctx.Rule.Level)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := advisor.UnmarshalTypeRestrictionRulePayload(ctx.Rule.Payload)
+	if err != nil {
+		return nil, err
+	}
+	checker := &columnTypeRestrictionChecker{
+		level:    level,
+		title:    string(ctx.Rule.Type),
+		typeList: payload.TypeList,
+	}
+
+	for _, stmt := range stmts {
+		ast.Walk(checker, stmt)
+	}
+
+	if len(checker.adviceList) == 0 {
+		checker.adviceList = append(checker.adviceList, advisor.Advice{
+			Status:  advisor.Success,
+			Code:    advisor.Ok,
+			Title:   "OK",
+			Content: "",
+		})
+	}
+	return checker.adviceList, nil
+}
+
+type columnTypeRestrictionChecker struct {
+	adviceList []advisor.Advice
+	level      advisor.Status
+	title      string
+	typeList   []string
+}
+
+// Visit implements the ast.Visitor interface.
+func (checker *columnTypeRestrictionChecker) Visit(node ast.Node) ast.Visitor {
+	switch n := node.(type) {
+	// CREATE TABLE
+	case *ast.CreateTableStmt:
+		for _, column := range n.ColumnList {
+			checker.check(n.Name, column.ColumnName, column.Type, column.LastLine())
+		}
+	// ALTER TABLE
+	case *ast.AlterTableStmt:
+		for _, item := range n.AlterItemList {
+			switch cmd := item.(type) {
+			// ALTER TABLE ADD COLUMN
+			case *ast.AddColumnListStmt:
+				for _, column := range cmd.ColumnList {
+					checker.check(n.Table, column.ColumnName, column.Type, n.LastLine())
+				}
+			// ALTER TABLE ALTER COLUMN TYPE
+			case *ast.AlterColumnTypeStmt:
+				checker.check(n.Table, cmd.ColumnName, cmd.Type, n.LastLine())
+			}
+		}
+	}
+	return checker
+}
+
+func (checker *columnTypeRestrictionChecker) check(table *ast.TableDef, column string, dataType ast.DataType, line int) {
+	for _, tp := range checker.typeList {
+		if dataType.EquivalentType(tp) {
+			checker.adviceList = append(checker.adviceList, advisor.Advice{
+				Status:  checker.level,
+				Code:    advisor.DisabledColumnType,
+				Title:   checker.title,
+				Content: fmt.Sprintf("Disallow column type %s but column \"%s\" in %s is", strings.ToUpper(tp), column, normalizeTableName(table)),
+				Line:    line,
+			})
+			return
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package pg
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bytebase/bytebase/plugin/advisor"
+)
+
+func TestColumnTypeRestriction(t *testing.T) {
+	tests := []advisor.TestCase{
+		{
+			Statement: `
+				CREATE TABLE t(
+					a int,
+					b json,
+					c bigint
+				)`,
+			Want: []advisor.Advice{
+				{
+					Status:  advisor.Warn,
+					Code:    advisor.DisabledColumnType,
+					Title:   "column.type-restriction",
+					Content: `Disallow column type JSON but column "b" in "public"."t" is`,
+					Line:    4,
+				},
+				{
+					Status:  advisor.Warn,
+					Code:    advisor.DisabledColumnType,
+					Title:   "column.type-restriction",
+					Content: `Disallow column type BIGINT but column "c" in "public"."t" is`,
+					Line:    5,
+				},
+			},
+		},
+		{
+			Statement: "ALTER TABLE tech_book ADD COLUMN a int8",
+			Want: []advisor.Advice{
+				{
+					Status:  advisor.Warn,
+					Code:    advisor.DisabledColumnType,
+					Title:   "column.type-restriction",
+					Content: `Disallow column type BIGINT but column "a" in "public"."tech_book" is`,
+					Line:    1,
+				},
+			},
+		},
+		{
+			Statement: "ALTER TABLE tech_book ALTER COLUMN id TYPE json",
+			Want: []advisor.Advice{
+				{
+					Status:  advisor.Warn,
+					Code:    advisor.DisabledColumnType,
+					Title:   "column.type-restriction",
+					Content: `Disallow column type JSON but column "id" in "public"."tech_book" is`,
+					Line:    1,
+				},
+			},
+		},
+		{
+			Statement: "CREATE TABLE t(a int, b varchar(20))",
+			Want: []advisor.Advice{
+				{
+					Status:  advisor.Success,
+					Code:    advisor.Ok,
+					Title:   "OK",
+					Content: "",
+				},
+			},
+		},
+	}
+
+	payload, err := json.Marshal(advisor.TypeRestrictionRulePayload{
+		TypeList: []string{"JSON", "BIGINT"},
+	})
+	require.NoError(t, err)
+	advisor.RunSQLReviewRuleTests(t, tests, &ColumnTypeRestrictionAdvisor{}, &advisor.SQLReviewRule{
+		Type:    advisor.SchemaRuleColumnTypeRestriction,
+		Level:   advisor.SchemaRuleLevelWarning,
+		Payload: string(payload),
+	}, advisor.MockPostgreSQLDatabase)
+}
@@ -0,0 +1,86 @@
+package pg
+
+import (
+	"fmt"
+
+	"github.com/bytebase/bytebase/plugin/advisor"
+	"github.com/bytebase/bytebase/plugin/advisor/catalog"
+	"github.com/bytebase/bytebase/plugin/advisor/db"
+	"github.com/bytebase/bytebase/plugin/parser/ast"
+)
+
+var (
+	_ advisor.Advisor = (*DatabaseAllowDropIfEmptyAdvisor)(nil)
+	_ ast.Visitor     = (*allowDropEmptyDBChecker)(nil)
+)
+
+func init() {
+	advisor.Register(db.Postgres, advisor.PostgreSQLDatabaseAllowDropIfEmpty, &DatabaseAllowDropIfEmptyAdvisor{})
+}
+
+// DatabaseAllowDropIfEmptyAdvisor is the advisor checking the PostgreSQLDatabaseAllowDropIfEmpty rule.
+type DatabaseAllowDropIfEmptyAdvisor struct {
+}
+
+// Check checks for dropping non-empty databases.
+func (*DatabaseAllowDropIfEmptyAdvisor) Check(ctx advisor.Context, statement string) ([]advisor.Advice, error) {
+	stmts, errAdvice := parseStatement(statement)
+	if errAdvice != nil {
+		return errAdvice, nil
+	}
+
+	level, err := advisor.NewStatusBySQLReviewRuleLevel(ctx.Rule.Level)
+	if err != nil {
+		return nil, err
+	}
+	checker := &allowDropEmptyDBChecker{
+		level:   level,
+		title:   string(ctx.Rule.Type),
+		catalog: ctx.Catalog,
+	}
+
+	for _, stmt := range stmts {
+		ast.Walk(checker, stmt)
+	}
+
+	if len(checker.adviceList) == 0 {
+		checker.adviceList = append(checker.adviceList, advisor.Advice{
+			Status:  advisor.Success,
+			Code:    advisor.Ok,
+			Title:   "OK",
+			Content: "",
+		})
+	}
+	return checker.adviceList, nil
+}
+
+type allowDropEmptyDBChecker struct {
+	adviceList []advisor.Advice
+	level      advisor.Status
+	title      string
+	catalog    *catalog.Finder
+}
+
+// Visit implements the ast.Visitor interface.
+func (checker *allowDropEmptyDBChecker) Visit(node ast.Node) ast.Visitor {
+	if n, ok := node.(*ast.DropDatabaseStmt); ok {
+		if checker.catalog.Origin.DatabaseName() != n.DatabaseName {
+			checker.adviceList = append(checker.adviceList, advisor.Advice{
+				Status:  checker.level,
+				Code:    advisor.NotCurrentDatabase,
+				Title:   checker.title,
+				Content: fmt.Sprintf("Database \"%s\" that is trying to be deleted is not the current database \"%s\"", n.DatabaseName, checker.catalog.Origin.DatabaseName()),
+				Line:    n.LastLine(),
+			})
+		} else if !checker.catalog.Origin.HasNoTable() {
+			checker.adviceList = append(checker.adviceList, advisor.Advice{
+				Status:  checker.level,
+				Code:    advisor.DatabaseNotEmpty,
+				Title:   checker.title,
+				Content: fmt.Sprintf("Database \"%s\" is not allowed to drop if not empty", n.DatabaseName),
+				Line:    n.LastLine(),
+			})
+		}
+	}
+	return checker
+}
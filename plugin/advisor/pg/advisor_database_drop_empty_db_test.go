@@ -0,0 +1,53 @@
+package pg
+
+import (
+	"testing"
+
+	"github.com/bytebase/bytebase/plugin/advisor"
+)
+
+func TestDatabaseAllowDropIfEmpty(t *testing.T) {
+	tests := []advisor.TestCase{
+		{
+			Statement: "DROP DATABASE test",
+			Want: []advisor.Advice{
+				{
+					Status:  advisor.Warn,
+					Code:    advisor.DatabaseNotEmpty,
+					Title:   "database.drop-empty-database",
+					Content: "Database \"test\" is not allowed to drop if not empty",
+					Line:    1,
+				},
+			},
+		},
+		{
+			Statement: "DROP DATABASE IF EXISTS foo",
+			Want: []advisor.Advice{
+				{
+					Status:  advisor.Warn,
+					Code:    advisor.NotCurrentDatabase,
+					Title:   "database.drop-empty-database",
+					Content: "Database \"foo\" that is trying to be deleted is not the current database \"test\"",
+					Line:    1,
+				},
+			},
+		},
+		{
+			Statement: "CREATE TABLE t(a int)",
+			Want: []advisor.Advice{
+				{
+					Status:  advisor.Success,
+					Code:    advisor.Ok,
+					Title:   "OK",
+					Content: "",
+				},
+			},
+		},
+	}
+
+	advisor.RunSQLReviewRuleTests(t, tests, &DatabaseAllowDropIfEmptyAdvisor{}, &advisor.SQLReviewRule{
+		Type:    advisor.SchemaRuleDropEmptyDatabase,
+		Level:   advisor.SchemaRuleLevelWarning,
+		Payload: "",
+	}, advisor.MockPostgreSQLDatabase)
+}
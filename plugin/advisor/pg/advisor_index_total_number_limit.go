@@ -0,0 +1,177 @@
+package pg
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/bytebase/bytebase/plugin/advisor"
+	"github.com/bytebase/bytebase/plugin/advisor/catalog"
+	"github.com/bytebase/bytebase/plugin/advisor/db"
+	"github.com/bytebase/bytebase/plugin/parser/ast"
+)
+
+var (
+	_ advisor.Advisor = (*IndexTotalNumberLimitAdvisor)(nil)
+	_ ast.Visitor     = (*indexTotalNumberLimitChecker)(nil)
+)
+
+func init() {
+	advisor.Register(db.Postgres, advisor.PostgreSQLIndexTotalNumberLimit, &IndexTotalNumberLimitAdvisor{})
+}
+
+// IndexTotalNumberLimitAdvisor is the advisor checking for index total number limit.
+type IndexTotalNumberLimitAdvisor struct {
+}
+
+// Check checks for index total number limit.
+func (*IndexTotalNumberLimitAdvisor) Check(ctx advisor.Context, statement string) ([]advisor.Advice, error) {
+	stmts, errAdvice := parseStatement(statement)
+	if errAdvice != nil {
+		return errAdvice, nil
+	}
+
+	level, err := advisor.NewStatusBySQLReviewRuleLevel(ctx.Rule.Level)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := advisor.UnmarshalNumberTypeRulePayload(ctx.Rule.Payload)
+	if err != nil {
+		return nil, err
+	}
+	checker := &indexTotalNumberLimitChecker{
+		level:    level,
+		title:    string(ctx.Rule.Type),
+		max:      payload.Number,
+		catalog:  ctx.Catalog,
+		tableMap: make(map[columnName]*tableIndexCount),
+	}
+
+	for _, stmt := range stmts {
+		ast.Walk(checker, stmt)
+	}
+
+	return checker.generateAdvice(), nil
+}
+
+type indexTotalNumberLimitChecker struct {
+	adviceList []advisor.Advice
+	level      advisor.Status
+	title      string
+	max        int
+	catalog    *catalog.Finder
+	// tableMap uses the columnName with empty column as the table key.
+	tableMap map[columnName]*tableIndexCount
+}
+
+type tableIndexCount struct {
+	name  columnName
+	count int
+	line  int
+}
+
+// Visit implements the ast.Visitor interface.
+func (checker *indexTotalNumberLimitChecker) Visit(node ast.Node) ast.Visitor {
+	switch n := node.(type) {
+	// CREATE TABLE
+	case *ast.CreateTableStmt:
+		table := checker.getTable(n.Name, false /* exists */)
+		table.count = 0
+		for _, column := range n.ColumnList {
+			table.count += countNewIndex(column.ConstraintList)
+		}
+		table.count += countNewIndex(n.ConstraintList)
+		table.line = n.LastLine()
+	// CREATE INDEX
+	case *ast.CreateIndexStmt:
+		table := checker.getTable(n.Index.Table, true /* exists */)
+		table.count++
+		table.line = n.LastLine()
+	// ALTER TABLE
+	case *ast.AlterTableStmt:
+		count := 0
+		for _, item := range n.AlterItemList {
+			switch cmd := item.(type) {
+			// ALTER TABLE ADD COLUMN
+			case *ast.AddColumnListStmt:
+				for _, column := range cmd.ColumnList {
+					count += countNewIndex(column.ConstraintList)
+				}
+			// ALTER TABLE ADD CONSTRAINT
+			case *ast.AddConstraintStmt:
+				count += countNewIndex([]*ast.ConstraintDef{cmd.Constraint})
+			}
+		}
+		if count > 0 {
+			table := checker.getTable(n.Table, true /* exists */)
+			table.count += count
+			table.line = n.LastLine()
+		}
+	}
+	return checker
+}
+
+// getTable gets the index count for the table.
+// For the existing table, the initial count is the index number in the catalog.
+// The PostgreSQL advisors are checked against the original catalog, so only the indexes created by the statements are accumulated.
+func (checker *indexTotalNumberLimitChecker) getTable(tableDef *ast.TableDef, exists bool) *tableIndexCount {
+	key := convertToColumnName(tableDef, "")
+	if table, ok := checker.tableMap[key]; ok {
+		return table
+	}
+	table := &tableIndexCount{name: key}
+	if exists {
+		tableInfo := checker.catalog.Origin.FindTable(&catalog.TableFind{
+			SchemaName: key.schema,
+			TableName:  key.table,
+		})
+		if tableInfo != nil {
+			table.count = tableInfo.CountIndex()
+		}
+	}
+	checker.tableMap[key] = table
+	return table
+}
+
+func (checker *indexTotalNumberLimitChecker) generateAdvice() []advisor.Advice {
+	var tableList []*tableIndexCount
+	for _, table := range checker.tableMap {
+		tableList = append(tableList, table)
+	}
+	sort.Slice(tableList, func(i, j int) bool {
+		return tableList[i].line < tableList[j].line
+	})
+
+	for _, table := range tableList {
+		if table.count > checker.max {
+			checker.adviceList = append(checker.adviceList, advisor.Advice{
+				Status:  checker.level,
+				Code:    advisor.IndexCountExceedsLimit,
+				Title:   checker.title,
+				Content: fmt.Sprintf("The count of index in table %s should be no more than %d, but found %d", table.name.normalizeTableName(), checker.max, table.count),
+				Line:    table.line,
+			})
+		}
+	}
+
+	if len(checker.adviceList) == 0 {
+		checker.adviceList = append(checker.adviceList, advisor.Advice{
+			Status:  advisor.Success,
+			Code:    advisor.Ok,
+			Title:   "OK",
+			Content: "",
+		})
+	}
+	return checker.adviceList
+}
+
+// countNewIndex counts the indexes implicitly created by the constraints.
+func countNewIndex(constraintList []*ast.ConstraintDef) int {
+	count := 0
+	for _, constraint := range constraintList {
+		switch constraint.Type {
+		case ast.ConstraintTypePrimary, ast.ConstraintTypeUnique:
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,75 @@
+package pg
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bytebase/bytebase/plugin/advisor"
+)
+
+func TestIndexTotalNumberLimit(t *testing.T) {
+	tests := []advisor.TestCase{
+		{
+			Statement: `
+				CREATE TABLE t(
+					a int PRIMARY KEY,
+					b int UNIQUE,
+					c int,
+					UNIQUE (b, c)
+				);
+				CREATE INDEX idx_t_c ON t(c);
+				CREATE INDEX idx_tech_book_id ON tech_book(id);`,
+			Want: []advisor.Advice{
+				{
+					Status:  advisor.Warn,
+					Code:    advisor.IndexCountExceedsLimit,
+					Title:   "index.total-number-limit",
+					Content: `The count of index in table "public"."t" should be no more than 3, but found 4`,
+					Line:    8,
+				},
+				{
+					Status:  advisor.Warn,
+					Code:    advisor.IndexCountExceedsLimit,
+					Title:   "index.total-number-limit",
+					Content: `The count of index in table "public"."tech_book" should be no more than 3, but found 4`,
+					Line:    9,
+				},
+			},
+		},
+		{
+			Statement: "ALTER TABLE tech_book ADD COLUMN a int, ADD COLUMN b int",
+			Want: []advisor.Advice{
+				{
+					Status:  advisor.Success,
+					Code:    advisor.Ok,
+					Title:   "OK",
+					Content: "",
+				},
+			},
+		},
+		{
+			Statement: "ALTER TABLE tech_book ADD CONSTRAINT uk_tech_book_id UNIQUE (id)",
+			Want: []advisor.Advice{
+				{
+					Status:  advisor.Warn,
+					Code:    advisor.IndexCountExceedsLimit,
+					Title:   "index.total-number-limit",
+					Content: `The count of index in table "public"."tech_book" should be no more than 3, but found 4`,
+					Line:    1,
+				},
+			},
+		},
+	}
+
+	payload, err := json.Marshal(advisor.NumberTypeRulePayload{
+		Number: 3,
+	})
+	require.NoError(t, err)
+	advisor.RunSQLReviewRuleTests(t, tests, &IndexTotalNumberLimitAdvisor{}, &advisor.SQLReviewRule{
+		Type:    advisor.SchemaRuleIndexTotalNumberLimit,
+		Level:   advisor.SchemaRuleLevelWarning,
+		Payload: string(payload),
+	}, advisor.MockPostgreSQLDatabase)
+}
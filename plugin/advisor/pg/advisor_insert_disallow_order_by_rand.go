@@ -0,0 +1,87 @@
+package pg
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bytebase/bytebase/plugin/advisor"
+	"github.com/bytebase/bytebase/plugin/advisor/db"
+	"github.com/bytebase/bytebase/plugin/parser/ast"
+)
+
+var (
+	_ advisor.Advisor = (*InsertDisallowOrderByRandAdvisor)(nil)
+	_ ast.Visitor     = (*insertDisallowOrderByRandChecker)(nil)
+)
+
+func init() {
+	advisor.Register(db.Postgres, advisor.PostgreSQLInsertDisallowOrderByRand, &InsertDisallowOrderByRandAdvisor{})
+}
+
+// InsertDisallowOrderByRandAdvisor is the advisor checking for to disallow order by random when inserting.
+type InsertDisallowOrderByRandAdvisor struct {
+}
+
+// Check checks for to disallow order by random when inserting.
+func (*InsertDisallowOrderByRandAdvisor) Check(ctx advisor.Context, statement string) ([]advisor.Advice, error) {
+	stmts, errAdvice := parseStatement(statement)
+	if errAdvice != nil {
+		return errAdvice, nil
+	}
+
+	level, err := advisor.NewStatusBySQLReviewRuleLevel(ctx.Rule.Level)
+	if err != nil {
+		return nil, err
+	}
+	checker := &insertDisallowOrderByRandChecker{
+		level: level,
+		title: string(ctx.Rule.Type),
+	}
+
+	for _, stmt := range stmts {
+		ast.Walk(checker, stmt)
+	}
+
+	if len(checker.adviceList) == 0 {
+		checker.adviceList = append(checker.adviceList, advisor.Advice{
+			Status:  advisor.Success,
+			Code:    advisor.Ok,
+			Title:   "OK",
+			Content: "",
+		})
+	}
+	return checker.adviceList, nil
+}
+
+type insertDisallowOrderByRandChecker struct {
+	adviceList []advisor.Advice
+	level      advisor.Status
+	title      string
+}
+
+// Visit implements the ast.Visitor interface.
+func (checker *insertDisallowOrderByRandChecker) Visit(node ast.Node) ast.Visitor {
+	if n, ok := node.(*ast.InsertStmt); ok && n.Select != nil {
+		for _, item := range n.Select.OrderByClause {
+			if isRandomFunction(item.Expression) {
+				checker.adviceList = append(checker.adviceList, advisor.Advice{
+					Status:  checker.level,
+					Code:    advisor.InsertUseOrderByRand,
+					Title:   checker.title,
+					Content: fmt.Sprintf("\"%s\" uses ORDER BY random() in the INSERT statement", n.Text()),
+					Line:    n.LastLine(),
+				})
+				break
+			}
+		}
+	}
+	return checker
+}
+
+func isRandomFunction(expression ast.ExpressionNode) bool {
+	function, ok := expression.(*ast.FuncCallDef)
+	if !ok {
+		return false
+	}
+	return strings.ToLower(function.Name) == "random" && (function.Schema == "" || function.Schema == "pg_catalog")
+}
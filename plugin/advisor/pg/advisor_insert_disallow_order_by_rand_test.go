@@ -0,0 +1,52 @@
+package pg
+
+import (
+	"testing"
+
+	"github.com/bytebase/bytebase/plugin/advisor"
+)
+
+func TestInsertDisallowOrderByRand(t *testing.T) {
+	tests := []advisor.TestCase{
+		{
+			Statement: "INSERT INTO tech_book SELECT * FROM tech_book_copy ORDER BY random()",
+			Want: []advisor.Advice{
+				{
+					Status:  advisor.Warn,
+					Code:    advisor.InsertUseOrderByRand,
+					Title:   "insert.disallow-order-by-rand",
+					Content: "\"INSERT INTO tech_book SELECT * FROM tech_book_copy ORDER BY random()\" uses ORDER BY random() in the INSERT statement",
+					Line:    1,
+				},
+			},
+		},
+		{
+			Statement: "INSERT INTO tech_book SELECT * FROM tech_book_copy ORDER BY id",
+			Want: []advisor.Advice{
+				{
+					Status:  advisor.Success,
+					Code:    advisor.Ok,
+					Title:   "OK",
+					Content: "",
+				},
+			},
+		},
+		{
+			Statement: "INSERT INTO tech_book VALUES(1, 'a')",
+			Want: []advisor.Advice{
+				{
+					Status:  advisor.Success,
+					Code:    advisor.Ok,
+					Title:   "OK",
+					Content: "",
+				},
+			},
+		},
+	}
+
+	advisor.RunSQLReviewRuleTests(t, tests, &InsertDisallowOrderByRandAdvisor{}, &advisor.SQLReviewRule{
+		Type:    advisor.SchemaRuleInsertDisallowOrderByRand,
+		Level:   advisor.SchemaRuleLevelWarning,
+		Payload: "",
+	}, advisor.MockPostgreSQLDatabase)
+}
@@ -0,0 +1,118 @@
+package pg
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/bytebase/bytebase/plugin/advisor"
+	"github.com/bytebase/bytebase/plugin/advisor/db"
+	"github.com/bytebase/bytebase/plugin/parser/ast"
+)
+
+var (
+	_ advisor.Advisor = (*StatementMergeAlterTableAdvisor)(nil)
+	_ ast.Visitor     = (*statementMergeAlterTableChecker)(nil)
+)
+
+func init() {
+	advisor.Register(db.Postgres, advisor.PostgreSQLMergeAlterTable, &StatementMergeAlterTableAdvisor{})
+}
+
+// StatementMergeAlterTableAdvisor is the advisor checking for no redundant ALTER TABLE statements.
+type StatementMergeAlterTableAdvisor struct {
+}
+
+// Check checks for no redundant ALTER TABLE statements.
+func (*StatementMergeAlterTableAdvisor) Check(ctx advisor.Context, statement string) ([]advisor.Advice, error) {
+	stmts, errAdvice := parseStatement(statement)
+	if errAdvice != nil {
+		return errAdvice, nil
+	}
+
+	level, err := advisor.NewStatusBySQLReviewRuleLevel(ctx.Rule.Level)
+	if err != nil {
+		return nil, err
+	}
+	checker := &statementMergeAlterTableChecker{
+		level:    level,
+		title:    string(ctx.Rule.Type),
+		tableMap: make(map[string]tableStatement),
+	}
+
+	for _, stmt := range stmts {
+		ast.Walk(checker, stmt)
+	}
+
+	return checker.generateAdvice(), nil
+}
+
+type statementMergeAlterTableChecker struct {
+	adviceList []advisor.Advice
+	level      advisor.Status
+	title      string
+	tableMap   map[string]tableStatement
+}
+
+type tableStatement struct {
+	name     string
+	count    int
+	lastLine int
+}
+
+// Visit implements the ast.Visitor interface.
+func (checker *statementMergeAlterTableChecker) Visit(node ast.Node) ast.Visitor {
+	switch n := node.(type) {
+	case *ast.CreateTableStmt:
+		name := normalizeTableName(n.Name)
+		checker.tableMap[name] = tableStatement{
+			name:     name,
+			count:    1,
+			lastLine: n.LastLine(),
+		}
+	case *ast.AlterTableStmt:
+		name := normalizeTableName(n.Table)
+		data, ok := checker.tableMap[name]
+		if !ok {
+			data = tableStatement{
+				name:  name,
+				count: 0,
+			}
+		}
+		data.count++
+		data.lastLine = n.LastLine()
+		checker.tableMap[name] = data
+	}
+	return checker
+}
+
+func (checker *statementMergeAlterTableChecker) generateAdvice() []advisor.Advice {
+	var tableList []tableStatement
+	for _, table := range checker.tableMap {
+		tableList = append(tableList, table)
+	}
+	sort.Slice(tableList, func(i, j int) bool {
+		return tableList[i].lastLine < tableList[j].lastLine
+	})
+
+	for _, table := range tableList {
+		if table.count > 1 {
+			checker.adviceList = append(checker.adviceList, advisor.Advice{
+				Status:  checker.level,
+				Code:    advisor.StatementRedundantAlterTable,
+				Title:   checker.title,
+				Content: fmt.Sprintf("There are %d statements to modify table %s", table.count, table.name),
+				Line:    table.lastLine,
+			})
+		}
+	}
+
+	if len(checker.adviceList) == 0 {
+		checker.adviceList = append(checker.adviceList, advisor.Advice{
+			Status:  advisor.Success,
+			Code:    advisor.Ok,
+			Title:   "OK",
+			Content: "",
+		})
+	}
+	return checker.adviceList
+}
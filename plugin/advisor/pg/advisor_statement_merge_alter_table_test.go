@@ -0,0 +1,48 @@
+package pg
+
+import (
+	"testing"
+
+	"github.com/bytebase/bytebase/plugin/advisor"
+)
+
+func TestStatementMergeAlterTable(t *testing.T) {
+	tests := []advisor.TestCase{
+		{
+			Statement: `
+				CREATE TABLE t(a int);
+				ALTER TABLE t ADD COLUMN b int;
+				ALTER TABLE public.t ADD COLUMN c int;
+				ALTER TABLE tech_book ADD COLUMN c int;
+				ALTER TABLE t1 ADD COLUMN c int;`,
+			Want: []advisor.Advice{
+				{
+					Status:  advisor.Warn,
+					Code:    advisor.StatementRedundantAlterTable,
+					Title:   "statement.merge-alter-table",
+					Content: `There are 3 statements to modify table "public"."t"`,
+					Line:    4,
+				},
+			},
+		},
+		{
+			Statement: `
+				CREATE TABLE t(a int);
+				ALTER TABLE t1 ADD COLUMN c int;`,
+			Want: []advisor.Advice{
+				{
+					Status:  advisor.Success,
+					Code:    advisor.Ok,
+					Title:   "OK",
+					Content: "",
+				},
+			},
+		},
+	}
+
+	advisor.RunSQLReviewRuleTests(t, tests, &StatementMergeAlterTableAdvisor{}, &advisor.SQLReviewRule{
+		Type:    advisor.SchemaRuleStatementMergeAlterTable,
+		Level:   advisor.SchemaRuleLevelWarning,
+		Payload: "",
+	}, advisor.MockPostgreSQLDatabase)
+}
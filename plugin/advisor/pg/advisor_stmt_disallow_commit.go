@@ -0,0 +1,73 @@
+package pg
+
+import (
+	"fmt"
+
+	"github.com/bytebase/bytebase/plugin/advisor"
+	"github.com/bytebase/bytebase/plugin/advisor/db"
+	"github.com/bytebase/bytebase/plugin/parser/ast"
+)
+
+var (
+	_ advisor.Advisor = (*StatementDisallowCommitAdvisor)(nil)
+	_ ast.Visitor     = (*statementDisallowCommitChecker)(nil)
+)
+
+func init() {
+	advisor.Register(db.Postgres, advisor.PostgreSQLStatementDisallowCommit, &StatementDisallowCommitAdvisor{})
+}
+
+// StatementDisallowCommitAdvisor is the advisor checking for disallowing COMMIT statements.
+type StatementDisallowCommitAdvisor struct {
+}
+
+// Check checks for disallowing COMMIT statements.
+func (*StatementDisallowCommitAdvisor) Check(ctx advisor.Context, statement string) ([]advisor.Advice, error) {
+	stmts, errAdvice := parseStatement(statement)
+	if errAdvice != nil {
+		return errAdvice, nil
+	}
+
+	level, err := advisor.NewStatusBySQLReviewRuleLevel(ctx.Rule.Level)
+	if err != nil {
+		return nil, err
+	}
+	checker := &statementDisallowCommitChecker{
+		level: level,
+		title: string(ctx.Rule.Type),
+	}
+
+	for _, stmt := range stmts {
+		ast.Walk(checker, stmt)
+	}
+
+	if len(checker.adviceList) == 0 {
+		checker.adviceList = append(checker.adviceList, advisor.Advice{
+			Status:  advisor.Success,
+			Code:    advisor.Ok,
+			Title:   "OK",
+			Content: "",
+		})
+	}
+	return checker.adviceList, nil
+}
+
+type statementDisallowCommitChecker struct {
+	adviceList []advisor.Advice
+	level      advisor.Status
+	title      string
+}
+
+// Visit implements the ast.Visitor interface.
+func (checker *statementDisallowCommitChecker) Visit(node ast.Node) ast.Visitor {
+	if n, ok := node.(*ast.CommitStmt); ok {
+		checker.adviceList = append(checker.adviceList, advisor.Advice{
+			Status:  checker.level,
+			Code:    advisor.StatementDisallowCommit,
+			Title:   checker.title,
+			Content: fmt.Sprintf("Commit is not allowed, related statement: \"%s\"", n.Text()),
+			Line:    n.LastLine(),
+		})
+	}
+	return checker
+}
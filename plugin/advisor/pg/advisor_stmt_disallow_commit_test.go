@@ -0,0 +1,44 @@
+package pg
+
+import (
+	"testing"
+
+	"github.com/bytebase/bytebase/plugin/advisor"
+)
+
+func TestStatementDisallowCommit(t *testing.T) {
+	tests := []advisor.TestCase{
+		{
+			Statement: "CREATE TABLE t(a int)",
+			Want: []advisor.Advice{
+				{
+					Status:  advisor.Success,
+					Code:    advisor.Ok,
+					Title:   "OK",
+					Content: "",
+				},
+			},
+		},
+		{
+			Statement: `
+				BEGIN;
+				CREATE TABLE t(a int);
+				COMMIT;`,
+			Want: []advisor.Advice{
+				{
+					Status:  advisor.Warn,
+					Code:    advisor.StatementDisallowCommit,
+					Title:   "statement.disallow-commit",
+					Content: "Commit is not allowed, related statement: \"COMMIT;\"",
+					Line:    4,
+				},
+			},
+		},
+	}
+
+	advisor.RunSQLReviewRuleTests(t, tests, &StatementDisallowCommitAdvisor{}, &advisor.SQLReviewRule{
+		Type:    advisor.SchemaRuleStatementDisallowCommit,
+		Level:   advisor.SchemaRuleLevelWarning,
+		Payload: "",
+	}, advisor.MockPostgreSQLDatabase)
+}
@@ -0,0 +1,126 @@
+package pg
+
+import (
+	"fmt"
+
+	"github.com/bytebase/bytebase/plugin/advisor"
+	"github.com/bytebase/bytebase/plugin/advisor/db"
+	"github.com/bytebase/bytebase/plugin/parser/ast"
+)
+
+var (
+	_ advisor.Advisor = (*TableCommentConventionAdvisor)(nil)
+	_ ast.Visitor     = (*tableCommentConventionChecker)(nil)
+)
+
+func init() {
+	advisor.Register(db.Postgres, advisor.PostgreSQLTableCommentConvention, &TableCommentConventionAdvisor{})
+}
+
+// TableCommentConventionAdvisor is the advisor checking for table comment convention.
+type TableCommentConventionAdvisor struct {
+}
+
+// Check checks for table comment convention.
+func (*TableCommentConventionAdvisor) Check(ctx advisor.Context, statement string) ([]advisor.Advice, error) {
+	stmts, errAdvice := parseStatement(statement)
+	if errAdvice != nil {
+		return errAdvice, nil
+	}
+
+	level, err := advisor.NewStatusBySQLReviewRuleLevel(ctx.Rule.Level)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := advisor.UnmarshalCommentConventionRulePayload(ctx.Rule.Payload)
+	if err != nil {
+		return nil, err
+	}
+	checker := &tableCommentConventionChecker{
+		level:          level,
+		title:          string(ctx.Rule.Type),
+		required:       payload.Required,
+		maxLength:      payload.MaxLength,
+		commentedTable: make(map[columnName]bool),
+	}
+
+	for _, stmt := range stmts {
+		ast.Walk(checker, stmt)
+	}
+
+	return checker.generateAdvice(), nil
+}
+
+type tableCommentConventionChecker struct {
+	adviceList []advisor.Advice
+	level      advisor.Status
+	title      string
+	required   bool
+	maxLength  int
+	// createdTableList is the table list created in the statements, using the columnName with empty column as the table name.
+	createdTableList []tableCreation
+	commentedTable   map[columnName]bool
+}
+
+type tableCreation struct {
+	name columnName
+	line int
+}
+
+// Visit implements the ast.Visitor interface.
+func (checker *tableCommentConventionChecker) Visit(node ast.Node) ast.Visitor {
+	switch n := node.(type) {
+	// CREATE TABLE
+	case *ast.CreateTableStmt:
+		checker.createdTableList = append(checker.createdTableList, tableCreation{
+			name: convertToColumnName(n.Name, ""),
+			line: n.LastLine(),
+		})
+	// COMMENT ON TABLE
+	case *ast.CommentStmt:
+		table, ok := n.Object.(*ast.TableDef)
+		if n.Type != ast.ObjectTypeTable || !ok {
+			break
+		}
+		name := convertToColumnName(table, "")
+		checker.commentedTable[name] = n.Comment != ""
+		if checker.maxLength >= 0 && len(n.Comment) > checker.maxLength {
+			checker.adviceList = append(checker.adviceList, advisor.Advice{
+				Status:  checker.level,
+				Code:    advisor.TableCommentTooLong,
+				Title:   checker.title,
+				Content: fmt.Sprintf("The length of table %s comment should be within %d characters", name.normalizeTableName(), checker.maxLength),
+				Line:    n.LastLine(),
+			})
+		}
+	}
+	return checker
+}
+
+// generateAdvice generates the advice list.
+// PostgreSQL has no inline table comment, so the table created in the statements needs the COMMENT ON TABLE statement in the same SQL.
+func (checker *tableCommentConventionChecker) generateAdvice() []advisor.Advice {
+	if checker.required {
+		for _, table := range checker.createdTableList {
+			if !checker.commentedTable[table.name] {
+				checker.adviceList = append(checker.adviceList, advisor.Advice{
+					Status:  checker.level,
+					Code:    advisor.NoTableComment,
+					Title:   checker.title,
+					Content: fmt.Sprintf("Table %s requires comments", table.name.normalizeTableName()),
+					Line:    table.line,
+				})
+			}
+		}
+	}
+
+	if len(checker.adviceList) == 0 {
+		checker.adviceList = append(checker.adviceList, advisor.Advice{
+			Status:  advisor.Success,
+			Code:    advisor.Ok,
+			Title:   "OK",
+			Content: "",
+		})
+	}
+	return checker.adviceList
+}
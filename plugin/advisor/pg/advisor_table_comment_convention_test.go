@@ -0,0 +1,91 @@
+package pg
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bytebase/bytebase/plugin/advisor"
+)
+
+func TestTableCommentConvention(t *testing.T) {
+	tests := []advisor.TestCase{
+		{
+			Statement: `
+				CREATE TABLE t(a int);
+				COMMENT ON TABLE t IS 'some comments';`,
+			Want: []advisor.Advice{
+				{
+					Status:  advisor.Success,
+					Code:    advisor.Ok,
+					Title:   "OK",
+					Content: "",
+				},
+			},
+		},
+		{
+			Statement: "CREATE TABLE t(a int)",
+			Want: []advisor.Advice{
+				{
+					Status:  advisor.Warn,
+					Code:    advisor.NoTableComment,
+					Title:   "table.comment",
+					Content: `Table "public"."t" requires comments`,
+					Line:    1,
+				},
+			},
+		},
+		{
+			Statement: `
+				CREATE TABLE t(a int);
+				COMMENT ON TABLE public.t IS NULL;`,
+			Want: []advisor.Advice{
+				{
+					Status:  advisor.Warn,
+					Code:    advisor.NoTableComment,
+					Title:   "table.comment",
+					Content: `Table "public"."t" requires comments`,
+					Line:    2,
+				},
+			},
+		},
+		{
+			Statement: `
+				CREATE TABLE t(a int);
+				COMMENT ON TABLE t IS 'some very very very very very very very very long comments';`,
+			Want: []advisor.Advice{
+				{
+					Status:  advisor.Warn,
+					Code:    advisor.TableCommentTooLong,
+					Title:   "table.comment",
+					Content: `The length of table "public"."t" comment should be within 20 characters`,
+					Line:    3,
+				},
+			},
+		},
+		{
+			Statement: "COMMENT ON TABLE tech_book IS 'some very very very very very very very very long comments'",
+			Want: []advisor.Advice{
+				{
+					Status:  advisor.Warn,
+					Code:    advisor.TableCommentTooLong,
+					Title:   "table.comment",
+					Content: `The length of table "public"."tech_book" comment should be within 20 characters`,
+					Line:    1,
+				},
+			},
+		},
+	}
+
+	payload, err := json.Marshal(advisor.CommentConventionRulePayload{
+		Required:  true,
+		MaxLength: 20,
+	})
+	require.NoError(t, err)
+	advisor.RunSQLReviewRuleTests(t, tests, &TableCommentConventionAdvisor{}, &advisor.SQLReviewRule{
+		Type:    advisor.SchemaRuleTableCommentConvention,
+		Level:   advisor.SchemaRuleLevelWarning,
+		Payload: string(payload),
+	}, advisor.MockPostgreSQLDatabase)
+}
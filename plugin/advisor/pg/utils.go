@@ -3,6 +3,8 @@ package pg
 
 import (
 	"fmt"
+
+	"github.com/bytebase/bytebase/plugin/parser/ast"
 )
 
 const (
@@ -32,3 +34,7 @@ func normalizeSchemaName(name string) string {
 	}
 	return "public"
 }
+
+func normalizeTableName(table *ast.TableDef) string {
+	return fmt.Sprintf(`"%s"."%s"`, normalizeSchemaName(table.Schema), table.Name)
+}
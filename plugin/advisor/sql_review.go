@@ -401,7 +401,7 @@ func SQLReviewCheck(statements string, ruleList []*SQLReviewRule, checkContext S
 
 	finder := checkContext.Catalog.GetFinder()
 	switch checkContext.DbType {
-	case db.TiDB, db.MySQL:
+	case db.TiDB, db.MySQL, db.Postgres:
 		if err := finder.WalkThrough(statements); err != nil {
 			return convertWalkThroughErrorToAdvice(err)
 		}
@@ -498,6 +498,14 @@ func convertWalkThroughErrorToAdvice(err error) ([]Advice, error) {
 			Content: walkThroughError.Content,
 			Line:    walkThroughError.Line,
 		})
+	case catalog.ErrorTypeSchemaExists:
+		res = append(res, Advice{
+			Status:  Error,
+			Code:    SchemaExists,
+			Title:   "Schema already exists",
+			Content: walkThroughError.Content,
+			Line:    walkThroughError.Line,
+		})
 	case catalog.ErrorTypeTableExists:
 		res = append(res, Advice{
 			Status:  Error,
@@ -514,6 +522,14 @@ func convertWalkThroughErrorToAdvice(err error) ([]Advice, error) {
 			Content: walkThroughError.Content,
 			Line:    walkThroughError.Line,
 		})
+	case catalog.ErrorTypeViewNotExists:
+		res = append(res, Advice{
+			Status:  Error,
+			Code:    TableNotExists,
+			Title:   "View does not exist",
+			Content: walkThroughError.Content,
+			Line:    walkThroughError.Line,
+		})
 	case catalog.ErrorTypeColumnExists:
 		res = append(res, Advice{
 			Status:  Error,
@@ -686,11 +702,15 @@ func getAdvisorTypeByRule(ruleType SQLReviewRuleType, engine db.Type) (Type, err
 		switch engine {
 		case db.MySQL, db.TiDB:
 			return MySQLColumnDisallowChangingType, nil
+		case db.Postgres:
+			return PostgreSQLColumnDisallowChangingType, nil
 		}
 	case SchemaRuleColumnSetDefaultForNotNull:
 		switch engine {
 		case db.MySQL, db.TiDB:
 			return MySQLColumnSetDefaultForNotNull, nil
+		case db.Postgres:
+			return PostgreSQLColumnSetDefaultForNotNull, nil
 		}
 	case SchemaRuleColumnDisallowChange:
 		switch engine {
@@ -706,6 +726,8 @@ func getAdvisorTypeByRule(ruleType SQLReviewRuleType, engine db.Type) (Type, err
 		switch engine {
 		case db.MySQL, db.TiDB:
 			return MySQLColumnCommentConvention, nil
+		case db.Postgres:
+			return PostgreSQLColumnCommentConvention, nil
 		}
 	case SchemaRuleColumnAutoIncrementMustInteger:
 		switch engine {
@@ -716,6 +738,8 @@ func getAdvisorTypeByRule(ruleType SQLReviewRuleType, engine db.Type) (Type, err
 		switch engine {
 		case db.MySQL, db.TiDB:
 			return MySQLColumnTypeRestriction, nil
+		case db.Postgres:
+			return PostgreSQLColumnTypeRestriction, nil
 		}
 	case SchemaRuleColumnDisallowSetCharset:
 		switch engine {
@@ -760,6 +784,8 @@ func getAdvisorTypeByRule(ruleType SQLReviewRuleType, engine db.Type) (Type, err
 		switch engine {
 		case db.MySQL, db.TiDB:
 			return MySQLTableCommentConvention, nil
+		case db.Postgres:
+			return PostgreSQLTableCommentConvention, nil
 		}
 	case SchemaRuleTableDisallowPartition:
 		switch engine {
@@ -774,6 +800,8 @@ func getAdvisorTypeByRule(ruleType SQLReviewRuleType, engine db.Type) (Type, err
 		switch engine {
 		case db.MySQL, db.TiDB:
 			return MySQLDatabaseAllowDropIfEmpty, nil
+		case db.Postgres:
+			return PostgreSQLDatabaseAllowDropIfEmpty, nil
 		}
 	case SchemaRuleIndexNoDuplicateColumn:
 		switch engine {
@@ -782,13 +810,17 @@ func getAdvisorTypeByRule(ruleType SQLReviewRuleType, engine db.Type) (Type, err
 		}
 	case SchemaRuleIndexKeyNumberLimit:
 		switch engine {
-		case db.MySQL, db.TiDB, db.Postgres:
+		case db.MySQL, db.TiDB:
 			return MySQLIndexKeyNumberLimit, nil
+		case db.Postgres:
+			return PostgreSQLIndexKeyNumberLimit, nil
 		}
 	case SchemaRuleIndexTotalNumberLimit:
 		switch engine {
 		case db.MySQL, db.TiDB:
 			return MySQLIndexTotalNumberLimit, nil
+		case db.Postgres:
+			return PostgreSQLIndexTotalNumberLimit, nil
 		}
 	case SchemaRuleStatementNoCreateTableAs:
 		switch engine {
@@ -799,6 +831,8 @@ func getAdvisorTypeByRule(ruleType SQLReviewRuleType, engine db.Type) (Type, err
 		switch engine {
 		case db.MySQL, db.TiDB:
 			return MySQLStatementDisallowCommit, nil
+		case db.Postgres:
+			return PostgreSQLStatementDisallowCommit, nil
 		}
 	case SchemaRuleCharsetAllowlist:
 		switch engine {
@@ -824,6 +858,8 @@ func getAdvisorTypeByRule(ruleType SQLReviewRuleType, engine db.Type) (Type, err
 		switch engine {
 		case db.MySQL, db.TiDB:
 			return MySQLInsertRowLimit, nil
+		case db.Postgres:
+			return PostgreSQLInsertRowLimit, nil
 		}
 	case SchemaRuleInsertMustSpecifyColumn:
 		switch engine {
@@ -834,6 +870,8 @@ func getAdvisorTypeByRule(ruleType SQLReviewRuleType, engine db.Type) (Type, err
 		switch engine {
 		case db.MySQL, db.TiDB:
 			return MySQLInsertDisallowOrderByRand, nil
+		case db.Postgres:
+			return PostgreSQLInsertDisallowOrderByRand, nil
 		}
 	case SchemaRuleStatementDisallowLimit:
 		switch engine {
@@ -849,6 +887,8 @@ func getAdvisorTypeByRule(ruleType SQLReviewRuleType, engine db.Type) (Type, err
 		switch engine {
 		case db.MySQL, db.TiDB:
 			return MySQLMergeAlterTable, nil
+		case db.Postgres:
+			return PostgreSQLMergeAlterTable, nil
 		}
 	}
 	return Fake, errors.Errorf("unknown SQL review rule type %v for %v", ruleType, engine)
@@ -10,6 +10,7 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -23,8 +24,14 @@ type Type string
 const (
 	// ClickHouse is the database type for CLICKHOUSE.
 	ClickHouse Type = "CLICKHOUSE"
+	// MongoDB is the database type for MONGODB.
+	MongoDB Type = "MONGODB"
 	// MySQL is the database type for MYSQL.
 	MySQL Type = "MYSQL"
+	// MSSQL is the database type for Microsoft SQL Server.
+	MSSQL Type = "MSSQL"
+	// Oracle is the database type for ORACLE.
+	Oracle Type = "ORACLE"
 	// Postgres is the database type for POSTGRES.
 	Postgres Type = "POSTGRES"
 	// Snowflake is the database type for SNOWFLAKE.
@@ -489,6 +496,55 @@ type Driver interface {
 	Restore(ctx context.Context, src io.Reader) error
 }
 
+// RollbackResult is the result of generating the rollback statement for a data migration.
+type RollbackResult struct {
+	// Statement is the statement to revert the row changes made by the migration.
+	Statement string
+	// AffectedRowCount is the number of rows changed by the migration.
+	AffectedRowCount int64
+	// Error is the reason why the rollback statement cannot be generated.
+	// The migration itself is not affected by this error.
+	Error string
+}
+
+// RollbackExecutor is implemented by the drivers which can snapshot the affected rows while executing a data migration.
+type RollbackExecutor interface {
+	// ExecuteMigrationWithRollback is the same as ExecuteMigration, and it also generates the statement to revert the row changes.
+	// The returned RollbackResult is nil if the statement isn't executed, e.g. the migration has already been applied.
+	ExecuteMigrationWithRollback(ctx context.Context, m *MigrationInfo, statement string) (int64, string, *RollbackResult, error)
+}
+
+// DryRunOption is the option to dry run a migration.
+type DryRunOption struct {
+	// StatementTimeout aborts a single statement running longer than it. Zero means no timeout.
+	StatementTimeout time.Duration
+	// LockTimeout aborts a single statement waiting for a lock longer than it. Zero means no timeout.
+	LockTimeout time.Duration
+	// Timeout aborts the whole dry run running longer than it. Zero means no timeout.
+	Timeout time.Duration
+}
+
+// DryRunResult is the result of dry running a migration.
+type DryRunResult struct {
+	// Statement is the first failing statement, empty if all statements succeed.
+	Statement string
+	// Line is the last line of the first failing statement in the migration.
+	Line int
+	// Error is the database error of the first failing statement.
+	Error string
+	// Duration is the elapsed time of running the statements.
+	Duration time.Duration
+	// SkippedStatementList is the statements which cannot be dry run, e.g. the non-transactional statements in Postgres.
+	SkippedStatementList []string
+}
+
+// DryRunExecutor is implemented by the drivers which can run a migration without persisting its changes.
+type DryRunExecutor interface {
+	// DryRun runs the statement against the database and always discards the changes.
+	// The statement failure is reported in the DryRunResult, while the returned error means the dry run itself fails.
+	DryRun(ctx context.Context, statement string, option DryRunOption) (*DryRunResult, error)
+}
+
 // Register makes a database driver available by the provided type.
 // If Register is called twice with the same name or if driver is nil,
 // it panics.
@@ -548,3 +604,29 @@ func FormatParamNameInNumberedPosition(paramNames []string) string {
 	}
 	return fmt.Sprintf("WHERE %s ", strings.Join(parts, " AND "))
 }
+
+// FormatParamNameInAtSignPosition formats the param name in at sign positions.
+// For example, it will be WHERE hello = @p1 AND world = @p2.
+func FormatParamNameInAtSignPosition(paramNames []string) string {
+	if len(paramNames) == 0 {
+		return ""
+	}
+	var parts []string
+	for i, param := range paramNames {
+		parts = append(parts, fmt.Sprintf("%s = @p%d", param, i+1))
+	}
+	return fmt.Sprintf("WHERE %s ", strings.Join(parts, " AND "))
+}
+
+// FormatParamNameInColonPosition formats the param name in colon positions.
+// For example, it will be WHERE hello = :1 AND world = :2.
+func FormatParamNameInColonPosition(paramNames []string) string {
+	if len(paramNames) == 0 {
+		return ""
+	}
+	var parts []string
+	for i, param := range paramNames {
+		parts = append(parts, fmt.Sprintf("%s = :%d", param, i+1))
+	}
+	return fmt.Sprintf("WHERE %s ", strings.Join(parts, " AND "))
+}
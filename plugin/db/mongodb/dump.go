@@ -0,0 +1,209 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/bytebase/bytebase/plugin/db"
+)
+
+// Dump and restore.
+const (
+	databaseHeaderFmt = "" +
+		"//\n" +
+		"// MongoDB database structure for %s\n" +
+		"//\n"
+	collectionHeaderFmt = "" +
+		"//\n" +
+		"// Collection structure for %s\n" +
+		"//\n"
+	collectionDataHeaderFmt = "" +
+		"//\n" +
+		"// Data for collection %s\n" +
+		"//\n"
+	viewHeaderFmt = "" +
+		"//\n" +
+		"// View structure for %s\n" +
+		"//\n"
+
+	// dumpBatchSize is the number of documents in each insertMany() statement.
+	dumpBatchSize = 100
+)
+
+// Dump dumps the database as mongosh-style statements, which can be restored by Execute.
+func (driver *Driver) Dump(ctx context.Context, database string, out io.Writer, schemaOnly bool) (string, error) {
+	// Find all dumpable databases
+	var dumpableDbNames []string
+	if database != "" {
+		dumpableDbNames = []string{database}
+	} else {
+		databases, err := driver.getDatabases(ctx)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to get databases")
+		}
+		for _, database := range databases {
+			if database == db.BytebaseDatabase {
+				continue
+			}
+			dumpableDbNames = append(dumpableDbNames, database)
+		}
+	}
+
+	for _, dbName := range dumpableDbNames {
+		// Database header and "use" statement are only included if dumping all databases.
+		if database == "" {
+			header := fmt.Sprintf(databaseHeaderFmt, dbName)
+			if _, err := io.WriteString(out, fmt.Sprintf("%suse %s;\n\n", header, dbName)); err != nil {
+				return "", err
+			}
+		}
+		if err := dumpOneDatabase(ctx, driver.client.Database(dbName), out, schemaOnly); err != nil {
+			return "", err
+		}
+	}
+
+	return "", nil
+}
+
+func dumpOneDatabase(ctx context.Context, mdb *mongo.Database, out io.Writer, schemaOnly bool) error {
+	collections, err := listCollections(ctx, mdb)
+	if err != nil {
+		return err
+	}
+
+	// Views are dumped after collections because views depend on collections.
+	for _, collection := range collections {
+		if collection.Type != "collection" {
+			continue
+		}
+		if err := dumpCollection(ctx, mdb, collection, out); err != nil {
+			return err
+		}
+	}
+	for _, collection := range collections {
+		if collection.Type != "view" {
+			continue
+		}
+		header := fmt.Sprintf(viewHeaderFmt, collection.Name)
+		if _, err := io.WriteString(out, fmt.Sprintf("%s%s\n\n", header, getViewDefinition(collection))); err != nil {
+			return err
+		}
+	}
+
+	if schemaOnly {
+		return nil
+	}
+	for _, collection := range collections {
+		if collection.Type != "collection" {
+			continue
+		}
+		if err := dumpCollectionData(ctx, mdb.Collection(collection.Name), out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dumpCollection dumps the db.createCollection() statement with the options such as the validator, and the createIndex() statements.
+func dumpCollection(ctx context.Context, mdb *mongo.Database, collection collectionInfo, out io.Writer) error {
+	var buf strings.Builder
+	buf.WriteString(fmt.Sprintf(collectionHeaderFmt, collection.Name))
+	if len(collection.Options) == 0 {
+		buf.WriteString(fmt.Sprintf("db.createCollection(%s);\n", quote(collection.Name)))
+	} else {
+		buf.WriteString(fmt.Sprintf("db.createCollection(%s, %s);\n", quote(collection.Name), marshalExtJSON(collection.Options, false /* canonical */)))
+	}
+
+	indexes, err := listIndexes(ctx, mdb.Collection(collection.Name))
+	if err != nil {
+		return err
+	}
+	for _, index := range indexes {
+		keys, opts := getIndexKeysAndOptions(index)
+		// The _id index is created along with the collection.
+		if name, _ := index.Map()["name"].(string); name == "_id_" {
+			continue
+		}
+		buf.WriteString(fmt.Sprintf("db.getCollection(%s).createIndex(%s, %s);\n", quote(collection.Name), marshalExtJSON(keys, false /* canonical */), marshalExtJSON(opts, false /* canonical */)))
+	}
+	buf.WriteString("\n")
+
+	_, err = io.WriteString(out, buf.String())
+	return err
+}
+
+// getIndexKeysAndOptions splits the index specification into the keys and the options for createIndex().
+func getIndexKeysAndOptions(spec bson.D) (bson.D, bson.D) {
+	var keys, opts bson.D
+	for _, e := range spec {
+		switch e.Key {
+		case "key":
+			keys, _ = e.Value.(bson.D)
+		case "v", "ns":
+			// The index version and namespace are determined by the server.
+		default:
+			opts = append(opts, e)
+		}
+	}
+	return keys, opts
+}
+
+// dumpCollectionData dumps the documents in batches of insertMany() statements.
+// The documents are in canonical Extended JSON to keep the BSON types, e.g. int64 and double.
+func dumpCollectionData(ctx context.Context, coll *mongo.Collection, out io.Writer) error {
+	cursor, err := coll.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to find documents of collection %q", coll.Name())
+	}
+	defer cursor.Close(ctx)
+
+	if _, err := io.WriteString(out, fmt.Sprintf(collectionDataHeaderFmt, coll.Name())); err != nil {
+		return err
+	}
+	var batch []string
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		stmt := fmt.Sprintf("db.getCollection(%s).insertMany([\n%s\n]);\n", quote(coll.Name()), strings.Join(batch, ",\n"))
+		batch = nil
+		_, err := io.WriteString(out, stmt)
+		return err
+	}
+	for cursor.Next(ctx) {
+		b, err := bson.MarshalExtJSON(cursor.Current, true /* canonical */, false /* escapeHTML */)
+		if err != nil {
+			return err
+		}
+		batch = append(batch, string(b))
+		if len(batch) >= dumpBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	_, err = io.WriteString(out, "\n")
+	return err
+}
+
+// Restore restores a database.
+func (driver *Driver) Restore(ctx context.Context, sc io.Reader) error {
+	b, err := io.ReadAll(sc)
+	if err != nil {
+		return err
+	}
+	return driver.Execute(ctx, string(b))
+}
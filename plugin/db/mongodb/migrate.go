@@ -0,0 +1,308 @@
+package mongodb
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	// embed will embeds the migration schema.
+	_ "embed"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/common/log"
+	"github.com/bytebase/bytebase/plugin/db"
+	"github.com/bytebase/bytebase/plugin/db/util"
+)
+
+const migrationHistoryCollection = "migration_history"
+
+//go:embed mongodb_migration_schema.js
+var migrationSchema string
+
+// migrationHistory is the document of the migration history.
+type migrationHistory struct {
+	ID                  int64  `bson:"_id"`
+	CreatedBy           string `bson:"created_by"`
+	CreatedTs           int64  `bson:"created_ts"`
+	UpdatedBy           string `bson:"updated_by"`
+	UpdatedTs           int64  `bson:"updated_ts"`
+	ReleaseVersion      string `bson:"release_version"`
+	Namespace           string `bson:"namespace"`
+	Sequence            int    `bson:"sequence"`
+	Source              string `bson:"source"`
+	Type                string `bson:"type"`
+	Status              string `bson:"status"`
+	Version             string `bson:"version"`
+	Description         string `bson:"description"`
+	Statement           string `bson:"statement"`
+	Schema              string `bson:"schema"`
+	SchemaPrev          string `bson:"schema_prev"`
+	ExecutionDurationNs int64  `bson:"execution_duration_ns"`
+	IssueID             string `bson:"issue_id"`
+	Payload             string `bson:"payload"`
+}
+
+func (driver *Driver) migrationHistory() *mongo.Collection {
+	return driver.client.Database(db.BytebaseDatabase).Collection(migrationHistoryCollection)
+}
+
+// NeedsSetupMigration returns whether it needs to setup migration.
+func (driver *Driver) NeedsSetupMigration(ctx context.Context) (bool, error) {
+	names, err := driver.client.Database(db.BytebaseDatabase).ListCollectionNames(ctx, bson.D{{Key: "name", Value: migrationHistoryCollection}})
+	if err != nil {
+		return false, errors.Wrap(err, "failed to list collections of the bytebase database")
+	}
+	return len(names) == 0, nil
+}
+
+// SetupMigrationIfNeeded sets up migration if needed.
+func (driver *Driver) SetupMigrationIfNeeded(ctx context.Context) error {
+	setup, err := driver.NeedsSetupMigration(ctx)
+	if err != nil {
+		return err
+	}
+
+	if setup {
+		log.Info("Bytebase migration schema not found, creating schema...",
+			zap.String("environment", driver.connectionCtx.EnvironmentName),
+			zap.String("database", driver.connectionCtx.InstanceName),
+		)
+		if err := driver.Execute(ctx, migrationSchema); err != nil {
+			log.Error("Failed to initialize migration schema.",
+				zap.Error(err),
+				zap.String("environment", driver.connectionCtx.EnvironmentName),
+				zap.String("database", driver.connectionCtx.InstanceName),
+			)
+			return errors.Wrap(err, "failed to initialize migration schema")
+		}
+		log.Info("Successfully created migration schema.",
+			zap.String("environment", driver.connectionCtx.EnvironmentName),
+			zap.String("database", driver.connectionCtx.InstanceName),
+		)
+	}
+
+	return nil
+}
+
+// ExecuteMigration will execute the migration.
+// It follows the same phases as util.ExecuteMigration, but records the migration history in the bytebase.migration_history collection.
+func (driver *Driver) ExecuteMigration(ctx context.Context, m *db.MigrationInfo, statement string) (migrationHistoryID int64, updatedSchema string, resErr error) {
+	var prevSchemaBuf bytes.Buffer
+	// Don't record schema if the database hasn't exist yet.
+	if !m.CreateDatabase {
+		if _, err := driver.Dump(ctx, m.Database, &prevSchemaBuf, true /*schemaOnly*/); err != nil {
+			return -1, "", err
+		}
+	}
+
+	// Phase 1 - Pre-check before executing migration
+	// Phase 2 - Record migration history as PENDING
+	insertedID, err := driver.beginMigration(ctx, m, prevSchemaBuf.String(), statement)
+	if err != nil {
+		if common.ErrorCode(err) == common.MigrationAlreadyApplied {
+			return insertedID, prevSchemaBuf.String(), nil
+		}
+		return -1, "", errors.Wrapf(err, "failed to begin migration for issue %s", m.IssueID)
+	}
+
+	startedNs := time.Now().UnixNano()
+
+	defer func() {
+		if err := driver.endMigration(ctx, startedNs, insertedID, updatedSchema, resErr == nil /*isDone*/); err != nil {
+			log.Error("Failed to update migration history record",
+				zap.Error(err),
+				zap.Int64("migration_id", migrationHistoryID),
+			)
+		}
+	}()
+
+	// Phase 3 - Executing migration
+	// Branch migration type always has empty statement.
+	// Baseline migration type could has non-empty statement but will not execute.
+	if statement != "" && m.Type != db.Baseline {
+		driver.databaseName = m.Database
+		if err := driver.Execute(ctx, statement); err != nil {
+			return -1, "", err
+		}
+	}
+
+	// Phase 4 - Dump the schema after migration
+	var afterSchemaBuf bytes.Buffer
+	if _, err := driver.Dump(ctx, m.Database, &afterSchemaBuf, true /*schemaOnly*/); err != nil {
+		return -1, "", err
+	}
+
+	return insertedID, afterSchemaBuf.String(), nil
+}
+
+// beginMigration checks before executing migration and inserts a migration history record with pending status.
+func (driver *Driver) beginMigration(ctx context.Context, m *db.MigrationInfo, prevSchema string, statement string) (int64, error) {
+	// Convert version to stored version.
+	storedVersion, err := util.ToStoredVersion(m.UseSemanticVersion, m.Version, m.SemanticVersionSuffix)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to convert to stored version")
+	}
+	if migrationHistoryID, reuse, err := util.CheckDuplicateVersion(ctx, driver, m); err != nil || reuse {
+		return migrationHistoryID, err
+	}
+
+	largestSequence, err := driver.findLargestSequence(ctx, m.Namespace, false /* baseline */)
+	if err != nil {
+		return -1, err
+	}
+
+	// Check if there is any higher version already been applied since the last baseline or branch.
+	largestBaselineSequence, err := driver.findLargestSequence(ctx, m.Namespace, true /* baseline */)
+	if err != nil {
+		return -1, err
+	}
+	var largestVersion migrationHistory
+	if err := driver.migrationHistory().FindOne(ctx,
+		bson.D{{Key: "namespace", Value: m.Namespace}, {Key: "sequence", Value: bson.D{{Key: "$gte", Value: largestBaselineSequence}}}},
+		options.FindOne().SetSort(bson.D{{Key: "version", Value: -1}}),
+	).Decode(&largestVersion); err != nil && err != mongo.ErrNoDocuments {
+		return -1, err
+	} else if err == nil && largestVersion.Version >= m.Version {
+		return -1, common.Errorf(common.MigrationOutOfOrder, "database %q has already applied version %s which >= %s", m.Database, largestVersion.Version, m.Version)
+	}
+
+	// Phase 2 - Record migration history as PENDING.
+	// MongoDB doesn't support running DDL in transactions, so we first write a PENDING migration record,
+	// and after migration completes, we then update the record to DONE together with the updated schema.
+	var last migrationHistory
+	var insertedID int64 = 1
+	if err := driver.migrationHistory().FindOne(ctx, bson.D{}, options.FindOne().SetSort(bson.D{{Key: "_id", Value: -1}})).Decode(&last); err != nil && err != mongo.ErrNoDocuments {
+		return -1, err
+	} else if err == nil {
+		insertedID = last.ID + 1
+	}
+	now := time.Now().Unix()
+	if _, err := driver.migrationHistory().InsertOne(ctx, migrationHistory{
+		ID:             insertedID,
+		CreatedBy:      m.Creator,
+		CreatedTs:      now,
+		UpdatedBy:      m.Creator,
+		UpdatedTs:      now,
+		ReleaseVersion: m.ReleaseVersion,
+		Namespace:      m.Namespace,
+		Sequence:       largestSequence + 1,
+		Source:         string(m.Source),
+		Type:           string(m.Type),
+		Status:         string(db.Pending),
+		Version:        storedVersion,
+		Description:    m.Description,
+		Statement:      statement,
+		Schema:         prevSchema,
+		SchemaPrev:     prevSchema,
+		IssueID:        m.IssueID,
+		Payload:        m.Payload,
+	}); err != nil {
+		return -1, util.FormatError(err)
+	}
+	return insertedID, nil
+}
+
+// findLargestSequence will return the largest sequence number, or 0 if we haven't applied any migration for this namespace.
+func (driver *Driver) findLargestSequence(ctx context.Context, namespace string, baseline bool) (int, error) {
+	filter := bson.D{{Key: "namespace", Value: namespace}}
+	if baseline {
+		filter = append(filter, bson.E{Key: "type", Value: bson.D{{Key: "$in", Value: bson.A{db.Baseline, db.Branch}}}})
+	}
+	var history migrationHistory
+	if err := driver.migrationHistory().FindOne(ctx, filter, options.FindOne().SetSort(bson.D{{Key: "sequence", Value: -1}})).Decode(&history); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return 0, nil
+		}
+		return -1, err
+	}
+	return history.Sequence, nil
+}
+
+// endMigration updates the migration history record to DONE or FAILED depending on migration is done or not.
+func (driver *Driver) endMigration(ctx context.Context, startedNs int64, migrationHistoryID int64, updatedSchema string, isDone bool) error {
+	update := bson.D{
+		{Key: "status", Value: db.Failed},
+		{Key: "execution_duration_ns", Value: time.Now().UnixNano() - startedNs},
+		{Key: "updated_ts", Value: time.Now().Unix()},
+	}
+	if isDone {
+		// Upon success, update the migration history as 'DONE', execution_duration_ns, updated schema.
+		update[0].Value = db.Done
+		update = append(update, bson.E{Key: "schema", Value: updatedSchema})
+	}
+	_, err := driver.migrationHistory().UpdateByID(ctx, migrationHistoryID, bson.D{{Key: "$set", Value: update}})
+	return err
+}
+
+// FindMigrationHistoryList finds the migration history.
+func (driver *Driver) FindMigrationHistoryList(ctx context.Context, find *db.MigrationHistoryFind) ([]*db.MigrationHistory, error) {
+	filter := bson.D{}
+	if v := find.ID; v != nil {
+		filter = append(filter, bson.E{Key: "_id", Value: int64(*v)})
+	}
+	if v := find.Database; v != nil {
+		filter = append(filter, bson.E{Key: "namespace", Value: *v})
+	}
+	if v := find.Version; v != nil {
+		// TODO(d): support semantic versioning.
+		storedVersion, err := util.ToStoredVersion(false, *v, "")
+		if err != nil {
+			return nil, err
+		}
+		filter = append(filter, bson.E{Key: "version", Value: storedVersion})
+	}
+	if v := find.Source; v != nil {
+		filter = append(filter, bson.E{Key: "source", Value: *v})
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "created_ts", Value: -1}, {Key: "_id", Value: -1}})
+	if v := find.Limit; v != nil {
+		opts.SetLimit(int64(*v))
+	}
+
+	cursor, err := driver.migrationHistory().Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var histories []migrationHistory
+	if err := cursor.All(ctx, &histories); err != nil {
+		return nil, err
+	}
+
+	var migrationHistoryList []*db.MigrationHistory
+	for _, h := range histories {
+		useSemanticVersion, version, semanticVersionSuffix, err := util.FromStoredVersion(h.Version)
+		if err != nil {
+			return nil, err
+		}
+		migrationHistoryList = append(migrationHistoryList, &db.MigrationHistory{
+			ID:                    int(h.ID),
+			Creator:               h.CreatedBy,
+			CreatedTs:             h.CreatedTs,
+			Updater:               h.UpdatedBy,
+			UpdatedTs:             h.UpdatedTs,
+			ReleaseVersion:        h.ReleaseVersion,
+			Namespace:             h.Namespace,
+			Sequence:              h.Sequence,
+			Source:                db.MigrationSource(h.Source),
+			Type:                  db.MigrationType(h.Type),
+			Status:                db.MigrationStatus(h.Status),
+			Version:               version,
+			Description:           h.Description,
+			Statement:             h.Statement,
+			Schema:                h.Schema,
+			SchemaPrev:            h.SchemaPrev,
+			ExecutionDurationNs:   h.ExecutionDurationNs,
+			IssueID:               h.IssueID,
+			Payload:               h.Payload,
+			UseSemanticVersion:    useSemanticVersion,
+			SemanticVersionSuffix: semanticVersionSuffix,
+		})
+	}
+	return migrationHistoryList, nil
+}
@@ -0,0 +1,557 @@
+// Package mongodb is the plugin for MongoDB driver.
+package mongodb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.uber.org/zap"
+
+	"github.com/bytebase/bytebase/common/log"
+	"github.com/bytebase/bytebase/plugin/db"
+)
+
+var (
+	systemDatabases = map[string]bool{
+		"admin":  true,
+		"config": true,
+		"local":  true,
+	}
+
+	_ db.Driver = (*Driver)(nil)
+)
+
+func init() {
+	db.Register(db.MongoDB, newDriver)
+}
+
+// Driver is the MongoDB driver.
+type Driver struct {
+	connectionCtx db.ConnectionContext
+
+	client       *mongo.Client
+	databaseName string
+}
+
+func newDriver(db.DriverConfig) db.Driver {
+	return &Driver{}
+}
+
+// Open opens a MongoDB driver.
+func (driver *Driver) Open(ctx context.Context, _ db.Type, config db.ConnectionConfig, connCtx db.ConnectionContext) (db.Driver, error) {
+	port := config.Port
+	if port == "" {
+		port = "27017"
+	}
+	u := &url.URL{
+		Scheme: "mongodb",
+		Host:   fmt.Sprintf("%s:%s", config.Host, port),
+	}
+	opts := options.Client().ApplyURI(u.String()).SetAppName("bytebase")
+	if config.Username != "" {
+		// The users are authenticated against the admin database by default.
+		opts.SetAuth(options.Credential{
+			Username: config.Username,
+			Password: config.Password,
+		})
+	}
+	// Set SSL configuration.
+	tlsConfig, err := config.TLSConfig.GetSslConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "mongodb: tls config error")
+	}
+	if tlsConfig != nil {
+		opts.SetTLSConfig(tlsConfig)
+	}
+
+	log.Debug("Opening MongoDB driver",
+		zap.String("host", u.Host),
+		zap.String("environment", connCtx.EnvironmentName),
+		zap.String("database", connCtx.InstanceName),
+	)
+	client, err := mongo.Connect(ctx, opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to MongoDB")
+	}
+
+	driver.connectionCtx = connCtx
+	driver.client = client
+	driver.databaseName = config.Database
+	return driver, nil
+}
+
+// Close closes the driver.
+func (driver *Driver) Close(ctx context.Context) error {
+	return driver.client.Disconnect(ctx)
+}
+
+// Ping pings the database.
+func (driver *Driver) Ping(ctx context.Context) error {
+	return driver.client.Ping(ctx, readpref.Primary())
+}
+
+// GetDBConnection isn't supported for MongoDB because MongoDB doesn't support database/sql.
+func (*Driver) GetDBConnection(context.Context, string) (*sql.DB, error) {
+	return nil, errors.Errorf("MongoDB doesn't support database/sql connection")
+}
+
+// getVersion gets the version.
+func (driver *Driver) getVersion(ctx context.Context) (string, error) {
+	var result struct {
+		Version string `bson:"version"`
+	}
+	if err := driver.client.Database("admin").RunCommand(ctx, bson.D{{Key: "buildInfo", Value: 1}}).Decode(&result); err != nil {
+		return "", errors.Wrap(err, "failed to get version")
+	}
+	return result.Version, nil
+}
+
+// getDatabases gets all non-system databases, including the internal "bytebase" database.
+func (driver *Driver) getDatabases(ctx context.Context) ([]string, error) {
+	names, err := driver.client.ListDatabaseNames(ctx, bson.D{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list databases")
+	}
+	var databases []string
+	for _, name := range names {
+		if systemDatabases[name] {
+			continue
+		}
+		databases = append(databases, name)
+	}
+	sort.Strings(databases)
+	return databases, nil
+}
+
+// Execute executes mongosh-style statements, e.g. db.users.insertOne({name: "bytebase"}).
+// MongoDB doesn't support running DDL in multi-document transactions, so the statements are executed one by one without a transaction.
+func (driver *Driver) Execute(ctx context.Context, statement string) error {
+	stmts, err := splitStatements(statement)
+	if err != nil {
+		return err
+	}
+	// "use <database>" only switches the database for the rest of the statements.
+	database := driver.databaseName
+	for _, text := range stmts {
+		stmt, err := parseStatement(text)
+		if err != nil {
+			return err
+		}
+		if stmt.use != "" {
+			database = stmt.use
+			continue
+		}
+		if err := driver.executeStatement(ctx, database, stmt); err != nil {
+			return errors.Wrapf(err, "execute query %q failed", stmt.text)
+		}
+	}
+	return nil
+}
+
+func (driver *Driver) executeStatement(ctx context.Context, database string, stmt *statement) error {
+	if stmt.database != "" {
+		database = stmt.database
+	}
+	if database == "" {
+		return errors.Errorf("database is not specified, please add \"use <database>\" before the statement")
+	}
+	if len(stmt.cursorMethods) > 0 {
+		return errors.Errorf("unsupported method %s() after %s()", stmt.cursorMethods[0].name, stmt.method.name)
+	}
+	mdb := driver.client.Database(database)
+	if stmt.collection == "" {
+		return executeDatabaseMethod(ctx, mdb, stmt.method)
+	}
+	return executeCollectionMethod(ctx, mdb, stmt.collection, stmt.method)
+}
+
+func executeDatabaseMethod(ctx context.Context, mdb *mongo.Database, method call) error {
+	switch method.name {
+	case "createCollection":
+		name, err := stringArg(method.name, method.args, 0)
+		if err != nil {
+			return err
+		}
+		command := bson.D{{Key: "create", Value: name}}
+		if len(method.args) > 1 {
+			opts, err := documentArg(method.name, method.args, 1)
+			if err != nil {
+				return err
+			}
+			command = append(command, opts...)
+		}
+		return mdb.RunCommand(ctx, command).Err()
+	case "createView":
+		name, err := stringArg(method.name, method.args, 0)
+		if err != nil {
+			return err
+		}
+		source, err := stringArg(method.name, method.args, 1)
+		if err != nil {
+			return err
+		}
+		pipeline, err := arrayArg(method.name, method.args, 2)
+		if err != nil {
+			return err
+		}
+		command := bson.D{{Key: "create", Value: name}, {Key: "viewOn", Value: source}, {Key: "pipeline", Value: pipeline}}
+		if len(method.args) > 3 {
+			opts, err := documentArg(method.name, method.args, 3)
+			if err != nil {
+				return err
+			}
+			command = append(command, opts...)
+		}
+		return mdb.RunCommand(ctx, command).Err()
+	case "runCommand":
+		command, err := documentArg(method.name, method.args, 0)
+		if err != nil {
+			return err
+		}
+		return mdb.RunCommand(ctx, command).Err()
+	case "adminCommand":
+		command, err := documentArg(method.name, method.args, 0)
+		if err != nil {
+			return err
+		}
+		return mdb.Client().Database("admin").RunCommand(ctx, command).Err()
+	case "dropDatabase":
+		return mdb.Drop(ctx)
+	}
+	return errors.Errorf("unsupported database method %s()", method.name)
+}
+
+func executeCollectionMethod(ctx context.Context, mdb *mongo.Database, collection string, method call) error {
+	coll := mdb.Collection(collection)
+	switch method.name {
+	case "insertOne":
+		doc, err := documentArg(method.name, method.args, 0)
+		if err != nil {
+			return err
+		}
+		_, err = coll.InsertOne(ctx, doc)
+		return err
+	case "insertMany", "insert":
+		docs, err := arrayArg(method.name, method.args, 0)
+		if err != nil {
+			// insert() accepts a single document as well.
+			doc, docErr := documentArg(method.name, method.args, 0)
+			if method.name != "insert" || docErr != nil {
+				return err
+			}
+			docs = bson.A{doc}
+		}
+		if len(docs) == 0 {
+			return nil
+		}
+		_, err = coll.InsertMany(ctx, docs)
+		return err
+	case "updateOne", "updateMany", "replaceOne":
+		filter, err := documentArg(method.name, method.args, 0)
+		if err != nil {
+			return err
+		}
+		if len(method.args) < 2 {
+			return errors.Errorf("%s() requires at least 2 arguments", method.name)
+		}
+		upsert := false
+		if len(method.args) > 2 {
+			opts, err := documentArg(method.name, method.args, 2)
+			if err != nil {
+				return err
+			}
+			upsert, _ = opts.Map()["upsert"].(bool)
+		}
+		switch method.name {
+		case "updateOne":
+			_, err = coll.UpdateOne(ctx, filter, method.args[1], options.Update().SetUpsert(upsert))
+		case "updateMany":
+			_, err = coll.UpdateMany(ctx, filter, method.args[1], options.Update().SetUpsert(upsert))
+		default:
+			_, err = coll.ReplaceOne(ctx, filter, method.args[1], options.Replace().SetUpsert(upsert))
+		}
+		return err
+	case "deleteOne", "deleteMany":
+		filter, err := documentArg(method.name, method.args, 0)
+		if err != nil {
+			return err
+		}
+		if method.name == "deleteOne" {
+			_, err = coll.DeleteOne(ctx, filter)
+		} else {
+			_, err = coll.DeleteMany(ctx, filter)
+		}
+		return err
+	case "createIndex", "createIndexes":
+		var keysList bson.A
+		if method.name == "createIndex" {
+			keys, err := documentArg(method.name, method.args, 0)
+			if err != nil {
+				return err
+			}
+			keysList = bson.A{keys}
+		} else {
+			list, err := arrayArg(method.name, method.args, 0)
+			if err != nil {
+				return err
+			}
+			keysList = list
+		}
+		var opts bson.D
+		if len(method.args) > 1 {
+			d, err := documentArg(method.name, method.args, 1)
+			if err != nil {
+				return err
+			}
+			opts = d
+		}
+		var indexes bson.A
+		for _, v := range keysList {
+			keys, ok := v.(bson.D)
+			if !ok {
+				return errors.Errorf("the index keys of %s() should be documents", method.name)
+			}
+			index := bson.D{{Key: "key", Value: keys}}
+			if _, ok := opts.Map()["name"]; !ok {
+				index = append(index, bson.E{Key: "name", Value: getIndexName(keys)})
+			}
+			index = append(index, opts...)
+			indexes = append(indexes, index)
+		}
+		return mdb.RunCommand(ctx, bson.D{{Key: "createIndexes", Value: collection}, {Key: "indexes", Value: indexes}}).Err()
+	case "dropIndex", "dropIndexes":
+		var index interface{} = "*"
+		if len(method.args) > 0 {
+			index = method.args[0]
+		} else if method.name == "dropIndex" {
+			return errors.Errorf("dropIndex() requires the index name or keys")
+		}
+		return mdb.RunCommand(ctx, bson.D{{Key: "dropIndexes", Value: collection}, {Key: "index", Value: index}}).Err()
+	case "drop":
+		return coll.Drop(ctx)
+	case "renameCollection":
+		target, err := stringArg(method.name, method.args, 0)
+		if err != nil {
+			return err
+		}
+		dropTarget := false
+		if len(method.args) > 1 {
+			dropTarget, _ = method.args[1].(bool)
+		}
+		command := bson.D{
+			{Key: "renameCollection", Value: fmt.Sprintf("%s.%s", mdb.Name(), collection)},
+			{Key: "to", Value: fmt.Sprintf("%s.%s", mdb.Name(), target)},
+			{Key: "dropTarget", Value: dropTarget},
+		}
+		return mdb.Client().Database("admin").RunCommand(ctx, command).Err()
+	}
+	return errors.Errorf("unsupported collection method %s()", method.name)
+}
+
+// getIndexName returns the default index name generated by MongoDB, e.g. "name_1_created_-1".
+func getIndexName(keys bson.D) string {
+	var parts []string
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s_%v", key.Key, key.Value))
+	}
+	return strings.Join(parts, "_")
+}
+
+// Query queries a statement, only find(), findOne(), aggregate() and countDocuments() are supported.
+// The result is in the same format as the SQL engines, the columns are the top-level fields of the documents.
+func (driver *Driver) Query(ctx context.Context, statement string, limit int) ([]interface{}, error) {
+	stmts, err := splitStatements(statement)
+	if err != nil {
+		return nil, err
+	}
+	if len(stmts) != 1 {
+		return nil, errors.Errorf("expect exactly one statement but got %d", len(stmts))
+	}
+	stmt, err := parseStatement(stmts[0])
+	if err != nil {
+		return nil, err
+	}
+	database := driver.databaseName
+	if stmt.database != "" {
+		database = stmt.database
+	}
+	if database == "" || stmt.collection == "" {
+		return nil, errors.Errorf("unsupported query %q, the query should be in the format of db.<collection>.find()", stmt.text)
+	}
+
+	docs, err := queryCollection(ctx, driver.client.Database(database).Collection(stmt.collection), stmt, limit)
+	if err != nil {
+		return nil, err
+	}
+	return getQueryResult(docs), nil
+}
+
+func queryCollection(ctx context.Context, coll *mongo.Collection, stmt *statement, limit int) ([]bson.D, error) {
+	method := stmt.method
+	var filter interface{} = bson.D{}
+	if len(method.args) > 0 {
+		filter = method.args[0]
+	}
+	switch method.name {
+	case "find", "findOne":
+		opts := options.Find()
+		if len(method.args) > 1 {
+			opts.SetProjection(method.args[1])
+		}
+		if method.name == "findOne" {
+			limit = 1
+		}
+		for _, cursorMethod := range stmt.cursorMethods {
+			switch cursorMethod.name {
+			case "sort":
+				if len(cursorMethod.args) != 1 {
+					return nil, errors.Errorf("sort() requires 1 argument")
+				}
+				opts.SetSort(cursorMethod.args[0])
+			case "limit", "skip":
+				n, err := int64Arg(cursorMethod)
+				if err != nil {
+					return nil, err
+				}
+				if cursorMethod.name == "skip" {
+					opts.SetSkip(n)
+				} else if limit <= 0 || int(n) < limit {
+					limit = int(n)
+				}
+			case "projection":
+				if len(cursorMethod.args) != 1 {
+					return nil, errors.Errorf("projection() requires 1 argument")
+				}
+				opts.SetProjection(cursorMethod.args[0])
+			default:
+				return nil, errors.Errorf("unsupported cursor method %s()", cursorMethod.name)
+			}
+		}
+		if limit > 0 {
+			opts.SetLimit(int64(limit))
+		}
+		cursor, err := coll.Find(ctx, filter, opts)
+		if err != nil {
+			return nil, err
+		}
+		var docs []bson.D
+		if err := cursor.All(ctx, &docs); err != nil {
+			return nil, err
+		}
+		return docs, nil
+	case "aggregate":
+		pipeline, err := arrayArg(method.name, method.args, 0)
+		if err != nil {
+			return nil, err
+		}
+		if limit > 0 {
+			pipeline = append(pipeline, bson.D{{Key: "$limit", Value: limit}})
+		}
+		cursor, err := coll.Aggregate(ctx, pipeline)
+		if err != nil {
+			return nil, err
+		}
+		var docs []bson.D
+		if err := cursor.All(ctx, &docs); err != nil {
+			return nil, err
+		}
+		return docs, nil
+	case "countDocuments":
+		count, err := coll.CountDocuments(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		return []bson.D{{{Key: "count", Value: count}}}, nil
+	}
+	return nil, errors.Errorf("unsupported query method %s(), only find(), findOne(), aggregate() and countDocuments() are supported", method.name)
+}
+
+func int64Arg(method call) (int64, error) {
+	if len(method.args) != 1 {
+		return 0, errors.Errorf("%s() requires 1 argument", method.name)
+	}
+	switch v := method.args[0].(type) {
+	case int32:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case float64:
+		return int64(v), nil
+	}
+	return 0, errors.Errorf("the argument of %s() should be a number", method.name)
+}
+
+// getQueryResult converts the documents to the columns, column types and rows.
+// The nested documents, arrays and the other BSON types without JSON equivalents are formatted as Extended JSON.
+func getQueryResult(docs []bson.D) []interface{} {
+	var columnNames, columnTypeNames []string
+	columnIndex := make(map[string]int)
+	for _, doc := range docs {
+		for _, e := range doc {
+			if _, ok := columnIndex[e.Key]; ok {
+				continue
+			}
+			columnIndex[e.Key] = len(columnNames)
+			columnNames = append(columnNames, e.Key)
+			columnTypeNames = append(columnTypeNames, getTypeName(e.Value))
+		}
+	}
+
+	data := []interface{}{}
+	for _, doc := range docs {
+		row := make([]interface{}, len(columnNames))
+		for _, e := range doc {
+			row[columnIndex[e.Key]] = formatValue(e.Value)
+		}
+		data = append(data, row)
+	}
+	return []interface{}{columnNames, columnTypeNames, data}
+}
+
+func getTypeName(v interface{}) string {
+	switch v.(type) {
+	case string:
+		return "STRING"
+	case bool:
+		return "BOOL"
+	case int32:
+		return "INT"
+	case int64:
+		return "LONG"
+	case float64:
+		return "DOUBLE"
+	case primitive.ObjectID:
+		return "OBJECTID"
+	case primitive.DateTime:
+		return "DATE"
+	case bson.D:
+		return "OBJECT"
+	case bson.A:
+		return "ARRAY"
+	case nil:
+		return "NULL"
+	}
+	return "BSON"
+}
+
+func formatValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string, bool, int32, int64, float64, nil:
+		return v
+	case primitive.ObjectID:
+		return v.Hex()
+	case primitive.DateTime:
+		return v.Time().UTC().Format("2006-01-02T15:04:05.000Z")
+	}
+	return marshalExtJSON(v, false /* canonical */)
+}
@@ -0,0 +1,16 @@
+// This is the bytebase schema to track migration info for MongoDB
+// Create a database called bytebase
+use bytebase;
+
+// Create migration_history collection
+// The documents have the same fields as the migration_history table of the SQL engines, and the _id is the auto-increment id.
+db.createCollection("migration_history");
+
+// Used to detect out of order migration together with 'namespace' and 'version' field.
+db.migration_history.createIndex({namespace: 1, sequence: 1}, {name: "bytebase_idx_unique_migration_history_namespace_sequence", unique: true});
+
+db.migration_history.createIndex({namespace: 1, version: 1}, {name: "bytebase_idx_unique_migration_history_namespace_version", unique: true});
+
+db.migration_history.createIndex({namespace: 1, source: 1, type: 1}, {name: "bytebase_idx_migration_history_namespace_source_type"});
+
+db.migration_history.createIndex({namespace: 1, created_ts: 1}, {name: "bytebase_idx_migration_history_namespace_created"});
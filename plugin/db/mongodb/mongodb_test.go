@@ -0,0 +1,274 @@
+package mongodb
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/bytebase/bytebase/plugin/db"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		script string
+		want   []string
+	}{
+		{
+			script: "use test\ndb.users.insertOne({name: \"a;b\"})\n\ndb.users.drop();",
+			want: []string{
+				"use test",
+				"db.users.insertOne({name: \"a;b\"})",
+				"db.users.drop()",
+			},
+		},
+		{
+			script: "// comment\ndb.createCollection(\"users\", {\n  validator: {\n    $jsonSchema: {required: [\"name\"]} // inline comment\n  }\n}); db.users.find({name: /a\\/(b/i})\n  .limit(10)\n/* block\ncomment */",
+			want: []string{
+				"db.createCollection(\"users\", {\n  validator: {\n    $jsonSchema: {required: [\"name\"]} \n  }\n})",
+				"db.users.find({name: /a\\/(b/i})   .limit(10)",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		got, err := splitStatements(test.script)
+		require.NoError(t, err)
+		require.Equal(t, test.want, got)
+	}
+
+	_, err := splitStatements("db.users.insertOne({name: 'a'")
+	require.Error(t, err)
+}
+
+func TestParseStatement(t *testing.T) {
+	tests := []struct {
+		text string
+		want *statement
+	}{
+		{
+			text: "use test",
+			want: &statement{text: "use test", use: "test"},
+		},
+		{
+			text: `db.users.insertOne({name: 'bytebase', 'age': 3, tags: ["a", "b",],})`,
+			want: &statement{
+				text:       `db.users.insertOne({name: 'bytebase', 'age': 3, tags: ["a", "b",],})`,
+				collection: "users",
+				method: call{name: "insertOne", args: []interface{}{
+					bson.D{{Key: "name", Value: "bytebase"}, {Key: "age", Value: int32(3)}, {Key: "tags", Value: bson.A{"a", "b"}}},
+				}},
+			},
+		},
+		{
+			text: `db.getSiblingDB("other").getCollection("my.coll").find({}, {_id: 0}).sort({a: -1}).limit(5)`,
+			want: &statement{
+				text:       `db.getSiblingDB("other").getCollection("my.coll").find({}, {_id: 0}).sort({a: -1}).limit(5)`,
+				database:   "other",
+				collection: "my.coll",
+				method:     call{name: "find", args: []interface{}{bson.D{}, bson.D{{Key: "_id", Value: int32(0)}}}},
+				cursorMethods: []call{
+					{name: "sort", args: []interface{}{bson.D{{Key: "a", Value: int32(-1)}}}},
+					{name: "limit", args: []interface{}{int32(5)}},
+				},
+			},
+		},
+		{
+			text: `db.system.profile.drop()`,
+			want: &statement{
+				text:       `db.system.profile.drop()`,
+				collection: "system.profile",
+				method:     call{name: "drop", args: []interface{}{}},
+			},
+		},
+		{
+			text: `db["users"].deleteMany({})`,
+			want: &statement{
+				text:       `db["users"].deleteMany({})`,
+				collection: "users",
+				method:     call{name: "deleteMany", args: []interface{}{bson.D{}}},
+			},
+		},
+		{
+			text: `db.createCollection("users")`,
+			want: &statement{
+				text:   `db.createCollection("users")`,
+				method: call{name: "createCollection", args: []interface{}{"users"}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		got, err := parseStatement(test.text)
+		require.NoError(t, err)
+		require.Equal(t, test.want, got, test.text)
+	}
+
+	for _, text := range []string{
+		`show dbs`,
+		`db.users`,
+		`db.users.find({a: foo})`,
+		`db.users.find({a: Math.random()})`,
+	} {
+		_, err := parseStatement(text)
+		require.Error(t, err, text)
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	oid, err := primitive.ObjectIDFromHex("6347a3e7b5a4e8e3f9b1c2d3")
+	require.NoError(t, err)
+	decimal, err := primitive.ParseDecimal128("1.50")
+	require.NoError(t, err)
+
+	tests := []struct {
+		text string
+		want []interface{}
+	}{
+		{
+			text: `{_id: ObjectId("6347a3e7b5a4e8e3f9b1c2d3"), created: ISODate("2022-10-01T00:00:00Z"), date: new Date("2022-10-01")}`,
+			want: []interface{}{bson.D{
+				{Key: "_id", Value: oid},
+				{Key: "created", Value: primitive.DateTime(1664582400000)},
+				{Key: "date", Value: primitive.DateTime(1664582400000)},
+			}},
+		},
+		{
+			text: `{a: NumberLong(42), b: NumberInt("7"), c: NumberDecimal("1.50"), d: 1.5, e: -.5, f: 0x10, g: null, h: true}`,
+			want: []interface{}{bson.D{
+				{Key: "a", Value: int64(42)},
+				{Key: "b", Value: int32(7)},
+				{Key: "c", Value: decimal},
+				{Key: "d", Value: 1.5},
+				{Key: "e", Value: -0.5},
+				{Key: "f", Value: int32(16)},
+				{Key: "g", Value: nil},
+				{Key: "h", Value: true},
+			}},
+		},
+		{
+			text: `{name: /^byte\/base/mi}, {$set: {"a.b": 'it\'s'}}`,
+			want: []interface{}{
+				bson.D{{Key: "name", Value: primitive.Regex{Pattern: `^byte\/base`, Options: "im"}}},
+				bson.D{{Key: "$set", Value: bson.D{{Key: "a.b", Value: "it's"}}}},
+			},
+		},
+		{
+			text: `[{$match: {a: {$in: [1, 2]}}}, {$limit: 10}]`,
+			want: []interface{}{bson.A{
+				bson.D{{Key: "$match", Value: bson.D{{Key: "a", Value: bson.D{{Key: "$in", Value: bson.A{int32(1), int32(2)}}}}}}},
+				bson.D{{Key: "$limit", Value: int32(10)}},
+			}},
+		},
+		{
+			// Extended JSON generated by Dump.
+			text: `{"validator":{"$jsonSchema":{"required":["name"]}},"ts":{"$date":"2022-10-01T00:00:00Z"}}`,
+			want: []interface{}{bson.D{
+				{Key: "validator", Value: bson.D{{Key: "$jsonSchema", Value: bson.D{{Key: "required", Value: bson.A{"name"}}}}}},
+				{Key: "ts", Value: primitive.DateTime(1664582400000)},
+			}},
+		},
+	}
+
+	for _, test := range tests {
+		got, err := parseArgs(test.text)
+		require.NoError(t, err, test.text)
+		require.Equal(t, test.want, got, test.text)
+	}
+}
+
+func TestGetIndexName(t *testing.T) {
+	require.Equal(t, "name_1_created_-1", getIndexName(bson.D{{Key: "name", Value: int32(1)}, {Key: "created", Value: int32(-1)}}))
+	require.Equal(t, "content_text", getIndexName(bson.D{{Key: "content", Value: "text"}}))
+}
+
+func TestGetColumnList(t *testing.T) {
+	jsonSchema := bson.D{
+		{Key: "bsonType", Value: "object"},
+		{Key: "required", Value: bson.A{"name", "address"}},
+		{Key: "properties", Value: bson.D{
+			{Key: "name", Value: bson.D{{Key: "bsonType", Value: "string"}, {Key: "description", Value: "the user name"}}},
+			{Key: "age", Value: bson.D{{Key: "bsonType", Value: bson.A{"int", "null"}}}},
+			{Key: "address", Value: bson.D{
+				{Key: "bsonType", Value: "object"},
+				{Key: "required", Value: bson.A{"city"}},
+				{Key: "properties", Value: bson.D{
+					{Key: "city", Value: bson.D{{Key: "type", Value: "string"}}},
+				}},
+			}},
+		}},
+	}
+	want := []db.Column{
+		{Name: "name", Position: 1, Nullable: false, Type: "string", Comment: "the user name"},
+		{Name: "age", Position: 2, Nullable: true, Type: "int|null"},
+		{Name: "address", Position: 3, Nullable: false, Type: "object"},
+		{Name: "address.city", Position: 4, Nullable: false, Type: "string"},
+	}
+	require.Equal(t, want, getColumnList(jsonSchema))
+}
+
+func TestGetIndexKeysAndOptions(t *testing.T) {
+	keys, opts := getIndexKeysAndOptions(bson.D{
+		{Key: "v", Value: int32(2)},
+		{Key: "key", Value: bson.D{{Key: "email", Value: int32(1)}}},
+		{Key: "name", Value: "email_1"},
+		{Key: "unique", Value: true},
+	})
+	require.Equal(t, bson.D{{Key: "email", Value: int32(1)}}, keys)
+	require.Equal(t, bson.D{{Key: "name", Value: "email_1"}, {Key: "unique", Value: true}}, opts)
+}
+
+// Only for manual test against a local container, e.g.
+// docker run -d -p 27017:27017 -e MONGO_INITDB_ROOT_USERNAME=root -e MONGO_INITDB_ROOT_PASSWORD=<password> mongo:5
+// Should be skipped in CI.
+func TestMigrationAndDump(t *testing.T) {
+	t.Skip()
+	a := require.New(t)
+	ctx := context.Background()
+
+	driver, err := newDriver(db.DriverConfig{}).Open(ctx, db.MongoDB, db.ConnectionConfig{
+		Host:     "localhost",
+		Port:     "27017",
+		Username: "root",
+		Password: os.Getenv("MONGODB_PASSWORD"),
+	}, db.ConnectionContext{})
+	a.NoError(err)
+	defer driver.Close(ctx)
+	defer driver.Execute(ctx, "use bb_test\ndb.dropDatabase()")
+
+	a.NoError(driver.SetupMigrationIfNeeded(ctx))
+	_, _, err = driver.ExecuteMigration(ctx, &db.MigrationInfo{
+		Version:     "0001",
+		Namespace:   "bb_test",
+		Database:    "bb_test",
+		Environment: "test",
+		Source:      db.UI,
+		Type:        db.Migrate,
+		Creator:     "bytebase",
+	}, `db.createCollection("users", {validator: {$jsonSchema: {required: ["name"], properties: {name: {bsonType: "string"}}}}});
+db.users.createIndex({name: 1}, {unique: true});
+db.users.insertOne({name: "bytebase"});`)
+	a.NoError(err)
+
+	namespace := "bb_test"
+	list, err := driver.FindMigrationHistoryList(ctx, &db.MigrationHistoryFind{Database: &namespace})
+	a.NoError(err)
+	a.Len(list, 1)
+	a.Equal(db.Done, list[0].Status)
+	a.Contains(list[0].Schema, `db.getCollection("users").createIndex({"name":1}`)
+
+	schema, err := driver.SyncDBSchema(ctx, "bb_test")
+	a.NoError(err)
+	a.Len(schema.TableList, 1)
+	a.Equal(int64(1), schema.TableList[0].RowCount)
+	a.Equal("name", schema.TableList[0].ColumnList[0].Name)
+
+	var buf bytes.Buffer
+	_, err = driver.Dump(ctx, "bb_test", &buf, false /* schemaOnly */)
+	a.NoError(err)
+	a.Contains(buf.String(), `"name":"bytebase"`)
+}
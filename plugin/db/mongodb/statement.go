@@ -0,0 +1,791 @@
+package mongodb
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// The statements are mongosh-style commands, e.g.
+//
+//	use test;
+//	db.createCollection("users", {validator: {$jsonSchema: {bsonType: "object", required: ["name"]}}});
+//	db.users.createIndex({name: 1}, {unique: true});
+//	db.users.insertOne({name: "bytebase", created: ISODate("2022-10-01T00:00:00Z")});
+//
+// The arguments are JavaScript literals, which are converted to MongoDB Extended JSON and decoded as BSON values.
+// Only literals and the mongosh constructors such as ObjectId() and ISODate() are supported, other JavaScript expressions are not evaluated.
+
+var useRegexp = regexp.MustCompile(`^use\s+(\S+)$`)
+
+// statement is a parsed mongosh statement.
+type statement struct {
+	// text is the original text of the statement.
+	text string
+	// use is the database of the "use <database>" statement.
+	use string
+	// database is the database switched by db.getSiblingDB() in the statement, empty for the current database.
+	database string
+	// collection is the collection of the collection methods, empty for the database methods.
+	collection string
+	// method is the method called on the database or the collection, e.g. insertOne().
+	method call
+	// cursorMethods are the methods chained after the method, e.g. sort() and limit() after find().
+	cursorMethods []call
+}
+
+// call is a method call with the arguments decoded as BSON values.
+type call struct {
+	name string
+	args []interface{}
+}
+
+// splitStatements splits the mongosh script into statements.
+// A statement ends with a semicolon or a new line outside the brackets, unless the next line starts with a chained method call.
+// The comments are removed.
+func splitStatements(script string) ([]string, error) {
+	runes := []rune(script)
+	var stmts []string
+	var buf strings.Builder
+	flush := func() {
+		if s := strings.TrimSpace(buf.String()); s != "" {
+			stmts = append(stmts, s)
+		}
+		buf.Reset()
+	}
+
+	depth := 0
+	var prev rune
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '/':
+			// Skip the line comment but keep the new line.
+			for i+1 < len(runes) && runes[i+1] != '\n' {
+				i++
+			}
+			continue
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			j := i + 2
+			for j+1 < len(runes) && !(runes[j] == '*' && runes[j+1] == '/') {
+				j++
+			}
+			if j+1 >= len(runes) {
+				return nil, errors.Errorf("unterminated comment")
+			}
+			i = j + 1
+			buf.WriteRune(' ')
+			continue
+		case isLiteralStart(runes, i, prev):
+			end, err := skipLiteral(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			buf.WriteString(string(runes[i:end]))
+			i = end - 1
+			prev = runes[i]
+			continue
+		case r == '(' || r == '[' || r == '{':
+			depth++
+		case r == ')' || r == ']' || r == '}':
+			depth--
+			if depth < 0 {
+				return nil, errors.Errorf("unexpected %q", r)
+			}
+		case r == ';' && depth == 0:
+			flush()
+			prev = 0
+			continue
+		case r == '\n' && depth == 0:
+			// The statement may continue with a chained method call on the next line.
+			j := i + 1
+			for j < len(runes) && unicode.IsSpace(runes[j]) {
+				j++
+			}
+			if j < len(runes) && runes[j] == '.' {
+				buf.WriteRune(' ')
+				continue
+			}
+			flush()
+			prev = 0
+			continue
+		}
+		buf.WriteRune(r)
+		if !unicode.IsSpace(r) {
+			prev = r
+		}
+	}
+	if depth != 0 {
+		return nil, errors.Errorf("unbalanced brackets in statement %q", strings.TrimSpace(buf.String()))
+	}
+	flush()
+	return stmts, nil
+}
+
+// parseStatement parses a statement split by splitStatements.
+func parseStatement(text string) (*statement, error) {
+	text = strings.TrimSpace(text)
+	if matches := useRegexp.FindStringSubmatch(text); matches != nil {
+		return &statement{text: text, use: strings.Trim(matches[1], `"'`)}, nil
+	}
+
+	runes := []rune(text)
+	type segment struct {
+		name string
+		// args is nil for property accesses.
+		args []interface{}
+	}
+	var segments []segment
+	i := skipSpaces(runes, 0)
+	name, i := readIdentifier(runes, i)
+	if name != "db" {
+		return nil, errors.Errorf("unsupported statement %q, the statement should be \"use <database>\" or start with \"db\"", text)
+	}
+	for i = skipSpaces(runes, i); i < len(runes); i = skipSpaces(runes, i) {
+		switch runes[i] {
+		case '.':
+			name, i = readIdentifier(runes, skipSpaces(runes, i+1))
+			if name == "" {
+				return nil, errors.Errorf("invalid statement %q, missing property name at position %d", text, i)
+			}
+			i = skipSpaces(runes, i)
+			if i < len(runes) && runes[i] == '(' {
+				end, err := findClosing(runes, i)
+				if err != nil {
+					return nil, err
+				}
+				args, err := parseArgs(string(runes[i+1 : end]))
+				if err != nil {
+					return nil, errors.Wrapf(err, "invalid arguments of %s()", name)
+				}
+				if args == nil {
+					args = []interface{}{}
+				}
+				segments = append(segments, segment{name: name, args: args})
+				i = end + 1
+				continue
+			}
+			segments = append(segments, segment{name: name})
+		case '[':
+			end, err := findClosing(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			property, err := parseStringArg(string(runes[i+1 : end]))
+			if err != nil {
+				return nil, err
+			}
+			segments = append(segments, segment{name: property})
+			i = end + 1
+		default:
+			return nil, errors.Errorf("invalid statement %q, unexpected %q at position %d", text, runes[i], i)
+		}
+	}
+
+	stmt := &statement{text: text}
+	if len(segments) > 0 && segments[0].name == "getSiblingDB" && segments[0].args != nil {
+		database, err := stringArg(segments[0].name, segments[0].args, 0)
+		if err != nil {
+			return nil, err
+		}
+		stmt.database = database
+		segments = segments[1:]
+	}
+	if len(segments) > 0 && segments[0].name == "getCollection" && segments[0].args != nil {
+		collection, err := stringArg(segments[0].name, segments[0].args, 0)
+		if err != nil {
+			return nil, err
+		}
+		stmt.collection = collection
+		segments = segments[1:]
+	} else {
+		// Collection names may contain dots, e.g. db.system.profile.find().
+		var names []string
+		for len(segments) > 0 && segments[0].args == nil {
+			names = append(names, segments[0].name)
+			segments = segments[1:]
+		}
+		stmt.collection = strings.Join(names, ".")
+	}
+	if len(segments) == 0 {
+		return nil, errors.Errorf("invalid statement %q, missing method call", text)
+	}
+	for i, s := range segments {
+		if s.args == nil {
+			return nil, errors.Errorf("invalid statement %q, unexpected property %q", text, s.name)
+		}
+		if i == 0 {
+			stmt.method = call{name: s.name, args: s.args}
+			continue
+		}
+		stmt.cursorMethods = append(stmt.cursorMethods, call{name: s.name, args: s.args})
+	}
+	return stmt, nil
+}
+
+// parseArgs parses the comma separated JavaScript literals into BSON values.
+func parseArgs(text string) ([]interface{}, error) {
+	pieces, err := splitTopLevel([]rune(text))
+	if err != nil {
+		return nil, err
+	}
+	var args []interface{}
+	for _, piece := range pieces {
+		extJSON, err := toExtJSON(piece)
+		if err != nil {
+			return nil, err
+		}
+		var doc bson.D
+		if err := bson.UnmarshalExtJSON([]byte(fmt.Sprintf(`{"v": %s}`, extJSON)), false /* canonical */, &doc); err != nil {
+			return nil, errors.Wrapf(err, "failed to decode %q", piece)
+		}
+		args = append(args, doc[0].Value)
+	}
+	return args, nil
+}
+
+func parseStringArg(text string) (string, error) {
+	args, err := parseArgs(text)
+	if err != nil {
+		return "", err
+	}
+	if len(args) != 1 {
+		return "", errors.Errorf("expect a string but got %q", text)
+	}
+	s, ok := args[0].(string)
+	if !ok {
+		return "", errors.Errorf("expect a string but got %q", text)
+	}
+	return s, nil
+}
+
+// toExtJSON converts the JavaScript literal to MongoDB Extended JSON.
+func toExtJSON(text string) (string, error) {
+	runes := []rune(text)
+	var out strings.Builder
+	// Commas are written lazily so that the trailing commas allowed in JavaScript are dropped.
+	pendingComma := false
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		if unicode.IsSpace(r) {
+			i++
+			continue
+		}
+		if r == ',' {
+			pendingComma = true
+			i++
+			continue
+		}
+		if r == '}' || r == ']' {
+			pendingComma = false
+			out.WriteRune(r)
+			i++
+			continue
+		}
+		if pendingComma {
+			out.WriteRune(',')
+			pendingComma = false
+		}
+		switch {
+		case r == '{' || r == '[' || r == ':':
+			out.WriteRune(r)
+			i++
+		case r == '"' || r == '\'' || r == '`':
+			end, err := skipLiteral(runes, i)
+			if err != nil {
+				return "", err
+			}
+			s, err := unquote(runes[i:end])
+			if err != nil {
+				return "", err
+			}
+			out.WriteString(quote(s))
+			i = end
+		case r == '/':
+			end, err := skipLiteral(runes, i)
+			if err != nil {
+				return "", err
+			}
+			pattern := string(runes[i+1 : end-1])
+			j := end
+			for j < len(runes) && unicode.IsLetter(runes[j]) {
+				j++
+			}
+			out.WriteString(regexExtJSON(pattern, string(runes[end:j])))
+			i = j
+		case r == '-' || r == '+' || r == '.' || unicode.IsDigit(r):
+			j := i + 1
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || unicode.IsLetter(runes[j]) || runes[j] == '.' || runes[j] == '+' || runes[j] == '-') {
+				j++
+			}
+			number, err := formatNumber(string(runes[i:j]))
+			if err != nil {
+				return "", err
+			}
+			out.WriteString(number)
+			i = j
+		case isIdentifierStart(r):
+			ident, j := readIdentifier(runes, i)
+			k := skipSpaces(runes, j)
+			switch {
+			case k < len(runes) && runes[k] == ':':
+				// Unquoted object keys.
+				out.WriteString(quote(ident))
+				i = j
+			case ident == "new":
+				i = j
+			case k < len(runes) && runes[k] == '(':
+				end, err := findClosing(runes, k)
+				if err != nil {
+					return "", err
+				}
+				s, err := constructorExtJSON(ident, string(runes[k+1:end]))
+				if err != nil {
+					return "", err
+				}
+				out.WriteString(s)
+				i = end + 1
+			default:
+				s, err := identifierExtJSON(ident)
+				if err != nil {
+					return "", err
+				}
+				out.WriteString(s)
+				i = j
+			}
+		default:
+			return "", errors.Errorf("unexpected %q in %q", r, text)
+		}
+	}
+	return out.String(), nil
+}
+
+func identifierExtJSON(ident string) (string, error) {
+	switch ident {
+	case "true", "false", "null":
+		return ident, nil
+	case "undefined":
+		return "null", nil
+	case "Infinity", "NaN":
+		return fmt.Sprintf(`{"$numberDouble": %q}`, ident), nil
+	case "MinKey":
+		return `{"$minKey": 1}`, nil
+	case "MaxKey":
+		return `{"$maxKey": 1}`, nil
+	}
+	return "", errors.Errorf("unsupported identifier %q, only literals are supported", ident)
+}
+
+// constructorExtJSON converts the mongosh constructors such as ObjectId() and ISODate() to Extended JSON.
+func constructorExtJSON(name string, argsText string) (string, error) {
+	pieces, err := splitTopLevel([]rune(argsText))
+	if err != nil {
+		return "", err
+	}
+	// The arguments of the constructors are strings or numbers.
+	var args []string
+	for _, piece := range pieces {
+		runes := []rune(strings.TrimSpace(piece))
+		if len(runes) > 0 && (runes[0] == '"' || runes[0] == '\'' || runes[0] == '`') {
+			s, err := unquote(runes)
+			if err != nil {
+				return "", err
+			}
+			args = append(args, s)
+			continue
+		}
+		number, err := formatNumber(string(runes))
+		if err != nil {
+			return "", errors.Wrapf(err, "invalid argument of %s()", name)
+		}
+		args = append(args, number)
+	}
+	arg := func(i int) (string, error) {
+		if i >= len(args) {
+			return "", errors.Errorf("%s() requires %d arguments", name, i+1)
+		}
+		return args[i], nil
+	}
+
+	switch name {
+	case "ObjectId", "ObjectID":
+		if len(args) == 0 {
+			return fmt.Sprintf(`{"$oid": %q}`, primitive.NewObjectID().Hex()), nil
+		}
+		return fmt.Sprintf(`{"$oid": %s}`, quote(args[0])), nil
+	case "ISODate", "Date":
+		if len(args) == 0 {
+			return fmt.Sprintf(`{"$date": {"$numberLong": "%d"}}`, time.Now().UnixMilli()), nil
+		}
+		ms, err := parseDate(args[0])
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf(`{"$date": {"$numberLong": "%d"}}`, ms), nil
+	case "NumberLong", "Long":
+		v, err := arg(0)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf(`{"$numberLong": %s}`, quote(v)), nil
+	case "NumberInt", "Int32":
+		v, err := arg(0)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf(`{"$numberInt": %s}`, quote(v)), nil
+	case "NumberDecimal", "Decimal128":
+		v, err := arg(0)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf(`{"$numberDecimal": %s}`, quote(v)), nil
+	case "Double":
+		v, err := arg(0)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf(`{"$numberDouble": %s}`, quote(v)), nil
+	case "Timestamp":
+		t, err := arg(0)
+		if err != nil {
+			return "", err
+		}
+		i, err := arg(1)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf(`{"$timestamp": {"t": %s, "i": %s}}`, t, i), nil
+	case "BinData":
+		subType, err := arg(0)
+		if err != nil {
+			return "", err
+		}
+		data, err := arg(1)
+		if err != nil {
+			return "", err
+		}
+		st, err := strconv.Atoi(subType)
+		if err != nil {
+			return "", errors.Wrapf(err, "invalid subtype %q of BinData()", subType)
+		}
+		return fmt.Sprintf(`{"$binary": {"base64": %s, "subType": "%02x"}}`, quote(data), st), nil
+	case "UUID":
+		v, err := arg(0)
+		if err != nil {
+			return "", err
+		}
+		b, err := hex.DecodeString(strings.ReplaceAll(v, "-", ""))
+		if err != nil || len(b) != 16 {
+			return "", errors.Errorf("invalid UUID %q", v)
+		}
+		return fmt.Sprintf(`{"$binary": {"base64": %q, "subType": "04"}}`, base64.StdEncoding.EncodeToString(b)), nil
+	case "RegExp":
+		pattern, err := arg(0)
+		if err != nil {
+			return "", err
+		}
+		flags := ""
+		if len(args) > 1 {
+			flags = args[1]
+		}
+		return regexExtJSON(pattern, flags), nil
+	case "MinKey":
+		return `{"$minKey": 1}`, nil
+	case "MaxKey":
+		return `{"$maxKey": 1}`, nil
+	}
+	return "", errors.Errorf("unsupported function %s(), only the literals and the constructors such as ObjectId() and ISODate() are supported", name)
+}
+
+// parseDate parses the date string or the milliseconds since epoch to the milliseconds since epoch.
+func parseDate(s string) (int64, error) {
+	if ms, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return ms, nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999", "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UnixMilli(), nil
+		}
+	}
+	return 0, errors.Errorf("invalid date %q", s)
+}
+
+func regexExtJSON(pattern, flags string) string {
+	options := strings.Split(flags, "")
+	// The options of the Extended JSON regular expression must be sorted.
+	sort.Strings(options)
+	return fmt.Sprintf(`{"$regularExpression": {"pattern": %s, "options": %s}}`, quote(pattern), quote(strings.Join(options, "")))
+}
+
+// formatNumber formats the JavaScript number literal as a JSON number.
+func formatNumber(s string) (string, error) {
+	s = strings.TrimPrefix(s, "+")
+	unsigned := strings.TrimPrefix(s, "-")
+	if strings.HasPrefix(unsigned, "0x") || strings.HasPrefix(unsigned, "0X") {
+		v, err := strconv.ParseInt(s, 0, 64)
+		if err != nil {
+			return "", errors.Errorf("invalid number %q", s)
+		}
+		return strconv.FormatInt(v, 10), nil
+	}
+	if _, err := strconv.ParseFloat(s, 64); err != nil {
+		return "", errors.Errorf("invalid number %q", s)
+	}
+	// JSON doesn't allow the numbers like ".5" and "5.".
+	if strings.HasPrefix(unsigned, ".") {
+		s = strings.Replace(s, ".", "0.", 1)
+	}
+	if strings.HasSuffix(s, ".") {
+		s += "0"
+	}
+	return s, nil
+}
+
+func quote(s string) string {
+	var buf strings.Builder
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	// Encoding a string never fails.
+	_ = encoder.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// unquote decodes the JavaScript string literal including the quotes.
+func unquote(runes []rune) (string, error) {
+	if len(runes) < 2 || runes[len(runes)-1] != runes[0] {
+		return "", errors.Errorf("invalid string %q", string(runes))
+	}
+	var out strings.Builder
+	body := runes[1 : len(runes)-1]
+	for i := 0; i < len(body); i++ {
+		if body[i] != '\\' {
+			out.WriteRune(body[i])
+			continue
+		}
+		i++
+		if i >= len(body) {
+			return "", errors.Errorf("invalid string %q", string(runes))
+		}
+		switch body[i] {
+		case 'n':
+			out.WriteRune('\n')
+		case 'r':
+			out.WriteRune('\r')
+		case 't':
+			out.WriteRune('\t')
+		case 'b':
+			out.WriteRune('\b')
+		case 'f':
+			out.WriteRune('\f')
+		case 'v':
+			out.WriteRune('\v')
+		case '0':
+			out.WriteRune(0)
+		case '\n':
+			// Line continuation.
+		case 'x', 'u':
+			var digits string
+			if body[i] == 'u' && i+1 < len(body) && body[i+1] == '{' {
+				j := i + 2
+				for j < len(body) && body[j] != '}' {
+					j++
+				}
+				if j >= len(body) {
+					return "", errors.Errorf("invalid unicode escape in %q", string(runes))
+				}
+				digits, i = string(body[i+2:j]), j
+			} else {
+				size := 2
+				if body[i] == 'u' {
+					size = 4
+				}
+				if i+size >= len(body) {
+					return "", errors.Errorf("invalid escape in %q", string(runes))
+				}
+				digits, i = string(body[i+1:i+1+size]), i+size
+			}
+			v, err := strconv.ParseUint(digits, 16, 32)
+			if err != nil {
+				return "", errors.Errorf("invalid escape in %q", string(runes))
+			}
+			out.WriteRune(rune(v))
+		default:
+			out.WriteRune(body[i])
+		}
+	}
+	return out.String(), nil
+}
+
+// isLiteralStart returns whether a string or regular expression literal starts at i.
+// prev is the previous non-space rune, which tells a regular expression from a division.
+func isLiteralStart(runes []rune, i int, prev rune) bool {
+	switch runes[i] {
+	case '"', '\'', '`':
+		return true
+	case '/':
+		return prev == 0 || strings.ContainsRune("(,:[=!&|?{};", prev)
+	}
+	return false
+}
+
+// skipLiteral returns the index after the string or regular expression literal starting at i.
+func skipLiteral(runes []rune, i int) (int, error) {
+	delimiter := runes[i]
+	inClass := false
+	for j := i + 1; j < len(runes); j++ {
+		switch {
+		case runes[j] == '\\':
+			j++
+		case delimiter == '/' && runes[j] == '[':
+			inClass = true
+		case delimiter == '/' && runes[j] == ']':
+			inClass = false
+		case runes[j] == delimiter && !inClass:
+			return j + 1, nil
+		case runes[j] == '\n' && delimiter != '`':
+			return 0, errors.Errorf("unterminated literal %q", string(runes[i:j]))
+		}
+	}
+	return 0, errors.Errorf("unterminated literal %q", string(runes[i:]))
+}
+
+// findClosing returns the index of the bracket closing the one at i.
+func findClosing(runes []rune, i int) (int, error) {
+	depth := 0
+	var prev rune
+	for j := i; j < len(runes); j++ {
+		if isLiteralStart(runes, j, prev) {
+			end, err := skipLiteral(runes, j)
+			if err != nil {
+				return 0, err
+			}
+			j = end - 1
+			prev = runes[j]
+			continue
+		}
+		switch runes[j] {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+			if depth == 0 {
+				return j, nil
+			}
+		}
+		if !unicode.IsSpace(runes[j]) {
+			prev = runes[j]
+		}
+	}
+	return 0, errors.Errorf("unbalanced brackets in %q", string(runes[i:]))
+}
+
+// splitTopLevel splits the text by the commas outside the brackets and literals.
+func splitTopLevel(runes []rune) ([]string, error) {
+	var pieces []string
+	depth, start := 0, 0
+	var prev rune
+	for j := 0; j < len(runes); j++ {
+		if isLiteralStart(runes, j, prev) {
+			end, err := skipLiteral(runes, j)
+			if err != nil {
+				return nil, err
+			}
+			j = end - 1
+			prev = runes[j]
+			continue
+		}
+		switch runes[j] {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				pieces = append(pieces, string(runes[start:j]))
+				start = j + 1
+			}
+		}
+		if !unicode.IsSpace(runes[j]) {
+			prev = runes[j]
+		}
+	}
+	pieces = append(pieces, string(runes[start:]))
+
+	var nonEmpty []string
+	for _, piece := range pieces {
+		if strings.TrimSpace(piece) != "" {
+			nonEmpty = append(nonEmpty, piece)
+		}
+	}
+	return nonEmpty, nil
+}
+
+func skipSpaces(runes []rune, i int) int {
+	for i < len(runes) && unicode.IsSpace(runes[i]) {
+		i++
+	}
+	return i
+}
+
+func isIdentifierStart(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r)
+}
+
+func readIdentifier(runes []rune, i int) (string, int) {
+	if i >= len(runes) || !isIdentifierStart(runes[i]) {
+		return "", i
+	}
+	j := i + 1
+	for j < len(runes) && (isIdentifierStart(runes[j]) || unicode.IsDigit(runes[j])) {
+		j++
+	}
+	return string(runes[i:j]), j
+}
+
+// stringArg returns the i-th argument of the method as a string.
+func stringArg(method string, args []interface{}, i int) (string, error) {
+	if i >= len(args) {
+		return "", errors.Errorf("%s() requires at least %d arguments", method, i+1)
+	}
+	s, ok := args[i].(string)
+	if !ok {
+		return "", errors.Errorf("the argument %d of %s() should be a string", i+1, method)
+	}
+	return s, nil
+}
+
+// documentArg returns the i-th argument of the method as a document.
+func documentArg(method string, args []interface{}, i int) (bson.D, error) {
+	if i >= len(args) {
+		return nil, errors.Errorf("%s() requires at least %d arguments", method, i+1)
+	}
+	d, ok := args[i].(bson.D)
+	if !ok {
+		return nil, errors.Errorf("the argument %d of %s() should be a document", i+1, method)
+	}
+	return d, nil
+}
+
+// arrayArg returns the i-th argument of the method as an array.
+func arrayArg(method string, args []interface{}, i int) (bson.A, error) {
+	if i >= len(args) {
+		return nil, errors.Errorf("%s() requires at least %d arguments", method, i+1)
+	}
+	a, ok := args[i].(bson.A)
+	if !ok {
+		return nil, errors.Errorf("the argument %d of %s() should be an array", i+1, method)
+	}
+	return a, nil
+}
@@ -0,0 +1,313 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/plugin/db"
+)
+
+// collectionInfo is the collection information returned by the listCollections command.
+type collectionInfo struct {
+	Name    string `bson:"name"`
+	Type    string `bson:"type"`
+	Options bson.D `bson:"options"`
+}
+
+// SyncInstance syncs the instance.
+func (driver *Driver) SyncInstance(ctx context.Context) (*db.InstanceMeta, error) {
+	version, err := driver.getVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Query user info
+	userList, err := driver.getUserList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Query db info
+	databases, err := driver.getDatabases(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var databaseList []db.DatabaseMeta
+	for _, database := range databases {
+		// Skip our internal "bytebase" database
+		if database == db.BytebaseDatabase {
+			continue
+		}
+		databaseList = append(databaseList, db.DatabaseMeta{Name: database})
+	}
+
+	return &db.InstanceMeta{
+		Version:      version,
+		UserList:     userList,
+		DatabaseList: databaseList,
+	}, nil
+}
+
+// SyncDBSchema syncs a single database schema.
+func (driver *Driver) SyncDBSchema(ctx context.Context, databaseName string) (*db.Schema, error) {
+	databases, err := driver.getDatabases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	found := false
+	for _, database := range databases {
+		if database == databaseName {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, common.Errorf(common.NotFound, "database %q not found", databaseName)
+	}
+
+	mdb := driver.client.Database(databaseName)
+	collections, err := listCollections(ctx, mdb)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := db.Schema{
+		Name: databaseName,
+	}
+	for _, collection := range collections {
+		switch collection.Type {
+		case "collection":
+			table, err := getTable(ctx, mdb, collection)
+			if err != nil {
+				return nil, err
+			}
+			schema.TableList = append(schema.TableList, *table)
+		case "view":
+			schema.ViewList = append(schema.ViewList, db.View{
+				Name:       collection.Name,
+				Definition: getViewDefinition(collection),
+			})
+		}
+	}
+	return &schema, nil
+}
+
+func (driver *Driver) getUserList(ctx context.Context) ([]db.User, error) {
+	// MongoDB users are defined in the databases, so we list the users in all databases.
+	var result struct {
+		Users []struct {
+			User  string `bson:"user"`
+			DB    string `bson:"db"`
+			Roles []struct {
+				Role string `bson:"role"`
+				DB   string `bson:"db"`
+			} `bson:"roles"`
+		} `bson:"users"`
+	}
+	command := bson.D{{Key: "usersInfo", Value: bson.D{{Key: "forAllDBs", Value: true}}}}
+	if err := driver.client.Database("admin").RunCommand(ctx, command).Decode(&result); err != nil {
+		return nil, errors.Wrap(err, "failed to get users")
+	}
+
+	var userList []db.User
+	for _, u := range result.Users {
+		var roles []string
+		for _, role := range u.Roles {
+			roles = append(roles, fmt.Sprintf("%s@%s", role.Role, role.DB))
+		}
+		userList = append(userList, db.User{
+			Name:  fmt.Sprintf("%s@%s", u.User, u.DB),
+			Grant: strings.Join(roles, ", "),
+		})
+	}
+	return userList, nil
+}
+
+// listCollections lists the collections and views of the database excluding the system collections, ordered by name.
+func listCollections(ctx context.Context, mdb *mongo.Database) ([]collectionInfo, error) {
+	cursor, err := mdb.ListCollections(ctx, bson.D{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list collections of database %q", mdb.Name())
+	}
+	var collections []collectionInfo
+	if err := cursor.All(ctx, &collections); err != nil {
+		return nil, err
+	}
+
+	var result []collectionInfo
+	for _, collection := range collections {
+		if strings.HasPrefix(collection.Name, "system.") {
+			continue
+		}
+		result = append(result, collection)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+func getTable(ctx context.Context, mdb *mongo.Database, collection collectionInfo) (*db.Table, error) {
+	table := db.Table{
+		Name: collection.Name,
+		Type: "COLLECTION",
+	}
+
+	var stats struct {
+		Count          int64 `bson:"count"`
+		Size           int64 `bson:"size"`
+		TotalIndexSize int64 `bson:"totalIndexSize"`
+	}
+	if err := mdb.RunCommand(ctx, bson.D{{Key: "collStats", Value: collection.Name}}).Decode(&stats); err != nil {
+		return nil, errors.Wrapf(err, "failed to get stats of collection %q", collection.Name)
+	}
+	table.RowCount, table.DataSize, table.IndexSize = stats.Count, stats.Size, stats.TotalIndexSize
+
+	indexes, err := listIndexes(ctx, mdb.Collection(collection.Name))
+	if err != nil {
+		return nil, err
+	}
+	for _, index := range indexes {
+		table.IndexList = append(table.IndexList, getIndexList(index)...)
+	}
+
+	if validator, ok := collection.Options.Map()["validator"].(bson.D); ok {
+		if jsonSchema, ok := validator.Map()["$jsonSchema"].(bson.D); ok {
+			table.ColumnList = getColumnList(jsonSchema)
+		}
+	}
+	return &table, nil
+}
+
+// listIndexes lists the index specifications of the collection.
+func listIndexes(ctx context.Context, coll *mongo.Collection) ([]bson.D, error) {
+	cursor, err := coll.Indexes().List(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list indexes of collection %q", coll.Name())
+	}
+	var indexes []bson.D
+	if err := cursor.All(ctx, &indexes); err != nil {
+		return nil, err
+	}
+	return indexes, nil
+}
+
+// getIndexList converts the index specification to an index entry for each key, similar to the SQL engines.
+func getIndexList(spec bson.D) []db.Index {
+	m := spec.Map()
+	name, _ := m["name"].(string)
+	keys, _ := m["key"].(bson.D)
+	unique, _ := m["unique"].(bool)
+	hidden, _ := m["hidden"].(bool)
+
+	var indexList []db.Index
+	for i, key := range keys {
+		indexList = append(indexList, db.Index{
+			Name:       name,
+			Expression: key.Key,
+			Position:   i + 1,
+			// The type is the index key value, e.g. 1, -1, "text", "2dsphere" and "hashed".
+			Type: fmt.Sprintf("%v", key.Value),
+			// The _id index is always unique, but the "unique" field is omitted in its specification.
+			Unique:  unique || name == "_id_",
+			Primary: name == "_id_",
+			Visible: !hidden,
+		})
+	}
+	return indexList
+}
+
+// getColumnList converts the properties of the $jsonSchema validator to columns.
+// The nested properties are flattened with the dotted field paths, e.g. "address.city".
+func getColumnList(jsonSchema bson.D) []db.Column {
+	var columnList []db.Column
+	var walk func(prefix string, schema bson.D)
+	walk = func(prefix string, schema bson.D) {
+		m := schema.Map()
+		required := make(map[string]bool)
+		if list, ok := m["required"].(bson.A); ok {
+			for _, v := range list {
+				if s, ok := v.(string); ok {
+					required[s] = true
+				}
+			}
+		}
+		properties, _ := m["properties"].(bson.D)
+		for _, property := range properties {
+			propertySchema, ok := property.Value.(bson.D)
+			if !ok {
+				continue
+			}
+			name := prefix + property.Key
+			bsonTypes := getBSONTypes(propertySchema)
+			pm := propertySchema.Map()
+			description, _ := pm["description"].(string)
+			nullable := !required[property.Key]
+			for _, t := range bsonTypes {
+				if t == "null" {
+					nullable = true
+				}
+			}
+			column := db.Column{
+				Name:     name,
+				Position: len(columnList) + 1,
+				Nullable: nullable,
+				Type:     strings.Join(bsonTypes, "|"),
+				Comment:  description,
+			}
+			columnList = append(columnList, column)
+			walk(name+".", propertySchema)
+		}
+	}
+	walk("", jsonSchema)
+	return columnList
+}
+
+// getBSONTypes returns the types of the $jsonSchema property, "bsonType" takes precedence over "type".
+func getBSONTypes(schema bson.D) []string {
+	m := schema.Map()
+	for _, key := range []string{"bsonType", "type"} {
+		switch v := m[key].(type) {
+		case string:
+			return []string{v}
+		case bson.A:
+			var types []string
+			for _, t := range v {
+				if s, ok := t.(string); ok {
+					types = append(types, s)
+				}
+			}
+			return types
+		}
+	}
+	return nil
+}
+
+// getViewDefinition returns the db.createView() statement of the view.
+func getViewDefinition(view collectionInfo) string {
+	m := view.Options.Map()
+	viewOn, _ := m["viewOn"].(string)
+	pipeline, ok := m["pipeline"].(bson.A)
+	if !ok {
+		pipeline = bson.A{}
+	}
+	return fmt.Sprintf("db.createView(%s, %s, %s);", quote(view.Name), quote(viewOn), marshalExtJSON(pipeline, false /* canonical */))
+}
+
+// marshalExtJSON marshals the BSON value to Extended JSON, the value could be a document or an array.
+func marshalExtJSON(v interface{}, canonical bool) string {
+	b, err := bson.MarshalExtJSON(bson.D{{Key: "v", Value: v}}, canonical, false /* escapeHTML */)
+	if err != nil {
+		return "null"
+	}
+	s := string(b)
+	return strings.TrimSuffix(strings.TrimPrefix(s, `{"v":`), "}")
+}
@@ -0,0 +1,762 @@
+package mssql
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/bytebase/bytebase/plugin/db"
+	"github.com/bytebase/bytebase/plugin/db/util"
+)
+
+// Dump and restore.
+const (
+	databaseHeaderFmt = "" +
+		"--\n" +
+		"-- SQL Server database structure for %s\n" +
+		"--\n"
+	tableHeaderFmt = "" +
+		"--\n" +
+		"-- Table structure for %s\n" +
+		"--\n"
+	tableDataHeaderFmt = "" +
+		"--\n" +
+		"-- Data for table %s\n" +
+		"--\n"
+	viewHeaderFmt = "" +
+		"--\n" +
+		"-- View structure for %s\n" +
+		"--\n"
+	routineHeaderFmt = "" +
+		"--\n" +
+		"-- %s structure for %s\n" +
+		"--\n"
+
+	// batchSeparator separates T-SQL batches so that statements such as CREATE VIEW and CREATE PROCEDURE
+	// are the first statement in their batch, same as sqlcmd and SSMS.
+	batchSeparator = "GO"
+)
+
+// Dump dumps the database.
+func (driver *Driver) Dump(ctx context.Context, database string, out io.Writer, schemaOnly bool) (string, error) {
+	// Find all dumpable databases
+	var dumpableDbNames []string
+	if database != "" {
+		dumpableDbNames = []string{database}
+	} else {
+		databases, err := driver.getDatabases(ctx)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to get databases")
+		}
+		for _, database := range databases {
+			if database.Name == db.BytebaseDatabase || systemDatabases[database.Name] {
+				continue
+			}
+			dumpableDbNames = append(dumpableDbNames, database.Name)
+		}
+	}
+
+	for _, dbName := range dumpableDbNames {
+		// Database header, CREATE DATABASE and USE statements are only included if dumping all databases.
+		if database == "" {
+			header := fmt.Sprintf(databaseHeaderFmt, dbName)
+			if _, err := io.WriteString(out, header); err != nil {
+				return "", err
+			}
+			if err := writeBatch(out, fmt.Sprintf("CREATE DATABASE %s;", quoteIdentifier(dbName))); err != nil {
+				return "", err
+			}
+			if err := writeBatch(out, fmt.Sprintf("USE %s;", quoteIdentifier(dbName))); err != nil {
+				return "", err
+			}
+		}
+		if err := driver.dumpOneDatabase(ctx, dbName, out, schemaOnly); err != nil {
+			return "", err
+		}
+	}
+
+	return "", nil
+}
+
+// tableDef is the definition of a table used to generate CREATE TABLE statements.
+type tableDef struct {
+	objectID    int64
+	schemaName  string
+	name        string
+	hasIdentity bool
+}
+
+func (driver *Driver) dumpOneDatabase(ctx context.Context, database string, out io.Writer, schemaOnly bool) error {
+	if _, err := driver.GetDBConnection(ctx, database); err != nil {
+		return err
+	}
+	txn, err := driver.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer txn.Rollback()
+
+	schemas, err := getUserSchemas(ctx, txn)
+	if err != nil {
+		return err
+	}
+	for _, schema := range schemas {
+		if err := writeBatch(out, fmt.Sprintf("CREATE SCHEMA %s;", quoteIdentifier(schema))); err != nil {
+			return err
+		}
+	}
+
+	tables, err := getTableDefs(ctx, txn)
+	if err != nil {
+		return err
+	}
+	for _, table := range tables {
+		fullName := fmt.Sprintf("%s.%s", quoteIdentifier(table.schemaName), quoteIdentifier(table.name))
+		if _, err := io.WriteString(out, fmt.Sprintf(tableHeaderFmt, fullName)); err != nil {
+			return err
+		}
+		stmt, err := getCreateTableStmt(ctx, txn, table)
+		if err != nil {
+			return err
+		}
+		if err := writeBatch(out, stmt); err != nil {
+			return err
+		}
+		indexStmts, err := getCreateIndexStmts(ctx, txn, table)
+		if err != nil {
+			return err
+		}
+		for _, stmt := range indexStmts {
+			if err := writeBatch(out, stmt); err != nil {
+				return err
+			}
+		}
+		if !schemaOnly {
+			if err := exportTableData(ctx, txn, table, out); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Foreign keys are dumped after all tables are created.
+	fkStmts, err := getForeignKeyStmts(ctx, txn)
+	if err != nil {
+		return err
+	}
+	for _, stmt := range fkStmts {
+		if err := writeBatch(out, stmt); err != nil {
+			return err
+		}
+	}
+
+	if err := dumpModules(ctx, txn, out); err != nil {
+		return err
+	}
+
+	return txn.Commit()
+}
+
+func getUserSchemas(ctx context.Context, txn *sql.Tx) ([]string, error) {
+	// Skip the built-in schemas such as dbo, guest, sys and the fixed database role schemas.
+	query := `
+		SELECT
+			s.name
+		FROM sys.schemas s
+		WHERE s.schema_id > 4 AND s.schema_id < 16384
+		ORDER BY s.name`
+	rows, err := txn.QueryContext(ctx, query)
+	if err != nil {
+		return nil, util.FormatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	var schemas []string
+	for rows.Next() {
+		var schema string
+		if err := rows.Scan(&schema); err != nil {
+			return nil, err
+		}
+		schemas = append(schemas, schema)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, util.FormatErrorWithQuery(err, query)
+	}
+	return schemas, nil
+}
+
+func getTableDefs(ctx context.Context, txn *sql.Tx) ([]*tableDef, error) {
+	query := `
+		SELECT
+			t.object_id,
+			s.name,
+			t.name,
+			OBJECTPROPERTY(t.object_id, 'TableHasIdentity')
+		FROM sys.tables t
+		JOIN sys.schemas s ON s.schema_id = t.schema_id
+		WHERE t.is_ms_shipped = 0
+		ORDER BY s.name, t.name`
+	rows, err := txn.QueryContext(ctx, query)
+	if err != nil {
+		return nil, util.FormatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	var tables []*tableDef
+	for rows.Next() {
+		var table tableDef
+		if err := rows.Scan(
+			&table.objectID,
+			&table.schemaName,
+			&table.name,
+			&table.hasIdentity,
+		); err != nil {
+			return nil, err
+		}
+		tables = append(tables, &table)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, util.FormatErrorWithQuery(err, query)
+	}
+	return tables, nil
+}
+
+func getCreateTableStmt(ctx context.Context, txn *sql.Tx, table *tableDef) (string, error) {
+	query := `
+		SELECT
+			c.name,
+			TYPE_NAME(c.user_type_id),
+			c.max_length,
+			c.precision,
+			c.scale,
+			c.is_nullable,
+			c.is_identity,
+			ISNULL(CAST(ic.seed_value AS NVARCHAR(64)), ''),
+			ISNULL(CAST(ic.increment_value AS NVARCHAR(64)), ''),
+			ISNULL(cc.definition, ''),
+			ISNULL(dc.name, ''),
+			ISNULL(dc.definition, ''),
+			ISNULL(c.collation_name, '')
+		FROM sys.columns c
+		LEFT JOIN sys.identity_columns ic ON ic.object_id = c.object_id AND ic.column_id = c.column_id
+		LEFT JOIN sys.computed_columns cc ON cc.object_id = c.object_id AND cc.column_id = c.column_id
+		LEFT JOIN sys.default_constraints dc ON dc.parent_object_id = c.object_id AND dc.parent_column_id = c.column_id
+		WHERE c.object_id = @p1
+		ORDER BY c.column_id`
+	rows, err := txn.QueryContext(ctx, query, table.objectID)
+	if err != nil {
+		return "", util.FormatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	var columnDefs []string
+	for rows.Next() {
+		var name, typeName, seed, increment, computed, defaultName, defaultDef, collation string
+		var maxLength, precision, scale int
+		var nullable, identity bool
+		if err := rows.Scan(
+			&name,
+			&typeName,
+			&maxLength,
+			&precision,
+			&scale,
+			&nullable,
+			&identity,
+			&seed,
+			&increment,
+			&computed,
+			&defaultName,
+			&defaultDef,
+			&collation,
+		); err != nil {
+			return "", err
+		}
+		if computed != "" {
+			columnDefs = append(columnDefs, fmt.Sprintf("    %s AS %s", quoteIdentifier(name), computed))
+			continue
+		}
+		def := fmt.Sprintf("    %s %s", quoteIdentifier(name), getColumnTypeDef(typeName, maxLength, precision, scale))
+		if collation != "" {
+			def += " COLLATE " + collation
+		}
+		if identity {
+			def += fmt.Sprintf(" IDENTITY(%s,%s)", seed, increment)
+		}
+		if nullable {
+			def += " NULL"
+		} else {
+			def += " NOT NULL"
+		}
+		if defaultName != "" {
+			def += fmt.Sprintf(" CONSTRAINT %s DEFAULT %s", quoteIdentifier(defaultName), defaultDef)
+		}
+		columnDefs = append(columnDefs, def)
+	}
+	if err := rows.Err(); err != nil {
+		return "", util.FormatErrorWithQuery(err, query)
+	}
+
+	checkQuery := `
+		SELECT
+			name,
+			definition
+		FROM sys.check_constraints
+		WHERE parent_object_id = @p1
+		ORDER BY name`
+	checkRows, err := txn.QueryContext(ctx, checkQuery, table.objectID)
+	if err != nil {
+		return "", util.FormatErrorWithQuery(err, checkQuery)
+	}
+	defer checkRows.Close()
+	for checkRows.Next() {
+		var name, definition string
+		if err := checkRows.Scan(&name, &definition); err != nil {
+			return "", err
+		}
+		columnDefs = append(columnDefs, fmt.Sprintf("    CONSTRAINT %s CHECK %s", quoteIdentifier(name), definition))
+	}
+	if err := checkRows.Err(); err != nil {
+		return "", util.FormatErrorWithQuery(err, checkQuery)
+	}
+
+	return fmt.Sprintf("CREATE TABLE %s.%s (\n%s\n);", quoteIdentifier(table.schemaName), quoteIdentifier(table.name), strings.Join(columnDefs, ",\n")), nil
+}
+
+// getColumnTypeDef returns the column type definition from sys.columns.
+// Note that max_length is in bytes, so the length of nchar and nvarchar is half of it.
+func getColumnTypeDef(typeName string, maxLength, precision, scale int) string {
+	switch strings.ToLower(typeName) {
+	case "char", "varchar", "binary", "varbinary":
+		if maxLength == -1 {
+			return fmt.Sprintf("%s(max)", typeName)
+		}
+		return fmt.Sprintf("%s(%d)", typeName, maxLength)
+	case "nchar", "nvarchar":
+		if maxLength == -1 {
+			return fmt.Sprintf("%s(max)", typeName)
+		}
+		return fmt.Sprintf("%s(%d)", typeName, maxLength/2)
+	case "decimal", "numeric":
+		return fmt.Sprintf("%s(%d,%d)", typeName, precision, scale)
+	case "datetime2", "datetimeoffset", "time":
+		return fmt.Sprintf("%s(%d)", typeName, scale)
+	}
+	return typeName
+}
+
+type indexDef struct {
+	name        string
+	typeDesc    string
+	unique      bool
+	primary     bool
+	constraint  bool
+	filter      string
+	columns     []string
+	includeCols []string
+}
+
+func getCreateIndexStmts(ctx context.Context, txn *sql.Tx, table *tableDef) ([]string, error) {
+	query := `
+		SELECT
+			i.name,
+			i.type_desc,
+			i.is_unique,
+			i.is_primary_key,
+			i.is_unique_constraint,
+			ISNULL(i.filter_definition, ''),
+			c.name,
+			ic.is_descending_key,
+			ic.is_included_column
+		FROM sys.indexes i
+		JOIN sys.index_columns ic ON ic.object_id = i.object_id AND ic.index_id = i.index_id
+		JOIN sys.columns c ON c.object_id = ic.object_id AND c.column_id = ic.column_id
+		WHERE i.object_id = @p1 AND i.name IS NOT NULL
+		ORDER BY i.index_id, ic.key_ordinal, ic.index_column_id`
+	rows, err := txn.QueryContext(ctx, query, table.objectID)
+	if err != nil {
+		return nil, util.FormatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	var indexes []*indexDef
+	indexMap := make(map[string]*indexDef)
+	for rows.Next() {
+		var index indexDef
+		var column string
+		var descending, included bool
+		if err := rows.Scan(
+			&index.name,
+			&index.typeDesc,
+			&index.unique,
+			&index.primary,
+			&index.constraint,
+			&index.filter,
+			&column,
+			&descending,
+			&included,
+		); err != nil {
+			return nil, err
+		}
+		existing, ok := indexMap[index.name]
+		if !ok {
+			existing = &index
+			indexMap[index.name] = existing
+			indexes = append(indexes, existing)
+		}
+		if included {
+			existing.includeCols = append(existing.includeCols, quoteIdentifier(column))
+			continue
+		}
+		column = quoteIdentifier(column)
+		if descending {
+			column += " DESC"
+		}
+		existing.columns = append(existing.columns, column)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, util.FormatErrorWithQuery(err, query)
+	}
+
+	tableName := fmt.Sprintf("%s.%s", quoteIdentifier(table.schemaName), quoteIdentifier(table.name))
+	var stmts []string
+	for _, index := range indexes {
+		switch {
+		case index.primary:
+			stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s PRIMARY KEY %s (%s);", tableName, quoteIdentifier(index.name), index.typeDesc, strings.Join(index.columns, ", ")))
+		case index.constraint:
+			stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s UNIQUE %s (%s);", tableName, quoteIdentifier(index.name), index.typeDesc, strings.Join(index.columns, ", ")))
+		default:
+			var buf strings.Builder
+			buf.WriteString("CREATE ")
+			if index.unique {
+				buf.WriteString("UNIQUE ")
+			}
+			fmt.Fprintf(&buf, "%s INDEX %s ON %s (%s)", index.typeDesc, quoteIdentifier(index.name), tableName, strings.Join(index.columns, ", "))
+			if len(index.includeCols) > 0 {
+				fmt.Fprintf(&buf, " INCLUDE (%s)", strings.Join(index.includeCols, ", "))
+			}
+			if index.filter != "" {
+				fmt.Fprintf(&buf, " WHERE %s", index.filter)
+			}
+			buf.WriteString(";")
+			stmts = append(stmts, buf.String())
+		}
+	}
+	return stmts, nil
+}
+
+func getForeignKeyStmts(ctx context.Context, txn *sql.Tx) ([]string, error) {
+	query := `
+		SELECT
+			fk.name,
+			SCHEMA_NAME(pt.schema_id),
+			pt.name,
+			pc.name,
+			SCHEMA_NAME(rt.schema_id),
+			rt.name,
+			rc.name,
+			fk.delete_referential_action_desc,
+			fk.update_referential_action_desc
+		FROM sys.foreign_keys fk
+		JOIN sys.foreign_key_columns fkc ON fkc.constraint_object_id = fk.object_id
+		JOIN sys.tables pt ON pt.object_id = fk.parent_object_id
+		JOIN sys.columns pc ON pc.object_id = fkc.parent_object_id AND pc.column_id = fkc.parent_column_id
+		JOIN sys.tables rt ON rt.object_id = fk.referenced_object_id
+		JOIN sys.columns rc ON rc.object_id = fkc.referenced_object_id AND rc.column_id = fkc.referenced_column_id
+		ORDER BY fk.name, fkc.constraint_column_id`
+	rows, err := txn.QueryContext(ctx, query)
+	if err != nil {
+		return nil, util.FormatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	type foreignKey struct {
+		name            string
+		table           string
+		columns         []string
+		referencedTable string
+		referencedCols  []string
+		onDelete        string
+		onUpdate        string
+	}
+	var foreignKeys []*foreignKey
+	foreignKeyMap := make(map[string]*foreignKey)
+	for rows.Next() {
+		var name, schemaName, tableName, column, refSchemaName, refTableName, refColumn, onDelete, onUpdate string
+		if err := rows.Scan(
+			&name,
+			&schemaName,
+			&tableName,
+			&column,
+			&refSchemaName,
+			&refTableName,
+			&refColumn,
+			&onDelete,
+			&onUpdate,
+		); err != nil {
+			return nil, err
+		}
+		fk, ok := foreignKeyMap[name]
+		if !ok {
+			fk = &foreignKey{
+				name:            name,
+				table:           fmt.Sprintf("%s.%s", quoteIdentifier(schemaName), quoteIdentifier(tableName)),
+				referencedTable: fmt.Sprintf("%s.%s", quoteIdentifier(refSchemaName), quoteIdentifier(refTableName)),
+				onDelete:        strings.ReplaceAll(onDelete, "_", " "),
+				onUpdate:        strings.ReplaceAll(onUpdate, "_", " "),
+			}
+			foreignKeyMap[name] = fk
+			foreignKeys = append(foreignKeys, fk)
+		}
+		fk.columns = append(fk.columns, quoteIdentifier(column))
+		fk.referencedCols = append(fk.referencedCols, quoteIdentifier(refColumn))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, util.FormatErrorWithQuery(err, query)
+	}
+
+	var stmts []string
+	for _, fk := range foreignKeys {
+		stmts = append(stmts, fmt.Sprintf(
+			"ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s) ON DELETE %s ON UPDATE %s;",
+			fk.table,
+			quoteIdentifier(fk.name),
+			strings.Join(fk.columns, ", "),
+			fk.referencedTable,
+			strings.Join(fk.referencedCols, ", "),
+			fk.onDelete,
+			fk.onUpdate,
+		))
+	}
+	return stmts, nil
+}
+
+// dumpModules dumps the views, functions, procedures and triggers in the order of creation
+// so that dependent modules are created after the modules they depend on.
+func dumpModules(ctx context.Context, txn *sql.Tx, out io.Writer) error {
+	query := `
+		SELECT
+			SCHEMA_NAME(o.schema_id),
+			o.name,
+			o.type,
+			m.definition
+		FROM sys.sql_modules m
+		JOIN sys.objects o ON o.object_id = m.object_id
+		WHERE o.is_ms_shipped = 0 AND o.type IN ('V', 'P', 'FN', 'IF', 'TF', 'TR')
+		ORDER BY o.create_date, o.object_id`
+	rows, err := txn.QueryContext(ctx, query)
+	if err != nil {
+		return util.FormatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var schemaName, name, objectType string
+		var definition sql.NullString
+		if err := rows.Scan(
+			&schemaName,
+			&name,
+			&objectType,
+			&definition,
+		); err != nil {
+			return err
+		}
+		// The definition is NULL for encrypted modules.
+		if !definition.Valid {
+			continue
+		}
+		fullName := fmt.Sprintf("%s.%s", quoteIdentifier(schemaName), quoteIdentifier(name))
+		var header string
+		switch strings.TrimSpace(objectType) {
+		case "V":
+			header = fmt.Sprintf(viewHeaderFmt, fullName)
+		case "P":
+			header = fmt.Sprintf(routineHeaderFmt, "Procedure", fullName)
+		case "TR":
+			header = fmt.Sprintf(routineHeaderFmt, "Trigger", fullName)
+		default:
+			header = fmt.Sprintf(routineHeaderFmt, "Function", fullName)
+		}
+		if _, err := io.WriteString(out, header); err != nil {
+			return err
+		}
+		if err := writeBatch(out, strings.TrimSpace(definition.String)); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return util.FormatErrorWithQuery(err, query)
+	}
+	return nil
+}
+
+// exportTableData dumps the data of a table as INSERT statements.
+func exportTableData(ctx context.Context, txn *sql.Tx, table *tableDef, out io.Writer) error {
+	fullName := fmt.Sprintf("%s.%s", quoteIdentifier(table.schemaName), quoteIdentifier(table.name))
+	query := fmt.Sprintf("SELECT * FROM %s", fullName)
+	rows, err := txn.QueryContext(ctx, query)
+	if err != nil {
+		return util.FormatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	if len(columns) == 0 {
+		return nil
+	}
+	var quotedColumns []string
+	for _, column := range columns {
+		quotedColumns = append(quotedColumns, quoteIdentifier(column))
+	}
+
+	var stmts []string
+	values := make([]interface{}, len(columns))
+	refs := make([]interface{}, len(columns))
+	for i := range columns {
+		refs[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(refs...); err != nil {
+			return err
+		}
+		var tokens []string
+		for _, v := range values {
+			tokens = append(tokens, formatValue(v))
+		}
+		stmts = append(stmts, fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s);", fullName, strings.Join(quotedColumns, ", "), strings.Join(tokens, ", ")))
+	}
+	if err := rows.Err(); err != nil {
+		return util.FormatErrorWithQuery(err, query)
+	}
+	if len(stmts) == 0 {
+		return nil
+	}
+
+	if _, err := io.WriteString(out, fmt.Sprintf(tableDataHeaderFmt, fullName)); err != nil {
+		return err
+	}
+	if table.hasIdentity {
+		stmts = append([]string{fmt.Sprintf("SET IDENTITY_INSERT %s ON;", fullName)}, stmts...)
+		stmts = append(stmts, fmt.Sprintf("SET IDENTITY_INSERT %s OFF;", fullName))
+	}
+	// IDENTITY_INSERT is a session setting, so we keep the inserts of a table in a single batch.
+	return writeBatch(out, strings.Join(stmts, "\n"))
+}
+
+// formatValue formats a scanned value as a T-SQL literal.
+func formatValue(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if value {
+			return "1"
+		}
+		return "0"
+	case []byte:
+		return "0x" + hex.EncodeToString(value)
+	case string:
+		return fmt.Sprintf("N'%s'", strings.ReplaceAll(value, "'", "''"))
+	case time.Time:
+		return fmt.Sprintf("'%s'", value.Format("2006-01-02T15:04:05.9999999Z07:00"))
+	default:
+		return fmt.Sprintf("%v", value)
+	}
+}
+
+// quoteIdentifier quotes the identifier with brackets.
+func quoteIdentifier(identifier string) string {
+	return fmt.Sprintf("[%s]", strings.ReplaceAll(identifier, "]", "]]"))
+}
+
+func writeBatch(out io.Writer, stmt string) error {
+	_, err := io.WriteString(out, fmt.Sprintf("%s\n%s\n\n", stmt, batchSeparator))
+	return err
+}
+
+// Restore restores a database.
+func (driver *Driver) Restore(ctx context.Context, sc io.Reader) error {
+	txn, err := driver.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer txn.Rollback()
+
+	f := func(stmt string) error {
+		if _, err := txn.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	if err := applyMultiStatements(sc, f); err != nil {
+		return err
+	}
+
+	return txn.Commit()
+}
+
+// applyMultiStatements applies the statements split from the reader.
+// If the script uses the "GO" batch separator, each batch will be applied as a whole. Otherwise, the script is split by semicolons.
+func applyMultiStatements(sc io.Reader, f func(string) error) error {
+	content, err := io.ReadAll(sc)
+	if err != nil {
+		return err
+	}
+	var batches []string
+	var batch strings.Builder
+	hasSeparator := false
+	scanner := bufio.NewScanner(strings.NewReader(string(content)))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(content)+1)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.EqualFold(strings.TrimSpace(line), batchSeparator) {
+			hasSeparator = true
+			batches = append(batches, batch.String())
+			batch.Reset()
+			continue
+		}
+		batch.WriteString(line)
+		batch.WriteString("\n")
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if !hasSeparator {
+		return util.ApplyMultiStatements(strings.NewReader(string(content)), f)
+	}
+	batches = append(batches, batch.String())
+
+	for _, batch := range batches {
+		stmt := trimComments(batch)
+		if stmt == "" {
+			continue
+		}
+		if err := f(stmt); err != nil {
+			return errors.Wrapf(err, "execute query %q failed", stmt)
+		}
+	}
+	return nil
+}
+
+// trimComments trims the leading comment lines and the surrounding spaces of a batch.
+func trimComments(batch string) string {
+	lines := strings.Split(batch, "\n")
+	i := 0
+	for ; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line != "" && !strings.HasPrefix(line, "--") {
+			break
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines[i:], "\n"))
+}
@@ -0,0 +1,249 @@
+package mssql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	// embed will embeds the migration schema.
+	_ "embed"
+
+	"go.uber.org/zap"
+
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/common/log"
+	"github.com/bytebase/bytebase/plugin/db"
+	"github.com/bytebase/bytebase/plugin/db/util"
+)
+
+var (
+	//go:embed mssql_migration_schema.sql
+	migrationSchema string
+
+	_ util.MigrationExecutor = (*Driver)(nil)
+)
+
+// NeedsSetupMigration returns whether it needs to setup migration.
+func (driver *Driver) NeedsSetupMigration(ctx context.Context) (bool, error) {
+	// OBJECT_ID() returns NULL instead of an error if the bytebase database doesn't exist.
+	const query = `
+		SELECT
+			1
+		WHERE OBJECT_ID('bytebase.dbo.migration_history', 'U') IS NOT NULL
+	`
+	return util.NeedsSetupMigrationSchema(ctx, driver.db, query)
+}
+
+// SetupMigrationIfNeeded sets up migration if needed.
+func (driver *Driver) SetupMigrationIfNeeded(ctx context.Context) error {
+	setup, err := driver.NeedsSetupMigration(ctx)
+	if err != nil {
+		return err
+	}
+
+	if setup {
+		log.Info("Bytebase migration schema not found, creating schema...",
+			zap.String("environment", driver.connectionCtx.EnvironmentName),
+			zap.String("database", driver.connectionCtx.InstanceName),
+		)
+		if err := driver.Execute(ctx, migrationSchema); err != nil {
+			log.Error("Failed to initialize migration schema.",
+				zap.Error(err),
+				zap.String("environment", driver.connectionCtx.EnvironmentName),
+				zap.String("database", driver.connectionCtx.InstanceName),
+			)
+			return util.FormatErrorWithQuery(err, migrationSchema)
+		}
+		log.Info("Successfully created migration schema.",
+			zap.String("environment", driver.connectionCtx.EnvironmentName),
+			zap.String("database", driver.connectionCtx.InstanceName),
+		)
+	}
+
+	return nil
+}
+
+// FindLargestVersionSinceBaseline will find the largest version since last baseline or branch.
+func (driver Driver) FindLargestVersionSinceBaseline(ctx context.Context, tx *sql.Tx, namespace string) (*string, error) {
+	largestBaselineSequence, err := driver.FindLargestSequence(ctx, tx, namespace, true /* baseline */)
+	if err != nil {
+		return nil, err
+	}
+	const getLargestVersionSinceLastBaselineQuery = `
+		SELECT MAX(version) FROM bytebase.dbo.migration_history
+		WHERE namespace = @p1 AND sequence >= @p2
+	`
+	var version sql.NullString
+	if err := tx.QueryRowContext(ctx, getLargestVersionSinceLastBaselineQuery,
+		namespace, largestBaselineSequence,
+	).Scan(&version); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, common.FormatDBErrorEmptyRowWithQuery(getLargestVersionSinceLastBaselineQuery)
+		}
+		return nil, util.FormatErrorWithQuery(err, getLargestVersionSinceLastBaselineQuery)
+	}
+	if version.Valid {
+		return &version.String, nil
+	}
+	return nil, nil
+}
+
+// FindLargestSequence will return the largest sequence number.
+func (Driver) FindLargestSequence(ctx context.Context, tx *sql.Tx, namespace string, baseline bool) (int, error) {
+	findLargestSequenceQuery := `
+		SELECT MAX(sequence) FROM bytebase.dbo.migration_history
+		WHERE namespace = @p1`
+	if baseline {
+		findLargestSequenceQuery = fmt.Sprintf("%s AND (type = '%s' OR type = '%s')", findLargestSequenceQuery, db.Baseline, db.Branch)
+	}
+	var sequence sql.NullInt32
+	if err := tx.QueryRowContext(ctx, findLargestSequenceQuery,
+		namespace,
+	).Scan(&sequence); err != nil {
+		if err == sql.ErrNoRows {
+			return -1, common.FormatDBErrorEmptyRowWithQuery(findLargestSequenceQuery)
+		}
+		return -1, util.FormatErrorWithQuery(err, findLargestSequenceQuery)
+	}
+	if sequence.Valid {
+		return int(sequence.Int32), nil
+	}
+	// Returns 0 if we haven't applied any migration for this namespace.
+	return 0, nil
+}
+
+// InsertPendingHistory will insert the migration record with pending status and return the inserted ID.
+func (Driver) InsertPendingHistory(ctx context.Context, tx *sql.Tx, sequence int, prevSchema string, m *db.MigrationInfo, storedVersion, statement string) (int64, error) {
+	const insertHistoryQuery = `
+		INSERT INTO bytebase.dbo.migration_history (
+			created_by,
+			created_ts,
+			updated_by,
+			updated_ts,
+			release_version,
+			namespace,
+			sequence,
+			source,
+			type,
+			status,
+			version,
+			description,
+			statement,
+			[schema],
+			schema_prev,
+			execution_duration_ns,
+			issue_id,
+			payload
+		)
+		OUTPUT INSERTED.id
+		VALUES (@p1, DATEDIFF_BIG(SECOND, '1970-01-01', GETUTCDATE()), @p2, DATEDIFF_BIG(SECOND, '1970-01-01', GETUTCDATE()), @p3, @p4, @p5, @p6, @p7, @p8, @p9, @p10, @p11, @p12, @p13, 0, @p14, @p15)
+	`
+	var insertedID int64
+	if err := tx.QueryRowContext(ctx, insertHistoryQuery,
+		m.Creator,
+		m.Creator,
+		m.ReleaseVersion,
+		m.Namespace,
+		sequence,
+		m.Source,
+		m.Type,
+		db.Pending,
+		storedVersion,
+		m.Description,
+		statement,
+		prevSchema,
+		prevSchema,
+		m.IssueID,
+		m.Payload,
+	).Scan(&insertedID); err != nil {
+		return int64(0), util.FormatErrorWithQuery(err, insertHistoryQuery)
+	}
+	return insertedID, nil
+}
+
+// UpdateHistoryAsDone will update the migration record as done.
+func (Driver) UpdateHistoryAsDone(ctx context.Context, tx *sql.Tx, migrationDurationNs int64, updatedSchema string, insertedID int64) error {
+	const updateHistoryAsDoneQuery = `
+		UPDATE
+			bytebase.dbo.migration_history
+		SET
+			status = @p1,
+			execution_duration_ns = @p2,
+			[schema] = @p3,
+			updated_ts = DATEDIFF_BIG(SECOND, '1970-01-01', GETUTCDATE())
+		WHERE id = @p4
+	`
+	_, err := tx.ExecContext(ctx, updateHistoryAsDoneQuery, db.Done, migrationDurationNs, updatedSchema, insertedID)
+	return err
+}
+
+// UpdateHistoryAsFailed will update the migration record as failed.
+func (Driver) UpdateHistoryAsFailed(ctx context.Context, tx *sql.Tx, migrationDurationNs int64, insertedID int64) error {
+	const updateHistoryAsFailedQuery = `
+		UPDATE
+			bytebase.dbo.migration_history
+		SET
+			status = @p1,
+			execution_duration_ns = @p2,
+			updated_ts = DATEDIFF_BIG(SECOND, '1970-01-01', GETUTCDATE())
+		WHERE id = @p3
+	`
+	_, err := tx.ExecContext(ctx, updateHistoryAsFailedQuery, db.Failed, migrationDurationNs, insertedID)
+	return err
+}
+
+// ExecuteMigration will execute the migration.
+func (driver *Driver) ExecuteMigration(ctx context.Context, m *db.MigrationInfo, statement string) (int64, string, error) {
+	return util.ExecuteMigration(ctx, driver, m, statement, db.BytebaseDatabase)
+}
+
+// FindMigrationHistoryList finds the migration history.
+func (driver *Driver) FindMigrationHistoryList(ctx context.Context, find *db.MigrationHistoryFind) ([]*db.MigrationHistory, error) {
+	baseQuery := `
+	SELECT
+		id,
+		created_by,
+		created_ts,
+		updated_by,
+		updated_ts,
+		release_version,
+		namespace,
+		sequence,
+		source,
+		type,
+		status,
+		version,
+		description,
+		statement,
+		[schema],
+		schema_prev,
+		execution_duration_ns,
+		issue_id,
+		payload
+		FROM bytebase.dbo.migration_history `
+	paramNames, params := []string{}, []interface{}{}
+	if v := find.ID; v != nil {
+		paramNames, params = append(paramNames, "id"), append(params, *v)
+	}
+	if v := find.Database; v != nil {
+		paramNames, params = append(paramNames, "namespace"), append(params, *v)
+	}
+	if v := find.Version; v != nil {
+		// TODO(d): support semantic versioning.
+		storedVersion, err := util.ToStoredVersion(false, *v, "")
+		if err != nil {
+			return nil, err
+		}
+		paramNames, params = append(paramNames, "version"), append(params, storedVersion)
+	}
+	if v := find.Source; v != nil {
+		paramNames, params = append(paramNames, "source"), append(params, *v)
+	}
+	var query = baseQuery +
+		db.FormatParamNameInAtSignPosition(paramNames) +
+		`ORDER BY created_ts DESC, id DESC`
+	if v := find.Limit; v != nil {
+		query += fmt.Sprintf(" OFFSET 0 ROWS FETCH NEXT %d ROWS ONLY", *v)
+	}
+	return util.FindMigrationHistoryList(ctx, query, params, driver, db.BytebaseDatabase)
+}
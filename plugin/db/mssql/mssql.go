@@ -0,0 +1,237 @@
+// Package mssql is the plugin for Microsoft SQL Server driver.
+package mssql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	mssqldb "github.com/microsoft/go-mssqldb"
+	"github.com/microsoft/go-mssqldb/msdsn"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/common/log"
+	"github.com/bytebase/bytebase/plugin/db"
+	"github.com/bytebase/bytebase/plugin/db/util"
+)
+
+var (
+	systemDatabases = map[string]bool{
+		"master": true,
+		"model":  true,
+		"msdb":   true,
+		"tempdb": true,
+		// aws
+		"rdsadmin": true,
+	}
+
+	// createDatabaseRegexp matches CREATE DATABASE statements which can't be run inside a transaction in SQL Server.
+	createDatabaseRegexp = regexp.MustCompile(`(?i)^CREATE\s+DATABASE\s+`)
+	// useDatabaseRegexp matches USE statements which switch the current database.
+	useDatabaseRegexp = regexp.MustCompile(`(?i)^USE\s+(\[[^\]]+\]|"[^"]+"|\S+?)\s*;?$`)
+
+	_ db.Driver = (*Driver)(nil)
+)
+
+func init() {
+	db.Register(db.MSSQL, newDriver)
+}
+
+// Driver is the Microsoft SQL Server driver.
+type Driver struct {
+	connectionCtx db.ConnectionContext
+	connCfg       msdsn.Config
+
+	db           *sql.DB
+	databaseName string
+}
+
+func newDriver(db.DriverConfig) db.Driver {
+	return &Driver{}
+}
+
+// Open opens a Microsoft SQL Server driver.
+func (driver *Driver) Open(_ context.Context, _ db.Type, config db.ConnectionConfig, connCtx db.ConnectionContext) (db.Driver, error) {
+	port := config.Port
+	if port == "" {
+		port = "1433"
+	}
+	query := url.Values{}
+	query.Add("app name", "bytebase")
+	if config.Database != "" {
+		query.Add("database", config.Database)
+	}
+	u := &url.URL{
+		Scheme:   "sqlserver",
+		User:     url.UserPassword(config.Username, config.Password),
+		Host:     fmt.Sprintf("%s:%s", config.Host, port),
+		RawQuery: query.Encode(),
+	}
+	connCfg, _, err := msdsn.Parse(u.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse SQL Server connection string")
+	}
+	// Set SSL configuration.
+	tlsConfig, err := config.TLSConfig.GetSslConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "sql: tls config error")
+	}
+	if tlsConfig != nil {
+		connCfg.Encryption = msdsn.EncryptionRequired
+		connCfg.TLSConfig = tlsConfig
+	}
+
+	log.Debug("Opening SQL Server driver",
+		zap.String("host", u.Host),
+		zap.String("environment", connCtx.EnvironmentName),
+		zap.String("database", connCtx.InstanceName),
+	)
+
+	driver.connectionCtx = connCtx
+	driver.connCfg = connCfg
+	driver.databaseName = config.Database
+	driver.db = sql.OpenDB(mssqldb.NewConnectorConfig(connCfg))
+	return driver, nil
+}
+
+// Close closes the driver.
+func (driver *Driver) Close(context.Context) error {
+	return driver.db.Close()
+}
+
+// Ping pings the database.
+func (driver *Driver) Ping(ctx context.Context) error {
+	return driver.db.PingContext(ctx)
+}
+
+// GetDBConnection gets a database connection.
+func (driver *Driver) GetDBConnection(_ context.Context, database string) (*sql.DB, error) {
+	if err := driver.switchDatabase(database); err != nil {
+		return nil, err
+	}
+	return driver.db, nil
+}
+
+func (driver *Driver) switchDatabase(database string) error {
+	if driver.db != nil && driver.databaseName == database {
+		return nil
+	}
+	if driver.db != nil {
+		if err := driver.db.Close(); err != nil {
+			return err
+		}
+	}
+	connCfg := driver.connCfg
+	connCfg.Database = database
+	driver.db = sql.OpenDB(mssqldb.NewConnectorConfig(connCfg))
+	driver.databaseName = database
+	return nil
+}
+
+// getVersion gets the version.
+func (driver *Driver) getVersion(ctx context.Context) (string, error) {
+	query := "SELECT CAST(SERVERPROPERTY('ProductVersion') AS NVARCHAR(128))"
+	var version string
+	if err := driver.db.QueryRowContext(ctx, query).Scan(&version); err != nil {
+		if err == sql.ErrNoRows {
+			return "", common.FormatDBErrorEmptyRowWithQuery(query)
+		}
+		return "", util.FormatErrorWithQuery(err, query)
+	}
+	return version, nil
+}
+
+// getDatabases gets all databases of an instance.
+func (driver *Driver) getDatabases(ctx context.Context) ([]db.DatabaseMeta, error) {
+	query := `
+		SELECT
+			name,
+			ISNULL(collation_name, '')
+		FROM sys.databases`
+	rows, err := driver.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, util.FormatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	var databaseList []db.DatabaseMeta
+	for rows.Next() {
+		var database db.DatabaseMeta
+		if err := rows.Scan(
+			&database.Name,
+			&database.Collation,
+		); err != nil {
+			return nil, err
+		}
+		databaseList = append(databaseList, database)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, util.FormatErrorWithQuery(err, query)
+	}
+	return databaseList, nil
+}
+
+// Execute executes a SQL statement.
+func (driver *Driver) Execute(ctx context.Context, statement string) error {
+	var remainingStmts []string
+	f := func(stmt string) error {
+		stmt = strings.TrimSpace(stmt)
+		switch {
+		case createDatabaseRegexp.MatchString(stmt):
+			// CREATE DATABASE statement is not allowed within multi-statement transaction in SQL Server.
+			if _, err := driver.db.ExecContext(ctx, stmt); err != nil {
+				return err
+			}
+		case useDatabaseRegexp.MatchString(stmt):
+			// Flush the statements for the current database before switching to another one.
+			if err := driver.executeInTransaction(ctx, remainingStmts); err != nil {
+				return err
+			}
+			remainingStmts = nil
+			database := useDatabaseRegexp.FindStringSubmatch(stmt)[1]
+			database = strings.Trim(database, `[]"`)
+			if _, err := driver.GetDBConnection(ctx, database); err != nil {
+				return err
+			}
+		default:
+			remainingStmts = append(remainingStmts, stmt)
+		}
+		return nil
+	}
+
+	if err := applyMultiStatements(strings.NewReader(statement), f); err != nil {
+		return err
+	}
+
+	return driver.executeInTransaction(ctx, remainingStmts)
+}
+
+func (driver *Driver) executeInTransaction(ctx context.Context, stmts []string) error {
+	if len(stmts) == 0 {
+		return nil
+	}
+	tx, err := driver.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Execute statements one by one because some statements such as CREATE VIEW must be the first statement in a T-SQL batch.
+	for _, stmt := range stmts {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Query queries a SQL statement.
+func (driver *Driver) Query(ctx context.Context, statement string, limit int) ([]interface{}, error) {
+	return util.Query(ctx, db.MSSQL, driver.db, statement, limit)
+}
@@ -0,0 +1,51 @@
+-- This is the bytebase schema to track migration info for SQL Server
+-- Create a database called bytebase
+CREATE DATABASE bytebase;
+
+-- Create migration_history table
+-- Note, we use NVARCHAR(256) instead of NVARCHAR(MAX) for the indexed columns because SQL Server can't index NVARCHAR(MAX) columns.
+CREATE TABLE bytebase.dbo.migration_history (
+    id BIGINT IDENTITY(1,1) PRIMARY KEY,
+    created_by NVARCHAR(MAX) NOT NULL,
+    created_ts BIGINT NOT NULL,
+    updated_by NVARCHAR(MAX) NOT NULL,
+    updated_ts BIGINT NOT NULL,
+    -- Record the client version creating this migration history. For Bytebase, we use its binary release version. Different Bytebase release might
+    -- record different history info and this field helps to handle such situation properly. Moreover, it helps debugging.
+    release_version NVARCHAR(MAX) NOT NULL,
+    -- Allows granular tracking of migration history (e.g If an application manages schemas for a multi-tenant service and each tenant has its own schema, that application can use namespace to record the tenant name to track the per-tenant schema migration)
+    -- Since bytebase also manages different application databases from an instance, it leverages this field to track each database migration history.
+    namespace NVARCHAR(256) NOT NULL,
+    -- Used to detect out of order migration together with 'namespace' and 'version' column.
+    sequence BIGINT NOT NULL CHECK (sequence >= 0),
+    -- We call it source because maybe we could load history from other migration tool.
+    -- Current allowed values are UI, VCS, LIBRARY.
+    source NVARCHAR(256) NOT NULL,
+    -- Current allowed values are BASELINE, MIGRATE, MIGRATE_SDL, BRANCH, DATA.
+    type NVARCHAR(256) NOT NULL,
+    -- Current allowed values are PENDING, DONE, FAILED.
+    -- SQL Server can't do cross database transaction, so we can't record DDL and migration_history into a single transaction.
+    -- Thus, we create a "PENDING" record before applying the DDL and update that record to "DONE" after applying the DDL.
+    status NVARCHAR(256) NOT NULL,
+    -- Record the migration version.
+    version NVARCHAR(256) NOT NULL,
+    description NVARCHAR(MAX) NOT NULL,
+    -- Record the migration statement
+    statement NVARCHAR(MAX) NOT NULL,
+    -- Record the schema after migration
+    [schema] NVARCHAR(MAX) NOT NULL,
+    -- Record the schema before migration. Though we could also fetch it from the previous migration history, it would complicate fetching logic.
+    -- Besides, by storing the schema_prev, we can perform consistency check to see if the migration history has any gaps.
+    schema_prev NVARCHAR(MAX) NOT NULL,
+    execution_duration_ns BIGINT NOT NULL,
+    issue_id NVARCHAR(MAX) NOT NULL,
+    payload NVARCHAR(MAX) NOT NULL
+);
+
+CREATE UNIQUE INDEX bytebase_idx_unique_migration_history_namespace_sequence ON bytebase.dbo.migration_history (namespace, sequence);
+
+CREATE UNIQUE INDEX bytebase_idx_unique_migration_history_namespace_version ON bytebase.dbo.migration_history (namespace, version);
+
+CREATE INDEX bytebase_idx_migration_history_namespace_source_type ON bytebase.dbo.migration_history (namespace, source, type);
+
+CREATE INDEX bytebase_idx_migration_history_namespace_created ON bytebase.dbo.migration_history (namespace, created_ts);
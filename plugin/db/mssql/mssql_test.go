@@ -0,0 +1,83 @@
+package mssql
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyMultiStatements(t *testing.T) {
+	tests := []struct {
+		statement string
+		want      []string
+	}{
+		{
+			statement: "CREATE TABLE t1 (id INT);\nINSERT INTO t1 VALUES (1);\n",
+			want: []string{
+				"CREATE TABLE t1 (id INT);",
+				"INSERT INTO t1 VALUES (1);",
+			},
+		},
+		{
+			statement: "--\n-- Table structure for [dbo].[t1]\n--\nCREATE TABLE [dbo].[t1] (\n    [id] int NOT NULL\n);\nGO\n\n" +
+				"CREATE VIEW [dbo].[v1] AS SELECT id FROM t1;\ngo\n\n" +
+				"CREATE PROCEDURE p1 AS\nBEGIN\n  SELECT 1;\n  SELECT 2;\nEND\nGO\n",
+			want: []string{
+				"CREATE TABLE [dbo].[t1] (\n    [id] int NOT NULL\n);",
+				"CREATE VIEW [dbo].[v1] AS SELECT id FROM t1;",
+				"CREATE PROCEDURE p1 AS\nBEGIN\n  SELECT 1;\n  SELECT 2;\nEND",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		var got []string
+		err := applyMultiStatements(strings.NewReader(test.statement), func(stmt string) error {
+			got = append(got, stmt)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, test.want, got)
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{nil, "NULL"},
+		{true, "1"},
+		{int64(42), "42"},
+		{3.5, "3.5"},
+		{"it's", "N'it''s'"},
+		{[]byte{0xde, 0xad}, "0xdead"},
+		{time.Date(2022, 10, 1, 12, 30, 0, 0, time.UTC), "'2022-10-01T12:30:00Z'"},
+	}
+
+	for _, test := range tests {
+		require.Equal(t, test.want, formatValue(test.value))
+	}
+}
+
+func TestGetColumnTypeDef(t *testing.T) {
+	tests := []struct {
+		typeName  string
+		maxLength int
+		precision int
+		scale     int
+		want      string
+	}{
+		{"int", 4, 10, 0, "int"},
+		{"nvarchar", 200, 0, 0, "nvarchar(100)"},
+		{"varchar", -1, 0, 0, "varchar(max)"},
+		{"decimal", 9, 10, 2, "decimal(10,2)"},
+		{"datetime2", 8, 27, 7, "datetime2(7)"},
+	}
+
+	for _, test := range tests {
+		require.Equal(t, test.want, getColumnTypeDef(test.typeName, test.maxLength, test.precision, test.scale))
+	}
+}
@@ -0,0 +1,389 @@
+package mssql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/plugin/db"
+	"github.com/bytebase/bytebase/plugin/db/util"
+)
+
+// SyncInstance syncs the instance.
+func (driver *Driver) SyncInstance(ctx context.Context) (*db.InstanceMeta, error) {
+	version, err := driver.getVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Query user info
+	userList, err := driver.getUserList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Query db info
+	databases, err := driver.getDatabases(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var databaseList []db.DatabaseMeta
+	for _, database := range databases {
+		// Skip our internal "bytebase" database
+		if database.Name == db.BytebaseDatabase {
+			continue
+		}
+		// Skip all system databases
+		if systemDatabases[database.Name] {
+			continue
+		}
+		databaseList = append(databaseList, database)
+	}
+
+	return &db.InstanceMeta{
+		Version:      version,
+		UserList:     userList,
+		DatabaseList: databaseList,
+	}, nil
+}
+
+// SyncDBSchema syncs a single database schema.
+func (driver *Driver) SyncDBSchema(ctx context.Context, databaseName string) (*db.Schema, error) {
+	databases, err := driver.getDatabases(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := db.Schema{
+		Name: databaseName,
+	}
+	found := false
+	for _, database := range databases {
+		if database.Name == databaseName {
+			found = true
+			schema.Collation = database.Collation
+			break
+		}
+	}
+	if !found {
+		return nil, common.Errorf(common.NotFound, "database %q not found", databaseName)
+	}
+
+	if _, err := driver.GetDBConnection(ctx, databaseName); err != nil {
+		return nil, err
+	}
+	txn, err := driver.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer txn.Rollback()
+
+	tableList, err := getTables(ctx, txn)
+	if err != nil {
+		return nil, err
+	}
+	viewList, err := getViews(ctx, txn)
+	if err != nil {
+		return nil, err
+	}
+	if err := txn.Commit(); err != nil {
+		return nil, err
+	}
+	schema.TableList, schema.ViewList = tableList, viewList
+
+	return &schema, nil
+}
+
+func (driver *Driver) getUserList(ctx context.Context) ([]db.User, error) {
+	// Server roles are granted to logins, e.g. sysadmin.
+	query := `
+		SELECT
+			p.name,
+			ISNULL(STRING_AGG(r.name, ', '), '')
+		FROM sys.server_principals p
+		LEFT JOIN sys.server_role_members m ON m.member_principal_id = p.principal_id
+		LEFT JOIN sys.server_principals r ON r.principal_id = m.role_principal_id
+		WHERE p.type IN ('S', 'U', 'G', 'E', 'X') AND p.name NOT LIKE '##%'
+		GROUP BY p.name`
+	rows, err := driver.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, util.FormatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	var userList []db.User
+	for rows.Next() {
+		var user db.User
+		if err := rows.Scan(
+			&user.Name,
+			&user.Grant,
+		); err != nil {
+			return nil, err
+		}
+		userList = append(userList, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, util.FormatErrorWithQuery(err, query)
+	}
+	return userList, nil
+}
+
+// getTables gets all tables of the current database.
+func getTables(ctx context.Context, txn *sql.Tx) ([]db.Table, error) {
+	columnMap, err := getTableColumns(ctx, txn)
+	if err != nil {
+		return nil, err
+	}
+	indexMap, err := getIndexes(ctx, txn)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT
+			s.name,
+			t.name,
+			DATEDIFF_BIG(SECOND, '1970-01-01', t.create_date),
+			DATEDIFF_BIG(SECOND, '1970-01-01', t.modify_date),
+			ISNULL((SELECT SUM(p.rows) FROM sys.partitions p WHERE p.object_id = t.object_id AND p.index_id IN (0, 1)), 0),
+			ISNULL((
+				SELECT SUM(a.used_pages) * 8 * 1024 FROM sys.partitions p
+				JOIN sys.allocation_units a ON a.container_id = p.partition_id
+				WHERE p.object_id = t.object_id AND p.index_id IN (0, 1)
+			), 0),
+			ISNULL((
+				SELECT SUM(a.used_pages) * 8 * 1024 FROM sys.partitions p
+				JOIN sys.allocation_units a ON a.container_id = p.partition_id
+				WHERE p.object_id = t.object_id AND p.index_id > 1
+			), 0),
+			ISNULL(CAST(ep.value AS NVARCHAR(MAX)), '')
+		FROM sys.tables t
+		JOIN sys.schemas s ON s.schema_id = t.schema_id
+		LEFT JOIN sys.extended_properties ep ON ep.major_id = t.object_id AND ep.minor_id = 0 AND ep.class = 1 AND ep.name = 'MS_Description'
+		WHERE t.is_ms_shipped = 0
+		ORDER BY s.name, t.name`
+	rows, err := txn.QueryContext(ctx, query)
+	if err != nil {
+		return nil, util.FormatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	var tables []db.Table
+	for rows.Next() {
+		var schemaName, tableName string
+		table := db.Table{
+			Type: "BASE TABLE",
+		}
+		if err := rows.Scan(
+			&schemaName,
+			&tableName,
+			&table.CreatedTs,
+			&table.UpdatedTs,
+			&table.RowCount,
+			&table.DataSize,
+			&table.IndexSize,
+			&table.Comment,
+		); err != nil {
+			return nil, err
+		}
+		table.Name = fmt.Sprintf("%s.%s", schemaName, tableName)
+		table.ColumnList = columnMap[table.Name]
+		table.IndexList = indexMap[table.Name]
+		tables = append(tables, table)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, util.FormatErrorWithQuery(err, query)
+	}
+	return tables, nil
+}
+
+// getTableColumns gets the columns of all tables keyed by "schema.table".
+func getTableColumns(ctx context.Context, txn *sql.Tx) (map[string][]db.Column, error) {
+	query := `
+		SELECT
+			c.TABLE_SCHEMA,
+			c.TABLE_NAME,
+			c.COLUMN_NAME,
+			c.ORDINAL_POSITION,
+			c.COLUMN_DEFAULT,
+			c.IS_NULLABLE,
+			c.DATA_TYPE,
+			c.CHARACTER_MAXIMUM_LENGTH,
+			c.NUMERIC_PRECISION,
+			c.NUMERIC_SCALE,
+			ISNULL(c.CHARACTER_SET_NAME, ''),
+			ISNULL(c.COLLATION_NAME, ''),
+			ISNULL(CAST(ep.value AS NVARCHAR(MAX)), '')
+		FROM INFORMATION_SCHEMA.COLUMNS c
+		LEFT JOIN sys.extended_properties ep
+			ON ep.major_id = OBJECT_ID(QUOTENAME(c.TABLE_SCHEMA) + '.' + QUOTENAME(c.TABLE_NAME))
+			AND ep.minor_id = COLUMNPROPERTY(ep.major_id, c.COLUMN_NAME, 'ColumnId')
+			AND ep.class = 1
+			AND ep.name = 'MS_Description'
+		ORDER BY c.TABLE_SCHEMA, c.TABLE_NAME, c.ORDINAL_POSITION`
+	rows, err := txn.QueryContext(ctx, query)
+	if err != nil {
+		return nil, util.FormatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	// schemaName.tableName -> columnList map
+	columnMap := make(map[string][]db.Column)
+	for rows.Next() {
+		var schemaName, tableName, nullable, dataType string
+		var defaultStr sql.NullString
+		var maxLength, precision, scale sql.NullInt64
+		var column db.Column
+		if err := rows.Scan(
+			&schemaName,
+			&tableName,
+			&column.Name,
+			&column.Position,
+			&defaultStr,
+			&nullable,
+			&dataType,
+			&maxLength,
+			&precision,
+			&scale,
+			&column.CharacterSet,
+			&column.Collation,
+			&column.Comment,
+		); err != nil {
+			return nil, err
+		}
+		if defaultStr.Valid {
+			column.Default = &defaultStr.String
+		}
+		column.Nullable = nullable == "YES"
+		column.Type = getColumnType(dataType, maxLength, precision, scale)
+
+		key := fmt.Sprintf("%s.%s", schemaName, tableName)
+		columnMap[key] = append(columnMap[key], column)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, util.FormatErrorWithQuery(err, query)
+	}
+	return columnMap, nil
+}
+
+// getColumnType returns the full column type such as "nvarchar(255)" or "decimal(10,2)".
+func getColumnType(dataType string, maxLength, precision, scale sql.NullInt64) string {
+	switch strings.ToLower(dataType) {
+	case "char", "varchar", "nchar", "nvarchar", "binary", "varbinary":
+		if !maxLength.Valid {
+			return dataType
+		}
+		if maxLength.Int64 == -1 {
+			return fmt.Sprintf("%s(max)", dataType)
+		}
+		return fmt.Sprintf("%s(%d)", dataType, maxLength.Int64)
+	case "decimal", "numeric":
+		if precision.Valid && scale.Valid {
+			return fmt.Sprintf("%s(%d,%d)", dataType, precision.Int64, scale.Int64)
+		}
+	}
+	return dataType
+}
+
+// getIndexes gets the indexes of all tables keyed by "schema.table".
+func getIndexes(ctx context.Context, txn *sql.Tx) (map[string][]db.Index, error) {
+	query := `
+		SELECT
+			s.name,
+			t.name,
+			i.name,
+			c.name,
+			ic.key_ordinal,
+			i.type_desc,
+			i.is_unique,
+			i.is_primary_key,
+			i.is_disabled
+		FROM sys.indexes i
+		JOIN sys.tables t ON t.object_id = i.object_id
+		JOIN sys.schemas s ON s.schema_id = t.schema_id
+		JOIN sys.index_columns ic ON ic.object_id = i.object_id AND ic.index_id = i.index_id
+		JOIN sys.columns c ON c.object_id = ic.object_id AND c.column_id = ic.column_id
+		WHERE t.is_ms_shipped = 0 AND i.name IS NOT NULL AND ic.is_included_column = 0
+		ORDER BY s.name, t.name, i.name, ic.key_ordinal`
+	rows, err := txn.QueryContext(ctx, query)
+	if err != nil {
+		return nil, util.FormatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	// schemaName.tableName -> indexList map
+	indexMap := make(map[string][]db.Index)
+	for rows.Next() {
+		var schemaName, tableName string
+		var disabled bool
+		var index db.Index
+		if err := rows.Scan(
+			&schemaName,
+			&tableName,
+			&index.Name,
+			&index.Expression,
+			&index.Position,
+			&index.Type,
+			&index.Unique,
+			&index.Primary,
+			&disabled,
+		); err != nil {
+			return nil, err
+		}
+		index.Visible = !disabled
+
+		key := fmt.Sprintf("%s.%s", schemaName, tableName)
+		indexMap[key] = append(indexMap[key], index)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, util.FormatErrorWithQuery(err, query)
+	}
+	return indexMap, nil
+}
+
+// getViews gets all views of the current database.
+func getViews(ctx context.Context, txn *sql.Tx) ([]db.View, error) {
+	query := `
+		SELECT
+			s.name,
+			v.name,
+			DATEDIFF_BIG(SECOND, '1970-01-01', v.create_date),
+			DATEDIFF_BIG(SECOND, '1970-01-01', v.modify_date),
+			ISNULL(OBJECT_DEFINITION(v.object_id), ''),
+			ISNULL(CAST(ep.value AS NVARCHAR(MAX)), '')
+		FROM sys.views v
+		JOIN sys.schemas s ON s.schema_id = v.schema_id
+		LEFT JOIN sys.extended_properties ep ON ep.major_id = v.object_id AND ep.minor_id = 0 AND ep.class = 1 AND ep.name = 'MS_Description'
+		WHERE v.is_ms_shipped = 0
+		ORDER BY s.name, v.name`
+	rows, err := txn.QueryContext(ctx, query)
+	if err != nil {
+		return nil, util.FormatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	var views []db.View
+	for rows.Next() {
+		var schemaName, viewName string
+		var view db.View
+		if err := rows.Scan(
+			&schemaName,
+			&viewName,
+			&view.CreatedTs,
+			&view.UpdatedTs,
+			&view.Definition,
+			&view.Comment,
+		); err != nil {
+			return nil, err
+		}
+		view.Name = fmt.Sprintf("%s.%s", schemaName, viewName)
+		views = append(views, view)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, util.FormatErrorWithQuery(err, query)
+	}
+	return views, nil
+}
@@ -0,0 +1,159 @@
+package mysql
+
+// This file implements the dry run of migrations.
+// DDL statements cause implicit commits in MySQL, so we cannot roll them back in a transaction. Instead, we clone
+// the schema of the database into a scratch database with Dump(schemaOnly=true), run the statements one by one
+// against the scratch database on a dedicated connection, and drop the scratch database afterwards.
+// The statements referring to other databases explicitly are skipped, so that the dry run never touches them.
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	tidbparser "github.com/pingcap/tidb/parser"
+	tidbast "github.com/pingcap/tidb/parser/ast"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/bytebase/bytebase/common/log"
+	"github.com/bytebase/bytebase/plugin/db"
+	"github.com/bytebase/bytebase/plugin/db/util"
+	"github.com/bytebase/bytebase/plugin/parser"
+)
+
+var (
+	_ db.DryRunExecutor = (*Driver)(nil)
+)
+
+// DryRun runs the statement against a scratch database with the same schema as the connected database.
+func (driver *Driver) DryRun(ctx context.Context, statement string, option db.DryRunOption) (*db.DryRunResult, error) {
+	database := driver.connCfg.Database
+	if database == "" {
+		return nil, errors.Errorf("dry run requires a connected database")
+	}
+	singleSQLs, err := parser.SplitMultiSQL(parser.MySQL, statement)
+	if err != nil {
+		return nil, err
+	}
+
+	var schema bytes.Buffer
+	if _, err := driver.Dump(ctx, database, &schema, true /* schemaOnly */); err != nil {
+		return nil, errors.Wrapf(err, "failed to dump the schema of database %q", database)
+	}
+
+	dryRunDatabaseName := util.GetDryRunDatabaseName(database, time.Now().Unix())
+	if _, err := driver.db.ExecContext(ctx, fmt.Sprintf("DROP DATABASE IF EXISTS `%s`; CREATE DATABASE `%s`;", dryRunDatabaseName, dryRunDatabaseName)); err != nil {
+		return nil, errors.Wrapf(err, "failed to create the dry run database %q", dryRunDatabaseName)
+	}
+	defer func() {
+		// Use a new context because the dry run database should be dropped even if the context is canceled.
+		if _, err := driver.db.ExecContext(context.Background(), fmt.Sprintf("DROP DATABASE IF EXISTS `%s`", dryRunDatabaseName)); err != nil {
+			log.Error("failed to drop the dry run database", zap.String("database", dryRunDatabaseName), zap.Error(err))
+		}
+	}()
+	if err := driver.restoreImpl(ctx, &schema, dryRunDatabaseName); err != nil {
+		return nil, errors.Wrapf(err, "failed to clone the schema of database %q to the dry run database %q", database, dryRunDatabaseName)
+	}
+
+	// The timeout bounds running the statements, but not cloning the schema.
+	if option.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, option.Timeout)
+		defer cancel()
+	}
+	conn, err := driver.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	setupStmts := []string{fmt.Sprintf("USE `%s`", dryRunDatabaseName)}
+	if option.LockTimeout > 0 {
+		// lock_wait_timeout is in seconds and at least 1.
+		setupStmts = append(setupStmts, fmt.Sprintf("SET SESSION lock_wait_timeout = %d", int64(math.Max(1, math.Ceil(option.LockTimeout.Seconds())))))
+	}
+	if option.StatementTimeout > 0 {
+		// max_execution_time only applies to read-only SELECT statements, the others are bounded by the context below.
+		setupStmts = append(setupStmts, fmt.Sprintf("SET SESSION max_execution_time = %d", option.StatementTimeout.Milliseconds()))
+	}
+	for _, stmt := range setupStmts {
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &db.DryRunResult{}
+	start := time.Now()
+	for _, singleSQL := range singleSQLs {
+		stmt := strings.TrimSpace(singleSQL.Text)
+		if skipInDryRun(stmt) {
+			result.SkippedStatementList = append(result.SkippedStatementList, stmt)
+			continue
+		}
+		if err := execDryRunStatement(ctx, conn, stmt, option.StatementTimeout); err != nil {
+			result.Statement = stmt
+			result.Line = singleSQL.LastLine
+			result.Error = err.Error()
+			break
+		}
+	}
+	result.Duration = time.Since(start)
+	return result, nil
+}
+
+// execDryRunStatement executes the statement on the connection, and cancels it if it runs longer than the timeout.
+func execDryRunStatement(ctx context.Context, conn *sql.Conn, stmt string, timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	_, err := conn.ExecContext(ctx, stmt)
+	return err
+}
+
+// skipInDryRun returns true if the statement may change anything other than the dry run database,
+// i.e. the statement refers to a database explicitly or it's not a statement on the database objects.
+func skipInDryRun(stmt string) bool {
+	nodes, _, err := tidbparser.New().Parse(stmt, "", "")
+	if err != nil {
+		// We cannot tell the objects referred by the statement, so skip it for safety.
+		return true
+	}
+	for _, node := range nodes {
+		switch node.(type) {
+		case *tidbast.UseStmt, *tidbast.CreateDatabaseStmt, *tidbast.DropDatabaseStmt, *tidbast.AlterDatabaseStmt,
+			*tidbast.GrantStmt, *tidbast.RevokeStmt, *tidbast.CreateUserStmt, *tidbast.AlterUserStmt, *tidbast.DropUserStmt,
+			*tidbast.SetStmt, *tidbast.BeginStmt, *tidbast.CommitStmt, *tidbast.RollbackStmt:
+			return true
+		}
+		visitor := &qualifiedTableVisitor{}
+		node.Accept(visitor)
+		if visitor.qualified {
+			return true
+		}
+	}
+	return false
+}
+
+// qualifiedTableVisitor finds the table names qualified with a database name.
+type qualifiedTableVisitor struct {
+	qualified bool
+}
+
+// Enter implements the ast.Visitor interface.
+func (v *qualifiedTableVisitor) Enter(in tidbast.Node) (tidbast.Node, bool) {
+	if table, ok := in.(*tidbast.TableName); ok && table.Schema.O != "" {
+		v.qualified = true
+	}
+	return in, v.qualified
+}
+
+// Leave implements the ast.Visitor interface.
+func (*qualifiedTableVisitor) Leave(in tidbast.Node) (tidbast.Node, bool) {
+	return in, true
+}
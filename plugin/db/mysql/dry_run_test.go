@@ -0,0 +1,29 @@
+package mysql
+
+import (
+	"testing"
+
+	// Register pingcap parser driver.
+	_ "github.com/pingcap/tidb/types/parser_driver"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSkipInDryRun(t *testing.T) {
+	tests := []struct {
+		stmt string
+		want bool
+	}{
+		{stmt: "ALTER TABLE t ADD COLUMN a int;", want: false},
+		{stmt: "CREATE TABLE t2 LIKE t;", want: false},
+		{stmt: "INSERT INTO t SELECT * FROM t2;", want: false},
+		{stmt: "ALTER TABLE db.t ADD COLUMN a int;", want: true},
+		{stmt: "INSERT INTO t SELECT * FROM db.t2;", want: true},
+		{stmt: "USE db;", want: true},
+		{stmt: "DROP DATABASE db;", want: true},
+		{stmt: "SET GLOBAL max_connections = 1;", want: true},
+	}
+
+	for _, test := range tests {
+		require.Equal(t, test.want, skipInDryRun(test.stmt), test.stmt)
+	}
+}
@@ -32,7 +32,7 @@ import (
 	"github.com/bytebase/bytebase/common"
 	"github.com/bytebase/bytebase/common/log"
 	"github.com/bytebase/bytebase/plugin/db/util"
-	bbs3 "github.com/bytebase/bytebase/plugin/storage/s3"
+	"github.com/bytebase/bytebase/plugin/storage"
 	"github.com/bytebase/bytebase/resources/mysqlutil"
 
 	"github.com/blang/semver/v4"
@@ -335,7 +335,7 @@ func sortBinlogFiles(binlogFiles []BinlogFile) []BinlogFile {
 
 // GetLatestBackupBeforeOrEqualTs finds the latest logical backup and corresponding binlog info whose time is before or equal to `targetTs`.
 // The backupList should only contain DONE backups.
-func (driver *Driver) GetLatestBackupBeforeOrEqualTs(ctx context.Context, backupList []*api.Backup, targetTs int64, client *bbs3.Client) (*api.Backup, *api.BinlogInfo, error) {
+func (driver *Driver) GetLatestBackupBeforeOrEqualTs(ctx context.Context, backupList []*api.Backup, targetTs int64, client storage.Client) (*api.Backup, *api.BinlogInfo, error) {
 	if len(backupList) == 0 {
 		return nil, nil, errors.Errorf("no valid backup")
 	}
@@ -562,7 +562,7 @@ func binlogMetaAreContinuous(files []binlogFileMeta) bool {
 }
 
 // Download binlog files on server.
-func (driver *Driver) downloadBinlogFilesOnServer(ctx context.Context, metaList []binlogFileMeta, binlogFilesOnServerSorted []BinlogFile, downloadLatestBinlogFile bool, uploader *bbs3.Client) error {
+func (driver *Driver) downloadBinlogFilesOnServer(ctx context.Context, metaList []binlogFileMeta, binlogFilesOnServerSorted []BinlogFile, downloadLatestBinlogFile bool, uploader storage.Client) error {
 	if len(binlogFilesOnServerSorted) == 0 {
 		log.Debug("No binlog file found on server to download")
 		return nil
@@ -604,7 +604,7 @@ func (driver *Driver) GetBinlogDir() string {
 }
 
 // FetchAllBinlogFiles downloads all binlog files on server to `binlogDir`.
-func (driver *Driver) FetchAllBinlogFiles(ctx context.Context, downloadLatestBinlogFile bool, client *bbs3.Client) error {
+func (driver *Driver) FetchAllBinlogFiles(ctx context.Context, downloadLatestBinlogFile bool, client storage.Client) error {
 	if err := os.MkdirAll(driver.binlogDir, os.ModePerm); err != nil {
 		return errors.Wrapf(err, "failed to create binlog directory %q", driver.binlogDir)
 	}
@@ -636,7 +636,7 @@ func (driver *Driver) FetchAllBinlogFiles(ctx context.Context, downloadLatestBin
 	return nil
 }
 
-func (driver *Driver) syncBinlogMetaFileFromCloud(ctx context.Context, client *bbs3.Client) error {
+func (driver *Driver) syncBinlogMetaFileFromCloud(ctx context.Context, client storage.Client) error {
 	metaListToDownload, err := driver.getBinlogMetaFileListToDownload(ctx, client)
 	if err != nil {
 		return errors.Wrapf(err, "failed to get binlog metadata file list on cloud in directory %q", driver.binlogDir)
@@ -651,7 +651,7 @@ func (driver *Driver) syncBinlogMetaFileFromCloud(ctx context.Context, client *b
 		filePathLocal := filepath.Join(driver.binlogDir, metaFileName)
 		// Use path.Join to compose a path on cloud which always uses / as the separator.
 		filePathOnCloud := path.Join(common.GetBinlogRelativeDir(driver.binlogDir), metaFileName)
-		if err := client.DownloadFileFromCloud(ctx, filePathLocal, filePathOnCloud); err != nil {
+		if err := storage.DownloadFileFromCloud(ctx, client, filePathLocal, filePathOnCloud); err != nil {
 			return errors.Wrapf(err, "failed to download binlog metadata file %s from the cloud storage", metaFileName)
 		}
 	}
@@ -659,14 +659,15 @@ func (driver *Driver) syncBinlogMetaFileFromCloud(ctx context.Context, client *b
 	return nil
 }
 
-func (driver *Driver) getBinlogMetaFileListToDownload(ctx context.Context, client *bbs3.Client) ([]string, error) {
-	listOutput, err := client.ListObjects(ctx, driver.binlogDir)
+func (driver *Driver) getBinlogMetaFileListToDownload(ctx context.Context, client storage.Client) ([]string, error) {
+	binlogDirOnCloud := common.GetBinlogRelativeDir(driver.binlogDir)
+	listOutput, err := client.ListObjects(ctx, binlogDirOnCloud)
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to list binlog dir %q in the cloud storage", driver.binlogDir)
+		return nil, errors.Wrapf(err, "failed to list binlog dir %q in the cloud storage", binlogDirOnCloud)
 	}
 	var downloadList []string
 	for _, item := range listOutput {
-		binlogPathOnCloud := *item.Key
+		binlogPathOnCloud := item.Key
 		if !strings.HasSuffix(binlogPathOnCloud, binlogMetaSuffix) {
 			continue
 		}
@@ -748,7 +749,7 @@ func (driver *Driver) downloadBinlogFile(ctx context.Context, binlogFileToDownlo
 	return nil
 }
 
-func (driver *Driver) uploadBinlogFileToCloud(ctx context.Context, uploader *bbs3.Client, binlogFileName string) error {
+func (driver *Driver) uploadBinlogFileToCloud(ctx context.Context, uploader storage.Client, binlogFileName string) error {
 	binlogFilePath := filepath.Join(driver.binlogDir, binlogFileName)
 	metaFileName := binlogFileName + binlogMetaSuffix
 	metaFilePath := filepath.Join(driver.binlogDir, metaFileName)
@@ -759,7 +760,7 @@ func (driver *Driver) uploadBinlogFileToCloud(ctx context.Context, uploader *bbs
 	defer binlogFile.Close()
 	defer os.Remove(binlogFilePath)
 	relativeDir := common.GetBinlogRelativeDir(driver.binlogDir)
-	if _, err := uploader.UploadObject(ctx, path.Join(relativeDir, binlogFileName), binlogFile); err != nil {
+	if err := uploader.UploadObject(ctx, path.Join(relativeDir, binlogFileName), binlogFile); err != nil {
 		// Remove the local metadata file so that it can be re-uploaded later.
 		if err := os.Remove(metaFilePath); err != nil {
 			log.Warn("Failed to remove binlog metadata file %q when error occurs in uploading binlog file", zap.String("binlogFile", binlogFilePath), zap.Error(err))
@@ -773,7 +774,7 @@ func (driver *Driver) uploadBinlogFileToCloud(ctx context.Context, uploader *bbs
 	}
 	defer metaFile.Close()
 	// We leave the local metadata file to indicate that the binlog file has been uploaded successfully.
-	if _, err := uploader.UploadObject(ctx, path.Join(relativeDir, metaFileName), metaFile); err != nil {
+	if err := uploader.UploadObject(ctx, path.Join(relativeDir, metaFileName), metaFile); err != nil {
 		return errors.Wrapf(err, "failed to upload binlog metadata file %q to cloud storage", metaFileName)
 	}
 	log.Debug("Successfully uploaded binlog file to cloud storage", zap.String("path", binlogFilePath))
@@ -840,7 +841,7 @@ func (driver *Driver) GetSortedBinlogFilesOnServer(ctx context.Context) ([]Binlo
 }
 
 // getBinlogCoordinateByTs converts a timestamp to binlog coordinate using local binlog files.
-func (driver *Driver) getBinlogCoordinateByTs(ctx context.Context, targetTs int64, client *bbs3.Client) (*binlogCoordinate, error) {
+func (driver *Driver) getBinlogCoordinateByTs(ctx context.Context, targetTs int64, client storage.Client) (*binlogCoordinate, error) {
 	metaList, err := getSortedLocalBinlogFilesMeta(driver.binlogDir)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to read local binlog metadata files")
@@ -878,7 +879,7 @@ func (driver *Driver) getBinlogCoordinateByTs(ctx context.Context, targetTs int6
 		filePathLocal := filepath.Join(driver.binlogDir, targetMeta.binlogName)
 		// Use path.Join to compose a path on cloud which always uses / as the separator.
 		filePathOnCloud := path.Join(common.GetBinlogRelativeDir(driver.binlogDir), targetMeta.binlogName)
-		if err := client.DownloadFileFromCloud(ctx, filePathLocal, filePathOnCloud); err != nil {
+		if err := storage.DownloadFileFromCloud(ctx, client, filePathLocal, filePathOnCloud); err != nil {
 			return nil, errors.Wrapf(err, "failed to download binlog file %s from the cloud storage", targetMeta.binlogName)
 		}
 	}
@@ -0,0 +1,570 @@
+package mysql
+
+// This file implements the rollback statement generation for data migrations.
+// The data migration is executed on a dedicated connection, and we record the connection ID and the binlog
+// coordinates before and after the execution. Then we decode the row events written by the connection with
+// mysqlbinlog, and revert each row change in the reverse order:
+// 1. INSERT is reverted by DELETE.
+// 2. DELETE is reverted by INSERT.
+// 3. UPDATE is reverted by UPDATE which sets the row back to the before image.
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/common/log"
+	"github.com/bytebase/bytebase/plugin/db"
+	"github.com/bytebase/bytebase/plugin/db/util"
+	"github.com/bytebase/bytebase/resources/mysqlutil"
+)
+
+var (
+	_ db.RollbackExecutor = (*Driver)(nil)
+
+	// The mysqlbinlog output line for the query event looks like
+	// "#221020 10:00:00 server id 1  end_log_pos 1309 CRC32 0x1b2c3d4e 	Query	thread_id=9	exec_time=0	error_code=0".
+	binlogThreadIDRegexp = regexp.MustCompile(`\sQuery\s+thread_id=(\d+)`)
+	// The signed integer column may be printed with the unsigned value, e.g. "-1 (18446744073709551615)".
+	binlogIntegerRegexp = regexp.MustCompile(`^(-\d+) \((\d+)\)$`)
+)
+
+const (
+	rowChangeInsert = "INSERT"
+	rowChangeUpdate = "UPDATE"
+	rowChangeDelete = "DELETE"
+)
+
+// rowChange is a row change decoded from the binlog row event.
+type rowChange struct {
+	changeType string
+	database   string
+	table      string
+	// before is the row image before the change, which is empty for INSERT.
+	before []string
+	// after is the row image after the change, which is empty for DELETE.
+	after []string
+}
+
+// rollbackColumn is the column metadata to generate the rollback statement.
+type rollbackColumn struct {
+	name      string
+	dataType  string
+	unsigned  bool
+	primary   bool
+	generated bool
+}
+
+// binlogExecutor executes the statement on a dedicated connection and records the binlog coordinates around the execution.
+type binlogExecutor struct {
+	*Driver
+
+	executed bool
+	threadID string
+	start    api.BinlogInfo
+	end      api.BinlogInfo
+}
+
+// Execute executes the statement within a transaction on a dedicated connection.
+func (executor *binlogExecutor) Execute(ctx context.Context, statement string) error {
+	conn, err := executor.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := conn.QueryRowContext(ctx, "SELECT CONNECTION_ID()").Scan(&executor.threadID); err != nil {
+		return err
+	}
+	start, err := GetBinlogInfo(ctx, conn)
+	if err != nil {
+		return errors.Wrap(err, "failed to get the binlog coordinate before executing the statement")
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, statement); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	end, err := GetBinlogInfo(ctx, conn)
+	if err != nil {
+		return errors.Wrap(err, "failed to get the binlog coordinate after executing the statement")
+	}
+	executor.executed = true
+	executor.start = start
+	executor.end = end
+	return nil
+}
+
+// ExecuteMigrationWithRollback executes the migration and generates the rollback statement from the binlog events written by the migration.
+func (driver *Driver) ExecuteMigrationWithRollback(ctx context.Context, m *db.MigrationInfo, statement string) (int64, string, *db.RollbackResult, error) {
+	if requirementErr := driver.checkRollbackRequirements(ctx); requirementErr != nil {
+		migrationHistoryID, schema, err := driver.ExecuteMigration(ctx, m, statement)
+		if err != nil {
+			return -1, "", nil, err
+		}
+		return migrationHistoryID, schema, &db.RollbackResult{Error: requirementErr.Error()}, nil
+	}
+
+	executor := &binlogExecutor{Driver: driver}
+	migrationHistoryID, schema, err := util.ExecuteMigration(ctx, executor, m, statement, db.BytebaseDatabase)
+	if err != nil {
+		return -1, "", nil, err
+	}
+	if !executor.executed {
+		return migrationHistoryID, schema, nil, nil
+	}
+
+	rollbackStatement, count, err := driver.generateRollbackStatement(ctx, executor.threadID, executor.start, executor.end)
+	if err != nil {
+		log.Warn("Failed to generate the rollback statement",
+			zap.String("database", m.Database),
+			zap.String("version", m.Version),
+			zap.Error(err),
+		)
+		return migrationHistoryID, schema, &db.RollbackResult{Error: err.Error()}, nil
+	}
+	return migrationHistoryID, schema, &db.RollbackResult{Statement: rollbackStatement, AffectedRowCount: count}, nil
+}
+
+// checkRollbackRequirements checks that the binlog contains the full row images.
+func (driver *Driver) checkRollbackRequirements(ctx context.Context) error {
+	if driver.dbType != db.MySQL {
+		return errors.Errorf("generating rollback statement is not supported for %s", driver.dbType)
+	}
+	if err := driver.CheckBinlogEnabled(ctx); err != nil {
+		return err
+	}
+	if err := driver.CheckBinlogRowFormat(ctx); err != nil {
+		return err
+	}
+	value, err := driver.getServerVariable(ctx, "binlog_row_image")
+	if err != nil {
+		return err
+	}
+	if strings.ToUpper(value) != "FULL" {
+		return errors.Errorf("binlog row image is not FULL but %s", value)
+	}
+	return nil
+}
+
+// generateRollbackStatement decodes the row events written by the thread between the start and end binlog coordinates,
+// and returns the rollback statement and the number of changed rows.
+func (driver *Driver) generateRollbackStatement(ctx context.Context, threadID string, start, end api.BinlogInfo) (string, int64, error) {
+	if start == end {
+		return "", 0, nil
+	}
+	binlogFiles, err := driver.GetSortedBinlogFilesOnServer(ctx)
+	if err != nil {
+		return "", 0, err
+	}
+	startSeq, err := GetBinlogNameSeq(start.FileName)
+	if err != nil {
+		return "", 0, err
+	}
+	endSeq, err := GetBinlogNameSeq(end.FileName)
+	if err != nil {
+		return "", 0, err
+	}
+	var binlogNames []string
+	for _, file := range binlogFiles {
+		if file.Seq >= startSeq && file.Seq <= endSeq {
+			binlogNames = append(binlogNames, file.Name)
+		}
+	}
+	if len(binlogNames) == 0 || binlogNames[0] != start.FileName {
+		return "", 0, errors.Errorf("binlog file %q is not found on the server", start.FileName)
+	}
+
+	args := []string{
+		"--read-from-remote-server",
+		"--host", driver.connCfg.Host,
+		"--user", driver.connCfg.Username,
+		// Decode the row events to the pseudo SQL statements.
+		"--base64-output=DECODE-ROWS",
+		"--verbose",
+		// The start position applies to the first binlog file and the stop position applies to the last one.
+		"--start-position", strconv.FormatInt(start.Position, 10),
+		"--stop-position", strconv.FormatInt(end.Position, 10),
+	}
+	if driver.connCfg.Port != "" {
+		args = append(args, "--port", driver.connCfg.Port)
+	}
+	args = append(args, binlogNames...)
+
+	cmd := exec.CommandContext(ctx, mysqlutil.GetPath(mysqlutil.MySQLBinlog, driver.resourceDir), args...)
+	// We cannot set password as a flag. Otherwise, there is warning message
+	// "mysqlbinlog: [Warning] Using a password on the command line interface can be insecure."
+	if driver.connCfg.Password != "" {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("MYSQL_PWD=%s", driver.connCfg.Password))
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	pr, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", 0, err
+	}
+	if err := cmd.Start(); err != nil {
+		return "", 0, errors.Wrap(err, "failed to execute mysqlbinlog binary")
+	}
+	changes, parseErr := parseBinlogRowChanges(pr, threadID)
+	// Drain the output so that mysqlbinlog can exit if we stop parsing early.
+	_, _ = io.Copy(io.Discard, pr)
+	if err := cmd.Wait(); err != nil {
+		return "", 0, errors.Wrapf(err, "failed to decode binlog with mysqlbinlog: %s", stderr.String())
+	}
+	if parseErr != nil {
+		return "", 0, parseErr
+	}
+
+	tables := make(map[string][]*rollbackColumn)
+	for _, change := range changes {
+		key := fmt.Sprintf("%s.%s", change.database, change.table)
+		if _, ok := tables[key]; ok {
+			continue
+		}
+		columns, err := driver.getRollbackColumns(ctx, change.database, change.table)
+		if err != nil {
+			return "", 0, err
+		}
+		tables[key] = columns
+	}
+
+	statement, err := generateRollbackStatement(changes, tables)
+	if err != nil {
+		return "", 0, err
+	}
+	return statement, int64(len(changes)), nil
+}
+
+// getRollbackColumns gets the columns of the table in the ordinal order, which is the same as the order in the binlog row image.
+func (driver *Driver) getRollbackColumns(ctx context.Context, database, table string) ([]*rollbackColumn, error) {
+	query := `
+		SELECT COLUMN_NAME, DATA_TYPE, COLUMN_TYPE, COLUMN_KEY, EXTRA
+		FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?
+		ORDER BY ORDINAL_POSITION`
+	rows, err := driver.db.QueryContext(ctx, query, database, table)
+	if err != nil {
+		return nil, util.FormatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	var columns []*rollbackColumn
+	for rows.Next() {
+		var name, dataType, columnType, columnKey string
+		var extra sql.NullString
+		if err := rows.Scan(&name, &dataType, &columnType, &columnKey, &extra); err != nil {
+			return nil, err
+		}
+		columns = append(columns, &rollbackColumn{
+			name:      name,
+			dataType:  strings.ToLower(dataType),
+			unsigned:  strings.Contains(strings.ToLower(columnType), "unsigned"),
+			primary:   columnKey == "PRI",
+			generated: strings.Contains(strings.ToUpper(extra.String), "GENERATED"),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, util.FormatErrorWithQuery(err, query)
+	}
+	if len(columns) == 0 {
+		return nil, errors.Errorf("table `%s`.`%s` is not found", database, table)
+	}
+	return columns, nil
+}
+
+// parseBinlogRowChanges parses the row changes of the thread from the mysqlbinlog verbose output, which looks like
+//
+//	### UPDATE `db`.`tbl`
+//	### WHERE
+//	###   @1=1
+//	###   @2='a'
+//	### SET
+//	###   @1=1
+//	###   @2='b'
+func parseBinlogRowChanges(r io.Reader, threadID string) ([]*rowChange, error) {
+	var changes []*rowChange
+	var current *rowChange
+	var currentImage *[]string
+	currentThreadID := ""
+
+	s := bufio.NewScanner(r)
+	// The row image could be large for the BLOB and TEXT columns.
+	s.Buffer(make([]byte, 0, 64*1024), 1024*1024*1024)
+	for s.Scan() {
+		line := s.Text()
+		if !strings.HasPrefix(line, "###") {
+			if matches := binlogThreadIDRegexp.FindStringSubmatch(line); matches != nil {
+				currentThreadID = matches[1]
+			}
+			continue
+		}
+		if currentThreadID != threadID {
+			continue
+		}
+
+		content := strings.TrimPrefix(line, "###")
+		switch {
+		case strings.HasPrefix(content, " INSERT INTO "):
+			current = &rowChange{changeType: rowChangeInsert}
+			currentImage = nil
+			if err := current.setTable(strings.TrimPrefix(content, " INSERT INTO ")); err != nil {
+				return nil, err
+			}
+			changes = append(changes, current)
+		case strings.HasPrefix(content, " UPDATE "):
+			current = &rowChange{changeType: rowChangeUpdate}
+			currentImage = nil
+			if err := current.setTable(strings.TrimPrefix(content, " UPDATE ")); err != nil {
+				return nil, err
+			}
+			changes = append(changes, current)
+		case strings.HasPrefix(content, " DELETE FROM "):
+			current = &rowChange{changeType: rowChangeDelete}
+			currentImage = nil
+			if err := current.setTable(strings.TrimPrefix(content, " DELETE FROM ")); err != nil {
+				return nil, err
+			}
+			changes = append(changes, current)
+		case content == " WHERE":
+			if current == nil {
+				return nil, errors.Errorf("unexpected mysqlbinlog output line %q", line)
+			}
+			currentImage = &current.before
+		case content == " SET":
+			if current == nil {
+				return nil, errors.Errorf("unexpected mysqlbinlog output line %q", line)
+			}
+			currentImage = &current.after
+		case strings.HasPrefix(content, "   @"):
+			if currentImage == nil {
+				return nil, errors.Errorf("unexpected mysqlbinlog output line %q", line)
+			}
+			column := strings.TrimPrefix(content, "   @")
+			i := strings.Index(column, "=")
+			if i < 0 {
+				return nil, errors.Errorf("unexpected mysqlbinlog output line %q", line)
+			}
+			position, err := strconv.Atoi(column[:i])
+			if err != nil {
+				return nil, errors.Wrapf(err, "unexpected mysqlbinlog output line %q", line)
+			}
+			if position != len(*currentImage)+1 {
+				return nil, errors.Errorf("expecting column @%d but got @%d in the row image", len(*currentImage)+1, position)
+			}
+			// The float and double values are padded with spaces.
+			*currentImage = append(*currentImage, strings.TrimRight(column[i+1:], " "))
+		default:
+			return nil, errors.Errorf("unexpected mysqlbinlog output line %q", line)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// setTable sets the database and table from the string like "`db`.`tbl`".
+func (change *rowChange) setTable(s string) error {
+	parts := strings.SplitN(s, "`.`", 2)
+	if len(parts) != 2 || !strings.HasPrefix(parts[0], "`") || !strings.HasSuffix(parts[1], "`") {
+		return errors.Errorf("failed to parse table name %q in the binlog row event", s)
+	}
+	change.database = strings.TrimPrefix(parts[0], "`")
+	change.table = strings.TrimSuffix(parts[1], "`")
+	return nil
+}
+
+// generateRollbackStatement generates the statements to revert the row changes in the reverse order.
+func generateRollbackStatement(changes []*rowChange, tables map[string][]*rollbackColumn) (string, error) {
+	var buf strings.Builder
+	for i := len(changes) - 1; i >= 0; i-- {
+		change := changes[i]
+		columns := tables[fmt.Sprintf("%s.%s", change.database, change.table)]
+		table := fmt.Sprintf("`%s`.`%s`", change.database, change.table)
+		for _, image := range [][]string{change.before, change.after} {
+			if len(image) != 0 && len(image) != len(columns) {
+				return "", errors.Errorf("table %s has %d columns but the binlog row image has %d columns, the table may have been altered", table, len(columns), len(image))
+			}
+		}
+
+		switch change.changeType {
+		case rowChangeInsert:
+			where, err := getRollbackWhere(columns, change.after)
+			if err != nil {
+				return "", err
+			}
+			if _, err := fmt.Fprintf(&buf, "DELETE FROM %s WHERE %s LIMIT 1;\n", table, where); err != nil {
+				return "", err
+			}
+		case rowChangeDelete:
+			var names, values []string
+			for i, column := range columns {
+				if column.generated {
+					continue
+				}
+				value, err := convertBinlogValue(change.before[i], column)
+				if err != nil {
+					return "", err
+				}
+				names = append(names, fmt.Sprintf("`%s`", column.name))
+				values = append(values, value)
+			}
+			if _, err := fmt.Fprintf(&buf, "INSERT INTO %s (%s) VALUES (%s);\n", table, strings.Join(names, ", "), strings.Join(values, ", ")); err != nil {
+				return "", err
+			}
+		case rowChangeUpdate:
+			var sets []string
+			for i, column := range columns {
+				if column.generated || change.before[i] == change.after[i] {
+					continue
+				}
+				value, err := convertBinlogValue(change.before[i], column)
+				if err != nil {
+					return "", err
+				}
+				sets = append(sets, fmt.Sprintf("`%s` = %s", column.name, value))
+			}
+			if len(sets) == 0 {
+				continue
+			}
+			where, err := getRollbackWhere(columns, change.after)
+			if err != nil {
+				return "", err
+			}
+			if _, err := fmt.Fprintf(&buf, "UPDATE %s SET %s WHERE %s LIMIT 1;\n", table, strings.Join(sets, ", "), where); err != nil {
+				return "", err
+			}
+		}
+	}
+	return buf.String(), nil
+}
+
+// getRollbackWhere gets the WHERE condition to identify the row, which uses the primary key if the table has one.
+func getRollbackWhere(columns []*rollbackColumn, image []string) (string, error) {
+	hasPrimaryKey := false
+	for _, column := range columns {
+		if column.primary {
+			hasPrimaryKey = true
+			break
+		}
+	}
+	var conditions []string
+	for i, column := range columns {
+		if hasPrimaryKey && !column.primary {
+			continue
+		}
+		if !hasPrimaryKey && column.generated {
+			continue
+		}
+		if image[i] == "NULL" {
+			conditions = append(conditions, fmt.Sprintf("`%s` IS NULL", column.name))
+			continue
+		}
+		value, err := convertBinlogValue(image[i], column)
+		if err != nil {
+			return "", err
+		}
+		conditions = append(conditions, fmt.Sprintf("`%s` = %s", column.name, value))
+	}
+	return strings.Join(conditions, " AND "), nil
+}
+
+// convertBinlogValue converts the value printed by mysqlbinlog to the SQL literal.
+func convertBinlogValue(value string, column *rollbackColumn) (string, error) {
+	if value == "NULL" {
+		return value, nil
+	}
+	if strings.HasPrefix(value, "'") {
+		if len(value) < 2 || !strings.HasSuffix(value, "'") {
+			return "", errors.Errorf("invalid string value %s of column `%s` in the binlog row image", value, column.name)
+		}
+		b, err := unescapeBinlogString(value[1 : len(value)-1])
+		if err != nil {
+			return "", errors.Wrapf(err, "invalid string value %s of column `%s` in the binlog row image", value, column.name)
+		}
+		if strings.Contains(column.dataType, "binary") || strings.Contains(column.dataType, "blob") || !utf8.Valid(b) {
+			return fmt.Sprintf("X'%s'", hex.EncodeToString(b)), nil
+		}
+		return quoteString(string(b)), nil
+	}
+	if matches := binlogIntegerRegexp.FindStringSubmatch(value); matches != nil {
+		if column.unsigned {
+			return matches[2], nil
+		}
+		return matches[1], nil
+	}
+	// The TIMESTAMP value is printed as the Unix timestamp.
+	if column.dataType == "timestamp" {
+		return fmt.Sprintf("FROM_UNIXTIME(%s)", value), nil
+	}
+	return value, nil
+}
+
+// unescapeBinlogString decodes the string printed by mysqlbinlog, which escapes the control characters, quotes and backslashes as "\xHH".
+func unescapeBinlogString(s string) ([]byte, error) {
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			b = append(b, s[i])
+			continue
+		}
+		if i+3 >= len(s) || s[i+1] != 'x' {
+			return nil, errors.Errorf("invalid escape sequence at %d", i)
+		}
+		v, err := strconv.ParseUint(s[i+2:i+4], 16, 8)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid escape sequence at %d", i)
+		}
+		b = append(b, byte(v))
+		i += 3
+	}
+	return b, nil
+}
+
+// quoteString quotes the string as the MySQL string literal.
+func quoteString(s string) string {
+	var buf strings.Builder
+	buf.WriteByte('\'')
+	for _, r := range s {
+		switch r {
+		case 0:
+			buf.WriteString(`\0`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\x1a':
+			buf.WriteString(`\Z`)
+		case '\'':
+			buf.WriteString(`\'`)
+		case '\\':
+			buf.WriteString(`\\`)
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	buf.WriteByte('\'')
+	return buf.String()
+}
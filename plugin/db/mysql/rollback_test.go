@@ -0,0 +1,157 @@
+package mysql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testBinlogOutput = `# at 1234
+#221020 10:00:00 server id 1  end_log_pos 1309 CRC32 0x1b2c3d4e 	Query	thread_id=9	exec_time=0	error_code=0
+SET TIMESTAMP=1666231200/*!*/;
+BEGIN
+/*!*/;
+# at 1309
+#221020 10:00:00 server id 1  end_log_pos 1370 CRC32 0x2c3d4e5f 	Table_map: ` + "`test`.`user`" + ` mapped to number 91
+# at 1370
+#221020 10:00:00 server id 1  end_log_pos 1440 CRC32 0x3d4e5f60 	Write_rows: table id 91 flags: STMT_END_F
+### INSERT INTO ` + "`test`.`user`" + `
+### SET
+###   @1=3
+###   @2='c'
+###   @3=NULL
+# at 1440
+#221020 10:00:00 server id 1  end_log_pos 1471 CRC32 0x4e5f6071 	Xid = 45
+COMMIT/*!*/;
+# at 1471
+#221020 10:00:01 server id 1  end_log_pos 1546 CRC32 0x5f607182 	Query	thread_id=10	exec_time=0	error_code=0
+BEGIN
+/*!*/;
+# at 1546
+#221020 10:00:01 server id 1  end_log_pos 1616 CRC32 0x60718293 	Delete_rows: table id 91 flags: STMT_END_F
+### DELETE FROM ` + "`test`.`user`" + `
+### WHERE
+###   @1=100
+###   @2='other'
+###   @3=NULL
+# at 1616
+#221020 10:00:01 server id 1  end_log_pos 1691 CRC32 0x718293a4 	Query	thread_id=9	exec_time=0	error_code=0
+BEGIN
+/*!*/;
+# at 1691
+#221020 10:00:01 server id 1  end_log_pos 1800 CRC32 0x8293a4b5 	Update_rows: table id 91 flags: STMT_END_F
+### UPDATE ` + "`test`.`user`" + `
+### WHERE
+###   @1=1
+###   @2='it\x27s\x0a'
+###   @3=-1 (4294967295)
+### SET
+###   @1=1
+###   @2='a'
+###   @3=-1 (4294967295)
+### DELETE FROM ` + "`test`.`user`" + `
+### WHERE
+###   @1=2
+###   @2='b'
+###   @3=1666231200
+`
+
+func TestParseBinlogRowChanges(t *testing.T) {
+	a := require.New(t)
+	changes, err := parseBinlogRowChanges(strings.NewReader(testBinlogOutput), "9")
+	a.NoError(err)
+	a.Equal([]*rowChange{
+		{
+			changeType: rowChangeInsert,
+			database:   "test",
+			table:      "user",
+			after:      []string{"3", "'c'", "NULL"},
+		},
+		{
+			changeType: rowChangeUpdate,
+			database:   "test",
+			table:      "user",
+			before:     []string{"1", `'it\x27s\x0a'`, "-1 (4294967295)"},
+			after:      []string{"1", "'a'", "-1 (4294967295)"},
+		},
+		{
+			changeType: rowChangeDelete,
+			database:   "test",
+			table:      "user",
+			before:     []string{"2", "'b'", "1666231200"},
+		},
+	}, changes)
+
+	_, err = parseBinlogRowChanges(strings.NewReader("#221020 10:00:01 server id 1  end_log_pos 1 CRC32 0x0 	Query	thread_id=9\n###   @2=1\n"), "9")
+	a.Error(err)
+}
+
+func TestGenerateRollbackStatement(t *testing.T) {
+	a := require.New(t)
+	changes, err := parseBinlogRowChanges(strings.NewReader(testBinlogOutput), "9")
+	a.NoError(err)
+
+	tests := []struct {
+		columns []*rollbackColumn
+		want    string
+	}{
+		{
+			columns: []*rollbackColumn{
+				{name: "id", dataType: "int", primary: true},
+				{name: "name", dataType: "varchar"},
+				{name: "value", dataType: "int", unsigned: true},
+			},
+			want: "INSERT INTO `test`.`user` (`id`, `name`, `value`) VALUES (2, 'b', 1666231200);\n" +
+				"UPDATE `test`.`user` SET `name` = 'it\\'s\\n' WHERE `id` = 1 LIMIT 1;\n" +
+				"DELETE FROM `test`.`user` WHERE `id` = 3 LIMIT 1;\n",
+		},
+		{
+			// Without primary key.
+			columns: []*rollbackColumn{
+				{name: "id", dataType: "int"},
+				{name: "name", dataType: "varbinary"},
+				{name: "value", dataType: "int"},
+			},
+			want: "INSERT INTO `test`.`user` (`id`, `name`, `value`) VALUES (2, X'62', 1666231200);\n" +
+				"UPDATE `test`.`user` SET `name` = X'697427730a' WHERE `id` = 1 AND `name` = X'61' AND `value` = -1 LIMIT 1;\n" +
+				"DELETE FROM `test`.`user` WHERE `id` = 3 AND `name` = X'63' AND `value` IS NULL LIMIT 1;\n",
+		},
+	}
+
+	for _, test := range tests {
+		got, err := generateRollbackStatement(changes, map[string][]*rollbackColumn{"test.user": test.columns})
+		a.NoError(err)
+		a.Equal(test.want, got)
+	}
+
+	_, err = generateRollbackStatement(changes, map[string][]*rollbackColumn{"test.user": {{name: "id"}}})
+	a.Error(err)
+}
+
+func TestConvertBinlogValue(t *testing.T) {
+	a := require.New(t)
+	tests := []struct {
+		value  string
+		column *rollbackColumn
+		want   string
+	}{
+		{value: "NULL", column: &rollbackColumn{dataType: "varchar"}, want: "NULL"},
+		{value: "1.5", column: &rollbackColumn{dataType: "double"}, want: "1.5"},
+		{value: "'a\\x5cb'", column: &rollbackColumn{dataType: "varchar"}, want: `'a\\b'`},
+		{value: "'\\xff'", column: &rollbackColumn{dataType: "varchar"}, want: "X'ff'"},
+		{value: "'2022:10:20'", column: &rollbackColumn{dataType: "date"}, want: "'2022:10:20'"},
+		{value: "-2 (4294967294)", column: &rollbackColumn{dataType: "int", unsigned: true}, want: "4294967294"},
+		{value: "-2 (4294967294)", column: &rollbackColumn{dataType: "int"}, want: "-2"},
+		{value: "1666231200", column: &rollbackColumn{dataType: "timestamp"}, want: "FROM_UNIXTIME(1666231200)"},
+		{value: "b'101'", column: &rollbackColumn{dataType: "bit"}, want: "b'101'"},
+	}
+	for _, test := range tests {
+		got, err := convertBinlogValue(test.value, test.column)
+		a.NoError(err)
+		a.Equal(test.want, got, test.value)
+	}
+
+	_, err := convertBinlogValue("'a\\x5'", &rollbackColumn{dataType: "varchar"})
+	a.Error(err)
+}
@@ -0,0 +1,393 @@
+package oracle
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/bytebase/bytebase/plugin/db/util"
+)
+
+// Dump and restore.
+const (
+	schemaHeaderFmt = "" +
+		"--\n" +
+		"-- Oracle schema structure for %s\n" +
+		"--\n"
+	objectHeaderFmt = "" +
+		"--\n" +
+		"-- %s structure for %s\n" +
+		"--\n"
+
+	// plsqlTerminator terminates PL/SQL blocks, same as SQL*Plus.
+	plsqlTerminator = "/"
+)
+
+var (
+	// dumpObjectTypes are the object types dumped after tables, in the order of dependencies.
+	// Indexes, constraints and comments of tables are dumped along with the tables.
+	dumpObjectTypes = []string{
+		"SEQUENCE",
+		"TYPE",
+		"SYNONYM",
+		"VIEW",
+		"FUNCTION",
+		"PROCEDURE",
+		"PACKAGE",
+		"TRIGGER",
+	}
+
+	// plsqlBlockRegexp matches the statements which are PL/SQL blocks terminated by a slash line.
+	plsqlBlockRegexp = regexp.MustCompile(`(?is)^(DECLARE|BEGIN|CREATE\s+(OR\s+REPLACE\s+)?((NON)?EDITIONABLE\s+)?(FUNCTION|PROCEDURE|PACKAGE|TRIGGER|TYPE)\s)`)
+)
+
+// Dump dumps the database.
+// Dumping data isn't supported yet, schemaOnly is true by default.
+func (driver *Driver) Dump(ctx context.Context, database string, out io.Writer, _ bool) (string, error) {
+	// Find all dumpable schemas
+	var dumpableSchemas []string
+	if database != "" {
+		dumpableSchemas = []string{strings.ToUpper(database)}
+	} else {
+		schemas, err := driver.getSchemas(ctx)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to get schemas")
+		}
+		for _, schema := range schemas {
+			if schema == bytebaseSchema {
+				continue
+			}
+			dumpableSchemas = append(dumpableSchemas, schema)
+		}
+	}
+
+	// DBMS_METADATA session transform parameters only take effect in the same session, so we pin the session with a transaction.
+	txn, err := driver.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer txn.Rollback()
+
+	if err := setMetadataTransformParams(ctx, txn); err != nil {
+		return "", err
+	}
+
+	for _, schema := range dumpableSchemas {
+		// Schema header and ALTER SESSION statement are only included if dumping all schemas.
+		if database == "" {
+			header := fmt.Sprintf(schemaHeaderFmt, schema)
+			if _, err := io.WriteString(out, header); err != nil {
+				return "", err
+			}
+			if _, err := io.WriteString(out, fmt.Sprintf("ALTER SESSION SET CURRENT_SCHEMA = \"%s\";\n\n", schema)); err != nil {
+				return "", err
+			}
+		}
+		if err := dumpOneSchema(ctx, txn, schema, out); err != nil {
+			return "", err
+		}
+	}
+
+	if err := txn.Commit(); err != nil {
+		return "", err
+	}
+
+	return "", nil
+}
+
+// setMetadataTransformParams sets the DBMS_METADATA transform parameters so that the DDL is terminated and portable across schemas and storages.
+func setMetadataTransformParams(ctx context.Context, txn *sql.Tx) error {
+	stmt := `
+		BEGIN
+			DBMS_METADATA.SET_TRANSFORM_PARAM(DBMS_METADATA.SESSION_TRANSFORM, 'SQLTERMINATOR', TRUE);
+			DBMS_METADATA.SET_TRANSFORM_PARAM(DBMS_METADATA.SESSION_TRANSFORM, 'PRETTY', TRUE);
+			DBMS_METADATA.SET_TRANSFORM_PARAM(DBMS_METADATA.SESSION_TRANSFORM, 'SEGMENT_ATTRIBUTES', FALSE);
+			DBMS_METADATA.SET_TRANSFORM_PARAM(DBMS_METADATA.SESSION_TRANSFORM, 'STORAGE', FALSE);
+			DBMS_METADATA.SET_TRANSFORM_PARAM(DBMS_METADATA.SESSION_TRANSFORM, 'EMIT_SCHEMA', FALSE);
+			DBMS_METADATA.SET_TRANSFORM_PARAM(DBMS_METADATA.SESSION_TRANSFORM, 'REF_CONSTRAINTS', FALSE);
+		END;`
+	if _, err := txn.ExecContext(ctx, stmt); err != nil {
+		return util.FormatErrorWithQuery(err, stmt)
+	}
+	return nil
+}
+
+// dumpOneSchema dumps the DDL of a schema, including tables, indexes, foreign keys, comments and the other objects in dumpObjectTypes.
+func dumpOneSchema(ctx context.Context, txn *sql.Tx, schema string, out io.Writer) error {
+	tables, err := getObjectNames(ctx, txn, schema, "TABLE")
+	if err != nil {
+		return err
+	}
+	for _, table := range tables {
+		if err := dumpObject(ctx, txn, schema, "TABLE", table, out); err != nil {
+			return err
+		}
+		if err := dumpTableIndexes(ctx, txn, schema, table, out); err != nil {
+			return err
+		}
+		if err := dumpTableComments(ctx, txn, schema, table, out); err != nil {
+			return err
+		}
+	}
+	// Foreign keys are dumped after all tables so that the referenced tables exist.
+	if err := dumpForeignKeys(ctx, txn, schema, out); err != nil {
+		return err
+	}
+
+	for _, objectType := range dumpObjectTypes {
+		names, err := getObjectNames(ctx, txn, schema, objectType)
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			if err := dumpObject(ctx, txn, schema, objectType, name, out); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// getObjectNames gets the names of the objects of the given type in the schema in the order of creation.
+func getObjectNames(ctx context.Context, txn *sql.Tx, schema, objectType string) ([]string, error) {
+	// Exclude the system generated objects and the tables for materialized views, queues and so on.
+	query := `
+		SELECT OBJECT_NAME
+		FROM ALL_OBJECTS o
+		WHERE OWNER = :1 AND OBJECT_TYPE = :2 AND GENERATED = 'N'
+			AND NOT EXISTS (SELECT 1 FROM ALL_MVIEWS m WHERE m.OWNER = o.OWNER AND m.MVIEW_NAME = o.OBJECT_NAME)
+			AND OBJECT_NAME NOT LIKE 'BIN$%'
+		ORDER BY CREATED, OBJECT_NAME`
+	return queryNames(ctx, txn, query, schema, objectType)
+}
+
+// dumpObject dumps the DDL of an object by DBMS_METADATA.GET_DDL.
+func dumpObject(ctx context.Context, txn *sql.Tx, schema, objectType, name string, out io.Writer) error {
+	ddl, err := getDDL(ctx, txn, schema, objectType, name)
+	if err != nil {
+		return err
+	}
+	header := fmt.Sprintf(objectHeaderFmt, formatObjectType(objectType), name)
+	if _, err := io.WriteString(out, header); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(out, fmt.Sprintf("%s\n\n", ddl)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func getDDL(ctx context.Context, txn *sql.Tx, schema, objectType, name string) (string, error) {
+	query := "SELECT DBMS_METADATA.GET_DDL(:1, :2, :3) FROM DUAL"
+	var ddl sql.NullString
+	if err := txn.QueryRowContext(ctx, query, objectType, name, schema).Scan(&ddl); err != nil {
+		return "", errors.Wrapf(util.FormatErrorWithQuery(err, query), "failed to get DDL of %s %q", strings.ToLower(objectType), name)
+	}
+	return strings.TrimSpace(ddl.String), nil
+}
+
+// dumpTableIndexes dumps the indexes of a table except the ones created implicitly for the constraints, which are dumped along with the table.
+func dumpTableIndexes(ctx context.Context, txn *sql.Tx, schema, table string, out io.Writer) error {
+	query := `
+		SELECT i.INDEX_NAME
+		FROM ALL_INDEXES i
+		WHERE i.OWNER = :1 AND i.TABLE_OWNER = :2 AND i.TABLE_NAME = :3 AND i.GENERATED = 'N' AND i.INDEX_TYPE <> 'LOB'
+			AND NOT EXISTS (SELECT 1 FROM ALL_CONSTRAINTS c WHERE c.OWNER = i.OWNER AND c.INDEX_NAME = i.INDEX_NAME)
+		ORDER BY i.INDEX_NAME`
+	names, err := queryNames(ctx, txn, query, schema, schema, table)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		ddl, err := getDDL(ctx, txn, schema, "INDEX", name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(out, fmt.Sprintf("%s\n\n", ddl)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dumpForeignKeys dumps the foreign key constraints of the schema.
+func dumpForeignKeys(ctx context.Context, txn *sql.Tx, schema string, out io.Writer) error {
+	query := `
+		SELECT CONSTRAINT_NAME
+		FROM ALL_CONSTRAINTS
+		WHERE OWNER = :1 AND CONSTRAINT_TYPE = 'R' AND TABLE_NAME NOT LIKE 'BIN$%'
+		ORDER BY TABLE_NAME, CONSTRAINT_NAME`
+	names, err := queryNames(ctx, txn, query, schema)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		ddl, err := getDDL(ctx, txn, schema, "REF_CONSTRAINT", name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(out, fmt.Sprintf("%s\n\n", ddl)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dumpTableComments dumps the comments of a table and its columns.
+// DBMS_METADATA.GET_DEPENDENT_DDL raises an error if there isn't any comment, so we build the statements ourselves.
+func dumpTableComments(ctx context.Context, txn *sql.Tx, schema, table string, out io.Writer) error {
+	query := `
+		SELECT NULL, COMMENTS FROM ALL_TAB_COMMENTS WHERE OWNER = :1 AND TABLE_NAME = :2 AND COMMENTS IS NOT NULL
+		UNION ALL
+		SELECT COLUMN_NAME, COMMENTS FROM ALL_COL_COMMENTS WHERE OWNER = :3 AND TABLE_NAME = :4 AND COMMENTS IS NOT NULL`
+	rows, err := txn.QueryContext(ctx, query, schema, table, schema, table)
+	if err != nil {
+		return util.FormatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	var stmts []string
+	for rows.Next() {
+		var column sql.NullString
+		var comment string
+		if err := rows.Scan(&column, &comment); err != nil {
+			return err
+		}
+		comment = strings.ReplaceAll(comment, "'", "''")
+		if column.Valid {
+			stmts = append(stmts, fmt.Sprintf("COMMENT ON COLUMN \"%s\".\"%s\" IS '%s';\n", table, column.String, comment))
+		} else {
+			stmts = append(stmts, fmt.Sprintf("COMMENT ON TABLE \"%s\" IS '%s';\n", table, comment))
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return util.FormatErrorWithQuery(err, query)
+	}
+	if len(stmts) == 0 {
+		return nil
+	}
+	if _, err := io.WriteString(out, strings.Join(stmts, "")+"\n"); err != nil {
+		return err
+	}
+	return nil
+}
+
+func queryNames(ctx context.Context, txn *sql.Tx, query string, args ...interface{}) ([]string, error) {
+	rows, err := txn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, util.FormatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, util.FormatErrorWithQuery(err, query)
+	}
+	return names, nil
+}
+
+// formatObjectType formats the object type for the header, e.g. "PROCEDURE" to "Procedure".
+func formatObjectType(objectType string) string {
+	return strings.ToUpper(objectType[:1]) + strings.ToLower(objectType[1:])
+}
+
+// Restore restores a database.
+func (driver *Driver) Restore(ctx context.Context, sc io.Reader) error {
+	txn, err := driver.beginTxWithSchema(ctx, driver.schemaName)
+	if err != nil {
+		return err
+	}
+	defer txn.Rollback()
+
+	f := func(stmt string) error {
+		if _, err := txn.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	if err := applyMultiStatements(sc, f); err != nil {
+		return err
+	}
+
+	return txn.Commit()
+}
+
+// applyMultiStatements applies the statements split from the reader, in the same way as SQL*Plus.
+// SQL statements are terminated by semicolons, which are stripped because the Oracle driver doesn't accept them.
+// PL/SQL blocks, e.g. CREATE PROCEDURE and anonymous blocks, are terminated by a line containing only a slash.
+func applyMultiStatements(sc io.Reader, f func(string) error) error {
+	scanner := bufio.NewScanner(sc)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	var stmt strings.Builder
+	inPLSQL := false
+
+	apply := func(s string) error {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			return nil
+		}
+		if err := f(s); err != nil {
+			return errors.Wrapf(err, "execute query %q failed", s)
+		}
+		return nil
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if stmt.Len() == 0 {
+			// Skip the comments and blank lines between statements.
+			if trimmed == "" || strings.HasPrefix(trimmed, "--") || trimmed == plsqlTerminator {
+				continue
+			}
+			inPLSQL = plsqlBlockRegexp.MatchString(trimmed)
+		}
+
+		if inPLSQL {
+			if trimmed == plsqlTerminator {
+				if err := apply(stmt.String()); err != nil {
+					return err
+				}
+				stmt.Reset()
+				continue
+			}
+			stmt.WriteString(line)
+			stmt.WriteString("\n")
+			continue
+		}
+
+		if strings.HasSuffix(trimmed, ";") {
+			stmt.WriteString(strings.TrimSuffix(strings.TrimRight(line, " \t"), ";"))
+			if err := apply(stmt.String()); err != nil {
+				return err
+			}
+			stmt.Reset()
+			continue
+		}
+		stmt.WriteString(line)
+		stmt.WriteString("\n")
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	// The last statement may not be terminated.
+	s := stmt.String()
+	if !inPLSQL {
+		s = strings.TrimSuffix(strings.TrimSpace(s), ";")
+	}
+	return apply(s)
+}
@@ -0,0 +1,332 @@
+package oracle
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	// embed will embeds the migration schema.
+	_ "embed"
+
+	go_ora "github.com/sijms/go-ora/v2"
+	"go.uber.org/zap"
+
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/common/log"
+	"github.com/bytebase/bytebase/plugin/db"
+	"github.com/bytebase/bytebase/plugin/db/util"
+)
+
+var (
+	//go:embed oracle_migration_schema.sql
+	migrationSchema string
+
+	_ util.MigrationExecutor = (*Driver)(nil)
+)
+
+// NeedsSetupMigration returns whether it needs to setup migration.
+func (driver *Driver) NeedsSetupMigration(ctx context.Context) (bool, error) {
+	const query = `
+		SELECT
+			1
+		FROM ALL_TABLES
+		WHERE OWNER = 'BYTEBASE' AND TABLE_NAME = 'MIGRATION_HISTORY'
+	`
+	return util.NeedsSetupMigrationSchema(ctx, driver.db, query)
+}
+
+// SetupMigrationIfNeeded sets up migration if needed.
+func (driver *Driver) SetupMigrationIfNeeded(ctx context.Context) error {
+	setup, err := driver.NeedsSetupMigration(ctx)
+	if err != nil {
+		return err
+	}
+
+	if setup {
+		// The migration schema requires Oracle 12.2 or later.
+		if _, err := driver.getVersion(ctx); err != nil {
+			return err
+		}
+		log.Info("Bytebase migration schema not found, creating schema...",
+			zap.String("environment", driver.connectionCtx.EnvironmentName),
+			zap.String("database", driver.connectionCtx.InstanceName),
+		)
+		if err := driver.Execute(ctx, migrationSchema); err != nil {
+			log.Error("Failed to initialize migration schema.",
+				zap.Error(err),
+				zap.String("environment", driver.connectionCtx.EnvironmentName),
+				zap.String("database", driver.connectionCtx.InstanceName),
+			)
+			return util.FormatErrorWithQuery(err, migrationSchema)
+		}
+		log.Info("Successfully created migration schema.",
+			zap.String("environment", driver.connectionCtx.EnvironmentName),
+			zap.String("database", driver.connectionCtx.InstanceName),
+		)
+	}
+
+	return nil
+}
+
+// FindLargestVersionSinceBaseline will find the largest version since last baseline or branch.
+func (driver Driver) FindLargestVersionSinceBaseline(ctx context.Context, tx *sql.Tx, namespace string) (*string, error) {
+	largestBaselineSequence, err := driver.FindLargestSequence(ctx, tx, namespace, true /* baseline */)
+	if err != nil {
+		return nil, err
+	}
+	const getLargestVersionSinceLastBaselineQuery = `
+		SELECT MAX(version) FROM bytebase.migration_history
+		WHERE namespace = :1 AND sequence >= :2
+	`
+	var version sql.NullString
+	if err := tx.QueryRowContext(ctx, getLargestVersionSinceLastBaselineQuery,
+		namespace, largestBaselineSequence,
+	).Scan(&version); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, common.FormatDBErrorEmptyRowWithQuery(getLargestVersionSinceLastBaselineQuery)
+		}
+		return nil, util.FormatErrorWithQuery(err, getLargestVersionSinceLastBaselineQuery)
+	}
+	if version.Valid {
+		return &version.String, nil
+	}
+	return nil, nil
+}
+
+// FindLargestSequence will return the largest sequence number.
+func (Driver) FindLargestSequence(ctx context.Context, tx *sql.Tx, namespace string, baseline bool) (int, error) {
+	findLargestSequenceQuery := `
+		SELECT MAX(sequence) FROM bytebase.migration_history
+		WHERE namespace = :1`
+	if baseline {
+		findLargestSequenceQuery = fmt.Sprintf("%s AND (type = '%s' OR type = '%s')", findLargestSequenceQuery, db.Baseline, db.Branch)
+	}
+	var sequence sql.NullInt32
+	if err := tx.QueryRowContext(ctx, findLargestSequenceQuery,
+		namespace,
+	).Scan(&sequence); err != nil {
+		if err == sql.ErrNoRows {
+			return -1, common.FormatDBErrorEmptyRowWithQuery(findLargestSequenceQuery)
+		}
+		return -1, util.FormatErrorWithQuery(err, findLargestSequenceQuery)
+	}
+	if sequence.Valid {
+		return int(sequence.Int32), nil
+	}
+	// Returns 0 if we haven't applied any migration for this namespace.
+	return 0, nil
+}
+
+// InsertPendingHistory will insert the migration record with pending status and return the inserted ID.
+func (Driver) InsertPendingHistory(ctx context.Context, tx *sql.Tx, sequence int, prevSchema string, m *db.MigrationInfo, storedVersion, statement string) (int64, error) {
+	const insertHistoryQuery = `
+		INSERT INTO bytebase.migration_history (
+			created_by,
+			created_ts,
+			updated_by,
+			updated_ts,
+			release_version,
+			namespace,
+			sequence,
+			source,
+			type,
+			status,
+			version,
+			description,
+			statement,
+			"SCHEMA",
+			schema_prev,
+			execution_duration_ns,
+			issue_id,
+			payload
+		)
+		VALUES (:1, :2, :3, :4, :5, :6, :7, :8, :9, :10, :11, :12, :13, :14, :15, 0, :16, :17)
+		RETURNING id INTO :18
+	`
+	var insertedID int64
+	// Oracle doesn't have a built-in function returning the unix epoch.
+	now := time.Now().Unix()
+	if _, err := tx.ExecContext(ctx, insertHistoryQuery,
+		m.Creator,
+		now,
+		m.Creator,
+		now,
+		m.ReleaseVersion,
+		m.Namespace,
+		sequence,
+		m.Source,
+		m.Type,
+		db.Pending,
+		storedVersion,
+		toClob(m.Description),
+		toClob(statement),
+		toClob(prevSchema),
+		toClob(prevSchema),
+		m.IssueID,
+		toClob(m.Payload),
+		sql.Out{Dest: &insertedID},
+	); err != nil {
+		return int64(0), util.FormatErrorWithQuery(err, insertHistoryQuery)
+	}
+	return insertedID, nil
+}
+
+// UpdateHistoryAsDone will update the migration record as done.
+func (Driver) UpdateHistoryAsDone(ctx context.Context, tx *sql.Tx, migrationDurationNs int64, updatedSchema string, insertedID int64) error {
+	const updateHistoryAsDoneQuery = `
+		UPDATE
+			bytebase.migration_history
+		SET
+			status = :1,
+			execution_duration_ns = :2,
+			"SCHEMA" = :3,
+			updated_ts = :4
+		WHERE id = :5
+	`
+	_, err := tx.ExecContext(ctx, updateHistoryAsDoneQuery, db.Done, migrationDurationNs, toClob(updatedSchema), time.Now().Unix(), insertedID)
+	return err
+}
+
+// UpdateHistoryAsFailed will update the migration record as failed.
+func (Driver) UpdateHistoryAsFailed(ctx context.Context, tx *sql.Tx, migrationDurationNs int64, insertedID int64) error {
+	const updateHistoryAsFailedQuery = `
+		UPDATE
+			bytebase.migration_history
+		SET
+			status = :1,
+			execution_duration_ns = :2,
+			updated_ts = :3
+		WHERE id = :4
+	`
+	_, err := tx.ExecContext(ctx, updateHistoryAsFailedQuery, db.Failed, migrationDurationNs, time.Now().Unix(), insertedID)
+	return err
+}
+
+// ExecuteMigration will execute the migration.
+func (driver *Driver) ExecuteMigration(ctx context.Context, m *db.MigrationInfo, statement string) (int64, string, error) {
+	return util.ExecuteMigration(ctx, driver, m, statement, db.BytebaseDatabase)
+}
+
+// FindMigrationHistoryList finds the migration history.
+func (driver *Driver) FindMigrationHistoryList(ctx context.Context, find *db.MigrationHistoryFind) ([]*db.MigrationHistory, error) {
+	baseQuery := `
+	SELECT
+		id,
+		created_by,
+		created_ts,
+		updated_by,
+		updated_ts,
+		release_version,
+		namespace,
+		sequence,
+		source,
+		type,
+		status,
+		version,
+		description,
+		statement,
+		"SCHEMA",
+		schema_prev,
+		execution_duration_ns,
+		issue_id,
+		payload
+		FROM bytebase.migration_history `
+	paramNames, params := []string{}, []interface{}{}
+	if v := find.ID; v != nil {
+		paramNames, params = append(paramNames, "id"), append(params, *v)
+	}
+	if v := find.Database; v != nil {
+		paramNames, params = append(paramNames, "namespace"), append(params, *v)
+	}
+	if v := find.Version; v != nil {
+		// TODO(d): support semantic versioning.
+		storedVersion, err := util.ToStoredVersion(false, *v, "")
+		if err != nil {
+			return nil, err
+		}
+		paramNames, params = append(paramNames, "version"), append(params, storedVersion)
+	}
+	if v := find.Source; v != nil {
+		paramNames, params = append(paramNames, "source"), append(params, *v)
+	}
+	var query = baseQuery +
+		db.FormatParamNameInColonPosition(paramNames) +
+		`ORDER BY created_ts DESC, id DESC`
+	if v := find.Limit; v != nil {
+		query = fmt.Sprintf("SELECT * FROM (%s) WHERE ROWNUM <= %d", query, *v)
+	}
+	return driver.findMigrationHistoryList(ctx, query, params)
+}
+
+// findMigrationHistoryList is similar to util.FindMigrationHistoryList, but scans the nullable columns because Oracle treats empty strings as NULL.
+func (driver *Driver) findMigrationHistoryList(ctx context.Context, query string, params []interface{}) ([]*db.MigrationHistory, error) {
+	tx, err := driver.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, query, params...)
+	if err != nil {
+		return nil, util.FormatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	var migrationHistoryList []*db.MigrationHistory
+	for rows.Next() {
+		var history db.MigrationHistory
+		var storedVersion string
+		var description, statement, schema, schemaPrev, issueID, payload sql.NullString
+		if err := rows.Scan(
+			&history.ID,
+			&history.Creator,
+			&history.CreatedTs,
+			&history.Updater,
+			&history.UpdatedTs,
+			&history.ReleaseVersion,
+			&history.Namespace,
+			&history.Sequence,
+			&history.Source,
+			&history.Type,
+			&history.Status,
+			&storedVersion,
+			&description,
+			&statement,
+			&schema,
+			&schemaPrev,
+			&history.ExecutionDurationNs,
+			&issueID,
+			&payload,
+		); err != nil {
+			return nil, err
+		}
+		history.Description = description.String
+		history.Statement = statement.String
+		history.Schema = schema.String
+		history.SchemaPrev = schemaPrev.String
+		history.IssueID = issueID.String
+		history.Payload = payload.String
+
+		useSemanticVersion, version, semanticVersionSuffix, err := util.FromStoredVersion(storedVersion)
+		if err != nil {
+			return nil, err
+		}
+		history.UseSemanticVersion, history.Version, history.SemanticVersionSuffix = useSemanticVersion, version, semanticVersionSuffix
+		migrationHistoryList = append(migrationHistoryList, &history)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return migrationHistoryList, nil
+}
+
+// toClob wraps the string as a CLOB parameter because VARCHAR2 parameters are limited to 32767 bytes.
+func toClob(s string) go_ora.Clob {
+	return go_ora.Clob{String: s, Valid: s != ""}
+}
@@ -0,0 +1,217 @@
+// Package oracle is the plugin for Oracle driver.
+// It requires Oracle 12.2 or later, because the migration history table uses the identity column and the index names
+// longer than 30 bytes, and the user schemas are filtered by the ORACLE_MAINTAINED column.
+package oracle
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	go_ora "github.com/sijms/go-ora/v2"
+	"go.uber.org/zap"
+
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/common/log"
+	"github.com/bytebase/bytebase/plugin/db"
+	"github.com/bytebase/bytebase/plugin/db/util"
+)
+
+var (
+	// bytebaseSchema is the schema (user) owning the migration history table.
+	// Oracle doesn't have databases inside an instance, so we map schemas onto Bytebase databases.
+	bytebaseSchema = "BYTEBASE"
+
+	// defaultServiceName is the service name of Oracle Database Express Edition 18c or later which is commonly used in local containers.
+	defaultServiceName = "XE"
+
+	// minimumMajorVersion and minimumMinorVersion is the minimum supported version, i.e. Oracle 12.2.
+	minimumMajorVersion = 12
+	minimumMinorVersion = 2
+
+	_ db.Driver = (*Driver)(nil)
+)
+
+func init() {
+	db.Register(db.Oracle, newDriver)
+}
+
+// Driver is the Oracle driver.
+type Driver struct {
+	connectionCtx db.ConnectionContext
+
+	db *sql.DB
+	// schemaName is the current schema which unqualified statements are executed against.
+	schemaName string
+}
+
+func newDriver(db.DriverConfig) db.Driver {
+	return &Driver{}
+}
+
+// Open opens an Oracle driver.
+// The host can be in the format of host/service_name, e.g. localhost/XEPDB1. The service name is XE if not specified.
+func (driver *Driver) Open(_ context.Context, _ db.Type, config db.ConnectionConfig, connCtx db.ConnectionContext) (db.Driver, error) {
+	host, serviceName := config.Host, defaultServiceName
+	if strings.Contains(config.Host, "/") {
+		parts := strings.Split(config.Host, "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, errors.Errorf("driver.Open() has invalid host %q, should be in the format of host/service_name", config.Host)
+		}
+		host, serviceName = parts[0], parts[1]
+	}
+	port := 1521
+	if config.Port != "" {
+		p, err := strconv.Atoi(config.Port)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid port %q", config.Port)
+		}
+		port = p
+	}
+
+	dsn := go_ora.BuildUrl(host, port, serviceName, config.Username, config.Password, nil)
+	log.Debug("Opening Oracle driver",
+		zap.String("host", host),
+		zap.Int("port", port),
+		zap.String("service", serviceName),
+		zap.String("environment", connCtx.EnvironmentName),
+		zap.String("database", connCtx.InstanceName),
+	)
+	db, err := sql.Open("oracle", dsn)
+	if err != nil {
+		return nil, err
+	}
+	driver.db = db
+	driver.connectionCtx = connCtx
+	driver.schemaName = strings.ToUpper(config.Database)
+	return driver, nil
+}
+
+// Close closes the driver.
+func (driver *Driver) Close(context.Context) error {
+	return driver.db.Close()
+}
+
+// Ping pings the database.
+func (driver *Driver) Ping(ctx context.Context) error {
+	return driver.db.PingContext(ctx)
+}
+
+// GetDBConnection gets a database connection.
+// The database is the schema in Oracle. Since the current schema is a session setting, the statements will be
+// executed after setting the current schema in a transaction which pins the session.
+func (driver *Driver) GetDBConnection(_ context.Context, database string) (*sql.DB, error) {
+	driver.schemaName = strings.ToUpper(database)
+	return driver.db, nil
+}
+
+// getVersion gets the version, and returns an error if the version is not supported.
+func (driver *Driver) getVersion(ctx context.Context) (string, error) {
+	query := "SELECT VERSION FROM PRODUCT_COMPONENT_VERSION WHERE PRODUCT LIKE 'Oracle%' AND ROWNUM = 1"
+	var version string
+	if err := driver.db.QueryRowContext(ctx, query).Scan(&version); err != nil {
+		if err == sql.ErrNoRows {
+			return "", common.FormatDBErrorEmptyRowWithQuery(query)
+		}
+		return "", util.FormatErrorWithQuery(err, query)
+	}
+	if err := checkVersion(version); err != nil {
+		return "", err
+	}
+	return version, nil
+}
+
+// checkVersion checks if the version, e.g. 12.2.0.1.0 or 19.0.0.0.0, is supported.
+func checkVersion(version string) error {
+	parts := strings.Split(version, ".")
+	if len(parts) < 2 {
+		return errors.Errorf("invalid Oracle version %q", version)
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return errors.Wrapf(err, "invalid Oracle version %q", version)
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return errors.Wrapf(err, "invalid Oracle version %q", version)
+	}
+	if major < minimumMajorVersion || (major == minimumMajorVersion && minor < minimumMinorVersion) {
+		return common.Errorf(common.NotImplemented, "Oracle %s is not supported, the minimum supported version is %d.%d", version, minimumMajorVersion, minimumMinorVersion)
+	}
+	return nil
+}
+
+// getSchemas gets all user schemas excluding the ones maintained by Oracle.
+func (driver *Driver) getSchemas(ctx context.Context) ([]string, error) {
+	query := "SELECT USERNAME FROM ALL_USERS WHERE ORACLE_MAINTAINED = 'N' ORDER BY USERNAME"
+	rows, err := driver.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, util.FormatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	var schemas []string
+	for rows.Next() {
+		var schema string
+		if err := rows.Scan(&schema); err != nil {
+			return nil, err
+		}
+		schemas = append(schemas, schema)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, util.FormatErrorWithQuery(err, query)
+	}
+	return schemas, nil
+}
+
+// beginTxWithSchema begins a transaction with the current schema set to the given schema.
+func (driver *Driver) beginTxWithSchema(ctx context.Context, schema string) (*sql.Tx, error) {
+	tx, err := driver.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if schema != "" {
+		stmt := fmt.Sprintf(`ALTER SESSION SET CURRENT_SCHEMA = "%s"`, schema)
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			tx.Rollback()
+			return nil, util.FormatErrorWithQuery(err, stmt)
+		}
+	}
+	return tx, nil
+}
+
+// Execute executes a SQL statement.
+// Note that DDL statements are committed implicitly in Oracle.
+func (driver *Driver) Execute(ctx context.Context, statement string) error {
+	tx, err := driver.beginTxWithSchema(ctx, driver.schemaName)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	f := func(stmt string) error {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+		return nil
+	}
+	if err := applyMultiStatements(strings.NewReader(statement), f); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Query queries a SQL statement.
+func (driver *Driver) Query(ctx context.Context, statement string, limit int) ([]interface{}, error) {
+	tx, err := driver.beginTxWithSchema(ctx, driver.schemaName)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	return util.QueryTx(ctx, db.Oracle, tx, statement, limit)
+}
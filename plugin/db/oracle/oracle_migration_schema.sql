@@ -0,0 +1,56 @@
+-- This is the bytebase schema to track migration info for Oracle
+-- It requires Oracle 12.2 or later for the identity column and the index names longer than 30 bytes.
+-- Oracle doesn't have databases inside an instance, so we create a schema (user) called bytebase.
+-- The account is locked so that nobody can log in as this user, the password is never used.
+CREATE USER bytebase IDENTIFIED BY "Bytebase_Locked_1" ACCOUNT LOCK;
+
+GRANT UNLIMITED TABLESPACE TO bytebase;
+
+-- Create migration_history table
+-- Note, the index names are quoted in lower case so that util.FormatError can recognize the unique constraint violations.
+-- Note, Oracle treats empty strings as NULL, so the columns which may store empty strings are nullable.
+CREATE TABLE bytebase.migration_history (
+    id NUMBER(19) GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    created_by VARCHAR2(1024) NOT NULL,
+    created_ts NUMBER(19) NOT NULL,
+    updated_by VARCHAR2(1024) NOT NULL,
+    updated_ts NUMBER(19) NOT NULL,
+    -- Record the client version creating this migration history. For Bytebase, we use its binary release version. Different Bytebase release might
+    -- record different history info and this field helps to handle such situation properly. Moreover, it helps debugging.
+    release_version VARCHAR2(256) NOT NULL,
+    -- Allows granular tracking of migration history (e.g If an application manages schemas for a multi-tenant service and each tenant has its own schema, that application can use namespace to record the tenant name to track the per-tenant schema migration)
+    -- Since bytebase also manages different application databases from an instance, it leverages this field to track each database migration history.
+    namespace VARCHAR2(256) NOT NULL,
+    -- Used to detect out of order migration together with 'namespace' and 'version' column.
+    sequence NUMBER(19) NOT NULL CHECK (sequence >= 0),
+    -- We call it source because maybe we could load history from other migration tool.
+    -- Current allowed values are UI, VCS, LIBRARY.
+    source VARCHAR2(256) NOT NULL,
+    -- Current allowed values are BASELINE, MIGRATE, MIGRATE_SDL, BRANCH, DATA.
+    type VARCHAR2(256) NOT NULL,
+    -- Current allowed values are PENDING, DONE, FAILED.
+    -- Oracle commits DDL implicitly, so we can't record DDL and migration_history into a single transaction.
+    -- Thus, we create a "PENDING" record before applying the DDL and update that record to "DONE" after applying the DDL.
+    status VARCHAR2(256) NOT NULL,
+    -- Record the migration version.
+    version VARCHAR2(256) NOT NULL,
+    description CLOB,
+    -- Record the migration statement
+    statement CLOB,
+    -- Record the schema after migration
+    "SCHEMA" CLOB,
+    -- Record the schema before migration. Though we could also fetch it from the previous migration history, it would complicate fetching logic.
+    -- Besides, by storing the schema_prev, we can perform consistency check to see if the migration history has any gaps.
+    schema_prev CLOB,
+    execution_duration_ns NUMBER(19) NOT NULL,
+    issue_id VARCHAR2(256),
+    payload CLOB
+);
+
+CREATE UNIQUE INDEX bytebase."bytebase_idx_unique_migration_history_namespace_sequence" ON bytebase.migration_history (namespace, sequence);
+
+CREATE UNIQUE INDEX bytebase."bytebase_idx_unique_migration_history_namespace_version" ON bytebase.migration_history (namespace, version);
+
+CREATE INDEX bytebase."bytebase_idx_migration_history_namespace_source_type" ON bytebase.migration_history (namespace, source, type);
+
+CREATE INDEX bytebase."bytebase_idx_migration_history_namespace_created" ON bytebase.migration_history (namespace, created_ts);
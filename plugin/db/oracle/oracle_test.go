@@ -0,0 +1,156 @@
+package oracle
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bytebase/bytebase/plugin/db"
+)
+
+func TestApplyMultiStatements(t *testing.T) {
+	tests := []struct {
+		statement string
+		want      []string
+	}{
+		{
+			statement: "CREATE TABLE t1 (id NUMBER);\nINSERT INTO t1 VALUES (1);\n",
+			want: []string{
+				"CREATE TABLE t1 (id NUMBER)",
+				"INSERT INTO t1 VALUES (1)",
+			},
+		},
+		{
+			statement: "--\n-- Table structure for T1\n--\nCREATE TABLE \"T1\"\n  (\t\"ID\" NUMBER\n  ) ;\n\n" +
+				"CREATE OR REPLACE EDITIONABLE PROCEDURE \"P1\" AS\nBEGIN\n  INSERT INTO t1 VALUES (1);\n  COMMIT;\nEND;\n/\n\n" +
+				"CREATE OR REPLACE EDITIONABLE TRIGGER \"TR1\" BEFORE INSERT ON t1 FOR EACH ROW\nBEGIN\n  NULL;\nEND;\n/\nALTER TRIGGER \"TR1\" ENABLE;\n",
+			want: []string{
+				"CREATE TABLE \"T1\"\n  (\t\"ID\" NUMBER\n  )",
+				"CREATE OR REPLACE EDITIONABLE PROCEDURE \"P1\" AS\nBEGIN\n  INSERT INTO t1 VALUES (1);\n  COMMIT;\nEND;",
+				"CREATE OR REPLACE EDITIONABLE TRIGGER \"TR1\" BEFORE INSERT ON t1 FOR EACH ROW\nBEGIN\n  NULL;\nEND;",
+				"ALTER TRIGGER \"TR1\" ENABLE",
+			},
+		},
+		{
+			statement: "BEGIN\n  DBMS_OUTPUT.PUT_LINE('a;');\nEND;\n/\nSELECT 1 FROM DUAL",
+			want: []string{
+				"BEGIN\n  DBMS_OUTPUT.PUT_LINE('a;');\nEND;",
+				"SELECT 1 FROM DUAL",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		var got []string
+		err := applyMultiStatements(strings.NewReader(test.statement), func(stmt string) error {
+			got = append(got, stmt)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, test.want, got)
+	}
+}
+
+func TestGetColumnType(t *testing.T) {
+	tests := []struct {
+		dataType   string
+		charLength sql.NullInt64
+		charUsed   sql.NullString
+		precision  sql.NullInt64
+		scale      sql.NullInt64
+		want       string
+	}{
+		{"VARCHAR2", sql.NullInt64{Int64: 255, Valid: true}, sql.NullString{String: "B", Valid: true}, sql.NullInt64{}, sql.NullInt64{}, "VARCHAR2(255)"},
+		{"VARCHAR2", sql.NullInt64{Int64: 255, Valid: true}, sql.NullString{String: "C", Valid: true}, sql.NullInt64{}, sql.NullInt64{}, "VARCHAR2(255 CHAR)"},
+		{"NVARCHAR2", sql.NullInt64{Int64: 64, Valid: true}, sql.NullString{String: "C", Valid: true}, sql.NullInt64{}, sql.NullInt64{}, "NVARCHAR2(64)"},
+		{"NUMBER", sql.NullInt64{}, sql.NullString{}, sql.NullInt64{Int64: 10, Valid: true}, sql.NullInt64{Int64: 2, Valid: true}, "NUMBER(10,2)"},
+		{"NUMBER", sql.NullInt64{}, sql.NullString{}, sql.NullInt64{Int64: 19, Valid: true}, sql.NullInt64{Int64: 0, Valid: true}, "NUMBER(19)"},
+		{"NUMBER", sql.NullInt64{}, sql.NullString{}, sql.NullInt64{}, sql.NullInt64{}, "NUMBER"},
+		{"DATE", sql.NullInt64{}, sql.NullString{}, sql.NullInt64{}, sql.NullInt64{}, "DATE"},
+	}
+
+	for _, test := range tests {
+		got := getColumnType(test.dataType, test.charLength, test.charUsed, test.precision, test.scale)
+		require.Equal(t, test.want, got)
+	}
+}
+
+func TestCheckVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		wantErr bool
+	}{
+		{"11.2.0.2.0", true},
+		{"12.1.0.2.0", true},
+		{"12.2.0.1.0", false},
+		{"19.0.0.0.0", false},
+		{"21.0.0.0.0", false},
+		{"invalid", true},
+	}
+
+	for _, test := range tests {
+		err := checkVersion(test.version)
+		if test.wantErr {
+			require.Error(t, err, test.version)
+		} else {
+			require.NoError(t, err, test.version)
+		}
+	}
+}
+
+// Only for manual test against a local container, e.g.
+// docker run -d -p 1521:1521 -e ORACLE_PASSWORD=<password> gvenzl/oracle-xe:21-slim
+// Should be skipped in CI.
+func TestMigrationAndDump(t *testing.T) {
+	t.Skip()
+	a := require.New(t)
+	ctx := context.Background()
+
+	driver, err := newDriver(db.DriverConfig{}).Open(ctx, db.Oracle, db.ConnectionConfig{
+		Host:     "localhost/XEPDB1",
+		Port:     "1521",
+		Username: "system",
+		Password: os.Getenv("ORACLE_PASSWORD"),
+	}, db.ConnectionContext{})
+	a.NoError(err)
+	defer driver.Close(ctx)
+
+	a.NoError(driver.Execute(ctx, `CREATE USER bb_test IDENTIFIED BY "Test_1234"`))
+	defer driver.Execute(ctx, "DROP USER bb_test CASCADE")
+	a.NoError(driver.SetupMigrationIfNeeded(ctx))
+
+	_, err = driver.GetDBConnection(ctx, "BB_TEST")
+	a.NoError(err)
+	_, _, err = driver.ExecuteMigration(ctx, &db.MigrationInfo{
+		Version:     "0001",
+		Namespace:   "BB_TEST",
+		Database:    "BB_TEST",
+		Environment: "test",
+		Source:      db.UI,
+		Type:        db.Migrate,
+		Creator:     "bytebase",
+	}, "CREATE TABLE t1 (id NUMBER(10) PRIMARY KEY, name VARCHAR2(64 CHAR));\nCOMMENT ON TABLE t1 IS 'test';\n")
+	a.NoError(err)
+
+	namespace := "BB_TEST"
+	list, err := driver.FindMigrationHistoryList(ctx, &db.MigrationHistoryFind{Database: &namespace})
+	a.NoError(err)
+	a.Len(list, 1)
+	a.Equal(db.Done, list[0].Status)
+	a.Contains(list[0].Schema, `CREATE TABLE "T1"`)
+
+	schema, err := driver.SyncDBSchema(ctx, "BB_TEST")
+	a.NoError(err)
+	a.Len(schema.TableList, 1)
+	a.Equal("test", schema.TableList[0].Comment)
+
+	var buf bytes.Buffer
+	_, err = driver.Dump(ctx, "BB_TEST", &buf, true)
+	a.NoError(err)
+	a.Contains(buf.String(), `COMMENT ON TABLE "T1" IS 'test';`)
+}
@@ -0,0 +1,370 @@
+package oracle
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/plugin/db"
+	"github.com/bytebase/bytebase/plugin/db/util"
+)
+
+// SyncInstance syncs the instance.
+func (driver *Driver) SyncInstance(ctx context.Context) (*db.InstanceMeta, error) {
+	version, err := driver.getVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Query user info
+	userList, err := driver.getUserList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Query schema info
+	schemas, err := driver.getSchemas(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var databaseList []db.DatabaseMeta
+	for _, schema := range schemas {
+		// Skip our internal "BYTEBASE" schema
+		if schema == bytebaseSchema {
+			continue
+		}
+		databaseList = append(databaseList, db.DatabaseMeta{Name: schema})
+	}
+
+	return &db.InstanceMeta{
+		Version:      version,
+		UserList:     userList,
+		DatabaseList: databaseList,
+	}, nil
+}
+
+// SyncDBSchema syncs a single database schema.
+func (driver *Driver) SyncDBSchema(ctx context.Context, databaseName string) (*db.Schema, error) {
+	schemas, err := driver.getSchemas(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	found := false
+	for _, schema := range schemas {
+		if schema == databaseName {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, common.Errorf(common.NotFound, "database %q not found", databaseName)
+	}
+
+	txn, err := driver.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer txn.Rollback()
+
+	tableList, err := getTables(ctx, txn, databaseName)
+	if err != nil {
+		return nil, err
+	}
+	viewList, err := getViews(ctx, txn, databaseName)
+	if err != nil {
+		return nil, err
+	}
+	if err := txn.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &db.Schema{
+		Name:      databaseName,
+		TableList: tableList,
+		ViewList:  viewList,
+	}, nil
+}
+
+func (driver *Driver) getUserList(ctx context.Context) ([]db.User, error) {
+	// DBA_ROLE_PRIVS requires the SELECT_CATALOG_ROLE, so we use USER_ROLE_PRIVS which only lists the roles of the current user.
+	query := `
+		SELECT
+			u.USERNAME,
+			NVL((SELECT LISTAGG(r.GRANTED_ROLE, ', ') WITHIN GROUP (ORDER BY r.GRANTED_ROLE) FROM USER_ROLE_PRIVS r WHERE r.USERNAME = u.USERNAME), ' ')
+		FROM ALL_USERS u
+		WHERE u.ORACLE_MAINTAINED = 'N'
+		ORDER BY u.USERNAME`
+	rows, err := driver.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, util.FormatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	var userList []db.User
+	for rows.Next() {
+		var user db.User
+		if err := rows.Scan(
+			&user.Name,
+			&user.Grant,
+		); err != nil {
+			return nil, err
+		}
+		user.Grant = strings.TrimSpace(user.Grant)
+		userList = append(userList, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, util.FormatErrorWithQuery(err, query)
+	}
+	return userList, nil
+}
+
+// getTables gets all tables of the schema.
+func getTables(ctx context.Context, txn *sql.Tx, schema string) ([]db.Table, error) {
+	columnMap, err := getTableColumns(ctx, txn, schema)
+	if err != nil {
+		return nil, err
+	}
+	indexMap, err := getIndexes(ctx, txn, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	// NUM_ROWS and AVG_ROW_LEN are collected by gathering the statistics, so the data size is an estimation.
+	query := `
+		SELECT
+			t.TABLE_NAME,
+			NVL(t.NUM_ROWS, 0),
+			NVL(t.NUM_ROWS, 0) * NVL(t.AVG_ROW_LEN, 0),
+			(o.CREATED - DATE '1970-01-01') * 86400,
+			(o.LAST_DDL_TIME - DATE '1970-01-01') * 86400,
+			c.COMMENTS
+		FROM ALL_TABLES t
+		JOIN ALL_OBJECTS o ON o.OWNER = t.OWNER AND o.OBJECT_NAME = t.TABLE_NAME AND o.OBJECT_TYPE = 'TABLE'
+		LEFT JOIN ALL_TAB_COMMENTS c ON c.OWNER = t.OWNER AND c.TABLE_NAME = t.TABLE_NAME
+		WHERE t.OWNER = :1 AND t.NESTED = 'NO' AND t.SECONDARY = 'N'
+		ORDER BY t.TABLE_NAME`
+	rows, err := txn.QueryContext(ctx, query, schema)
+	if err != nil {
+		return nil, util.FormatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	var tables []db.Table
+	for rows.Next() {
+		var comment sql.NullString
+		var createdTs, updatedTs float64
+		table := db.Table{
+			Type: "BASE TABLE",
+		}
+		if err := rows.Scan(
+			&table.Name,
+			&table.RowCount,
+			&table.DataSize,
+			&createdTs,
+			&updatedTs,
+			&comment,
+		); err != nil {
+			return nil, err
+		}
+		table.CreatedTs, table.UpdatedTs = int64(createdTs), int64(updatedTs)
+		table.Comment = comment.String
+		table.ColumnList = columnMap[table.Name]
+		table.IndexList = indexMap[table.Name]
+		tables = append(tables, table)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, util.FormatErrorWithQuery(err, query)
+	}
+	return tables, nil
+}
+
+// getTableColumns gets the columns of all tables keyed by the table name.
+func getTableColumns(ctx context.Context, txn *sql.Tx, schema string) (map[string][]db.Column, error) {
+	query := `
+		SELECT
+			c.TABLE_NAME,
+			c.COLUMN_NAME,
+			c.COLUMN_ID,
+			c.DATA_DEFAULT,
+			c.NULLABLE,
+			c.DATA_TYPE,
+			c.CHAR_LENGTH,
+			c.CHAR_USED,
+			c.DATA_PRECISION,
+			c.DATA_SCALE,
+			c.CHARACTER_SET_NAME,
+			cc.COMMENTS
+		FROM ALL_TAB_COLUMNS c
+		LEFT JOIN ALL_COL_COMMENTS cc ON cc.OWNER = c.OWNER AND cc.TABLE_NAME = c.TABLE_NAME AND cc.COLUMN_NAME = c.COLUMN_NAME
+		WHERE c.OWNER = :1
+		ORDER BY c.TABLE_NAME, c.COLUMN_ID`
+	rows, err := txn.QueryContext(ctx, query, schema)
+	if err != nil {
+		return nil, util.FormatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	// tableName -> columnList map
+	columnMap := make(map[string][]db.Column)
+	for rows.Next() {
+		var tableName, nullable, dataType string
+		var defaultStr, charUsed, charset, comment sql.NullString
+		var charLength, precision, scale sql.NullInt64
+		var column db.Column
+		if err := rows.Scan(
+			&tableName,
+			&column.Name,
+			&column.Position,
+			&defaultStr,
+			&nullable,
+			&dataType,
+			&charLength,
+			&charUsed,
+			&precision,
+			&scale,
+			&charset,
+			&comment,
+		); err != nil {
+			return nil, err
+		}
+		if defaultStr.Valid {
+			// DATA_DEFAULT is a LONG column which keeps the trailing whitespaces of the definition.
+			d := strings.TrimSpace(defaultStr.String)
+			column.Default = &d
+		}
+		column.Nullable = nullable == "Y"
+		column.Type = getColumnType(dataType, charLength, charUsed, precision, scale)
+		column.CharacterSet = charset.String
+		column.Comment = comment.String
+
+		columnMap[tableName] = append(columnMap[tableName], column)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, util.FormatErrorWithQuery(err, query)
+	}
+	return columnMap, nil
+}
+
+// getColumnType returns the full column type such as "VARCHAR2(255 CHAR)" or "NUMBER(10,2)".
+func getColumnType(dataType string, charLength sql.NullInt64, charUsed sql.NullString, precision, scale sql.NullInt64) string {
+	switch dataType {
+	case "VARCHAR2", "NVARCHAR2", "CHAR", "NCHAR":
+		if !charLength.Valid {
+			return dataType
+		}
+		// NVARCHAR2 and NCHAR are always in characters.
+		if charUsed.String == "C" && (dataType == "VARCHAR2" || dataType == "CHAR") {
+			return fmt.Sprintf("%s(%d CHAR)", dataType, charLength.Int64)
+		}
+		return fmt.Sprintf("%s(%d)", dataType, charLength.Int64)
+	case "NUMBER":
+		if !precision.Valid {
+			return dataType
+		}
+		if scale.Valid && scale.Int64 != 0 {
+			return fmt.Sprintf("%s(%d,%d)", dataType, precision.Int64, scale.Int64)
+		}
+		return fmt.Sprintf("%s(%d)", dataType, precision.Int64)
+	}
+	return dataType
+}
+
+// getIndexes gets the indexes of all tables keyed by the table name.
+func getIndexes(ctx context.Context, txn *sql.Tx, schema string) (map[string][]db.Index, error) {
+	query := `
+		SELECT
+			i.TABLE_NAME,
+			i.INDEX_NAME,
+			ic.COLUMN_NAME,
+			ic.COLUMN_POSITION,
+			i.INDEX_TYPE,
+			i.UNIQUENESS,
+			NVL((SELECT 'Y' FROM ALL_CONSTRAINTS c WHERE c.OWNER = i.OWNER AND c.INDEX_NAME = i.INDEX_NAME AND c.CONSTRAINT_TYPE = 'P'), 'N'),
+			i.VISIBILITY
+		FROM ALL_INDEXES i
+		JOIN ALL_IND_COLUMNS ic ON ic.INDEX_OWNER = i.OWNER AND ic.INDEX_NAME = i.INDEX_NAME
+		WHERE i.OWNER = :1 AND i.TABLE_OWNER = :2
+		ORDER BY i.TABLE_NAME, i.INDEX_NAME, ic.COLUMN_POSITION`
+	rows, err := txn.QueryContext(ctx, query, schema, schema)
+	if err != nil {
+		return nil, util.FormatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	// tableName -> indexList map
+	indexMap := make(map[string][]db.Index)
+	for rows.Next() {
+		var tableName, uniqueness, primary, visibility string
+		var index db.Index
+		if err := rows.Scan(
+			&tableName,
+			&index.Name,
+			&index.Expression,
+			&index.Position,
+			&index.Type,
+			&uniqueness,
+			&primary,
+			&visibility,
+		); err != nil {
+			return nil, err
+		}
+		index.Unique = uniqueness == "UNIQUE"
+		index.Primary = primary == "Y"
+		index.Visible = visibility == "VISIBLE"
+
+		indexMap[tableName] = append(indexMap[tableName], index)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, util.FormatErrorWithQuery(err, query)
+	}
+	return indexMap, nil
+}
+
+// getViews gets all views of the schema.
+func getViews(ctx context.Context, txn *sql.Tx, schema string) ([]db.View, error) {
+	query := `
+		SELECT
+			v.VIEW_NAME,
+			(o.CREATED - DATE '1970-01-01') * 86400,
+			(o.LAST_DDL_TIME - DATE '1970-01-01') * 86400,
+			v.TEXT,
+			c.COMMENTS
+		FROM ALL_VIEWS v
+		JOIN ALL_OBJECTS o ON o.OWNER = v.OWNER AND o.OBJECT_NAME = v.VIEW_NAME AND o.OBJECT_TYPE = 'VIEW'
+		LEFT JOIN ALL_TAB_COMMENTS c ON c.OWNER = v.OWNER AND c.TABLE_NAME = v.VIEW_NAME
+		WHERE v.OWNER = :1
+		ORDER BY v.VIEW_NAME`
+	rows, err := txn.QueryContext(ctx, query, schema)
+	if err != nil {
+		return nil, util.FormatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	var views []db.View
+	for rows.Next() {
+		var definition, comment sql.NullString
+		var createdTs, updatedTs float64
+		var view db.View
+		if err := rows.Scan(
+			&view.Name,
+			&createdTs,
+			&updatedTs,
+			&definition,
+			&comment,
+		); err != nil {
+			return nil, err
+		}
+		view.CreatedTs, view.UpdatedTs = int64(createdTs), int64(updatedTs)
+		view.Definition = definition.String
+		view.Comment = comment.String
+		views = append(views, view)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, util.FormatErrorWithQuery(err, query)
+	}
+	return views, nil
+}
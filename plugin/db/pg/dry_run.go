@@ -0,0 +1,192 @@
+package pg
+
+// This file implements the dry run of migrations.
+// Most DDL statements are transactional in Postgres, so we run the statements one by one in a transaction which is
+// always rolled back. The statement timeout and lock timeout are set for the transaction so that the dry run will
+// not block the workload on the database for long. The whole transaction is bounded by the dry run timeout, and
+// the idle_in_transaction_session_timeout makes the server abort the transaction if we go away in the middle of it.
+// The statements which cannot run inside a transaction block, e.g. CREATE INDEX CONCURRENTLY, are skipped and reported.
+//
+// Sequences are not transactional, so the rollback does not undo the sequence changes. The statements calling
+// nextval() or setval() explicitly are skipped. However, the statements using the sequences implicitly, e.g. inserting
+// rows into a table with serial or identity columns, still advance the sequences, which leaves gaps in the values.
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bytebase/bytebase/plugin/db"
+	"github.com/bytebase/bytebase/plugin/parser"
+)
+
+var (
+	_ db.DryRunExecutor = (*Driver)(nil)
+
+	// nonTransactionalPrefixList is the statement prefixes which cannot run inside a transaction block.
+	nonTransactionalPrefixList = []string{
+		"CREATE DATABASE",
+		"DROP DATABASE",
+		"CREATE TABLESPACE",
+		"DROP TABLESPACE",
+		"ALTER SYSTEM",
+		"VACUUM",
+		"CLUSTER",
+		"CREATE SUBSCRIPTION",
+		"DROP SUBSCRIPTION",
+		"ALTER SUBSCRIPTION",
+	}
+	// transactionControlPrefixList is the statement prefixes which would end the transaction block of the dry run.
+	transactionControlPrefixList = []string{
+		"BEGIN",
+		"START TRANSACTION",
+		"COMMIT",
+		"END",
+		"ROLLBACK",
+		"ABORT",
+		"SAVEPOINT",
+		"RELEASE",
+		"PREPARE TRANSACTION",
+	}
+	// sequenceFunctionList is the functions changing the sequences, which cannot be rolled back.
+	sequenceFunctionList = []string{
+		"NEXTVAL",
+		"SETVAL",
+	}
+)
+
+// DryRun runs the statement in a transaction which is always rolled back.
+func (driver *Driver) DryRun(ctx context.Context, statement string, option db.DryRunOption) (*db.DryRunResult, error) {
+	owner, err := driver.GetCurrentDatabaseOwner()
+	if err != nil {
+		return nil, err
+	}
+	singleSQLs, err := parser.SplitMultiSQL(parser.Postgres, statement)
+	if err != nil {
+		return nil, err
+	}
+
+	if option.Timeout > 0 {
+		var cancel context.CancelFunc
+		// The transaction is rolled back by database/sql once the context is done.
+		ctx, cancel = context.WithTimeout(ctx, option.Timeout)
+		defer cancel()
+	}
+	tx, err := driver.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	// Always roll back the transaction so that the dry run leaves no change.
+	defer tx.Rollback()
+
+	setupStmts := []string{fmt.Sprintf("SET LOCAL ROLE %s", owner)}
+	if option.StatementTimeout > 0 {
+		setupStmts = append(setupStmts, fmt.Sprintf("SET LOCAL statement_timeout = %d", option.StatementTimeout.Milliseconds()))
+	}
+	if option.LockTimeout > 0 {
+		setupStmts = append(setupStmts, fmt.Sprintf("SET LOCAL lock_timeout = %d", option.LockTimeout.Milliseconds()))
+	}
+	if option.Timeout > 0 {
+		setupStmts = append(setupStmts, fmt.Sprintf("SET LOCAL idle_in_transaction_session_timeout = %d", option.Timeout.Milliseconds()))
+	}
+	for _, stmt := range setupStmts {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &db.DryRunResult{}
+	start := time.Now()
+	for _, singleSQL := range singleSQLs {
+		stmt := singleSQL.Text
+		if skipInDryRun(stmt) {
+			result.SkippedStatementList = append(result.SkippedStatementList, strings.TrimSpace(stmt))
+			continue
+		}
+		if isSuperuserStatement(stmt) {
+			if strings.Contains(strings.ToUpper(stmt), "CREATE EVENT TRIGGER") {
+				stmt = strings.ReplaceAll(stmt, "EXECUTE FUNCTION", "EXECUTE PROCEDURE")
+			}
+			stmt = fmt.Sprintf("SET LOCAL ROLE NONE;%sSET LOCAL ROLE %s;", stmt, owner)
+		}
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			result.Statement = strings.TrimSpace(singleSQL.Text)
+			result.Line = singleSQL.LastLine
+			result.Error = err.Error()
+			break
+		}
+	}
+	result.Duration = time.Since(start)
+	return result, nil
+}
+
+// skipInDryRun returns true if the statement cannot run in the transaction block of the dry run.
+func skipInDryRun(stmt string) bool {
+	upperCaseStmt := strings.ToUpper(trimLeadingComments(stmt))
+	if upperCaseStmt == "" {
+		return false
+	}
+	// psql meta-commands like \connect.
+	if strings.HasPrefix(upperCaseStmt, "\\") {
+		return true
+	}
+	for _, prefix := range nonTransactionalPrefixList {
+		if hasKeywordPrefix(upperCaseStmt, prefix) {
+			return true
+		}
+	}
+	for _, prefix := range transactionControlPrefixList {
+		if hasKeywordPrefix(upperCaseStmt, prefix) {
+			return true
+		}
+	}
+	// CREATE INDEX CONCURRENTLY, DROP INDEX CONCURRENTLY, REINDEX ... CONCURRENTLY and
+	// DETACH PARTITION ... CONCURRENTLY cannot run inside a transaction block.
+	if strings.Contains(strings.Join(strings.Fields(upperCaseStmt), " "), " CONCURRENTLY") {
+		return true
+	}
+	// The sequence changes made by nextval() and setval() are not rolled back.
+	compactStmt := strings.Join(strings.Fields(upperCaseStmt), "")
+	for _, function := range sequenceFunctionList {
+		if strings.Contains(compactStmt, function+"(") {
+			return true
+		}
+	}
+	return false
+}
+
+// hasKeywordPrefix returns true if the statement starts with the keywords followed by a word boundary.
+func hasKeywordPrefix(upperCaseStmt, prefix string) bool {
+	if !strings.HasPrefix(upperCaseStmt, prefix) {
+		return false
+	}
+	if len(upperCaseStmt) == len(prefix) {
+		return true
+	}
+	next := upperCaseStmt[len(prefix)]
+	return !(next >= 'A' && next <= 'Z' || next >= '0' && next <= '9' || next == '_')
+}
+
+// trimLeadingComments trims the leading spaces and comments of the statement.
+func trimLeadingComments(stmt string) string {
+	for {
+		stmt = strings.TrimSpace(stmt)
+		switch {
+		case strings.HasPrefix(stmt, "--"):
+			end := strings.Index(stmt, "\n")
+			if end < 0 {
+				return ""
+			}
+			stmt = stmt[end+1:]
+		case strings.HasPrefix(stmt, "/*"):
+			end := strings.Index(stmt, "*/")
+			if end < 0 {
+				return ""
+			}
+			stmt = stmt[end+2:]
+		default:
+			return stmt
+		}
+	}
+}
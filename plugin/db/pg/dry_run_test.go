@@ -0,0 +1,33 @@
+package pg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSkipInDryRun(t *testing.T) {
+	tests := []struct {
+		stmt string
+		want bool
+	}{
+		{stmt: "ALTER TABLE t ADD COLUMN a int;", want: false},
+		{stmt: "-- comment\nCREATE INDEX idx_t_a ON t(a);", want: false},
+		{stmt: "CREATE INDEX CONCURRENTLY idx_t_a ON t(a);", want: true},
+		{stmt: "/* comment */ DROP INDEX\n\tCONCURRENTLY idx_t_a;", want: true},
+		{stmt: "CREATE DATABASE db;", want: true},
+		{stmt: "VACUUM t;", want: true},
+		{stmt: "COMMIT;", want: true},
+		{stmt: "BEGIN;", want: true},
+		{stmt: "BEGINNING_TABLE_INSERT;", want: false},
+		{stmt: "ENDPOINT;", want: false},
+		{stmt: `\connect "db";`, want: true},
+		{stmt: "SELECT setval('t_id_seq', 100);", want: true},
+		{stmt: "INSERT INTO t(id) VALUES (nextval ('t_id_seq'));", want: true},
+		{stmt: "INSERT INTO t(a) VALUES (1);", want: false},
+	}
+
+	for _, test := range tests {
+		require.Equal(t, test.want, skipInDryRun(test.stmt), test.stmt)
+	}
+}
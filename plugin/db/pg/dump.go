@@ -66,6 +66,8 @@ func (driver *Driver) dumpOneDatabaseWithPgDump(ctx context.Context, database st
 	args = append(args, fmt.Sprintf("--port=%s", driver.config.Port))
 	if schemaOnly {
 		args = append(args, "--schema-only")
+		// The archive tables for data migration rollback are not part of the database schema.
+		args = append(args, fmt.Sprintf("--exclude-schema=%s", archiveSchema))
 	}
 	args = append(args, "--inserts")
 	args = append(args, "--use-set-session-authorization")
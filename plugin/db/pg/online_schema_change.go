@@ -0,0 +1,874 @@
+package pg
+
+// This file implements the online schema change for Postgres, which is the counterpart of gh-ost for MySQL.
+// The migration of a table "t" takes the following steps:
+// 1. Create the shadow table "_t_gho" with CREATE TABLE ... (LIKE t INCLUDING ALL), and apply the ALTER TABLE statement to it.
+// 2. Create a trigger on "t" which replays each row change on the shadow table, so that the shadow table is kept in sync.
+// 3. Backfill the shadow table in batches ordered by the primary key. The rows are copied with SELECT ... FOR SHARE,
+//    so a concurrent change either waits for the copy or is copied by the batch, and no change is lost.
+// 4. After the backfill, the trigger keeps the shadow table in sync until the cutover, so the cutover can be postponed.
+// 5. The cutover drops the trigger and swaps the tables in a single transaction under a short lock timeout. The original
+//    table is kept as "_t_del", the same as gh-ost does.
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	pgquery "github.com/pganalyze/pg_query_go/v2"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/bytebase/bytebase/common/log"
+)
+
+const (
+	// oscBatchSize is the number of rows copied to the shadow table in a batch.
+	oscBatchSize = 1000
+	// oscCutoverLockTimeout is the timeout of waiting for the lock on the original table in the cutover.
+	oscCutoverLockTimeout = 3 * time.Second
+	// minOSCServerVersionNum is the minimum server version supporting the identity columns and ON CONFLICT.
+	minOSCServerVersionNum = 100000
+)
+
+// oscQueryer is the common interface of *sql.DB and *sql.Tx used by the online schema change.
+type oscQueryer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// oscColumn is a column of the table in the online schema change.
+type oscColumn struct {
+	name string
+	// dataType is the formatted data type, e.g. "character varying(20)".
+	dataType string
+}
+
+// OnlineSchemaChange migrates a table with an ALTER TABLE statement online, using a shadow table kept in sync by the trigger.
+type OnlineSchemaChange struct {
+	driver   *Driver
+	database string
+	// alterStmt is the parsed ALTER TABLE statement.
+	alterStmt *pgquery.AlterTableStmt
+	// schema is empty until it's resolved by the search path if the statement doesn't specify it.
+	schema string
+	table  string
+
+	rowsEstimate int64
+	rowsCopied   int64
+}
+
+// NewOnlineSchemaChange creates an online schema change on the database with a single ALTER TABLE statement.
+func NewOnlineSchemaChange(driver *Driver, database string, statement string) (*OnlineSchemaChange, error) {
+	alterStmt, err := parseOnlineSchemaChangeStatement(statement)
+	if err != nil {
+		return nil, err
+	}
+	table := alterStmt.Relation.Relname
+	// The shadow table name is the longest one among the generated names.
+	if len(getShadowTableName(table)) > maxIdentifierLength {
+		return nil, errors.Errorf("table name %q is too long for the online schema change", table)
+	}
+	return &OnlineSchemaChange{
+		driver:    driver,
+		database:  database,
+		alterStmt: alterStmt,
+		schema:    alterStmt.Relation.Schemaname,
+		table:     table,
+	}, nil
+}
+
+// parseOnlineSchemaChangeStatement parses the statement and returns the ALTER TABLE statement.
+func parseOnlineSchemaChangeStatement(statement string) (*pgquery.AlterTableStmt, error) {
+	res, err := pgquery.Parse(statement)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse the statement")
+	}
+	if len(res.Stmts) != 1 {
+		return nil, errors.Errorf("online schema change requires exactly one ALTER TABLE statement, but got %d statements", len(res.Stmts))
+	}
+	alterStmt := res.Stmts[0].Stmt.GetAlterTableStmt()
+	if alterStmt == nil || alterStmt.Relkind != pgquery.ObjectType_OBJECT_TABLE {
+		return nil, errors.Errorf("online schema change only supports the ALTER TABLE statement")
+	}
+	if alterStmt.MissingOk {
+		return nil, errors.Errorf("online schema change doesn't support ALTER TABLE IF EXISTS")
+	}
+	return alterStmt, nil
+}
+
+// getShadowAlterStatement returns the ALTER TABLE statement applied to the shadow table.
+func getShadowAlterStatement(alterStmt *pgquery.AlterTableStmt, schema string) (string, error) {
+	relation := &pgquery.RangeVar{
+		Schemaname:     schema,
+		Relname:        getShadowTableName(alterStmt.Relation.Relname),
+		Inh:            true,
+		Relpersistence: alterStmt.Relation.Relpersistence,
+	}
+	shadowStmt := &pgquery.AlterTableStmt{
+		Relation: relation,
+		Cmds:     alterStmt.Cmds,
+		Relkind:  alterStmt.Relkind,
+	}
+	return pgquery.Deparse(&pgquery.ParseResult{
+		Stmts: []*pgquery.RawStmt{
+			{Stmt: &pgquery.Node{Node: &pgquery.Node_AlterTableStmt{AlterTableStmt: shadowStmt}}},
+		},
+	})
+}
+
+func getShadowTableName(table string) string {
+	return fmt.Sprintf("_%s_gho", table)
+}
+
+func getOldTableName(table string) string {
+	return fmt.Sprintf("_%s_del", table)
+}
+
+func getSyncTriggerName(table string) string {
+	return fmt.Sprintf("_%s_gho_sync", table)
+}
+
+// GetProgress returns the estimated total rows and the copied rows of the backfill.
+func (osc *OnlineSchemaChange) GetProgress() (int64, int64) {
+	return atomic.LoadInt64(&osc.rowsEstimate), atomic.LoadInt64(&osc.rowsCopied)
+}
+
+// Check checks if the table can be migrated online, by applying the statement to the shadow table in a transaction which is rolled back.
+func (osc *OnlineSchemaChange) Check(ctx context.Context) error {
+	sqldb, err := osc.getDB(ctx)
+	if err != nil {
+		return err
+	}
+	tx, err := sqldb.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := osc.resolveSchema(ctx, tx); err != nil {
+		return err
+	}
+	// Clean up the shadow table left by a failed migration in the transaction, the sync will do it again.
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("DROP TABLE IF EXISTS %s", osc.qualify(getShadowTableName(osc.table)))); err != nil {
+		return err
+	}
+	_, err = osc.prepareShadowTable(ctx, tx)
+	return err
+}
+
+// Sync creates the shadow table, installs the sync trigger and backfills the shadow table.
+// After Sync returns successfully, the shadow table is kept in sync by the trigger until Cutover.
+func (osc *OnlineSchemaChange) Sync(ctx context.Context) (resErr error) {
+	sqldb, err := osc.getDB(ctx)
+	if err != nil {
+		return err
+	}
+	if err := osc.resolveSchema(ctx, sqldb); err != nil {
+		return err
+	}
+	// Clean up the objects left by a failed migration, so that the sync is idempotent.
+	if err := osc.cleanup(ctx, sqldb); err != nil {
+		return errors.Wrap(err, "failed to clean up the previous online schema change")
+	}
+	defer func() {
+		if resErr == nil {
+			return
+		}
+		// Use a new context because the trigger should be removed from the original table even if the context is canceled.
+		if err := osc.cleanup(context.Background(), sqldb); err != nil {
+			log.Error("failed to clean up the online schema change", zap.String("table", osc.table), zap.Error(err))
+		}
+	}()
+
+	tx, err := sqldb.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	columns, err := osc.prepareShadowTable(ctx, tx)
+	if err != nil {
+		return err
+	}
+	if err := osc.createSyncTrigger(ctx, tx, columns); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	var rowsEstimate int64
+	if err := sqldb.QueryRowContext(ctx, "SELECT GREATEST(reltuples, 0)::bigint FROM pg_class WHERE oid = $1::text::regclass", osc.qualify(osc.table)).Scan(&rowsEstimate); err != nil {
+		return err
+	}
+	atomic.StoreInt64(&osc.rowsEstimate, rowsEstimate)
+	if err := osc.backfill(ctx, sqldb, columns); err != nil {
+		return errors.Wrap(err, "failed to backfill the shadow table")
+	}
+	if _, err := sqldb.ExecContext(ctx, fmt.Sprintf("ANALYZE %s", osc.qualify(getShadowTableName(osc.table)))); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Cutover swaps the original table and the shadow table, the original table is kept as the old table.
+func (osc *OnlineSchemaChange) Cutover(ctx context.Context) error {
+	sqldb, err := osc.getDB(ctx)
+	if err != nil {
+		return err
+	}
+	if err := osc.resolveSchema(ctx, sqldb); err != nil {
+		return err
+	}
+	shadowTable := getShadowTableName(osc.table)
+
+	tx, err := sqldb.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("SET LOCAL lock_timeout = %d", oscCutoverLockTimeout.Milliseconds())); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("LOCK TABLE %s IN ACCESS EXCLUSIVE MODE", osc.qualify(osc.table))); err != nil {
+		return errors.Wrapf(err, "failed to lock table %q for the cutover", osc.table)
+	}
+	var triggerCount int
+	if err := tx.QueryRowContext(ctx, "SELECT count(*) FROM pg_trigger WHERE tgrelid = $1::text::regclass AND tgname = $2", osc.qualify(osc.table), getSyncTriggerName(osc.table)).Scan(&triggerCount); err != nil {
+		return err
+	}
+	if triggerCount == 0 {
+		return errors.Errorf("the shadow table of %q is not in sync, please rerun the sync", osc.table)
+	}
+	if err := osc.dropSyncTrigger(ctx, tx); err != nil {
+		return err
+	}
+
+	columns, err := getOSCColumns(ctx, tx, osc.qualify(shadowTable))
+	if err != nil {
+		return err
+	}
+	// The owner goes first because the sequence must have the same owner as the table it's owned by.
+	if err := osc.copyOwnerAndPrivileges(ctx, tx); err != nil {
+		return err
+	}
+	if err := osc.moveSequences(ctx, tx, columns); err != nil {
+		return err
+	}
+	foreignKeys, err := osc.copyForeignKeys(ctx, tx, columns)
+	if err != nil {
+		return err
+	}
+	if err := osc.swapIndexNames(ctx, tx); err != nil {
+		return err
+	}
+	stmts := []string{
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", osc.qualify(osc.table), quoteIdentifier(getOldTableName(osc.table))),
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", osc.qualify(shadowTable), quoteIdentifier(osc.table)),
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	// The foreign keys are added as NOT VALID in the cutover to keep the lock short.
+	// The validation doesn't block the writes on the table.
+	for _, foreignKey := range foreignKeys {
+		if _, err := sqldb.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s VALIDATE CONSTRAINT %s", osc.qualify(osc.table), quoteIdentifier(foreignKey))); err != nil {
+			return errors.Wrapf(err, "failed to validate foreign key %q after the cutover", foreignKey)
+		}
+	}
+	return nil
+}
+
+// getDB returns the connection to the database, because the driver may have been switched to another database, e.g. for recording the migration history.
+func (osc *OnlineSchemaChange) getDB(ctx context.Context) (*sql.DB, error) {
+	return osc.driver.GetDBConnection(ctx, osc.database)
+}
+
+func (osc *OnlineSchemaChange) resolveSchema(ctx context.Context, q oscQueryer) error {
+	if osc.schema != "" {
+		return nil
+	}
+	var schema sql.NullString
+	if err := q.QueryRowContext(ctx, "SELECT current_schema()").Scan(&schema); err != nil {
+		return err
+	}
+	if !schema.Valid {
+		return errors.Errorf("no schema is selected by the search path")
+	}
+	osc.schema = schema.String
+	return nil
+}
+
+// qualify returns the quoted name of the relation in the schema of the table.
+func (osc *OnlineSchemaChange) qualify(name string) string {
+	return fmt.Sprintf("%s.%s", quoteIdentifier(osc.schema), quoteIdentifier(name))
+}
+
+// prepareShadowTable validates the original table, creates the shadow table and returns the columns copied to the shadow table.
+func (osc *OnlineSchemaChange) prepareShadowTable(ctx context.Context, tx *sql.Tx) ([]*oscColumn, error) {
+	if err := osc.validateTable(ctx, tx); err != nil {
+		return nil, err
+	}
+	shadowAlterStatement, err := getShadowAlterStatement(osc.alterStmt, osc.schema)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to deparse the statement for the shadow table")
+	}
+	stmts := []string{
+		fmt.Sprintf("CREATE TABLE %s (LIKE %s INCLUDING ALL)", osc.qualify(getShadowTableName(osc.table)), osc.qualify(osc.table)),
+		shadowAlterStatement,
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return nil, errors.Wrapf(err, "failed to execute %q", stmt)
+		}
+	}
+
+	primaryKey, err := getOSCPrimaryKey(ctx, tx, osc.qualify(osc.table))
+	if err != nil {
+		return nil, err
+	}
+	shadowPrimaryKey, err := getOSCPrimaryKey(ctx, tx, osc.qualify(getShadowTableName(osc.table)))
+	if err != nil {
+		return nil, err
+	}
+	if !equalOSCColumnNames(primaryKey, shadowPrimaryKey) {
+		return nil, errors.Errorf("online schema change doesn't support changing the primary key of table %q", osc.table)
+	}
+
+	columns, err := getOSCColumns(ctx, tx, osc.qualify(osc.table))
+	if err != nil {
+		return nil, err
+	}
+	shadowColumns, err := getOSCColumns(ctx, tx, osc.qualify(getShadowTableName(osc.table)))
+	if err != nil {
+		return nil, err
+	}
+	// Only the columns existing in both tables are copied, the new columns take their default values.
+	columnMap := make(map[string]bool)
+	for _, column := range columns {
+		columnMap[column.name] = true
+	}
+	var commonColumns []*oscColumn
+	for _, column := range shadowColumns {
+		if columnMap[column.name] {
+			commonColumns = append(commonColumns, column)
+		}
+	}
+	return commonColumns, nil
+}
+
+// validateTable checks the limitations of the online schema change on the original table.
+func (osc *OnlineSchemaChange) validateTable(ctx context.Context, q oscQueryer) error {
+	version, err := getServerVersionNum(ctx, q)
+	if err != nil {
+		return err
+	}
+	if version < minOSCServerVersionNum {
+		return errors.Errorf("online schema change requires Postgres 10 or later")
+	}
+
+	table := osc.qualify(osc.table)
+	var relkind string
+	if err := q.QueryRowContext(ctx, "SELECT relkind::text FROM pg_class WHERE oid = to_regclass($1)", table).Scan(&relkind); err != nil {
+		if err == sql.ErrNoRows {
+			return errors.Errorf("table %q does not exist", table)
+		}
+		return err
+	}
+	if relkind != "r" {
+		return errors.Errorf("online schema change only supports ordinary tables, but %q is of relkind %q", table, relkind)
+	}
+
+	primaryKey, err := getOSCPrimaryKey(ctx, q, table)
+	if err != nil {
+		return err
+	}
+	if len(primaryKey) == 0 {
+		return errors.Errorf("online schema change requires a primary key on table %q", table)
+	}
+
+	var oldTableExists bool
+	if err := q.QueryRowContext(ctx, "SELECT to_regclass($1) IS NOT NULL", osc.qualify(getOldTableName(osc.table))).Scan(&oldTableExists); err != nil {
+		return err
+	}
+	if oldTableExists {
+		return errors.Errorf("table %q exists, please drop it after confirming the previous online schema change", osc.qualify(getOldTableName(osc.table)))
+	}
+
+	// The tables are swapped by renaming, while these objects refer to the original table by OID and would keep referring to the old table.
+	dependentQueries := []struct {
+		query       string
+		args        []interface{}
+		description string
+	}{
+		{
+			query: `SELECT count(DISTINCT r.ev_class) FROM pg_depend d JOIN pg_rewrite r ON d.objid = r.oid
+				WHERE d.classid = 'pg_rewrite'::regclass AND d.refobjid = $1::text::regclass AND r.ev_class <> $1::text::regclass`,
+			description: "views",
+		},
+		{
+			query:       "SELECT count(*) FROM pg_constraint WHERE contype = 'f' AND confrelid = $1::text::regclass AND conrelid <> $1::text::regclass",
+			description: "foreign keys referencing it",
+		},
+		{
+			// The sync trigger may be left by a failed migration, which will be cleaned up by the sync.
+			query:       "SELECT count(*) FROM pg_trigger WHERE tgrelid = $1::text::regclass AND NOT tgisinternal AND tgname <> $2",
+			args:        []interface{}{getSyncTriggerName(osc.table)},
+			description: "triggers",
+		},
+		{
+			query:       "SELECT count(*) FROM pg_policy WHERE polrelid = $1::text::regclass",
+			description: "row security policies",
+		},
+		{
+			query:       "SELECT count(*) FROM pg_inherits WHERE inhrelid = $1::text::regclass OR inhparent = $1::text::regclass",
+			description: "inheritance",
+		},
+	}
+	for _, dependentQuery := range dependentQueries {
+		var count int
+		args := append([]interface{}{table}, dependentQuery.args...)
+		if err := q.QueryRowContext(ctx, dependentQuery.query, args...).Scan(&count); err != nil {
+			return err
+		}
+		if count > 0 {
+			return errors.Errorf("online schema change doesn't support table %q with %s", table, dependentQuery.description)
+		}
+	}
+	return nil
+}
+
+// createSyncTrigger creates the trigger which replays the row changes of the original table on the shadow table.
+// An UPDATE is replayed as deleting the old row and inserting the new row, so it works no matter whether the row has been copied.
+func (osc *OnlineSchemaChange) createSyncTrigger(ctx context.Context, tx *sql.Tx, columns []*oscColumn) error {
+	primaryKey, err := getOSCPrimaryKey(ctx, tx, osc.qualify(osc.table))
+	if err != nil {
+		return err
+	}
+	overriding, err := getOverridingClause(ctx, tx, osc.qualify(getShadowTableName(osc.table)))
+	if err != nil {
+		return err
+	}
+	var oldKeys, newValues []string
+	for _, column := range primaryKey {
+		oldKeys = append(oldKeys, "OLD."+quoteIdentifier(column.name))
+	}
+	for _, column := range columns {
+		newValues = append(newValues, "NEW."+quoteIdentifier(column.name))
+	}
+	shadowTable := osc.qualify(getShadowTableName(osc.table))
+	syncName := osc.qualify(getSyncTriggerName(osc.table))
+	// SECURITY DEFINER grants the privileges on the shadow table to the users changing the original table.
+	function := fmt.Sprintf(`CREATE FUNCTION %s() RETURNS trigger LANGUAGE plpgsql SECURITY DEFINER AS $bbosc$
+BEGIN
+	IF TG_OP IN ('UPDATE', 'DELETE') THEN
+		DELETE FROM %s WHERE (%s) = (%s);
+	END IF;
+	IF TG_OP IN ('INSERT', 'UPDATE') THEN
+		INSERT INTO %s (%s)%s VALUES (%s) ON CONFLICT DO NOTHING;
+	END IF;
+	RETURN NULL;
+END;
+$bbosc$`,
+		syncName,
+		shadowTable, joinOSCColumnNames(primaryKey), strings.Join(oldKeys, ", "),
+		shadowTable, joinOSCColumnNames(columns), overriding, strings.Join(newValues, ", "),
+	)
+	// Use EXECUTE PROCEDURE to support the versions before Postgres 11.
+	trigger := fmt.Sprintf("CREATE TRIGGER %s AFTER INSERT OR UPDATE OR DELETE ON %s FOR EACH ROW EXECUTE PROCEDURE %s()",
+		quoteIdentifier(getSyncTriggerName(osc.table)), osc.qualify(osc.table), syncName)
+	for _, stmt := range []string{function, trigger} {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return errors.Wrap(err, "failed to create the sync trigger")
+		}
+	}
+	return nil
+}
+
+func (osc *OnlineSchemaChange) dropSyncTrigger(ctx context.Context, q oscQueryer) error {
+	stmts := []string{
+		fmt.Sprintf("DROP TRIGGER IF EXISTS %s ON %s", quoteIdentifier(getSyncTriggerName(osc.table)), osc.qualify(osc.table)),
+		fmt.Sprintf("DROP FUNCTION IF EXISTS %s()", osc.qualify(getSyncTriggerName(osc.table))),
+	}
+	for _, stmt := range stmts {
+		if _, err := q.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (osc *OnlineSchemaChange) cleanup(ctx context.Context, q oscQueryer) error {
+	if err := osc.dropSyncTrigger(ctx, q); err != nil {
+		return err
+	}
+	_, err := q.ExecContext(ctx, fmt.Sprintf("DROP TABLE IF EXISTS %s", osc.qualify(getShadowTableName(osc.table))))
+	return err
+}
+
+// backfill copies the rows to the shadow table in batches ordered by the primary key.
+func (osc *OnlineSchemaChange) backfill(ctx context.Context, sqldb *sql.DB, columns []*oscColumn) error {
+	primaryKey, err := getOSCPrimaryKey(ctx, sqldb, osc.qualify(osc.table))
+	if err != nil {
+		return err
+	}
+	overriding, err := getOverridingClause(ctx, sqldb, osc.qualify(getShadowTableName(osc.table)))
+	if err != nil {
+		return err
+	}
+	firstBatch, nextBatch := getBackfillStatements(osc.qualify(osc.table), osc.qualify(getShadowTableName(osc.table)), columns, primaryKey, overriding)
+
+	var lastKey []interface{}
+	for {
+		stmt := nextBatch
+		if lastKey == nil {
+			stmt = firstBatch
+		}
+		var count int64
+		key := make([]sql.NullString, len(primaryKey))
+		dest := []interface{}{&count}
+		for i := range key {
+			dest = append(dest, &key[i])
+		}
+		if err := sqldb.QueryRowContext(ctx, stmt, lastKey...).Scan(dest...); err != nil {
+			if err == sql.ErrNoRows {
+				return nil
+			}
+			return err
+		}
+		atomic.AddInt64(&osc.rowsCopied, count)
+		lastKey = nil
+		for _, k := range key {
+			lastKey = append(lastKey, k.String)
+		}
+	}
+}
+
+// getBackfillStatements returns the statements copying the first batch and the next batch after the last copied primary key.
+// The statements return the number of copied rows and the last primary key as text, and no row if there's nothing to copy.
+func getBackfillStatements(table, shadowTable string, columns, primaryKey []*oscColumn, overriding string) (string, string) {
+	var lastKeys, params []string
+	for i, column := range primaryKey {
+		lastKeys = append(lastKeys, fmt.Sprintf("%s::text", quoteIdentifier(column.name)))
+		params = append(params, fmt.Sprintf("$%d::text::%s", i+1, column.dataType))
+	}
+	var orderBy, orderByDesc []string
+	for _, column := range primaryKey {
+		orderBy = append(orderBy, quoteIdentifier(column.name))
+		orderByDesc = append(orderByDesc, quoteIdentifier(column.name)+" DESC")
+	}
+	columnNames := joinOSCColumnNames(columns)
+	format := `WITH batch AS (
+	SELECT %s FROM %s%s ORDER BY %s LIMIT %d FOR SHARE
+), copied AS (
+	INSERT INTO %s (%s)%s SELECT %s FROM batch ON CONFLICT DO NOTHING
+)
+SELECT (SELECT count(*) FROM batch), %s FROM batch ORDER BY %s LIMIT 1`
+	getStatement := func(where string) string {
+		return fmt.Sprintf(format,
+			columnNames, table, where, strings.Join(orderBy, ", "), oscBatchSize,
+			shadowTable, columnNames, overriding, columnNames,
+			strings.Join(lastKeys, ", "), strings.Join(orderByDesc, ", "),
+		)
+	}
+	return getStatement(""), getStatement(fmt.Sprintf(" WHERE (%s) > (%s)", joinOSCColumnNames(primaryKey), strings.Join(params, ", ")))
+}
+
+// moveSequences keeps the sequences working after the tables are swapped.
+// The serial sequences owned by the original table are moved to the shadow table, and the identity sequences of the shadow table are set to the original values.
+func (osc *OnlineSchemaChange) moveSequences(ctx context.Context, tx *sql.Tx, columns []*oscColumn) error {
+	shadowTable := osc.qualify(getShadowTableName(osc.table))
+	for _, column := range columns {
+		var sequence, shadowSequence sql.NullString
+		if err := tx.QueryRowContext(ctx, "SELECT pg_get_serial_sequence($1, $2), pg_get_serial_sequence($3, $2)", osc.qualify(osc.table), column.name, shadowTable).Scan(&sequence, &shadowSequence); err != nil {
+			return err
+		}
+		if !sequence.Valid {
+			continue
+		}
+		var stmt string
+		switch {
+		case !shadowSequence.Valid:
+			stmt = fmt.Sprintf("ALTER SEQUENCE %s OWNED BY %s.%s", sequence.String, shadowTable, quoteIdentifier(column.name))
+		case shadowSequence.String != sequence.String:
+			stmt = fmt.Sprintf("SELECT setval('%s'::regclass, last_value, is_called) FROM %s", strings.ReplaceAll(shadowSequence.String, "'", "''"), sequence.String)
+		default:
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return errors.Wrapf(err, "failed to move the sequence of column %q", column.name)
+		}
+	}
+	return nil
+}
+
+// copyForeignKeys adds the foreign keys of the original table to the shadow table as NOT VALID, since CREATE TABLE ... LIKE doesn't copy them.
+// The foreign keys on the dropped columns are skipped. It returns the names of the added foreign keys.
+func (osc *OnlineSchemaChange) copyForeignKeys(ctx context.Context, tx *sql.Tx, columns []*oscColumn) ([]string, error) {
+	columnMap := make(map[string]bool)
+	for _, column := range columns {
+		columnMap[column.name] = true
+	}
+	query := `SELECT c.conname, pg_get_constraintdef(c.oid), array_to_string(array_agg(a.attname), ',')
+		FROM pg_constraint c JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = ANY(c.conkey)
+		WHERE c.contype = 'f' AND c.conrelid = $1::text::regclass
+		GROUP BY c.oid, c.conname`
+	rows, err := tx.QueryContext(ctx, query, osc.qualify(osc.table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var stmts, foreignKeys []string
+	for rows.Next() {
+		var name, definition, keyColumns string
+		if err := rows.Scan(&name, &definition, &keyColumns); err != nil {
+			return nil, err
+		}
+		exist := true
+		for _, column := range strings.Split(keyColumns, ",") {
+			if !columnMap[column] {
+				exist = false
+				break
+			}
+		}
+		if !exist {
+			continue
+		}
+		stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s %s NOT VALID", osc.qualify(getShadowTableName(osc.table)), quoteIdentifier(name), definition))
+		foreignKeys = append(foreignKeys, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return nil, errors.Wrapf(err, "failed to copy the foreign key with %q", stmt)
+		}
+	}
+	return foreignKeys, nil
+}
+
+// copyOwnerAndPrivileges sets the owner and the privileges of the shadow table the same as the original table.
+func (osc *OnlineSchemaChange) copyOwnerAndPrivileges(ctx context.Context, tx *sql.Tx) error {
+	shadowTable := osc.qualify(getShadowTableName(osc.table))
+	var owner string
+	if err := tx.QueryRowContext(ctx, "SELECT pg_get_userbyid(relowner) FROM pg_class WHERE oid = $1::text::regclass", osc.qualify(osc.table)).Scan(&owner); err != nil {
+		return err
+	}
+	stmts := []string{fmt.Sprintf("ALTER TABLE %s OWNER TO %s", shadowTable, quoteIdentifier(owner))}
+
+	query := `SELECT CASE WHEN acl.grantee = 0 THEN 'PUBLIC' ELSE quote_ident(pg_get_userbyid(acl.grantee)) END, acl.privilege_type, acl.is_grantable
+		FROM pg_class c, aclexplode(c.relacl) acl
+		WHERE c.oid = $1::text::regclass AND acl.grantee <> c.relowner`
+	rows, err := tx.QueryContext(ctx, query, osc.qualify(osc.table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var grantee, privilege string
+		var grantable bool
+		if err := rows.Scan(&grantee, &privilege, &grantable); err != nil {
+			return err
+		}
+		stmt := fmt.Sprintf("GRANT %s ON %s TO %s", privilege, shadowTable, grantee)
+		if grantable {
+			stmt += " WITH GRANT OPTION"
+		}
+		stmts = append(stmts, stmt)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return errors.Wrapf(err, "failed to copy the privileges with %q", stmt)
+		}
+	}
+	return nil
+}
+
+// swapIndexNames renames the indexes of the shadow table to the names of the same indexes on the original table.
+// The indexes of the original table are renamed with the "_del" suffix.
+func (osc *OnlineSchemaChange) swapIndexNames(ctx context.Context, tx *sql.Tx) error {
+	indexes, err := getOSCIndexes(ctx, tx, osc.qualify(osc.table))
+	if err != nil {
+		return err
+	}
+	shadowIndexes, err := getOSCIndexes(ctx, tx, osc.qualify(getShadowTableName(osc.table)))
+	if err != nil {
+		return err
+	}
+	var stmts []string
+	for _, index := range indexes {
+		shadowIndex, ok := shadowIndexes[index.definition]
+		if !ok {
+			continue
+		}
+		delete(shadowIndexes, index.definition)
+		oldName := fmt.Sprintf("%s_del", index.name)
+		if len(oldName) > maxIdentifierLength {
+			oldName = fmt.Sprintf("%s_del", index.name[:maxIdentifierLength-len("_del")])
+		}
+		stmts = append(stmts,
+			fmt.Sprintf("ALTER INDEX %s RENAME TO %s", osc.qualify(index.name), quoteIdentifier(oldName)),
+			fmt.Sprintf("ALTER INDEX %s RENAME TO %s", osc.qualify(shadowIndex.name), quoteIdentifier(index.name)),
+		)
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return errors.Wrapf(err, "failed to rename index with %q", stmt)
+		}
+	}
+	return nil
+}
+
+// oscIndex is an index of the table in the online schema change.
+type oscIndex struct {
+	name string
+	// definition is the index definition without the index name and the table name, e.g. "UNIQUE USING btree (a, b)".
+	definition string
+}
+
+// getOSCIndexes returns the indexes of the table keyed by the definitions.
+func getOSCIndexes(ctx context.Context, q oscQueryer, table string) (map[string]*oscIndex, error) {
+	query := `SELECT i.relname, x.indisunique, x.indisprimary, pg_get_indexdef(x.indexrelid)
+		FROM pg_index x JOIN pg_class i ON i.oid = x.indexrelid
+		WHERE x.indrelid = $1::text::regclass
+		ORDER BY i.relname`
+	rows, err := q.QueryContext(ctx, query, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	indexes := make(map[string]*oscIndex)
+	for rows.Next() {
+		var name, indexDef string
+		var unique, primary bool
+		if err := rows.Scan(&name, &unique, &primary, &indexDef); err != nil {
+			return nil, err
+		}
+		definition := getIndexDefinitionKey(indexDef, unique, primary)
+		if _, ok := indexes[definition]; ok {
+			continue
+		}
+		indexes[definition] = &oscIndex{name: name, definition: definition}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return indexes, nil
+}
+
+// getIndexDefinitionKey returns the index definition without the index name and the table name,
+// so that the same indexes on the original table and the shadow table have the same key.
+func getIndexDefinitionKey(indexDef string, unique, primary bool) string {
+	definition := indexDef
+	if i := strings.Index(indexDef, " USING "); i >= 0 {
+		definition = indexDef[i+1:]
+	}
+	if unique {
+		definition = "UNIQUE " + definition
+	}
+	if primary {
+		definition = "PRIMARY " + definition
+	}
+	return definition
+}
+
+// getOSCPrimaryKey returns the primary key columns of the table in the key order.
+func getOSCPrimaryKey(ctx context.Context, q oscQueryer, table string) ([]*oscColumn, error) {
+	query := `SELECT a.attname, format_type(a.atttypid, a.atttypmod)
+		FROM pg_index x, unnest(x.indkey) WITH ORDINALITY AS k(attnum, position), pg_attribute a
+		WHERE x.indrelid = $1::text::regclass AND x.indisprimary AND a.attrelid = x.indrelid AND a.attnum = k.attnum
+		ORDER BY k.position`
+	return queryOSCColumns(ctx, q, query, table)
+}
+
+// getOSCColumns returns the columns which can be inserted to the table, i.e. the generated columns are excluded.
+func getOSCColumns(ctx context.Context, q oscQueryer, table string) ([]*oscColumn, error) {
+	version, err := getServerVersionNum(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	query := `SELECT a.attname, format_type(a.atttypid, a.atttypmod)
+		FROM pg_attribute a
+		WHERE a.attrelid = $1::text::regclass AND a.attnum > 0 AND NOT a.attisdropped`
+	// The generated columns are added in Postgres 12.
+	if version >= 120000 {
+		query += " AND a.attgenerated = ''"
+	}
+	query += " ORDER BY a.attnum"
+	return queryOSCColumns(ctx, q, query, table)
+}
+
+func queryOSCColumns(ctx context.Context, q oscQueryer, query string, table string) ([]*oscColumn, error) {
+	rows, err := q.QueryContext(ctx, query, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var columns []*oscColumn
+	for rows.Next() {
+		column := &oscColumn{}
+		if err := rows.Scan(&column.name, &column.dataType); err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return columns, nil
+}
+
+// getOverridingClause returns the OVERRIDING SYSTEM VALUE clause if the table has GENERATED ALWAYS identity columns, which reject the explicit values otherwise.
+func getOverridingClause(ctx context.Context, q oscQueryer, table string) (string, error) {
+	var count int
+	if err := q.QueryRowContext(ctx, "SELECT count(*) FROM pg_attribute WHERE attrelid = $1::text::regclass AND attidentity = 'a'", table).Scan(&count); err != nil {
+		return "", err
+	}
+	if count > 0 {
+		return " OVERRIDING SYSTEM VALUE", nil
+	}
+	return "", nil
+}
+
+// getServerVersionNum returns the version number of the Postgres server, e.g. 140005 for 14.5.
+func getServerVersionNum(ctx context.Context, q oscQueryer) (int, error) {
+	var version int
+	if err := q.QueryRowContext(ctx, "SELECT current_setting('server_version_num')::int").Scan(&version); err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+func joinOSCColumnNames(columns []*oscColumn) string {
+	var names []string
+	for _, column := range columns {
+		names = append(names, quoteIdentifier(column.name))
+	}
+	return strings.Join(names, ", ")
+}
+
+func equalOSCColumnNames(a, b []*oscColumn) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].name != b[i].name {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,77 @@
+package pg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOnlineSchemaChangeStatement(t *testing.T) {
+	tests := []struct {
+		statement string
+		schema    string
+		table     string
+		err       bool
+	}{
+		{statement: "ALTER TABLE t ADD COLUMN a int;", table: "t"},
+		{statement: `ALTER TABLE "S"."T" ALTER COLUMN a TYPE bigint, ADD COLUMN b text;`, schema: "S", table: "T"},
+		{statement: "ALTER TABLE t RENAME COLUMN a TO b;", err: true},
+		{statement: "ALTER TABLE IF EXISTS t ADD COLUMN a int;", err: true},
+		{statement: "ALTER INDEX idx_t_a RENAME TO idx_t_b;", err: true},
+		{statement: "ALTER TABLE t ADD COLUMN a int; ALTER TABLE t ADD COLUMN b int;", err: true},
+	}
+
+	for _, test := range tests {
+		alterStmt, err := parseOnlineSchemaChangeStatement(test.statement)
+		if test.err {
+			require.Error(t, err, test.statement)
+			continue
+		}
+		require.NoError(t, err, test.statement)
+		require.Equal(t, test.schema, alterStmt.Relation.Schemaname, test.statement)
+		require.Equal(t, test.table, alterStmt.Relation.Relname, test.statement)
+	}
+}
+
+func TestGetShadowAlterStatement(t *testing.T) {
+	alterStmt, err := parseOnlineSchemaChangeStatement("ALTER TABLE t ADD COLUMN a int NOT NULL DEFAULT 0, ALTER COLUMN b TYPE bigint;")
+	require.NoError(t, err)
+	statement, err := getShadowAlterStatement(alterStmt, "public")
+	require.NoError(t, err)
+	require.Equal(t, "ALTER TABLE public._t_gho ADD COLUMN a int NOT NULL DEFAULT 0, ALTER COLUMN b TYPE bigint", statement)
+}
+
+func TestGetBackfillStatements(t *testing.T) {
+	columns := []*oscColumn{
+		{name: "id", dataType: "integer"},
+		{name: "tenant", dataType: "text"},
+		{name: "name", dataType: "character varying(20)"},
+	}
+	primaryKey := columns[:2]
+	firstBatch, nextBatch := getBackfillStatements(`"public"."t"`, `"public"."_t_gho"`, columns, primaryKey, " OVERRIDING SYSTEM VALUE")
+	require.Equal(t, `WITH batch AS (
+	SELECT "id", "tenant", "name" FROM "public"."t" ORDER BY "id", "tenant" LIMIT 1000 FOR SHARE
+), copied AS (
+	INSERT INTO "public"."_t_gho" ("id", "tenant", "name") OVERRIDING SYSTEM VALUE SELECT "id", "tenant", "name" FROM batch ON CONFLICT DO NOTHING
+)
+SELECT (SELECT count(*) FROM batch), "id"::text, "tenant"::text FROM batch ORDER BY "id" DESC, "tenant" DESC LIMIT 1`, firstBatch)
+	require.Equal(t, `WITH batch AS (
+	SELECT "id", "tenant", "name" FROM "public"."t" WHERE ("id", "tenant") > ($1::text::integer, $2::text::text) ORDER BY "id", "tenant" LIMIT 1000 FOR SHARE
+), copied AS (
+	INSERT INTO "public"."_t_gho" ("id", "tenant", "name") OVERRIDING SYSTEM VALUE SELECT "id", "tenant", "name" FROM batch ON CONFLICT DO NOTHING
+)
+SELECT (SELECT count(*) FROM batch), "id"::text, "tenant"::text FROM batch ORDER BY "id" DESC, "tenant" DESC LIMIT 1`, nextBatch)
+}
+
+func TestGetIndexDefinitionKey(t *testing.T) {
+	a := require.New(t)
+	a.Equal(
+		getIndexDefinitionKey("CREATE UNIQUE INDEX t_pkey ON public.t USING btree (id)", true, true),
+		getIndexDefinitionKey("CREATE UNIQUE INDEX _t_gho_pkey ON public._t_gho USING btree (id)", true, true),
+	)
+	a.Equal("USING btree (a) WHERE (a > 0)", getIndexDefinitionKey("CREATE INDEX idx_t_a ON public.t USING btree (a) WHERE (a > 0)", false, false))
+	a.NotEqual(
+		getIndexDefinitionKey("CREATE UNIQUE INDEX t_a_key ON public.t USING btree (a)", true, false),
+		getIndexDefinitionKey("CREATE INDEX t_a_idx ON public.t USING btree (a)", false, false),
+	)
+}
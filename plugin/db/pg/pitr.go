@@ -0,0 +1,580 @@
+package pg
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/common/log"
+	"github.com/bytebase/bytebase/plugin/db"
+	"github.com/bytebase/bytebase/plugin/db/util"
+	"github.com/bytebase/bytebase/plugin/storage"
+	"github.com/bytebase/bytebase/resources/postgres"
+	"github.com/bytebase/bytebase/resources/utils"
+)
+
+// The PITR for PostgreSQL is based on the continuous archiving of the WAL.
+// We take base backups with pg_basebackup and stream the WAL with pg_receivewal. To recover a database to a point in time,
+// we start a temporary cluster with the bundled binaries from the base backup, replay the WAL up to the target time,
+// and then dump the database from the temporary cluster and restore it to the target database.
+// Please refer to https://www.postgresql.org/docs/14/continuous-archiving.html for details.
+
+const (
+	// bundledMajorVersion is the major version of the bundled PostgreSQL binaries.
+	// A base backup can only be recovered by the binaries of the same major version.
+	bundledMajorVersion = 14
+	// walArchiveSlotName is the physical replication slot used by pg_receivewal if the instance opts in to it,
+	// so that the server retains the WAL until it's archived.
+	walArchiveSlotName = "bytebase_wal_archive"
+	// walSegmentNameLength is the length of WAL segment file names, e.g., 000000010000000000000002.
+	walSegmentNameLength = 24
+	// walPartialSuffix is the suffix of the WAL segment being received by pg_receivewal.
+	walPartialSuffix = ".partial"
+	// walHistorySuffix is the suffix of the timeline history files.
+	walHistorySuffix = ".history"
+)
+
+// hotStandbySettingList is the settings which must be no less than those on the primary server to start a hot standby.
+var hotStandbySettingList = []string{
+	"max_connections",
+	"max_worker_processes",
+	"max_wal_senders",
+	"max_prepared_transactions",
+	"max_locks_per_transaction",
+}
+
+// CheckWALArchivingForPITR checks the prerequisites of archiving the WAL for PITR.
+func (driver *Driver) CheckWALArchivingForPITR(ctx context.Context) error {
+	versionNum, err := driver.showSetting(ctx, "server_version_num")
+	if err != nil {
+		return err
+	}
+	version, err := strconv.Atoi(versionNum)
+	if err != nil {
+		return errors.Wrapf(err, "invalid server_version_num %q", versionNum)
+	}
+	if version/10000 != bundledMajorVersion {
+		return errors.Errorf("PITR requires PostgreSQL %d, but the server version is %d.%d", bundledMajorVersion, version/10000, version%10000)
+	}
+
+	walLevel, err := driver.showSetting(ctx, "wal_level")
+	if err != nil {
+		return err
+	}
+	if walLevel != "replica" && walLevel != "logical" {
+		return errors.Errorf("wal_level must be \"replica\" or \"logical\" to archive the WAL for PITR, but got %q", walLevel)
+	}
+
+	maxWALSenders, err := driver.showSetting(ctx, "max_wal_senders")
+	if err != nil {
+		return err
+	}
+	if maxWALSenders == "0" {
+		return errors.Errorf("max_wal_senders must be greater than 0 to stream the WAL for PITR")
+	}
+
+	const query = "SELECT rolsuper OR rolreplication FROM pg_roles WHERE rolname = current_user"
+	var canReplicate bool
+	if err := driver.db.QueryRowContext(ctx, query).Scan(&canReplicate); err != nil {
+		return util.FormatErrorWithQuery(err, query)
+	}
+	if !canReplicate {
+		return errors.Errorf("user %q must have the REPLICATION attribute to take base backups and stream the WAL for PITR", driver.config.Username)
+	}
+	return nil
+}
+
+// BaseBackup takes a base backup of the whole instance in tar format with pg_basebackup.
+// It returns the name of the WAL segment at the beginning of the base backup, the earlier segments are not needed to recover from it.
+func (driver *Driver) BaseBackup(ctx context.Context, out io.Writer) (string, error) {
+	const query = "SELECT pg_walfile_name(pg_current_wal_lsn())"
+	var startWALFileName string
+	if err := driver.db.QueryRowContext(ctx, query).Scan(&startWALFileName); err != nil {
+		return "", util.FormatErrorWithQuery(err, query)
+	}
+
+	// The WAL generated during the backup is included by "--wal-method=fetch", so the base backup is consistent by itself.
+	cmd := driver.pgUtilityCommand(ctx, "pg_basebackup", "--pgdata=-", "--format=tar", "--wal-method=fetch", "--checkpoint=fast")
+	var stderr bytes.Buffer
+	cmd.Stdout = out
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", errors.Wrapf(err, "failed to take base backup, error message: %s", stderr.String())
+	}
+	return startWALFileName, nil
+}
+
+// ReceiveWAL streams the WAL of the instance to walDir with pg_receivewal until the context is canceled or the connection is lost.
+// Without a replication slot, the server may recycle the WAL not yet received while the streaming stops, and the WAL
+// can only be replayed from the base backups taken after the gap. With replicationSlot, the server retains the WAL
+// until it's received, which requires max_slot_wal_keep_size to bound the WAL retained while Bytebase is down.
+// The slot is dropped by DropWALArchiveSlot once the instance no longer archives the WAL with it.
+func (driver *Driver) ReceiveWAL(ctx context.Context, walDir string, replicationSlot bool) error {
+	if err := os.MkdirAll(walDir, os.ModePerm); err != nil {
+		return errors.Wrapf(err, "failed to create WAL directory %q", walDir)
+	}
+
+	args := []string{fmt.Sprintf("--directory=%s", walDir), "--synchronous", "--no-loop"}
+	var stderr bytes.Buffer
+	if replicationSlot {
+		if err := driver.checkWALArchiveSlot(ctx); err != nil {
+			return err
+		}
+		slot := fmt.Sprintf("--slot=%s", walArchiveSlotName)
+		createSlot := driver.pgUtilityCommand(ctx, "pg_receivewal", "--create-slot", "--if-not-exists", slot)
+		createSlot.Stderr = &stderr
+		if err := createSlot.Run(); err != nil {
+			return errors.Wrapf(err, "failed to create replication slot %q, error message: %s", walArchiveSlotName, stderr.String())
+		}
+		stderr.Reset()
+		args = append(args, slot)
+	}
+
+	receive := driver.pgUtilityCommand(ctx, "pg_receivewal", args...)
+	receive.Stderr = &stderr
+	log.Debug("Start receiving WAL", zap.String("instance", driver.connectionCtx.InstanceName), zap.String("walDir", walDir), zap.Bool("replicationSlot", replicationSlot))
+	if err := receive.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to receive WAL, error message: %s", stderr.String())
+	}
+	return nil
+}
+
+// checkWALArchiveSlot checks the prerequisites of archiving the WAL with a replication slot.
+func (driver *Driver) checkWALArchiveSlot(ctx context.Context) error {
+	maxReplicationSlots, err := driver.showSetting(ctx, "max_replication_slots")
+	if err != nil {
+		return err
+	}
+	if maxReplicationSlots == "0" {
+		return errors.Errorf("max_replication_slots must be greater than 0 to stream the WAL with a replication slot")
+	}
+	// The slot retains the WAL without limit if max_slot_wal_keep_size is -1, which may fill up the disk of the server while the streaming stops.
+	maxSlotWALKeepSize, err := driver.showSetting(ctx, "max_slot_wal_keep_size")
+	if err != nil {
+		return err
+	}
+	if maxSlotWALKeepSize == "-1" {
+		return errors.Errorf("max_slot_wal_keep_size must be set to bound the WAL retained by the replication slot %q", walArchiveSlotName)
+	}
+	return nil
+}
+
+// DropWALArchiveSlot drops the replication slot for archiving the WAL if it exists.
+// It fails if the slot is still in use, e.g., pg_receivewal has not exited yet, and the caller should retry later.
+func (driver *Driver) DropWALArchiveSlot(ctx context.Context) error {
+	const query = "SELECT active FROM pg_replication_slots WHERE slot_name = $1"
+	var active bool
+	if err := driver.db.QueryRowContext(ctx, query, walArchiveSlotName).Scan(&active); err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return util.FormatErrorWithQuery(err, query)
+	}
+	if active {
+		return errors.Errorf("replication slot %q is still active", walArchiveSlotName)
+	}
+	const dropQuery = "SELECT pg_drop_replication_slot($1)"
+	if _, err := driver.db.ExecContext(ctx, dropQuery, walArchiveSlotName); err != nil {
+		return util.FormatErrorWithQuery(err, dropQuery)
+	}
+	log.Info("Dropped the replication slot for archiving the WAL", zap.String("instance", driver.connectionCtx.InstanceName), zap.String("slot", walArchiveSlotName))
+	return nil
+}
+
+// pgUtilityCommand returns the command running the bundled PostgreSQL utility against the instance.
+func (driver *Driver) pgUtilityCommand(ctx context.Context, name string, args ...string) *exec.Cmd {
+	args = append([]string{
+		fmt.Sprintf("--username=%s", driver.config.Username),
+		fmt.Sprintf("--host=%s", driver.config.Host),
+		fmt.Sprintf("--port=%s", driver.config.Port),
+		"--no-password",
+	}, args...)
+	cmd := exec.CommandContext(ctx, filepath.Join(driver.pgInstanceDir, "bin", name), args...)
+	if driver.config.Password != "" {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("PGPASSWORD=%s", driver.config.Password))
+	}
+	cmd.Env = append(cmd.Env, "OPENSSL_CONF=/etc/ssl/")
+	return cmd
+}
+
+func (driver *Driver) showSetting(ctx context.Context, name string) (string, error) {
+	query := fmt.Sprintf("SHOW %s", name)
+	var value string
+	if err := driver.db.QueryRowContext(ctx, query).Scan(&value); err != nil {
+		if err == sql.ErrNoRows {
+			return "", common.FormatDBErrorEmptyRowWithQuery(query)
+		}
+		return "", util.FormatErrorWithQuery(err, query)
+	}
+	return value, nil
+}
+
+// UploadWALFiles uploads the received WAL segments and the timeline history files in walDir to the cloud storage.
+// The uploaded segments are removed locally except for the latest one, from which pg_receivewal resumes streaming after restart.
+func UploadWALFiles(ctx context.Context, client storage.Client, walDir string) error {
+	fileNameList, err := listWALFiles(walDir)
+	if err != nil {
+		return err
+	}
+	relativeDir := common.GetBinlogRelativeDir(walDir)
+	listOutput, err := client.ListObjects(ctx, relativeDir+"/")
+	if err != nil {
+		return errors.Wrapf(err, "failed to list WAL dir %q in the cloud storage", relativeDir)
+	}
+	uploaded := make(map[string]bool)
+	for _, item := range listOutput {
+		uploaded[path.Base(item.Key)] = true
+	}
+
+	latestSegment := ""
+	for _, fileName := range fileNameList {
+		if len(fileName) == walSegmentNameLength {
+			latestSegment = fileName
+		}
+	}
+	for _, fileName := range fileNameList {
+		// The segment being received is incomplete.
+		if strings.HasSuffix(fileName, walPartialSuffix) {
+			continue
+		}
+		filePath := filepath.Join(walDir, fileName)
+		if !uploaded[fileName] {
+			if err := uploadFileToCloud(ctx, client, filePath, path.Join(relativeDir, fileName)); err != nil {
+				return err
+			}
+			log.Debug("Uploaded WAL file to the cloud storage", zap.String("path", filePath))
+		}
+		if fileName == latestSegment || strings.HasSuffix(fileName, walHistorySuffix) {
+			continue
+		}
+		if err := os.Remove(filePath); err != nil {
+			log.Warn("Failed to remove the local WAL file after uploading to the cloud storage", zap.String("path", filePath), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+func uploadFileToCloud(ctx context.Context, client storage.Client, filePathLocal, filePathOnCloud string) error {
+	f, err := os.Open(filePathLocal)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open local file %q for uploading", filePathLocal)
+	}
+	defer f.Close()
+	if err := client.UploadObject(ctx, filePathOnCloud, f); err != nil {
+		return errors.Wrapf(err, "failed to upload file %q to the cloud storage", filePathLocal)
+	}
+	return nil
+}
+
+// PrepareWALReplay collects the WAL files to recover from the base backup starting at startWALFileName into replayDir.
+// The archived files are downloaded from the cloud storage if client is not nil, and the local files received later are copied.
+func PrepareWALReplay(ctx context.Context, client storage.Client, walDir, replayDir, startWALFileName string) error {
+	if err := os.MkdirAll(replayDir, os.ModePerm); err != nil {
+		return errors.Wrapf(err, "failed to create WAL replay directory %q", replayDir)
+	}
+	collected := make(map[string]bool)
+	if client != nil {
+		relativeDir := common.GetBinlogRelativeDir(walDir)
+		listOutput, err := client.ListObjects(ctx, relativeDir+"/")
+		if err != nil {
+			return errors.Wrapf(err, "failed to list WAL dir %q in the cloud storage", relativeDir)
+		}
+		var fileNameList []string
+		for _, item := range listOutput {
+			fileNameList = append(fileNameList, path.Base(item.Key))
+		}
+		for _, fileName := range getWALReplayList(fileNameList, startWALFileName) {
+			if err := storage.DownloadFileFromCloud(ctx, client, filepath.Join(replayDir, fileName), path.Join(relativeDir, fileName)); err != nil {
+				return errors.Wrapf(err, "failed to download WAL file %s from the cloud storage", fileName)
+			}
+			collected[fileName] = true
+		}
+	}
+
+	fileNameList, err := listWALFiles(walDir)
+	if err != nil {
+		return err
+	}
+	for _, fileName := range getWALReplayList(fileNameList, startWALFileName) {
+		// The segment being received is replayed as a complete one, and the recovery stops at the end of its valid records.
+		targetFileName := strings.TrimSuffix(fileName, walPartialSuffix)
+		if collected[targetFileName] {
+			continue
+		}
+		if err := copyFile(filepath.Join(walDir, fileName), filepath.Join(replayDir, targetFileName)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getWALReplayList returns the sorted WAL segments starting from startWALFileName and all the timeline history files.
+func getWALReplayList(fileNameList []string, startWALFileName string) []string {
+	// The timeline may change during the recovery, so we compare the log and segment number without the timeline ID.
+	startSegment := ""
+	if len(startWALFileName) == walSegmentNameLength {
+		startSegment = startWALFileName[8:]
+	}
+	var replayList []string
+	for _, fileName := range fileNameList {
+		if !isWALFile(fileName) {
+			continue
+		}
+		if strings.HasSuffix(fileName, walHistorySuffix) || fileName[8:walSegmentNameLength] >= startSegment {
+			replayList = append(replayList, fileName)
+		}
+	}
+	sort.Strings(replayList)
+	return replayList
+}
+
+func listWALFiles(walDir string) ([]string, error) {
+	entryList, err := os.ReadDir(walDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to read WAL directory %q", walDir)
+	}
+	var fileNameList []string
+	for _, entry := range entryList {
+		if !entry.IsDir() && isWALFile(entry.Name()) {
+			fileNameList = append(fileNameList, entry.Name())
+		}
+	}
+	sort.Strings(fileNameList)
+	return fileNameList, nil
+}
+
+// isWALFile returns true for the WAL segments, with an optional ".partial" suffix, and the timeline history files.
+func isWALFile(fileName string) bool {
+	if strings.HasSuffix(fileName, walHistorySuffix) {
+		return isUpperHex(strings.TrimSuffix(fileName, walHistorySuffix))
+	}
+	name := strings.TrimSuffix(fileName, walPartialSuffix)
+	return len(name) == walSegmentNameLength && isUpperHex(name)
+}
+
+func isUpperHex(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if !(c >= '0' && c <= '9' || c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open file %q", src)
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create file %q", dst)
+	}
+	defer out.Close()
+	if _, err := io.Copy(out, in); err != nil {
+		return errors.Wrapf(err, "failed to copy file %q to %q", src, dst)
+	}
+	return out.Close()
+}
+
+// RecoveryCluster is a temporary PostgreSQL cluster recovered from a base backup and the archived WAL.
+// It's run by the bundled binaries and only listens on the Unix domain socket.
+type RecoveryCluster struct {
+	pgInstanceDir string
+	dataDir       string
+	port          int
+	username      string
+	// settingMap is the settings of the source instance required to start a hot standby.
+	settingMap map[string]string
+}
+
+// NewRecoveryCluster creates a recovery cluster in dataDir for the base backups of the instance.
+func (driver *Driver) NewRecoveryCluster(ctx context.Context, dataDir string) (*RecoveryCluster, error) {
+	settingMap := make(map[string]string)
+	for _, name := range hotStandbySettingList {
+		value, err := driver.showSetting(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		settingMap[name] = value
+	}
+	port, err := getFreePort()
+	if err != nil {
+		return nil, err
+	}
+	return &RecoveryCluster{
+		pgInstanceDir: driver.pgInstanceDir,
+		dataDir:       dataDir,
+		port:          port,
+		username:      driver.config.Username,
+		settingMap:    settingMap,
+	}, nil
+}
+
+// Recover extracts the base backup, replays the WAL in walDir up to targetTs and waits for the recovery to finish.
+// The caller should stop the cluster after a successful recovery.
+func (c *RecoveryCluster) Recover(ctx context.Context, baseBackup io.Reader, walDir string, targetTs int64) error {
+	if err := os.RemoveAll(c.dataDir); err != nil {
+		return errors.Wrapf(err, "failed to remove the recovery data directory %q", c.dataDir)
+	}
+	if err := utils.ExtractTar(baseBackup, c.dataDir); err != nil {
+		return errors.Wrap(err, "failed to extract the base backup")
+	}
+	if err := c.writeRecoveryConfig(walDir, targetTs); err != nil {
+		return err
+	}
+	if err := os.Chmod(c.dataDir, 0700); err != nil {
+		return errors.Wrapf(err, "failed to chmod the recovery data directory %q to 0700", c.dataDir)
+	}
+	if err := postgres.ChownDataDir(c.dataDir); err != nil {
+		return err
+	}
+
+	log.Debug("Start the recovery cluster", zap.String("dataDir", c.dataDir), zap.Int("port", c.port))
+	if err := postgres.Start(c.port, c.pgInstanceDir, c.dataDir, os.Stdout, os.Stderr); err != nil {
+		return errors.Wrap(err, "failed to start the recovery cluster")
+	}
+	if err := c.waitForRecovery(ctx); err != nil {
+		if err := c.Stop(); err != nil {
+			log.Warn("Failed to stop the recovery cluster", zap.String("dataDir", c.dataDir), zap.Error(err))
+		}
+		return err
+	}
+	return nil
+}
+
+// writeRecoveryConfig configures the cluster to recover to the target time.
+// The configuration files may not be in the data directory, e.g., on Debian, and the ones in the base backup are for the source instance.
+// So we make sure the files exist and override the settings which prevent the cluster from starting locally.
+func (c *RecoveryCluster) writeRecoveryConfig(walDir string, targetTs int64) error {
+	for _, fileName := range []string{"postgresql.conf", "pg_ident.conf"} {
+		f, err := os.OpenFile(filepath.Join(c.dataDir, fileName), os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create %s", fileName)
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	if err := os.WriteFile(filepath.Join(c.dataDir, "pg_hba.conf"), []byte("local all all trust\n"), 0600); err != nil {
+		return errors.Wrap(err, "failed to write pg_hba.conf")
+	}
+	if err := os.WriteFile(filepath.Join(c.dataDir, "recovery.signal"), nil, 0600); err != nil {
+		return errors.Wrap(err, "failed to write recovery.signal")
+	}
+	f, err := os.OpenFile(filepath.Join(c.dataDir, "postgresql.auto.conf"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrap(err, "failed to open postgresql.auto.conf")
+	}
+	defer f.Close()
+	if _, err := f.WriteString(buildRecoveryConfig(c.dataDir, walDir, targetTs, c.settingMap)); err != nil {
+		return errors.Wrap(err, "failed to write postgresql.auto.conf")
+	}
+	return f.Close()
+}
+
+// buildRecoveryConfig returns the settings appended to postgresql.auto.conf for the recovery.
+func buildRecoveryConfig(dataDir, walDir string, targetTs int64, settingMap map[string]string) string {
+	var buf strings.Builder
+	_, _ = buf.WriteString("\n# Point-in-time recovery settings added by Bytebase.\n")
+	settingList := [][2]string{
+		{"restore_command", fmt.Sprintf(`cp "%s/%%f" "%%p"`, walDir)},
+		{"recovery_target_time", time.Unix(targetTs, 0).UTC().Format("2006-01-02 15:04:05+00")},
+		{"recovery_target_action", "promote"},
+		{"hot_standby", "on"},
+		{"archive_mode", "off"},
+		{"ssl", "off"},
+		{"shared_preload_libraries", ""},
+		{"hba_file", filepath.Join(dataDir, "pg_hba.conf")},
+		{"ident_file", filepath.Join(dataDir, "pg_ident.conf")},
+	}
+	for _, name := range hotStandbySettingList {
+		if value, ok := settingMap[name]; ok {
+			settingList = append(settingList, [2]string{name, value})
+		}
+	}
+	for _, setting := range settingList {
+		_, _ = fmt.Fprintf(&buf, "%s = '%s'\n", setting[0], strings.ReplaceAll(setting[1], "'", "''"))
+	}
+	return buf.String()
+}
+
+// waitForRecovery waits until the cluster is promoted after reaching the recovery target.
+func (c *RecoveryCluster) waitForRecovery(ctx context.Context) error {
+	dsn := fmt.Sprintf("host=%s port=%d user=%s dbname=template1 sslmode=disable", common.GetPostgresSocketDir(), c.port, c.username)
+	recoveryDB, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return err
+	}
+	defer recoveryDB.Close()
+
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+	for {
+		var inRecovery bool
+		if err := recoveryDB.QueryRowContext(ctx, "SELECT pg_is_in_recovery()").Scan(&inRecovery); err != nil {
+			// The cluster shuts down if the archived WAL doesn't reach the recovery target.
+			return errors.Wrap(err, "failed to check the recovery status, the archived WAL may not cover the recovery target")
+		}
+		if !inRecovery {
+			return nil
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return errors.Errorf("context is canceled when waiting for the recovery")
+		}
+	}
+}
+
+// Dump dumps the database in the recovery cluster.
+func (c *RecoveryCluster) Dump(ctx context.Context, database string, out io.Writer) error {
+	driver := &Driver{
+		pgInstanceDir: c.pgInstanceDir,
+		config: db.ConnectionConfig{
+			Host:     common.GetPostgresSocketDir(),
+			Port:     strconv.Itoa(c.port),
+			Username: c.username,
+		},
+	}
+	return driver.dumpOneDatabaseWithPgDump(ctx, database, out, false /* schemaOnly */)
+}
+
+// Stop stops the recovery cluster.
+func (c *RecoveryCluster) Stop() error {
+	return postgres.Stop(c.pgInstanceDir, c.dataDir, os.Stdout, os.Stderr)
+}
+
+func getFreePort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to find a free port")
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
@@ -0,0 +1,100 @@
+package pg
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetWALReplayList(t *testing.T) {
+	tests := []struct {
+		name             string
+		fileNameList     []string
+		startWALFileName string
+		want             []string
+	}{
+		{
+			name: "startFromBaseBackup",
+			fileNameList: []string{
+				"000000010000000000000003",
+				"000000010000000000000001",
+				"000000010000000000000002",
+				"000000010000000000000004.partial",
+			},
+			startWALFileName: "000000010000000000000002",
+			want: []string{
+				"000000010000000000000002",
+				"000000010000000000000003",
+				"000000010000000000000004.partial",
+			},
+		},
+		{
+			name: "timelineSwitch",
+			fileNameList: []string{
+				"000000010000000000000001",
+				"000000010000000100000000",
+				"00000002.history",
+				"000000020000000100000001",
+			},
+			startWALFileName: "0000000100000000000000FF",
+			want: []string{
+				"000000010000000100000000",
+				"00000002.history",
+				"000000020000000100000001",
+			},
+		},
+		{
+			name: "ignoreOtherFile",
+			fileNameList: []string{
+				"000000010000000000000002.tmp",
+				"000000010000000000000002.meta",
+				"00000001000000000000000g",
+				"backup_label",
+				"000000010000000000000002",
+			},
+			startWALFileName: "000000010000000000000001",
+			want: []string{
+				"000000010000000000000002",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		got := getWALReplayList(test.fileNameList, test.startWALFileName)
+		require.Equal(t, test.want, got, test.name)
+	}
+}
+
+func TestBuildRecoveryConfig(t *testing.T) {
+	targetTs := time.Date(2022, 10, 25, 8, 30, 0, 0, time.UTC).Unix()
+	settingMap := map[string]string{
+		"max_connections":           "200",
+		"max_worker_processes":      "8",
+		"max_wal_senders":           "10",
+		"max_prepared_transactions": "0",
+		"max_locks_per_transaction": "64",
+	}
+	want := `
+# Point-in-time recovery settings added by Bytebase.
+restore_command = 'cp "/data/pitr/wal/%f" "%p"'
+recovery_target_time = '2022-10-25 08:30:00+00'
+recovery_target_action = 'promote'
+hot_standby = 'on'
+archive_mode = 'off'
+ssl = 'off'
+shared_preload_libraries = ''
+hba_file = '/data/pitr/data/pg_hba.conf'
+ident_file = '/data/pitr/data/pg_ident.conf'
+max_connections = '200'
+max_worker_processes = '8'
+max_wal_senders = '10'
+max_prepared_transactions = '0'
+max_locks_per_transaction = '64'
+`
+	got := buildRecoveryConfig("/data/pitr/data", "/data/pitr/wal", targetTs, settingMap)
+	require.Equal(t, want, got)
+
+	got = buildRecoveryConfig("/data/it's", "/data/wal", targetTs, nil)
+	require.Contains(t, got, `hba_file = '/data/it''s/pg_hba.conf'`)
+}
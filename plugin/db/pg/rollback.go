@@ -0,0 +1,421 @@
+package pg
+
+// This file implements the rollback statement generation for data migrations.
+// Before each UPDATE and DELETE statement, we copy the rows to be changed into an archive table in the
+// bbdataarchive schema with SELECT ... FOR UPDATE in the same transaction, so that the rows cannot be changed
+// by others between the copy and the statement. After the migration, the rollback statement restores the rows
+// from the archive tables in the reverse order:
+// 1. DELETE is reverted by inserting the archived rows.
+// 2. UPDATE is reverted by updating the rows back to the archived values, matched by the primary key.
+// A row is archived more than once if the statement joins other tables with a many-to-one relation, so the archived
+// rows are de-duplicated on the primary key.
+// The archive tables are excluded from the schema sync and dump, and are dropped after the retention period, so the
+// rollback statements are only available within the retention period.
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	pgquery "github.com/pganalyze/pg_query_go/v2"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/bytebase/bytebase/common/log"
+	"github.com/bytebase/bytebase/plugin/db"
+	"github.com/bytebase/bytebase/plugin/db/util"
+)
+
+var (
+	_ db.RollbackExecutor = (*Driver)(nil)
+)
+
+const (
+	// archiveSchema is the schema for the archive tables.
+	// Note, the schema sync queries in sync.go exclude this schema by the literal name.
+	archiveSchema = "bbdataarchive"
+	// maxIdentifierLength is the maximum identifier length in Postgres.
+	maxIdentifierLength = 63
+	// archiveRetention is the period to keep the archive tables.
+	archiveRetention = 30 * 24 * time.Hour
+)
+
+// archivePlan is the plan to archive the rows changed by an UPDATE or DELETE statement.
+type archivePlan struct {
+	// isUpdate is true for UPDATE and false for DELETE.
+	isUpdate bool
+	// table is the target table of the statement.
+	table *pgquery.RangeVar
+	// updatedColumns is the columns set by the UPDATE statement.
+	updatedColumns []string
+	// hasJoin is true if the statement joins other tables by UPDATE ... FROM or DELETE ... USING.
+	hasJoin bool
+	// archiveTable is the name of the archive table in the archive schema.
+	archiveTable string
+	// archiveStatement copies the rows to be changed into the archive table.
+	archiveStatement string
+}
+
+// archiveExecutor executes the statement with the archive statements inserted before the UPDATE and DELETE statements.
+type archiveExecutor struct {
+	*Driver
+
+	script   string
+	executed bool
+}
+
+// Execute executes the statement together with the archive statements in the same transaction.
+func (executor *archiveExecutor) Execute(ctx context.Context, _ string) error {
+	if err := executor.Driver.Execute(ctx, executor.script); err != nil {
+		return err
+	}
+	executor.executed = true
+	return nil
+}
+
+// ExecuteMigrationWithRollback executes the migration and generates the rollback statement from the archived rows.
+func (driver *Driver) ExecuteMigrationWithRollback(ctx context.Context, m *db.MigrationInfo, statement string) (int64, string, *db.RollbackResult, error) {
+	plans, script, planErr := getArchivePlans(statement, m.Version, time.Now().Unix())
+	if planErr != nil {
+		migrationHistoryID, schema, err := driver.ExecuteMigration(ctx, m, statement)
+		if err != nil {
+			return -1, "", nil, err
+		}
+		return migrationHistoryID, schema, &db.RollbackResult{Error: planErr.Error()}, nil
+	}
+
+	databaseName := db.BytebaseDatabase
+	if driver.strictUseDb() {
+		databaseName = driver.strictDatabase
+	}
+	executor := &archiveExecutor{Driver: driver, script: script}
+	migrationHistoryID, schema, err := util.ExecuteMigration(ctx, executor, m, statement, databaseName)
+	if err != nil {
+		return -1, "", nil, err
+	}
+	if !executor.executed {
+		return migrationHistoryID, schema, nil, nil
+	}
+
+	// The archive tables of the previous migrations are dropped on the data migrations of the same database.
+	if err := driver.purgeArchiveTables(ctx, m.Database, time.Now().Add(-archiveRetention).Unix()); err != nil {
+		log.Warn("Failed to purge the expired archive tables",
+			zap.String("database", m.Database),
+			zap.Error(err),
+		)
+	}
+
+	rollbackStatement, count, err := driver.generateRollbackStatement(ctx, m.Database, plans)
+	if err != nil {
+		log.Warn("Failed to generate the rollback statement",
+			zap.String("database", m.Database),
+			zap.String("version", m.Version),
+			zap.Error(err),
+		)
+		return migrationHistoryID, schema, &db.RollbackResult{Error: err.Error()}, nil
+	}
+	return migrationHistoryID, schema, &db.RollbackResult{Statement: rollbackStatement, AffectedRowCount: count}, nil
+}
+
+// getArchivePlans parses the statement and returns the archive plans, and the script to execute which inserts the archive statements before the UPDATE and DELETE statements.
+// The archive tables are named by the migration version and the statement index, and commented with the creation timestamp for the retention.
+func getArchivePlans(statement string, version string, createdTs int64) ([]*archivePlan, string, error) {
+	res, err := pgquery.Parse(statement)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to parse the statement")
+	}
+
+	var plans []*archivePlan
+	var script strings.Builder
+	for i, stmt := range res.Stmts {
+		text := statement[int(stmt.StmtLocation):]
+		if stmt.StmtLen > 0 {
+			text = statement[int(stmt.StmtLocation):int(stmt.StmtLocation+stmt.StmtLen)]
+		}
+		text = strings.TrimSuffix(strings.TrimSpace(text), ";")
+
+		var plan *archivePlan
+		switch node := stmt.Stmt.Node.(type) {
+		case *pgquery.Node_UpdateStmt:
+			var updatedColumns []string
+			for _, target := range node.UpdateStmt.TargetList {
+				if resTarget, ok := target.Node.(*pgquery.Node_ResTarget); ok {
+					updatedColumns = append(updatedColumns, resTarget.ResTarget.Name)
+				}
+			}
+			plan = &archivePlan{
+				isUpdate:       true,
+				table:          node.UpdateStmt.Relation,
+				updatedColumns: updatedColumns,
+				hasJoin:        len(node.UpdateStmt.FromClause) > 0,
+			}
+			plan.archiveStatement, err = getArchiveSelect(node.UpdateStmt.Relation, node.UpdateStmt.FromClause, node.UpdateStmt.WhereClause, node.UpdateStmt.WithClause)
+		case *pgquery.Node_DeleteStmt:
+			plan = &archivePlan{
+				table:   node.DeleteStmt.Relation,
+				hasJoin: len(node.DeleteStmt.UsingClause) > 0,
+			}
+			plan.archiveStatement, err = getArchiveSelect(node.DeleteStmt.Relation, node.DeleteStmt.UsingClause, node.DeleteStmt.WhereClause, node.DeleteStmt.WithClause)
+		case *pgquery.Node_InsertStmt:
+			return nil, "", errors.Errorf("generating rollback statement is not supported for INSERT statement %q", text)
+		}
+		if err != nil {
+			return nil, "", errors.Wrapf(err, "failed to generate the archive statement for %q", text)
+		}
+
+		if plan != nil {
+			plan.archiveTable = getArchiveTableName(version, i, plan.table.Relname)
+			archiveTable := fmt.Sprintf("%s.%s", quoteIdentifier(archiveSchema), quoteIdentifier(plan.archiveTable))
+			plan.archiveStatement = fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s;\nCREATE TABLE %s (LIKE %s);\nCOMMENT ON TABLE %s IS '%d';\nINSERT INTO %s %s;\n",
+				quoteIdentifier(archiveSchema),
+				archiveTable,
+				getRangeVarName(plan.table),
+				archiveTable,
+				createdTs,
+				archiveTable,
+				plan.archiveStatement,
+			)
+			plans = append(plans, plan)
+			script.WriteString(plan.archiveStatement)
+		}
+		script.WriteString(text)
+		script.WriteString(";\n")
+	}
+	if len(plans) == 0 {
+		return nil, "", errors.Errorf("no UPDATE or DELETE statement is found")
+	}
+	return plans, script.String(), nil
+}
+
+// getArchiveSelect returns the SELECT ... FOR UPDATE statement for the rows of the relation to be changed.
+func getArchiveSelect(relation *pgquery.RangeVar, fromClause []*pgquery.Node, whereClause *pgquery.Node, withClause *pgquery.WithClause) (string, error) {
+	if withClause != nil {
+		for _, cte := range withClause.Ctes {
+			// The data-modifying statements in WITH would be executed twice.
+			if expr, ok := cte.Node.(*pgquery.Node_CommonTableExpr); ok {
+				if _, ok := expr.CommonTableExpr.Ctequery.Node.(*pgquery.Node_SelectStmt); !ok {
+					return "", errors.Errorf("data-modifying statement in WITH is not supported")
+				}
+			}
+		}
+	}
+	refName := relation.Relname
+	if relation.Alias != nil && relation.Alias.Aliasname != "" {
+		refName = relation.Alias.Aliasname
+	}
+	selectStmt := &pgquery.SelectStmt{
+		TargetList: []*pgquery.Node{
+			pgquery.MakeResTargetNodeWithVal(pgquery.MakeColumnRefNode([]*pgquery.Node{pgquery.MakeStrNode(refName), pgquery.MakeAStarNode()}, -1), -1),
+		},
+		FromClause:  append([]*pgquery.Node{{Node: &pgquery.Node_RangeVar{RangeVar: relation}}}, fromClause...),
+		WhereClause: whereClause,
+		WithClause:  withClause,
+		LockingClause: []*pgquery.Node{
+			{
+				Node: &pgquery.Node_LockingClause{
+					LockingClause: &pgquery.LockingClause{
+						LockedRels: []*pgquery.Node{pgquery.MakeSimpleRangeVarNode(refName, -1)},
+						Strength:   pgquery.LockClauseStrength_LCS_FORUPDATE,
+						WaitPolicy: pgquery.LockWaitPolicy_LockWaitBlock,
+					},
+				},
+			},
+		},
+		Op: pgquery.SetOperation_SETOP_NONE,
+	}
+	return pgquery.Deparse(&pgquery.ParseResult{
+		Stmts: []*pgquery.RawStmt{
+			{Stmt: &pgquery.Node{Node: &pgquery.Node_SelectStmt{SelectStmt: selectStmt}}},
+		},
+	})
+}
+
+// getArchiveTableName returns the archive table name like "20221018000000_1_tbl", which is truncated to the maximum identifier length.
+func getArchiveTableName(version string, index int, table string) string {
+	name := fmt.Sprintf("%s_%d_%s", version, index, table)
+	for len(name) > maxIdentifierLength {
+		// Truncate by rune to keep the name valid UTF-8.
+		runes := []rune(name)
+		name = string(runes[:len(runes)-1])
+	}
+	return name
+}
+
+// generateRollbackStatement generates the statements to restore the archived rows in the reverse order.
+func (driver *Driver) generateRollbackStatement(ctx context.Context, database string, plans []*archivePlan) (string, int64, error) {
+	// The connection is switched to the bytebase database when recording the migration history.
+	sqldb, err := driver.GetDBConnection(ctx, database)
+	if err != nil {
+		return "", 0, err
+	}
+
+	var buf strings.Builder
+	var total int64
+	for i := len(plans) - 1; i >= 0; i-- {
+		plan := plans[i]
+		archiveTable := fmt.Sprintf("%s.%s", quoteIdentifier(archiveSchema), quoteIdentifier(plan.archiveTable))
+		tableName := getRangeVarName(plan.table)
+		// Resolve the schema of the table with the search path if the schema is not specified.
+		schemaQuery := "SELECT n.nspname FROM pg_catalog.pg_class c JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace WHERE c.oid = $1::regclass"
+		var schema string
+		if err := sqldb.QueryRowContext(ctx, schemaQuery, tableName).Scan(&schema); err != nil {
+			return "", 0, util.FormatErrorWithQuery(err, schemaQuery)
+		}
+		columns, primaryKeys, err := getRollbackColumns(ctx, sqldb, schema, plan.table.Relname)
+		if err != nil {
+			return "", 0, err
+		}
+		table := fmt.Sprintf("%s.%s", quoteIdentifier(schema), quoteIdentifier(plan.table.Relname))
+
+		// The archived rows are the copies of the same row if they have the same primary key.
+		archivedRows := archiveTable
+		if len(primaryKeys) > 0 {
+			var keys []string
+			for _, key := range primaryKeys {
+				keys = append(keys, quoteIdentifier(key))
+			}
+			archivedRows = fmt.Sprintf("(SELECT DISTINCT ON (%s) * FROM %s)", strings.Join(keys, ", "), archiveTable)
+		} else if plan.hasJoin {
+			return "", 0, errors.Errorf("table %s has no primary key to de-duplicate the rows archived in %s", table, archiveTable)
+		}
+
+		var count int64
+		countQuery := fmt.Sprintf("SELECT COUNT(1) FROM %s AS a", archivedRows)
+		if err := sqldb.QueryRowContext(ctx, countQuery).Scan(&count); err != nil {
+			return "", 0, util.FormatErrorWithQuery(err, countQuery)
+		}
+		if count == 0 {
+			continue
+		}
+		total += count
+
+		if !plan.isUpdate {
+			var names []string
+			for _, column := range columns {
+				names = append(names, quoteIdentifier(column))
+			}
+			columnList := strings.Join(names, ", ")
+			buf.WriteString(fmt.Sprintf("INSERT INTO %s (%s) OVERRIDING SYSTEM VALUE SELECT %s FROM %s AS a;\n", table, columnList, columnList, archivedRows))
+			continue
+		}
+
+		if len(primaryKeys) == 0 {
+			return "", 0, errors.Errorf("table %s has no primary key to match the rows archived in %s", table, archiveTable)
+		}
+		isPrimaryKey := make(map[string]bool)
+		for _, key := range primaryKeys {
+			isPrimaryKey[key] = true
+		}
+		for _, column := range plan.updatedColumns {
+			if isPrimaryKey[column] {
+				return "", 0, errors.Errorf("the primary key column %q of table %s is updated, the rows archived in %s cannot be matched", column, table, archiveTable)
+			}
+		}
+		var sets, conditions []string
+		for _, column := range columns {
+			if isPrimaryKey[column] {
+				conditions = append(conditions, fmt.Sprintf("t.%s = a.%s", quoteIdentifier(column), quoteIdentifier(column)))
+				continue
+			}
+			sets = append(sets, fmt.Sprintf("%s = a.%s", quoteIdentifier(column), quoteIdentifier(column)))
+		}
+		if len(sets) == 0 {
+			continue
+		}
+		buf.WriteString(fmt.Sprintf("UPDATE %s AS t SET %s FROM %s AS a WHERE %s;\n", table, strings.Join(sets, ", "), archivedRows, strings.Join(conditions, " AND ")))
+	}
+	return buf.String(), total, nil
+}
+
+// purgeArchiveTables drops the archive tables created before the given timestamp.
+func (driver *Driver) purgeArchiveTables(ctx context.Context, database string, beforeTs int64) error {
+	sqldb, err := driver.GetDBConnection(ctx, database)
+	if err != nil {
+		return err
+	}
+	// The creation timestamp is stored in the table comment.
+	query := `
+		SELECT c.relname
+		FROM pg_catalog.pg_class c
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = $1 AND c.relkind = 'r'
+			AND obj_description(c.oid, 'pg_class') ~ '^[0-9]+$'
+			AND obj_description(c.oid, 'pg_class')::bigint < $2`
+	tables, err := queryStrings(ctx, sqldb, query, archiveSchema, beforeTs)
+	if err != nil {
+		return err
+	}
+	for _, table := range tables {
+		stmt := fmt.Sprintf("DROP TABLE IF EXISTS %s.%s", quoteIdentifier(archiveSchema), quoteIdentifier(table))
+		if _, err := sqldb.ExecContext(ctx, stmt); err != nil {
+			return util.FormatErrorWithQuery(err, stmt)
+		}
+	}
+	return nil
+}
+
+// getRollbackColumns returns the columns excluding the generated columns, and the primary key columns of the table.
+func getRollbackColumns(ctx context.Context, sqldb *sql.DB, schema, table string) ([]string, []string, error) {
+	columnQuery := `
+		SELECT column_name
+		FROM information_schema.columns
+		WHERE table_schema = $1 AND table_name = $2 AND is_generated = 'NEVER'
+		ORDER BY ordinal_position`
+	columns, err := queryStrings(ctx, sqldb, columnQuery, schema, table)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(columns) == 0 {
+		return nil, nil, errors.Errorf("table %q.%q is not found", schema, table)
+	}
+
+	primaryKeyQuery := `
+		SELECT kcu.column_name
+		FROM information_schema.table_constraints tc
+		JOIN information_schema.key_column_usage kcu
+			ON tc.constraint_schema = kcu.constraint_schema AND tc.constraint_name = kcu.constraint_name
+		WHERE tc.constraint_type = 'PRIMARY KEY' AND tc.table_schema = $1 AND tc.table_name = $2
+		ORDER BY kcu.ordinal_position`
+	primaryKeys, err := queryStrings(ctx, sqldb, primaryKeyQuery, schema, table)
+	if err != nil {
+		return nil, nil, err
+	}
+	return columns, primaryKeys, nil
+}
+
+// queryStrings returns the first column of the query result.
+func queryStrings(ctx context.Context, sqldb *sql.DB, query string, args ...interface{}) ([]string, error) {
+	rows, err := sqldb.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, util.FormatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	var result []string
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return nil, err
+		}
+		result = append(result, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, util.FormatErrorWithQuery(err, query)
+	}
+	return result, nil
+}
+
+// getRangeVarName returns the quoted name of the relation like "schema"."table".
+func getRangeVarName(relation *pgquery.RangeVar) string {
+	if relation.Schemaname != "" {
+		return fmt.Sprintf("%s.%s", quoteIdentifier(relation.Schemaname), quoteIdentifier(relation.Relname))
+	}
+	return quoteIdentifier(relation.Relname)
+}
+
+// quoteIdentifier quotes the identifier with double quotes.
+func quoteIdentifier(s string) string {
+	return fmt.Sprintf(`"%s"`, strings.ReplaceAll(s, `"`, `""`))
+}
@@ -0,0 +1,57 @@
+package pg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetArchivePlans(t *testing.T) {
+	a := require.New(t)
+	statement := `UPDATE "public".tech_book SET name = 'x' WHERE id > 1;
+SELECT 1;
+DELETE FROM tech_book b USING author a WHERE b.author_id = a.id AND a.name = 'bb';`
+	plans, script, err := getArchivePlans(statement, "20221018000000", 1666051200)
+	a.NoError(err)
+	a.Len(plans, 2)
+
+	a.True(plans[0].isUpdate)
+	a.Equal("20221018000000_0_tech_book", plans[0].archiveTable)
+	a.Equal([]string{"name"}, plans[0].updatedColumns)
+	a.False(plans[0].hasJoin)
+	a.False(plans[1].isUpdate)
+	a.True(plans[1].hasJoin)
+	a.Equal("20221018000000_2_tech_book", plans[1].archiveTable)
+
+	want := `CREATE SCHEMA IF NOT EXISTS "bbdataarchive";
+CREATE TABLE "bbdataarchive"."20221018000000_0_tech_book" (LIKE "public"."tech_book");
+COMMENT ON TABLE "bbdataarchive"."20221018000000_0_tech_book" IS '1666051200';
+INSERT INTO "bbdataarchive"."20221018000000_0_tech_book" SELECT tech_book.* FROM public.tech_book WHERE id > 1 FOR UPDATE OF tech_book;
+UPDATE "public".tech_book SET name = 'x' WHERE id > 1;
+SELECT 1;
+CREATE SCHEMA IF NOT EXISTS "bbdataarchive";
+CREATE TABLE "bbdataarchive"."20221018000000_2_tech_book" (LIKE "tech_book");
+COMMENT ON TABLE "bbdataarchive"."20221018000000_2_tech_book" IS '1666051200';
+INSERT INTO "bbdataarchive"."20221018000000_2_tech_book" SELECT b.* FROM tech_book b, author a WHERE b.author_id = a.id AND a.name = 'bb' FOR UPDATE OF b;
+DELETE FROM tech_book b USING author a WHERE b.author_id = a.id AND a.name = 'bb';
+`
+	a.Equal(want, script)
+
+	for _, statement := range []string{
+		"INSERT INTO tech_book VALUES (1, 'x'); DELETE FROM tech_book;",
+		"SELECT 1;",
+		"WITH d AS (DELETE FROM author RETURNING id) UPDATE tech_book SET author_id = NULL WHERE author_id IN (SELECT id FROM d);",
+	} {
+		_, _, err := getArchivePlans(statement, "20221018000000", 1666051200)
+		a.Error(err, statement)
+	}
+}
+
+func TestGetArchiveTableName(t *testing.T) {
+	a := require.New(t)
+	a.Equal("20221018000000_1_tbl", getArchiveTableName("20221018000000", 1, "tbl"))
+	name := getArchiveTableName("20221018000000", 1, strings.Repeat("表", 30))
+	a.LessOrEqual(len(name), maxIdentifierLength)
+	a.True(strings.HasPrefix(name, "20221018000000_1_表"))
+}
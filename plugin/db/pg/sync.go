@@ -325,7 +325,7 @@ func getPgTables(txn *sql.Tx) ([]*tableSchema, error) {
 		obj_description(format('%s.%s', quote_ident(tbl.schemaname), quote_ident(tbl.tablename))::regclass) AS comment
 	FROM pg_catalog.pg_tables tbl
 	LEFT JOIN pg_class as pc ON pc.oid = format('%s.%s', quote_ident(tbl.schemaname), quote_ident(tbl.tablename))::regclass
-	WHERE tbl.schemaname NOT IN ('pg_catalog', 'information_schema');`
+	WHERE tbl.schemaname NOT IN ('pg_catalog', 'information_schema', 'bbdataarchive');`
 	rows, err := txn.Query(query)
 	if err != nil {
 		return nil, err
@@ -381,7 +381,7 @@ func getTableColumns(txn *sql.Tx) (map[string][]*columnSchema, error) {
 		cols.udt_name,
 		pg_catalog.col_description(format('%s.%s', quote_ident(table_schema), quote_ident(table_name))::regclass, cols.ordinal_position::int) as column_comment
 	FROM INFORMATION_SCHEMA.COLUMNS AS cols
-	WHERE cols.table_schema NOT IN ('pg_catalog', 'information_schema');`
+	WHERE cols.table_schema NOT IN ('pg_catalog', 'information_schema', 'bbdataarchive');`
 	rows, err := txn.Query(query)
 	if err != nil {
 		return nil, err
@@ -431,7 +431,7 @@ func getTableConstraints(txn *sql.Tx) (map[string][]*tableConstraint, error) {
 		"SELECT n.nspname, conrelid::regclass, conname, pg_get_constraintdef(c.oid) " +
 		"FROM pg_constraint c " +
 		"JOIN pg_namespace n ON n.oid = c.connamespace " +
-		"WHERE n.nspname NOT IN ('pg_catalog', 'information_schema');"
+		"WHERE n.nspname NOT IN ('pg_catalog', 'information_schema', 'bbdataarchive');"
 	ret := make(map[string][]*tableConstraint)
 	rows, err := txn.Query(query)
 	if err != nil {
@@ -460,7 +460,7 @@ func getTableConstraints(txn *sql.Tx) (map[string][]*tableConstraint, error) {
 func getViews(txn *sql.Tx) ([]*viewSchema, error) {
 	query := `
 	SELECT schemaname, viewname, definition, obj_description(format('%s.%s', quote_ident(schemaname), quote_ident(viewname))::regclass) FROM pg_catalog.pg_views
-	WHERE schemaname NOT IN ('pg_catalog', 'information_schema');`
+	WHERE schemaname NOT IN ('pg_catalog', 'information_schema', 'bbdataarchive');`
 	var views []*viewSchema
 	rows, err := txn.Query(query)
 	if err != nil {
@@ -532,7 +532,7 @@ func getIndices(txn *sql.Tx) ([]*indexSchema, error) {
 		AND table_name = idx.tablename
 		AND constraint_type = 'PRIMARY KEY') AS primary,
 		obj_description(format('%s.%s', quote_ident(idx.schemaname), quote_ident(idx.indexname))::regclass) AS comment
-	FROM pg_indexes AS idx WHERE idx.schemaname NOT IN ('pg_catalog', 'information_schema');`
+	FROM pg_indexes AS idx WHERE idx.schemaname NOT IN ('pg_catalog', 'information_schema', 'bbdataarchive');`
 
 	var indices []*indexSchema
 	rows, err := txn.Query(query)
@@ -215,37 +215,8 @@ func BeginMigration(ctx context.Context, executor MigrationExecutor, m *db.Migra
 		return 0, errors.Wrap(err, "failed to convert to stored version")
 	}
 	// Phase 1 - Pre-check before executing migration
-	// Check if the same migration version has already been applied.
-	if list, err := executor.FindMigrationHistoryList(ctx, &db.MigrationHistoryFind{
-		Database: &m.Namespace,
-		Version:  &m.Version,
-	}); err != nil {
-		return -1, errors.Wrap(err, "failed to check duplicate version")
-	} else if len(list) > 0 {
-		migrationHistory := list[0]
-		switch migrationHistory.Status {
-		case db.Done:
-			if migrationHistory.IssueID != m.IssueID {
-				return int64(migrationHistory.ID), common.Errorf(common.MigrationFailed, "database %q has already applied version %s by issue %s", m.Database, m.Version, migrationHistory.IssueID)
-			}
-			return int64(migrationHistory.ID), common.Errorf(common.MigrationAlreadyApplied, "database %q has already applied version %s", m.Database, m.Version)
-		case db.Pending:
-			err := errors.Errorf("database %q version %s migration is already in progress", m.Database, m.Version)
-			log.Debug(err.Error())
-			// For force migration, we will ignore the existing migration history and continue to migration.
-			if m.Force {
-				return int64(migrationHistory.ID), nil
-			}
-			return -1, common.Wrap(err, common.MigrationPending)
-		case db.Failed:
-			err := errors.Errorf("database %q version %s migration has failed, please check your database to make sure things are fine and then start a new migration using a new version ", m.Database, m.Version)
-			log.Debug(err.Error())
-			// For force migration, we will ignore the existing migration history and continue to migration.
-			if m.Force {
-				return int64(migrationHistory.ID), nil
-			}
-			return -1, common.Wrap(err, common.MigrationFailed)
-		}
+	if migrationHistoryID, reuse, err := CheckDuplicateVersion(ctx, executor, m); err != nil || reuse {
+		return migrationHistoryID, err
 	}
 
 	sqldb, err := executor.GetDBConnection(ctx, databaseName)
@@ -287,6 +258,45 @@ func BeginMigration(ctx context.Context, executor MigrationExecutor, m *db.Migra
 	return insertedID, nil
 }
 
+// CheckDuplicateVersion checks if the same migration version has already been applied.
+// It returns the ID of the existing migration history and true if the existing migration history should be reused by a force migration.
+// It's the pre-check of BeginMigration, and is shared with the drivers which record the migration history without SQL, e.g. MongoDB.
+func CheckDuplicateVersion(ctx context.Context, driver db.Driver, m *db.MigrationInfo) (int64, bool, error) {
+	// Check if the same migration version has already been applied.
+	if list, err := driver.FindMigrationHistoryList(ctx, &db.MigrationHistoryFind{
+		Database: &m.Namespace,
+		Version:  &m.Version,
+	}); err != nil {
+		return -1, false, errors.Wrap(err, "failed to check duplicate version")
+	} else if len(list) > 0 {
+		migrationHistory := list[0]
+		switch migrationHistory.Status {
+		case db.Done:
+			if migrationHistory.IssueID != m.IssueID {
+				return int64(migrationHistory.ID), false, common.Errorf(common.MigrationFailed, "database %q has already applied version %s by issue %s", m.Database, m.Version, migrationHistory.IssueID)
+			}
+			return int64(migrationHistory.ID), false, common.Errorf(common.MigrationAlreadyApplied, "database %q has already applied version %s", m.Database, m.Version)
+		case db.Pending:
+			err := errors.Errorf("database %q version %s migration is already in progress", m.Database, m.Version)
+			log.Debug(err.Error())
+			// For force migration, we will ignore the existing migration history and continue to migration.
+			if m.Force {
+				return int64(migrationHistory.ID), true, nil
+			}
+			return -1, false, common.Wrap(err, common.MigrationPending)
+		case db.Failed:
+			err := errors.Errorf("database %q version %s migration has failed, please check your database to make sure things are fine and then start a new migration using a new version ", m.Database, m.Version)
+			log.Debug(err.Error())
+			// For force migration, we will ignore the existing migration history and continue to migration.
+			if m.Force {
+				return int64(migrationHistory.ID), true, nil
+			}
+			return -1, false, common.Wrap(err, common.MigrationFailed)
+		}
+	}
+	return -1, false, nil
+}
+
 // EndMigration updates the migration history record to DONE or FAILED depending on migration is done or not.
 func EndMigration(ctx context.Context, executor MigrationExecutor, startedNs int64, migrationHistoryID int64, updatedSchema string, databaseName string, isDone bool) (err error) {
 	migrationDurationNs := time.Now().UnixNano() - startedNs
@@ -318,20 +328,14 @@ func EndMigration(ctx context.Context, executor MigrationExecutor, startedNs int
 
 // Query will execute a readonly / SELECT query.
 func Query(ctx context.Context, dbType db.Type, sqldb *sql.DB, statement string, limit int) ([]interface{}, error) {
-	// Limit SQL query result size.
-	if dbType == db.MySQL {
-		// MySQL 5.7 doesn't support WITH clause.
-		statement = getMySQLStatementWithResultLimit(statement, limit)
-	} else {
-		statement = getStatementWithResultLimit(statement, limit)
-	}
-
 	// Not all sql engines support ReadOnly flag, so we will use tx rollback semantics to enforce readonly.
 	readOnly := true
 	// TiDB doesn't support READ ONLY transactions. We have to skip the flag for it.
 	// https://github.com/pingcap/tidb/issues/34626
 	// Clickhouse doesn't support READ ONLY transactions (Error: sql: driver does not support read-only transactions).
-	if dbType == db.TiDB || dbType == db.ClickHouse {
+	// SQL Server doesn't support READ ONLY transactions (Error: read-only transactions are not supported).
+	// Oracle doesn't support READ ONLY transactions in the driver, and SET TRANSACTION READ ONLY disallows altering the session.
+	if dbType == db.TiDB || dbType == db.ClickHouse || dbType == db.MSSQL || dbType == db.Oracle {
 		readOnly = false
 	}
 	tx, err := sqldb.BeginTx(ctx, &sql.TxOptions{ReadOnly: readOnly})
@@ -340,6 +344,27 @@ func Query(ctx context.Context, dbType db.Type, sqldb *sql.DB, statement string,
 	}
 	defer tx.Rollback()
 
+	return QueryTx(ctx, dbType, tx, statement, limit)
+}
+
+// QueryTx will execute a readonly / SELECT query in the given transaction.
+// It's useful for the engines which need to set up the session before querying, e.g. setting the current schema in Oracle.
+func QueryTx(ctx context.Context, dbType db.Type, tx *sql.Tx, statement string, limit int) ([]interface{}, error) {
+	// Limit SQL query result size.
+	switch dbType {
+	case db.MySQL:
+		// MySQL 5.7 doesn't support WITH clause.
+		statement = getMySQLStatementWithResultLimit(statement, limit)
+	case db.MSSQL:
+		// SQL Server doesn't support LIMIT clause.
+		statement = getMSSQLStatementWithResultLimit(statement, limit)
+	case db.Oracle:
+		// Oracle doesn't support LIMIT clause.
+		statement = getOracleStatementWithResultLimit(statement, limit)
+	default:
+		statement = getStatementWithResultLimit(statement, limit)
+	}
+
 	rows, err := tx.QueryContext(ctx, statement)
 	if err != nil {
 		return nil, FormatErrorWithQuery(err, statement)
@@ -447,6 +472,145 @@ func getMySQLStatementWithResultLimit(stmt string, limit int) string {
 	return stmt
 }
 
+// getMSSQLStatementWithResultLimit limits the result of the SELECT statement.
+// Wrapping the statement in a derived table breaks the ORDER BY and WITH clauses, so we
+// 1. append OFFSET 0 ROWS FETCH NEXT n ROWS ONLY if the statement has the ORDER BY clause,
+// 2. wrap the main query after the WITH clause in a derived table if the statement has the UNION, EXCEPT or INTERSECT operator,
+// 3. add TOP (n) to the main query otherwise.
+// The statement is not changed if it limits the result itself by TOP or OFFSET, or has the FOR or OPTION clause.
+func getMSSQLStatementWithResultLimit(stmt string, limit int) string {
+	stmt = strings.TrimRight(stmt, " \n\t;")
+	if limit <= 0 {
+		return stmt
+	}
+	wordList := getMSSQLTopLevelWordList(stmt)
+	if len(wordList) == 0 || (wordList[0].text != "SELECT" && wordList[0].text != "WITH") {
+		return stmt
+	}
+
+	// The main query starts from the first top-level SELECT, because the common table expressions are in the parentheses.
+	selectIndex := -1
+	for i, word := range wordList {
+		if word.text == "SELECT" {
+			selectIndex = i
+			break
+		}
+	}
+	if selectIndex < 0 {
+		return stmt
+	}
+	// The TOP clause follows the optional ALL or DISTINCT.
+	topIndex := selectIndex
+	if topIndex+1 < len(wordList) && (wordList[topIndex+1].text == "ALL" || wordList[topIndex+1].text == "DISTINCT") {
+		topIndex++
+	}
+	if topIndex+1 < len(wordList) && wordList[topIndex+1].text == "TOP" {
+		return stmt
+	}
+
+	hasOrderBy, hasSetOperator := false, false
+	for i, word := range wordList[selectIndex:] {
+		switch word.text {
+		case "OFFSET", "FOR", "OPTION":
+			return stmt
+		case "UNION", "EXCEPT", "INTERSECT":
+			hasSetOperator = true
+		case "ORDER":
+			if selectIndex+i+1 < len(wordList) && wordList[selectIndex+i+1].text == "BY" {
+				hasOrderBy = true
+			}
+		}
+	}
+
+	switch {
+	case hasOrderBy:
+		return fmt.Sprintf("%s OFFSET 0 ROWS FETCH NEXT %d ROWS ONLY;", stmt, limit)
+	case hasSetOperator:
+		mainQueryStart := wordList[selectIndex].start
+		return fmt.Sprintf("%sSELECT TOP (%d) * FROM (%s) result;", stmt[:mainQueryStart], limit, stmt[mainQueryStart:])
+	default:
+		topStart := wordList[topIndex].end
+		return fmt.Sprintf("%s TOP (%d)%s;", stmt[:topStart], limit, stmt[topStart:])
+	}
+}
+
+// mssqlWord is a keyword or an unquoted identifier in the SQL Server statement.
+type mssqlWord struct {
+	// text is in upper case.
+	text  string
+	start int
+	end   int
+}
+
+// getMSSQLTopLevelWordList returns the words not in the parentheses, strings, quoted identifiers or comments.
+func getMSSQLTopLevelWordList(stmt string) []mssqlWord {
+	var wordList []mssqlWord
+	depth := 0
+	for i := 0; i < len(stmt); {
+		c := stmt[i]
+		switch {
+		case c == '\'' || c == '"' || c == '[':
+			closing := c
+			if c == '[' {
+				closing = ']'
+			}
+			i++
+			for i < len(stmt) {
+				if stmt[i] == closing {
+					// The closing character is escaped by doubling it.
+					if i+1 < len(stmt) && stmt[i+1] == closing {
+						i += 2
+						continue
+					}
+					break
+				}
+				i++
+			}
+			i++
+		case c == '-' && i+1 < len(stmt) && stmt[i+1] == '-':
+			for i < len(stmt) && stmt[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(stmt) && stmt[i+1] == '*':
+			end := strings.Index(stmt[i+2:], "*/")
+			if end < 0 {
+				i = len(stmt)
+			} else {
+				i += end + 4
+			}
+		case c == '(':
+			depth++
+			i++
+		case c == ')':
+			depth--
+			i++
+		case isMSSQLWordChar(c):
+			start := i
+			for i < len(stmt) && isMSSQLWordChar(stmt[i]) {
+				i++
+			}
+			if depth == 0 {
+				wordList = append(wordList, mssqlWord{text: strings.ToUpper(stmt[start:i]), start: start, end: i})
+			}
+		default:
+			i++
+		}
+	}
+	return wordList
+}
+
+func isMSSQLWordChar(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '_' || c == '@' || c == '#' || c == '$' || c >= 0x80
+}
+
+func getOracleStatementWithResultLimit(stmt string, limit int) string {
+	stmt = strings.TrimRight(stmt, " \n\t;")
+	if limit > 0 && strings.HasPrefix(strings.ToUpper(strings.TrimSpace(stmt)), "SELECT") {
+		return fmt.Sprintf("SELECT * FROM (%s) WHERE ROWNUM <= %d", stmt, limit)
+	}
+	return stmt
+}
+
 // FindMigrationHistoryList will find the list of migration history.
 func FindMigrationHistoryList(ctx context.Context, findMigrationHistoryListQuery string, queryParams []interface{}, driver db.Driver, database string) ([]*db.MigrationHistory, error) {
 	// To support `pg` option, the util layer will not know which database where `migration_history` table is,
@@ -496,7 +660,7 @@ func FindMigrationHistoryList(ctx context.Context, findMigrationHistoryListQuery
 			return nil, err
 		}
 
-		useSemanticVersion, version, semanticVersionSuffix, err := fromStoredVersion(storedVersion)
+		useSemanticVersion, version, semanticVersionSuffix, err := FromStoredVersion(storedVersion)
 		if err != nil {
 			return nil, err
 		}
@@ -550,8 +714,8 @@ func ToStoredVersion(useSemanticVersion bool, version, semanticVersionSuffix str
 	return fmt.Sprintf("%04s.%04s.%04s-%s", major, minor, patch, semanticVersionSuffix), nil
 }
 
-// fromStoredVersion converts stored version to semantic or non-semantic version.
-func fromStoredVersion(storedVersion string) (bool, string, string, error) {
+// FromStoredVersion converts stored version to semantic or non-semantic version.
+func FromStoredVersion(storedVersion string) (bool, string, string, error) {
 	if strings.HasPrefix(storedVersion, NonSemanticPrefix) {
 		return false, strings.TrimPrefix(storedVersion, NonSemanticPrefix), "", nil
 	}
@@ -1,6 +1,7 @@
 package util
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 	"strings"
@@ -8,6 +9,9 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/plugin/db"
 )
 
 func TestToStoredVersion(t *testing.T) {
@@ -61,7 +65,7 @@ func TestFromStoredVersion(t *testing.T) {
 		{"1.2.3", false, "", "", "should contain '-'"},
 	}
 	for _, tc := range tests {
-		gotUseSemanticVersion, gotVersion, gotSemanticVersionSuffix, err := fromStoredVersion(tc.storedVersion)
+		gotUseSemanticVersion, gotVersion, gotSemanticVersionSuffix, err := FromStoredVersion(tc.storedVersion)
 		if tc.wantErr != "" {
 			require.Contains(t, err.Error(), tc.wantErr)
 			continue
@@ -175,6 +179,108 @@ func TestGetMySQLStatementWithResultLimit(t *testing.T) {
 	}
 }
 
+func TestGetMSSQLStatementWithResultLimit(t *testing.T) {
+	tests := []struct {
+		sqlStatement string
+		limit        int
+		want         string
+	}{
+		{
+			sqlStatement: "  seLeCT * FROM test;",
+			limit:        123,
+			want:         "  seLeCT TOP (123) * FROM test;",
+		},
+		{
+			sqlStatement: "  seLeCT * FROM test;",
+			limit:        0,
+			want:         "  seLeCT * FROM test",
+		},
+		{
+			sqlStatement: "SELECT\n*\nFROM\n[test;]  ;;;\n",
+			limit:        100,
+			want:         "SELECT TOP (100)\n*\nFROM\n[test;];",
+		},
+		{
+			sqlStatement: "SELECT DISTINCT name FROM test",
+			limit:        100,
+			want:         "SELECT DISTINCT TOP (100) name FROM test;",
+		},
+		{
+			sqlStatement: "SELECT name, ROW_NUMBER() OVER (ORDER BY id) FROM test ORDER BY name;",
+			limit:        100,
+			want:         "SELECT name, ROW_NUMBER() OVER (ORDER BY id) FROM test ORDER BY name OFFSET 0 ROWS FETCH NEXT 100 ROWS ONLY;",
+		},
+		{
+			sqlStatement: "WITH t AS (SELECT TOP 10 * FROM test ORDER BY id) SELECT * FROM t",
+			limit:        100,
+			want:         "WITH t AS (SELECT TOP 10 * FROM test ORDER BY id) SELECT TOP (100) * FROM t;",
+		},
+		{
+			sqlStatement: "WITH t AS (SELECT * FROM test) SELECT id FROM t UNION SELECT id FROM test2",
+			limit:        100,
+			want:         "WITH t AS (SELECT * FROM test) SELECT TOP (100) * FROM (SELECT id FROM t UNION SELECT id FROM test2) result;",
+		},
+		{
+			sqlStatement: "-- select ' order by\nSELECT 'ORDER BY' AS [order by] FROM test",
+			limit:        100,
+			want:         "-- select ' order by\nSELECT TOP (100) 'ORDER BY' AS [order by] FROM test;",
+		},
+		{
+			sqlStatement: "SELECT TOP 5 * FROM test",
+			limit:        100,
+			want:         "SELECT TOP 5 * FROM test",
+		},
+		{
+			sqlStatement: "SELECT * FROM test ORDER BY id OFFSET 10 ROWS",
+			limit:        100,
+			want:         "SELECT * FROM test ORDER BY id OFFSET 10 ROWS",
+		},
+		{
+			sqlStatement: "EXEC sp_help 'test';",
+			limit:        100,
+			want:         "EXEC sp_help 'test'",
+		},
+	}
+
+	for _, test := range tests {
+		got := getMSSQLStatementWithResultLimit(test.sqlStatement, test.limit)
+		if got != test.want {
+			t.Errorf("trimSQLStatement %q: got result %v, want %v.", test.sqlStatement, got, test.want)
+		}
+	}
+}
+
+func TestGetOracleStatementWithResultLimit(t *testing.T) {
+	tests := []struct {
+		sqlStatement string
+		limit        int
+		want         string
+	}{
+		{
+			sqlStatement: "  seLeCT * FROM test;",
+			limit:        123,
+			want:         "SELECT * FROM (  seLeCT * FROM test) WHERE ROWNUM <= 123",
+		},
+		{
+			sqlStatement: "SELECT * FROM test;\n",
+			limit:        0,
+			want:         "SELECT * FROM test",
+		},
+		{
+			sqlStatement: "EXPLAIN PLAN FOR SELECT * FROM test;",
+			limit:        100,
+			want:         "EXPLAIN PLAN FOR SELECT * FROM test",
+		},
+	}
+
+	for _, test := range tests {
+		got := getOracleStatementWithResultLimit(test.sqlStatement, test.limit)
+		if got != test.want {
+			t.Errorf("trimSQLStatement %q: got result %v, want %v.", test.sqlStatement, got, test.want)
+		}
+	}
+}
+
 func TestApplyMultiStatements(t *testing.T) {
 	type testData struct {
 		statement string
@@ -232,3 +338,84 @@ func generateOneMBInsert() string {
 	}
 	return fmt.Sprintf("INSERT INTO t values('%s')", string(b))
 }
+
+// migrationHistoryDriver is the driver which only finds the given migration history.
+type migrationHistoryDriver struct {
+	db.Driver
+
+	historyList []*db.MigrationHistory
+}
+
+func (driver *migrationHistoryDriver) FindMigrationHistoryList(context.Context, *db.MigrationHistoryFind) ([]*db.MigrationHistory, error) {
+	return driver.historyList, nil
+}
+
+func TestCheckDuplicateVersion(t *testing.T) {
+	tests := []struct {
+		status    db.MigrationStatus
+		issueID   string
+		force     bool
+		wantID    int64
+		wantReuse bool
+		wantCode  common.Code
+	}{
+		{
+			// No existing migration history.
+			wantID: -1,
+		},
+		{
+			status:   db.Done,
+			issueID:  "101",
+			wantID:   1,
+			wantCode: common.MigrationAlreadyApplied,
+		},
+		{
+			status:   db.Done,
+			issueID:  "102",
+			wantID:   1,
+			wantCode: common.MigrationFailed,
+		},
+		{
+			status:   db.Pending,
+			issueID:  "101",
+			wantID:   -1,
+			wantCode: common.MigrationPending,
+		},
+		{
+			status:    db.Pending,
+			issueID:   "101",
+			force:     true,
+			wantID:    1,
+			wantReuse: true,
+		},
+		{
+			status:   db.Failed,
+			issueID:  "101",
+			wantID:   -1,
+			wantCode: common.MigrationFailed,
+		},
+		{
+			status:    db.Failed,
+			issueID:   "101",
+			force:     true,
+			wantID:    1,
+			wantReuse: true,
+		},
+	}
+
+	for _, test := range tests {
+		driver := &migrationHistoryDriver{}
+		if test.status != "" {
+			driver.historyList = []*db.MigrationHistory{{ID: 1, Status: test.status, IssueID: test.issueID}}
+		}
+		m := &db.MigrationInfo{Database: "db", Namespace: "db", Version: "ver1", IssueID: "101", Force: test.force}
+		id, reuse, err := CheckDuplicateVersion(context.Background(), driver, m)
+		require.Equal(t, test.wantID, id)
+		require.Equal(t, test.wantReuse, reuse)
+		if test.wantCode == common.Ok {
+			require.NoError(t, err)
+		} else {
+			require.Equal(t, test.wantCode, common.ErrorCode(err))
+		}
+	}
+}
@@ -23,6 +23,20 @@ func GetPITROldDatabaseName(database string, suffixTs int64) string {
 	return GetSafeName(database, suffix)
 }
 
+// GetBackupVerificationDatabaseName composes a scratch database name that we restore the backup into for verification.
+// For example, GetBackupVerificationDatabaseName("dbfoo", 1653018005) -> "dbfoo_verify_1653018005".
+func GetBackupVerificationDatabaseName(database string, suffixTs int64) string {
+	suffix := fmt.Sprintf("verify_%d", suffixTs)
+	return GetSafeName(database, suffix)
+}
+
+// GetDryRunDatabaseName composes a scratch database name that we clone the schema into for dry running a migration.
+// For example, GetDryRunDatabaseName("dbfoo", 1653018005) -> "dbfoo_dryrun_1653018005".
+func GetDryRunDatabaseName(database string, suffixTs int64) string {
+	suffix := fmt.Sprintf("dryrun_%d", suffixTs)
+	return GetSafeName(database, suffix)
+}
+
 // GetSafeName trims the name according to max allowed database name length.
 func GetSafeName(baseName, suffix string) string {
 	name := fmt.Sprintf("%s_%s", baseName, suffix)
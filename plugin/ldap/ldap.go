@@ -0,0 +1,270 @@
+// Package ldap provides the client for authenticating users and listing group members against an LDAP server, e.g., Active Directory.
+package ldap
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/pkg/errors"
+
+	"github.com/bytebase/bytebase/common"
+)
+
+// SecurityProtocol is the protocol to secure the connection to the LDAP server.
+type SecurityProtocol string
+
+const (
+	// SecurityProtocolNone connects to the LDAP server in plain text.
+	SecurityProtocolNone SecurityProtocol = ""
+	// SecurityProtocolStartTLS upgrades the plain text connection with the StartTLS extended operation.
+	SecurityProtocolStartTLS SecurityProtocol = "starttls"
+	// SecurityProtocolLDAPS connects to the LDAP server over TLS, i.e., ldaps://.
+	SecurityProtocolLDAPS SecurityProtocol = "ldaps"
+
+	// DefaultEmailAttribute is the default attribute of the user email.
+	DefaultEmailAttribute = "mail"
+	// DefaultNameAttribute is the default attribute of the user display name.
+	DefaultNameAttribute = "cn"
+
+	dialTimeout = 10 * time.Second
+	// The attributes of a group entry listing the DNs of its members,
+	// "member" is used by Active Directory and groupOfNames, "uniqueMember" is used by groupOfUniqueNames.
+	memberAttribute       = "member"
+	uniqueMemberAttribute = "uniqueMember"
+)
+
+// Config is the configuration of the LDAP client.
+type Config struct {
+	Host             string
+	Port             int
+	SecurityProtocol SecurityProtocol
+	// SkipTLSVerify skips the verification of the server certificate, which is only meant for testing.
+	SkipTLSVerify bool
+	// BindDN and BindPassword are the credentials of the service account to search the directory.
+	BindDN       string
+	BindPassword string
+	// BaseDN is the base DN to search the users.
+	BaseDN string
+	// UserFilter is the filter to find the user by the username, where "%s" is replaced by the escaped username,
+	// e.g., "(uid=%s)" for OpenLDAP and "(sAMAccountName=%s)" for Active Directory.
+	UserFilter     string
+	EmailAttribute string
+	NameAttribute  string
+}
+
+// User is the user entry in the LDAP directory.
+type User struct {
+	DN    string
+	Email string
+	Name  string
+}
+
+// Client is the LDAP client bound as the service account.
+type Client struct {
+	config Config
+	conn   *ldap.Conn
+}
+
+// Validate validates the LDAP configuration.
+func (c *Config) Validate() error {
+	if c.Host == "" {
+		return errors.New("LDAP host is required")
+	}
+	if c.Port <= 0 || c.Port > 65535 {
+		return errors.Errorf("invalid LDAP port %d", c.Port)
+	}
+	switch c.SecurityProtocol {
+	case SecurityProtocolNone, SecurityProtocolStartTLS, SecurityProtocolLDAPS:
+	default:
+		return errors.Errorf("invalid LDAP security protocol %q, should be one of %q, %q, %q", c.SecurityProtocol, SecurityProtocolNone, SecurityProtocolStartTLS, SecurityProtocolLDAPS)
+	}
+	if c.BaseDN == "" {
+		return errors.New("LDAP base DN is required")
+	}
+	if strings.Count(c.UserFilter, "%s") != 1 {
+		return errors.Errorf("invalid LDAP user filter %q, should contain exactly one %%s for the username", c.UserFilter)
+	}
+	if _, err := ldap.CompileFilter(fmt.Sprintf(c.UserFilter, "username")); err != nil {
+		return errors.Wrapf(err, "invalid LDAP user filter %q", c.UserFilter)
+	}
+	return nil
+}
+
+// NewClient connects to the LDAP server and binds as the service account.
+// The caller should close the client after use.
+func NewClient(config Config) (*Client, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	if config.EmailAttribute == "" {
+		config.EmailAttribute = DefaultEmailAttribute
+	}
+	if config.NameAttribute == "" {
+		config.NameAttribute = DefaultNameAttribute
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName: config.Host,
+		// #nosec G402
+		InsecureSkipVerify: config.SkipTLSVerify,
+	}
+	address := net.JoinHostPort(config.Host, strconv.Itoa(config.Port))
+	scheme := "ldap"
+	if config.SecurityProtocol == SecurityProtocolLDAPS {
+		scheme = "ldaps"
+	}
+	conn, err := ldap.DialURL(fmt.Sprintf("%s://%s", scheme, address),
+		ldap.DialWithDialer(&net.Dialer{Timeout: dialTimeout}),
+		ldap.DialWithTLSConfig(tlsConfig),
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to connect to LDAP server %s", address)
+	}
+	if config.SecurityProtocol == SecurityProtocolStartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, errors.Wrapf(err, "failed to start TLS with LDAP server %s", address)
+		}
+	}
+
+	client := &Client{
+		config: config,
+		conn:   conn,
+	}
+	if err := client.bindServiceAccount(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+// Close closes the connection to the LDAP server.
+func (c *Client) Close() {
+	c.conn.Close()
+}
+
+func (c *Client) bindServiceAccount() error {
+	if c.config.BindDN == "" {
+		// Anonymous bind.
+		if err := c.conn.UnauthenticatedBind(""); err != nil {
+			return errors.Wrap(err, "failed to bind LDAP anonymously")
+		}
+		return nil
+	}
+	if err := c.conn.Bind(c.config.BindDN, c.config.BindPassword); err != nil {
+		return errors.Wrapf(err, "failed to bind LDAP as %q", c.config.BindDN)
+	}
+	return nil
+}
+
+// Authenticate finds the user by the username with the user filter and verifies the password by binding as the user.
+// It returns a NotAuthorized error if the user is not found or the password is incorrect.
+// The client is re-bound as the service account afterwards so that it can be reused.
+func (c *Client) Authenticate(username, password string) (*User, error) {
+	// An empty password makes an unauthenticated bind that always succeeds, see RFC 4513 section 5.1.2.
+	if username == "" || password == "" {
+		return nil, common.Errorf(common.NotAuthorized, "username and password are required")
+	}
+	filter := fmt.Sprintf(c.config.UserFilter, ldap.EscapeFilter(username))
+	entryList, err := c.search(c.config.BaseDN, ldap.ScopeWholeSubtree, filter, 2 /* sizeLimit */)
+	if err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+			return nil, errors.Errorf("found multiple LDAP users with filter %q", filter)
+		}
+		return nil, errors.Wrapf(err, "failed to search LDAP user with filter %q", filter)
+	}
+	if len(entryList) == 0 {
+		return nil, common.Errorf(common.NotAuthorized, "LDAP user not found: %s", username)
+	}
+	if len(entryList) > 1 {
+		return nil, errors.Errorf("found multiple LDAP users with filter %q", filter)
+	}
+	entry := entryList[0]
+
+	if err := c.conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, common.Errorf(common.NotAuthorized, "incorrect password for LDAP user: %s", username)
+		}
+		return nil, errors.Wrapf(err, "failed to bind LDAP as %q", entry.DN)
+	}
+	if err := c.bindServiceAccount(); err != nil {
+		return nil, err
+	}
+	return c.convertUser(entry), nil
+}
+
+// ListGroupMember returns the users listed in the "member" and "uniqueMember" attributes of the group.
+// The nested groups and the members without an email are skipped.
+func (c *Client) ListGroupMember(groupDN string) ([]*User, error) {
+	groupList, err := c.search(groupDN, ldap.ScopeBaseObject, "(objectClass=*)", 0 /* sizeLimit */, memberAttribute, uniqueMemberAttribute)
+	if err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+			return nil, common.Errorf(common.NotFound, "LDAP group not found: %s", groupDN)
+		}
+		return nil, errors.Wrapf(err, "failed to find LDAP group %q", groupDN)
+	}
+	if len(groupList) == 0 {
+		return nil, common.Errorf(common.NotFound, "LDAP group not found: %s", groupDN)
+	}
+	group := groupList[0]
+	memberDNList := append(group.GetEqualFoldAttributeValues(memberAttribute), group.GetEqualFoldAttributeValues(uniqueMemberAttribute)...)
+
+	var userList []*User
+	for _, memberDN := range memberDNList {
+		entryList, err := c.search(memberDN, ldap.ScopeBaseObject, "(objectClass=*)", 0 /* sizeLimit */)
+		if err != nil {
+			// The group may refer to the entries that have been deleted.
+			if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+				continue
+			}
+			return nil, errors.Wrapf(err, "failed to find LDAP member %q of group %q", memberDN, groupDN)
+		}
+		if len(entryList) == 0 {
+			continue
+		}
+		user := c.convertUser(entryList[0])
+		if user.Email == "" {
+			continue
+		}
+		userList = append(userList, user)
+	}
+	return userList, nil
+}
+
+func (c *Client) search(baseDN string, scope int, filter string, sizeLimit int, attributes ...string) ([]*ldap.Entry, error) {
+	if len(attributes) == 0 {
+		attributes = []string{c.config.EmailAttribute, c.config.NameAttribute}
+	}
+	result, err := c.conn.Search(ldap.NewSearchRequest(
+		baseDN,
+		scope,
+		ldap.NeverDerefAliases,
+		sizeLimit,
+		int(dialTimeout.Seconds()),
+		false, /* typesOnly */
+		filter,
+		attributes,
+		nil, /* controls */
+	))
+	if err != nil {
+		return nil, err
+	}
+	return result.Entries, nil
+}
+
+func (c *Client) convertUser(entry *ldap.Entry) *User {
+	user := &User{
+		DN:    entry.DN,
+		Email: strings.ToLower(entry.GetEqualFoldAttributeValue(c.config.EmailAttribute)),
+		Name:  entry.GetEqualFoldAttributeValue(c.config.NameAttribute),
+	}
+	if user.Name == "" {
+		user.Name = user.Email
+	}
+	return user
+}
@@ -0,0 +1,427 @@
+package ldap
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bytebase/bytebase/common"
+)
+
+const (
+	testBaseDN       = "dc=example,dc=com"
+	testBindDN       = "cn=admin,dc=example,dc=com"
+	testBindPassword = "admin-secret"
+)
+
+// The LDAP protocol operations and result codes used by the test server, see RFC 4511.
+const (
+	applicationBindRequest       = 0
+	applicationBindResponse      = 1
+	applicationUnbindRequest     = 2
+	applicationSearchRequest     = 3
+	applicationSearchResultEntry = 4
+	applicationSearchResultDone  = 5
+	applicationExtendedRequest   = 23
+	applicationExtendedResponse  = 24
+	resultSuccess                = 0
+	resultSizeLimitExceeded      = 4
+	resultNoSuchObject           = 32
+	resultInvalidCredentials     = 49
+	resultUnwillingToPerform     = 53
+	startTLSOID                  = "1.3.6.1.4.1.1466.20037"
+	filterAnd                    = 0
+	filterOr                     = 1
+	filterNot                    = 2
+	filterEqualityMatch          = 3
+	filterPresent                = 7
+	scopeBaseObject              = 0
+)
+
+type testEntry struct {
+	dn         string
+	attributes map[string][]string
+}
+
+// testServer is a minimal in-process LDAP server serving a static directory.
+// It supports the simple bind, the search with the and/or/not/equality/present filters, and StartTLS.
+type testServer struct {
+	t         *testing.T
+	listener  net.Listener
+	tlsConfig *tls.Config
+	entryList []*testEntry
+}
+
+func newTestServer(t *testing.T, securityProtocol SecurityProtocol) *testServer {
+	s := &testServer{
+		t: t,
+		tlsConfig: &tls.Config{
+			Certificates: []tls.Certificate{newTestCertificate(t)},
+			MinVersion:   tls.VersionTLS12,
+		},
+		entryList: []*testEntry{
+			{dn: testBaseDN, attributes: map[string][]string{"objectClass": {"domain"}}},
+			{dn: testBindDN, attributes: map[string][]string{"objectClass": {"person"}, "userPassword": {testBindPassword}}},
+			{dn: "uid=alice,ou=people,dc=example,dc=com", attributes: map[string][]string{
+				"objectClass": {"inetOrgPerson"}, "uid": {"alice"}, "cn": {"Alice"}, "mail": {"Alice@example.com"}, "userPassword": {"alice-secret"},
+			}},
+			{dn: "uid=bob,ou=people,dc=example,dc=com", attributes: map[string][]string{
+				"objectClass": {"inetOrgPerson"}, "uid": {"bob"}, "mail": {"bob@example.com"}, "userPassword": {"bob-secret"},
+			}},
+			// A duplicate uid in another organization unit.
+			{dn: "uid=carol,ou=people,dc=example,dc=com", attributes: map[string][]string{
+				"objectClass": {"inetOrgPerson"}, "uid": {"carol"}, "cn": {"Carol"}, "mail": {"carol@example.com"}, "userPassword": {"carol-secret"},
+			}},
+			{dn: "uid=carol,ou=contractors,dc=example,dc=com", attributes: map[string][]string{
+				"objectClass": {"inetOrgPerson"}, "uid": {"carol"}, "cn": {"Carol"}, "mail": {"carol@contractor.com"}, "userPassword": {"carol-secret"},
+			}},
+			{dn: "cn=dba,ou=groups,dc=example,dc=com", attributes: map[string][]string{
+				"objectClass": {"groupOfNames"},
+				"member":      {"uid=alice,ou=people,dc=example,dc=com", "cn=developer,ou=groups,dc=example,dc=com", "uid=deleted,ou=people,dc=example,dc=com"},
+			}},
+			{dn: "cn=developer,ou=groups,dc=example,dc=com", attributes: map[string][]string{
+				"objectClass":  {"groupOfUniqueNames"},
+				"uniqueMember": {"uid=bob,ou=people,dc=example,dc=com"},
+			}},
+		},
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	if securityProtocol == SecurityProtocolLDAPS {
+		listener = tls.NewListener(listener, s.tlsConfig)
+	}
+	s.listener = listener
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *testServer) config(securityProtocol SecurityProtocol) Config {
+	host, port, err := net.SplitHostPort(s.listener.Addr().String())
+	require.NoError(s.t, err)
+	portNumber, err := strconv.Atoi(port)
+	require.NoError(s.t, err)
+	return Config{
+		Host:             host,
+		Port:             portNumber,
+		SecurityProtocol: securityProtocol,
+		SkipTLSVerify:    true,
+		BindDN:           testBindDN,
+		BindPassword:     testBindPassword,
+		BaseDN:           testBaseDN,
+		UserFilter:       "(&(objectClass=inetOrgPerson)(uid=%s))",
+	}
+}
+
+func (s *testServer) serve(conn net.Conn) {
+	defer conn.Close()
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil {
+			return
+		}
+		messageID := packet.Children[0].Value.(int64)
+		op := packet.Children[1]
+		switch op.Tag {
+		case applicationBindRequest:
+			s.bind(conn, messageID, op)
+		case applicationSearchRequest:
+			s.search(conn, messageID, op)
+		case applicationExtendedRequest:
+			if op.Children[0].Data.String() != startTLSOID {
+				writeResult(conn, messageID, applicationExtendedResponse, resultUnwillingToPerform, "unsupported extended operation")
+				continue
+			}
+			writeResult(conn, messageID, applicationExtendedResponse, resultSuccess, "")
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+		case applicationUnbindRequest:
+			return
+		}
+	}
+}
+
+func (s *testServer) bind(conn net.Conn, messageID int64, op *ber.Packet) {
+	dn := op.Children[1].Data.String()
+	password := op.Children[2].Data.String()
+	if dn == "" && password == "" {
+		writeResult(conn, messageID, applicationBindResponse, resultSuccess, "")
+		return
+	}
+	for _, entry := range s.entryList {
+		if strings.EqualFold(entry.dn, dn) {
+			for _, userPassword := range entry.attributes["userPassword"] {
+				if password != "" && userPassword == password {
+					writeResult(conn, messageID, applicationBindResponse, resultSuccess, "")
+					return
+				}
+			}
+		}
+	}
+	writeResult(conn, messageID, applicationBindResponse, resultInvalidCredentials, "invalid credentials")
+}
+
+func (s *testServer) search(conn net.Conn, messageID int64, op *ber.Packet) {
+	baseDN := strings.ToLower(op.Children[0].Data.String())
+	scope := op.Children[1].Value.(int64)
+	sizeLimit := op.Children[3].Value.(int64)
+	filter := op.Children[6]
+	var attributeList []string
+	for _, attribute := range op.Children[7].Children {
+		attributeList = append(attributeList, attribute.Data.String())
+	}
+
+	found := false
+	var matchedList []*testEntry
+	for _, entry := range s.entryList {
+		dn := strings.ToLower(entry.dn)
+		if dn == baseDN {
+			found = true
+		}
+		if scope == scopeBaseObject && dn != baseDN {
+			continue
+		}
+		// The single level and the whole subtree scopes are not distinguished.
+		if scope != scopeBaseObject && dn != baseDN && !strings.HasSuffix(dn, ","+baseDN) {
+			continue
+		}
+		if matchFilter(entry, filter) {
+			matchedList = append(matchedList, entry)
+		}
+	}
+	if !found {
+		writeResult(conn, messageID, applicationSearchResultDone, resultNoSuchObject, "no such object")
+		return
+	}
+
+	for i, entry := range matchedList {
+		if sizeLimit > 0 && int64(i) >= sizeLimit {
+			writeResult(conn, messageID, applicationSearchResultDone, resultSizeLimitExceeded, "size limit exceeded")
+			return
+		}
+		result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, applicationSearchResultEntry, nil, "Search Result Entry")
+		result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.dn, "Object Name"))
+		attributes := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes")
+		for _, name := range attributeList {
+			for key, valueList := range entry.attributes {
+				if !strings.EqualFold(key, name) {
+					continue
+				}
+				attribute := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attribute")
+				attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, key, "Type"))
+				values := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+				for _, value := range valueList {
+					values.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "Value"))
+				}
+				attribute.AppendChild(values)
+				attributes.AppendChild(attribute)
+			}
+		}
+		result.AppendChild(attributes)
+		writeMessage(conn, messageID, result)
+	}
+	writeResult(conn, messageID, applicationSearchResultDone, resultSuccess, "")
+}
+
+func matchFilter(entry *testEntry, filter *ber.Packet) bool {
+	switch filter.Tag {
+	case filterAnd:
+		for _, child := range filter.Children {
+			if !matchFilter(entry, child) {
+				return false
+			}
+		}
+		return true
+	case filterOr:
+		for _, child := range filter.Children {
+			if matchFilter(entry, child) {
+				return true
+			}
+		}
+		return false
+	case filterNot:
+		return !matchFilter(entry, filter.Children[0])
+	case filterEqualityMatch:
+		name, value := filter.Children[0].Data.String(), filter.Children[1].Data.String()
+		for key, valueList := range entry.attributes {
+			if !strings.EqualFold(key, name) {
+				continue
+			}
+			for _, v := range valueList {
+				if strings.EqualFold(v, value) {
+					return true
+				}
+			}
+		}
+		return false
+	case filterPresent:
+		name := filter.Data.String()
+		for key := range entry.attributes {
+			if strings.EqualFold(key, name) {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+func writeResult(conn net.Conn, messageID int64, tag ber.Tag, resultCode int64, message string) {
+	result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Result")
+	result.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, resultCode, "Result Code"))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, message, "Diagnostic Message"))
+	writeMessage(conn, messageID, result)
+}
+
+func writeMessage(conn net.Conn, messageID int64, op *ber.Packet) {
+	message := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Message")
+	message.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, "Message ID"))
+	message.AppendChild(op)
+	_, _ = conn.Write(message.Bytes())
+}
+
+func newTestCertificate(t *testing.T) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestAuthenticate(t *testing.T) {
+	for _, securityProtocol := range []SecurityProtocol{SecurityProtocolNone, SecurityProtocolStartTLS, SecurityProtocolLDAPS} {
+		s := newTestServer(t, securityProtocol)
+		client, err := NewClient(s.config(securityProtocol))
+		require.NoError(t, err, securityProtocol)
+
+		user, err := client.Authenticate("alice", "alice-secret")
+		require.NoError(t, err, securityProtocol)
+		assert.Equal(t, &User{DN: "uid=alice,ou=people,dc=example,dc=com", Email: "alice@example.com", Name: "Alice"}, user, securityProtocol)
+
+		// The user name falls back to the email, and the client is reusable after authenticating another user.
+		user, err = client.Authenticate("bob", "bob-secret")
+		require.NoError(t, err, securityProtocol)
+		assert.Equal(t, &User{DN: "uid=bob,ou=people,dc=example,dc=com", Email: "bob@example.com", Name: "bob@example.com"}, user, securityProtocol)
+
+		client.Close()
+	}
+}
+
+func TestAuthenticateFailure(t *testing.T) {
+	s := newTestServer(t, SecurityProtocolNone)
+	client, err := NewClient(s.config(SecurityProtocolNone))
+	require.NoError(t, err)
+	defer client.Close()
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		code     common.Code
+	}{
+		{name: "incorrect password", username: "alice", password: "bob-secret", code: common.NotAuthorized},
+		{name: "empty password", username: "alice", password: "", code: common.NotAuthorized},
+		{name: "user not found", username: "dave", password: "dave-secret", code: common.NotAuthorized},
+		{name: "escaped filter", username: "*", password: "alice-secret", code: common.NotAuthorized},
+		{name: "multiple users", username: "carol", password: "carol-secret", code: common.Internal},
+	}
+	for _, test := range tests {
+		_, err := client.Authenticate(test.username, test.password)
+		require.Error(t, err, test.name)
+		assert.Equal(t, test.code, common.ErrorCode(err), test.name)
+	}
+}
+
+func TestNewClientFailure(t *testing.T) {
+	s := newTestServer(t, SecurityProtocolLDAPS)
+
+	config := s.config(SecurityProtocolLDAPS)
+	config.BindPassword = "incorrect"
+	_, err := NewClient(config)
+	require.Error(t, err)
+
+	// The self-signed certificate is rejected without skipping the verification.
+	config = s.config(SecurityProtocolLDAPS)
+	config.SkipTLSVerify = false
+	_, err = NewClient(config)
+	require.Error(t, err)
+}
+
+func TestListGroupMember(t *testing.T) {
+	s := newTestServer(t, SecurityProtocolNone)
+	client, err := NewClient(s.config(SecurityProtocolNone))
+	require.NoError(t, err)
+	defer client.Close()
+
+	// The nested group without email and the deleted member are skipped.
+	userList, err := client.ListGroupMember("cn=dba,ou=groups,dc=example,dc=com")
+	require.NoError(t, err)
+	assert.Equal(t, []*User{{DN: "uid=alice,ou=people,dc=example,dc=com", Email: "alice@example.com", Name: "Alice"}}, userList)
+
+	userList, err = client.ListGroupMember("cn=developer,ou=groups,dc=example,dc=com")
+	require.NoError(t, err)
+	assert.Equal(t, []*User{{DN: "uid=bob,ou=people,dc=example,dc=com", Email: "bob@example.com", Name: "bob@example.com"}}, userList)
+
+	_, err = client.ListGroupMember("cn=unknown,ou=groups,dc=example,dc=com")
+	require.Error(t, err)
+	assert.Equal(t, common.NotFound, common.ErrorCode(err))
+}
+
+func TestConfigValidate(t *testing.T) {
+	valid := Config{Host: "ldap.example.com", Port: 389, BaseDN: testBaseDN, UserFilter: "(uid=%s)"}
+	require.NoError(t, valid.Validate())
+
+	tests := []struct {
+		name   string
+		mutate func(*Config)
+	}{
+		{name: "empty host", mutate: func(c *Config) { c.Host = "" }},
+		{name: "invalid port", mutate: func(c *Config) { c.Port = 0 }},
+		{name: "invalid security protocol", mutate: func(c *Config) { c.SecurityProtocol = "tls" }},
+		{name: "empty base DN", mutate: func(c *Config) { c.BaseDN = "" }},
+		{name: "filter without placeholder", mutate: func(c *Config) { c.UserFilter = "(uid=alice)" }},
+		{name: "malformed filter", mutate: func(c *Config) { c.UserFilter = "(uid=%s" }},
+	}
+	for _, test := range tests {
+		config := valid
+		test.mutate(&config)
+		require.Error(t, config.Validate(), test.name)
+	}
+}
@@ -0,0 +1,15 @@
+package ast
+
+// AlterSequenceStmt is the struct for alter sequence statement.
+type AlterSequenceStmt struct {
+	ddl
+
+	IfExists bool
+	// SequenceDef is the options to alter, and the nil or false fields mean that the option is not changed.
+	SequenceDef SequenceDef
+	// NoCycle is true for the NO CYCLE option.
+	NoCycle bool
+	// Restart is true for the RESTART option, and RestartWith is the value of the RESTART WITH option.
+	Restart     bool
+	RestartWith *int64
+}
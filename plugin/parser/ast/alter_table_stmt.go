@@ -4,6 +4,7 @@ package ast
 type AlterTableStmt struct {
 	ddl
 
+	IfExists      bool
 	Table         *TableDef
 	AlterItemList []Node
 }
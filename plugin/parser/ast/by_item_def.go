@@ -0,0 +1,7 @@
+package ast
+
+// ByItemDef is the struct for the item in ORDER BY clause.
+type ByItemDef struct {
+	node
+	Expression ExpressionNode
+}
@@ -1,8 +1,24 @@
 package ast
 
+// ObjectType is the type for the database objects.
+type ObjectType int
+
+const (
+	// ObjectTypeUndefined is the undefined type.
+	ObjectTypeUndefined ObjectType = iota
+	// ObjectTypeTable is the type for table.
+	ObjectTypeTable
+	// ObjectTypeColumn is the type for column.
+	ObjectTypeColumn
+)
+
 // CommentStmt is the struct for comment statement.
 type CommentStmt struct {
 	node
-
 	Comment string
+	// Type is the type of the commented object.
+	Type ObjectType
+	// Object is the commented object.
+	// It's a *TableDef for ObjectTypeTable, a *ColumnNameDef for ObjectTypeColumn and nil for others.
+	Object Node
 }
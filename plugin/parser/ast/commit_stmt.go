@@ -0,0 +1,6 @@
+package ast
+
+// CommitStmt is the struct for commit statement.
+type CommitStmt struct {
+	node
+}
@@ -22,6 +22,8 @@ const (
 	ConstraintTypeNotNull
 	// ConstraintTypeCheck is the check constraint.
 	ConstraintTypeCheck
+	// ConstraintTypeDefault is the default value of the column, which is a constraint in the PostgreSQL AST.
+	ConstraintTypeDefault
 )
 
 // ConstraintDef is struct for constraint definition.
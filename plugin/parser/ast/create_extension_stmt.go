@@ -0,0 +1,13 @@
+package ast
+
+// CreateExtensionStmt is the struct for create extension statement.
+type CreateExtensionStmt struct {
+	ddl
+
+	IfNotExists bool
+	Name        string
+	// Schema is the schema specified by the WITH SCHEMA clause.
+	Schema string
+	// Version is the version specified by the VERSION clause.
+	Version string
+}
@@ -0,0 +1,22 @@
+package ast
+
+// CreateFunctionStmt is the struct for create function statement.
+type CreateFunctionStmt struct {
+	ddl
+
+	Replace bool
+	// IsProcedure is true for the CREATE PROCEDURE statement.
+	IsProcedure bool
+	Function    *FunctionDef
+	// ReturnType is nil for procedures and for functions that return by the OUT parameters.
+	ReturnType DataType
+	// ReturnSetOf is true for the RETURNS SETOF and the RETURNS TABLE clauses.
+	ReturnSetOf bool
+	Language    string
+	// Body is the definition of the AS clause.
+	Body string
+	// Volatility is IMMUTABLE, STABLE or VOLATILE, and empty if it's not specified.
+	Volatility      string
+	Strict          bool
+	SecurityDefiner bool
+}
@@ -0,0 +1,12 @@
+package ast
+
+// CreateSchemaStmt is the struct for create schema statement.
+type CreateSchemaStmt struct {
+	ddl
+
+	IfNotExists bool
+	// Name is empty for the CREATE SCHEMA AUTHORIZATION statement without schema name, whose schema is named after the owner.
+	Name string
+	// Owner is the role of the AUTHORIZATION clause.
+	Owner string
+}
@@ -0,0 +1,9 @@
+package ast
+
+// CreateSequenceStmt is the struct for create sequence statement.
+type CreateSequenceStmt struct {
+	ddl
+
+	IfNotExists bool
+	SequenceDef SequenceDef
+}
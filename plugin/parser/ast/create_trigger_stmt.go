@@ -0,0 +1,8 @@
+package ast
+
+// CreateTriggerStmt is the struct for create trigger statement.
+type CreateTriggerStmt struct {
+	ddl
+
+	Trigger *TriggerDef
+}
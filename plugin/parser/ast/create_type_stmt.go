@@ -0,0 +1,8 @@
+package ast
+
+// CreateTypeStmt is the struct for create type statement.
+type CreateTypeStmt struct {
+	ddl
+
+	Type UserDefinedType
+}
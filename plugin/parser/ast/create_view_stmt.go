@@ -0,0 +1,26 @@
+package ast
+
+// ViewCheckOption is the type for the WITH CHECK OPTION clause of views.
+type ViewCheckOption int
+
+const (
+	// ViewCheckOptionNone is the view without the WITH CHECK OPTION clause.
+	ViewCheckOptionNone ViewCheckOption = iota
+	// ViewCheckOptionLocal is the WITH LOCAL CHECK OPTION clause.
+	ViewCheckOptionLocal
+	// ViewCheckOptionCascaded is the WITH [CASCADED] CHECK OPTION clause.
+	ViewCheckOptionCascaded
+)
+
+// CreateViewStmt is the struct for create view statement.
+type CreateViewStmt struct {
+	ddl
+
+	Replace bool
+	Name    *TableDef
+	// ColumnList is the column names specified after the view name.
+	ColumnList []string
+	// Definition is the normalized query of the view.
+	Definition  string
+	CheckOption ViewCheckOption
+}
@@ -5,5 +5,6 @@ type DropColumnStmt struct {
 	node
 
 	Table      *TableDef
+	IfExists   bool
 	ColumnName string
 }
@@ -5,5 +5,6 @@ type DropConstraintStmt struct {
 	node
 
 	Table          *TableDef
+	IfExists       bool
 	ConstraintName string
 }
@@ -4,5 +4,6 @@ package ast
 type DropTableStmt struct {
 	ddl
 
+	IfExists  bool
 	TableList []*TableDef
 }
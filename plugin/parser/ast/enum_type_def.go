@@ -0,0 +1,18 @@
+package ast
+
+var (
+	_ UserDefinedType = (*EnumTypeDef)(nil)
+)
+
+// EnumTypeDef is the struct for enum type definition.
+type EnumTypeDef struct {
+	userDefinedType
+
+	Name      *TypeNameDef
+	LabelList []string
+}
+
+// TypeName implements the UserDefinedType interface.
+func (e *EnumTypeDef) TypeName() *TypeNameDef {
+	return e.Name
+}
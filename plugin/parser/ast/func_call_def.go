@@ -0,0 +1,10 @@
+package ast
+
+// FuncCallDef is the struct for function call definition.
+type FuncCallDef struct {
+	expression
+	// Schema is the schema of the function, it's empty if not specified.
+	Schema        string
+	Name          string
+	ParameterList []ExpressionNode
+}
@@ -0,0 +1,11 @@
+package ast
+
+// FunctionDef is the struct for function definition.
+type FunctionDef struct {
+	node
+
+	// Schema is a PostgreSQL specific field.
+	Schema        string
+	Name          string
+	ParameterList []*FunctionParameterDef
+}
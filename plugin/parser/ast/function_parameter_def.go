@@ -0,0 +1,26 @@
+package ast
+
+// FunctionParameterMode is the mode for function parameter.
+type FunctionParameterMode int
+
+const (
+	// FunctionParameterModeIn is the IN parameter, which is the default mode.
+	FunctionParameterModeIn FunctionParameterMode = iota
+	// FunctionParameterModeOut is the OUT parameter.
+	FunctionParameterModeOut
+	// FunctionParameterModeInOut is the INOUT parameter.
+	FunctionParameterModeInOut
+	// FunctionParameterModeVariadic is the VARIADIC parameter.
+	FunctionParameterModeVariadic
+	// FunctionParameterModeTable is the column of the RETURNS TABLE clause.
+	FunctionParameterModeTable
+)
+
+// FunctionParameterDef is the struct for function parameter definition.
+type FunctionParameterDef struct {
+	node
+
+	Name string
+	Type DataType
+	Mode FunctionParameterMode
+}
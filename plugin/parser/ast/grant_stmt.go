@@ -0,0 +1,13 @@
+package ast
+
+// GrantStmt is the struct for grant statement.
+// It's not a DDL, so that the privileges can be changed along with the data changes.
+type GrantStmt struct {
+	node
+
+	PrivilegeList []*PrivilegeDef
+	Target        *PrivilegeTargetDef
+	// GranteeList is the role names, and the special roles PUBLIC, CURRENT_USER and SESSION_USER are in upper case.
+	GranteeList     []string
+	WithGrantOption bool
+}
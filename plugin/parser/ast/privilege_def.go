@@ -0,0 +1,50 @@
+package ast
+
+// PrivilegeObjectType is the type of the objects in the GRANT and REVOKE statements.
+type PrivilegeObjectType int
+
+const (
+	// PrivilegeObjectTypeUnknown is the type for the unsupported objects.
+	PrivilegeObjectTypeUnknown PrivilegeObjectType = iota
+	// PrivilegeObjectTypeTable is the type for tables.
+	PrivilegeObjectTypeTable
+	// PrivilegeObjectTypeSequence is the type for sequences.
+	PrivilegeObjectTypeSequence
+	// PrivilegeObjectTypeDatabase is the type for databases.
+	PrivilegeObjectTypeDatabase
+	// PrivilegeObjectTypeSchema is the type for schemas.
+	PrivilegeObjectTypeSchema
+	// PrivilegeObjectTypeFunction is the type for functions.
+	PrivilegeObjectTypeFunction
+	// PrivilegeObjectTypeProcedure is the type for procedures.
+	PrivilegeObjectTypeProcedure
+	// PrivilegeObjectTypeType is the type for user defined types.
+	PrivilegeObjectTypeType
+)
+
+// PrivilegeDef is the struct for the privilege in the GRANT and REVOKE statements.
+type PrivilegeDef struct {
+	node
+
+	// Type is the privilege type in upper case, e.g. SELECT, and it's ALL for ALL PRIVILEGES.
+	Type string
+	// ColumnList is the columns of the column privileges.
+	ColumnList []string
+}
+
+// PrivilegeTargetDef is the struct for the objects in the GRANT and REVOKE statements.
+// Only the list for the object type is set.
+type PrivilegeTargetDef struct {
+	node
+
+	Type PrivilegeObjectType
+	// AllInSchema is true for the ON ALL TABLES IN SCHEMA clause, and the schemas are in the NameList.
+	AllInSchema bool
+
+	TableList    []*TableDef
+	SequenceList []*SequenceNameDef
+	FunctionList []*FunctionDef
+	TypeList     []*TypeNameDef
+	// NameList is the names of the databases and the schemas.
+	NameList []string
+}
@@ -0,0 +1,15 @@
+package ast
+
+// RevokeStmt is the struct for revoke statement.
+// It's not a DDL, so that the privileges can be changed along with the data changes.
+type RevokeStmt struct {
+	node
+
+	// GrantOptionFor is true for the REVOKE GRANT OPTION FOR statement, which only revokes the grant option.
+	GrantOptionFor bool
+	PrivilegeList  []*PrivilegeDef
+	Target         *PrivilegeTargetDef
+	// GranteeList is the role names, and the special roles PUBLIC, CURRENT_USER and SESSION_USER are in upper case.
+	GranteeList []string
+	Cascade     bool
+}
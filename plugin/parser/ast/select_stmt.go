@@ -32,8 +32,9 @@ type SelectStmt struct {
 	RQuery *SelectStmt
 
 	// SELECT fields
-	FieldList   []ExpressionNode
-	WhereClause ExpressionNode
+	FieldList     []ExpressionNode
+	WhereClause   ExpressionNode
+	OrderByClause []*ByItemDef
 
 	// TODO(rebelice): support all expression and remove them.
 	// We define them because we cannot convert all expression now.
@@ -0,0 +1,23 @@
+package ast
+
+// SequenceDef is the struct for sequence definition.
+// The nil pointer fields mean that the option is not specified.
+type SequenceDef struct {
+	node
+
+	SequenceName     *SequenceNameDef
+	SequenceDataType DataType
+	IncrementBy      *int64
+	// NoMinValue is true for the NO MINVALUE option.
+	NoMinValue bool
+	MinValue   *int64
+	// NoMaxValue is true for the NO MAXVALUE option.
+	NoMaxValue bool
+	MaxValue   *int64
+	StartWith  *int64
+	Cache      *int64
+	Cycle      bool
+	// OwnedByNone is true for the OWNED BY NONE option.
+	OwnedByNone bool
+	OwnedBy     *ColumnNameDef
+}
@@ -0,0 +1,10 @@
+package ast
+
+// SequenceNameDef is the struct for sequence name.
+type SequenceNameDef struct {
+	node
+
+	// Schema is a PostgreSQL specific field.
+	Schema string
+	Name   string
+}
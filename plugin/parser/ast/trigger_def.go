@@ -0,0 +1,46 @@
+package ast
+
+// TriggerTiming is the time when the trigger fires.
+type TriggerTiming int
+
+const (
+	// TriggerTimingAfter is the AFTER trigger, which is the default timing.
+	TriggerTimingAfter TriggerTiming = iota
+	// TriggerTimingBefore is the BEFORE trigger.
+	TriggerTimingBefore
+	// TriggerTimingInsteadOf is the INSTEAD OF trigger.
+	TriggerTimingInsteadOf
+)
+
+// TriggerEvent is the event that fires the trigger.
+type TriggerEvent int
+
+const (
+	// TriggerEventInsert is the INSERT event.
+	TriggerEventInsert TriggerEvent = iota
+	// TriggerEventUpdate is the UPDATE event.
+	TriggerEventUpdate
+	// TriggerEventDelete is the DELETE event.
+	TriggerEventDelete
+	// TriggerEventTruncate is the TRUNCATE event.
+	TriggerEventTruncate
+)
+
+// TriggerDef is the struct for trigger definition.
+type TriggerDef struct {
+	node
+
+	Name  string
+	Table *TableDef
+	// Function is the function executed by the trigger, and ArgumentList is the arguments passed to the function.
+	Function     *FunctionDef
+	ArgumentList []string
+	Timing       TriggerTiming
+	EventList    []TriggerEvent
+	// UpdateColumnList is the columns of the UPDATE OF event.
+	UpdateColumnList []string
+	// ForEachRow is true for the row-level triggers.
+	ForEachRow bool
+	// When is the condition of the WHEN clause.
+	When string
+}
@@ -0,0 +1,14 @@
+package ast
+
+// TruncateStmt is the struct for truncate statement.
+type TruncateStmt struct {
+	ddl
+
+	TableList []*TableDef
+	// OnlyList is parallel to TableList, and OnlyList[i] is true if TableList[i] is specified with ONLY,
+	// i.e., the descendant tables are not truncated.
+	OnlyList []bool
+	// RestartIdentity is true for the RESTART IDENTITY option.
+	RestartIdentity bool
+	Cascade         bool
+}
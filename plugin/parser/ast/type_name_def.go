@@ -0,0 +1,10 @@
+package ast
+
+// TypeNameDef is the struct for user defined type name.
+type TypeNameDef struct {
+	node
+
+	// Schema is a PostgreSQL specific field.
+	Schema string
+	Name   string
+}
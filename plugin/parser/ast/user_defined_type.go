@@ -0,0 +1,15 @@
+package ast
+
+// UserDefinedType is the interface for user defined type.
+type UserDefinedType interface {
+	Node
+
+	TypeName() *TypeNameDef
+	userDefinedTypeInterface()
+}
+
+type userDefinedType struct {
+	node
+}
+
+func (*userDefinedType) userDefinedTypeInterface() {}
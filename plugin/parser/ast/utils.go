@@ -33,6 +33,10 @@ func Walk(v Visitor, node Node) {
 		for _, cmd := range n.AlterItemList {
 			Walk(v, cmd)
 		}
+	case *ByItemDef:
+		if n.Expression != nil {
+			Walk(v, n.Expression)
+		}
 	case *ChangeColumnStmt:
 		if n.Table != nil {
 			Walk(v, n.Table)
@@ -51,6 +55,12 @@ func Walk(v Visitor, node Node) {
 		if n.Table != nil {
 			Walk(v, n.Table)
 		}
+	case *CommentStmt:
+		if n.Object != nil {
+			Walk(v, n.Object)
+		}
+	case *CommitStmt:
+		// No members to walk through.
 	case *ConstraintDef:
 		if n.Foreign != nil {
 			Walk(v, n.Foreign)
@@ -63,6 +73,17 @@ func Walk(v Visitor, node Node) {
 		if n.Index != nil {
 			Walk(v, n.Index)
 		}
+	case *CreateExtensionStmt:
+		// No members to walk through.
+	case *CreateFunctionStmt:
+		if n.Function != nil {
+			Walk(v, n.Function)
+		}
+		if n.ReturnType != nil {
+			Walk(v, n.ReturnType)
+		}
+	case *CreateSequenceStmt:
+		Walk(v, &n.SequenceDef)
 	case *CreateTableStmt:
 		if n.Name != nil {
 			Walk(v, n.Name)
@@ -73,6 +94,18 @@ func Walk(v Visitor, node Node) {
 		for _, cons := range n.ConstraintList {
 			Walk(v, cons)
 		}
+	case *CreateTriggerStmt:
+		if n.Trigger != nil {
+			Walk(v, n.Trigger)
+		}
+	case *CreateTypeStmt:
+		if n.Type != nil {
+			Walk(v, n.Type)
+		}
+	case *CreateViewStmt:
+		if n.Name != nil {
+			Walk(v, n.Name)
+		}
 	case *DeleteStmt:
 		if n.Table != nil {
 			Walk(v, n.Table)
@@ -109,6 +142,10 @@ func Walk(v Visitor, node Node) {
 		for _, tableDef := range n.TableList {
 			Walk(v, tableDef)
 		}
+	case *EnumTypeDef:
+		if n.Name != nil {
+			Walk(v, n.Name)
+		}
 	case *ExplainStmt:
 		if n.Statement != nil {
 			Walk(v, n.Statement)
@@ -117,6 +154,18 @@ func Walk(v Visitor, node Node) {
 		if n.Table != nil {
 			Walk(v, n.Table)
 		}
+	case *FuncCallDef:
+		for _, parameter := range n.ParameterList {
+			Walk(v, parameter)
+		}
+	case *FunctionDef:
+		for _, parameter := range n.ParameterList {
+			Walk(v, parameter)
+		}
+	case *FunctionParameterDef:
+		if n.Type != nil {
+			Walk(v, n.Type)
+		}
 	case *IndexDef:
 		if n.Table != nil {
 			Walk(v, n.Table)
@@ -169,6 +218,9 @@ func Walk(v Visitor, node Node) {
 		if n.WhereClause != nil {
 			Walk(v, n.WhereClause)
 		}
+		for _, item := range n.OrderByClause {
+			Walk(v, item)
+		}
 
 		for _, like := range n.PatternLikeList {
 			Walk(v, like)
@@ -176,6 +228,18 @@ func Walk(v Visitor, node Node) {
 		for _, subquery := range n.SubqueryList {
 			Walk(v, subquery)
 		}
+	case *SequenceDef:
+		if n.SequenceName != nil {
+			Walk(v, n.SequenceName)
+		}
+		if n.SequenceDataType != nil {
+			Walk(v, n.SequenceDataType)
+		}
+		if n.OwnedBy != nil {
+			Walk(v, n.OwnedBy)
+		}
+	case *SequenceNameDef:
+		// No members to walk through.
 	case *SetNotNullStmt:
 		if n.Table != nil {
 			Walk(v, n.Table)
@@ -192,6 +256,15 @@ func Walk(v Visitor, node Node) {
 		}
 	case *TableDef:
 		// No members to walk through.
+	case *TriggerDef:
+		if n.Table != nil {
+			Walk(v, n.Table)
+		}
+		if n.Function != nil {
+			Walk(v, n.Function)
+		}
+	case *TypeNameDef:
+		// No members to walk through.
 	case *UnconvertedExpressionDef:
 		// No members to walk through.
 	case *UpdateStmt:
@@ -208,5 +281,7 @@ func Walk(v Visitor, node Node) {
 		for _, subquery := range n.SubqueryList {
 			Walk(v, subquery)
 		}
+	case *VariableSetStmt:
+		// No members to walk through.
 	}
 }
@@ -0,0 +1,12 @@
+package ast
+
+// VariableSetStmt is the struct for the statement setting a run-time parameter, e.g., SET search_path TO s1, public.
+type VariableSetStmt struct {
+	node
+
+	Name string
+	// ValueList is empty for SET ... TO DEFAULT and RESET.
+	ValueList []string
+	// Local is true for SET LOCAL.
+	Local bool
+}
@@ -0,0 +1,277 @@
+// Package clickhouse provides the ClickHouse differ plugin.
+package clickhouse
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/bytebase/bytebase/plugin/parser"
+	"github.com/bytebase/bytebase/plugin/parser/differ"
+)
+
+var (
+	_ differ.SchemaDiffer = (*SchemaDiffer)(nil)
+
+	// defaultSettingMap is the MergeTree settings shown by ClickHouse even if they are not specified.
+	defaultSettingMap = map[string]string{
+		"index_granularity": "8192",
+	}
+)
+
+func init() {
+	differ.Register(parser.ClickHouse, &SchemaDiffer{})
+}
+
+// SchemaDiffer it the differ for ClickHouse dialect.
+type SchemaDiffer struct {
+}
+
+// SchemaDiff returns the schema diff.
+// It only supports the CREATE TABLE statements, and the other statements are ignored.
+func (*SchemaDiffer) SchemaDiff(oldStmt, newStmt string) (string, error) {
+	oldTableList, err := parseTableList(oldStmt)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse old statement %q", oldStmt)
+	}
+	newTableList, err := parseTableList(newStmt)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse new statement %q", newStmt)
+	}
+
+	oldTableMap := make(map[string]*tableDef)
+	for _, table := range oldTableList {
+		oldTableMap[table.name] = table
+	}
+
+	var buf bytes.Buffer
+	for _, newTable := range newTableList {
+		oldTable, ok := oldTableMap[newTable.name]
+		if !ok {
+			writeStatement(&buf, strings.TrimSpace(newTable.text))
+			continue
+		}
+		statementList, err := diffTable(oldTable, newTable)
+		if err != nil {
+			return "", err
+		}
+		for _, statement := range statementList {
+			writeStatement(&buf, statement)
+		}
+	}
+	return buf.String(), nil
+}
+
+func parseTableList(statement string) ([]*tableDef, error) {
+	singleSQLList, err := parser.SplitMultiSQL(parser.ClickHouse, statement)
+	if err != nil {
+		return nil, err
+	}
+	var tableList []*tableDef
+	for _, singleSQL := range singleSQLList {
+		table, err := parseCreateTable(singleSQL.Text)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse statement %q", singleSQL.Text)
+		}
+		if table != nil {
+			tableList = append(tableList, table)
+		}
+	}
+	return tableList, nil
+}
+
+// diffTable returns the ALTER TABLE statements to migrate the old table to the new one.
+func diffTable(oldTable, newTable *tableDef) ([]string, error) {
+	tableName := quoteIdentifier(newTable.name)
+	if err := checkImmutableClause(newTable.name, "ENGINE", canonicalizeEngine(oldTable.engine), canonicalizeEngine(newTable.engine)); err != nil {
+		return nil, err
+	}
+	if err := checkImmutableClause(newTable.name, "PARTITION BY", canonicalize(oldTable.partitionBy), canonicalize(newTable.partitionBy)); err != nil {
+		return nil, err
+	}
+	if err := checkImmutableClause(newTable.name, "SAMPLE BY", canonicalize(oldTable.sampleBy), canonicalize(newTable.sampleBy)); err != nil {
+		return nil, err
+	}
+	// The primary key is the same as the sorting key by default.
+	if len(newTable.primaryKey) > 0 {
+		oldPrimaryKey := oldTable.primaryKey
+		if len(oldPrimaryKey) == 0 {
+			oldPrimaryKey = oldTable.orderBy
+		}
+		if err := checkImmutableClause(newTable.name, "PRIMARY KEY", canonicalize(oldPrimaryKey), canonicalize(newTable.primaryKey)); err != nil {
+			return nil, err
+		}
+	}
+
+	// The column, index, constraint and projection changes and the sorting key change go to one ALTER TABLE statement,
+	// because ClickHouse requires adding the new columns in the same statement with MODIFY ORDER BY.
+	var commandList []string
+	commandList = append(commandList, diffColumnList(oldTable.columnList, newTable.columnList)...)
+	commandList = append(commandList, diffElementList("INDEX", oldTable.indexList, newTable.indexList)...)
+	commandList = append(commandList, diffElementList("CONSTRAINT", oldTable.constraintList, newTable.constraintList)...)
+	commandList = append(commandList, diffElementList("PROJECTION", oldTable.projectionList, newTable.projectionList)...)
+	if canonicalize(oldTable.orderBy) != canonicalize(newTable.orderBy) {
+		if len(newTable.orderBy) == 0 {
+			return nil, errors.Errorf("cannot remove the ORDER BY of table %q", newTable.name)
+		}
+		commandList = append(commandList, fmt.Sprintf("MODIFY ORDER BY %s", formatTokenList(newTable.orderBy)))
+	}
+
+	var statementList []string
+	if len(commandList) > 0 {
+		statementList = append(statementList, fmt.Sprintf("ALTER TABLE %s %s", tableName, strings.Join(commandList, ", ")))
+	}
+	if canonicalize(oldTable.ttl) != canonicalize(newTable.ttl) {
+		if len(newTable.ttl) == 0 {
+			statementList = append(statementList, fmt.Sprintf("ALTER TABLE %s REMOVE TTL", tableName))
+		} else {
+			statementList = append(statementList, fmt.Sprintf("ALTER TABLE %s MODIFY TTL %s", tableName, formatTokenList(newTable.ttl)))
+		}
+	}
+	statementList = append(statementList, diffSettingList(tableName, oldTable.settingList, newTable.settingList)...)
+	oldComment, newComment := "", ""
+	if oldTable.comment != nil {
+		oldComment = *oldTable.comment
+	}
+	if newTable.comment != nil {
+		newComment = *newTable.comment
+	}
+	if oldComment != newComment {
+		statementList = append(statementList, fmt.Sprintf("ALTER TABLE %s MODIFY COMMENT %s", tableName, quoteString(newComment)))
+	}
+	return statementList, nil
+}
+
+// checkImmutableClause returns an error if the clause which cannot be altered is changed.
+func checkImmutableClause(tableName string, clause string, oldValue string, newValue string) error {
+	if oldValue != newValue {
+		return errors.Errorf("cannot change the %s of table %q from %q to %q, please recreate the table", clause, tableName, oldValue, newValue)
+	}
+	return nil
+}
+
+func diffColumnList(oldColumnList, newColumnList []*elementDef) []string {
+	oldColumnMap := make(map[string]*elementDef)
+	for _, column := range oldColumnList {
+		oldColumnMap[column.name] = column
+	}
+	newColumnMap := make(map[string]*elementDef)
+	for _, column := range newColumnList {
+		newColumnMap[column.name] = column
+	}
+
+	var commandList []string
+	for i, newColumn := range newColumnList {
+		oldColumn, ok := oldColumnMap[newColumn.name]
+		if !ok {
+			position := "FIRST"
+			if i > 0 {
+				position = fmt.Sprintf("AFTER %s", quoteIdentifier(newColumnList[i-1].name))
+			}
+			commandList = append(commandList, fmt.Sprintf("ADD COLUMN %s %s %s", quoteIdentifier(newColumn.name), formatTokenList(newColumn.definition), position))
+			continue
+		}
+		if canonicalizeColumn(oldColumn.definition) != canonicalizeColumn(newColumn.definition) {
+			commandList = append(commandList, fmt.Sprintf("MODIFY COLUMN %s %s", quoteIdentifier(newColumn.name), formatTokenList(newColumn.definition)))
+		}
+	}
+	for _, oldColumn := range oldColumnList {
+		if _, ok := newColumnMap[oldColumn.name]; !ok {
+			commandList = append(commandList, fmt.Sprintf("DROP COLUMN %s", quoteIdentifier(oldColumn.name)))
+		}
+	}
+	return commandList
+}
+
+// diffElementList diffs the indexes, constraints or projections, the changed ones are dropped and recreated.
+func diffElementList(kind string, oldElementList, newElementList []*elementDef) []string {
+	oldElementMap := make(map[string]*elementDef)
+	for _, element := range oldElementList {
+		oldElementMap[element.name] = element
+	}
+	newElementMap := make(map[string]*elementDef)
+	for _, element := range newElementList {
+		newElementMap[element.name] = element
+	}
+
+	var commandList []string
+	for _, oldElement := range oldElementList {
+		newElement, ok := newElementMap[oldElement.name]
+		if !ok || canonicalize(oldElement.definition) != canonicalize(newElement.definition) {
+			commandList = append(commandList, fmt.Sprintf("DROP %s %s", kind, quoteIdentifier(oldElement.name)))
+		}
+	}
+	for _, newElement := range newElementList {
+		oldElement, ok := oldElementMap[newElement.name]
+		if !ok || canonicalize(oldElement.definition) != canonicalize(newElement.definition) {
+			commandList = append(commandList, fmt.Sprintf("ADD %s %s %s", kind, quoteIdentifier(newElement.name), formatTokenList(newElement.definition)))
+		}
+	}
+	return commandList
+}
+
+func diffSettingList(tableName string, oldSettingList, newSettingList []*settingDef) []string {
+	oldSettingMap := make(map[string]string)
+	for _, setting := range oldSettingList {
+		oldSettingMap[setting.name] = canonicalize(setting.value)
+	}
+	newSettingMap := make(map[string]string)
+	for _, setting := range newSettingList {
+		newSettingMap[setting.name] = canonicalize(setting.value)
+	}
+
+	var modifyList []string
+	for _, setting := range newSettingList {
+		if oldValue, ok := oldSettingMap[setting.name]; !ok || oldValue != newSettingMap[setting.name] {
+			if !ok && defaultSettingMap[setting.name] == newSettingMap[setting.name] {
+				continue
+			}
+			modifyList = append(modifyList, fmt.Sprintf("%s = %s", setting.name, formatTokenList(setting.value)))
+		}
+	}
+	var resetList []string
+	for _, setting := range oldSettingList {
+		if _, ok := newSettingMap[setting.name]; ok {
+			continue
+		}
+		if defaultSettingMap[setting.name] == oldSettingMap[setting.name] {
+			continue
+		}
+		resetList = append(resetList, setting.name)
+	}
+
+	var statementList []string
+	if len(modifyList) > 0 {
+		statementList = append(statementList, fmt.Sprintf("ALTER TABLE %s MODIFY SETTING %s", tableName, strings.Join(modifyList, ", ")))
+	}
+	if len(resetList) > 0 {
+		statementList = append(statementList, fmt.Sprintf("ALTER TABLE %s RESET SETTING %s", tableName, strings.Join(resetList, ", ")))
+	}
+	return statementList
+}
+
+// canonicalizeEngine returns the canonical engine, ClickHouse shows "MergeTree()" as "MergeTree".
+func canonicalizeEngine(engine []token) string {
+	if len(engine) == 3 && engine[1].text == "(" && engine[2].text == ")" {
+		engine = engine[:1]
+	}
+	return canonicalize(engine)
+}
+
+func writeStatement(buf *bytes.Buffer, statement string) {
+	_, _ = buf.WriteString(statement)
+	if !strings.HasSuffix(statement, ";") {
+		_, _ = buf.WriteString(";")
+	}
+	_, _ = buf.WriteString("\n")
+}
+
+func quoteIdentifier(s string) string {
+	return fmt.Sprintf("`%s`", strings.ReplaceAll(s, "`", "\\`"))
+}
+
+func quoteString(s string) string {
+	return fmt.Sprintf("'%s'", strings.ReplaceAll(strings.ReplaceAll(s, `\`, `\\`), "'", `\'`))
+}
@@ -0,0 +1,116 @@
+package clickhouse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaDiff(t *testing.T) {
+	tests := []struct {
+		name      string
+		oldSchema string
+		newSchema string
+		want      string
+		errPart   string
+	}{
+		{
+			name:      "createTable",
+			oldSchema: ``,
+			newSchema: `CREATE TABLE events (id UInt64, name String) ENGINE = MergeTree() ORDER BY id;`,
+			want:      "CREATE TABLE events (id UInt64, name String) ENGINE = MergeTree() ORDER BY id;\n",
+		},
+		{
+			name: "unchangedTable",
+			oldSchema: "--\n-- Table structure for `events`\n--\n" +
+				"CREATE TABLE events (`id` UInt64, `name` String DEFAULT 'a', `price` Decimal(10, 2), `created` DateTime CODEC(Delta, ZSTD(1))) " +
+				"ENGINE = MergeTree PARTITION BY toYYYYMM(created) ORDER BY (id, created) TTL created + toIntervalDay(30) SETTINGS index_granularity = 8192 COMMENT 'events';\n",
+			newSchema: `CREATE TABLE IF NOT EXISTS events (
+	id UInt64,
+	name String default 'a',
+	price Decimal(10,2),
+	created DateTime CODEC(Delta, ZSTD(1))
+)
+ENGINE = MergeTree()
+PARTITION BY toYYYYMM(created)
+ORDER BY (id, created)
+TTL created + INTERVAL 30 DAY
+COMMENT 'events';`,
+			want: "",
+		},
+		{
+			name:      "columnAlias",
+			oldSchema: "CREATE TABLE t (`a` Int32, `b` String) ENGINE = MergeTree ORDER BY a SETTINGS index_granularity = 8192;",
+			newSchema: "CREATE TABLE t (a INT, b VARCHAR(255)) ENGINE = MergeTree ORDER BY a;",
+			want:      "",
+		},
+		{
+			name:      "addModifyDropColumn",
+			oldSchema: "CREATE TABLE t (`a` UInt64, `b` String, `c` String) ENGINE = MergeTree ORDER BY a SETTINGS index_granularity = 8192;",
+			newSchema: "CREATE TABLE t (x UInt8, a UInt64, b LowCardinality(String), d Nullable(String) COMMENT 'd') ENGINE = MergeTree ORDER BY a;",
+			want:      "ALTER TABLE `t` ADD COLUMN `x` UInt8 FIRST, MODIFY COLUMN `b` LowCardinality(String), ADD COLUMN `d` Nullable(String) COMMENT 'd' AFTER `b`, DROP COLUMN `c`;\n",
+		},
+		{
+			name:      "modifyOrderByWithNewColumn",
+			oldSchema: "CREATE TABLE t (`a` UInt64) ENGINE = MergeTree ORDER BY a SETTINGS index_granularity = 8192;",
+			newSchema: "CREATE TABLE t (a UInt64, b UInt64) ENGINE = MergeTree ORDER BY (a, b);",
+			want:      "ALTER TABLE `t` ADD COLUMN `b` UInt64 AFTER `a`, MODIFY ORDER BY (a, b);\n",
+		},
+		{
+			name:      "changeIndexAndProjection",
+			oldSchema: "CREATE TABLE t (`a` UInt64, `b` String, INDEX idx_b b TYPE bloom_filter GRANULARITY 1, INDEX idx_old b TYPE set(100) GRANULARITY 2) ENGINE = MergeTree ORDER BY a SETTINGS index_granularity = 8192;",
+			newSchema: "CREATE TABLE t (a UInt64, b String, INDEX idx_b b TYPE bloom_filter GRANULARITY 4, PROJECTION p (SELECT b ORDER BY b)) ENGINE = MergeTree ORDER BY a;",
+			want:      "ALTER TABLE `t` DROP INDEX `idx_b`, DROP INDEX `idx_old`, ADD INDEX `idx_b` b TYPE bloom_filter GRANULARITY 4, ADD PROJECTION `p` (SELECT b ORDER BY b);\n",
+		},
+		{
+			name:      "modifyTTL",
+			oldSchema: "CREATE TABLE t (`a` UInt64, `d` DateTime) ENGINE = MergeTree ORDER BY a TTL d + toIntervalDay(1) SETTINGS index_granularity = 8192;",
+			newSchema: "CREATE TABLE t (a UInt64, d DateTime) ENGINE = MergeTree ORDER BY a TTL d + INTERVAL 1 MONTH DELETE;",
+			want:      "ALTER TABLE `t` MODIFY TTL d + INTERVAL 1 MONTH DELETE;\n",
+		},
+		{
+			name:      "removeTTL",
+			oldSchema: "CREATE TABLE t (`a` UInt64, `d` DateTime) ENGINE = MergeTree ORDER BY a TTL d + toIntervalDay(1) SETTINGS index_granularity = 8192;",
+			newSchema: "CREATE TABLE t (a UInt64, d DateTime) ENGINE = MergeTree ORDER BY a;",
+			want:      "ALTER TABLE `t` REMOVE TTL;\n",
+		},
+		{
+			name:      "settingAndComment",
+			oldSchema: "CREATE TABLE t (`a` UInt64) ENGINE = MergeTree ORDER BY a SETTINGS index_granularity = 8192, ttl_only_drop_parts = 1 COMMENT 'old';",
+			newSchema: "CREATE TABLE t (a UInt64) ENGINE = MergeTree ORDER BY a SETTINGS merge_with_ttl_timeout = 3600;",
+			want: "ALTER TABLE `t` MODIFY SETTING merge_with_ttl_timeout = 3600;\n" +
+				"ALTER TABLE `t` RESET SETTING ttl_only_drop_parts;\n" +
+				"ALTER TABLE `t` MODIFY COMMENT '';\n",
+		},
+		{
+			name:      "changeEngine",
+			oldSchema: "CREATE TABLE t (`a` UInt64) ENGINE = MergeTree ORDER BY a SETTINGS index_granularity = 8192;",
+			newSchema: "CREATE TABLE t (a UInt64) ENGINE = ReplacingMergeTree ORDER BY a;",
+			errPart:   `cannot change the ENGINE of table "t" from "MergeTree" to "ReplacingMergeTree"`,
+		},
+		{
+			name:      "changePartitionBy",
+			oldSchema: "CREATE TABLE t (`a` UInt64, `d` Date) ENGINE = MergeTree PARTITION BY toYYYYMM(d) ORDER BY a SETTINGS index_granularity = 8192;",
+			newSchema: "CREATE TABLE t (a UInt64, d Date) ENGINE = MergeTree PARTITION BY d ORDER BY a;",
+			errPart:   `cannot change the PARTITION BY of table "t"`,
+		},
+		{
+			name:      "ignoreOtherStatement",
+			oldSchema: "CREATE VIEW v (`a` UInt64) AS SELECT a FROM t;",
+			newSchema: "CREATE VIEW v AS SELECT a, b FROM t;\nCREATE DATABASE db;",
+			want:      "",
+		},
+	}
+
+	clickhouseDiffer := &SchemaDiffer{}
+	for _, test := range tests {
+		diff, err := clickhouseDiffer.SchemaDiff(test.oldSchema, test.newSchema)
+		if test.errPart == "" {
+			require.NoError(t, err, test.name)
+		} else {
+			require.Error(t, err, test.name)
+			require.Contains(t, err.Error(), test.errPart, test.name)
+		}
+		require.Equal(t, test.want, diff, test.name)
+	}
+}
@@ -0,0 +1,526 @@
+package clickhouse
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+type tokenType int
+
+const (
+	tokenIdentifier tokenType = iota
+	tokenQuotedIdentifier
+	tokenString
+	tokenNumber
+	tokenSymbol
+)
+
+// token is the lexical token of ClickHouse DDL.
+type token struct {
+	tp tokenType
+	// text is the raw text of the token.
+	text string
+	// value is the unquoted value for the quoted identifiers and strings, otherwise it's the same as text.
+	value string
+}
+
+// isKeyword returns true if the token is the bare identifier equal to the keyword case-insensitively.
+func (t token) isKeyword(keyword string) bool {
+	return t.tp == tokenIdentifier && strings.EqualFold(t.text, keyword)
+}
+
+var (
+	bareIdentifierReg = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+	// canonicalKeywordSet is the keywords which are case-insensitive in the expressions.
+	canonicalKeywordSet = map[string]bool{
+		"ALIAS": true, "AND": true, "AS": true, "ASC": true, "BETWEEN": true, "BY": true, "CASE": true,
+		"CODEC": true, "COMMENT": true, "DEFAULT": true, "DELETE": true, "DESC": true, "DISK": true,
+		"ELSE": true, "END": true, "EPHEMERAL": true, "GRANULARITY": true, "GROUP": true, "IN": true,
+		"IS": true, "LIKE": true, "MATERIALIZED": true, "NOT": true, "NULL": true, "OR": true,
+		"RECOMPRESS": true, "SET": true, "THEN": true, "TO": true, "TTL": true, "TYPE": true,
+		"VOLUME": true, "WHEN": true, "WHERE": true,
+	}
+
+	// intervalUnitSet is the units of INTERVAL, ClickHouse shows "INTERVAL 1 DAY" as "toIntervalDay(1)".
+	intervalUnitSet = map[string]string{
+		"SECOND": "Second", "MINUTE": "Minute", "HOUR": "Hour", "DAY": "Day",
+		"WEEK": "Week", "MONTH": "Month", "QUARTER": "Quarter", "YEAR": "Year",
+	}
+
+	// dataTypeAliasMap is the map from the case-insensitive data type aliases to the ClickHouse data types.
+	// https://clickhouse.com/docs/en/sql-reference/data-types/
+	dataTypeAliasMap = map[string]string{
+		"TINYINT":  "Int8",
+		"SMALLINT": "Int16",
+		"INT":      "Int32",
+		"INTEGER":  "Int32",
+		"BIGINT":   "Int64",
+		"FLOAT":    "Float32",
+		"DOUBLE":   "Float64",
+		"TEXT":     "String",
+		"VARCHAR":  "String",
+		"CHAR":     "String",
+		"BLOB":     "String",
+		"BOOLEAN":  "Bool",
+		"BOOL":     "Bool",
+	}
+)
+
+// tableDef is the definition of ClickHouse table.
+type tableDef struct {
+	name string
+	// text is the original CREATE TABLE statement.
+	text           string
+	columnList     []*elementDef
+	indexList      []*elementDef
+	constraintList []*elementDef
+	projectionList []*elementDef
+	engine         []token
+	orderBy        []token
+	partitionBy    []token
+	primaryKey     []token
+	sampleBy       []token
+	ttl            []token
+	settingList    []*settingDef
+	comment        *string
+}
+
+// elementDef is the named element in the CREATE TABLE, such as the column, index, constraint and projection.
+type elementDef struct {
+	name string
+	// definition is the element definition without name.
+	definition []token
+}
+
+// settingDef is the table setting.
+type settingDef struct {
+	name  string
+	value []token
+}
+
+// tokenize splits the statement into tokens, the comments are skipped.
+func tokenize(statement string) ([]token, error) {
+	var tokenList []token
+	runeList := []rune(statement)
+	for i := 0; i < len(runeList); {
+		c := runeList[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '-' && i+1 < len(runeList) && runeList[i+1] == '-', c == '#':
+			for i < len(runeList) && runeList[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(runeList) && runeList[i+1] == '*':
+			j := i + 2
+			for j+1 < len(runeList) && !(runeList[j] == '*' && runeList[j+1] == '/') {
+				j++
+			}
+			if j+1 >= len(runeList) {
+				return nil, errors.Errorf("invalid comment: not found */")
+			}
+			i = j + 2
+		case c == '\'' || c == '"' || c == '`':
+			var value strings.Builder
+			j := i + 1
+			for ; j < len(runeList); j++ {
+				if runeList[j] == '\\' && j+1 < len(runeList) {
+					j++
+					_, _ = value.WriteRune(runeList[j])
+					continue
+				}
+				if runeList[j] == c {
+					// The doubled quote is the escaped quote.
+					if j+1 < len(runeList) && runeList[j+1] == c {
+						_, _ = value.WriteRune(c)
+						j++
+						continue
+					}
+					break
+				}
+				_, _ = value.WriteRune(runeList[j])
+			}
+			if j >= len(runeList) {
+				return nil, errors.Errorf("invalid string: not found delimiter %c", c)
+			}
+			tp := tokenQuotedIdentifier
+			if c == '\'' {
+				tp = tokenString
+			}
+			tokenList = append(tokenList, token{tp: tp, text: string(runeList[i : j+1]), value: value.String()})
+			i = j + 1
+		case unicode.IsLetter(c) || c == '_':
+			j := i + 1
+			for j < len(runeList) && (unicode.IsLetter(runeList[j]) || unicode.IsDigit(runeList[j]) || runeList[j] == '_' || runeList[j] == '$') {
+				j++
+			}
+			text := string(runeList[i:j])
+			tokenList = append(tokenList, token{tp: tokenIdentifier, text: text, value: text})
+			i = j
+		case unicode.IsDigit(c):
+			j := i + 1
+			for j < len(runeList) && (unicode.IsLetter(runeList[j]) || unicode.IsDigit(runeList[j]) || runeList[j] == '.' || runeList[j] == '_') {
+				j++
+			}
+			text := string(runeList[i:j])
+			tokenList = append(tokenList, token{tp: tokenNumber, text: text, value: text})
+			i = j
+		default:
+			text := string(c)
+			if i+1 < len(runeList) {
+				switch two := string(runeList[i : i+2]); two {
+				case "!=", "<>", "<=", ">=", "||", "->", "::", "==":
+					text = two
+				}
+			}
+			tokenList = append(tokenList, token{tp: tokenSymbol, text: text, value: text})
+			i += len([]rune(text))
+		}
+	}
+	return tokenList, nil
+}
+
+// parseCreateTable parses the CREATE TABLE statement. It returns nil if the statement is not a CREATE TABLE statement.
+func parseCreateTable(statement string) (*tableDef, error) {
+	tokenList, err := tokenize(statement)
+	if err != nil {
+		return nil, err
+	}
+	p := &tokenParser{tokenList: tokenList}
+
+	// CREATE [OR REPLACE] TABLE [IF NOT EXISTS] [db.]table_name [UUID 'uuid'] [ON CLUSTER cluster] (...)
+	if !p.acceptKeyword("CREATE") {
+		return nil, nil
+	}
+	if p.acceptKeyword("OR") && !p.acceptKeyword("REPLACE") {
+		return nil, errors.Errorf("expect REPLACE after CREATE OR")
+	}
+	if !p.acceptKeyword("TABLE") {
+		return nil, nil
+	}
+	if p.acceptKeyword("IF") {
+		if !p.acceptKeyword("NOT") || !p.acceptKeyword("EXISTS") {
+			return nil, errors.Errorf("expect NOT EXISTS after IF")
+		}
+	}
+	name, ok := p.next()
+	if !ok || (name.tp != tokenIdentifier && name.tp != tokenQuotedIdentifier) {
+		return nil, errors.Errorf("expect table name")
+	}
+	table := &tableDef{name: name.value, text: statement}
+	if p.acceptSymbol(".") {
+		if name, ok = p.next(); !ok {
+			return nil, errors.Errorf("expect table name after database name")
+		}
+		table.name = name.value
+	}
+	if p.acceptKeyword("UUID") {
+		p.skip(1)
+	}
+	if p.acceptKeyword("ON") {
+		if !p.acceptKeyword("CLUSTER") {
+			return nil, errors.Errorf("expect CLUSTER after ON")
+		}
+		p.skip(1)
+	}
+	if !p.acceptSymbol("(") {
+		// CREATE TABLE ... AS ... and CREATE TABLE ... ENGINE = ... AS SELECT are not supported.
+		return nil, errors.Errorf("table %q without column definitions is not supported", table.name)
+	}
+	elementList, err := p.parenthesizedList()
+	if err != nil {
+		return nil, err
+	}
+	for _, element := range elementList {
+		if err := table.addElement(element); err != nil {
+			return nil, err
+		}
+	}
+
+	for !p.eof() {
+		switch {
+		case p.acceptSymbol(";"):
+		case p.acceptKeyword("ENGINE"):
+			p.acceptSymbol("=")
+			table.engine = p.clause()
+		case p.acceptKeywordList("ORDER", "BY"):
+			table.orderBy = p.clause()
+		case p.acceptKeywordList("PARTITION", "BY"):
+			table.partitionBy = p.clause()
+		case p.acceptKeywordList("PRIMARY", "KEY"):
+			table.primaryKey = p.clause()
+		case p.acceptKeywordList("SAMPLE", "BY"):
+			table.sampleBy = p.clause()
+		case p.acceptKeyword("TTL"):
+			table.ttl = p.clause()
+		case p.acceptKeyword("SETTINGS"):
+			for _, setting := range splitByComma(p.clause()) {
+				if len(setting) < 2 || setting[1].text != "=" {
+					return nil, errors.Errorf("invalid setting %q of table %q", formatTokenList(setting), table.name)
+				}
+				table.settingList = append(table.settingList, &settingDef{name: setting[0].value, value: setting[2:]})
+			}
+		case p.acceptKeyword("COMMENT"):
+			comment, ok := p.next()
+			if !ok || comment.tp != tokenString {
+				return nil, errors.Errorf("expect string after COMMENT of table %q", table.name)
+			}
+			table.comment = &comment.value
+		default:
+			t, _ := p.next()
+			return nil, errors.Errorf("unexpected %q in the table %q", t.text, table.name)
+		}
+	}
+	return table, nil
+}
+
+// addElement adds the element in the parentheses of CREATE TABLE.
+func (table *tableDef) addElement(element []token) error {
+	if len(element) == 0 {
+		return errors.Errorf("empty definition in the table %q", table.name)
+	}
+	first := element[0]
+	switch {
+	case first.isKeyword("INDEX") && len(element) > 1:
+		table.indexList = append(table.indexList, &elementDef{name: element[1].value, definition: element[2:]})
+	case first.isKeyword("CONSTRAINT") && len(element) > 1:
+		table.constraintList = append(table.constraintList, &elementDef{name: element[1].value, definition: element[2:]})
+	case first.isKeyword("PROJECTION") && len(element) > 1:
+		table.projectionList = append(table.projectionList, &elementDef{name: element[1].value, definition: element[2:]})
+	case first.isKeyword("PRIMARY") && len(element) > 1 && element[1].isKeyword("KEY"):
+		table.primaryKey = element[2:]
+	default:
+		table.columnList = append(table.columnList, &elementDef{name: first.value, definition: element[1:]})
+	}
+	return nil
+}
+
+// tokenParser is the cursor over the token list.
+type tokenParser struct {
+	tokenList []token
+	cursor    int
+}
+
+func (p *tokenParser) eof() bool {
+	return p.cursor >= len(p.tokenList)
+}
+
+func (p *tokenParser) next() (token, bool) {
+	if p.eof() {
+		return token{}, false
+	}
+	p.cursor++
+	return p.tokenList[p.cursor-1], true
+}
+
+func (p *tokenParser) skip(n int) {
+	p.cursor += n
+}
+
+func (p *tokenParser) acceptKeyword(keyword string) bool {
+	return p.acceptKeywordList(keyword)
+}
+
+// acceptKeywordList consumes the keywords if the following tokens match all of them.
+func (p *tokenParser) acceptKeywordList(keywordList ...string) bool {
+	if p.cursor+len(keywordList) > len(p.tokenList) {
+		return false
+	}
+	for i, keyword := range keywordList {
+		if !p.tokenList[p.cursor+i].isKeyword(keyword) {
+			return false
+		}
+	}
+	p.cursor += len(keywordList)
+	return true
+}
+
+func (p *tokenParser) acceptSymbol(symbol string) bool {
+	if !p.eof() && p.tokenList[p.cursor].tp == tokenSymbol && p.tokenList[p.cursor].text == symbol {
+		p.cursor++
+		return true
+	}
+	return false
+}
+
+// parenthesizedList returns the comma separated token lists until the matching right parenthesis.
+// The left parenthesis should have been consumed.
+func (p *tokenParser) parenthesizedList() ([][]token, error) {
+	depth := 1
+	start := p.cursor
+	for ; !p.eof(); p.cursor++ {
+		switch p.tokenList[p.cursor].text {
+		case "(":
+			if p.tokenList[p.cursor].tp == tokenSymbol {
+				depth++
+			}
+		case ")":
+			if p.tokenList[p.cursor].tp == tokenSymbol {
+				depth--
+			}
+		}
+		if depth == 0 {
+			result := splitByComma(p.tokenList[start:p.cursor])
+			p.cursor++
+			return result, nil
+		}
+	}
+	return nil, errors.Errorf("not found the matching right parenthesis")
+}
+
+// clause returns the tokens until the next table clause at depth 0.
+func (p *tokenParser) clause() []token {
+	depth := 0
+	start := p.cursor
+	for ; !p.eof(); p.cursor++ {
+		t := p.tokenList[p.cursor]
+		if t.tp == tokenSymbol {
+			switch t.text {
+			case "(":
+				depth++
+			case ")":
+				depth--
+			case ";":
+				if depth == 0 {
+					return p.tokenList[start:p.cursor]
+				}
+			}
+		}
+		if depth == 0 && p.cursor > start && p.isClauseStart() {
+			return p.tokenList[start:p.cursor]
+		}
+	}
+	return p.tokenList[start:p.cursor]
+}
+
+func (p *tokenParser) isClauseStart() bool {
+	cursor := p.cursor
+	defer func() {
+		p.cursor = cursor
+	}()
+	return p.acceptKeyword("ENGINE") ||
+		p.acceptKeywordList("ORDER", "BY") ||
+		p.acceptKeywordList("PARTITION", "BY") ||
+		p.acceptKeywordList("PRIMARY", "KEY") ||
+		p.acceptKeywordList("SAMPLE", "BY") ||
+		p.acceptKeyword("TTL") ||
+		p.acceptKeyword("SETTINGS") ||
+		p.acceptKeyword("COMMENT")
+}
+
+// splitByComma splits the token list by the commas at depth 0.
+func splitByComma(tokenList []token) [][]token {
+	var result [][]token
+	depth := 0
+	start := 0
+	for i, t := range tokenList {
+		if t.tp != tokenSymbol {
+			continue
+		}
+		switch t.text {
+		case "(", "[":
+			depth++
+		case ")", "]":
+			depth--
+		case ",":
+			if depth == 0 {
+				result = append(result, tokenList[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if start < len(tokenList) {
+		result = append(result, tokenList[start:])
+	}
+	return result
+}
+
+// formatTokenList formats the tokens to the SQL text.
+func formatTokenList(tokenList []token) string {
+	var buf strings.Builder
+	for i, t := range tokenList {
+		if i > 0 && needSpace(tokenList[i-1], t) {
+			_, _ = buf.WriteString(" ")
+		}
+		_, _ = buf.WriteString(t.text)
+	}
+	return buf.String()
+}
+
+func needSpace(prev, cur token) bool {
+	if prev.tp == tokenSymbol && (prev.text == "(" || prev.text == "[" || prev.text == ".") {
+		return false
+	}
+	if cur.tp == tokenSymbol && (cur.text == ")" || cur.text == "]" || cur.text == "," || cur.text == ".") {
+		return false
+	}
+	// Function call or parameterized type, e.g. "toDate(x)" and "Decimal(10, 2)".
+	if cur.tp == tokenSymbol && (cur.text == "(" || cur.text == "[") && (prev.tp == tokenIdentifier || prev.tp == tokenQuotedIdentifier) && !canonicalKeywordSet[strings.ToUpper(prev.text)] {
+		return false
+	}
+	return true
+}
+
+// canonicalize returns the canonical text of the tokens, which is used to compare the definitions
+// from the ClickHouse dump and the ones written by users.
+func canonicalize(tokenList []token) string {
+	var result []token
+	for i := 0; i < len(tokenList); i++ {
+		t := tokenList[i]
+		switch t.tp {
+		case tokenQuotedIdentifier:
+			if bareIdentifierReg.MatchString(t.value) && !canonicalKeywordSet[strings.ToUpper(t.value)] {
+				t = token{tp: tokenIdentifier, text: t.value, value: t.value}
+			} else {
+				t.text = fmt.Sprintf("`%s`", strings.ReplaceAll(t.value, "`", "\\`"))
+			}
+		case tokenString:
+			t.text = fmt.Sprintf("'%s'", strings.ReplaceAll(strings.ReplaceAll(t.value, `\`, `\\`), "'", `\'`))
+		case tokenIdentifier:
+			upper := strings.ToUpper(t.text)
+			// INTERVAL n UNIT => toIntervalUnit(n).
+			if upper == "INTERVAL" && i+2 < len(tokenList) && tokenList[i+1].tp == tokenNumber {
+				if unit, ok := intervalUnitSet[strings.ToUpper(tokenList[i+2].text)]; ok {
+					result = append(result,
+						token{tp: tokenIdentifier, text: "toInterval" + unit},
+						token{tp: tokenSymbol, text: "("},
+						tokenList[i+1],
+						token{tp: tokenSymbol, text: ")"},
+					)
+					i += 2
+					continue
+				}
+			}
+			if canonicalKeywordSet[upper] {
+				t.text = upper
+			}
+		}
+		result = append(result, t)
+	}
+	return formatTokenList(result)
+}
+
+// canonicalizeColumn returns the canonical text of the column definition with the data type aliases resolved.
+func canonicalizeColumn(definition []token) string {
+	if len(definition) > 0 && definition[0].tp == tokenIdentifier {
+		if tp, ok := dataTypeAliasMap[strings.ToUpper(definition[0].text)]; ok {
+			// The parameters of the aliases are ignored by ClickHouse, e.g. VARCHAR(255).
+			end := 1
+			if len(definition) > 1 && definition[1].text == "(" {
+				for end < len(definition) && definition[end].text != ")" {
+					end++
+				}
+				end++
+			}
+			if end <= len(definition) {
+				definition = append([]token{{tp: tokenIdentifier, text: tp, value: tp}}, definition[end:]...)
+			}
+		}
+	}
+	return canonicalize(definition)
+}
@@ -0,0 +1,166 @@
+package differ
+
+import (
+	"container/heap"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DependencyGraph is the dependency graph of the schema objects.
+// An edge from A to B means that A depends on B, so B must be created before A and dropped after A.
+type DependencyGraph struct {
+	nodeList []string
+	// nodeIndex is the map from the node to its insertion index, which breaks the ties in the topological sort.
+	nodeIndex map[string]int
+	// edgeMap is the map from the node to the nodes it depends on.
+	edgeMap map[string][]string
+}
+
+// NewDependencyGraph creates a new dependency graph.
+func NewDependencyGraph() *DependencyGraph {
+	return &DependencyGraph{
+		nodeIndex: make(map[string]int),
+		edgeMap:   make(map[string][]string),
+	}
+}
+
+// AddNode adds the node to the graph. Adding an existing node is a no-op.
+func (g *DependencyGraph) AddNode(node string) {
+	if _, exists := g.nodeIndex[node]; exists {
+		return
+	}
+	g.nodeIndex[node] = len(g.nodeList)
+	g.nodeList = append(g.nodeList, node)
+}
+
+// AddEdge adds the dependency that node "from" depends on node "to".
+// The edges from or to unknown nodes and the self-references are ignored in the sort.
+func (g *DependencyGraph) AddEdge(from, to string) {
+	if from == to {
+		return
+	}
+	g.edgeMap[from] = append(g.edgeMap[from], to)
+}
+
+// TopologicalSort returns the nodes in the order that every node comes after the nodes it depends on.
+// The nodes without dependencies between them keep their insertion order.
+// It returns an error describing the cycle if the graph has one.
+func (g *DependencyGraph) TopologicalSort() ([]string, error) {
+	inDegree := make(map[string]int)
+	dependentMap := make(map[string][]string)
+	for _, node := range g.nodeList {
+		for _, dependency := range g.dependencyList(node) {
+			inDegree[node]++
+			dependentMap[dependency] = append(dependentMap[dependency], node)
+		}
+	}
+
+	readyQueue := &nodeQueue{nodeIndex: g.nodeIndex}
+	for _, node := range g.nodeList {
+		if inDegree[node] == 0 {
+			heap.Push(readyQueue, node)
+		}
+	}
+	var result []string
+	for readyQueue.Len() > 0 {
+		node := heap.Pop(readyQueue).(string)
+		result = append(result, node)
+		for _, dependent := range dependentMap[node] {
+			inDegree[dependent]--
+			if inDegree[dependent] == 0 {
+				heap.Push(readyQueue, dependent)
+			}
+		}
+	}
+
+	if len(result) < len(g.nodeList) {
+		return nil, errors.Errorf("found dependency cycle: %s", strings.Join(g.findCycle(inDegree), " -> "))
+	}
+	return result, nil
+}
+
+// dependencyList returns the deduplicated known dependencies of the node.
+func (g *DependencyGraph) dependencyList(node string) []string {
+	var result []string
+	visited := make(map[string]bool)
+	for _, dependency := range g.edgeMap[node] {
+		if _, exists := g.nodeIndex[dependency]; !exists || dependency == node || visited[dependency] {
+			continue
+		}
+		visited[dependency] = true
+		result = append(result, dependency)
+	}
+	return result
+}
+
+// findCycle finds a cycle among the nodes which are not sorted, i.e. with positive in-degree.
+func (g *DependencyGraph) findCycle(inDegree map[string]int) []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int)
+	var path []string
+	var cycle []string
+	var visit func(node string) bool
+	visit = func(node string) bool {
+		state[node] = visiting
+		path = append(path, node)
+		for _, dependency := range g.dependencyList(node) {
+			switch state[dependency] {
+			case visiting:
+				for i, pathNode := range path {
+					if pathNode == dependency {
+						cycle = append(append(cycle, path[i:]...), dependency)
+						break
+					}
+				}
+				return true
+			case unvisited:
+				if visit(dependency) {
+					return true
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[node] = visited
+		return false
+	}
+	for _, node := range g.nodeList {
+		if inDegree[node] > 0 && state[node] == unvisited && visit(node) {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// nodeQueue is the priority queue of the nodes ordered by the insertion index.
+type nodeQueue struct {
+	nodeList  []string
+	nodeIndex map[string]int
+}
+
+func (q *nodeQueue) Len() int {
+	return len(q.nodeList)
+}
+
+func (q *nodeQueue) Less(i, j int) bool {
+	return q.nodeIndex[q.nodeList[i]] < q.nodeIndex[q.nodeList[j]]
+}
+
+func (q *nodeQueue) Swap(i, j int) {
+	q.nodeList[i], q.nodeList[j] = q.nodeList[j], q.nodeList[i]
+}
+
+func (q *nodeQueue) Push(x interface{}) {
+	q.nodeList = append(q.nodeList, x.(string))
+}
+
+func (q *nodeQueue) Pop() interface{} {
+	n := len(q.nodeList)
+	node := q.nodeList[n-1]
+	q.nodeList = q.nodeList[:n-1]
+	return node
+}
@@ -0,0 +1,69 @@
+package differ
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopologicalSort(t *testing.T) {
+	type edge struct {
+		from string
+		to   string
+	}
+	tests := []struct {
+		name     string
+		nodeList []string
+		edgeList []edge
+		want     []string
+		errPart  string
+	}{
+		{
+			name:     "keepInsertionOrder",
+			nodeList: []string{"a", "b", "c"},
+			want:     []string{"a", "b", "c"},
+		},
+		{
+			name:     "dependencyFirst",
+			nodeList: []string{"view", "orders", "users"},
+			edgeList: []edge{{"view", "orders"}, {"orders", "users"}},
+			want:     []string{"users", "orders", "view"},
+		},
+		{
+			name:     "ignoreUnknownAndSelfReference",
+			nodeList: []string{"a", "b"},
+			edgeList: []edge{{"a", "a"}, {"a", "unknown"}, {"b", "a"}, {"b", "a"}},
+			want:     []string{"a", "b"},
+		},
+		{
+			name:     "tieBreakByInsertionOrder",
+			nodeList: []string{"c", "b", "a", "d"},
+			edgeList: []edge{{"c", "d"}},
+			want:     []string{"b", "a", "d", "c"},
+		},
+		{
+			name:     "cycle",
+			nodeList: []string{"x", "a", "b", "c"},
+			edgeList: []edge{{"a", "b"}, {"b", "c"}, {"c", "a"}, {"x", "a"}},
+			errPart:  "found dependency cycle: a -> b -> c -> a",
+		},
+	}
+
+	for _, test := range tests {
+		g := NewDependencyGraph()
+		for _, node := range test.nodeList {
+			g.AddNode(node)
+		}
+		for _, e := range test.edgeList {
+			g.AddEdge(e.from, e.to)
+		}
+		got, err := g.TopologicalSort()
+		if test.errPart != "" {
+			require.Error(t, err, test.name)
+			require.Contains(t, err.Error(), test.errPart, test.name)
+			continue
+		}
+		require.NoError(t, err, test.name)
+		require.Equal(t, test.want, got, test.name)
+	}
+}
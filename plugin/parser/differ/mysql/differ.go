@@ -3,6 +3,7 @@ package mysql
 
 import (
 	"bytes"
+	"fmt"
 	"sort"
 
 	"github.com/pingcap/tidb/parser"
@@ -47,287 +48,530 @@ func (*SchemaDiffer) SchemaDiff(oldStmt, newStmt string) (string, error) {
 		return "", errors.Wrapf(err, "failed to parse new statement %q", newStmt)
 	}
 
-	var newNodeList []ast.Node
-	var inplaceUpdate []ast.Node
-	// inplaceDropNodeList and inplaceAddNodeList are used to handle destructive node updates.
-	// For example, we should drop the old index named 'id_idx' and then add a new index named 'id_idx' in the same table.
-	var inplaceDropNodeList []ast.Node
-	var inplaceAddNodeList []ast.Node
-	var dropNodeList []ast.Node
+	oldSchema := buildSchemaInfo(oldNodes)
+	newSchema := buildSchemaInfo(newNodes)
+	diff := newDiffNode()
+	// oldForeignKeySet and newForeignKeySet are the foreign keys dropped from the old schema and added to the new schema.
+	oldForeignKeySet := make(map[string]bool)
+	newForeignKeySet := make(map[string]bool)
 
-	oldTableMap := buildTableMap(oldNodes)
+	for _, tableName := range newSchema.tableList {
+		newStmt := newSchema.tableMap[tableName]
+		id := objectID(tableKind, tableName)
+		oldStmt, ok := oldSchema.tableMap[tableName]
+		if !ok {
+			stmt := *newStmt
+			stmt.IfNotExists = true
+			diff.addNewNode(id, &stmt)
+			for _, constraint := range newStmt.Constraints {
+				if constraint.Tp == ast.ConstraintForeignKey {
+					newForeignKeySet[foreignKeyID(tableName, constraint.Name)] = true
+				}
+			}
+			continue
+		}
+		if alterTableOptionStmt := diffTableOptions(newStmt.Table, oldStmt.Options, newStmt.Options); alterTableOptionStmt != nil {
+			diff.inplaceUpdate = append(diff.inplaceUpdate, alterTableOptionStmt)
+		}
+		constraintMap := buildConstraintMap(oldStmt)
+		var alterTableAddColumnSpecs []*ast.AlterTableSpec
+		var alterTableModifyColumnSpecs []*ast.AlterTableSpec
+		var alterTableAddNewConstraintSpecs []*ast.AlterTableSpec
+		var alterTableDropExcessConstraintSpecs []*ast.AlterTableSpec
+		var alterTableInplaceAddConstraintSpecs []*ast.AlterTableSpec
+		var alterTableInplaceDropConstraintSpecs []*ast.AlterTableSpec
 
-	for _, node := range newNodes {
-		switch newStmt := node.(type) {
-		case *ast.CreateTableStmt:
-			tableName := newStmt.Table.Name.O
-			oldStmt, ok := oldTableMap[tableName]
+		var alterTableDropColumnSpecs []*ast.AlterTableSpec
+
+		oldColumnMap := buildColumnMap(oldStmt)
+		for _, columnDef := range newStmt.Cols {
+			newColumnName := columnDef.Name.Name.O
+			oldColumnDef, ok := oldColumnMap[newColumnName]
 			if !ok {
-				stmt := *newStmt
-				stmt.IfNotExists = true
-				newNodeList = append(newNodeList, &stmt)
+				alterTableAddColumnSpecs = append(alterTableAddColumnSpecs, &ast.AlterTableSpec{
+					Tp:         ast.AlterTableAddColumns,
+					NewColumns: []*ast.ColumnDef{columnDef},
+				})
 				continue
 			}
-			if alterTableOptionStmt := diffTableOptions(newStmt.Table, oldStmt.Options, newStmt.Options); alterTableOptionStmt != nil {
-				inplaceUpdate = append(inplaceUpdate, alterTableOptionStmt)
+			// Compare the two column definitions.
+			delete(oldColumnMap, newColumnName)
+			if !isColumnEqual(oldColumnDef, columnDef) {
+				alterTableModifyColumnSpecs = append(alterTableModifyColumnSpecs, &ast.AlterTableSpec{
+					Tp:         ast.AlterTableModifyColumn,
+					NewColumns: []*ast.ColumnDef{columnDef},
+					Position:   &ast.ColumnPosition{Tp: ast.ColumnPositionNone},
+				})
 			}
-			constraintMap := buildConstraintMap(oldStmt)
-			var alterTableAddColumnSpecs []*ast.AlterTableSpec
-			var alterTableModifyColumnSpecs []*ast.AlterTableSpec
-			var alterTableAddNewConstraintSpecs []*ast.AlterTableSpec
-			var alterTableDropExcessConstraintSpecs []*ast.AlterTableSpec
-			var alterTableInplaceAddConstraintSpecs []*ast.AlterTableSpec
-			var alterTableInplaceDropConstraintSpecs []*ast.AlterTableSpec
-
-			oldColumnMap := buildColumnMap(oldNodes, newStmt.Table.Name)
-			for _, columnDef := range newStmt.Cols {
-				newColumnName := columnDef.Name.Name.O
-				oldColumnDef, ok := oldColumnMap[newColumnName]
-				if !ok {
-					alterTableAddColumnSpecs = append(alterTableAddColumnSpecs, &ast.AlterTableSpec{
-						Tp:         ast.AlterTableAddColumns,
-						NewColumns: []*ast.ColumnDef{columnDef},
-					})
+		}
+		// Compare the create definitions
+		for _, constraint := range newStmt.Constraints {
+			switch constraint.Tp {
+			case ast.ConstraintIndex, ast.ConstraintKey, ast.ConstraintUniq, ast.ConstraintUniqKey, ast.ConstraintUniqIndex, ast.ConstraintFulltext:
+				indexName := constraint.Name
+				if oldConstraint, ok := constraintMap[indexName]; ok {
+					if !isIndexEqual(constraint, oldConstraint) {
+						alterTableInplaceDropConstraintSpecs = append(alterTableInplaceDropConstraintSpecs, &ast.AlterTableSpec{
+							Tp:   ast.AlterTableDropIndex,
+							Name: indexName,
+						})
+						alterTableInplaceAddConstraintSpecs = append(alterTableInplaceAddConstraintSpecs, &ast.AlterTableSpec{
+							Tp:         ast.AlterTableAddConstraint,
+							Constraint: constraint,
+						})
+					}
+					delete(constraintMap, indexName)
 					continue
 				}
-				// Compare the two column definitions.
-				if !isColumnEqual(oldColumnDef, columnDef) {
-					alterTableModifyColumnSpecs = append(alterTableModifyColumnSpecs, &ast.AlterTableSpec{
-						Tp:         ast.AlterTableModifyColumn,
-						NewColumns: []*ast.ColumnDef{columnDef},
-						Position:   &ast.ColumnPosition{Tp: ast.ColumnPositionNone},
-					})
-				}
-			}
-			// Compare the create definitions
-			for _, constraint := range newStmt.Constraints {
-				switch constraint.Tp {
-				case ast.ConstraintIndex, ast.ConstraintKey, ast.ConstraintUniq, ast.ConstraintUniqKey, ast.ConstraintUniqIndex, ast.ConstraintFulltext:
-					indexName := constraint.Name
-					if oldConstraint, ok := constraintMap[indexName]; ok {
-						if !isIndexEqual(constraint, oldConstraint) {
-							alterTableInplaceDropConstraintSpecs = append(alterTableInplaceDropConstraintSpecs, &ast.AlterTableSpec{
-								Tp:   ast.AlterTableDropIndex,
-								Name: indexName,
-							})
-							alterTableInplaceAddConstraintSpecs = append(alterTableInplaceAddConstraintSpecs, &ast.AlterTableSpec{
-								Tp:         ast.AlterTableAddConstraint,
-								Constraint: constraint,
-							})
-						}
-						delete(constraintMap, indexName)
-						continue
-					}
-					alterTableAddNewConstraintSpecs = append(alterTableAddNewConstraintSpecs, &ast.AlterTableSpec{
-						Tp:         ast.AlterTableAddConstraint,
-						Constraint: constraint,
-					})
-				case ast.ConstraintPrimaryKey:
-					primaryKeyName := "PRIMARY"
-					if oldConstraint, ok := constraintMap[primaryKeyName]; ok {
-						if !isIndexEqual(constraint, oldConstraint) {
-							alterTableInplaceDropConstraintSpecs = append(alterTableInplaceDropConstraintSpecs, &ast.AlterTableSpec{
-								Tp: ast.AlterTableDropPrimaryKey,
-							})
-							alterTableInplaceAddConstraintSpecs = append(alterTableInplaceAddConstraintSpecs, &ast.AlterTableSpec{
-								Tp:         ast.AlterTableAddConstraint,
-								Constraint: constraint,
-							})
-						}
-						delete(constraintMap, primaryKeyName)
-						continue
+				alterTableAddNewConstraintSpecs = append(alterTableAddNewConstraintSpecs, &ast.AlterTableSpec{
+					Tp:         ast.AlterTableAddConstraint,
+					Constraint: constraint,
+				})
+			case ast.ConstraintPrimaryKey:
+				primaryKeyName := "PRIMARY"
+				if oldConstraint, ok := constraintMap[primaryKeyName]; ok {
+					if !isIndexEqual(constraint, oldConstraint) {
+						alterTableInplaceDropConstraintSpecs = append(alterTableInplaceDropConstraintSpecs, &ast.AlterTableSpec{
+							Tp: ast.AlterTableDropPrimaryKey,
+						})
+						alterTableInplaceAddConstraintSpecs = append(alterTableInplaceAddConstraintSpecs, &ast.AlterTableSpec{
+							Tp:         ast.AlterTableAddConstraint,
+							Constraint: constraint,
+						})
 					}
-					alterTableAddNewConstraintSpecs = append(alterTableAddNewConstraintSpecs, &ast.AlterTableSpec{
-						Tp:         ast.AlterTableAddConstraint,
-						Constraint: constraint,
-					})
-				// The parent column in the foreign key always needs an index, so in the case of referencing itself,
-				// we need to drop the foreign key before dropping the primary key.
-				// Since the mysqldump statement always puts the primary key in front of the foreign key, and we will reverse the drop statements order.
-				// TODO(zp): So we don't have to worry about this now until one of the statements doesn't come from mysqldump.
-				case ast.ConstraintForeignKey:
-					if oldConstraint, ok := constraintMap[constraint.Name]; ok {
-						if !isForeignKeyConstraintEqual(constraint, oldConstraint) {
-							alterTableInplaceDropConstraintSpecs = append(alterTableInplaceDropConstraintSpecs, &ast.AlterTableSpec{
-								Tp:   ast.AlterTableDropForeignKey,
-								Name: constraint.Name,
-							})
-							alterTableInplaceAddConstraintSpecs = append(alterTableInplaceAddConstraintSpecs, &ast.AlterTableSpec{
-								Tp:         ast.AlterTableAddConstraint,
-								Constraint: constraint,
-							})
-						}
-						delete(constraintMap, constraint.Name)
-						continue
+					delete(constraintMap, primaryKeyName)
+					continue
+				}
+				alterTableAddNewConstraintSpecs = append(alterTableAddNewConstraintSpecs, &ast.AlterTableSpec{
+					Tp:         ast.AlterTableAddConstraint,
+					Constraint: constraint,
+				})
+			// The parent column in the foreign key always needs an index, so in the case of referencing itself,
+			// we need to drop the foreign key before dropping the primary key.
+			// Since the mysqldump statement always puts the primary key in front of the foreign key, and we will reverse the drop statements order.
+			// TODO(zp): So we don't have to worry about this now until one of the statements doesn't come from mysqldump.
+			case ast.ConstraintForeignKey:
+				if oldConstraint, ok := constraintMap[constraint.Name]; ok {
+					if !isForeignKeyConstraintEqual(constraint, oldConstraint) {
+						oldForeignKeySet[foreignKeyID(tableName, constraint.Name)] = true
+						newForeignKeySet[foreignKeyID(tableName, constraint.Name)] = true
+						alterTableInplaceDropConstraintSpecs = append(alterTableInplaceDropConstraintSpecs, &ast.AlterTableSpec{
+							Tp:   ast.AlterTableDropForeignKey,
+							Name: constraint.Name,
+						})
+						alterTableInplaceAddConstraintSpecs = append(alterTableInplaceAddConstraintSpecs, &ast.AlterTableSpec{
+							Tp:         ast.AlterTableAddConstraint,
+							Constraint: constraint,
+						})
 					}
-					alterTableAddNewConstraintSpecs = append(alterTableAddNewConstraintSpecs, &ast.AlterTableSpec{
-						Tp:         ast.AlterTableAddConstraint,
-						Constraint: constraint,
-					})
+					delete(constraintMap, constraint.Name)
+					continue
 				}
-			}
-			if len(alterTableAddColumnSpecs) > 0 {
-				newNodeList = append(newNodeList, &ast.AlterTableStmt{
-					Table: &ast.TableName{
-						Name: model.NewCIStr(tableName),
-					},
-					Specs: alterTableAddColumnSpecs,
+				newForeignKeySet[foreignKeyID(tableName, constraint.Name)] = true
+				alterTableAddNewConstraintSpecs = append(alterTableAddNewConstraintSpecs, &ast.AlterTableSpec{
+					Tp:         ast.AlterTableAddConstraint,
+					Constraint: constraint,
 				})
 			}
-			if len(alterTableModifyColumnSpecs) > 0 {
-				inplaceUpdate = append(inplaceUpdate, &ast.AlterTableStmt{
-					Table: &ast.TableName{
-						Name: model.NewCIStr(tableName),
-					},
-					Specs: alterTableModifyColumnSpecs,
+		}
+		if len(alterTableAddColumnSpecs) > 0 {
+			diff.addNewNode(id, &ast.AlterTableStmt{
+				Table: &ast.TableName{
+					Name: model.NewCIStr(tableName),
+				},
+				Specs: alterTableAddColumnSpecs,
+			})
+		}
+		if len(alterTableModifyColumnSpecs) > 0 {
+			diff.inplaceUpdate = append(diff.inplaceUpdate, &ast.AlterTableStmt{
+				Table: &ast.TableName{
+					Name: model.NewCIStr(tableName),
+				},
+				Specs: alterTableModifyColumnSpecs,
+			})
+		}
+		// We should drop the remaining indices in the indexMap.
+		for indexName, constraint := range constraintMap {
+			switch constraint.Tp {
+			case ast.ConstraintIndex, ast.ConstraintKey, ast.ConstraintUniq, ast.ConstraintUniqKey, ast.ConstraintUniqIndex, ast.ConstraintFulltext:
+				alterTableDropExcessConstraintSpecs = append(alterTableDropExcessConstraintSpecs, &ast.AlterTableSpec{
+					Tp:   ast.AlterTableDropIndex,
+					Name: indexName,
 				})
-			}
-			// We should drop the remaining indices in the indexMap.
-			for indexName, constraint := range constraintMap {
-				switch constraint.Tp {
-				case ast.ConstraintIndex, ast.ConstraintKey, ast.ConstraintUniq, ast.ConstraintUniqKey, ast.ConstraintUniqIndex, ast.ConstraintFulltext:
-					alterTableDropExcessConstraintSpecs = append(alterTableDropExcessConstraintSpecs, &ast.AlterTableSpec{
-						Tp:   ast.AlterTableDropIndex,
-						Name: indexName,
-					})
-				case ast.ConstraintPrimaryKey:
-					alterTableDropExcessConstraintSpecs = append(alterTableDropExcessConstraintSpecs, &ast.AlterTableSpec{
-						Tp: ast.AlterTableDropPrimaryKey,
-					})
-				case ast.ConstraintForeignKey:
-					alterTableDropExcessConstraintSpecs = append(alterTableDropExcessConstraintSpecs, &ast.AlterTableSpec{
-						Tp:   ast.AlterTableDropForeignKey,
-						Name: constraint.Name,
-					})
-				}
-			}
-
-			if len(alterTableAddNewConstraintSpecs) > 0 {
-				newNodeList = append(newNodeList, &ast.AlterTableStmt{
-					Table: &ast.TableName{
-						Name: model.NewCIStr(tableName),
-					},
-					Specs: alterTableAddNewConstraintSpecs,
+			case ast.ConstraintPrimaryKey:
+				alterTableDropExcessConstraintSpecs = append(alterTableDropExcessConstraintSpecs, &ast.AlterTableSpec{
+					Tp: ast.AlterTableDropPrimaryKey,
 				})
-			}
-
-			if len(alterTableDropExcessConstraintSpecs) > 0 {
-				dropNodeList = append(dropNodeList, &ast.AlterTableStmt{
-					Table: &ast.TableName{
-						Name: model.NewCIStr(tableName),
-					},
-					Specs: alterTableDropExcessConstraintSpecs,
+			case ast.ConstraintForeignKey:
+				oldForeignKeySet[foreignKeyID(tableName, constraint.Name)] = true
+				alterTableDropExcessConstraintSpecs = append(alterTableDropExcessConstraintSpecs, &ast.AlterTableSpec{
+					Tp:   ast.AlterTableDropForeignKey,
+					Name: constraint.Name,
 				})
 			}
-
-			if len(alterTableInplaceDropConstraintSpecs) > 0 {
-				inplaceDropNodeList = append(inplaceDropNodeList, &ast.AlterTableStmt{
-					Table: &ast.TableName{
-						Name: model.NewCIStr(tableName),
-					},
-					Specs: alterTableInplaceDropConstraintSpecs,
+		}
+		// We should drop the remaining columns in the oldColumnMap after dropping the constraints on them.
+		for _, columnDef := range oldStmt.Cols {
+			if _, ok := oldColumnMap[columnDef.Name.Name.O]; ok {
+				alterTableDropColumnSpecs = append(alterTableDropColumnSpecs, &ast.AlterTableSpec{
+					Tp:            ast.AlterTableDropColumn,
+					OldColumnName: &ast.ColumnName{Name: columnDef.Name.Name},
 				})
 			}
+		}
 
-			if len(alterTableInplaceAddConstraintSpecs) > 0 {
-				inplaceAddNodeList = append(inplaceAddNodeList, &ast.AlterTableStmt{
-					Table: &ast.TableName{
-						Name: model.NewCIStr(tableName),
-					},
-					Specs: alterTableInplaceAddConstraintSpecs,
-				})
-			}
-		default:
+		if len(alterTableAddNewConstraintSpecs) > 0 {
+			diff.addNewNode(id, &ast.AlterTableStmt{
+				Table: &ast.TableName{
+					Name: model.NewCIStr(tableName),
+				},
+				Specs: alterTableAddNewConstraintSpecs,
+			})
+		}
+
+		if alterTableDropSpecs := append(alterTableDropExcessConstraintSpecs, alterTableDropColumnSpecs...); len(alterTableDropSpecs) > 0 {
+			diff.addDropNode(id, &ast.AlterTableStmt{
+				Table: &ast.TableName{
+					Name: model.NewCIStr(tableName),
+				},
+				Specs: alterTableDropSpecs,
+			})
+		}
+
+		if len(alterTableInplaceDropConstraintSpecs) > 0 {
+			diff.inplaceDrop = append(diff.inplaceDrop, &ast.AlterTableStmt{
+				Table: &ast.TableName{
+					Name: model.NewCIStr(tableName),
+				},
+				Specs: alterTableInplaceDropConstraintSpecs,
+			})
 		}
+
+		if len(alterTableInplaceAddConstraintSpecs) > 0 {
+			diff.inplaceAdd = append(diff.inplaceAdd, &ast.AlterTableStmt{
+				Table: &ast.TableName{
+					Name: model.NewCIStr(tableName),
+				},
+				Specs: alterTableInplaceAddConstraintSpecs,
+			})
+		}
+	}
+
+	diffView(diff, oldSchema, newSchema)
+	diffIndex(diff, oldSchema, newSchema)
+
+	return diff.deparse(oldSchema.buildDependencyGraph(oldForeignKeySet), newSchema.buildDependencyGraph(newForeignKeySet), format.DefaultRestoreFlags|format.RestoreStringWithoutCharset)
+}
+
+// diffView drops the views removed from the new schema, and re-creates the views changed in the new schema.
+func diffView(diff *diffNode, oldSchema, newSchema *schemaInfo) {
+	for _, name := range oldSchema.viewList {
+		newView, ok := newSchema.viewMap[name]
+		if ok && isNodeEqual(oldSchema.viewMap[name], newView) {
+			continue
+		}
+		diff.addDropNode(objectID(viewKind, name), &ast.DropTableStmt{
+			IsView: true,
+			Tables: []*ast.TableName{{Name: model.NewCIStr(name)}},
+		})
+	}
+	for _, name := range newSchema.viewList {
+		newView := newSchema.viewMap[name]
+		if oldView, ok := oldSchema.viewMap[name]; ok && isNodeEqual(oldView, newView) {
+			continue
+		}
+		diff.addNewDependentNode(objectID(viewKind, name), newView)
+	}
+}
+
+// diffIndex drops the indexes removed from the new schema, and re-creates the indexes changed in the new schema.
+// It only handles the indexes created by the CREATE INDEX statements, the ones in the CREATE TABLE statements are handled with the tables.
+func diffIndex(diff *diffNode, oldSchema, newSchema *schemaInfo) {
+	for _, key := range oldSchema.indexList {
+		oldIndex := oldSchema.indexMap[key]
+		if newIndex, ok := newSchema.indexMap[key]; ok && isNodeEqual(oldIndex, newIndex) {
+			continue
+		}
+		diff.addDropNode(objectID(indexKind, key), &ast.DropIndexStmt{
+			IndexName: oldIndex.IndexName,
+			Table:     &ast.TableName{Name: oldIndex.Table.Name},
+		})
+	}
+	for _, key := range newSchema.indexList {
+		newIndex := newSchema.indexMap[key]
+		if oldIndex, ok := oldSchema.indexMap[key]; ok && isNodeEqual(oldIndex, newIndex) {
+			continue
+		}
+		diff.addNewDependentNode(objectID(indexKind, key), newIndex)
+	}
+}
+
+// diffNode is the nodes of the schema diff.
+// The nodes of the new and dropped objects are keyed by the object ID, so that they can be restored in the dependency order.
+type diffNode struct {
+	// newNodeMap is the nodes creating the tables, and adding the columns and constraints to the tables.
+	newNodeMap map[string][]ast.Node
+	// newDependentNodeMap is the nodes creating the views and indexes, which are restored after all the table changes.
+	newDependentNodeMap map[string][]ast.Node
+	inplaceUpdate       []ast.Node
+	// inplaceDrop and inplaceAdd are used to handle destructive node updates.
+	// For example, we should drop the old index named 'id_idx' and then add a new index named 'id_idx' in the same table.
+	inplaceDrop []ast.Node
+	inplaceAdd  []ast.Node
+	// dropNodeMap is the nodes dropping the views and indexes, and dropping the columns and constraints from the tables.
+	dropNodeMap map[string][]ast.Node
+}
+
+func newDiffNode() *diffNode {
+	return &diffNode{
+		newNodeMap:          make(map[string][]ast.Node),
+		newDependentNodeMap: make(map[string][]ast.Node),
+		dropNodeMap:         make(map[string][]ast.Node),
 	}
-	return deparse(newNodeList, inplaceUpdate, inplaceAddNodeList, inplaceDropNodeList, dropNodeList, format.DefaultRestoreFlags|format.RestoreStringWithoutCharset)
 }
 
-func deparse(newNodeList []ast.Node, inplaceUpdate []ast.Node, inplaceAdd []ast.Node, inplaceDrop []ast.Node, dropNodeList []ast.Node, flag format.RestoreFlags) (string, error) {
+func (diff *diffNode) addNewNode(id string, node ast.Node) {
+	diff.newNodeMap[id] = append(diff.newNodeMap[id], node)
+}
+
+func (diff *diffNode) addNewDependentNode(id string, node ast.Node) {
+	diff.newDependentNodeMap[id] = append(diff.newDependentNodeMap[id], node)
+}
+
+func (diff *diffNode) addDropNode(id string, node ast.Node) {
+	diff.dropNodeMap[id] = append(diff.dropNodeMap[id], node)
+}
+
+func (diff *diffNode) deparse(oldGraph, newGraph *differ.DependencyGraph, flag format.RestoreFlags) (string, error) {
+	var oldObjectList, newObjectList []string
+	if len(diff.dropNodeMap) > 0 {
+		list, err := oldGraph.TopologicalSort()
+		if err != nil {
+			return "", errors.Wrap(err, "failed to sort the objects in the old schema")
+		}
+		oldObjectList = list
+	}
+	if len(diff.newNodeMap) > 0 || len(diff.newDependentNodeMap) > 0 {
+		list, err := newGraph.TopologicalSort()
+		if err != nil {
+			return "", errors.Wrap(err, "failed to sort the objects in the new schema")
+		}
+		newObjectList = list
+	}
+
 	var buf bytes.Buffer
 	// We should following the right order to avoid break the dependency:
-	// Additions for new nodes.
+	// Additions for new nodes (in the dependency order of the new schema).
 	// Updates for in-place node updates.
 	// Deletions for destructive (none in-place) node updates (in reverse order).
 	// Additions for destructive node updates.
-	// Deletions for deleted nodes (in reverse order).
-	for _, node := range newNodeList {
-		if err := node.Restore(format.NewRestoreCtx(flag, &buf)); err != nil {
-			return "", err
-		}
-		if _, err := buf.Write([]byte(";\n")); err != nil {
+	// Deletions for deleted nodes (in the reverse dependency order of the old schema).
+	// Additions for new views and indexes (in the dependency order of the new schema).
+	for _, id := range newObjectList {
+		if err := writeNodeList(&buf, diff.newNodeMap[id], flag); err != nil {
 			return "", err
 		}
 	}
-
-	for _, node := range inplaceUpdate {
-		if err := node.Restore(format.NewRestoreCtx(flag, &buf)); err != nil {
-			return "", err
-		}
-		if _, err := buf.Write([]byte(";\n")); err != nil {
-			return "", err
-		}
+	if err := writeNodeList(&buf, diff.inplaceUpdate, flag); err != nil {
+		return "", err
 	}
-
-	for i := len(inplaceDrop) - 1; i >= 0; i-- {
-		if err := inplaceDrop[i].Restore(format.NewRestoreCtx(flag, &buf)); err != nil {
+	for i := len(diff.inplaceDrop) - 1; i >= 0; i-- {
+		if err := writeNodeList(&buf, diff.inplaceDrop[i:i+1], flag); err != nil {
 			return "", err
 		}
-		if _, err := buf.Write([]byte(";\n")); err != nil {
+	}
+	for i := len(diff.inplaceAdd) - 1; i >= 0; i-- {
+		if err := writeNodeList(&buf, diff.inplaceAdd[i:i+1], flag); err != nil {
 			return "", err
 		}
 	}
-
-	for i := len(inplaceAdd) - 1; i >= 0; i-- {
-		if err := inplaceAdd[i].Restore(format.NewRestoreCtx(flag, &buf)); err != nil {
+	for i := len(oldObjectList) - 1; i >= 0; i-- {
+		if err := writeNodeList(&buf, diff.dropNodeMap[oldObjectList[i]], flag); err != nil {
 			return "", err
 		}
-		if _, err := buf.Write([]byte(";\n")); err != nil {
+	}
+	for _, id := range newObjectList {
+		if err := writeNodeList(&buf, diff.newDependentNodeMap[id], flag); err != nil {
 			return "", err
 		}
 	}
+	return buf.String(), nil
+}
 
-	for i := len(dropNodeList) - 1; i >= 0; i-- {
-		if err := dropNodeList[i].Restore(format.NewRestoreCtx(flag, &buf)); err != nil {
-			return "", err
+func writeNodeList(buf *bytes.Buffer, nodeList []ast.Node, flag format.RestoreFlags) error {
+	for _, node := range nodeList {
+		if err := node.Restore(format.NewRestoreCtx(flag, buf)); err != nil {
+			return err
 		}
 		if _, err := buf.Write([]byte(";\n")); err != nil {
-			return "", err
+			return err
 		}
 	}
-	return buf.String(), nil
+	return nil
 }
 
-// buildTableMap returns a map of table name to create table statements.
-func buildTableMap(nodes []ast.StmtNode) map[string]*ast.CreateTableStmt {
-	tableMap := make(map[string]*ast.CreateTableStmt)
+// objectKind is the kind of the schema objects.
+type objectKind string
+
+const (
+	tableKind objectKind = "table"
+	viewKind  objectKind = "view"
+	indexKind objectKind = "index"
+)
+
+// objectID returns the ID of the object in the dependency graph.
+func objectID(kind objectKind, key string) string {
+	return fmt.Sprintf("%s %s", kind, key)
+}
+
+// foreignKeyID returns the ID of the foreign key constraint on the table.
+func foreignKeyID(tableName, constraintName string) string {
+	return fmt.Sprintf("%s.%s", tableName, constraintName)
+}
+
+// schemaInfo is the tables, views and indexes in the schema.
+// The lists keep the statement order for generating stable diffs.
+type schemaInfo struct {
+	tableList []string
+	tableMap  map[string]*ast.CreateTableStmt
+	viewList  []string
+	viewMap   map[string]*ast.CreateViewStmt
+	// indexList and indexMap are keyed by "table.index".
+	indexList []string
+	indexMap  map[string]*ast.CreateIndexStmt
+}
+
+// buildSchemaInfo returns the tables, views and indexes created by the statements.
+// mysqldump creates a placeholder for each view before creating the tables, and drops it before creating the view,
+// so the objects dropped by the later statements are removed.
+func buildSchemaInfo(nodes []ast.StmtNode) *schemaInfo {
+	schema := &schemaInfo{
+		tableMap: make(map[string]*ast.CreateTableStmt),
+		viewMap:  make(map[string]*ast.CreateViewStmt),
+		indexMap: make(map[string]*ast.CreateIndexStmt),
+	}
+	var tableList, viewList, indexList []string
 	for _, node := range nodes {
 		switch stmt := node.(type) {
 		case *ast.CreateTableStmt:
-			tableName := stmt.Table.Name.String()
-			tableMap[tableName] = stmt
+			tableName := stmt.Table.Name.O
+			tableList = append(tableList, tableName)
+			schema.tableMap[tableName] = stmt
+		case *ast.CreateViewStmt:
+			viewName := stmt.ViewName.Name.O
+			viewList = append(viewList, viewName)
+			schema.viewMap[viewName] = stmt
+		case *ast.CreateIndexStmt:
+			key := fmt.Sprintf("%s.%s", stmt.Table.Name.O, stmt.IndexName)
+			indexList = append(indexList, key)
+			schema.indexMap[key] = stmt
+		case *ast.DropTableStmt:
+			for _, table := range stmt.Tables {
+				if stmt.IsView {
+					delete(schema.viewMap, table.Name.O)
+				} else {
+					delete(schema.tableMap, table.Name.O)
+				}
+			}
 		default:
 		}
 	}
-	return tableMap
+	schema.tableList = filterKeyList(tableList, func(key string) bool { _, ok := schema.tableMap[key]; return ok })
+	schema.viewList = filterKeyList(viewList, func(key string) bool { _, ok := schema.viewMap[key]; return ok })
+	schema.indexList = filterKeyList(indexList, func(key string) bool { _, ok := schema.indexMap[key]; return ok })
+	return schema
 }
 
-// buildColumnMap returns a map of column name to column definition on a given table.
-func buildColumnMap(nodes []ast.StmtNode, tableName model.CIStr) map[string]*ast.ColumnDef {
-	oldColumnMap := make(map[string]*ast.ColumnDef)
-	for _, node := range nodes {
-		switch stmt := node.(type) {
-		case *ast.CreateTableStmt:
-			if stmt.Table.Name.O != tableName.O {
+// filterKeyList returns the existing keys in the first appearance order.
+func filterKeyList(keyList []string, exists func(key string) bool) []string {
+	var result []string
+	seen := make(map[string]bool)
+	for _, key := range keyList {
+		if seen[key] || !exists(key) {
+			continue
+		}
+		seen[key] = true
+		result = append(result, key)
+	}
+	return result
+}
+
+// buildDependencyGraph builds the dependency graph of the tables, views and indexes in the schema.
+// Only the foreign keys in the foreignKeySet add the edges between the tables, because the existing foreign keys
+// are not changed by the diff and MySQL allows them to reference each other.
+func (schema *schemaInfo) buildDependencyGraph(foreignKeySet map[string]bool) *differ.DependencyGraph {
+	graph := differ.NewDependencyGraph()
+	for _, name := range schema.tableList {
+		graph.AddNode(objectID(tableKind, name))
+	}
+	for _, name := range schema.viewList {
+		graph.AddNode(objectID(viewKind, name))
+	}
+	for _, key := range schema.indexList {
+		graph.AddNode(objectID(indexKind, key))
+	}
+
+	for _, name := range schema.tableList {
+		for _, constraint := range schema.tableMap[name].Constraints {
+			if constraint.Tp != ast.ConstraintForeignKey || constraint.Refer == nil || !foreignKeySet[foreignKeyID(name, constraint.Name)] {
 				continue
 			}
-			for _, columnDef := range stmt.Cols {
-				oldColumnMap[columnDef.Name.Name.O] = columnDef
-			}
-		default:
+			graph.AddEdge(objectID(tableKind, name), objectID(tableKind, constraint.Refer.Table.Name.O))
 		}
 	}
-	return oldColumnMap
+	for _, name := range schema.viewList {
+		collector := &tableNameCollector{}
+		schema.viewMap[name].Select.Accept(collector)
+		for _, dependency := range collector.nameList {
+			// The view may select from either a table or another view.
+			graph.AddEdge(objectID(viewKind, name), objectID(tableKind, dependency))
+			graph.AddEdge(objectID(viewKind, name), objectID(viewKind, dependency))
+		}
+	}
+	for _, key := range schema.indexList {
+		graph.AddEdge(objectID(indexKind, key), objectID(tableKind, schema.indexMap[key].Table.Name.O))
+	}
+	return graph
+}
+
+// tableNameCollector collects the names of the tables and views referenced by a statement.
+type tableNameCollector struct {
+	nameList []string
+}
+
+// Enter implements the ast.Visitor interface.
+func (c *tableNameCollector) Enter(in ast.Node) (ast.Node, bool) {
+	if table, ok := in.(*ast.TableName); ok {
+		c.nameList = append(c.nameList, table.Name.O)
+	}
+	return in, false
+}
+
+// Leave implements the ast.Visitor interface.
+func (*tableNameCollector) Leave(in ast.Node) (ast.Node, bool) {
+	return in, true
+}
+
+// isNodeEqual returns true if the two nodes are restored to the same statement.
+func isNodeEqual(old, new ast.Node) bool {
+	var oldBuf, newBuf bytes.Buffer
+	flag := format.DefaultRestoreFlags | format.RestoreStringWithoutCharset
+	if err := old.Restore(format.NewRestoreCtx(flag, &oldBuf)); err != nil {
+		return false
+	}
+	if err := new.Restore(format.NewRestoreCtx(flag, &newBuf)); err != nil {
+		return false
+	}
+	return oldBuf.String() == newBuf.String()
+}
+
+// buildColumnMap returns a map of column name to column definition on the table.
+func buildColumnMap(stmt *ast.CreateTableStmt) map[string]*ast.ColumnDef {
+	columnMap := make(map[string]*ast.ColumnDef)
+	for _, columnDef := range stmt.Cols {
+		columnMap[columnDef.Name.Name.O] = columnDef
+	}
+	return columnMap
 }
 
 // buildConstraintMap build a map of index name to constraint on given table name.
@@ -384,6 +628,11 @@ func isColumnOptionsEqual(old, new []*ast.ColumnOption) bool {
 			if oldOption.StrValue != newOption.StrValue {
 				return false
 			}
+		case ast.ColumnOptionAutoRandom:
+			// TiDB specific, AUTO_RANDOM(shard_bits).
+			if oldOption.AutoRandomBitLength != newOption.AutoRandomBitLength {
+				return false
+			}
 		default:
 		}
 	}
@@ -412,15 +661,6 @@ func isIndexEqual(old, new *ast.Constraint) bool {
 	if old.Name != new.Name {
 		return false
 	}
-	if (old.Option == nil) != (new.Option == nil) {
-		return false
-	}
-	if old.Option != nil && new.Option != nil {
-		if old.Option.Tp != new.Option.Tp {
-			return false
-		}
-	}
-
 	if !isKeyPartEqual(old.Keys, new.Keys) {
 		return false
 	}
@@ -485,11 +725,12 @@ func isIndexOptionEqual(old, new *ast.IndexOption) bool {
 	//   |ENGINE_ATTRIBUTE [=] 'string'
 	//   |SECONDARY_ENGINE_ATTRIBUTE [=] 'string'
 	// }
-	if (old == nil) != (new == nil) {
-		return false
+	// TiDB always shows the index options such as the clustered primary key, so we treat the nil option as the empty one.
+	if old == nil {
+		old = &ast.IndexOption{}
 	}
-	if old == nil && new == nil {
-		return true
+	if new == nil {
+		new = &ast.IndexOption{}
 	}
 	if old.KeyBlockSize != new.KeyBlockSize {
 		return false
@@ -506,6 +747,11 @@ func isIndexOptionEqual(old, new *ast.IndexOption) bool {
 	if old.Visibility != new.Visibility {
 		return false
 	}
+	// TiDB specific, {CLUSTERED | NONCLUSTERED} for primary key.
+	// The default type depends on the TiDB config, so we only compare the explicit ones.
+	if old.PrimaryKeyTp != model.PrimaryKeyTypeDefault && new.PrimaryKeyTp != model.PrimaryKeyTypeDefault && old.PrimaryKeyTp != new.PrimaryKeyTp {
+		return false
+	}
 	// TODO(zp): support ENGINE_ATTRIBUTE and SECONDARY_ENGINE_ATTRIBUTE.
 	return true
 }
@@ -602,6 +848,15 @@ func diffTableOptions(tableName *ast.TableName, old, new []*ast.TableOption) *as
 	//   | TABLESPACE tablespace_name [STORAGE {DISK | MEMORY}]
 	//   | UNION [=] (tbl_name[,tbl_name]...)
 	// }
+	// TiDB also supports the following table options:
+	// https://docs.pingcap.com/tidb/stable/sql-statement-create-table
+	// table_option: {
+	//     AUTO_ID_CACHE [=] value
+	//   | AUTO_RANDOM_BASE [=] value
+	//   | SHARD_ROW_ID_BITS [=] value
+	//   | PRE_SPLIT_REGIONS [=] value
+	//   | PLACEMENT POLICY [=] policy_name
+	// }
 
 	// We use map to record the table options, so we can easily find the difference.
 	oldOptionsMap := buildTableOptionMap(old)
@@ -761,6 +1016,25 @@ func dropTableOption(option *ast.TableOption) *ast.TableOption {
 		// TODO(zp): handle the table space
 	case ast.TableOptionUnion:
 		// TODO(zp): handle the union
+	case ast.TableOptionShardRowID:
+		// TiDB specific, the default SHARD_ROW_ID_BITS is 0.
+		return &ast.TableOption{
+			Tp:        ast.TableOptionShardRowID,
+			UintValue: 0,
+		}
+	case ast.TableOptionAutoRandomBase:
+		// TiDB specific, AUTO_RANDOM_BASE is the allocation state like AUTO_INCREMENT,
+		// but TiDB doesn't support resetting it to 0, so we leave it untouched.
+	case ast.TableOptionAutoIdCache:
+		// TiDB specific, the default AUTO_ID_CACHE depends on the TiDB version, so we leave it untouched.
+	case ast.TableOptionPreSplitRegion:
+		// TiDB specific, PRE_SPLIT_REGIONS only takes effect on table creation.
+	case ast.TableOptionPlacementPolicy:
+		// TiDB specific.
+		return &ast.TableOption{
+			Tp:       ast.TableOptionPlacementPolicy,
+			StrValue: "DEFAULT",
+		}
 	}
 	return nil
 }
@@ -848,6 +1122,17 @@ func isTableOptionValEqual(old, new *ast.TableOption) bool {
 			}
 		}
 		return true
+	case ast.TableOptionShardRowID:
+		return old.UintValue == new.UintValue
+	case ast.TableOptionAutoRandomBase:
+		return old.UintValue == new.UintValue
+	case ast.TableOptionAutoIdCache:
+		return old.UintValue == new.UintValue
+	case ast.TableOptionPreSplitRegion:
+		// PRE_SPLIT_REGIONS only takes effect on table creation, so we cannot change it.
+		return true
+	case ast.TableOptionPlacementPolicy:
+		return old.StrValue == new.StrValue
 	}
 	return true
 }
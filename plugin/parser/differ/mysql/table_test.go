@@ -270,3 +270,64 @@ func TestTableOption(t *testing.T) {
 		a.Equalf(test.want, out, "old: %s\nnew: %s\n", test.old, test.new)
 	}
 }
+
+func TestTableDependency(t *testing.T) {
+	tests := []struct {
+		old     string
+		new     string
+		want    string
+		errPart string
+	}{
+		{
+			old: ``,
+			new: `CREATE TABLE book(id INT, author_id INT, PRIMARY KEY(id), CONSTRAINT fk_author FOREIGN KEY (author_id) REFERENCES author(id));
+			CREATE TABLE author(id INT, PRIMARY KEY(id));
+			`,
+			want: "CREATE TABLE IF NOT EXISTS `author` (`id` INT,PRIMARY KEY(`id`));\nCREATE TABLE IF NOT EXISTS `book` (`id` INT,`author_id` INT,PRIMARY KEY(`id`),CONSTRAINT `fk_author` FOREIGN KEY (`author_id`) REFERENCES `author`(`id`));\n",
+		},
+		{
+			old: `CREATE TABLE book(id INT, author_id INT, PRIMARY KEY(id));`,
+			new: `CREATE TABLE book(id INT, author_id INT, PRIMARY KEY(id), CONSTRAINT fk_author FOREIGN KEY (author_id) REFERENCES author(id));
+			CREATE TABLE author(id INT, PRIMARY KEY(id));
+			`,
+			want: "CREATE TABLE IF NOT EXISTS `author` (`id` INT,PRIMARY KEY(`id`));\nALTER TABLE `book` ADD CONSTRAINT `fk_author` FOREIGN KEY (`author_id`) REFERENCES `author`(`id`);\n",
+		},
+		{
+			old: ``,
+			new: `CREATE TABLE a(id INT, b_id INT, PRIMARY KEY(id), CONSTRAINT fk_b FOREIGN KEY (b_id) REFERENCES b(id));
+			CREATE TABLE b(id INT, a_id INT, PRIMARY KEY(id), CONSTRAINT fk_a FOREIGN KEY (a_id) REFERENCES a(id));
+			`,
+			errPart: "found dependency cycle: table a -> table b -> table a",
+		},
+		{
+			old: `CREATE TABLE a(id INT, b_id INT, PRIMARY KEY(id), CONSTRAINT fk_b FOREIGN KEY (b_id) REFERENCES b(id));
+			CREATE TABLE b(id INT, a_id INT, PRIMARY KEY(id), CONSTRAINT fk_a FOREIGN KEY (a_id) REFERENCES a(id));
+			`,
+			new: `CREATE TABLE a(id INT, b_id INT, name VARCHAR(20), PRIMARY KEY(id), CONSTRAINT fk_b FOREIGN KEY (b_id) REFERENCES b(id));
+			CREATE TABLE b(id INT, a_id INT, PRIMARY KEY(id), CONSTRAINT fk_a FOREIGN KEY (a_id) REFERENCES a(id));
+			`,
+			want: "ALTER TABLE `a` ADD COLUMN (`name` VARCHAR(20));\n",
+		},
+		{
+			old: `CREATE TABLE author(id INT, PRIMARY KEY(id));
+			CREATE TABLE book(id INT, author_id INT, PRIMARY KEY(id), CONSTRAINT fk_author FOREIGN KEY (author_id) REFERENCES author(id));
+			`,
+			new: `CREATE TABLE author(id INT);
+			CREATE TABLE book(id INT, PRIMARY KEY(id));
+			`,
+			want: "ALTER TABLE `book` DROP FOREIGN KEY `fk_author`, DROP COLUMN `author_id`;\nALTER TABLE `author` DROP PRIMARY KEY;\n",
+		},
+	}
+	a := require.New(t)
+	mysqlDiffer := &SchemaDiffer{}
+	for _, test := range tests {
+		out, err := mysqlDiffer.SchemaDiff(test.old, test.new)
+		if test.errPart != "" {
+			a.Error(err)
+			a.Contains(err.Error(), test.errPart)
+			continue
+		}
+		a.NoError(err)
+		a.Equal(test.want, out)
+	}
+}
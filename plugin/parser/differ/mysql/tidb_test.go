@@ -0,0 +1,73 @@
+package mysql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTiDBOption(t *testing.T) {
+	tests := []struct {
+		old  string
+		new  string
+		want string
+	}{
+		// AUTO_RANDOM
+		{
+			old:  "CREATE TABLE `book` (`id` BIGINT NOT NULL /*T![auto_rand] AUTO_RANDOM(5) */,PRIMARY KEY (`id`) /*T![clustered_index] CLUSTERED */);",
+			new:  "CREATE TABLE book(id BIGINT NOT NULL AUTO_RANDOM(5), PRIMARY KEY(id));",
+			want: "",
+		},
+		{
+			old:  "CREATE TABLE `book` (`id` BIGINT NOT NULL /*T![auto_rand] AUTO_RANDOM(5) */,PRIMARY KEY (`id`) /*T![clustered_index] CLUSTERED */);",
+			new:  "CREATE TABLE book(id BIGINT NOT NULL AUTO_RANDOM(6), PRIMARY KEY(id));",
+			want: "ALTER TABLE `book` MODIFY COLUMN `id` BIGINT NOT NULL AUTO_RANDOM(6);\n",
+		},
+		// CLUSTERED
+		{
+			old:  "CREATE TABLE `book` (`id` BIGINT NOT NULL,PRIMARY KEY (`id`) /*T![clustered_index] CLUSTERED */);",
+			new:  "CREATE TABLE book(id BIGINT NOT NULL, PRIMARY KEY(id) NONCLUSTERED);",
+			want: "ALTER TABLE `book` DROP PRIMARY KEY;\nALTER TABLE `book` ADD PRIMARY KEY(`id`) NONCLUSTERED;\n",
+		},
+		// SHARD_ROW_ID_BITS
+		{
+			old:  "CREATE TABLE `book` (`id` INT) /*T! SHARD_ROW_ID_BITS=4 */;",
+			new:  "CREATE TABLE book(id INT) SHARD_ROW_ID_BITS = 4;",
+			want: "",
+		},
+		{
+			old:  "CREATE TABLE `book` (`id` INT) /*T! SHARD_ROW_ID_BITS=4 */;",
+			new:  "CREATE TABLE book(id INT) SHARD_ROW_ID_BITS = 6;",
+			want: "ALTER TABLE `book` SHARD_ROW_ID_BITS = 6;\n",
+		},
+		{
+			old:  "CREATE TABLE `book` (`id` INT) /*T! SHARD_ROW_ID_BITS=4 */;",
+			new:  "CREATE TABLE book(id INT);",
+			want: "ALTER TABLE `book` SHARD_ROW_ID_BITS = 0;\n",
+		},
+		// AUTO_RANDOM_BASE and PRE_SPLIT_REGIONS
+		{
+			old:  "CREATE TABLE `book` (`id` BIGINT NOT NULL /*T![auto_rand] AUTO_RANDOM(5) */,PRIMARY KEY (`id`) /*T![clustered_index] CLUSTERED */) /*T![auto_rand_base] AUTO_RANDOM_BASE=30001 */ /*T! PRE_SPLIT_REGIONS=2 */;",
+			new:  "CREATE TABLE book(id BIGINT NOT NULL AUTO_RANDOM(5), PRIMARY KEY(id));",
+			want: "",
+		},
+		// PLACEMENT POLICY
+		{
+			old:  "CREATE TABLE `book` (`id` INT) /*T![placement] PLACEMENT POLICY=`p1` */;",
+			new:  "CREATE TABLE book(id INT) PLACEMENT POLICY = p2;",
+			want: "ALTER TABLE `book` PLACEMENT POLICY = `p2`;\n",
+		},
+		{
+			old:  "CREATE TABLE `book` (`id` INT) /*T![placement] PLACEMENT POLICY=`p1` */;",
+			new:  "CREATE TABLE book(id INT);",
+			want: "ALTER TABLE `book` PLACEMENT POLICY = `DEFAULT`;\n",
+		},
+	}
+	a := require.New(t)
+	tidbDiffer := &SchemaDiffer{}
+	for _, test := range tests {
+		out, err := tidbDiffer.SchemaDiff(test.old, test.new)
+		a.NoError(err)
+		a.Equal(test.want, out)
+	}
+}
@@ -0,0 +1,83 @@
+package mysql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestView(t *testing.T) {
+	tests := []struct {
+		old     string
+		new     string
+		want    string
+		errPart string
+	}{
+		// The view depending on the dropped column is dropped before the column, and created after.
+		{
+			old: "CREATE TABLE `t` (`a` INT, `b` INT);\n" +
+				"/*!50001 DROP VIEW IF EXISTS `v`*/;\n" +
+				"/*!50001 CREATE ALGORITHM=UNDEFINED */\n" +
+				"/*!50013 DEFINER=`root`@`%` SQL SECURITY DEFINER */\n" +
+				"/*!50001 VIEW `v` AS select `t`.`a` AS `a`,`t`.`b` AS `b` from `t` */;\n",
+			new: "CREATE TABLE `t` (`a` INT);\n" +
+				"/*!50001 DROP VIEW IF EXISTS `v`*/;\n" +
+				"/*!50001 CREATE ALGORITHM=UNDEFINED */\n" +
+				"/*!50013 DEFINER=`root`@`%` SQL SECURITY DEFINER */\n" +
+				"/*!50001 VIEW `v` AS select `t`.`a` AS `a` from `t` */;\n",
+			want: "DROP VIEW `v`;\n" +
+				"ALTER TABLE `t` DROP COLUMN `b`;\n" +
+				"CREATE ALGORITHM = UNDEFINED DEFINER = `root`@`%` SQL SECURITY DEFINER VIEW `v` AS SELECT `t`.`a` AS `a` FROM `t`;\n",
+		},
+		// The views are dropped and created in the dependency order.
+		{
+			old: "CREATE TABLE `t` (`a` INT);\n" +
+				"CREATE VIEW `v2` AS SELECT `a` FROM `v1`;\n" +
+				"CREATE VIEW `v1` AS SELECT `a` FROM `t`;\n",
+			new: "CREATE TABLE `t` (`a` INT, `b` INT);\n" +
+				"CREATE VIEW `v2` AS SELECT `a`, `b` FROM `v1`;\n" +
+				"CREATE VIEW `v1` AS SELECT `a`, `b` FROM `t`;\n",
+			want: "ALTER TABLE `t` ADD COLUMN (`b` INT);\n" +
+				"DROP VIEW `v2`;\n" +
+				"DROP VIEW `v1`;\n" +
+				"CREATE ALGORITHM = UNDEFINED DEFINER = CURRENT_USER SQL SECURITY DEFINER VIEW `v1` AS SELECT `a`,`b` FROM `t`;\n" +
+				"CREATE ALGORITHM = UNDEFINED DEFINER = CURRENT_USER SQL SECURITY DEFINER VIEW `v2` AS SELECT `a`,`b` FROM `v1`;\n",
+		},
+		// The mysqldump placeholder of the view is not a table.
+		{
+			old: "CREATE TABLE `t` (`a` INT);\n",
+			new: "/*!50001 CREATE VIEW `v` AS SELECT 1 AS `a`*/;\n" +
+				"CREATE TABLE `t` (`a` INT);\n" +
+				"/*!50001 DROP VIEW IF EXISTS `v`*/;\n" +
+				"/*!50001 CREATE VIEW `v` AS select `t`.`a` AS `a` from `t` */;\n",
+			want: "CREATE ALGORITHM = UNDEFINED DEFINER = CURRENT_USER SQL SECURITY DEFINER VIEW `v` AS SELECT `t`.`a` AS `a` FROM `t`;\n",
+		},
+		{
+			old: "CREATE TABLE `t` (`a` INT);\n",
+			new: "CREATE TABLE `t` (`a` INT);\n" +
+				"CREATE VIEW `v1` AS SELECT `a` FROM `v2`;\n" +
+				"CREATE VIEW `v2` AS SELECT `a` FROM `v1`;\n",
+			errPart: "found dependency cycle: view v1 -> view v2 -> view v1",
+		},
+		// The standalone indexes are created after the tables.
+		{
+			old: "",
+			new: "CREATE INDEX `idx_a` ON `t` (`a`);\n" +
+				"CREATE TABLE `t` (`a` INT);\n",
+			want: "CREATE TABLE IF NOT EXISTS `t` (`a` INT);\n" +
+				"CREATE INDEX `idx_a` ON `t` (`a`);\n",
+		},
+	}
+	a := require.New(t)
+	mysqlDiffer := &SchemaDiffer{}
+	for _, test := range tests {
+		out, err := mysqlDiffer.SchemaDiff(test.old, test.new)
+		if test.errPart != "" {
+			a.Error(err)
+			a.Contains(err.Error(), test.errPart)
+			continue
+		}
+		a.NoError(err)
+		a.Equalf(test.want, out, "old: %s\nnew: %s\n", test.old, test.new)
+	}
+}
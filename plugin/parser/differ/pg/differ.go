@@ -3,7 +3,13 @@ package pg
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
 
+	pgquery "github.com/pganalyze/pg_query_go/v2"
 	"github.com/pkg/errors"
 
 	"github.com/bytebase/bytebase/plugin/parser"
@@ -13,6 +19,12 @@ import (
 
 var (
 	_ differ.SchemaDiffer = (*SchemaDiffer)(nil)
+
+	createOrReplaceReg = regexp.MustCompile(`(?is)^CREATE\s+(OR\s+REPLACE\s+)?`)
+)
+
+const (
+	publicSchemaName = "public"
 )
 
 func init() {
@@ -23,7 +35,92 @@ func init() {
 type SchemaDiffer struct {
 }
 
+// objectMap is the map from the object key to the statement creating it.
+// It keeps the statement order for generating stable diffs.
+type objectMap struct {
+	keyList []string
+	nodeMap map[string]ast.Node
+}
+
+func newObjectMap() *objectMap {
+	return &objectMap{
+		nodeMap: make(map[string]ast.Node),
+	}
+}
+
+func (m *objectMap) add(key string, node ast.Node) {
+	if _, exists := m.nodeMap[key]; !exists {
+		m.keyList = append(m.keyList, key)
+	}
+	m.nodeMap[key] = node
+}
+
+// objectKind is the kind of the schema objects.
+type objectKind string
+
+const (
+	extensionKind objectKind = "extension"
+	typeKind      objectKind = "type"
+	sequenceKind  objectKind = "sequence"
+	functionKind  objectKind = "function"
+	tableKind     objectKind = "table"
+	indexKind     objectKind = "index"
+	viewKind      objectKind = "view"
+	triggerKind   objectKind = "trigger"
+)
+
+// objectKindOrder is the creation order of the object kinds.
+// We cannot figure out all the dependencies from the statements, e.g. the functions used by the views,
+// so the objects without known dependencies between them are created in this order.
+var objectKindOrder = []objectKind{
+	extensionKind,
+	typeKind,
+	sequenceKind,
+	functionKind,
+	tableKind,
+	indexKind,
+	viewKind,
+	triggerKind,
+}
+
+// objectID returns the ID of the object in the dependency graph.
+func objectID(kind objectKind, key string) string {
+	return fmt.Sprintf("%s %s", kind, key)
+}
+
+// schemaInfo is the objects declared by a schema file.
+type schemaInfo struct {
+	objectMapByKind map[objectKind]*objectMap
+	// implicitSequenceSet is the set of sequences implicitly created by the serial columns.
+	implicitSequenceSet map[string]bool
+}
+
+func (s *schemaInfo) tableMap() *objectMap {
+	return s.objectMapByKind[tableKind]
+}
+
+// diffNode is the diff statements of the schema objects.
+type diffNode struct {
+	// dropStatementMap is the map from the object ID to the statements dropping the old object.
+	dropStatementMap map[string][]string
+	// createStatementMap is the map from the object ID to the statements creating or altering the new object.
+	createStatementMap map[string][]string
+}
+
+func (diff *diffNode) addDropStatement(kind objectKind, key string, statementList ...string) {
+	id := objectID(kind, key)
+	diff.dropStatementMap[id] = append(diff.dropStatementMap[id], statementList...)
+}
+
+func (diff *diffNode) addCreateStatement(kind objectKind, key string, statementList ...string) {
+	id := objectID(kind, key)
+	diff.createStatementMap[id] = append(diff.createStatementMap[id], statementList...)
+}
+
 // SchemaDiff computes the schema differences between old and new schema.
+// The statements are generated in the dependency order: the dropped objects go first in the reverse
+// topological order of the old schema, and then the created or changed objects in the topological order
+// of the new schema. It returns an error if there is a dependency cycle.
 func (*SchemaDiffer) SchemaDiff(oldStmt, newStmt string) (string, error) {
 	oldNodes, err := parser.Parse(parser.Postgres, parser.ParseContext{}, oldStmt)
 	if err != nil {
@@ -34,36 +131,703 @@ func (*SchemaDiffer) SchemaDiff(oldStmt, newStmt string) (string, error) {
 		return "", errors.Wrapf(err, "failed to parse new statement %q", newStmt)
 	}
 
-	oldTables := make(map[string]*ast.CreateTableStmt)
-	for _, node := range oldNodes {
-		if n, ok := node.(*ast.CreateTableStmt); ok {
-			oldTables[n.Name.Name] = n
+	oldSchema, err := buildSchemaInfo(oldNodes)
+	if err != nil {
+		return "", err
+	}
+	newSchema, err := buildSchemaInfo(newNodes)
+	if err != nil {
+		return "", err
+	}
+
+	diff := &diffNode{
+		dropStatementMap:   make(map[string][]string),
+		createStatementMap: make(map[string][]string),
+	}
+	diff.diffTable(oldSchema, newSchema)
+	diff.diffIndex(oldSchema, newSchema)
+	diff.diffView(oldSchema, newSchema)
+	if err := diff.diffSequence(oldSchema, newSchema); err != nil {
+		return "", err
+	}
+	if err := diff.diffType(oldSchema, newSchema); err != nil {
+		return "", err
+	}
+	if err := diff.diffFunction(oldSchema, newSchema); err != nil {
+		return "", err
+	}
+	diff.diffTrigger(oldSchema, newSchema)
+	diff.diffExtension(oldSchema, newSchema)
+
+	return diff.deparse(oldSchema, newSchema)
+}
+
+func buildSchemaInfo(nodes []ast.Node) (*schemaInfo, error) {
+	schema := &schemaInfo{
+		objectMapByKind:     make(map[objectKind]*objectMap),
+		implicitSequenceSet: make(map[string]bool),
+	}
+	for _, kind := range objectKindOrder {
+		schema.objectMapByKind[kind] = newObjectMap()
+	}
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case *ast.CreateTableStmt:
+			schema.objectMapByKind[tableKind].add(tableKey(n.Name), n)
+			for _, column := range n.ColumnList {
+				if _, ok := column.Type.(*ast.Serial); ok {
+					// PostgreSQL names the sequence of serial column as "table_column_seq".
+					sequenceName := fmt.Sprintf("%s_%s_seq", n.Name.Name, column.ColumnName)
+					schema.implicitSequenceSet[objectKey(n.Name.Schema, sequenceName)] = true
+				}
+			}
+		case *ast.CreateIndexStmt:
+			// We cannot identify the index without name, because PostgreSQL generates the name on creation.
+			if n.Index.Name != "" {
+				schema.objectMapByKind[indexKind].add(objectKey(n.Index.Table.Schema, n.Index.Name), n)
+			}
+		case *ast.CreateViewStmt:
+			schema.objectMapByKind[viewKind].add(tableKey(n.Name), n)
+		case *ast.CreateSequenceStmt:
+			schema.objectMapByKind[sequenceKind].add(objectKey(n.SequenceDef.SequenceName.Schema, n.SequenceDef.SequenceName.Name), n)
+		case *ast.CreateTypeStmt:
+			typeName := n.Type.TypeName()
+			schema.objectMapByKind[typeKind].add(objectKey(typeName.Schema, typeName.Name), n)
+		case *ast.CreateFunctionStmt:
+			signature, err := functionSignature(n.Function)
+			if err != nil {
+				return nil, err
+			}
+			schema.objectMapByKind[functionKind].add(objectKey(n.Function.Schema, signature), n)
+		case *ast.CreateTriggerStmt:
+			schema.objectMapByKind[triggerKind].add(triggerKey(n.Trigger), n)
+		case *ast.CreateExtensionStmt:
+			schema.objectMapByKind[extensionKind].add(n.Name, n)
+		}
+	}
+	return schema, nil
+}
+
+// buildDependencyGraph builds the dependency graph of the objects in the schema.
+// The dependencies include the foreign keys, the relations used by the views, the tables of the indexes
+// and the triggers, the functions of the triggers and the tables owning the sequences.
+func (s *schemaInfo) buildDependencyGraph() *differ.DependencyGraph {
+	graph := differ.NewDependencyGraph()
+	for _, kind := range objectKindOrder {
+		for _, key := range s.objectMapByKind[kind].keyList {
+			graph.AddNode(objectID(kind, key))
+		}
+	}
+
+	for _, key := range s.objectMapByKind[tableKind].keyList {
+		table := s.objectMapByKind[tableKind].nodeMap[key].(*ast.CreateTableStmt)
+		constraintList := table.ConstraintList
+		for _, column := range table.ColumnList {
+			constraintList = append(constraintList, column.ConstraintList...)
+		}
+		for _, constraint := range constraintList {
+			if constraint.Type == ast.ConstraintTypeForeign && constraint.Foreign != nil {
+				graph.AddEdge(objectID(tableKind, key), objectID(tableKind, tableKey(constraint.Foreign.Table)))
+			}
+		}
+	}
+	for _, key := range s.objectMapByKind[indexKind].keyList {
+		index := s.objectMapByKind[indexKind].nodeMap[key].(*ast.CreateIndexStmt)
+		graph.AddEdge(objectID(indexKind, key), objectID(tableKind, tableKey(index.Index.Table)))
+	}
+	for _, key := range s.objectMapByKind[viewKind].keyList {
+		view := s.objectMapByKind[viewKind].nodeMap[key]
+		for _, relation := range extractRelationList(view.Text()) {
+			// The relation may be either a table or a view, and the unknown one is ignored by the graph.
+			graph.AddEdge(objectID(viewKind, key), objectID(tableKind, relation))
+			graph.AddEdge(objectID(viewKind, key), objectID(viewKind, relation))
+		}
+	}
+	for _, key := range s.objectMapByKind[sequenceKind].keyList {
+		sequence := s.objectMapByKind[sequenceKind].nodeMap[key].(*ast.CreateSequenceStmt)
+		if ownedBy := sequence.SequenceDef.OwnedBy; ownedBy != nil && ownedBy.Table != nil {
+			graph.AddEdge(objectID(sequenceKind, key), objectID(tableKind, tableKey(ownedBy.Table)))
+		}
+	}
+	for _, key := range s.objectMapByKind[triggerKind].keyList {
+		trigger := s.objectMapByKind[triggerKind].nodeMap[key].(*ast.CreateTriggerStmt)
+		graph.AddEdge(objectID(triggerKind, key), objectID(tableKind, tableKey(trigger.Trigger.Table)))
+		// The trigger function takes no arguments.
+		functionKey := objectKey(trigger.Trigger.Function.Schema, fmt.Sprintf("%s()", quoteIdentifier(trigger.Trigger.Function.Name)))
+		graph.AddEdge(objectID(triggerKind, key), objectID(functionKind, functionKey))
+	}
+	return graph
+}
+
+// extractRelationList returns the keys of the relations referenced by the statement.
+func extractRelationList(statement string) []string {
+	jsonText, err := pgquery.ParseToJSON(statement)
+	if err != nil {
+		return nil
+	}
+	var tree interface{}
+	if err := json.Unmarshal([]byte(jsonText), &tree); err != nil {
+		return nil
+	}
+	var result []string
+	var walk func(value interface{})
+	walk = func(value interface{}) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			for field, child := range v {
+				// Only the RangeVar wrapped as a node is a referenced relation, the view name itself is not.
+				if rangeVar, ok := child.(map[string]interface{}); ok && field == "RangeVar" {
+					schema, _ := rangeVar["schemaname"].(string)
+					name, _ := rangeVar["relname"].(string)
+					result = append(result, objectKey(schema, name))
+				}
+				walk(child)
+			}
+		case []interface{}:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	walk(tree)
+	return result
+}
+
+func (diff *diffNode) diffTable(oldSchema, newSchema *schemaInfo) {
+	for _, key := range newSchema.tableMap().keyList {
+		if _, exists := oldSchema.tableMap().nodeMap[key]; !exists {
+			diff.addCreateStatement(tableKind, key, newSchema.tableMap().nodeMap[key].Text())
+		}
+	}
+}
+
+func (diff *diffNode) diffIndex(oldSchema, newSchema *schemaInfo) {
+	oldIndexMap, newIndexMap := oldSchema.objectMapByKind[indexKind], newSchema.objectMapByKind[indexKind]
+	for _, key := range oldIndexMap.keyList {
+		oldIndex := oldIndexMap.nodeMap[key].(*ast.CreateIndexStmt)
+		newIndex, exists := newIndexMap.nodeMap[key]
+		// There is no CREATE OR REPLACE INDEX, so we drop and recreate the changed index.
+		if !exists || !isStatementEqual(oldIndex.Text(), newIndex.Text()) {
+			diff.addDropStatement(indexKind, key, fmt.Sprintf("DROP INDEX %s;", quoteName(oldIndex.Index.Table.Schema, oldIndex.Index.Name)))
+		}
+	}
+	for _, key := range newIndexMap.keyList {
+		newIndex := newIndexMap.nodeMap[key]
+		oldIndex, exists := oldIndexMap.nodeMap[key]
+		if !exists || !isStatementEqual(oldIndex.Text(), newIndex.Text()) {
+			diff.addCreateStatement(indexKind, key, newIndex.Text())
+		}
+	}
+}
+
+func (diff *diffNode) diffView(oldSchema, newSchema *schemaInfo) {
+	for _, key := range oldSchema.objectMapByKind[viewKind].keyList {
+		if _, exists := newSchema.objectMapByKind[viewKind].nodeMap[key]; !exists {
+			oldView := oldSchema.objectMapByKind[viewKind].nodeMap[key].(*ast.CreateViewStmt)
+			diff.addDropStatement(viewKind, key, fmt.Sprintf("DROP VIEW %s;", quoteTableName(oldView.Name)))
+		}
+	}
+	for _, key := range newSchema.objectMapByKind[viewKind].keyList {
+		newView := newSchema.objectMapByKind[viewKind].nodeMap[key]
+		oldView, exists := oldSchema.objectMapByKind[viewKind].nodeMap[key]
+		if !exists {
+			diff.addCreateStatement(viewKind, key, newView.Text())
+			continue
+		}
+		if !isStatementEqual(oldView.Text(), newView.Text()) {
+			diff.addCreateStatement(viewKind, key, createOrReplace(newView.Text()))
+		}
+	}
+}
+
+func (diff *diffNode) diffSequence(oldSchema, newSchema *schemaInfo) error {
+	for _, key := range oldSchema.objectMapByKind[sequenceKind].keyList {
+		if _, exists := newSchema.objectMapByKind[sequenceKind].nodeMap[key]; exists {
+			continue
+		}
+		// The sequences of the serial columns aren't declared explicitly in the new schema.
+		if newSchema.implicitSequenceSet[key] {
+			continue
+		}
+		oldSequence := oldSchema.objectMapByKind[sequenceKind].nodeMap[key].(*ast.CreateSequenceStmt)
+		diff.addDropStatement(sequenceKind, key, fmt.Sprintf("DROP SEQUENCE %s;", quoteName(oldSequence.SequenceDef.SequenceName.Schema, oldSequence.SequenceDef.SequenceName.Name)))
+	}
+	for _, key := range newSchema.objectMapByKind[sequenceKind].keyList {
+		newSequence := newSchema.objectMapByKind[sequenceKind].nodeMap[key].(*ast.CreateSequenceStmt)
+		oldNode, exists := oldSchema.objectMapByKind[sequenceKind].nodeMap[key]
+		if !exists {
+			diff.addCreateStatement(sequenceKind, key, newSequence.Text())
+			continue
+		}
+		alterSequence, err := diffSequenceDef(&oldNode.(*ast.CreateSequenceStmt).SequenceDef, &newSequence.SequenceDef)
+		if err != nil {
+			return err
+		}
+		if alterSequence != "" {
+			diff.addCreateStatement(sequenceKind, key, alterSequence)
+		}
+	}
+	return nil
+}
+
+// diffSequenceDef returns the ALTER SEQUENCE statement for the changed options, or empty string if there is no change.
+// OWNED BY is not compared because pg_dump emits it in a separate ALTER SEQUENCE statement.
+func diffSequenceDef(oldSequence, newSequence *ast.SequenceDef) (string, error) {
+	var optionList []string
+
+	oldType, err := deparseDataType(sequenceDataType(oldSequence))
+	if err != nil {
+		return "", err
+	}
+	newType, err := deparseDataType(sequenceDataType(newSequence))
+	if err != nil {
+		return "", err
+	}
+	if oldType != newType {
+		optionList = append(optionList, fmt.Sprintf("AS %s", newType))
+	}
+	if newIncrement := sequenceIncrement(newSequence); sequenceIncrement(oldSequence) != newIncrement {
+		optionList = append(optionList, fmt.Sprintf("INCREMENT BY %d", newIncrement))
+	}
+	if !isInt64PointerEqual(sequenceMinValue(oldSequence), sequenceMinValue(newSequence)) {
+		if newSequence.MinValue == nil {
+			optionList = append(optionList, "NO MINVALUE")
+		} else {
+			optionList = append(optionList, fmt.Sprintf("MINVALUE %d", *newSequence.MinValue))
+		}
+	}
+	if !isInt64PointerEqual(sequenceMaxValue(oldSequence), sequenceMaxValue(newSequence)) {
+		if newSequence.MaxValue == nil {
+			optionList = append(optionList, "NO MAXVALUE")
+		} else {
+			optionList = append(optionList, fmt.Sprintf("MAXVALUE %d", *newSequence.MaxValue))
+		}
+	}
+	if newStart := sequenceStart(newSequence); sequenceStart(oldSequence) != newStart {
+		optionList = append(optionList, fmt.Sprintf("START WITH %d", newStart))
+	}
+	if newCache := sequenceCache(newSequence); sequenceCache(oldSequence) != newCache {
+		optionList = append(optionList, fmt.Sprintf("CACHE %d", newCache))
+	}
+	if oldSequence.Cycle != newSequence.Cycle {
+		if newSequence.Cycle {
+			optionList = append(optionList, "CYCLE")
+		} else {
+			optionList = append(optionList, "NO CYCLE")
+		}
+	}
+
+	if len(optionList) == 0 {
+		return "", nil
+	}
+	return fmt.Sprintf("ALTER SEQUENCE %s %s;", quoteName(newSequence.SequenceName.Schema, newSequence.SequenceName.Name), strings.Join(optionList, " ")), nil
+}
+
+func (diff *diffNode) diffType(oldSchema, newSchema *schemaInfo) error {
+	for _, key := range oldSchema.objectMapByKind[typeKind].keyList {
+		if _, exists := newSchema.objectMapByKind[typeKind].nodeMap[key]; !exists {
+			typeName := oldSchema.objectMapByKind[typeKind].nodeMap[key].(*ast.CreateTypeStmt).Type.TypeName()
+			diff.addDropStatement(typeKind, key, fmt.Sprintf("DROP TYPE %s;", quoteName(typeName.Schema, typeName.Name)))
+		}
+	}
+	for _, key := range newSchema.objectMapByKind[typeKind].keyList {
+		newType := newSchema.objectMapByKind[typeKind].nodeMap[key].(*ast.CreateTypeStmt)
+		oldNode, exists := oldSchema.objectMapByKind[typeKind].nodeMap[key]
+		if !exists {
+			diff.addCreateStatement(typeKind, key, newType.Text())
+			continue
+		}
+		oldEnum, oldIsEnum := oldNode.(*ast.CreateTypeStmt).Type.(*ast.EnumTypeDef)
+		newEnum, newIsEnum := newType.Type.(*ast.EnumTypeDef)
+		if !oldIsEnum || !newIsEnum {
+			continue
+		}
+		alterTypeList, err := diffEnumLabel(oldEnum, newEnum)
+		if err != nil {
+			return err
+		}
+		diff.addCreateStatement(typeKind, key, alterTypeList...)
+	}
+	return nil
+}
+
+// diffEnumLabel returns the ALTER TYPE ... ADD VALUE statements for the new enum labels.
+// PostgreSQL doesn't support removing or reordering the enum labels, so we return an error for these cases.
+func diffEnumLabel(oldEnum, newEnum *ast.EnumTypeDef) ([]string, error) {
+	typeName := quoteName(newEnum.Name.Schema, newEnum.Name.Name)
+	newLabelIndex := make(map[string]int)
+	for i, label := range newEnum.LabelList {
+		newLabelIndex[label] = i
+	}
+	oldLabelSet := make(map[string]bool)
+	lastIndex := -1
+	for _, label := range oldEnum.LabelList {
+		index, exists := newLabelIndex[label]
+		if !exists {
+			return nil, errors.Errorf("cannot drop the value %q of enum type %s", label, typeName)
+		}
+		if index < lastIndex {
+			return nil, errors.Errorf("cannot reorder the value %q of enum type %s", label, typeName)
+		}
+		lastIndex = index
+		oldLabelSet[label] = true
+	}
+
+	var res []string
+	for i, label := range newEnum.LabelList {
+		if oldLabelSet[label] {
+			continue
+		}
+		switch {
+		case i > 0:
+			// The previous label always exists because we add the labels in order.
+			res = append(res, fmt.Sprintf("ALTER TYPE %s ADD VALUE %s AFTER %s;", typeName, quoteString(label), quoteString(newEnum.LabelList[i-1])))
+		case len(oldEnum.LabelList) > 0:
+			res = append(res, fmt.Sprintf("ALTER TYPE %s ADD VALUE %s BEFORE %s;", typeName, quoteString(label), quoteString(oldEnum.LabelList[0])))
+		default:
+			res = append(res, fmt.Sprintf("ALTER TYPE %s ADD VALUE %s;", typeName, quoteString(label)))
+		}
+	}
+	return res, nil
+}
+
+func (diff *diffNode) diffFunction(oldSchema, newSchema *schemaInfo) error {
+	for _, key := range oldSchema.objectMapByKind[functionKind].keyList {
+		if _, exists := newSchema.objectMapByKind[functionKind].nodeMap[key]; !exists {
+			dropFunction, err := dropFunctionStatement(oldSchema.objectMapByKind[functionKind].nodeMap[key].(*ast.CreateFunctionStmt))
+			if err != nil {
+				return err
+			}
+			diff.addDropStatement(functionKind, key, dropFunction)
+		}
+	}
+	for _, key := range newSchema.objectMapByKind[functionKind].keyList {
+		newFunction := newSchema.objectMapByKind[functionKind].nodeMap[key].(*ast.CreateFunctionStmt)
+		oldNode, exists := oldSchema.objectMapByKind[functionKind].nodeMap[key]
+		if !exists {
+			diff.addCreateStatement(functionKind, key, newFunction.Text())
+			continue
+		}
+		oldFunction := oldNode.(*ast.CreateFunctionStmt)
+		if isStatementEqual(oldFunction.Text(), newFunction.Text()) {
+			continue
+		}
+		oldReturnType, err := functionReturnType(oldFunction)
+		if err != nil {
+			return err
+		}
+		newReturnType, err := functionReturnType(newFunction)
+		if err != nil {
+			return err
+		}
+		if oldReturnType == newReturnType {
+			diff.addCreateStatement(functionKind, key, createOrReplace(newFunction.Text()))
+			continue
+		}
+		// CREATE OR REPLACE FUNCTION cannot change the return type, so we drop and recreate the function.
+		dropFunction, err := dropFunctionStatement(oldFunction)
+		if err != nil {
+			return err
+		}
+		diff.addDropStatement(functionKind, key, dropFunction)
+		diff.addCreateStatement(functionKind, key, newFunction.Text())
+	}
+	return nil
+}
+
+func (diff *diffNode) diffTrigger(oldSchema, newSchema *schemaInfo) {
+	for _, key := range oldSchema.objectMapByKind[triggerKind].keyList {
+		oldTrigger := oldSchema.objectMapByKind[triggerKind].nodeMap[key].(*ast.CreateTriggerStmt)
+		newTrigger, exists := newSchema.objectMapByKind[triggerKind].nodeMap[key]
+		// There is no CREATE OR REPLACE TRIGGER before PostgreSQL 14, so we drop and recreate the changed trigger.
+		if !exists || !isStatementEqual(oldTrigger.Text(), newTrigger.Text()) {
+			diff.addDropStatement(triggerKind, key, fmt.Sprintf("DROP TRIGGER %s ON %s;", quoteIdentifier(oldTrigger.Trigger.Name), quoteTableName(oldTrigger.Trigger.Table)))
+		}
+	}
+	for _, key := range newSchema.objectMapByKind[triggerKind].keyList {
+		newTrigger := newSchema.objectMapByKind[triggerKind].nodeMap[key]
+		oldTrigger, exists := oldSchema.objectMapByKind[triggerKind].nodeMap[key]
+		if !exists || !isStatementEqual(oldTrigger.Text(), newTrigger.Text()) {
+			diff.addCreateStatement(triggerKind, key, newTrigger.Text())
+		}
+	}
+}
+
+func (diff *diffNode) diffExtension(oldSchema, newSchema *schemaInfo) {
+	for _, key := range oldSchema.objectMapByKind[extensionKind].keyList {
+		if _, exists := newSchema.objectMapByKind[extensionKind].nodeMap[key]; !exists {
+			diff.addDropStatement(extensionKind, key, fmt.Sprintf("DROP EXTENSION %s;", quoteIdentifier(key)))
+		}
+	}
+	for _, key := range newSchema.objectMapByKind[extensionKind].keyList {
+		newExtension := newSchema.objectMapByKind[extensionKind].nodeMap[key].(*ast.CreateExtensionStmt)
+		oldNode, exists := oldSchema.objectMapByKind[extensionKind].nodeMap[key]
+		if !exists {
+			diff.addCreateStatement(extensionKind, key, newExtension.Text())
+			continue
+		}
+		oldExtension := oldNode.(*ast.CreateExtensionStmt)
+		// The empty schema or version means the default one, which we cannot compare with.
+		if newExtension.Schema != "" && oldExtension.Schema != "" && newExtension.Schema != oldExtension.Schema {
+			diff.addCreateStatement(extensionKind, key, fmt.Sprintf("ALTER EXTENSION %s SET SCHEMA %s;", quoteIdentifier(key), quoteIdentifier(newExtension.Schema)))
+		}
+		if newExtension.Version != "" && newExtension.Version != oldExtension.Version {
+			diff.addCreateStatement(extensionKind, key, fmt.Sprintf("ALTER EXTENSION %s UPDATE TO %s;", quoteIdentifier(key), quoteString(newExtension.Version)))
+		}
+	}
+}
+
+// NOTE: Due to limitation of current deparse implementation, we directly
+// generate the final DDLs here instead of returning []ast.Node to the caller.
+func (diff *diffNode) deparse(oldSchema, newSchema *schemaInfo) (string, error) {
+	var buf bytes.Buffer
+	if len(diff.dropStatementMap) > 0 {
+		oldObjectList, err := oldSchema.buildDependencyGraph().TopologicalSort()
+		if err != nil {
+			return "", errors.Wrap(err, "failed to sort the objects in the old schema")
+		}
+		for i := len(oldObjectList) - 1; i >= 0; i-- {
+			writeStatementList(&buf, diff.dropStatementMap[oldObjectList[i]])
+		}
+	}
+	if len(diff.createStatementMap) > 0 {
+		newObjectList, err := newSchema.buildDependencyGraph().TopologicalSort()
+		if err != nil {
+			return "", errors.Wrap(err, "failed to sort the objects in the new schema")
+		}
+		for _, id := range newObjectList {
+			writeStatementList(&buf, diff.createStatementMap[id])
+		}
+	}
+	return buf.String(), nil
+}
+
+func writeStatementList(buf *bytes.Buffer, statementList []string) {
+	for _, statement := range statementList {
+		_, _ = buf.WriteString(statement)
+		if !strings.HasSuffix(statement, ";") {
+			_, _ = buf.WriteString(";")
+		}
+		_, _ = buf.WriteString("\n")
+	}
+}
+
+// isStatementEqual compares two statements by their canonical forms.
+func isStatementEqual(oldStatement, newStatement string) bool {
+	return canonicalize(oldStatement) == canonicalize(newStatement)
+}
+
+// canonicalize returns the canonical form of the statement by deparsing it with pg_query.
+// The OR REPLACE option and the object schema are removed, so that the statements from pg_dump
+// and the statements written by users can be compared.
+func canonicalize(statement string) string {
+	res, err := pgquery.Parse(statement)
+	if err != nil {
+		return strings.Join(strings.Fields(statement), " ")
+	}
+	for _, stmt := range res.Stmts {
+		switch n := stmt.Stmt.Node.(type) {
+		case *pgquery.Node_ViewStmt:
+			n.ViewStmt.Replace = false
+			n.ViewStmt.View.Schemaname = ""
+		case *pgquery.Node_CreateFunctionStmt:
+			n.CreateFunctionStmt.Replace = false
+			if len(n.CreateFunctionStmt.Funcname) > 1 {
+				n.CreateFunctionStmt.Funcname = n.CreateFunctionStmt.Funcname[len(n.CreateFunctionStmt.Funcname)-1:]
+			}
+			// pg_dump and users may write the function options in different orders.
+			sort.SliceStable(n.CreateFunctionStmt.Options, func(i, j int) bool {
+				return n.CreateFunctionStmt.Options[i].GetDefElem().GetDefname() < n.CreateFunctionStmt.Options[j].GetDefElem().GetDefname()
+			})
+		case *pgquery.Node_CreateTrigStmt:
+			n.CreateTrigStmt.Relation.Schemaname = ""
+		case *pgquery.Node_IndexStmt:
+			n.IndexStmt.Relation.Schemaname = ""
+		}
+	}
+	canonical, err := pgquery.Deparse(res)
+	if err != nil {
+		return strings.Join(strings.Fields(statement), " ")
+	}
+	return canonical
+}
+
+// createOrReplace rewrites the CREATE statement to the CREATE OR REPLACE statement.
+func createOrReplace(statement string) string {
+	return createOrReplaceReg.ReplaceAllString(stripLeadingComment(statement), "CREATE OR REPLACE ")
+}
+
+// stripLeadingComment strips the comments before the statement, such as the object headers in pg_dump.
+func stripLeadingComment(statement string) string {
+	for {
+		statement = strings.TrimSpace(statement)
+		switch {
+		case strings.HasPrefix(statement, "--"):
+			index := strings.Index(statement, "\n")
+			if index < 0 {
+				return ""
+			}
+			statement = statement[index+1:]
+		case strings.HasPrefix(statement, "/*"):
+			index := strings.Index(statement, "*/")
+			if index < 0 {
+				return statement
+			}
+			statement = statement[index+2:]
+		default:
+			return statement
 		}
 	}
+}
 
-	var diffs []ast.Node
-	for _, node := range newNodes {
-		if newTable, ok := node.(*ast.CreateTableStmt); ok {
-			_, hasTable := oldTables[newTable.Name.Name]
-			if !hasTable {
-				diffs = append(diffs, node)
-				continue
+// functionSignature returns the function name with the argument types, which identifies a function in a schema.
+func functionSignature(function *ast.FunctionDef) (string, error) {
+	var typeList []string
+	for _, parameter := range function.ParameterList {
+		switch parameter.Mode {
+		case ast.FunctionParameterModeIn, ast.FunctionParameterModeInOut, ast.FunctionParameterModeVariadic:
+			tp, err := deparseDataType(parameter.Type)
+			if err != nil {
+				return "", err
 			}
+			typeList = append(typeList, tp)
 		}
 	}
+	return fmt.Sprintf("%s(%s)", quoteIdentifier(function.Name), strings.Join(typeList, ", ")), nil
+}
 
-	// NOTE: Due to limitation of current deparse implementation, we directly
-	// generate the final DDLs here instead of returning []ast.Node to the caller.
-	deparse := func(nodes []ast.Node) string {
-		var buf bytes.Buffer
-		for _, node := range nodes {
-			if n, ok := node.(*ast.CreateTableStmt); ok {
-				_, _ = buf.WriteString(n.Text())
-				_, _ = buf.WriteString("\n")
+// functionReturnType returns the return type with the output parameter types.
+func functionReturnType(function *ast.CreateFunctionStmt) (string, error) {
+	var typeList []string
+	if function.ReturnType != nil {
+		tp, err := deparseDataType(function.ReturnType)
+		if err != nil {
+			return "", err
+		}
+		typeList = append(typeList, tp)
+	}
+	for _, parameter := range function.Function.ParameterList {
+		switch parameter.Mode {
+		case ast.FunctionParameterModeOut, ast.FunctionParameterModeInOut, ast.FunctionParameterModeTable:
+			tp, err := deparseDataType(parameter.Type)
+			if err != nil {
+				return "", err
 			}
+			typeList = append(typeList, tp)
+		}
+	}
+	return strings.Join(typeList, ", "), nil
+}
+
+func dropFunctionStatement(function *ast.CreateFunctionStmt) (string, error) {
+	signature, err := functionSignature(function.Function)
+	if err != nil {
+		return "", err
+	}
+	if function.Function.Schema != "" {
+		signature = fmt.Sprintf("%s.%s", quoteIdentifier(function.Function.Schema), signature)
+	}
+	if function.IsProcedure {
+		return fmt.Sprintf("DROP PROCEDURE %s;", signature), nil
+	}
+	return fmt.Sprintf("DROP FUNCTION %s;", signature), nil
+}
+
+func deparseDataType(dataType ast.DataType) (string, error) {
+	return parser.Deparse(parser.Postgres, parser.DeparseContext{}, dataType)
+}
+
+func sequenceDataType(sequence *ast.SequenceDef) ast.DataType {
+	if sequence.SequenceDataType == nil {
+		return &ast.Integer{Size: 8}
+	}
+	return sequence.SequenceDataType
+}
+
+func sequenceIncrement(sequence *ast.SequenceDef) int64 {
+	if sequence.IncrementBy == nil {
+		return 1
+	}
+	return *sequence.IncrementBy
+}
+
+// sequenceMinValue returns nil for the default min value.
+func sequenceMinValue(sequence *ast.SequenceDef) *int64 {
+	if sequence.NoMinValue {
+		return nil
+	}
+	return sequence.MinValue
+}
+
+// sequenceMaxValue returns nil for the default max value.
+func sequenceMaxValue(sequence *ast.SequenceDef) *int64 {
+	if sequence.NoMaxValue {
+		return nil
+	}
+	return sequence.MaxValue
+}
+
+func sequenceStart(sequence *ast.SequenceDef) int64 {
+	if sequence.StartWith != nil {
+		return *sequence.StartWith
+	}
+	// The default start value is the min value for ascending sequences and the max value for descending ones.
+	if sequenceIncrement(sequence) > 0 {
+		if minValue := sequenceMinValue(sequence); minValue != nil {
+			return *minValue
 		}
-		return buf.String()
+		return 1
+	}
+	if maxValue := sequenceMaxValue(sequence); maxValue != nil {
+		return *maxValue
+	}
+	return -1
+}
+
+func sequenceCache(sequence *ast.SequenceDef) int64 {
+	if sequence.Cache == nil {
+		return 1
+	}
+	return *sequence.Cache
+}
+
+func isInt64PointerEqual(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
 	}
+	return *a == *b
+}
+
+func objectKey(schema string, name string) string {
+	if schema == "" {
+		schema = publicSchemaName
+	}
+	return fmt.Sprintf("%s.%s", schema, name)
+}
+
+func tableKey(table *ast.TableDef) string {
+	return objectKey(table.Schema, table.Name)
+}
+
+func triggerKey(trigger *ast.TriggerDef) string {
+	return fmt.Sprintf("%s.%s", tableKey(trigger.Table), trigger.Name)
+}
+
+func quoteIdentifier(s string) string {
+	return fmt.Sprintf(`"%s"`, strings.ReplaceAll(s, `"`, `""`))
+}
+
+func quoteName(schema string, name string) string {
+	if schema == "" {
+		return quoteIdentifier(name)
+	}
+	return fmt.Sprintf("%s.%s", quoteIdentifier(schema), quoteIdentifier(name))
+}
+
+func quoteTableName(table *ast.TableDef) string {
+	return quoteName(table.Schema, table.Name)
+}
 
-	return deparse(diffs), nil
+func quoteString(s string) string {
+	return fmt.Sprintf("'%s'", strings.ReplaceAll(s, "'", "''"))
 }
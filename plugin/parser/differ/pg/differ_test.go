@@ -38,16 +38,355 @@ CREATE TABLE repositories (
 `,
 			errPart: "",
 		},
+		{
+			name: "diffSchemaQualifiedTable",
+			oldSchema: `CREATE TABLE public.projects (
+    id integer NOT NULL
+);`,
+			newSchema: `CREATE TABLE projects (id int NOT NULL);`,
+			want:      "",
+		},
+		{
+			name:      "createView",
+			oldSchema: `CREATE TABLE t (a int);`,
+			newSchema: `CREATE TABLE t (a int);
+CREATE VIEW v AS SELECT a FROM t;`,
+			want: "CREATE VIEW v AS SELECT a FROM t;\n",
+		},
+		{
+			name: "unchangedView",
+			oldSchema: `CREATE TABLE public.t (a integer);
+--
+-- Name: v; Type: VIEW; Schema: public; Owner: bytebase
+--
+
+CREATE VIEW public.v AS
+ SELECT a
+   FROM t;`,
+			newSchema: `CREATE TABLE t (a int);
+CREATE VIEW v AS SELECT a FROM t;`,
+			want: "",
+		},
+		{
+			name: "changeView",
+			oldSchema: `-- A comment.
+CREATE VIEW public.v AS
+ SELECT a
+   FROM t;`,
+			newSchema: `CREATE VIEW v AS SELECT a, b FROM t;`,
+			want:      "CREATE OR REPLACE VIEW v AS SELECT a, b FROM t;\n",
+		},
+		{
+			name:      "dropView",
+			oldSchema: `CREATE VIEW public.v AS SELECT a FROM t;`,
+			newSchema: ``,
+			want:      "DROP VIEW \"public\".\"v\";\n",
+		},
+		{
+			name:      "createSequence",
+			oldSchema: ``,
+			newSchema: `CREATE SEQUENCE s INCREMENT BY 2;`,
+			want:      "CREATE SEQUENCE s INCREMENT BY 2;\n",
+		},
+		{
+			name: "unchangedSequence",
+			oldSchema: `CREATE SEQUENCE public.s
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;`,
+			newSchema: `CREATE SEQUENCE s;`,
+			want:      "",
+		},
+		{
+			name:      "changeSequence",
+			oldSchema: `CREATE SEQUENCE public.s AS integer START WITH 1 INCREMENT BY 1 NO MINVALUE NO MAXVALUE CACHE 1;`,
+			newSchema: `CREATE SEQUENCE s AS bigint INCREMENT BY 2 MINVALUE 10 CACHE 5 CYCLE;`,
+			want:      "ALTER SEQUENCE \"s\" AS INT8 INCREMENT BY 2 MINVALUE 10 START WITH 10 CACHE 5 CYCLE;\n",
+		},
+		{
+			name: "dropSequence",
+			oldSchema: `CREATE TABLE public.t (id integer NOT NULL);
+CREATE SEQUENCE public.t_id_seq;
+CREATE SEQUENCE public.s;`,
+			newSchema: `CREATE TABLE t (id serial NOT NULL);`,
+			want:      "DROP SEQUENCE \"public\".\"s\";\n",
+		},
+		{
+			name:      "createEnum",
+			oldSchema: ``,
+			newSchema: `CREATE TYPE mood AS ENUM ('sad', 'ok');`,
+			want:      "CREATE TYPE mood AS ENUM ('sad', 'ok');\n",
+		},
+		{
+			name:      "addEnumValue",
+			oldSchema: `CREATE TYPE public.mood AS ENUM ('sad', 'ok');`,
+			newSchema: `CREATE TYPE mood AS ENUM ('angry', 'sad', 'ok', 'happy', 'very happy');`,
+			want: `ALTER TYPE "mood" ADD VALUE 'angry' BEFORE 'sad';
+ALTER TYPE "mood" ADD VALUE 'happy' AFTER 'ok';
+ALTER TYPE "mood" ADD VALUE 'very happy' AFTER 'happy';
+`,
+		},
+		{
+			name:      "dropEnumValue",
+			oldSchema: `CREATE TYPE public.mood AS ENUM ('sad', 'ok');`,
+			newSchema: `CREATE TYPE mood AS ENUM ('sad');`,
+			errPart:   `cannot drop the value "ok" of enum type "mood"`,
+		},
+		{
+			name:      "reorderEnumValue",
+			oldSchema: `CREATE TYPE public.mood AS ENUM ('sad', 'ok');`,
+			newSchema: `CREATE TYPE mood AS ENUM ('ok', 'sad');`,
+			errPart:   `cannot reorder the value`,
+		},
+		{
+			name:      "dropType",
+			oldSchema: `CREATE TYPE public.mood AS ENUM ('sad', 'ok');`,
+			newSchema: ``,
+			want:      "DROP TYPE \"public\".\"mood\";\n",
+		},
+		{
+			name:      "createFunction",
+			oldSchema: ``,
+			newSchema: `CREATE FUNCTION add(a int, b int) RETURNS int AS $$ SELECT a + b $$ LANGUAGE SQL;`,
+			want:      "CREATE FUNCTION add(a int, b int) RETURNS int AS $$ SELECT a + b $$ LANGUAGE SQL;\n",
+		},
+		{
+			name: "unchangedFunction",
+			oldSchema: `CREATE FUNCTION public.add(a integer, b integer) RETURNS integer
+    LANGUAGE sql
+    AS $$ SELECT a + b $$;`,
+			newSchema: `CREATE FUNCTION add(a int, b int) RETURNS int AS $$ SELECT a + b $$ LANGUAGE SQL;`,
+			want:      "",
+		},
+		{
+			name:      "changeFunction",
+			oldSchema: `CREATE FUNCTION public.add(a integer, b integer) RETURNS integer LANGUAGE sql AS $$ SELECT a + b $$;`,
+			newSchema: `CREATE FUNCTION add(a int, b int) RETURNS int AS $$ SELECT b + a $$ LANGUAGE SQL;`,
+			want:      "CREATE OR REPLACE FUNCTION add(a int, b int) RETURNS int AS $$ SELECT b + a $$ LANGUAGE SQL;\n",
+		},
+		{
+			name:      "changeFunctionReturnType",
+			oldSchema: `CREATE FUNCTION public.add(a integer, b integer) RETURNS integer LANGUAGE sql AS $$ SELECT a + b $$;`,
+			newSchema: `CREATE FUNCTION add(a int, b int) RETURNS bigint AS $$ SELECT a + b $$ LANGUAGE SQL;`,
+			want: `DROP FUNCTION "public"."add"(INT4, INT4);
+CREATE FUNCTION add(a int, b int) RETURNS bigint AS $$ SELECT a + b $$ LANGUAGE SQL;
+`,
+		},
+		{
+			name:      "overloadFunction",
+			oldSchema: `CREATE FUNCTION public.add(a integer, b integer) RETURNS integer LANGUAGE sql AS $$ SELECT a + b $$;`,
+			newSchema: `CREATE FUNCTION add(a int, b int) RETURNS int AS $$ SELECT a + b $$ LANGUAGE SQL;
+CREATE FUNCTION add(a bigint) RETURNS bigint AS $$ SELECT a $$ LANGUAGE SQL;`,
+			want: "CREATE FUNCTION add(a bigint) RETURNS bigint AS $$ SELECT a $$ LANGUAGE SQL;\n",
+		},
+		{
+			name:      "dropProcedure",
+			oldSchema: `CREATE PROCEDURE public.p(IN a integer, OUT b integer) LANGUAGE sql AS $$ SELECT a $$;`,
+			newSchema: ``,
+			want:      "DROP PROCEDURE \"public\".\"p\"(INT4);\n",
+		},
+		{
+			name:      "createTrigger",
+			oldSchema: `CREATE TABLE public.t (a integer);`,
+			newSchema: `CREATE TABLE t (a int);
+CREATE TRIGGER tr BEFORE INSERT ON t FOR EACH ROW EXECUTE FUNCTION f();`,
+			want: "CREATE TRIGGER tr BEFORE INSERT ON t FOR EACH ROW EXECUTE FUNCTION f();\n",
+		},
+		{
+			name:      "unchangedTrigger",
+			oldSchema: `CREATE TRIGGER tr BEFORE INSERT ON public.t FOR EACH ROW EXECUTE FUNCTION public.f();`,
+			newSchema: `CREATE TRIGGER tr BEFORE INSERT ON t FOR EACH ROW EXECUTE FUNCTION public.f();`,
+			want:      "",
+		},
+		{
+			name:      "changeTrigger",
+			oldSchema: `CREATE TRIGGER tr BEFORE INSERT ON public.t FOR EACH ROW EXECUTE FUNCTION public.f();`,
+			newSchema: `CREATE TRIGGER tr AFTER INSERT ON t FOR EACH ROW EXECUTE FUNCTION public.f();`,
+			want: `DROP TRIGGER "tr" ON "public"."t";
+CREATE TRIGGER tr AFTER INSERT ON t FOR EACH ROW EXECUTE FUNCTION public.f();
+`,
+		},
+		{
+			name:      "createExtension",
+			oldSchema: ``,
+			newSchema: `CREATE EXTENSION IF NOT EXISTS hstore WITH SCHEMA public;`,
+			want:      "CREATE EXTENSION IF NOT EXISTS hstore WITH SCHEMA public;\n",
+		},
+		{
+			name:      "changeExtension",
+			oldSchema: `CREATE EXTENSION IF NOT EXISTS hstore WITH SCHEMA public;`,
+			newSchema: `CREATE EXTENSION hstore WITH SCHEMA ext VERSION '1.8';`,
+			want: `ALTER EXTENSION "hstore" SET SCHEMA "ext";
+ALTER EXTENSION "hstore" UPDATE TO '1.8';
+`,
+		},
+		{
+			name:      "dropExtension",
+			oldSchema: `CREATE EXTENSION IF NOT EXISTS hstore WITH SCHEMA public;`,
+			newSchema: ``,
+			want:      "DROP EXTENSION \"hstore\";\n",
+		},
+	}
+
+	pgDiffer := &SchemaDiffer{}
+	for _, test := range tests {
+		diff, err := pgDiffer.SchemaDiff(test.oldSchema, test.newSchema)
+		if test.errPart == "" {
+			require.NoError(t, err, test.name)
+		} else {
+			require.Error(t, err, test.name)
+			require.Contains(t, err.Error(), test.errPart, test.name)
+		}
+		require.Equal(t, test.want, diff, test.name)
+	}
+}
+
+func TestComputeDiffOrder(t *testing.T) {
+	oldSchema := `CREATE VIEW public.v AS SELECT 1;
+CREATE TYPE public.mood AS ENUM ('sad');
+CREATE EXTENSION hstore;`
+	newSchema := `CREATE TRIGGER tr BEFORE INSERT ON t FOR EACH ROW EXECUTE FUNCTION f();
+CREATE VIEW w AS SELECT 1;
+CREATE TABLE t (a int);
+CREATE FUNCTION f() RETURNS trigger AS $$ BEGIN RETURN NEW; END $$ LANGUAGE plpgsql;
+CREATE SEQUENCE s;
+CREATE TYPE mood AS ENUM ('sad', 'happy');
+CREATE EXTENSION citext;`
+	want := `DROP VIEW "public"."v";
+DROP EXTENSION "hstore";
+CREATE EXTENSION citext;
+ALTER TYPE "mood" ADD VALUE 'happy' AFTER 'sad';
+CREATE SEQUENCE s;
+CREATE FUNCTION f() RETURNS trigger AS $$ BEGIN RETURN NEW; END $$ LANGUAGE plpgsql;
+CREATE TABLE t (a int);
+CREATE VIEW w AS SELECT 1;
+CREATE TRIGGER tr BEFORE INSERT ON t FOR EACH ROW EXECUTE FUNCTION f();
+`
+	pgDiffer := &SchemaDiffer{}
+	diff, err := pgDiffer.SchemaDiff(oldSchema, newSchema)
+	require.NoError(t, err)
+	require.Equal(t, want, diff)
+
+	// Applying the diff to the new schema should produce no more changes.
+	diff, err = pgDiffer.SchemaDiff(newSchema, newSchema)
+	require.NoError(t, err)
+	require.Equal(t, "", diff)
+}
+
+func TestComputeDiffDependency(t *testing.T) {
+	tests := []struct {
+		name      string
+		oldSchema string
+		newSchema string
+		want      string
+		errPart   string
+	}{
+		{
+			name:      "createReferencedTableFirst",
+			oldSchema: ``,
+			newSchema: `CREATE TABLE orders (id int PRIMARY KEY, user_id int REFERENCES users (id));
+CREATE TABLE users (id int PRIMARY KEY);`,
+			want: `CREATE TABLE users (id int PRIMARY KEY);
+CREATE TABLE orders (id int PRIMARY KEY, user_id int REFERENCES users (id));
+`,
+		},
+		{
+			name:      "createTableConstraintReferencedTableFirst",
+			oldSchema: `CREATE TABLE public.users (id integer NOT NULL);`,
+			newSchema: `CREATE TABLE items (id int, order_id int, CONSTRAINT fk_order FOREIGN KEY (order_id) REFERENCES orders (id));
+CREATE TABLE orders (id int PRIMARY KEY, user_id int REFERENCES users (id));
+CREATE TABLE users (id int);`,
+			want: `CREATE TABLE orders (id int PRIMARY KEY, user_id int REFERENCES users (id));
+CREATE TABLE items (id int, order_id int, CONSTRAINT fk_order FOREIGN KEY (order_id) REFERENCES orders (id));
+`,
+		},
+		{
+			name:      "createViewAfterRelations",
+			oldSchema: ``,
+			newSchema: `CREATE VIEW b AS SELECT a.id FROM a JOIN t ON a.id = t.id;
+CREATE VIEW a AS SELECT id FROM (SELECT id FROM t) AS sub;
+CREATE TABLE t (id int);`,
+			want: `CREATE TABLE t (id int);
+CREATE VIEW a AS SELECT id FROM (SELECT id FROM t) AS sub;
+CREATE VIEW b AS SELECT a.id FROM a JOIN t ON a.id = t.id;
+`,
+		},
+		{
+			name: "dropDependentViewFirst",
+			oldSchema: `CREATE VIEW public.b AS SELECT a.id FROM public.a;
+CREATE VIEW public.a AS SELECT 1 AS id;`,
+			newSchema: ``,
+			want: `DROP VIEW "public"."b";
+DROP VIEW "public"."a";
+`,
+		},
+		{
+			name:      "createIndexAfterTable",
+			oldSchema: `CREATE TABLE public.t (a integer);`,
+			newSchema: `CREATE INDEX idx_u ON u (a);
+CREATE INDEX idx_t ON t (a);
+CREATE TABLE t (a int);
+CREATE TABLE u (a int);`,
+			want: `CREATE TABLE u (a int);
+CREATE INDEX idx_u ON u (a);
+CREATE INDEX idx_t ON t (a);
+`,
+		},
+		{
+			name: "unchangedIndex",
+			oldSchema: `CREATE TABLE public.t (a integer);
+CREATE INDEX idx_t ON public.t USING btree (a);`,
+			newSchema: `CREATE TABLE t (a int);
+CREATE INDEX idx_t ON t (a);`,
+			want: "",
+		},
+		{
+			name: "changeAndDropIndex",
+			oldSchema: `CREATE TABLE public.t (a integer, b integer);
+CREATE INDEX idx_a ON public.t USING btree (a);
+CREATE INDEX idx_b ON public.t USING btree (b);`,
+			newSchema: `CREATE TABLE t (a int, b int);
+CREATE UNIQUE INDEX idx_a ON t (a);`,
+			want: `DROP INDEX "public"."idx_b";
+DROP INDEX "public"."idx_a";
+CREATE UNIQUE INDEX idx_a ON t (a);
+`,
+		},
+		{
+			name:      "createOwnedSequenceAfterTable",
+			oldSchema: ``,
+			newSchema: `CREATE SEQUENCE s OWNED BY t.id;
+CREATE TABLE t (id bigint);`,
+			want: `CREATE TABLE t (id bigint);
+CREATE SEQUENCE s OWNED BY t.id;
+`,
+		},
+		{
+			name:      "foreignKeyCycle",
+			oldSchema: ``,
+			newSchema: `CREATE TABLE a (id int PRIMARY KEY, b_id int REFERENCES b (id));
+CREATE TABLE b (id int PRIMARY KEY, a_id int REFERENCES a (id));`,
+			errPart: `found dependency cycle: table public.a -> table public.b -> table public.a`,
+		},
+		{
+			name:      "selfReference",
+			oldSchema: ``,
+			newSchema: `CREATE TABLE tree (id int PRIMARY KEY, parent_id int REFERENCES tree (id));`,
+			want:      "CREATE TABLE tree (id int PRIMARY KEY, parent_id int REFERENCES tree (id));\n",
+		},
 	}
 
 	pgDiffer := &SchemaDiffer{}
 	for _, test := range tests {
 		diff, err := pgDiffer.SchemaDiff(test.oldSchema, test.newSchema)
 		if test.errPart == "" {
-			require.NoError(t, err)
+			require.NoError(t, err, test.name)
 		} else {
+			require.Error(t, err, test.name)
 			require.Contains(t, err.Error(), test.errPart, test.name)
 		}
-		require.Equal(t, test.want, diff)
+		require.Equal(t, test.want, diff, test.name)
 	}
 }
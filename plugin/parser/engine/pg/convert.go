@@ -30,6 +30,7 @@ func convert(node *pgquery.Node, statement parser.SingleSQL) (res ast.Node, err
 	switch in := node.Node.(type) {
 	case *pgquery.Node_AlterTableStmt:
 		alterTable := &ast.AlterTableStmt{
+			IfExists:      in.AlterTableStmt.MissingOk,
 			Table:         convertRangeVarToTableName(in.AlterTableStmt.Relation, ast.TableTypeBaseTable),
 			AlterItemList: []ast.Node{},
 		}
@@ -50,14 +51,16 @@ func convert(node *pgquery.Node, statement parser.SingleSQL) (res ast.Node, err
 					}
 
 					addColumn := &ast.AddColumnListStmt{
-						Table:      alterTable.Table,
-						ColumnList: []*ast.ColumnDef{column},
+						Table:       alterTable.Table,
+						IfNotExists: alterCmd.MissingOk,
+						ColumnList:  []*ast.ColumnDef{column},
 					}
 
 					alterTable.AlterItemList = append(alterTable.AlterItemList, addColumn)
 				case pgquery.AlterTableType_AT_DropColumn:
 					dropColumn := &ast.DropColumnStmt{
 						Table:      alterTable.Table,
+						IfExists:   alterCmd.MissingOk,
 						ColumnName: alterCmd.Name,
 					}
 
@@ -81,6 +84,7 @@ func convert(node *pgquery.Node, statement parser.SingleSQL) (res ast.Node, err
 				case pgquery.AlterTableType_AT_DropConstraint:
 					dropConstraint := &ast.DropConstraintStmt{
 						Table:          alterTable.Table,
+						IfExists:       alterCmd.MissingOk,
 						ConstraintName: alterCmd.Name,
 					}
 
@@ -147,7 +151,8 @@ func convert(node *pgquery.Node, statement parser.SingleSQL) (res ast.Node, err
 			}
 			table := convertRangeVarToTableName(in.RenameStmt.Relation, tableType)
 			return &ast.AlterTableStmt{
-				Table: table,
+				IfExists: in.RenameStmt.MissingOk,
+				Table:    table,
 				AlterItemList: []ast.Node{
 					&ast.RenameColumnStmt{
 						Table:      table,
@@ -159,7 +164,8 @@ func convert(node *pgquery.Node, statement parser.SingleSQL) (res ast.Node, err
 		case pgquery.ObjectType_OBJECT_TABLE:
 			table := convertRangeVarToTableName(in.RenameStmt.Relation, ast.TableTypeBaseTable)
 			return &ast.AlterTableStmt{
-				Table: table,
+				IfExists: in.RenameStmt.MissingOk,
+				Table:    table,
 				AlterItemList: []ast.Node{
 					&ast.RenameTableStmt{
 						Table:   table,
@@ -170,7 +176,8 @@ func convert(node *pgquery.Node, statement parser.SingleSQL) (res ast.Node, err
 		case pgquery.ObjectType_OBJECT_TABCONSTRAINT:
 			table := convertRangeVarToTableName(in.RenameStmt.Relation, ast.TableTypeBaseTable)
 			return &ast.AlterTableStmt{
-				Table: table,
+				IfExists: in.RenameStmt.MissingOk,
+				Table:    table,
 				AlterItemList: []ast.Node{
 					&ast.RenameConstraintStmt{
 						Table:          table,
@@ -182,7 +189,8 @@ func convert(node *pgquery.Node, statement parser.SingleSQL) (res ast.Node, err
 		case pgquery.ObjectType_OBJECT_VIEW:
 			view := convertRangeVarToTableName(in.RenameStmt.Relation, ast.TableTypeView)
 			return &ast.AlterTableStmt{
-				Table: view,
+				IfExists: in.RenameStmt.MissingOk,
+				Table:    view,
 				AlterItemList: []ast.Node{
 					&ast.RenameTableStmt{
 						Table:   view,
@@ -223,11 +231,11 @@ func convert(node *pgquery.Node, statement parser.SingleSQL) (res ast.Node, err
 			}
 		}
 
-		return &ast.CreateIndexStmt{Index: indexDef}, nil
+		return &ast.CreateIndexStmt{IfNotExists: in.IndexStmt.IfNotExists, Index: indexDef}, nil
 	case *pgquery.Node_DropStmt:
 		switch in.DropStmt.RemoveType {
 		case pgquery.ObjectType_OBJECT_INDEX:
-			dropIndex := &ast.DropIndexStmt{}
+			dropIndex := &ast.DropIndexStmt{IfExists: in.DropStmt.MissingOk}
 			for _, object := range in.DropStmt.Objects {
 				list, ok := object.Node.(*pgquery.Node_List)
 				if !ok {
@@ -241,7 +249,7 @@ func convert(node *pgquery.Node, statement parser.SingleSQL) (res ast.Node, err
 			}
 			return dropIndex, nil
 		case pgquery.ObjectType_OBJECT_TABLE:
-			dropTable := &ast.DropTableStmt{}
+			dropTable := &ast.DropTableStmt{IfExists: in.DropStmt.MissingOk}
 			for _, object := range in.DropStmt.Objects {
 				list, ok := object.Node.(*pgquery.Node_List)
 				if !ok {
@@ -255,7 +263,7 @@ func convert(node *pgquery.Node, statement parser.SingleSQL) (res ast.Node, err
 			}
 			return dropTable, nil
 		case pgquery.ObjectType_OBJECT_VIEW:
-			dropView := &ast.DropTableStmt{}
+			dropView := &ast.DropTableStmt{IfExists: in.DropStmt.MissingOk}
 			for _, object := range in.DropStmt.Objects {
 				list, ok := object.Node.(*pgquery.Node_List)
 				if !ok {
@@ -318,7 +326,8 @@ func convert(node *pgquery.Node, statement parser.SingleSQL) (res ast.Node, err
 		case pgquery.ObjectType_OBJECT_TABLE:
 			table := convertRangeVarToTableName(in.AlterObjectSchemaStmt.Relation, ast.TableTypeBaseTable)
 			return &ast.AlterTableStmt{
-				Table: table,
+				IfExists: in.AlterObjectSchemaStmt.MissingOk,
+				Table:    table,
 				AlterItemList: []ast.Node{
 					&ast.SetSchemaStmt{
 						Table:     table,
@@ -329,7 +338,8 @@ func convert(node *pgquery.Node, statement parser.SingleSQL) (res ast.Node, err
 		case pgquery.ObjectType_OBJECT_VIEW:
 			view := convertRangeVarToTableName(in.AlterObjectSchemaStmt.Relation, ast.TableTypeView)
 			return &ast.AlterTableStmt{
-				Table: view,
+				IfExists: in.AlterObjectSchemaStmt.MissingOk,
+				Table:    view,
 				AlterItemList: []ast.Node{
 					&ast.SetSchemaStmt{
 						Table:     view,
@@ -391,8 +401,38 @@ func convert(node *pgquery.Node, statement parser.SingleSQL) (res ast.Node, err
 		commentStmt := ast.CommentStmt{
 			Comment: in.CommentStmt.Comment,
 		}
+		switch in.CommentStmt.Objtype {
+		case pgquery.ObjectType_OBJECT_TABLE:
+			list, ok := in.CommentStmt.Object.Node.(*pgquery.Node_List)
+			if !ok {
+				return nil, parser.NewConvertErrorf("expected List but found %t", in.CommentStmt.Object.Node)
+			}
+			table, err := convertListToTableDef(list, ast.TableTypeBaseTable)
+			if err != nil {
+				return nil, err
+			}
+			commentStmt.Type = ast.ObjectTypeTable
+			commentStmt.Object = table
+		case pgquery.ObjectType_OBJECT_COLUMN:
+			list, ok := in.CommentStmt.Object.Node.(*pgquery.Node_List)
+			if !ok {
+				return nil, parser.NewConvertErrorf("expected List but found %t", in.CommentStmt.Object.Node)
+			}
+			column, err := convertListToColumnNameDef(list)
+			if err != nil {
+				return nil, err
+			}
+			commentStmt.Type = ast.ObjectTypeColumn
+			commentStmt.Object = column
+		}
 
 		return &commentStmt, nil
+	case *pgquery.Node_TransactionStmt:
+		switch in.TransactionStmt.Kind {
+		case pgquery.TransactionStmtKind_TRANS_STMT_COMMIT, pgquery.TransactionStmtKind_TRANS_STMT_COMMIT_PREPARED:
+			return &ast.CommitStmt{}, nil
+		}
+		return &ast.UnconvertedStmt{}, nil
 	case *pgquery.Node_CreatedbStmt:
 		createDatabaseStmt := ast.CreateDatabaseStmt{
 			Name: in.CreatedbStmt.Dbname,
@@ -411,6 +451,164 @@ func convert(node *pgquery.Node, statement parser.SingleSQL) (res ast.Node, err
 		}
 
 		return &createDatabaseStmt, nil
+	case *pgquery.Node_ViewStmt:
+		columnList, err := convertNodeListToStringList(in.ViewStmt.Aliases)
+		if err != nil {
+			return nil, err
+		}
+		definition, err := deparseStatementNode(in.ViewStmt.Query)
+		if err != nil {
+			return nil, err
+		}
+		createView := &ast.CreateViewStmt{
+			Replace:    in.ViewStmt.Replace,
+			Name:       convertRangeVarToTableName(in.ViewStmt.View, ast.TableTypeView),
+			ColumnList: columnList,
+			Definition: definition,
+		}
+		switch in.ViewStmt.WithCheckOption {
+		case pgquery.ViewCheckOption_LOCAL_CHECK_OPTION:
+			createView.CheckOption = ast.ViewCheckOptionLocal
+		case pgquery.ViewCheckOption_CASCADED_CHECK_OPTION:
+			createView.CheckOption = ast.ViewCheckOptionCascaded
+		}
+		return createView, nil
+	case *pgquery.Node_CreateSeqStmt:
+		sequenceDef, err := convertSequenceDef(in.CreateSeqStmt.Sequence, in.CreateSeqStmt.Options)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.CreateSequenceStmt{
+			IfNotExists: in.CreateSeqStmt.IfNotExists,
+			SequenceDef: *sequenceDef,
+		}, nil
+	case *pgquery.Node_AlterSeqStmt:
+		sequenceDef, err := convertSequenceDef(in.AlterSeqStmt.Sequence, in.AlterSeqStmt.Options)
+		if err != nil {
+			return nil, err
+		}
+		alterSequence := &ast.AlterSequenceStmt{
+			IfExists:    in.AlterSeqStmt.MissingOk,
+			SequenceDef: *sequenceDef,
+		}
+		for _, option := range in.AlterSeqStmt.Options {
+			item, ok := option.Node.(*pgquery.Node_DefElem)
+			if !ok {
+				continue
+			}
+			switch item.DefElem.Defname {
+			case "cycle":
+				alterSequence.NoCycle = !sequenceDef.Cycle
+			case "restart":
+				alterSequence.Restart = true
+				if item.DefElem.Arg != nil {
+					value, err := convertToInt64(item.DefElem.Arg)
+					if err != nil {
+						return nil, err
+					}
+					alterSequence.RestartWith = &value
+				}
+			}
+		}
+		return alterSequence, nil
+	case *pgquery.Node_CreateEnumStmt:
+		nameList, err := convertNodeListToStringList(in.CreateEnumStmt.TypeName)
+		if err != nil {
+			return nil, err
+		}
+		typeName, err := convertStringListToTypeNameDef(nameList)
+		if err != nil {
+			return nil, err
+		}
+		labelList, err := convertNodeListToStringList(in.CreateEnumStmt.Vals)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.CreateTypeStmt{
+			Type: &ast.EnumTypeDef{
+				Name:      typeName,
+				LabelList: labelList,
+			},
+		}, nil
+	case *pgquery.Node_CreateFunctionStmt:
+		function, err := convertToFunctionDef(in.CreateFunctionStmt.Funcname, in.CreateFunctionStmt.Parameters)
+		if err != nil {
+			return nil, err
+		}
+		createFunction := &ast.CreateFunctionStmt{
+			Replace:     in.CreateFunctionStmt.Replace,
+			IsProcedure: in.CreateFunctionStmt.IsProcedure,
+			Function:    function,
+		}
+		if in.CreateFunctionStmt.ReturnType != nil {
+			createFunction.ReturnType = convertDataType(in.CreateFunctionStmt.ReturnType)
+			createFunction.ReturnSetOf = in.CreateFunctionStmt.ReturnType.Setof
+		}
+		if err := convertFunctionOptionList(createFunction, in.CreateFunctionStmt.Options); err != nil {
+			return nil, err
+		}
+		return createFunction, nil
+	case *pgquery.Node_CreateTrigStmt:
+		function, err := convertToFunctionDef(in.CreateTrigStmt.Funcname, nil /* parameters */)
+		if err != nil {
+			return nil, err
+		}
+		trigger, err := convertTriggerDef(in.CreateTrigStmt, function)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.CreateTriggerStmt{
+			Trigger: trigger,
+		}, nil
+	case *pgquery.Node_CreateExtensionStmt:
+		createExtension := &ast.CreateExtensionStmt{
+			IfNotExists: in.CreateExtensionStmt.IfNotExists,
+			Name:        in.CreateExtensionStmt.Extname,
+		}
+		for _, option := range in.CreateExtensionStmt.Options {
+			item, ok := option.Node.(*pgquery.Node_DefElem)
+			if !ok {
+				continue
+			}
+			value, ok := item.DefElem.Arg.Node.(*pgquery.Node_String_)
+			if !ok {
+				continue
+			}
+			switch item.DefElem.Defname {
+			case "schema":
+				createExtension.Schema = value.String_.Str
+			case "new_version":
+				createExtension.Version = value.String_.Str
+			}
+		}
+		return createExtension, nil
+	case *pgquery.Node_GrantStmt:
+		return convertGrantStmt(in.GrantStmt)
+	case *pgquery.Node_TruncateStmt:
+		truncate := &ast.TruncateStmt{
+			RestartIdentity: in.TruncateStmt.RestartSeqs,
+			Cascade:         in.TruncateStmt.Behavior == pgquery.DropBehavior_DROP_CASCADE,
+		}
+		for _, relation := range in.TruncateStmt.Relations {
+			table, ok := relation.Node.(*pgquery.Node_RangeVar)
+			if !ok {
+				return nil, parser.NewConvertErrorf("expected RangeVar but found %t", relation.Node)
+			}
+			truncate.TableList = append(truncate.TableList, convertRangeVarToTableName(table.RangeVar, ast.TableTypeBaseTable))
+			truncate.OnlyList = append(truncate.OnlyList, !table.RangeVar.Inh)
+		}
+		return truncate, nil
+	case *pgquery.Node_CreateSchemaStmt:
+		createSchema := &ast.CreateSchemaStmt{
+			IfNotExists: in.CreateSchemaStmt.IfNotExists,
+			Name:        in.CreateSchemaStmt.Schemaname,
+		}
+		if in.CreateSchemaStmt.Authrole != nil {
+			createSchema.Owner = convertRoleSpec(in.CreateSchemaStmt.Authrole)
+		}
+		return createSchema, nil
+	case *pgquery.Node_VariableSetStmt:
+		return convertVariableSetStmt(in.VariableSetStmt), nil
 	default:
 		return &ast.UnconvertedStmt{}, nil
 	}
@@ -418,6 +616,300 @@ func convert(node *pgquery.Node, statement parser.SingleSQL) (res ast.Node, err
 	return nil, nil
 }
 
+func convertVariableSetStmt(in *pgquery.VariableSetStmt) ast.Node {
+	variableSet := &ast.VariableSetStmt{
+		Name:  in.Name,
+		Local: in.IsLocal,
+	}
+	switch in.Kind {
+	case pgquery.VariableSetKind_VAR_SET_VALUE:
+		for _, arg := range in.Args {
+			constant, ok := arg.Node.(*pgquery.Node_AConst)
+			if !ok {
+				return &ast.UnconvertedStmt{}
+			}
+			switch value := constant.AConst.Val.Node.(type) {
+			case *pgquery.Node_String_:
+				variableSet.ValueList = append(variableSet.ValueList, value.String_.Str)
+			case *pgquery.Node_Integer:
+				variableSet.ValueList = append(variableSet.ValueList, strconv.FormatInt(int64(value.Integer.Ival), 10))
+			case *pgquery.Node_Float:
+				variableSet.ValueList = append(variableSet.ValueList, value.Float.Str)
+			default:
+				return &ast.UnconvertedStmt{}
+			}
+		}
+	case pgquery.VariableSetKind_VAR_SET_DEFAULT, pgquery.VariableSetKind_VAR_RESET:
+		// The parameter is set to its default value, so the value list is empty.
+	default:
+		// SET ... FROM CURRENT, SET TRANSACTION, RESET ALL and the like.
+		return &ast.UnconvertedStmt{}
+	}
+	return variableSet
+}
+
+// The bits of the trigger type in PostgreSQL, see src/include/catalog/pg_trigger.h.
+const (
+	triggerTypeRow      = 1 << 0
+	triggerTypeBefore   = 1 << 1
+	triggerTypeInsert   = 1 << 2
+	triggerTypeDelete   = 1 << 3
+	triggerTypeUpdate   = 1 << 4
+	triggerTypeTruncate = 1 << 5
+	triggerTypeInstead  = 1 << 6
+)
+
+func convertTriggerDef(in *pgquery.CreateTrigStmt, function *ast.FunctionDef) (*ast.TriggerDef, error) {
+	trigger := &ast.TriggerDef{
+		Name:       in.Trigname,
+		Table:      convertRangeVarToTableName(in.Relation, ast.TableTypeBaseTable),
+		Function:   function,
+		ForEachRow: in.Row,
+	}
+	switch {
+	case in.Timing&triggerTypeBefore != 0:
+		trigger.Timing = ast.TriggerTimingBefore
+	case in.Timing&triggerTypeInstead != 0:
+		trigger.Timing = ast.TriggerTimingInsteadOf
+	default:
+		trigger.Timing = ast.TriggerTimingAfter
+	}
+	for _, event := range []struct {
+		bit   int32
+		event ast.TriggerEvent
+	}{
+		{triggerTypeInsert, ast.TriggerEventInsert},
+		{triggerTypeUpdate, ast.TriggerEventUpdate},
+		{triggerTypeDelete, ast.TriggerEventDelete},
+		{triggerTypeTruncate, ast.TriggerEventTruncate},
+	} {
+		if in.Events&event.bit != 0 {
+			trigger.EventList = append(trigger.EventList, event.event)
+		}
+	}
+	columnList, err := convertNodeListToStringList(in.Columns)
+	if err != nil {
+		return nil, err
+	}
+	trigger.UpdateColumnList = columnList
+	argumentList, err := convertNodeListToStringList(in.Args)
+	if err != nil {
+		return nil, err
+	}
+	trigger.ArgumentList = argumentList
+	if in.WhenClause != nil {
+		when, err := deparseExpressionNode(in.WhenClause)
+		if err != nil {
+			return nil, err
+		}
+		trigger.When = when
+	}
+	return trigger, nil
+}
+
+func convertFunctionOptionList(function *ast.CreateFunctionStmt, optionList []*pgquery.Node) error {
+	for _, option := range optionList {
+		item, ok := option.Node.(*pgquery.Node_DefElem)
+		if !ok {
+			return parser.NewConvertErrorf("expected DefElem but found %t", option.Node)
+		}
+		defElem := item.DefElem
+		switch defElem.Defname {
+		case "language", "volatility":
+			value, ok := defElem.Arg.Node.(*pgquery.Node_String_)
+			if !ok {
+				return parser.NewConvertErrorf("expected String but found %t", defElem.Arg.Node)
+			}
+			if defElem.Defname == "language" {
+				function.Language = value.String_.Str
+			} else {
+				function.Volatility = strings.ToUpper(value.String_.Str)
+			}
+		case "strict", "security":
+			value, err := convertToInt64(defElem.Arg)
+			if err != nil {
+				return err
+			}
+			if defElem.Defname == "strict" {
+				function.Strict = value != 0
+			} else {
+				function.SecurityDefiner = value != 0
+			}
+		case "as":
+			list, ok := defElem.Arg.Node.(*pgquery.Node_List)
+			if !ok {
+				return parser.NewConvertErrorf("expected List but found %t", defElem.Arg.Node)
+			}
+			// The C functions have the object file and the link symbol, and we only keep the first one.
+			bodyList, err := convertListToStringList(list)
+			if err != nil {
+				return err
+			}
+			if len(bodyList) > 0 {
+				function.Body = bodyList[0]
+			}
+		}
+	}
+	return nil
+}
+
+func convertGrantStmt(in *pgquery.GrantStmt) (ast.Node, error) {
+	target := &ast.PrivilegeTargetDef{
+		AllInSchema: in.Targtype == pgquery.GrantTargetType_ACL_TARGET_ALL_IN_SCHEMA,
+	}
+	switch in.Objtype {
+	case pgquery.ObjectType_OBJECT_TABLE:
+		target.Type = ast.PrivilegeObjectTypeTable
+	case pgquery.ObjectType_OBJECT_SEQUENCE:
+		target.Type = ast.PrivilegeObjectTypeSequence
+	case pgquery.ObjectType_OBJECT_DATABASE:
+		target.Type = ast.PrivilegeObjectTypeDatabase
+	case pgquery.ObjectType_OBJECT_SCHEMA:
+		target.Type = ast.PrivilegeObjectTypeSchema
+	case pgquery.ObjectType_OBJECT_FUNCTION:
+		target.Type = ast.PrivilegeObjectTypeFunction
+	case pgquery.ObjectType_OBJECT_PROCEDURE:
+		target.Type = ast.PrivilegeObjectTypeProcedure
+	case pgquery.ObjectType_OBJECT_TYPE:
+		target.Type = ast.PrivilegeObjectTypeType
+	default:
+		return &ast.UnconvertedStmt{}, nil
+	}
+	if in.Targtype == pgquery.GrantTargetType_ACL_TARGET_DEFAULTS {
+		return &ast.UnconvertedStmt{}, nil
+	}
+
+	for _, object := range in.Objects {
+		switch node := object.Node.(type) {
+		case *pgquery.Node_String_:
+			target.NameList = append(target.NameList, node.String_.Str)
+		case *pgquery.Node_RangeVar:
+			if target.Type == ast.PrivilegeObjectTypeSequence {
+				target.SequenceList = append(target.SequenceList, &ast.SequenceNameDef{
+					Schema: node.RangeVar.Schemaname,
+					Name:   node.RangeVar.Relname,
+				})
+			} else {
+				target.TableList = append(target.TableList, convertRangeVarToTableName(node.RangeVar, ast.TableTypeUnknown))
+			}
+		case *pgquery.Node_ObjectWithArgs:
+			function, err := convertToFunctionDef(node.ObjectWithArgs.Objname, nil /* parameters */)
+			if err != nil {
+				return nil, err
+			}
+			for _, arg := range node.ObjectWithArgs.Objargs {
+				tp, ok := arg.Node.(*pgquery.Node_TypeName)
+				if !ok {
+					return nil, parser.NewConvertErrorf("expected TypeName but found %t", arg.Node)
+				}
+				function.ParameterList = append(function.ParameterList, &ast.FunctionParameterDef{
+					Type: convertDataType(tp.TypeName),
+					Mode: ast.FunctionParameterModeIn,
+				})
+			}
+			target.FunctionList = append(target.FunctionList, function)
+		case *pgquery.Node_List:
+			nameList, err := convertListToStringList(node)
+			if err != nil {
+				return nil, err
+			}
+			typeName, err := convertStringListToTypeNameDef(nameList)
+			if err != nil {
+				return nil, err
+			}
+			target.TypeList = append(target.TypeList, typeName)
+		default:
+			return nil, parser.NewConvertErrorf("unexpected privilege object %t", object.Node)
+		}
+	}
+
+	var privilegeList []*ast.PrivilegeDef
+	// The empty privilege list means ALL PRIVILEGES.
+	if len(in.Privileges) == 0 {
+		privilegeList = append(privilegeList, &ast.PrivilegeDef{Type: "ALL"})
+	}
+	for _, item := range in.Privileges {
+		privilege, ok := item.Node.(*pgquery.Node_AccessPriv)
+		if !ok {
+			return nil, parser.NewConvertErrorf("expected AccessPriv but found %t", item.Node)
+		}
+		columnList, err := convertNodeListToStringList(privilege.AccessPriv.Cols)
+		if err != nil {
+			return nil, err
+		}
+		privilegeType := strings.ToUpper(privilege.AccessPriv.PrivName)
+		if privilegeType == "" {
+			// ALL PRIVILEGES with the column list.
+			privilegeType = "ALL"
+		}
+		privilegeList = append(privilegeList, &ast.PrivilegeDef{
+			Type:       privilegeType,
+			ColumnList: columnList,
+		})
+	}
+
+	var granteeList []string
+	for _, item := range in.Grantees {
+		role, ok := item.Node.(*pgquery.Node_RoleSpec)
+		if !ok {
+			return nil, parser.NewConvertErrorf("expected RoleSpec but found %t", item.Node)
+		}
+		granteeList = append(granteeList, convertRoleSpec(role.RoleSpec))
+	}
+
+	if in.IsGrant {
+		return &ast.GrantStmt{
+			PrivilegeList:   privilegeList,
+			Target:          target,
+			GranteeList:     granteeList,
+			WithGrantOption: in.GrantOption,
+		}, nil
+	}
+	return &ast.RevokeStmt{
+		GrantOptionFor: in.GrantOption,
+		PrivilegeList:  privilegeList,
+		Target:         target,
+		GranteeList:    granteeList,
+		Cascade:        in.Behavior == pgquery.DropBehavior_DROP_CASCADE,
+	}, nil
+}
+
+// convertRoleSpec returns the role name, and the special roles in upper case.
+func convertRoleSpec(in *pgquery.RoleSpec) string {
+	switch in.Roletype {
+	case pgquery.RoleSpecType_ROLESPEC_PUBLIC:
+		return "PUBLIC"
+	case pgquery.RoleSpecType_ROLESPEC_CURRENT_USER:
+		return "CURRENT_USER"
+	case pgquery.RoleSpecType_ROLESPEC_SESSION_USER:
+		return "SESSION_USER"
+	default:
+		return in.Rolename
+	}
+}
+
+// deparseStatementNode returns the normalized text of the statement node, such as the query of views.
+func deparseStatementNode(in *pgquery.Node) (string, error) {
+	return pgquery.Deparse(&pgquery.ParseResult{Stmts: []*pgquery.RawStmt{{Stmt: in}}})
+}
+
+// deparseExpressionNode returns the normalized text of the expression node by deparsing it as SELECT expression.
+func deparseExpressionNode(in *pgquery.Node) (string, error) {
+	text, err := deparseStatementNode(&pgquery.Node{
+		Node: &pgquery.Node_SelectStmt{
+			SelectStmt: &pgquery.SelectStmt{
+				TargetList: []*pgquery.Node{
+					{Node: &pgquery.Node_ResTarget{ResTarget: &pgquery.ResTarget{Val: in}}},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimPrefix(text, "SELECT "), nil
+}
+
 func convertExpressionNode(node *pgquery.Node) (ast.ExpressionNode, []*ast.PatternLikeDef, []*ast.SubqueryDef, error) {
 	if node == nil || node.Node == nil {
 		return &ast.UnconvertedExpressionDef{}, nil, nil, nil
@@ -482,17 +974,35 @@ func convertExpressionNode(node *pgquery.Node) (ast.ExpressionNode, []*ast.Patte
 		}
 		return columnName, nil, nil, nil
 	case *pgquery.Node_FuncCall:
+		funcCall := &ast.FuncCallDef{}
+		nameList, err := convertNodeListToStringList(in.FuncCall.Funcname)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		// There are two cases for function name:
+		//   1. schemaName.functionName
+		//   2. functionName
+		switch len(nameList) {
+		case 2:
+			funcCall.Schema = nameList[0]
+			funcCall.Name = nameList[1]
+		case 1:
+			funcCall.Name = nameList[0]
+		default:
+			return nil, nil, nil, parser.NewConvertErrorf("expected length is 1 or 2, but found %d", len(nameList))
+		}
 		var likeList []*ast.PatternLikeDef
 		var subqueryList []*ast.SubqueryDef
 		for _, arg := range in.FuncCall.Args {
-			_, interLike, interSubquery, err := convertExpressionNode(arg)
+			parameter, interLike, interSubquery, err := convertExpressionNode(arg)
 			if err != nil {
 				return nil, nil, nil, err
 			}
+			funcCall.ParameterList = append(funcCall.ParameterList, parameter)
 			likeList = append(likeList, interLike...)
 			subqueryList = append(subqueryList, interSubquery...)
 		}
-		return &ast.UnconvertedExpressionDef{}, likeList, subqueryList, nil
+		return funcCall, likeList, subqueryList, nil
 	case *pgquery.Node_AExpr:
 		var likeList, interLike []*ast.PatternLikeDef
 		var subqueryList, interSubquery []*ast.SubqueryDef
@@ -607,6 +1117,20 @@ func convertSelectStmt(in *pgquery.SelectStmt) (*ast.SelectStmt, error) {
 		}
 		selectStmt.SubqueryList = append(selectStmt.SubqueryList, subqueryList...)
 	}
+	// Convert ORDER BY clause
+	for _, item := range in.SortClause {
+		sortBy, ok := item.Node.(*pgquery.Node_SortBy)
+		if !ok {
+			return nil, parser.NewConvertErrorf("expected SortBy but found %t", item.Node)
+		}
+		expression, likeList, subqueryList, err := convertExpressionNode(sortBy.SortBy.Node)
+		if err != nil {
+			return nil, err
+		}
+		selectStmt.OrderByClause = append(selectStmt.OrderByClause, &ast.ByItemDef{Expression: expression})
+		selectStmt.PatternLikeList = append(selectStmt.PatternLikeList, likeList...)
+		selectStmt.SubqueryList = append(selectStmt.SubqueryList, subqueryList...)
+	}
 	return selectStmt, nil
 }
 
@@ -680,9 +1204,41 @@ func convertListToIndexDef(in *pgquery.Node_List, tableType ast.TableType) (*ast
 	return indexDef, nil
 }
 
+func convertListToColumnNameDef(in *pgquery.Node_List) (*ast.ColumnNameDef, error) {
+	stringList, err := convertListToStringList(in)
+	if err != nil {
+		return nil, err
+	}
+	switch len(stringList) {
+	case 3:
+		return &ast.ColumnNameDef{
+			Table: &ast.TableDef{
+				Type:   ast.TableTypeBaseTable,
+				Schema: stringList[0],
+				Name:   stringList[1],
+			},
+			ColumnName: stringList[2],
+		}, nil
+	case 2:
+		return &ast.ColumnNameDef{
+			Table: &ast.TableDef{
+				Type: ast.TableTypeBaseTable,
+				Name: stringList[0],
+			},
+			ColumnName: stringList[1],
+		}, nil
+	default:
+		return nil, parser.NewConvertErrorf("expected length is 2 or 3, but found %d", len(stringList))
+	}
+}
+
 func convertListToStringList(in *pgquery.Node_List) ([]string, error) {
+	return convertNodeListToStringList(in.List.Items)
+}
+
+func convertNodeListToStringList(list []*pgquery.Node) ([]string, error) {
 	var res []string
-	for _, item := range in.List.Items {
+	for _, item := range list {
 		s, ok := item.Node.(*pgquery.Node_String_)
 		if !ok {
 			return nil, parser.NewConvertErrorf("expected String but found %t", item.Node)
@@ -692,6 +1248,163 @@ func convertListToStringList(in *pgquery.Node_List) ([]string, error) {
 	return res, nil
 }
 
+func convertStringListToTypeNameDef(nameList []string) (*ast.TypeNameDef, error) {
+	switch len(nameList) {
+	case 2:
+		return &ast.TypeNameDef{
+			Schema: nameList[0],
+			Name:   nameList[1],
+		}, nil
+	case 1:
+		return &ast.TypeNameDef{
+			Name: nameList[0],
+		}, nil
+	default:
+		return nil, parser.NewConvertErrorf("expected length is 1 or 2, but found %d", len(nameList))
+	}
+}
+
+func convertToFunctionDef(funcName []*pgquery.Node, parameterList []*pgquery.Node) (*ast.FunctionDef, error) {
+	nameList, err := convertNodeListToStringList(funcName)
+	if err != nil {
+		return nil, err
+	}
+	function := &ast.FunctionDef{}
+	switch len(nameList) {
+	case 2:
+		function.Schema = nameList[0]
+		function.Name = nameList[1]
+	case 1:
+		function.Name = nameList[0]
+	default:
+		return nil, parser.NewConvertErrorf("expected length is 1 or 2, but found %d", len(nameList))
+	}
+
+	for _, item := range parameterList {
+		parameter, ok := item.Node.(*pgquery.Node_FunctionParameter)
+		if !ok {
+			return nil, parser.NewConvertErrorf("expected FunctionParameter but found %t", item.Node)
+		}
+		mode, err := convertFunctionParameterMode(parameter.FunctionParameter.Mode)
+		if err != nil {
+			return nil, err
+		}
+		function.ParameterList = append(function.ParameterList, &ast.FunctionParameterDef{
+			Name: parameter.FunctionParameter.Name,
+			Type: convertDataType(parameter.FunctionParameter.ArgType),
+			Mode: mode,
+		})
+	}
+	return function, nil
+}
+
+func convertFunctionParameterMode(mode pgquery.FunctionParameterMode) (ast.FunctionParameterMode, error) {
+	switch mode {
+	case pgquery.FunctionParameterMode_FUNC_PARAM_IN:
+		return ast.FunctionParameterModeIn, nil
+	case pgquery.FunctionParameterMode_FUNC_PARAM_OUT:
+		return ast.FunctionParameterModeOut, nil
+	case pgquery.FunctionParameterMode_FUNC_PARAM_INOUT:
+		return ast.FunctionParameterModeInOut, nil
+	case pgquery.FunctionParameterMode_FUNC_PARAM_VARIADIC:
+		return ast.FunctionParameterModeVariadic, nil
+	case pgquery.FunctionParameterMode_FUNC_PARAM_TABLE:
+		return ast.FunctionParameterModeTable, nil
+	}
+	return ast.FunctionParameterModeIn, parser.NewConvertErrorf("unsupported function parameter mode %s", mode)
+}
+
+func convertSequenceDef(sequence *pgquery.RangeVar, optionList []*pgquery.Node) (*ast.SequenceDef, error) {
+	sequenceDef := &ast.SequenceDef{
+		SequenceName: &ast.SequenceNameDef{
+			Schema: sequence.Schemaname,
+			Name:   sequence.Relname,
+		},
+	}
+	for _, option := range optionList {
+		item, ok := option.Node.(*pgquery.Node_DefElem)
+		if !ok {
+			return nil, parser.NewConvertErrorf("expected DefElem but found %t", option.Node)
+		}
+		defElem := item.DefElem
+		switch defElem.Defname {
+		case "as":
+			tp, ok := defElem.Arg.Node.(*pgquery.Node_TypeName)
+			if !ok {
+				return nil, parser.NewConvertErrorf("expected TypeName but found %t", defElem.Arg.Node)
+			}
+			sequenceDef.SequenceDataType = convertDataType(tp.TypeName)
+		case "increment", "minvalue", "maxvalue", "start", "cache":
+			// The MINVALUE and MAXVALUE options without argument are the NO MINVALUE and NO MAXVALUE.
+			if defElem.Arg == nil {
+				switch defElem.Defname {
+				case "minvalue":
+					sequenceDef.NoMinValue = true
+				case "maxvalue":
+					sequenceDef.NoMaxValue = true
+				}
+				continue
+			}
+			value, err := convertToInt64(defElem.Arg)
+			if err != nil {
+				return nil, err
+			}
+			switch defElem.Defname {
+			case "increment":
+				sequenceDef.IncrementBy = &value
+			case "minvalue":
+				sequenceDef.MinValue = &value
+			case "maxvalue":
+				sequenceDef.MaxValue = &value
+			case "start":
+				sequenceDef.StartWith = &value
+			case "cache":
+				sequenceDef.Cache = &value
+			}
+		case "cycle":
+			value, err := convertToInt64(defElem.Arg)
+			if err != nil {
+				return nil, err
+			}
+			sequenceDef.Cycle = value != 0
+		case "owned_by":
+			list, ok := defElem.Arg.Node.(*pgquery.Node_List)
+			if !ok {
+				return nil, parser.NewConvertErrorf("expected List but found %t", defElem.Arg.Node)
+			}
+			nameList, err := convertListToStringList(list)
+			if err != nil {
+				return nil, err
+			}
+			if len(nameList) == 1 && strings.ToLower(nameList[0]) == "none" {
+				sequenceDef.OwnedByNone = true
+				continue
+			}
+			column, err := convertListToColumnNameDef(list)
+			if err != nil {
+				return nil, err
+			}
+			sequenceDef.OwnedBy = column
+		}
+	}
+	return sequenceDef, nil
+}
+
+func convertToInt64(in *pgquery.Node) (int64, error) {
+	switch node := in.Node.(type) {
+	case *pgquery.Node_Integer:
+		return int64(node.Integer.Ival), nil
+	case *pgquery.Node_Float:
+		// The integer which is out of the int32 range is a Float node in pg_query.
+		value, err := strconv.ParseInt(node.Float.Str, 10, 64)
+		if err != nil {
+			return 0, parser.NewConvertErrorf("expected int64 but found %s", node.Float.Str)
+		}
+		return value, nil
+	}
+	return 0, parser.NewConvertErrorf("expected Integer or Float but found %t", in.Node)
+}
+
 func convertRangeVarToTableName(in *pgquery.RangeVar, tableType ast.TableType) *ast.TableDef {
 	return &ast.TableDef{
 		Type:     tableType,
@@ -778,6 +1491,8 @@ func convertConstraintType(in pgquery.ConstrType, usingIndex bool) ast.Constrain
 		return ast.ConstraintTypeNotNull
 	case pgquery.ConstrType_CONSTR_CHECK:
 		return ast.ConstraintTypeCheck
+	case pgquery.ConstrType_CONSTR_DEFAULT:
+		return ast.ConstraintTypeDefault
 	}
 	return ast.ConstraintTypeUndefined
 }
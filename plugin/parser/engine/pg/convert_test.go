@@ -723,6 +723,23 @@ func TestPGDropIndexStmt(t *testing.T) {
 				},
 			},
 		},
+		{
+			stmt: "DROP INDEX IF EXISTS idx_x",
+			want: []ast.Node{
+				&ast.DropIndexStmt{
+					IfExists: true,
+					IndexList: []*ast.IndexDef{
+						{Name: "idx_x"},
+					},
+				},
+			},
+			statementList: []parser.SingleSQL{
+				{
+					Text:     "DROP INDEX IF EXISTS idx_x",
+					LastLine: 1,
+				},
+			},
+		},
 	}
 
 	runTests(t, tests)
@@ -1023,6 +1040,34 @@ func TestPGDropColumnStmt(t *testing.T) {
 				},
 			},
 		},
+		{
+			stmt: "ALTER TABLE IF EXISTS tech_book DROP COLUMN IF EXISTS a",
+			want: []ast.Node{
+				&ast.AlterTableStmt{
+					IfExists: true,
+					Table: &ast.TableDef{
+						Type: ast.TableTypeBaseTable,
+						Name: "tech_book",
+					},
+					AlterItemList: []ast.Node{
+						&ast.DropColumnStmt{
+							Table: &ast.TableDef{
+								Type: ast.TableTypeBaseTable,
+								Name: "tech_book",
+							},
+							IfExists:   true,
+							ColumnName: "a",
+						},
+					},
+				},
+			},
+			statementList: []parser.SingleSQL{
+				{
+					Text:     "ALTER TABLE IF EXISTS tech_book DROP COLUMN IF EXISTS a",
+					LastLine: 1,
+				},
+			},
+		},
 	}
 
 	runTests(t, tests)
@@ -1078,6 +1123,26 @@ func TestPGDropTableStmt(t *testing.T) {
 				},
 			},
 		},
+		{
+			stmt: "DROP TABLE IF EXISTS tech_book",
+			want: []ast.Node{
+				&ast.DropTableStmt{
+					IfExists: true,
+					TableList: []*ast.TableDef{
+						{
+							Type: ast.TableTypeBaseTable,
+							Name: "tech_book",
+						},
+					},
+				},
+			},
+			statementList: []parser.SingleSQL{
+				{
+					Text:     "DROP TABLE IF EXISTS tech_book",
+					LastLine: 1,
+				},
+			},
+		},
 	}
 
 	runTests(t, tests)
@@ -1250,7 +1315,15 @@ func TestPGSelectStmt(t *testing.T) {
 								Table:      &ast.TableDef{},
 								ColumnName: "b",
 							},
-							&ast.UnconvertedExpressionDef{},
+							&ast.FuncCallDef{
+								Name: "lower",
+								ParameterList: []ast.ExpressionNode{
+									&ast.ColumnNameDef{
+										Table:      &ast.TableDef{},
+										ColumnName: "a",
+									},
+								},
+							},
 							&ast.UnconvertedExpressionDef{},
 						},
 						WhereClause: &ast.UnconvertedExpressionDef{},
@@ -1700,6 +1773,34 @@ func TestInsertStmt(t *testing.T) {
 				},
 			},
 		},
+		{
+			stmt: "INSERT INTO tech_book SELECT * FROM book ORDER BY random()",
+			want: []ast.Node{
+				&ast.InsertStmt{
+					Table: &ast.TableDef{
+						Type: ast.TableTypeBaseTable,
+						Name: "tech_book",
+					},
+					Select: &ast.SelectStmt{
+						FieldList: []ast.ExpressionNode{
+							&ast.ColumnNameDef{
+								Table:      &ast.TableDef{},
+								ColumnName: "*",
+							},
+						},
+						OrderByClause: []*ast.ByItemDef{
+							{Expression: &ast.FuncCallDef{Name: "random"}},
+						},
+					},
+				},
+			},
+			statementList: []parser.SingleSQL{
+				{
+					Text:     "INSERT INTO tech_book SELECT * FROM book ORDER BY random()",
+					LastLine: 1,
+				},
+			},
+		},
 	}
 
 	runTests(t, tests)
@@ -1753,6 +1854,11 @@ func TestCommentStmt(t *testing.T) {
 			stmt: "COMMENT ON TABLE tech_book IS 'This is a comment.'",
 			want: []ast.Node{&ast.CommentStmt{
 				Comment: "This is a comment.",
+				Type:    ast.ObjectTypeTable,
+				Object: &ast.TableDef{
+					Type: ast.TableTypeBaseTable,
+					Name: "tech_book",
+				},
 			}},
 			statementList: []parser.SingleSQL{
 				{
@@ -1761,6 +1867,39 @@ func TestCommentStmt(t *testing.T) {
 				},
 			},
 		},
+		{
+			stmt: "COMMENT ON COLUMN public.tech_book.id IS 'This is a comment.'",
+			want: []ast.Node{&ast.CommentStmt{
+				Comment: "This is a comment.",
+				Type:    ast.ObjectTypeColumn,
+				Object: &ast.ColumnNameDef{
+					Table: &ast.TableDef{
+						Type:   ast.TableTypeBaseTable,
+						Schema: "public",
+						Name:   "tech_book",
+					},
+					ColumnName: "id",
+				},
+			}},
+			statementList: []parser.SingleSQL{
+				{
+					Text:     "COMMENT ON COLUMN public.tech_book.id IS 'This is a comment.'",
+					LastLine: 1,
+				},
+			},
+		},
+		{
+			stmt: "COMMENT ON SCHEMA public IS 'This is a comment.'",
+			want: []ast.Node{&ast.CommentStmt{
+				Comment: "This is a comment.",
+			}},
+			statementList: []parser.SingleSQL{
+				{
+					Text:     "COMMENT ON SCHEMA public IS 'This is a comment.'",
+					LastLine: 1,
+				},
+			},
+		},
 	}
 
 	runTests(t, tests)
@@ -1790,3 +1929,535 @@ func TestCreateDatabase(t *testing.T) {
 
 	runTests(t, tests)
 }
+
+func TestCommitStmt(t *testing.T) {
+	tests := []testData{
+		{
+			stmt: "COMMIT",
+			want: []ast.Node{&ast.CommitStmt{}},
+			statementList: []parser.SingleSQL{
+				{
+					Text:     "COMMIT",
+					LastLine: 1,
+				},
+			},
+		},
+		{
+			stmt: "BEGIN",
+			want: []ast.Node{&ast.UnconvertedStmt{}},
+			statementList: []parser.SingleSQL{
+				{
+					Text:     "BEGIN",
+					LastLine: 1,
+				},
+			},
+		},
+	}
+
+	runTests(t, tests)
+}
+
+func TestCreateViewStmt(t *testing.T) {
+	tests := []testData{
+		{
+			stmt: "CREATE OR REPLACE VIEW public.v AS SELECT * FROM t",
+			want: []ast.Node{
+				&ast.CreateViewStmt{
+					Replace: true,
+					Name: &ast.TableDef{
+						Type:   ast.TableTypeView,
+						Schema: "public",
+						Name:   "v",
+					},
+					Definition: "SELECT * FROM t",
+				},
+			},
+			statementList: []parser.SingleSQL{
+				{
+					Text:     "CREATE OR REPLACE VIEW public.v AS SELECT * FROM t",
+					LastLine: 1,
+				},
+			},
+		},
+	}
+
+	runTests(t, tests)
+}
+
+func TestCreateSequenceStmt(t *testing.T) {
+	one := int64(1)
+	maxValue := int64(9223372036854775807)
+	tests := []testData{
+		{
+			stmt: "CREATE SEQUENCE public.s AS integer START WITH 1 INCREMENT BY 1 NO MINVALUE MAXVALUE 9223372036854775807 CACHE 1 OWNED BY t.id",
+			want: []ast.Node{
+				&ast.CreateSequenceStmt{
+					SequenceDef: ast.SequenceDef{
+						SequenceName: &ast.SequenceNameDef{
+							Schema: "public",
+							Name:   "s",
+						},
+						SequenceDataType: &ast.Integer{Size: 4},
+						StartWith:        &one,
+						IncrementBy:      &one,
+						NoMinValue:       true,
+						MaxValue:         &maxValue,
+						Cache:            &one,
+						OwnedBy: &ast.ColumnNameDef{
+							Table: &ast.TableDef{
+								Type: ast.TableTypeBaseTable,
+								Name: "t",
+							},
+							ColumnName: "id",
+						},
+					},
+				},
+			},
+			statementList: []parser.SingleSQL{
+				{
+					Text:     "CREATE SEQUENCE public.s AS integer START WITH 1 INCREMENT BY 1 NO MINVALUE MAXVALUE 9223372036854775807 CACHE 1 OWNED BY t.id",
+					LastLine: 1,
+				},
+			},
+		},
+		{
+			stmt: "CREATE SEQUENCE IF NOT EXISTS s CYCLE OWNED BY NONE",
+			want: []ast.Node{
+				&ast.CreateSequenceStmt{
+					IfNotExists: true,
+					SequenceDef: ast.SequenceDef{
+						SequenceName: &ast.SequenceNameDef{
+							Name: "s",
+						},
+						Cycle:       true,
+						OwnedByNone: true,
+					},
+				},
+			},
+			statementList: []parser.SingleSQL{
+				{
+					Text:     "CREATE SEQUENCE IF NOT EXISTS s CYCLE OWNED BY NONE",
+					LastLine: 1,
+				},
+			},
+		},
+	}
+
+	runTests(t, tests)
+}
+
+func TestCreateTypeStmt(t *testing.T) {
+	tests := []testData{
+		{
+			stmt: "CREATE TYPE public.mood AS ENUM ('sad', 'ok', 'happy')",
+			want: []ast.Node{
+				&ast.CreateTypeStmt{
+					Type: &ast.EnumTypeDef{
+						Name: &ast.TypeNameDef{
+							Schema: "public",
+							Name:   "mood",
+						},
+						LabelList: []string{"sad", "ok", "happy"},
+					},
+				},
+			},
+			statementList: []parser.SingleSQL{
+				{
+					Text:     "CREATE TYPE public.mood AS ENUM ('sad', 'ok', 'happy')",
+					LastLine: 1,
+				},
+			},
+		},
+		{
+			stmt: "CREATE TYPE point AS (x int, y int)",
+			want: []ast.Node{&ast.UnconvertedStmt{}},
+			statementList: []parser.SingleSQL{
+				{
+					Text:     "CREATE TYPE point AS (x int, y int)",
+					LastLine: 1,
+				},
+			},
+		},
+	}
+
+	runTests(t, tests)
+}
+
+func TestCreateFunctionStmt(t *testing.T) {
+	tests := []testData{
+		{
+			stmt: "CREATE OR REPLACE FUNCTION public.add(a int, INOUT b bigint, OUT c text) RETURNS record LANGUAGE sql AS $$ SELECT 1 $$",
+			want: []ast.Node{
+				&ast.CreateFunctionStmt{
+					Replace: true,
+					Function: &ast.FunctionDef{
+						Schema: "public",
+						Name:   "add",
+						ParameterList: []*ast.FunctionParameterDef{
+							{
+								Name: "a",
+								Type: &ast.Integer{Size: 4},
+								Mode: ast.FunctionParameterModeIn,
+							},
+							{
+								Name: "b",
+								Type: &ast.Integer{Size: 8},
+								Mode: ast.FunctionParameterModeInOut,
+							},
+							{
+								Name: "c",
+								Type: &ast.UnconvertedDataType{Name: []string{"text"}},
+								Mode: ast.FunctionParameterModeOut,
+							},
+						},
+					},
+					ReturnType: &ast.UnconvertedDataType{Name: []string{"record"}},
+					Language:   "sql",
+					Body:       " SELECT 1 ",
+				},
+			},
+			statementList: []parser.SingleSQL{
+				{
+					Text:     "CREATE OR REPLACE FUNCTION public.add(a int, INOUT b bigint, OUT c text) RETURNS record LANGUAGE sql AS $$ SELECT 1 $$",
+					LastLine: 1,
+				},
+			},
+		},
+		{
+			stmt: "CREATE PROCEDURE p() LANGUAGE sql AS $$ SELECT 1 $$",
+			want: []ast.Node{
+				&ast.CreateFunctionStmt{
+					IsProcedure: true,
+					Function: &ast.FunctionDef{
+						Name: "p",
+					},
+					Language: "sql",
+					Body:     " SELECT 1 ",
+				},
+			},
+			statementList: []parser.SingleSQL{
+				{
+					Text:     "CREATE PROCEDURE p() LANGUAGE sql AS $$ SELECT 1 $$",
+					LastLine: 1,
+				},
+			},
+		},
+	}
+
+	runTests(t, tests)
+}
+
+func TestCreateTriggerStmt(t *testing.T) {
+	tests := []testData{
+		{
+			stmt: "CREATE TRIGGER check_update BEFORE UPDATE ON public.accounts FOR EACH ROW EXECUTE FUNCTION check_account_update()",
+			want: []ast.Node{
+				&ast.CreateTriggerStmt{
+					Trigger: &ast.TriggerDef{
+						Name: "check_update",
+						Table: &ast.TableDef{
+							Type:   ast.TableTypeBaseTable,
+							Schema: "public",
+							Name:   "accounts",
+						},
+						Function: &ast.FunctionDef{
+							Name: "check_account_update",
+						},
+						Timing:     ast.TriggerTimingBefore,
+						EventList:  []ast.TriggerEvent{ast.TriggerEventUpdate},
+						ForEachRow: true,
+					},
+				},
+			},
+			statementList: []parser.SingleSQL{
+				{
+					Text:     "CREATE TRIGGER check_update BEFORE UPDATE ON public.accounts FOR EACH ROW EXECUTE FUNCTION check_account_update()",
+					LastLine: 1,
+				},
+			},
+		},
+	}
+
+	runTests(t, tests)
+}
+
+func TestCreateExtensionStmt(t *testing.T) {
+	tests := []testData{
+		{
+			stmt: "CREATE EXTENSION IF NOT EXISTS pgcrypto WITH SCHEMA public VERSION '1.3'",
+			want: []ast.Node{
+				&ast.CreateExtensionStmt{
+					IfNotExists: true,
+					Name:        "pgcrypto",
+					Schema:      "public",
+					Version:     "1.3",
+				},
+			},
+			statementList: []parser.SingleSQL{
+				{
+					Text:     "CREATE EXTENSION IF NOT EXISTS pgcrypto WITH SCHEMA public VERSION '1.3'",
+					LastLine: 1,
+				},
+			},
+		},
+	}
+
+	runTests(t, tests)
+}
+
+func TestAlterSequenceStmt(t *testing.T) {
+	increment := int64(2)
+	restart := int64(10)
+	tests := []testData{
+		{
+			stmt: "ALTER SEQUENCE IF EXISTS public.seq INCREMENT BY 2 NO CYCLE RESTART WITH 10 OWNED BY public.t.id",
+			want: []ast.Node{
+				&ast.AlterSequenceStmt{
+					IfExists: true,
+					SequenceDef: ast.SequenceDef{
+						SequenceName: &ast.SequenceNameDef{
+							Schema: "public",
+							Name:   "seq",
+						},
+						IncrementBy: &increment,
+						OwnedBy: &ast.ColumnNameDef{
+							Table: &ast.TableDef{
+								Type:   ast.TableTypeBaseTable,
+								Schema: "public",
+								Name:   "t",
+							},
+							ColumnName: "id",
+						},
+					},
+					NoCycle:     true,
+					Restart:     true,
+					RestartWith: &restart,
+				},
+			},
+			statementList: []parser.SingleSQL{
+				{
+					Text:     "ALTER SEQUENCE IF EXISTS public.seq INCREMENT BY 2 NO CYCLE RESTART WITH 10 OWNED BY public.t.id",
+					LastLine: 1,
+				},
+			},
+		},
+	}
+
+	runTests(t, tests)
+}
+
+func TestGrantStmt(t *testing.T) {
+	tests := []testData{
+		{
+			stmt: "GRANT SELECT, UPDATE (a, b) ON TABLE t1, public.t2 TO u1, PUBLIC WITH GRANT OPTION",
+			want: []ast.Node{
+				&ast.GrantStmt{
+					PrivilegeList: []*ast.PrivilegeDef{
+						{Type: "SELECT"},
+						{Type: "UPDATE", ColumnList: []string{"a", "b"}},
+					},
+					Target: &ast.PrivilegeTargetDef{
+						Type: ast.PrivilegeObjectTypeTable,
+						TableList: []*ast.TableDef{
+							{Type: ast.TableTypeUnknown, Name: "t1"},
+							{Type: ast.TableTypeUnknown, Schema: "public", Name: "t2"},
+						},
+					},
+					GranteeList:     []string{"u1", "PUBLIC"},
+					WithGrantOption: true,
+				},
+			},
+			statementList: []parser.SingleSQL{
+				{
+					Text:     "GRANT SELECT, UPDATE (a, b) ON TABLE t1, public.t2 TO u1, PUBLIC WITH GRANT OPTION",
+					LastLine: 1,
+				},
+			},
+		},
+		{
+			stmt: "GRANT ALL PRIVILEGES ON ALL SEQUENCES IN SCHEMA public TO CURRENT_USER",
+			want: []ast.Node{
+				&ast.GrantStmt{
+					PrivilegeList: []*ast.PrivilegeDef{
+						{Type: "ALL"},
+					},
+					Target: &ast.PrivilegeTargetDef{
+						Type:        ast.PrivilegeObjectTypeSequence,
+						AllInSchema: true,
+						NameList:    []string{"public"},
+					},
+					GranteeList: []string{"CURRENT_USER"},
+				},
+			},
+			statementList: []parser.SingleSQL{
+				{
+					Text:     "GRANT ALL PRIVILEGES ON ALL SEQUENCES IN SCHEMA public TO CURRENT_USER",
+					LastLine: 1,
+				},
+			},
+		},
+		{
+			stmt: "REVOKE GRANT OPTION FOR EXECUTE ON FUNCTION public.f(int) FROM u1 CASCADE",
+			want: []ast.Node{
+				&ast.RevokeStmt{
+					GrantOptionFor: true,
+					PrivilegeList: []*ast.PrivilegeDef{
+						{Type: "EXECUTE"},
+					},
+					Target: &ast.PrivilegeTargetDef{
+						Type: ast.PrivilegeObjectTypeFunction,
+						FunctionList: []*ast.FunctionDef{
+							{
+								Schema: "public",
+								Name:   "f",
+								ParameterList: []*ast.FunctionParameterDef{
+									{Type: &ast.Integer{Size: 4}, Mode: ast.FunctionParameterModeIn},
+								},
+							},
+						},
+					},
+					GranteeList: []string{"u1"},
+					Cascade:     true,
+				},
+			},
+			statementList: []parser.SingleSQL{
+				{
+					Text:     "REVOKE GRANT OPTION FOR EXECUTE ON FUNCTION public.f(int) FROM u1 CASCADE",
+					LastLine: 1,
+				},
+			},
+		},
+	}
+
+	runTests(t, tests)
+}
+
+func TestTruncateStmt(t *testing.T) {
+	tests := []testData{
+		{
+			stmt: "TRUNCATE TABLE t1, public.t2 RESTART IDENTITY CASCADE",
+			want: []ast.Node{
+				&ast.TruncateStmt{
+					TableList: []*ast.TableDef{
+						{Type: ast.TableTypeBaseTable, Name: "t1"},
+						{Type: ast.TableTypeBaseTable, Schema: "public", Name: "t2"},
+					},
+					OnlyList:        []bool{false, false},
+					RestartIdentity: true,
+					Cascade:         true,
+				},
+			},
+			statementList: []parser.SingleSQL{
+				{
+					Text:     "TRUNCATE TABLE t1, public.t2 RESTART IDENTITY CASCADE",
+					LastLine: 1,
+				},
+			},
+		},
+		{
+			stmt: "TRUNCATE ONLY t1, t2 *",
+			want: []ast.Node{
+				&ast.TruncateStmt{
+					TableList: []*ast.TableDef{
+						{Type: ast.TableTypeBaseTable, Name: "t1"},
+						{Type: ast.TableTypeBaseTable, Name: "t2"},
+					},
+					OnlyList: []bool{true, false},
+				},
+			},
+			statementList: []parser.SingleSQL{
+				{
+					Text:     "TRUNCATE ONLY t1, t2 *",
+					LastLine: 1,
+				},
+			},
+		},
+	}
+
+	runTests(t, tests)
+}
+
+func TestCreateSchemaStmt(t *testing.T) {
+	tests := []testData{
+		{
+			stmt: "CREATE SCHEMA IF NOT EXISTS s AUTHORIZATION u",
+			want: []ast.Node{
+				&ast.CreateSchemaStmt{
+					IfNotExists: true,
+					Name:        "s",
+					Owner:       "u",
+				},
+			},
+			statementList: []parser.SingleSQL{
+				{
+					Text:     "CREATE SCHEMA IF NOT EXISTS s AUTHORIZATION u",
+					LastLine: 1,
+				},
+			},
+		},
+	}
+
+	runTests(t, tests)
+}
+
+func TestVariableSetStmt(t *testing.T) {
+	tests := []testData{
+		{
+			stmt: "SET search_path TO s1, \"$user\", public",
+			want: []ast.Node{
+				&ast.VariableSetStmt{
+					Name:      "search_path",
+					ValueList: []string{"s1", "$user", "public"},
+				},
+			},
+			statementList: []parser.SingleSQL{
+				{
+					Text:     "SET search_path TO s1, \"$user\", public",
+					LastLine: 1,
+				},
+			},
+		},
+		{
+			stmt: "SET LOCAL lock_timeout = 100",
+			want: []ast.Node{
+				&ast.VariableSetStmt{
+					Name:      "lock_timeout",
+					ValueList: []string{"100"},
+					Local:     true,
+				},
+			},
+			statementList: []parser.SingleSQL{
+				{
+					Text:     "SET LOCAL lock_timeout = 100",
+					LastLine: 1,
+				},
+			},
+		},
+		{
+			stmt: "RESET search_path",
+			want: []ast.Node{
+				&ast.VariableSetStmt{
+					Name: "search_path",
+				},
+			},
+			statementList: []parser.SingleSQL{
+				{
+					Text:     "RESET search_path",
+					LastLine: 1,
+				},
+			},
+		},
+		{
+			stmt: "RESET ALL",
+			want: []ast.Node{&ast.UnconvertedStmt{}},
+			statementList: []parser.SingleSQL{
+				{
+					Text:     "RESET ALL",
+					LastLine: 1,
+				},
+			},
+		},
+	}
+
+	runTests(t, tests)
+}
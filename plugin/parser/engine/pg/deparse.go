@@ -21,6 +21,28 @@ func deparse(context parser.DeparseContext, in ast.Node, buf *strings.Builder) e
 		return deparseTableDef(context, node, buf)
 	case *ast.ColumnDef:
 		return deparseColumnDef(context, node, buf)
+	case *ast.CreateViewStmt:
+		return deparseCreateView(context, node, buf)
+	case *ast.CreateFunctionStmt:
+		return deparseCreateFunction(context, node, buf)
+	case *ast.CreateSequenceStmt:
+		return deparseCreateSequence(context, node, buf)
+	case *ast.AlterSequenceStmt:
+		return deparseAlterSequence(context, node, buf)
+	case *ast.CreateTypeStmt:
+		return deparseCreateType(context, node, buf)
+	case *ast.GrantStmt:
+		return deparseGrant(context, node, buf)
+	case *ast.RevokeStmt:
+		return deparseRevoke(context, node, buf)
+	case *ast.CreateTriggerStmt:
+		return deparseCreateTrigger(context, node, buf)
+	case *ast.TruncateStmt:
+		return deparseTruncate(context, node, buf)
+	case *ast.CreateExtensionStmt:
+		return deparseCreateExtension(context, node, buf)
+	case *ast.CreateSchemaStmt:
+		return deparseCreateSchema(context, node, buf)
 	}
 
 	return errors.Errorf("failed to deparse %T", in)
@@ -204,3 +226,484 @@ func writeSurrounding(buf *strings.Builder, s string, enclosure string) error {
 	}
 	return nil
 }
+
+func deparseCreateView(_ parser.DeparseContext, in *ast.CreateViewStmt, buf *strings.Builder) error {
+	var b strings.Builder
+	b.WriteString("CREATE ")
+	if in.Replace {
+		b.WriteString("OR REPLACE ")
+	}
+	b.WriteString("VIEW ")
+	b.WriteString(quoteName(in.Name.Schema, in.Name.Name))
+	if len(in.ColumnList) != 0 {
+		b.WriteString("(")
+		b.WriteString(quoteIdentifierList(in.ColumnList))
+		b.WriteString(")")
+	}
+	b.WriteString(" AS ")
+	b.WriteString(in.Definition)
+	switch in.CheckOption {
+	case ast.ViewCheckOptionLocal:
+		b.WriteString(" WITH LOCAL CHECK OPTION")
+	case ast.ViewCheckOptionCascaded:
+		b.WriteString(" WITH CASCADED CHECK OPTION")
+	}
+	_, err := buf.WriteString(b.String())
+	return err
+}
+
+func deparseCreateFunction(context parser.DeparseContext, in *ast.CreateFunctionStmt, buf *strings.Builder) error {
+	var b strings.Builder
+	b.WriteString("CREATE ")
+	if in.Replace {
+		b.WriteString("OR REPLACE ")
+	}
+	if in.IsProcedure {
+		b.WriteString("PROCEDURE ")
+	} else {
+		b.WriteString("FUNCTION ")
+	}
+	b.WriteString(quoteName(in.Function.Schema, in.Function.Name))
+
+	var parameterList, tableColumnList []string
+	for _, parameter := range in.Function.ParameterList {
+		tp, err := deparseDataTypeToString(context, parameter.Type)
+		if err != nil {
+			return err
+		}
+		var itemList []string
+		switch parameter.Mode {
+		case ast.FunctionParameterModeTable:
+			tableColumnList = append(tableColumnList, fmt.Sprintf("%s %s", quoteIdentifier(parameter.Name), tp))
+			continue
+		case ast.FunctionParameterModeOut:
+			itemList = append(itemList, "OUT")
+		case ast.FunctionParameterModeInOut:
+			itemList = append(itemList, "INOUT")
+		case ast.FunctionParameterModeVariadic:
+			itemList = append(itemList, "VARIADIC")
+		}
+		if parameter.Name != "" {
+			itemList = append(itemList, quoteIdentifier(parameter.Name))
+		}
+		itemList = append(itemList, tp)
+		parameterList = append(parameterList, strings.Join(itemList, " "))
+	}
+	b.WriteString("(")
+	b.WriteString(strings.Join(parameterList, ", "))
+	b.WriteString(")")
+
+	switch {
+	case len(tableColumnList) != 0:
+		b.WriteString(" RETURNS TABLE(")
+		b.WriteString(strings.Join(tableColumnList, ", "))
+		b.WriteString(")")
+	case in.ReturnType != nil:
+		tp, err := deparseDataTypeToString(context, in.ReturnType)
+		if err != nil {
+			return err
+		}
+		b.WriteString(" RETURNS ")
+		if in.ReturnSetOf {
+			b.WriteString("SETOF ")
+		}
+		b.WriteString(tp)
+	}
+	if in.Language != "" {
+		b.WriteString(" LANGUAGE ")
+		b.WriteString(in.Language)
+	}
+	if in.Volatility != "" {
+		b.WriteString(" ")
+		b.WriteString(in.Volatility)
+	}
+	if in.Strict {
+		b.WriteString(" STRICT")
+	}
+	if in.SecurityDefiner {
+		b.WriteString(" SECURITY DEFINER")
+	}
+	b.WriteString(" AS ")
+	b.WriteString(quoteDollar(in.Body))
+	_, err := buf.WriteString(b.String())
+	return err
+}
+
+func deparseCreateSequence(context parser.DeparseContext, in *ast.CreateSequenceStmt, buf *strings.Builder) error {
+	var b strings.Builder
+	b.WriteString("CREATE SEQUENCE ")
+	if in.IfNotExists {
+		b.WriteString("IF NOT EXISTS ")
+	}
+	b.WriteString(quoteName(in.SequenceDef.SequenceName.Schema, in.SequenceDef.SequenceName.Name))
+	optionList, err := sequenceOptionList(context, &in.SequenceDef)
+	if err != nil {
+		return err
+	}
+	if in.SequenceDef.Cycle {
+		optionList = append(optionList, "CYCLE")
+	}
+	optionList = append(optionList, sequenceOwnedBy(&in.SequenceDef)...)
+	for _, option := range optionList {
+		b.WriteString(" ")
+		b.WriteString(option)
+	}
+	_, err = buf.WriteString(b.String())
+	return err
+}
+
+func deparseAlterSequence(context parser.DeparseContext, in *ast.AlterSequenceStmt, buf *strings.Builder) error {
+	var b strings.Builder
+	b.WriteString("ALTER SEQUENCE ")
+	if in.IfExists {
+		b.WriteString("IF EXISTS ")
+	}
+	b.WriteString(quoteName(in.SequenceDef.SequenceName.Schema, in.SequenceDef.SequenceName.Name))
+	optionList, err := sequenceOptionList(context, &in.SequenceDef)
+	if err != nil {
+		return err
+	}
+	if in.Restart {
+		if in.RestartWith != nil {
+			optionList = append(optionList, fmt.Sprintf("RESTART WITH %d", *in.RestartWith))
+		} else {
+			optionList = append(optionList, "RESTART")
+		}
+	}
+	switch {
+	case in.SequenceDef.Cycle:
+		optionList = append(optionList, "CYCLE")
+	case in.NoCycle:
+		optionList = append(optionList, "NO CYCLE")
+	}
+	optionList = append(optionList, sequenceOwnedBy(&in.SequenceDef)...)
+	for _, option := range optionList {
+		b.WriteString(" ")
+		b.WriteString(option)
+	}
+	_, err = buf.WriteString(b.String())
+	return err
+}
+
+// sequenceOptionList returns the options of the sequence except CYCLE and OWNED BY.
+func sequenceOptionList(context parser.DeparseContext, in *ast.SequenceDef) ([]string, error) {
+	var optionList []string
+	if in.SequenceDataType != nil {
+		tp, err := deparseDataTypeToString(context, in.SequenceDataType)
+		if err != nil {
+			return nil, err
+		}
+		optionList = append(optionList, fmt.Sprintf("AS %s", tp))
+	}
+	if in.IncrementBy != nil {
+		optionList = append(optionList, fmt.Sprintf("INCREMENT BY %d", *in.IncrementBy))
+	}
+	if in.NoMinValue {
+		optionList = append(optionList, "NO MINVALUE")
+	} else if in.MinValue != nil {
+		optionList = append(optionList, fmt.Sprintf("MINVALUE %d", *in.MinValue))
+	}
+	if in.NoMaxValue {
+		optionList = append(optionList, "NO MAXVALUE")
+	} else if in.MaxValue != nil {
+		optionList = append(optionList, fmt.Sprintf("MAXVALUE %d", *in.MaxValue))
+	}
+	if in.StartWith != nil {
+		optionList = append(optionList, fmt.Sprintf("START WITH %d", *in.StartWith))
+	}
+	if in.Cache != nil {
+		optionList = append(optionList, fmt.Sprintf("CACHE %d", *in.Cache))
+	}
+	return optionList, nil
+}
+
+func sequenceOwnedBy(in *ast.SequenceDef) []string {
+	switch {
+	case in.OwnedByNone:
+		return []string{"OWNED BY NONE"}
+	case in.OwnedBy != nil:
+		return []string{fmt.Sprintf("OWNED BY %s.%s", quoteName(in.OwnedBy.Table.Schema, in.OwnedBy.Table.Name), quoteIdentifier(in.OwnedBy.ColumnName))}
+	}
+	return nil
+}
+
+func deparseCreateType(_ parser.DeparseContext, in *ast.CreateTypeStmt, buf *strings.Builder) error {
+	switch tp := in.Type.(type) {
+	case *ast.EnumTypeDef:
+		var labelList []string
+		for _, label := range tp.LabelList {
+			labelList = append(labelList, quoteLiteral(label))
+		}
+		_, err := buf.WriteString(fmt.Sprintf("CREATE TYPE %s AS ENUM (%s)", quoteName(tp.Name.Schema, tp.Name.Name), strings.Join(labelList, ", ")))
+		return err
+	default:
+		return errors.Errorf("failed to deparse user defined type %T", in.Type)
+	}
+}
+
+func deparseGrant(context parser.DeparseContext, in *ast.GrantStmt, buf *strings.Builder) error {
+	target, err := deparsePrivilegeTarget(context, in.PrivilegeList, in.Target)
+	if err != nil {
+		return err
+	}
+	var b strings.Builder
+	b.WriteString("GRANT ")
+	b.WriteString(target)
+	b.WriteString(" TO ")
+	b.WriteString(quoteRoleList(in.GranteeList))
+	if in.WithGrantOption {
+		b.WriteString(" WITH GRANT OPTION")
+	}
+	_, err = buf.WriteString(b.String())
+	return err
+}
+
+func deparseRevoke(context parser.DeparseContext, in *ast.RevokeStmt, buf *strings.Builder) error {
+	target, err := deparsePrivilegeTarget(context, in.PrivilegeList, in.Target)
+	if err != nil {
+		return err
+	}
+	var b strings.Builder
+	b.WriteString("REVOKE ")
+	if in.GrantOptionFor {
+		b.WriteString("GRANT OPTION FOR ")
+	}
+	b.WriteString(target)
+	b.WriteString(" FROM ")
+	b.WriteString(quoteRoleList(in.GranteeList))
+	if in.Cascade {
+		b.WriteString(" CASCADE")
+	}
+	_, err = buf.WriteString(b.String())
+	return err
+}
+
+// deparsePrivilegeTarget returns the privileges and the objects of the GRANT and REVOKE statements, e.g. SELECT ON TABLE "t".
+func deparsePrivilegeTarget(context parser.DeparseContext, privilegeList []*ast.PrivilegeDef, in *ast.PrivilegeTargetDef) (string, error) {
+	var itemList []string
+	for _, privilege := range privilegeList {
+		item := privilege.Type
+		if len(privilege.ColumnList) != 0 {
+			item = fmt.Sprintf("%s (%s)", item, quoteIdentifierList(privilege.ColumnList))
+		}
+		itemList = append(itemList, item)
+	}
+
+	var objectType string
+	switch in.Type {
+	case ast.PrivilegeObjectTypeTable:
+		objectType = "TABLE"
+	case ast.PrivilegeObjectTypeSequence:
+		objectType = "SEQUENCE"
+	case ast.PrivilegeObjectTypeDatabase:
+		objectType = "DATABASE"
+	case ast.PrivilegeObjectTypeSchema:
+		objectType = "SCHEMA"
+	case ast.PrivilegeObjectTypeFunction:
+		objectType = "FUNCTION"
+	case ast.PrivilegeObjectTypeProcedure:
+		objectType = "PROCEDURE"
+	case ast.PrivilegeObjectTypeType:
+		objectType = "TYPE"
+	default:
+		return "", errors.Errorf("failed to deparse privilege object type %d", in.Type)
+	}
+	if in.AllInSchema {
+		// The plural forms are TABLES, SEQUENCES, FUNCTIONS and PROCEDURES.
+		return fmt.Sprintf("%s ON ALL %sS IN SCHEMA %s", strings.Join(itemList, ", "), objectType, quoteIdentifierList(in.NameList)), nil
+	}
+
+	var objectList []string
+	for _, table := range in.TableList {
+		objectList = append(objectList, quoteName(table.Schema, table.Name))
+	}
+	for _, sequence := range in.SequenceList {
+		objectList = append(objectList, quoteName(sequence.Schema, sequence.Name))
+	}
+	for _, function := range in.FunctionList {
+		var typeList []string
+		for _, parameter := range function.ParameterList {
+			tp, err := deparseDataTypeToString(context, parameter.Type)
+			if err != nil {
+				return "", err
+			}
+			typeList = append(typeList, tp)
+		}
+		objectList = append(objectList, fmt.Sprintf("%s(%s)", quoteName(function.Schema, function.Name), strings.Join(typeList, ", ")))
+	}
+	for _, tp := range in.TypeList {
+		objectList = append(objectList, quoteName(tp.Schema, tp.Name))
+	}
+	for _, name := range in.NameList {
+		objectList = append(objectList, quoteIdentifier(name))
+	}
+	return fmt.Sprintf("%s ON %s %s", strings.Join(itemList, ", "), objectType, strings.Join(objectList, ", ")), nil
+}
+
+func deparseCreateTrigger(_ parser.DeparseContext, in *ast.CreateTriggerStmt, buf *strings.Builder) error {
+	trigger := in.Trigger
+	var b strings.Builder
+	b.WriteString("CREATE TRIGGER ")
+	b.WriteString(quoteIdentifier(trigger.Name))
+	switch trigger.Timing {
+	case ast.TriggerTimingBefore:
+		b.WriteString(" BEFORE ")
+	case ast.TriggerTimingInsteadOf:
+		b.WriteString(" INSTEAD OF ")
+	default:
+		b.WriteString(" AFTER ")
+	}
+	var eventList []string
+	for _, event := range trigger.EventList {
+		switch event {
+		case ast.TriggerEventInsert:
+			eventList = append(eventList, "INSERT")
+		case ast.TriggerEventUpdate:
+			if len(trigger.UpdateColumnList) != 0 {
+				eventList = append(eventList, fmt.Sprintf("UPDATE OF %s", quoteIdentifierList(trigger.UpdateColumnList)))
+			} else {
+				eventList = append(eventList, "UPDATE")
+			}
+		case ast.TriggerEventDelete:
+			eventList = append(eventList, "DELETE")
+		case ast.TriggerEventTruncate:
+			eventList = append(eventList, "TRUNCATE")
+		}
+	}
+	b.WriteString(strings.Join(eventList, " OR "))
+	b.WriteString(" ON ")
+	b.WriteString(quoteName(trigger.Table.Schema, trigger.Table.Name))
+	if trigger.ForEachRow {
+		b.WriteString(" FOR EACH ROW")
+	} else {
+		b.WriteString(" FOR EACH STATEMENT")
+	}
+	if trigger.When != "" {
+		b.WriteString(" WHEN (")
+		b.WriteString(trigger.When)
+		b.WriteString(")")
+	}
+	var argumentList []string
+	for _, argument := range trigger.ArgumentList {
+		argumentList = append(argumentList, quoteLiteral(argument))
+	}
+	b.WriteString(fmt.Sprintf(" EXECUTE FUNCTION %s(%s)", quoteName(trigger.Function.Schema, trigger.Function.Name), strings.Join(argumentList, ", ")))
+	_, err := buf.WriteString(b.String())
+	return err
+}
+
+func deparseTruncate(_ parser.DeparseContext, in *ast.TruncateStmt, buf *strings.Builder) error {
+	var tableList []string
+	for i, table := range in.TableList {
+		name := quoteName(table.Schema, table.Name)
+		if i < len(in.OnlyList) && in.OnlyList[i] {
+			name = "ONLY " + name
+		}
+		tableList = append(tableList, name)
+	}
+	var b strings.Builder
+	b.WriteString("TRUNCATE TABLE ")
+	b.WriteString(strings.Join(tableList, ", "))
+	if in.RestartIdentity {
+		b.WriteString(" RESTART IDENTITY")
+	}
+	if in.Cascade {
+		b.WriteString(" CASCADE")
+	}
+	_, err := buf.WriteString(b.String())
+	return err
+}
+
+func deparseCreateExtension(_ parser.DeparseContext, in *ast.CreateExtensionStmt, buf *strings.Builder) error {
+	var b strings.Builder
+	b.WriteString("CREATE EXTENSION ")
+	if in.IfNotExists {
+		b.WriteString("IF NOT EXISTS ")
+	}
+	b.WriteString(quoteIdentifier(in.Name))
+	if in.Schema != "" {
+		b.WriteString(" WITH SCHEMA ")
+		b.WriteString(quoteIdentifier(in.Schema))
+	}
+	if in.Version != "" {
+		b.WriteString(" VERSION ")
+		b.WriteString(quoteLiteral(in.Version))
+	}
+	_, err := buf.WriteString(b.String())
+	return err
+}
+
+func deparseCreateSchema(_ parser.DeparseContext, in *ast.CreateSchemaStmt, buf *strings.Builder) error {
+	var b strings.Builder
+	b.WriteString("CREATE SCHEMA")
+	if in.IfNotExists {
+		b.WriteString(" IF NOT EXISTS")
+	}
+	if in.Name != "" {
+		b.WriteString(" ")
+		b.WriteString(quoteIdentifier(in.Name))
+	}
+	if in.Owner != "" {
+		b.WriteString(" AUTHORIZATION ")
+		b.WriteString(quoteRole(in.Owner))
+	}
+	_, err := buf.WriteString(b.String())
+	return err
+}
+
+func deparseDataTypeToString(context parser.DeparseContext, in ast.DataType) (string, error) {
+	var buf strings.Builder
+	if err := deparseDataType(context, in, &buf); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func quoteIdentifier(s string) string {
+	return fmt.Sprintf(`"%s"`, strings.ReplaceAll(s, `"`, `""`))
+}
+
+func quoteIdentifierList(list []string) string {
+	var quotedList []string
+	for _, s := range list {
+		quotedList = append(quotedList, quoteIdentifier(s))
+	}
+	return strings.Join(quotedList, ", ")
+}
+
+func quoteName(schema string, name string) string {
+	if schema == "" {
+		return quoteIdentifier(name)
+	}
+	return fmt.Sprintf("%s.%s", quoteIdentifier(schema), quoteIdentifier(name))
+}
+
+func quoteLiteral(s string) string {
+	return fmt.Sprintf("'%s'", strings.ReplaceAll(s, "'", "''"))
+}
+
+// quoteDollar returns the dollar-quoted string, whose tag doesn't appear in the string.
+func quoteDollar(s string) string {
+	tag := "$$"
+	for i := 0; strings.Contains(s, tag); i++ {
+		tag = fmt.Sprintf("$body%d$", i)
+	}
+	return tag + s + tag
+}
+
+// quoteRole quotes the role name except the special roles.
+func quoteRole(role string) string {
+	switch role {
+	case "PUBLIC", "CURRENT_USER", "SESSION_USER":
+		return role
+	}
+	return quoteIdentifier(role)
+}
+
+func quoteRoleList(list []string) string {
+	var quotedList []string
+	for _, role := range list {
+		quotedList = append(quotedList, quoteRole(role))
+	}
+	return strings.Join(quotedList, ", ")
+}
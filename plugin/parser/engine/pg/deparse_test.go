@@ -6,6 +6,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/bytebase/bytebase/plugin/parser"
+	"github.com/bytebase/bytebase/plugin/parser/ast"
 )
 
 type testDeparseData struct {
@@ -80,3 +81,102 @@ func TestCreateTable(t *testing.T) {
 
 	runDeparseTest(t, tests)
 }
+
+func TestDeparseDDL(t *testing.T) {
+	tests := []testDeparseData{
+		{
+			stmt: "CREATE OR REPLACE VIEW public.v (a, b) AS SELECT id, name FROM t WHERE id > 1 WITH LOCAL CHECK OPTION",
+			want: `CREATE OR REPLACE VIEW "public"."v"("a", "b") AS SELECT id, name FROM t WHERE id > 1 WITH LOCAL CHECK OPTION`,
+		},
+		{
+			stmt: "CREATE FUNCTION f(a int, OUT b bigint) RETURNS SETOF int LANGUAGE sql IMMUTABLE STRICT SECURITY DEFINER AS 'SELECT $$1$$'",
+			want: `CREATE FUNCTION "f"("a" INT4, OUT "b" INT8) RETURNS SETOF INT4 LANGUAGE sql IMMUTABLE STRICT SECURITY DEFINER AS $body0$SELECT $$1$$$body0$`,
+		},
+		{
+			stmt: "CREATE PROCEDURE s.p() LANGUAGE plpgsql AS $$ BEGIN END $$",
+			want: `CREATE PROCEDURE "s"."p"() LANGUAGE plpgsql AS $$ BEGIN END $$`,
+		},
+		{
+			stmt: "CREATE FUNCTION g() RETURNS TABLE(a int) LANGUAGE sql AS $$ SELECT 1 $$",
+			want: `CREATE FUNCTION "g"() RETURNS TABLE("a" INT4) LANGUAGE sql AS $$ SELECT 1 $$`,
+		},
+		{
+			stmt: "CREATE SEQUENCE IF NOT EXISTS public.seq AS bigint INCREMENT BY 2 NO MINVALUE MAXVALUE 100 START WITH 3 CACHE 5 CYCLE OWNED BY t.id",
+			want: `CREATE SEQUENCE IF NOT EXISTS "public"."seq" AS INT8 INCREMENT BY 2 NO MINVALUE MAXVALUE 100 START WITH 3 CACHE 5 CYCLE OWNED BY "t"."id"`,
+		},
+		{
+			stmt: "ALTER SEQUENCE seq NO CYCLE RESTART OWNED BY NONE",
+			want: `ALTER SEQUENCE "seq" RESTART NO CYCLE OWNED BY NONE`,
+		},
+		{
+			stmt: "CREATE TYPE public.mood AS ENUM ('sad', 'it''s ok')",
+			want: `CREATE TYPE "public"."mood" AS ENUM ('sad', 'it''s ok')`,
+		},
+		{
+			stmt: "GRANT SELECT, UPDATE (a) ON t1, public.t2 TO u1, PUBLIC WITH GRANT OPTION",
+			want: `GRANT SELECT, UPDATE ("a") ON TABLE "t1", "public"."t2" TO "u1", PUBLIC WITH GRANT OPTION`,
+		},
+		{
+			stmt: "GRANT USAGE ON TYPE public.mood TO u1",
+			want: `GRANT USAGE ON TYPE "public"."mood" TO "u1"`,
+		},
+		{
+			stmt: "REVOKE GRANT OPTION FOR ALL ON ALL TABLES IN SCHEMA public FROM u1 CASCADE",
+			want: `REVOKE GRANT OPTION FOR ALL ON ALL TABLES IN SCHEMA "public" FROM "u1" CASCADE`,
+		},
+		{
+			stmt: "REVOKE CONNECT ON DATABASE db FROM SESSION_USER",
+			want: `REVOKE CONNECT ON DATABASE "db" FROM SESSION_USER`,
+		},
+		{
+			stmt: "CREATE TRIGGER tr BEFORE INSERT OR UPDATE OF a, b ON public.t FOR EACH ROW WHEN (NEW.a > 0) EXECUTE FUNCTION f('x')",
+			want: `CREATE TRIGGER "tr" BEFORE INSERT OR UPDATE OF "a", "b" ON "public"."t" FOR EACH ROW WHEN (new.a > 0) EXECUTE FUNCTION "f"('x')`,
+		},
+		{
+			stmt: "CREATE TRIGGER tr AFTER TRUNCATE ON t EXECUTE PROCEDURE s.f()",
+			want: `CREATE TRIGGER "tr" AFTER TRUNCATE ON "t" FOR EACH STATEMENT EXECUTE FUNCTION "s"."f"()`,
+		},
+		{
+			stmt: "TRUNCATE t1, public.t2 RESTART IDENTITY CASCADE",
+			want: `TRUNCATE TABLE "t1", "public"."t2" RESTART IDENTITY CASCADE`,
+		},
+		{
+			stmt: "TRUNCATE ONLY t1, public.t2",
+			want: `TRUNCATE TABLE ONLY "t1", "public"."t2"`,
+		},
+		{
+			stmt: "CREATE EXTENSION IF NOT EXISTS pgcrypto WITH SCHEMA public VERSION '1.3'",
+			want: `CREATE EXTENSION IF NOT EXISTS "pgcrypto" WITH SCHEMA "public" VERSION '1.3'`,
+		},
+		{
+			stmt: "CREATE SCHEMA AUTHORIZATION CURRENT_USER",
+			want: `CREATE SCHEMA AUTHORIZATION CURRENT_USER`,
+		},
+	}
+
+	runDeparseTest(t, tests)
+}
+
+func TestTruncateRoundTrip(t *testing.T) {
+	p := &PostgreSQLParser{}
+	tests := []string{
+		"TRUNCATE ONLY t1, public.t2 RESTART IDENTITY CASCADE",
+		"TRUNCATE TABLE t1, ONLY t2",
+	}
+
+	for _, stmt := range tests {
+		nodeList, err := p.Parse(parser.ParseContext{}, stmt)
+		require.NoError(t, err)
+		require.Len(t, nodeList, 1)
+		deparsed, err := p.Deparse(parser.DeparseContext{}, nodeList[0])
+		require.NoError(t, err)
+		// Parsing the deparsed statement gets the same node.
+		reparsedList, err := p.Parse(parser.ParseContext{}, deparsed)
+		require.NoError(t, err)
+		require.Len(t, reparsedList, 1)
+		redeparsed, err := p.Deparse(parser.DeparseContext{}, reparsedList[0])
+		require.NoError(t, err)
+		require.Equal(t, deparsed, redeparsed, stmt)
+		require.Equal(t, nodeList[0].(*ast.TruncateStmt).OnlyList, reparsedList[0].(*ast.TruncateStmt).OnlyList, stmt)
+	}
+}
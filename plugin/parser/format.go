@@ -0,0 +1,802 @@
+package parser
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// KeywordCase is the case of the keywords in the formatted statement.
+type KeywordCase string
+
+const (
+	// KeywordCaseUpper converts the keywords to upper case.
+	KeywordCaseUpper KeywordCase = "UPPER"
+	// KeywordCaseLower converts the keywords to lower case.
+	KeywordCaseLower KeywordCase = "LOWER"
+	// KeywordCasePreserve keeps the keywords as they are.
+	KeywordCasePreserve KeywordCase = "PRESERVE"
+)
+
+// CommaPosition is the position of the commas in the lists broken into multiple lines.
+type CommaPosition string
+
+const (
+	// CommaPositionTrailing places the comma at the end of the line.
+	CommaPositionTrailing CommaPosition = "TRAILING"
+	// CommaPositionLeading places the comma at the beginning of the next line.
+	CommaPositionLeading CommaPosition = "LEADING"
+)
+
+const (
+	// DefaultFormatIndentWidth is the default number of spaces for one level of indentation.
+	DefaultFormatIndentWidth = 2
+	// maxFormatIndentWidth is the maximum number of spaces for one level of indentation.
+	maxFormatIndentWidth = 8
+)
+
+// FormatOption is the option of the SQL formatter.
+type FormatOption struct {
+	// KeywordCase is the case of the keywords, the default is KeywordCaseUpper.
+	KeywordCase KeywordCase `json:"keywordCase"`
+	// IndentWidth is the number of spaces for one level of indentation, the default is DefaultFormatIndentWidth.
+	IndentWidth int `json:"indentWidth"`
+	// CommaPosition is the position of the commas in the lists broken into multiple lines, the default is CommaPositionTrailing.
+	CommaPosition CommaPosition `json:"commaPosition"`
+}
+
+// Format formats the statement, which may contain multiple SQL statements, and keeps the comments.
+// The clauses of the queries and the definitions of CREATE TABLE statements are broken into lines and indented.
+// The other statements are kept in one line with normalized spacing and keyword case.
+func Format(engineType EngineType, statement string, option FormatOption) (string, error) {
+	if option.KeywordCase == "" {
+		option.KeywordCase = KeywordCaseUpper
+	}
+	if option.IndentWidth == 0 {
+		option.IndentWidth = DefaultFormatIndentWidth
+	}
+	if option.CommaPosition == "" {
+		option.CommaPosition = CommaPositionTrailing
+	}
+	switch option.KeywordCase {
+	case KeywordCaseUpper, KeywordCaseLower, KeywordCasePreserve:
+	default:
+		return "", errors.Errorf("invalid keyword case %q", option.KeywordCase)
+	}
+	switch option.CommaPosition {
+	case CommaPositionTrailing, CommaPositionLeading:
+	default:
+		return "", errors.Errorf("invalid comma position %q", option.CommaPosition)
+	}
+	if option.IndentWidth < 0 || option.IndentWidth > maxFormatIndentWidth {
+		return "", errors.Errorf("indent width must be between 0 and %d, but got %d", maxFormatIndentWidth, option.IndentWidth)
+	}
+	switch engineType {
+	case MySQL, TiDB, Postgres:
+	default:
+		return "", errors.Errorf("engine type is not supported: %s", engineType)
+	}
+
+	tokenList, err := tokenizeForFormat(engineType, statement)
+	if err != nil {
+		return "", err
+	}
+	f := &formatter{
+		option:              option,
+		tokenList:           tokenList,
+		nonReservedKeywords: formatNonReservedKeywordSet[engineType],
+	}
+	return f.format(), nil
+}
+
+type formatTokenKind int
+
+const (
+	wordToken formatTokenKind = iota
+	// stringToken is the string literals, the quoted identifiers and the dollar-quoted strings, which are kept as they are.
+	stringToken
+	commentToken
+	punctuationToken
+	operatorToken
+)
+
+type formatToken struct {
+	kind formatTokenKind
+	text string
+	// spaceBefore is true if there are blank characters before the token in the statement.
+	spaceBefore bool
+	// newlineBefore is the number of newlines before the token in the statement.
+	newlineBefore int
+}
+
+// multiCharOperatorList is the operators with multiple characters, the longer ones come first.
+var multiCharOperatorList = []string{
+	"->>", "#>>", "<=>", "!~*", "~~*", "!~~",
+	"::", "<=", ">=", "<>", "!=", "||", "->", "#>", "@>", "<@", ":=", "&&", "<<", ">>", "~~", "!~", "~*",
+}
+
+func tokenizeForFormat(engineType EngineType, statement string) ([]*formatToken, error) {
+	t := newTokenizer(statement)
+	var tokenList []*formatToken
+	// The newline consumed by a line comment belongs to the next token.
+	newlineAfterComment := 0
+	for {
+		blankStart := t.pos()
+		newlineBefore := newlineAfterComment
+		newlineAfterComment = 0
+		for emptyRune(t.char(0)) {
+			if t.char(0) == '\n' {
+				newlineBefore++
+			}
+			t.skip(1)
+		}
+		if t.char(0) == eofRune {
+			return tokenList, nil
+		}
+
+		token := &formatToken{
+			spaceBefore:   t.pos() > blankStart || newlineBefore > 0,
+			newlineBefore: newlineBefore,
+		}
+		startPos := t.pos()
+		c := t.char(0)
+		switch {
+		case c == '-' && t.char(1) == '-', c == '/' && t.char(1) == '*', c == '#' && engineType != Postgres:
+			if err := t.scanComment(); err != nil {
+				return nil, err
+			}
+			token.kind = commentToken
+		case c == '\'':
+			if err := t.scanString('\''); err != nil {
+				return nil, err
+			}
+			token.kind = stringToken
+		case c == '"' && engineType == Postgres:
+			if err := t.scanIdentifier('"'); err != nil {
+				return nil, err
+			}
+			token.kind = stringToken
+		case c == '"':
+			// MySQL allows enclosing strings within double quotes.
+			if err := t.scanString('"'); err != nil {
+				return nil, err
+			}
+			token.kind = stringToken
+		case c == '`' && engineType != Postgres:
+			if err := t.scanIdentifier('`'); err != nil {
+				return nil, err
+			}
+			token.kind = stringToken
+		case c == '$' && engineType == Postgres && t.isDollarQuoteStart():
+			if err := t.scanDoubleDollarQuotedString(); err != nil {
+				return nil, err
+			}
+			token.kind = stringToken
+		case isFormatWordRune(c) || c == '@' || c == '$':
+			t.scanWord()
+			token.kind = wordToken
+		case strings.ContainsRune("(),;.[]", c):
+			t.skip(1)
+			token.kind = punctuationToken
+		default:
+			t.skip(uint(len([]rune(t.operatorAt()))))
+			token.kind = operatorToken
+		}
+		token.text = t.getString(startPos, t.pos()-startPos)
+		if token.kind == commentToken && strings.HasSuffix(token.text, "\n") {
+			token.text = strings.TrimRight(token.text, "\r\n")
+			newlineAfterComment = 1
+		}
+		tokenList = append(tokenList, token)
+	}
+}
+
+// isDollarQuoteStart returns true if the cursor is at the beginning of a dollar-quoted string, i.e. $$ or $tag$.
+// The positional parameters such as $1 are not dollar-quoted strings.
+func (t *tokenizer) isDollarQuoteStart() bool {
+	if t.char(1) == '$' {
+		return true
+	}
+	if unicode.IsDigit(t.char(1)) {
+		return false
+	}
+	i := uint(1)
+	for unicode.IsLetter(t.char(i)) || unicode.IsDigit(t.char(i)) || t.char(i) == '_' {
+		i++
+	}
+	return i > 1 && t.char(i) == '$'
+}
+
+// scanWord scans the keywords, identifiers, numbers, variables and parameters.
+func (t *tokenizer) scanWord() {
+	if unicode.IsDigit(t.char(0)) {
+		for unicode.IsDigit(t.char(0)) {
+			t.skip(1)
+		}
+		if t.char(0) == '.' && unicode.IsDigit(t.char(1)) {
+			t.skip(1)
+			for unicode.IsDigit(t.char(0)) {
+				t.skip(1)
+			}
+		}
+		if (t.char(0) == 'e' || t.char(0) == 'E') && (unicode.IsDigit(t.char(1)) || (t.char(1) == '-' || t.char(1) == '+') && unicode.IsDigit(t.char(2))) {
+			t.skip(2)
+			for unicode.IsDigit(t.char(0)) {
+				t.skip(1)
+			}
+		}
+	}
+	for t.char(0) == '@' {
+		t.skip(1)
+	}
+	for isFormatWordRune(t.char(0)) || t.char(0) == '$' {
+		t.skip(1)
+	}
+}
+
+// operatorAt returns the operator at the cursor, and the unknown characters are returned as single character operators.
+func (t *tokenizer) operatorAt() string {
+	for _, operator := range multiCharOperatorList {
+		if t.equalWordCaseInsensitive([]rune(operator)) {
+			return operator
+		}
+	}
+	return string(t.char(0))
+}
+
+func isFormatWordRune(r rune) bool {
+	return r != eofRune && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '?')
+}
+
+type frameKind int
+
+const (
+	// statementFrame is a statement or a subquery, whose clauses are broken into lines.
+	statementFrame frameKind = iota
+	// listFrame is the definition list of CREATE TABLE statements, whose items are broken into lines.
+	listFrame
+	// inlineFrame is the other parentheses such as function calls and column lists, which are kept in one line.
+	inlineFrame
+)
+
+type frame struct {
+	kind frameKind
+	// baseIndent is the indent level of the clause keywords, and the items of the clauses or the list are indented one more level.
+	baseIndent int
+	// closeIndent is the indent level of the closing parenthesis.
+	closeIndent int
+
+	// The following fields are only used by the statement frames.
+	// firstWord is the first word of the statement or the subquery in upper case.
+	firstWord string
+	// query is true if the statement is a query or a DML statement, whose clauses are broken into lines.
+	query bool
+	// clause is the current clause keyword in upper case, e.g. "SELECT" or "GROUP BY".
+	clause string
+	// itemBreakPending is true if the next token is the first item of a clause and starts a new line.
+	itemBreakPending bool
+	// between is true after BETWEEN before its paired AND.
+	between bool
+	// createTable is true for the CREATE TABLE statements, and listOpened is true after the definition list is opened.
+	createTable bool
+	listOpened  bool
+}
+
+var (
+	formatKeywordSet = newWordSet(`
+		ADD ALL ALTER AND ANY AS ASC AUTO_INCREMENT BEGIN BETWEEN BIGINT BINARY BLOB BOOLEAN BOTH BY CASCADE
+		CASE CAST CHANGE CHAR CHARACTER CHARSET CHECK COLLATE COLUMN COMMENT COMMIT CONFLICT CONSTRAINT
+		CREATE CROSS CURRENT_DATE CURRENT_TIME CURRENT_TIMESTAMP DATABASE DATETIME DECIMAL DEFAULT DELETE
+		DESC DISTINCT DO DOUBLE DROP DUPLICATE ELSE END ENGINE ESCAPE EXCEPT EXISTS EXPLAIN EXTENSION FALSE
+		FETCH FIRST FLOAT FOR FOREIGN FROM FULL FUNCTION GRANT GROUP HAVING IF IGNORE ILIKE IN INDEX INNER
+		INSERT INT INTEGER INTERSECT INTERVAL INTO IS JOIN JSON JSONB KEY LEFT LIKE LIMIT MODIFY NATURAL NOT
+		NULL NUMERIC OFFSET ON OR ORDER OUTER OVER PARTITION PRIMARY PROCEDURE RECURSIVE REFERENCES RENAME
+		REPLACE RESTRICT RETURNING RETURNS REVOKE RIGHT ROLLBACK SCHEMA SELECT SEQUENCE SERIAL SET SMALLINT
+		TABLE TEMPORARY TEXT THEN TIMESTAMP TINYINT TO TRIGGER TRUE TRUNCATE UNION UNIQUE UNSIGNED UPDATE
+		USING VALUES VARCHAR VIEW WHEN WHERE WINDOW WITH
+	`)
+	// formatNonReservedKeywordSet is the keywords which are not reserved in the engine and may be unquoted identifiers,
+	// e.g. "comment" and "json" in MySQL. They are only formatted as keywords if they are not in the position of identifiers.
+	formatNonReservedKeywordSet = map[EngineType]map[string]bool{
+		MySQL:    mysqlNonReservedKeywordSet,
+		TiDB:     mysqlNonReservedKeywordSet,
+		Postgres: postgresNonReservedKeywordSet,
+	}
+	mysqlNonReservedKeywordSet = newWordSet(`
+		ANY AUTO_INCREMENT BEGIN BOOLEAN CAST CHARSET COMMENT COMMIT CONFLICT DATETIME DO DUPLICATE END ENGINE ESCAPE
+		EXCEPT EXTENSION FIRST FULL FUNCTION ILIKE INTERSECT JSON JSONB MODIFY OFFSET RETURNING RETURNS ROLLBACK SEQUENCE
+		SERIAL TEMPORARY TEXT TIMESTAMP TRUNCATE VIEW
+	`)
+	postgresNonReservedKeywordSet = newWordSet(`
+		ADD ALTER AUTO_INCREMENT BEGIN BETWEEN BIGINT BLOB BOOLEAN BY CASCADE CHANGE CHAR CHARACTER CHARSET COMMENT
+		COMMIT CONFLICT DATABASE DATETIME DECIMAL DELETE DOUBLE DROP DUPLICATE ENGINE ESCAPE EXISTS EXPLAIN EXTENSION
+		FIRST FLOAT FUNCTION IF IGNORE INDEX INSERT INT INTEGER INTERVAL JSON JSONB KEY MODIFY NUMERIC OVER PARTITION
+		PROCEDURE RECURSIVE RENAME REPLACE RESTRICT RETURNS REVOKE ROLLBACK SCHEMA SEQUENCE SERIAL SET SMALLINT
+		TEMPORARY TEXT TIMESTAMP TINYINT TRIGGER TRUNCATE UNSIGNED UPDATE VALUES VARCHAR VIEW
+	`)
+	// identifierLeadingWordSet is the words followed by identifiers, e.g. the table names after FROM,
+	// or by expressions which may start with identifiers, e.g. the column names after WHERE.
+	identifierLeadingWordSet = newWordSet(`
+		ADD AND AS BY CHANGE COLUMN CONSTRAINT DATABASE DISTINCT ELSE EXISTS EXTENSION FROM FUNCTION HAVING INDEX INTO
+		JOIN MODIFY OR PROCEDURE REFERENCES RETURNING SCHEMA SELECT SEQUENCE SET TABLE THEN TO TRIGGER TRUNCATE UPDATE
+		VIEW WHEN WHERE WITH
+	`)
+	// queryStartWordSet is the first words of the statements whose clauses are broken into lines.
+	queryStartWordSet = map[string]bool{
+		"SELECT": true, "WITH": true, "INSERT": true, "REPLACE": true, "UPDATE": true, "DELETE": true, "VALUES": true, "EXPLAIN": true,
+	}
+	// listClauseSet is the clauses whose items are broken into lines at the commas.
+	listClauseSet = map[string]bool{
+		"SELECT": true, "FROM": true, "GROUP BY": true, "ORDER BY": true, "SET": true, "VALUES": true, "RETURNING": true, "WINDOW": true,
+	}
+	// conditionClauseSet is the clauses whose conditions are broken into lines at AND and OR.
+	conditionClauseSet = map[string]bool{
+		"WHERE": true, "HAVING": true, "JOIN": true,
+	}
+	joinWordSet = map[string]bool{
+		"NATURAL": true, "LEFT": true, "RIGHT": true, "FULL": true, "INNER": true, "CROSS": true, "OUTER": true, "STRAIGHT_JOIN": true,
+	}
+)
+
+type formatter struct {
+	option    FormatOption
+	tokenList []*formatToken
+	// nonReservedKeywords is the keywords which may be unquoted identifiers in the engine.
+	nonReservedKeywords map[string]bool
+	// pos is the index of the current token.
+	pos   int
+	stack []*frame
+
+	buf strings.Builder
+	// lineIndent is the indent level of the current line.
+	lineIndent int
+	// pending is true if the next token starts a new line with pendingIndent.
+	pending       bool
+	pendingIndent int
+	// prev is the last written token, and prevUnary is true if it's a unary operator.
+	prev      *formatToken
+	prevUnary bool
+	// statementStart is true before the first token of a statement is written.
+	statementStart bool
+}
+
+func (f *formatter) format() string {
+	f.stack = []*frame{{kind: statementFrame}}
+	f.pending = true
+	f.statementStart = true
+	for f.pos = 0; f.pos < len(f.tokenList); f.pos++ {
+		token := f.tokenList[f.pos]
+		switch token.kind {
+		case commentToken:
+			f.formatComment(token)
+		case punctuationToken:
+			f.formatPunctuation(token)
+		case operatorToken:
+			f.formatOperator(token)
+		case wordToken:
+			f.formatWord(token)
+		default:
+			f.beforeToken(token)
+			f.write(token, token.text, f.needSpace(token))
+		}
+	}
+	result := strings.TrimRight(f.buf.String(), " \n")
+	if result == "" {
+		return ""
+	}
+	return result + "\n"
+}
+
+func (f *formatter) top() *frame {
+	return f.stack[len(f.stack)-1]
+}
+
+// breakLine starts a new line with the indent level before the next token.
+func (f *formatter) breakLine(indent int) {
+	f.pending = true
+	f.pendingIndent = indent
+}
+
+func (f *formatter) write(token *formatToken, text string, space bool) {
+	if f.pending {
+		if f.buf.Len() > 0 {
+			_, _ = f.buf.WriteString("\n")
+			// Keep one blank line between the statements and before the comments.
+			if token.newlineBefore >= 2 && (token.kind == commentToken || f.statementStart) {
+				_, _ = f.buf.WriteString("\n")
+			}
+		}
+		f.lineIndent = f.pendingIndent
+		f.pending = false
+		_, _ = f.buf.WriteString(strings.Repeat(" ", f.lineIndent*f.option.IndentWidth))
+	} else if space {
+		_, _ = f.buf.WriteString(" ")
+	}
+	_, _ = f.buf.WriteString(text)
+	f.prev = token
+	f.prevUnary = false
+	if token.kind != commentToken {
+		f.statementStart = false
+	}
+}
+
+// beforeToken starts a new line for the first item of the clause.
+func (f *formatter) beforeToken(token *formatToken) {
+	fr := f.top()
+	if !fr.itemBreakPending {
+		return
+	}
+	// SELECT DISTINCT and SELECT ALL are kept in one line.
+	if fr.clause == "SELECT" && token.kind == wordToken {
+		if upper := strings.ToUpper(token.text); upper == "DISTINCT" || upper == "ALL" {
+			return
+		}
+	}
+	fr.itemBreakPending = false
+	f.breakLine(fr.baseIndent + 1)
+}
+
+func (f *formatter) needSpace(token *formatToken) bool {
+	prev := f.prev
+	if prev == nil || f.prevUnary {
+		return false
+	}
+	if prev.kind == punctuationToken || prev.kind == operatorToken {
+		switch prev.text {
+		case "(", ".", "[", "::":
+			return false
+		}
+	}
+	if token.kind == punctuationToken || token.kind == operatorToken {
+		switch token.text {
+		case ")", ",", ";", ".", "[", "]", "::":
+			return false
+		case "(":
+			// Function calls are kept without the space, e.g. count(*).
+			return token.spaceBefore || prev.kind == operatorToken || prev.text == ","
+		}
+	}
+	// Keep the prefixes of the strings, e.g. E'abc', and the MySQL user names, e.g. 'user'@'host'.
+	if !token.spaceBefore && prev.kind != commentToken {
+		if token.kind == stringToken || prev.kind == stringToken || token.text == "@" || prev.text == "@" {
+			return false
+		}
+	}
+	return true
+}
+
+// isKeyword returns true if the token is a keyword rather than an identifier.
+// The reserved keywords are always keywords unless qualified by a dot, while the non-reserved ones are identifiers
+// in the position of identifiers, e.g. "INSERT INTO comment" in MySQL.
+func (f *formatter) isKeyword(token *formatToken) bool {
+	upper := strings.ToUpper(token.text)
+	if token.kind != wordToken || !formatKeywordSet[upper] {
+		return false
+	}
+	if f.prev != nil && f.prev.text == "." {
+		return false
+	}
+	next := f.peek(1)
+	if next != nil && next.text == "." {
+		return false
+	}
+	if !f.nonReservedKeywords[upper] {
+		return true
+	}
+	// IF [NOT] EXISTS and WITH RECURSIVE.
+	if upper == "IF" && next != nil && (strings.EqualFold(next.text, "NOT") || strings.EqualFold(next.text, "EXISTS")) {
+		return true
+	}
+	return !f.isIdentifierPosition(upper)
+}
+
+// isIdentifierPosition returns true if the current word is in the position of identifiers or expressions.
+func (f *formatter) isIdentifierPosition(upper string) bool {
+	var prev, prevPrev *formatToken
+	for pos := f.pos - 1; pos >= 0 && prevPrev == nil; pos-- {
+		if f.tokenList[pos].kind == commentToken {
+			continue
+		}
+		if prev == nil {
+			prev = f.tokenList[pos]
+		} else {
+			prevPrev = f.tokenList[pos]
+		}
+	}
+	if prev == nil {
+		return false
+	}
+	switch prev.kind {
+	case punctuationToken:
+		return prev.text == "(" || prev.text == ","
+	case operatorToken:
+		// The type name after the PostgreSQL type cast.
+		return prev.text != "::"
+	case wordToken:
+		prevUpper := strings.ToUpper(prev.text)
+		if !identifierLeadingWordSet[prevUpper] {
+			return false
+		}
+		switch prevUpper {
+		case "WITH":
+			return upper != "RECURSIVE"
+		case "UPDATE":
+			// DO UPDATE SET, ON DUPLICATE KEY UPDATE and FOR UPDATE.
+			return prevPrev == nil || prevPrev.kind != wordToken
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+func (f *formatter) keywordText(token *formatToken) string {
+	switch f.option.KeywordCase {
+	case KeywordCaseUpper:
+		return strings.ToUpper(token.text)
+	case KeywordCaseLower:
+		return strings.ToLower(token.text)
+	default:
+		return token.text
+	}
+}
+
+// peek returns the i-th token after the current one, skipping the comments.
+func (f *formatter) peek(i int) *formatToken {
+	for pos := f.pos + 1; pos < len(f.tokenList); pos++ {
+		if f.tokenList[pos].kind == commentToken {
+			continue
+		}
+		i--
+		if i == 0 {
+			return f.tokenList[pos]
+		}
+	}
+	return nil
+}
+
+// peekWord returns the i-th token after the current one in upper case if it's a word.
+func (f *formatter) peekWord(i int) string {
+	token := f.peek(i)
+	if token == nil || token.kind != wordToken {
+		return ""
+	}
+	return strings.ToUpper(token.text)
+}
+
+func (f *formatter) formatComment(token *formatToken) {
+	lineComment := !strings.HasPrefix(token.text, "/*")
+	if token.newlineBefore == 0 && f.prev != nil {
+		// The comment following a token in the same line stays in the line.
+		_, _ = f.buf.WriteString(" ")
+		_, _ = f.buf.WriteString(token.text)
+		if lineComment && !f.pending {
+			f.breakLine(f.lineIndent)
+		}
+		return
+	}
+	indent := f.lineIndent
+	if f.pending {
+		indent = f.pendingIndent
+	}
+	f.breakLine(indent)
+	f.write(token, token.text, false)
+	f.breakLine(indent)
+}
+
+func (f *formatter) formatPunctuation(token *formatToken) {
+	fr := f.top()
+	switch token.text {
+	case "(":
+		f.beforeToken(token)
+		f.write(token, token.text, f.needSpace(token))
+		switch next := f.peekWord(1); {
+		case next == "SELECT" || next == "WITH":
+			f.stack = append(f.stack, &frame{kind: statementFrame, baseIndent: f.lineIndent + 1, closeIndent: f.lineIndent})
+			f.breakLine(f.lineIndent + 1)
+		case fr.kind == statementFrame && fr.createTable && !fr.listOpened:
+			fr.listOpened = true
+			f.stack = append(f.stack, &frame{kind: listFrame, baseIndent: f.lineIndent, closeIndent: f.lineIndent})
+			f.breakLine(f.lineIndent + 1)
+		default:
+			f.stack = append(f.stack, &frame{kind: inlineFrame})
+		}
+	case ")":
+		if len(f.stack) > 1 {
+			f.stack = f.stack[:len(f.stack)-1]
+			if fr.kind != inlineFrame {
+				f.breakLine(fr.closeIndent)
+			}
+		}
+		f.write(token, token.text, false)
+	case ",":
+		if fr.kind == listFrame || fr.kind == statementFrame && fr.query && listClauseSet[fr.clause] {
+			if f.option.CommaPosition == CommaPositionLeading {
+				f.breakLine(fr.baseIndent + 1)
+				f.write(token, token.text, false)
+			} else {
+				f.write(token, token.text, false)
+				f.breakLine(fr.baseIndent + 1)
+			}
+			return
+		}
+		f.write(token, token.text, false)
+	case ";":
+		f.write(token, token.text, false)
+		f.stack = []*frame{{kind: statementFrame}}
+		f.breakLine(0)
+		f.statementStart = true
+	default:
+		f.beforeToken(token)
+		f.write(token, token.text, f.needSpace(token))
+	}
+}
+
+func (f *formatter) formatOperator(token *formatToken) {
+	f.beforeToken(token)
+	prev := f.prev
+	// The operators following the operators, the opening parentheses, the commas and the keywords are unary, e.g. -1 and SELECT *.
+	unary := prev == nil || prev.kind == operatorToken || prev.kind == punctuationToken && (prev.text == "(" || prev.text == ",") ||
+		prev.kind == wordToken && formatKeywordSet[strings.ToUpper(prev.text)]
+	if token.text == "*" && (unary || prev.text == ".") {
+		f.write(token, token.text, f.needSpace(token))
+		return
+	}
+	f.write(token, token.text, f.needSpace(token))
+	if unary && (token.text == "-" || token.text == "+" || token.text == "~") {
+		f.prevUnary = true
+	}
+}
+
+func (f *formatter) formatWord(token *formatToken) {
+	fr := f.top()
+	upper := strings.ToUpper(token.text)
+	keyword := f.isKeyword(token)
+	if fr.kind == statementFrame {
+		first := fr.firstWord == ""
+		if first {
+			fr.firstWord = upper
+			fr.query = queryStartWordSet[upper]
+		}
+		if keyword {
+			if fr.firstWord == "CREATE" && upper == "TABLE" {
+				fr.createTable = true
+			}
+			// CREATE TABLE ... AS SELECT and CREATE VIEW ... AS SELECT.
+			if !fr.query && (upper == "SELECT" || upper == "WITH") && f.prev != nil && strings.ToUpper(f.prev.text) == "AS" {
+				fr.query = true
+			}
+			if fr.query && f.formatClause(token, upper, first) {
+				return
+			}
+		}
+	}
+
+	f.beforeToken(token)
+	if fr.kind == statementFrame && fr.query && keyword {
+		switch upper {
+		case "BETWEEN":
+			fr.between = true
+		case "AND", "OR":
+			if upper == "AND" && fr.between {
+				fr.between = false
+			} else if conditionClauseSet[fr.clause] {
+				f.breakLine(fr.baseIndent + 1)
+			}
+		}
+	}
+	text := token.text
+	if keyword {
+		text = f.keywordText(token)
+	}
+	f.write(token, text, f.needSpace(token))
+}
+
+// formatClause writes the clause keywords in a new line, and returns false if the token doesn't start a clause.
+func (f *formatter) formatClause(token *formatToken, upper string, first bool) bool {
+	fr := f.top()
+	prevUpper := ""
+	if f.prev != nil && f.prev.kind == wordToken {
+		prevUpper = strings.ToUpper(f.prev.text)
+	}
+
+	wordCount := 1
+	block := false
+	indent := fr.baseIndent
+	clause := upper
+	switch upper {
+	case "SELECT", "WHERE", "HAVING", "RETURNING", "WINDOW":
+		block = true
+	case "FROM":
+		// DELETE FROM and IS [NOT] DISTINCT FROM are kept in one line.
+		if prevUpper == "DELETE" || prevUpper == "DISTINCT" {
+			return false
+		}
+		block = true
+	case "VALUES":
+		// DEFAULT VALUES and the MySQL VALUES() function are kept in one line.
+		if prevUpper == "DEFAULT" || f.prev != nil && (f.prev.kind == operatorToken || f.prev.text == "(" || f.prev.text == ",") {
+			return false
+		}
+		block = true
+	case "SET":
+		if fr.firstWord != "UPDATE" {
+			return false
+		}
+		block = true
+	case "GROUP", "ORDER":
+		if f.peekWord(1) != "BY" {
+			return false
+		}
+		wordCount = 2
+		clause = upper + " BY"
+		block = true
+	case "UNION", "INTERSECT", "EXCEPT":
+		if next := f.peekWord(1); next == "ALL" || next == "DISTINCT" {
+			wordCount = 2
+		}
+	case "LIMIT", "OFFSET", "FETCH":
+	case "ON":
+		switch {
+		case f.peekWord(1) == "CONFLICT":
+			wordCount = 2
+		case f.peekWord(1) == "DUPLICATE" && f.peekWord(2) == "KEY" && f.peekWord(3) == "UPDATE":
+			wordCount = 4
+		default:
+			return false
+		}
+	case "JOIN", "NATURAL", "LEFT", "RIGHT", "FULL", "INNER", "CROSS", "STRAIGHT_JOIN":
+		// LEFT and RIGHT may be function calls.
+		for wordCount = 1; joinWordSet[f.peekWordFrom(wordCount-1)]; wordCount++ {
+		}
+		if f.peekWordFrom(wordCount-1) != "JOIN" && upper != "STRAIGHT_JOIN" {
+			return false
+		}
+		if upper == "STRAIGHT_JOIN" && f.peekWordFrom(wordCount-1) != "JOIN" {
+			wordCount--
+		}
+		clause = "JOIN"
+		indent = fr.baseIndent + 1
+	default:
+		if !first {
+			return false
+		}
+	}
+
+	if !first {
+		f.breakLine(indent)
+	}
+	fr.itemBreakPending = false
+	for i := 0; i < wordCount; i++ {
+		if i > 0 {
+			f.pos++
+			for f.tokenList[f.pos].kind == commentToken {
+				f.formatComment(f.tokenList[f.pos])
+				f.pos++
+			}
+			token = f.tokenList[f.pos]
+		}
+		f.write(token, f.keywordText(token), f.needSpace(token))
+	}
+	fr.clause = clause
+	fr.itemBreakPending = block
+	fr.between = false
+	return true
+}
+
+func newWordSet(words string) map[string]bool {
+	wordSet := make(map[string]bool)
+	for _, word := range strings.Fields(words) {
+		wordSet[word] = true
+	}
+	return wordSet
+}
+
+// peekWordFrom returns the word at the i-th token after the current one, and the current token for 0.
+func (f *formatter) peekWordFrom(i int) string {
+	if i == 0 {
+		return strings.ToUpper(f.tokenList[f.pos].text)
+	}
+	return f.peekWord(i)
+}
@@ -0,0 +1,166 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name       string
+		engineType EngineType
+		statement  string
+		option     FormatOption
+		want       string
+	}{
+		{
+			name:       "selectWithComments",
+			engineType: Postgres,
+			statement: `-- Find the active users.
+select id, name as n, count(*) from "user" u left join team t on u.team_id = t.id -- join team
+where u.status = 'ACTIVE' and u.age between 18 and 60 or u.id in (select user_id from admin) group by id, name order by id desc limit 10;`,
+			want: `-- Find the active users.
+SELECT
+  id,
+  name AS n,
+  count(*)
+FROM
+  "user" u
+  LEFT JOIN team t ON u.team_id = t.id -- join team
+WHERE
+  u.status = 'ACTIVE'
+  AND u.age BETWEEN 18 AND 60
+  OR u.id IN (
+    SELECT
+      user_id
+    FROM
+      admin
+  )
+GROUP BY
+  id,
+  name
+ORDER BY
+  id DESC
+LIMIT 10;
+`,
+		},
+		{
+			name:       "options",
+			engineType: MySQL,
+			statement:  "SELECT `id`, name FROM t WHERE a = -1",
+			option: FormatOption{
+				KeywordCase:   KeywordCaseLower,
+				IndentWidth:   4,
+				CommaPosition: CommaPositionLeading,
+			},
+			want: "select\n    `id`\n    , name\nfrom\n    t\nwhere\n    a = -1\n",
+		},
+		{
+			name:       "createTable",
+			engineType: MySQL,
+			statement: `CREATE TABLE t(id int NOT NULL AUTO_INCREMENT, # the id
+price decimal(10,2) DEFAULT '0.00', PRIMARY KEY (id)) ENGINE=InnoDB;
+
+/* The second statement. */
+insert into t(id,price) values(1,2.5),(2,3e-2) on duplicate key update price=values(price);`,
+			want: `CREATE TABLE t(
+  id INT NOT NULL AUTO_INCREMENT, # the id
+  price DECIMAL(10, 2) DEFAULT '0.00',
+  PRIMARY KEY (id)
+) ENGINE = InnoDB;
+
+/* The second statement. */
+INSERT INTO t(id, price)
+VALUES
+  (1, 2.5),
+  (2, 3e-2)
+ON DUPLICATE KEY UPDATE price = VALUES(price);
+`,
+		},
+		{
+			name:       "postgresSpecific",
+			engineType: Postgres,
+			statement: `UPDATE t SET a = E'x\'y', b = '{}'::text[] WHERE c = $1 RETURNING id;
+CREATE FUNCTION f() RETURNS trigger AS $$ BEGIN   RETURN NEW; END $$ LANGUAGE plpgsql;
+alter table t add column d int;`,
+			option: FormatOption{
+				KeywordCase: KeywordCasePreserve,
+			},
+			want: `UPDATE t
+SET
+  a = E'x\'y',
+  b = '{}'::text[]
+WHERE
+  c = $1
+RETURNING
+  id;
+CREATE FUNCTION f() RETURNS trigger AS $$ BEGIN   RETURN NEW; END $$ LANGUAGE plpgsql;
+alter table t add column d int;
+`,
+		},
+		{
+			name:       "mysqlNonReservedKeywordIdentifier",
+			engineType: MySQL,
+			statement: `create table comment (id int, json json comment 'doc', text text not null);
+insert into comment(id, json) values (1, '{}');
+select json, text from comment where json is not null;`,
+			want: `CREATE TABLE comment (
+  id INT,
+  json JSON COMMENT 'doc',
+  text TEXT NOT NULL
+);
+INSERT INTO comment(id, json)
+VALUES
+  (1, '{}');
+SELECT
+  json,
+  text
+FROM
+  comment
+WHERE
+  json IS NOT NULL;
+`,
+		},
+		{
+			name:       "postgresNonReservedKeywordIdentifier",
+			engineType: Postgres,
+			statement: `create table if not exists json (comment text, key int);
+update json set comment = 'a'::text where key = 1;
+insert into json values ('b', 2) on conflict (key) do update set comment = excluded.comment;`,
+			want: `CREATE TABLE IF NOT EXISTS json (
+  comment TEXT,
+  key INT
+);
+UPDATE json
+SET
+  comment = 'a'::TEXT
+WHERE
+  key = 1;
+INSERT INTO json
+VALUES
+  ('b', 2)
+ON CONFLICT (key) DO UPDATE SET comment = excluded.comment;
+`,
+		},
+	}
+
+	for _, test := range tests {
+		got, err := Format(test.engineType, test.statement, test.option)
+		require.NoError(t, err, test.name)
+		require.Equal(t, test.want, got, test.name)
+		// Formatting the formatted statement again makes no change.
+		got, err = Format(test.engineType, got, test.option)
+		require.NoError(t, err, test.name)
+		require.Equal(t, test.want, got, test.name)
+	}
+}
+
+func TestFormatInvalidOption(t *testing.T) {
+	_, err := Format(MySQL, "SELECT 1", FormatOption{KeywordCase: "CAMEL"})
+	require.Error(t, err)
+	_, err = Format(MySQL, "SELECT 1", FormatOption{IndentWidth: 100})
+	require.Error(t, err)
+	_, err = Format(MySQL, "SELECT 'abc", FormatOption{})
+	require.Error(t, err)
+}
@@ -19,6 +19,8 @@ const (
 	Postgres EngineType = "POSTGRES"
 	// TiDB is the engine type for TiDB.
 	TiDB EngineType = "TIDB"
+	// ClickHouse is the engine type for CLICKHOUSE.
+	ClickHouse EngineType = "CLICKHOUSE"
 )
 
 // ParseContext is the context for parsing.
@@ -449,3 +449,45 @@ func TestMySQLSplitMultiSQL(t *testing.T) {
 		require.Equal(t, test.want, resData{res, errStr}, test.statement)
 	}
 }
+
+func TestClickHouseSplitMultiSQL(t *testing.T) {
+	tests := []testData{
+		{
+			statement: "--\n" +
+				"-- Table structure for `events`\n" +
+				"--\n" +
+				"CREATE TABLE events (`id` UInt64, `name` String DEFAULT 'a;b') ENGINE = MergeTree ORDER BY id SETTINGS index_granularity = 8192;\n" +
+				"--\n" +
+				"-- View structure for `v`\n" +
+				"--\n" +
+				"CREATE VIEW v (`id` UInt64) AS SELECT id FROM events;\n",
+			want: resData{
+				res: []SingleSQL{
+					{
+						Text: "--\n" +
+							"-- Table structure for `events`\n" +
+							"--\n" +
+							"CREATE TABLE events (`id` UInt64, `name` String DEFAULT 'a;b') ENGINE = MergeTree ORDER BY id SETTINGS index_granularity = 8192;",
+						LastLine: 4,
+					},
+					{
+						Text: "--\n" +
+							"-- View structure for `v`\n" +
+							"--\n" +
+							"CREATE VIEW v (`id` UInt64) AS SELECT id FROM events;",
+						LastLine: 8,
+					},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		res, err := SplitMultiSQL(ClickHouse, test.statement)
+		errStr := ""
+		if err != nil {
+			errStr = err.Error()
+		}
+		require.Equal(t, test.want, resData{res, errStr}, test.statement)
+	}
+}
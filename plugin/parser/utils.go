@@ -22,7 +22,8 @@ func SplitMultiSQL(engineType EngineType, statement string) ([]SingleSQL, error)
 	case Postgres:
 		t := newTokenizer(statement)
 		return t.splitPostgreSQLMultiSQL()
-	case MySQL, TiDB:
+	case MySQL, TiDB, ClickHouse:
+		// ClickHouse shares the same quoting and comment rules with MySQL.
 		t := newTokenizer(statement)
 		return t.splitMySQLMultiSQL()
 	default:
@@ -36,7 +37,7 @@ func SplitMultiSQLStream(engineType EngineType, src io.Reader, f func(string) er
 	case Postgres:
 		t := newStreamTokenizer(src, f)
 		return t.splitPostgreSQLMultiSQL()
-	case MySQL, TiDB:
+	case MySQL, TiDB, ClickHouse:
 		t := newStreamTokenizer(src, f)
 		return t.splitMySQLMultiSQL()
 	default:
@@ -0,0 +1,116 @@
+// Package azure provides the client for Azure Blob Storage.
+package azure
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/pkg/errors"
+
+	"github.com/bytebase/bytebase/plugin/storage"
+)
+
+var _ storage.Client = (*Client)(nil)
+
+// Client wraps the Azure Blob Storage client.
+type Client struct {
+	c         *azblob.Client
+	container string
+}
+
+// GetConnectionStringFromFile loads the Azure Storage connection string from file.
+// The connection string can also point to the Azurite emulator with the BlobEndpoint field.
+func GetConnectionStringFromFile(connectionStringFileName string) (string, error) {
+	content, err := os.ReadFile(connectionStringFileName)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to read Azure Storage connection string file %q", connectionStringFileName)
+	}
+	connectionString := strings.TrimSpace(string(content))
+	if connectionString == "" {
+		return "", errors.Errorf("Azure Storage connection string file %q is empty", connectionStringFileName)
+	}
+	return connectionString, nil
+}
+
+// NewClient returns a new Azure Blob Storage client.
+func NewClient(container, connectionString string) (*Client, error) {
+	c, err := azblob.NewClientFromConnectionString(connectionString, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create Azure Blob Storage client")
+	}
+	return &Client{
+		c:         c,
+		container: container,
+	}, nil
+}
+
+// ListObjects lists objects with prefix in their names.
+func (c *Client) ListObjects(ctx context.Context, prefix string) ([]*storage.Object, error) {
+	var ret []*storage.Object
+	pager := c.c.NewListBlobsFlatPager(c.container, &azblob.ListBlobsFlatOptions{
+		Prefix: &prefix,
+	})
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to load the next page of Azure blobs")
+		}
+		for _, item := range page.Segment.BlobItems {
+			if item.Name == nil {
+				continue
+			}
+			object := &storage.Object{
+				Key: *item.Name,
+			}
+			if item.Properties != nil {
+				if item.Properties.LastModified != nil {
+					object.LastModified = *item.Properties.LastModified
+				}
+				if item.Properties.ContentLength != nil {
+					object.Size = *item.Properties.ContentLength
+				}
+			}
+			ret = append(ret, object)
+		}
+	}
+	return ret, nil
+}
+
+// DownloadObject downloads the object with path.
+func (c *Client) DownloadObject(ctx context.Context, path string, w io.WriterAt) (int64, error) {
+	resp, err := c.c.DownloadStream(ctx, c.container, path, nil)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to download blob %q from Azure Blob Storage", path)
+	}
+	body := resp.NewRetryReader(ctx, nil)
+	defer body.Close()
+	return io.Copy(storage.NewOffsetWriter(w), body)
+}
+
+// UploadObject uploads an object with the path.
+// The blob is uploaded in blocks, which are committed only if all of them are uploaded successfully.
+func (c *Client) UploadObject(ctx context.Context, path string, body io.Reader) error {
+	if _, err := c.c.UploadStream(ctx, c.container, path, body, nil); err != nil {
+		return errors.Wrapf(err, "failed to upload blob %q to Azure Blob Storage", path)
+	}
+	return nil
+}
+
+// DeleteObjects deletes the objects with path.
+func (c *Client) DeleteObjects(ctx context.Context, pathList ...string) error {
+	for _, path := range pathList {
+		if _, err := c.c.DeleteBlob(ctx, c.container, path, nil); err != nil && !bloberror.HasCode(err, bloberror.BlobNotFound) {
+			return errors.Wrapf(err, "failed to delete blob %q in Azure Blob Storage", path)
+		}
+	}
+	return nil
+}
+
+// GetBucket returns the container.
+func (c *Client) GetBucket() string {
+	return c.container
+}
@@ -0,0 +1,59 @@
+package azure
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/bytebase/bytebase/common/log"
+)
+
+const (
+	container = "bytebase-dev"
+	// The well-known connection string of the Azurite emulator, e.g., `docker run -d -p 10000:10000 mcr.microsoft.com/azure-storage/azurite azurite-blob --blobHost 0.0.0.0`.
+	azuriteConnectionString = "DefaultEndpointsProtocol=http;AccountName=devstoreaccount1;AccountKey=Eby8vdM02xNOcqFlqUwJPLlmEtlCDXJ1OUzFT50uSRZ6IFsuFq2UVErCz4I6tq/K1SZFPTOtr/KBHBeksoGMGw==;BlobEndpoint=http://127.0.0.1:10000/devstoreaccount1;"
+)
+
+// Only for manual test.
+// Should be skipped in CI.
+func TestAzureOperations(t *testing.T) {
+	t.Skip()
+	a := require.New(t)
+	ctx := context.Background()
+	client, err := NewClient(container, azuriteConnectionString)
+	a.NoError(err)
+	_, err = client.c.CreateContainer(ctx, container, nil)
+	a.NoError(err)
+
+	t.Run("UploadObjects", func(t *testing.T) {
+		buf := make([]byte, 10*1024*1024)
+		blob := bytes.NewReader(buf)
+		err := client.UploadObject(ctx, "backup/test/blob", blob)
+		a.NoError(err)
+	})
+
+	t.Run("ListObjects", func(t *testing.T) {
+		list, err := client.ListObjects(ctx, "backup/")
+		a.NoError(err)
+		for _, obj := range list {
+			log.Info("Object", zap.String("Key", obj.Key), zap.Time("LastModified", obj.LastModified))
+		}
+	})
+
+	t.Run("DownloadObjects", func(t *testing.T) {
+		file, err := os.CreateTemp(t.TempDir(), "blob")
+		a.NoError(err)
+		n, err := client.DownloadObject(ctx, "backup/test/blob", file)
+		a.NoError(err)
+		a.Equal(int64(10*1024*1024), n)
+	})
+
+	t.Run("DeleteObjects", func(t *testing.T) {
+		err := client.DeleteObjects(ctx, "backup/test/blob")
+		a.NoError(err)
+	})
+}
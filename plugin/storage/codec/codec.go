@@ -0,0 +1,258 @@
+// Package codec provides the streaming compression and encryption of the backup artifacts.
+//
+// An encoded artifact starts with a header:
+//
+//	magic (8 bytes) | version (1 byte) | compression (1 byte) | encryption (1 byte)
+//
+// followed by the key ID (8 bytes) and the nonce prefix (7 bytes) if the artifact is encrypted.
+// The payload is compressed first and then encrypted.
+// Artifacts without the header are plain SQL files written before the codec is introduced.
+package codec
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+
+	"github.com/bytebase/bytebase/api"
+)
+
+const (
+	magic         = "BBBACKUP"
+	formatVersion = 1
+	// KeySize is the size of the AES-256 key in bytes.
+	KeySize   = 32
+	keyIDSize = 8
+)
+
+var (
+	compressionCodes = map[api.BackupCompression]byte{
+		api.BackupCompressionNone: 0,
+		api.BackupCompressionGzip: 1,
+		api.BackupCompressionZstd: 2,
+	}
+	encryptionCodes = map[api.BackupEncryption]byte{
+		api.BackupEncryptionNone:      0,
+		api.BackupEncryptionAES256GCM: 1,
+	}
+)
+
+// Header is the header of an encoded backup artifact.
+type Header struct {
+	Compression api.BackupCompression
+	Encryption  api.BackupEncryption
+	// KeyID identifies the encryption key, so that decrypting with a wrong key gets a clear error.
+	KeyID []byte
+}
+
+// ParseKey decodes the base64 encoded 256-bit encryption key.
+func ParseKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, errors.Wrap(err, "encryption key must be base64 encoded")
+	}
+	if len(key) != KeySize {
+		return nil, errors.Errorf("encryption key must be %d bytes, but got %d bytes", KeySize, len(key))
+	}
+	return key, nil
+}
+
+// ReadKeyFile reads the base64 encoded 256-bit encryption key from file.
+func ReadKeyFile(keyFile string) ([]byte, error) {
+	content, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read encryption key file %q", keyFile)
+	}
+	key, err := ParseKey(string(content))
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid encryption key file %q", keyFile)
+	}
+	return key, nil
+}
+
+func getKeyID(key []byte) []byte {
+	sum := sha256.Sum256(key)
+	return sum[:keyIDSize]
+}
+
+type writer struct {
+	compressor io.WriteCloser
+	encryptor  *encryptWriter
+}
+
+// NewWriter returns a writer encoding the artifact to w with the compression and encryption.
+// The key is only used for encryption. Closing the writer flushes the pending data but doesn't close w.
+func NewWriter(w io.Writer, compression api.BackupCompression, encryption api.BackupEncryption, key []byte) (io.WriteCloser, error) {
+	compressionCode, ok := compressionCodes[compression]
+	if !ok {
+		return nil, errors.Errorf("unsupported backup compression %q", compression)
+	}
+	encryptionCode, ok := encryptionCodes[encryption]
+	if !ok {
+		return nil, errors.Errorf("unsupported backup encryption %q", encryption)
+	}
+
+	header := []byte(magic)
+	header = append(header, formatVersion, compressionCode, encryptionCode)
+	ret := &writer{}
+	var out io.Writer = w
+	if encryption == api.BackupEncryptionAES256GCM {
+		if len(key) != KeySize {
+			return nil, errors.Errorf("encryption key must be %d bytes, but got %d bytes", KeySize, len(key))
+		}
+		encryptor, noncePrefix, err := newEncryptWriter(w, key)
+		if err != nil {
+			return nil, err
+		}
+		header = append(header, getKeyID(key)...)
+		header = append(header, noncePrefix...)
+		ret.encryptor = encryptor
+		out = encryptor
+	}
+	if _, err := w.Write(header); err != nil {
+		return nil, errors.Wrap(err, "failed to write backup artifact header")
+	}
+
+	switch compression {
+	case api.BackupCompressionGzip:
+		ret.compressor = gzip.NewWriter(out)
+	case api.BackupCompressionZstd:
+		compressor, err := zstd.NewWriter(out)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create zstd writer")
+		}
+		ret.compressor = compressor
+	default:
+		ret.compressor = nopWriteCloser{out}
+	}
+	return ret, nil
+}
+
+// Write implements io.Writer.
+func (w *writer) Write(p []byte) (int, error) {
+	return w.compressor.Write(p)
+}
+
+// Close flushes the compressor and the encryptor.
+func (w *writer) Close() error {
+	if err := w.compressor.Close(); err != nil {
+		return errors.Wrap(err, "failed to flush the compressed backup")
+	}
+	if w.encryptor != nil {
+		if err := w.encryptor.Close(); err != nil {
+			return errors.Wrap(err, "failed to flush the encrypted backup")
+		}
+	}
+	return nil
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+type reader struct {
+	r            io.Reader
+	decompressor io.Closer
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	return r.r.Read(p)
+}
+
+func (r *reader) Close() error {
+	if r.decompressor != nil {
+		return r.decompressor.Close()
+	}
+	return nil
+}
+
+// NewReader detects the artifact format from the header and returns the decoded reader with the header.
+// Plain artifacts without the header are returned as is with the NONE compression and encryption.
+// The key is only required for the encrypted artifacts.
+func NewReader(r io.Reader, key []byte) (io.ReadCloser, *Header, error) {
+	br := bufio.NewReader(r)
+	prefix, err := br.Peek(len(magic) + 3)
+	if err != nil && err != io.EOF {
+		return nil, nil, errors.Wrap(err, "failed to read backup artifact header")
+	}
+	if !bytes.HasPrefix(prefix, []byte(magic)) || len(prefix) < len(magic)+3 {
+		return io.NopCloser(br), &Header{Compression: api.BackupCompressionNone, Encryption: api.BackupEncryptionNone}, nil
+	}
+	if prefix[len(magic)] != formatVersion {
+		return nil, nil, errors.Errorf("unsupported backup artifact format version %d", prefix[len(magic)])
+	}
+	header := &Header{}
+	for compression, code := range compressionCodes {
+		if code == prefix[len(magic)+1] {
+			header.Compression = compression
+		}
+	}
+	for encryption, code := range encryptionCodes {
+		if code == prefix[len(magic)+2] {
+			header.Encryption = encryption
+		}
+	}
+	if header.Compression == "" || header.Encryption == "" {
+		return nil, nil, errors.Errorf("unsupported backup artifact codec %v", prefix[len(magic)+1:])
+	}
+	if _, err := br.Discard(len(prefix)); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to read backup artifact header")
+	}
+
+	ret := &reader{r: br}
+	if header.Encryption == api.BackupEncryptionAES256GCM {
+		encryptionHeader := make([]byte, keyIDSize+noncePrefixSize)
+		if _, err := io.ReadFull(br, encryptionHeader); err != nil {
+			return nil, nil, errors.Wrap(err, "failed to read backup artifact encryption header")
+		}
+		header.KeyID = encryptionHeader[:keyIDSize]
+		if len(key) == 0 {
+			return nil, nil, errors.Errorf("the backup is encrypted, but no encryption key is configured")
+		}
+		if !bytes.Equal(header.KeyID, getKeyID(key)) {
+			return nil, nil, errors.Errorf("the backup is encrypted with a different key")
+		}
+		decryptor, err := newDecryptReader(br, key, encryptionHeader[keyIDSize:])
+		if err != nil {
+			return nil, nil, err
+		}
+		ret.r = decryptor
+	}
+
+	switch header.Compression {
+	case api.BackupCompressionGzip:
+		decompressor, err := gzip.NewReader(ret.r)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to create gzip reader")
+		}
+		ret.r, ret.decompressor = decompressor, decompressor
+	case api.BackupCompressionZstd:
+		decompressor, err := zstd.NewReader(ret.r)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to create zstd reader")
+		}
+		ret.r, ret.decompressor = decompressor, zstdCloser{decompressor}
+	}
+	return ret, header, nil
+}
+
+type zstdCloser struct {
+	d *zstd.Decoder
+}
+
+func (c zstdCloser) Close() error {
+	c.d.Close()
+	return nil
+}
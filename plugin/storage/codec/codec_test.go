@@ -0,0 +1,113 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bytebase/bytebase/api"
+)
+
+func TestCodec(t *testing.T) {
+	a := require.New(t)
+	key := bytes.Repeat([]byte{1}, KeySize)
+	otherKey := bytes.Repeat([]byte{2}, KeySize)
+	contents := []string{
+		"",
+		"CREATE TABLE t(a INT);\n",
+		// Exactly two encryption chunks.
+		strings.Repeat("a", 2*chunkSize),
+		strings.Repeat("INSERT INTO t VALUES (1);\n", 10000),
+	}
+	compressions := []api.BackupCompression{api.BackupCompressionNone, api.BackupCompressionGzip, api.BackupCompressionZstd}
+	encryptions := []api.BackupEncryption{api.BackupEncryptionNone, api.BackupEncryptionAES256GCM}
+
+	for _, content := range contents {
+		for _, compression := range compressions {
+			for _, encryption := range encryptions {
+				var buf bytes.Buffer
+				w, err := NewWriter(&buf, compression, encryption, key)
+				a.NoError(err)
+				_, err = io.Copy(w, strings.NewReader(content))
+				a.NoError(err)
+				a.NoError(w.Close())
+				if encryption == api.BackupEncryptionAES256GCM && content != "" {
+					a.NotContains(buf.String(), content)
+				}
+
+				r, header, err := NewReader(bytes.NewReader(buf.Bytes()), key)
+				a.NoError(err)
+				a.Equal(compression, header.Compression)
+				a.Equal(encryption, header.Encryption)
+				got, err := io.ReadAll(r)
+				a.NoError(err)
+				a.Equal(content, string(got))
+				a.NoError(r.Close())
+
+				if encryption == api.BackupEncryptionAES256GCM {
+					_, _, err := NewReader(bytes.NewReader(buf.Bytes()), otherKey)
+					a.Error(err)
+					_, _, err = NewReader(bytes.NewReader(buf.Bytes()), nil)
+					a.Error(err)
+				}
+			}
+		}
+	}
+}
+
+func TestPlainBackup(t *testing.T) {
+	a := require.New(t)
+	for _, content := range []string{"", "BB", "CREATE TABLE t(a INT);\n"} {
+		r, header, err := NewReader(strings.NewReader(content), nil)
+		a.NoError(err)
+		a.Equal(api.BackupCompressionNone, header.Compression)
+		a.Equal(api.BackupEncryptionNone, header.Encryption)
+		got, err := io.ReadAll(r)
+		a.NoError(err)
+		a.Equal(content, string(got))
+	}
+}
+
+func TestEncryptedBackupTampered(t *testing.T) {
+	a := require.New(t)
+	key := bytes.Repeat([]byte{1}, KeySize)
+	content := strings.Repeat("a", 3*chunkSize)
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, api.BackupCompressionNone, api.BackupEncryptionAES256GCM, key)
+	a.NoError(err)
+	_, err = w.Write([]byte(content))
+	a.NoError(err)
+	a.NoError(w.Close())
+	encoded := buf.Bytes()
+
+	// Truncated at the chunk boundary.
+	headerSize := len(magic) + 3 + keyIDSize + noncePrefixSize
+	truncated := encoded[:headerSize+2*(chunkSize+16)]
+	r, _, err := NewReader(bytes.NewReader(truncated), key)
+	a.NoError(err)
+	_, err = io.ReadAll(r)
+	a.Error(err)
+
+	// Flipped a bit.
+	tampered := append([]byte{}, encoded...)
+	tampered[len(tampered)-1] ^= 1
+	r, _, err = NewReader(bytes.NewReader(tampered), key)
+	a.NoError(err)
+	_, err = io.ReadAll(r)
+	a.Error(err)
+}
+
+func TestParseKey(t *testing.T) {
+	a := require.New(t)
+	key, err := ParseKey(base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, KeySize)) + "\n")
+	a.NoError(err)
+	a.Len(key, KeySize)
+	_, err = ParseKey(base64.StdEncoding.EncodeToString([]byte("short")))
+	a.Error(err)
+	_, err = ParseKey("not base64")
+	a.Error(err)
+}
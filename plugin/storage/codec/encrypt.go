@@ -0,0 +1,164 @@
+package codec
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// The encrypted payload is a sequence of AES-GCM sealed chunks following the STREAM construction,
+// so that large backups can be encrypted and decrypted without buffering the whole file.
+// The nonce of each chunk is the nonce prefix, the big-endian chunk counter and a flag marking the last chunk,
+// which detects reordered, dropped or truncated chunks.
+const (
+	chunkSize       = 64 * 1024
+	noncePrefixSize = 7
+)
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create AES cipher")
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create AES-GCM cipher")
+	}
+	return aead, nil
+}
+
+func getNonce(noncePrefix []byte, counter uint32, last bool) []byte {
+	nonce := make([]byte, noncePrefixSize+5)
+	copy(nonce, noncePrefix)
+	binary.BigEndian.PutUint32(nonce[noncePrefixSize:], counter)
+	if last {
+		nonce[len(nonce)-1] = 1
+	}
+	return nonce
+}
+
+type encryptWriter struct {
+	w           io.Writer
+	aead        cipher.AEAD
+	noncePrefix []byte
+	counter     uint32
+	buf         []byte
+}
+
+func newEncryptWriter(w io.Writer, key []byte) (*encryptWriter, []byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	noncePrefix := make([]byte, noncePrefixSize)
+	if _, err := rand.Read(noncePrefix); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to generate nonce")
+	}
+	return &encryptWriter{
+		w:           w,
+		aead:        aead,
+		noncePrefix: noncePrefix,
+		buf:         make([]byte, 0, chunkSize+1),
+	}, noncePrefix, nil
+}
+
+// Write implements io.Writer.
+// A full chunk is sealed only after more data arrives, so that the last chunk can always be marked on Close.
+func (w *encryptWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		if len(w.buf) == chunkSize {
+			if err := w.seal(false); err != nil {
+				return n - len(p), err
+			}
+		}
+		written := copy(w.buf[len(w.buf):chunkSize], p)
+		w.buf = w.buf[:len(w.buf)+written]
+		p = p[written:]
+	}
+	return n, nil
+}
+
+// Close seals the last chunk, which may be empty.
+func (w *encryptWriter) Close() error {
+	return w.seal(true)
+}
+
+func (w *encryptWriter) seal(last bool) error {
+	if w.counter == math.MaxUint32 {
+		return errors.Errorf("encrypted backup is too large")
+	}
+	sealed := w.aead.Seal(nil, getNonce(w.noncePrefix, w.counter, last), w.buf, nil)
+	w.counter++
+	w.buf = w.buf[:0]
+	if _, err := w.w.Write(sealed); err != nil {
+		return errors.Wrap(err, "failed to write encrypted chunk")
+	}
+	return nil
+}
+
+type decryptReader struct {
+	r           *bufio.Reader
+	aead        cipher.AEAD
+	noncePrefix []byte
+	counter     uint32
+	sealed      []byte
+	plain       []byte
+	done        bool
+}
+
+func newDecryptReader(r *bufio.Reader, key, noncePrefix []byte) (*decryptReader, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return &decryptReader{
+		r:           r,
+		aead:        aead,
+		noncePrefix: noncePrefix,
+		sealed:      make([]byte, chunkSize+aead.Overhead()),
+	}, nil
+}
+
+// Read implements io.Reader.
+func (r *decryptReader) Read(p []byte) (int, error) {
+	for len(r.plain) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+	return n, nil
+}
+
+func (r *decryptReader) open() error {
+	n, err := io.ReadFull(r.r, r.sealed)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return errors.Wrap(err, "failed to read encrypted chunk")
+	}
+	// Non-last chunks are always full, so a short chunk or a full chunk at the end of the stream is the last one.
+	last := n < len(r.sealed)
+	if !last {
+		if _, err := r.r.Peek(1); err == io.EOF {
+			last = true
+		}
+	}
+	plain, err := r.aead.Open(r.sealed[:0:0], getNonce(r.noncePrefix, r.counter, last), r.sealed[:n], nil)
+	if err != nil {
+		return errors.Errorf("failed to decrypt the backup, it may be corrupted or truncated")
+	}
+	r.counter++
+	r.plain = plain
+	r.done = last
+	return nil
+}
@@ -0,0 +1,166 @@
+// Package fs provides the client for a file system storage, e.g., a mounted NFS share.
+package fs
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/bytebase/bytebase/plugin/storage"
+)
+
+var _ storage.Client = (*Client)(nil)
+
+// Client stores the objects as files under the root directory.
+type Client struct {
+	root string
+}
+
+// NewClient returns a new file system storage client.
+// The root directory must exist, it's usually the mount point of a network file system.
+func NewClient(root string) (*Client, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the absolute path of %q", root)
+	}
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to access the storage directory %q", root)
+	}
+	if !info.IsDir() {
+		return nil, errors.Errorf("storage path %q is not a directory", root)
+	}
+	return &Client{root: root}, nil
+}
+
+// ListObjects lists objects with prefix in their names.
+func (c *Client) ListObjects(_ context.Context, prefix string) ([]*storage.Object, error) {
+	// Only walk the deepest directory containing all the objects with the prefix.
+	dir := path.Dir(prefix)
+	if strings.HasSuffix(prefix, "/") {
+		dir = strings.TrimSuffix(prefix, "/")
+	}
+	walkRoot, err := c.getFilePath(dir)
+	if err != nil {
+		return nil, err
+	}
+	var ret []*storage.Object
+	if err := filepath.WalkDir(walkRoot, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(c.root, filePath)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		// Skip the temporary files of ongoing uploads.
+		if !strings.HasPrefix(key, prefix) || strings.HasSuffix(key, uploadingSuffix) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		ret = append(ret, &storage.Object{
+			Key:          key,
+			LastModified: info.ModTime(),
+			Size:         info.Size(),
+		})
+		return nil
+	}); err != nil {
+		return nil, errors.Wrapf(err, "failed to list objects with prefix %q in %q", prefix, c.root)
+	}
+	return ret, nil
+}
+
+// DownloadObject downloads the object with path.
+func (c *Client) DownloadObject(_ context.Context, path string, w io.WriterAt) (int64, error) {
+	filePath, err := c.getFilePath(path)
+	if err != nil {
+		return 0, err
+	}
+	f, err := os.Open(filePath)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to open file %q", filePath)
+	}
+	defer f.Close()
+	return io.Copy(storage.NewOffsetWriter(w), f)
+}
+
+const uploadingSuffix = ".uploading"
+
+// UploadObject uploads an object with the path.
+// The object is first written to a temporary file and then renamed, so that readers never see a partial object.
+func (c *Client) UploadObject(_ context.Context, path string, body io.Reader) error {
+	filePath, err := c.getFilePath(path)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
+		return errors.Wrapf(err, "failed to create directory for %q", filePath)
+	}
+	filePathTemp := filePath + uploadingSuffix
+	f, err := os.Create(filePathTemp)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create file %q", filePathTemp)
+	}
+	if _, err := io.Copy(f, body); err != nil {
+		f.Close()
+		os.Remove(filePathTemp)
+		return errors.Wrapf(err, "failed to write file %q", filePathTemp)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(filePathTemp)
+		return errors.Wrapf(err, "failed to sync file %q", filePathTemp)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(filePathTemp)
+		return errors.Wrapf(err, "failed to close file %q", filePathTemp)
+	}
+	if err := os.Rename(filePathTemp, filePath); err != nil {
+		return errors.Wrapf(err, "failed to rename %q to %q", filePathTemp, filePath)
+	}
+	return nil
+}
+
+// DeleteObjects deletes the objects with path.
+func (c *Client) DeleteObjects(_ context.Context, pathList ...string) error {
+	for _, path := range pathList {
+		filePath, err := c.getFilePath(path)
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "failed to delete file %q", filePath)
+		}
+	}
+	return nil
+}
+
+// GetBucket returns the root directory.
+func (c *Client) GetBucket() string {
+	return c.root
+}
+
+// getFilePath returns the file path of the object, and rejects paths escaping the root directory.
+func (c *Client) getFilePath(objectPath string) (string, error) {
+	filePath := filepath.Join(c.root, filepath.FromSlash(objectPath))
+	if filePath != c.root && !strings.HasPrefix(filePath, c.root+string(filepath.Separator)) {
+		return "", errors.Errorf("object path %q is outside of the storage directory %q", objectPath, c.root)
+	}
+	return filePath, nil
+}
@@ -0,0 +1,64 @@
+package fs
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSystemOperations(t *testing.T) {
+	a := require.New(t)
+	ctx := context.Background()
+	client, err := NewClient(t.TempDir())
+	a.NoError(err)
+
+	for _, path := range []string{"backup/db/1/a.sql", "backup/db/1/b.sql", "backup/db/12/c.sql", "backup/instance/1/binlog.000001"} {
+		err := client.UploadObject(ctx, path, bytes.NewReader([]byte(path)))
+		a.NoError(err)
+	}
+
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{prefix: "backup/db/1/", want: []string{"backup/db/1/a.sql", "backup/db/1/b.sql"}},
+		{prefix: "backup/db/1", want: []string{"backup/db/1/a.sql", "backup/db/1/b.sql", "backup/db/12/c.sql"}},
+		{prefix: "backup/instance", want: []string{"backup/instance/1/binlog.000001"}},
+		{prefix: "backup/none/", want: nil},
+	}
+	for _, test := range tests {
+		list, err := client.ListObjects(ctx, test.prefix)
+		a.NoError(err)
+		var got []string
+		for _, object := range list {
+			got = append(got, object.Key)
+			a.Equal(int64(len(object.Key)), object.Size)
+		}
+		sort.Strings(got)
+		a.Equal(test.want, got, test.prefix)
+	}
+
+	file, err := os.Create(filepath.Join(t.TempDir(), "download"))
+	a.NoError(err)
+	defer file.Close()
+	n, err := client.DownloadObject(ctx, "backup/db/1/a.sql", file)
+	a.NoError(err)
+	a.Equal(int64(len("backup/db/1/a.sql")), n)
+	content, err := os.ReadFile(file.Name())
+	a.NoError(err)
+	a.Equal("backup/db/1/a.sql", string(content))
+
+	err = client.DeleteObjects(ctx, "backup/db/1/a.sql", "backup/db/1/not_exist.sql")
+	a.NoError(err)
+	list, err := client.ListObjects(ctx, "backup/db/1/")
+	a.NoError(err)
+	a.Len(list, 1)
+
+	err = client.UploadObject(ctx, "../escape", bytes.NewReader(nil))
+	a.Error(err)
+}
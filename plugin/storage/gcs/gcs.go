@@ -0,0 +1,101 @@
+// Package gcs provides the client for Google Cloud Storage.
+package gcs
+
+import (
+	"context"
+	"io"
+
+	"cloud.google.com/go/storage"
+	"github.com/pkg/errors"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+
+	bbstorage "github.com/bytebase/bytebase/plugin/storage"
+)
+
+var _ bbstorage.Client = (*Client)(nil)
+
+// Client wraps the Google Cloud Storage client.
+type Client struct {
+	c      *storage.Client
+	bucket string
+}
+
+// NewClient returns a new Google Cloud Storage client.
+// credentialsFileName is the path of the service account key file.
+// The client connects to the emulator, e.g., fake-gcs-server, if the STORAGE_EMULATOR_HOST environment variable is set.
+func NewClient(ctx context.Context, bucket, credentialsFileName string) (*Client, error) {
+	var opts []option.ClientOption
+	if credentialsFileName != "" {
+		opts = append(opts, option.WithCredentialsFile(credentialsFileName))
+	}
+	c, err := storage.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create Google Cloud Storage client")
+	}
+	return &Client{
+		c:      c,
+		bucket: bucket,
+	}, nil
+}
+
+// ListObjects lists objects with prefix in their names.
+func (c *Client) ListObjects(ctx context.Context, prefix string) ([]*bbstorage.Object, error) {
+	var ret []*bbstorage.Object
+	it := c.c.Bucket(c.bucket).Objects(ctx, &storage.Query{Prefix: prefix})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to load the next page of Google Cloud Storage objects")
+		}
+		ret = append(ret, &bbstorage.Object{
+			Key:          attrs.Name,
+			LastModified: attrs.Updated,
+			Size:         attrs.Size,
+		})
+	}
+	return ret, nil
+}
+
+// DownloadObject downloads the object with path.
+func (c *Client) DownloadObject(ctx context.Context, path string, w io.WriterAt) (int64, error) {
+	r, err := c.c.Bucket(c.bucket).Object(path).NewReader(ctx)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to read object %q from Google Cloud Storage", path)
+	}
+	defer r.Close()
+	return io.Copy(bbstorage.NewOffsetWriter(w), r)
+}
+
+// UploadObject uploads an object with the path.
+func (c *Client) UploadObject(ctx context.Context, path string, body io.Reader) error {
+	// Canceling the context aborts the upload, so that no partial object is created on failures.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	w := c.c.Bucket(c.bucket).Object(path).NewWriter(ctx)
+	if _, err := io.Copy(w, body); err != nil {
+		return errors.Wrapf(err, "failed to upload object %q to Google Cloud Storage", path)
+	}
+	if err := w.Close(); err != nil {
+		return errors.Wrapf(err, "failed to finish uploading object %q to Google Cloud Storage", path)
+	}
+	return nil
+}
+
+// DeleteObjects deletes the objects with path.
+func (c *Client) DeleteObjects(ctx context.Context, pathList ...string) error {
+	for _, path := range pathList {
+		if err := c.c.Bucket(c.bucket).Object(path).Delete(ctx); err != nil && err != storage.ErrObjectNotExist {
+			return errors.Wrapf(err, "failed to delete object %q in Google Cloud Storage", path)
+		}
+	}
+	return nil
+}
+
+// GetBucket returns the bucket.
+func (c *Client) GetBucket() string {
+	return c.bucket
+}
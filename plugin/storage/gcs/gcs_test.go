@@ -0,0 +1,57 @@
+package gcs
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/bytebase/bytebase/common/log"
+)
+
+const (
+	bucket = "bytebase-dev"
+)
+
+// Only for manual test.
+// Should be skipped in CI.
+// To test with fake-gcs-server, run `docker run -d -p 4443:4443 fsouza/fake-gcs-server -scheme http -public-host localhost:4443`,
+// create the bucket and set STORAGE_EMULATOR_HOST=localhost:4443.
+func TestGCSOperations(t *testing.T) {
+	t.Skip()
+	a := require.New(t)
+	ctx := context.Background()
+	client, err := NewClient(ctx, bucket, os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"))
+	a.NoError(err)
+
+	t.Run("UploadObjects", func(t *testing.T) {
+		buf := make([]byte, 10*1024*1024)
+		blob := bytes.NewReader(buf)
+		err := client.UploadObject(ctx, "backup/test/blob", blob)
+		a.NoError(err)
+	})
+
+	t.Run("ListObjects", func(t *testing.T) {
+		list, err := client.ListObjects(ctx, "backup/")
+		a.NoError(err)
+		for _, obj := range list {
+			log.Info("Object", zap.String("Key", obj.Key), zap.Time("LastModified", obj.LastModified))
+		}
+	})
+
+	t.Run("DownloadObjects", func(t *testing.T) {
+		file, err := os.CreateTemp(t.TempDir(), "blob")
+		a.NoError(err)
+		n, err := client.DownloadObject(ctx, "backup/test/blob", file)
+		a.NoError(err)
+		a.Equal(int64(10*1024*1024), n)
+	})
+
+	t.Run("DeleteObjects", func(t *testing.T) {
+		err := client.DeleteObjects(ctx, "backup/test/blob")
+		a.NoError(err)
+	})
+}
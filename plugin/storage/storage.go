@@ -0,0 +1,72 @@
+// Package storage provides the interface for the backup storage backends.
+package storage
+
+import (
+	"context"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Object is the metadata of an object in the storage backend.
+type Object struct {
+	// Key is the path of the object relative to the bucket.
+	Key          string
+	LastModified time.Time
+	Size         int64
+}
+
+// Client is the interface of a backup storage backend, e.g., AWS S3, Google Cloud Storage, Azure Blob Storage or a mounted file system.
+// Object paths always use / as the separator.
+type Client interface {
+	// ListObjects lists objects with prefix in their names.
+	ListObjects(ctx context.Context, prefix string) ([]*Object, error)
+	// UploadObject uploads an object with the path.
+	UploadObject(ctx context.Context, path string, body io.Reader) error
+	// DownloadObject downloads the object with path and returns the number of bytes written.
+	DownloadObject(ctx context.Context, path string, w io.WriterAt) (int64, error)
+	// DeleteObjects deletes the objects with path.
+	DeleteObjects(ctx context.Context, pathList ...string) error
+	// GetBucket returns the bucket, container or directory of the storage backend.
+	GetBucket() string
+}
+
+// DownloadFileFromCloud downloads a binlog or metadata file from the cloud storage.
+// In case of network errors which will get partially downloaded files, we first download to a temporary file.
+// After that, we then rename it to the target file path.
+func DownloadFileFromCloud(ctx context.Context, client Client, filePathLocal, filePathOnCloud string) error {
+	filePathTemp := filePathLocal + ".tmp"
+	fileTemp, err := os.Create(filePathTemp)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create the local temporary file %s", filePathTemp)
+	}
+	defer fileTemp.Close()
+	if _, err := client.DownloadObject(ctx, filePathOnCloud, fileTemp); err != nil {
+		return errors.Wrapf(err, "failed to download file %q from the cloud storage", filePathOnCloud)
+	}
+	if err := os.Rename(filePathTemp, filePathLocal); err != nil {
+		return errors.Wrapf(err, "failed to rename %q to %q", filePathTemp, filePathLocal)
+	}
+	return nil
+}
+
+// OffsetWriter adapts an io.WriterAt to an io.Writer by writing sequentially from offset 0.
+// It's used by the storage backends whose SDKs download objects as streams.
+type OffsetWriter struct {
+	w   io.WriterAt
+	off int64
+}
+
+// NewOffsetWriter returns an OffsetWriter writing to w.
+func NewOffsetWriter(w io.WriterAt) *OffsetWriter {
+	return &OffsetWriter{w: w}
+}
+
+// Write implements io.Writer.
+func (o *OffsetWriter) Write(p []byte) (int, error) {
+	n, err := o.w.WriteAt(p, o.off)
+	o.off += int64(n)
+	return n, err
+}
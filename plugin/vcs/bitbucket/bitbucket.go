@@ -0,0 +1,1872 @@
+// Package bitbucket is the plugin for Bitbucket Server (Data Center).
+package bitbucket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/plugin/vcs"
+	"github.com/bytebase/bytebase/plugin/vcs/internal/oauth"
+)
+
+const (
+	// SecretTokenLength is the length of secret token.
+	SecretTokenLength = 16
+
+	// apiPath is the API path.
+	apiPath = "rest/api/1.0"
+	// buildStatusAPIPath is the API path of the build status, which is not a
+	// part of the core REST API.
+	buildStatusAPIPath = "rest/build-status/1.0"
+	// apiPageSize is the default page size when making API requests. Bitbucket
+	// Server caps the page size to 1000 by default.
+	apiPageSize = 100
+
+	// emptyCommitID is the commit ID of the "fromHash" in the push event when a
+	// new branch is created.
+	emptyCommitID = "0000000000000000000000000000000000000000"
+)
+
+func init() {
+	vcs.Register(vcs.BitbucketServer, newProvider)
+}
+
+var _ vcs.Provider = (*Provider)(nil)
+
+// Provider is a Bitbucket Server VCS provider.
+type Provider struct {
+	client *http.Client
+}
+
+func newProvider(config vcs.ProviderConfig) vcs.Provider {
+	if config.Client == nil {
+		config.Client = &http.Client{}
+	}
+	return &Provider{
+		client: config.Client,
+	}
+}
+
+// APIURL returns the API URL path of a Bitbucket Server instance.
+func (*Provider) APIURL(instanceURL string) string {
+	return fmt.Sprintf("%s/%s", instanceURL, apiPath)
+}
+
+// repositoryPath returns the API path of the repository. The repositoryID is
+// in the form of "{projectKey}/{repositorySlug}".
+func repositoryPath(repositoryID string) (string, error) {
+	projectKey, repositorySlug, ok := strings.Cut(repositoryID, "/")
+	if !ok || projectKey == "" || repositorySlug == "" {
+		return "", errors.Errorf("invalid Bitbucket repository ID %q, want {projectKey}/{repositorySlug}", repositoryID)
+	}
+	return fmt.Sprintf("projects/%s/repos/%s", projectKey, repositorySlug), nil
+}
+
+// RepositoryRole is the permission of the repository user.
+type RepositoryRole string
+
+// The list of Bitbucket Server repository permissions.
+const (
+	RepositoryRoleAdmin RepositoryRole = "REPO_ADMIN"
+	RepositoryRoleWrite RepositoryRole = "REPO_WRITE"
+	RepositoryRoleRead  RepositoryRole = "REPO_READ"
+)
+
+// page is the pagination information of a Bitbucket Server API response.
+//
+// Docs: https://docs.atlassian.com/bitbucket-server/rest/7.21.0/bitbucket-rest.html#paging-params
+type page struct {
+	IsLastPage    bool `json:"isLastPage"`
+	NextPageStart int  `json:"nextPageStart"`
+}
+
+// User represents a Bitbucket Server API response for a user.
+type User struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	Slug         string `json:"slug"`
+	EmailAddress string `json:"emailAddress"`
+	DisplayName  string `json:"displayName"`
+	Active       bool   `json:"active"`
+}
+
+// Link represents a Bitbucket Server API response for a link.
+type Link struct {
+	Href string `json:"href"`
+}
+
+// Links represents a Bitbucket Server API response for the links of a
+// resource.
+type Links struct {
+	Self []Link `json:"self"`
+}
+
+// Project represents a Bitbucket Server API response for a project.
+type Project struct {
+	Key string `json:"key"`
+}
+
+// Repository represents a Bitbucket Server API response for a repository.
+type Repository struct {
+	ID      int64   `json:"id"`
+	Slug    string  `json:"slug"`
+	Name    string  `json:"name"`
+	Project Project `json:"project"`
+	Links   Links   `json:"links"`
+}
+
+// Commit represents a Bitbucket Server API response for a commit.
+type Commit struct {
+	ID      string `json:"id"`
+	Message string `json:"message"`
+	Author  struct {
+		Name         string `json:"name"`
+		EmailAddress string `json:"emailAddress"`
+		DisplayName  string `json:"displayName"`
+	} `json:"author"`
+	// AuthorTimestamp is the Unix timestamp in milliseconds.
+	AuthorTimestamp int64 `json:"authorTimestamp"`
+}
+
+// ChangeType is the type of a file change.
+type ChangeType string
+
+// The list of Bitbucket Server file change types.
+const (
+	ChangeTypeAdd    ChangeType = "ADD"
+	ChangeTypeCopy   ChangeType = "COPY"
+	ChangeTypeDelete ChangeType = "DELETE"
+	ChangeTypeModify ChangeType = "MODIFY"
+	ChangeTypeMove   ChangeType = "MOVE"
+)
+
+// Change represents a Bitbucket Server API response for a file change.
+type Change struct {
+	Path struct {
+		ToString string `json:"toString"`
+	} `json:"path"`
+	Type ChangeType `json:"type"`
+}
+
+// WebhookType is the Bitbucket Server webhook event key.
+type WebhookType string
+
+const (
+	// WebhookPing is the webhook type for testing the connection.
+	WebhookPing WebhookType = "diagnostics:ping"
+	// WebhookPush is the webhook type for push, i.e. the refs of the repository
+	// have been changed.
+	WebhookPush WebhookType = "repo:refs_changed"
+	// WebhookPullRequestOpened is the webhook type for opening a pull request.
+	WebhookPullRequestOpened WebhookType = "pr:opened"
+	// WebhookPullRequestFromRefUpdated is the webhook type for pushing new
+	// commits to the source branch of a pull request.
+	WebhookPullRequestFromRefUpdated WebhookType = "pr:from_ref_updated"
+	// WebhookPullRequestMerged is the webhook type for merging a pull request.
+	WebhookPullRequestMerged WebhookType = "pr:merged"
+	// WebhookPullRequestDeclined is the webhook type for declining a pull request.
+	WebhookPullRequestDeclined WebhookType = "pr:declined"
+)
+
+// WebhookName is the name of the webhook created by Bytebase.
+const WebhookName = "Bytebase GitOps"
+
+// WebhookInfo represents a Bitbucket Server API response for the webhook
+// information.
+type WebhookInfo struct {
+	ID int `json:"id"`
+}
+
+// WebhookConfiguration represents the Bitbucket Server API message for webhook
+// configuration.
+type WebhookConfiguration struct {
+	// Secret is the secret will be used as the key to generate the HMAC hex digest
+	// value in the X-Hub-Signature header.
+	Secret string `json:"secret"`
+}
+
+// WebhookCreateOrUpdate represents a Bitbucket Server API request for creating
+// or updating a webhook.
+type WebhookCreateOrUpdate struct {
+	Name          string               `json:"name"`
+	URL           string               `json:"url"`
+	Active        bool                 `json:"active"`
+	Events        []string             `json:"events"`
+	Configuration WebhookConfiguration `json:"configuration"`
+}
+
+// WebhookRefChangeType is the type of a ref change in the push event.
+type WebhookRefChangeType string
+
+// The list of Bitbucket Server ref change types.
+const (
+	WebhookRefChangeAdd    WebhookRefChangeType = "ADD"
+	WebhookRefChangeDelete WebhookRefChangeType = "DELETE"
+	WebhookRefChangeUpdate WebhookRefChangeType = "UPDATE"
+)
+
+// WebhookRefChange is the API message for webhook ref change.
+type WebhookRefChange struct {
+	Ref struct {
+		ID        string `json:"id"`
+		DisplayID string `json:"displayId"`
+		// Type is either "BRANCH" or "TAG".
+		Type string `json:"type"`
+	} `json:"ref"`
+	RefID    string               `json:"refId"`
+	FromHash string               `json:"fromHash"`
+	ToHash   string               `json:"toHash"`
+	Type     WebhookRefChangeType `json:"type"`
+}
+
+// WebhookPushEvent is the API message for webhook push event.
+type WebhookPushEvent struct {
+	EventKey   WebhookType        `json:"eventKey"`
+	Actor      User               `json:"actor"`
+	Repository Repository         `json:"repository"`
+	Changes    []WebhookRefChange `json:"changes"`
+}
+
+// WebhookPullRequestRef is the API message for the source or target ref of
+// the webhook pull request.
+type WebhookPullRequestRef struct {
+	ID           string     `json:"id"`
+	DisplayID    string     `json:"displayId"`
+	LatestCommit string     `json:"latestCommit"`
+	Repository   Repository `json:"repository"`
+}
+
+// WebhookPullRequest is the API message for webhook pull request.
+type WebhookPullRequest struct {
+	ID      int                   `json:"id"`
+	Title   string                `json:"title"`
+	FromRef WebhookPullRequestRef `json:"fromRef"`
+	ToRef   WebhookPullRequestRef `json:"toRef"`
+	Links   Links                 `json:"links"`
+}
+
+// WebhookPullRequestEvent is the API message for webhook pull request event.
+type WebhookPullRequestEvent struct {
+	EventKey    WebhookType        `json:"eventKey"`
+	Actor       User               `json:"actor"`
+	PullRequest WebhookPullRequest `json:"pullRequest"`
+}
+
+// fetchUserInfoImpl fetches the user information by the username.
+//
+// Docs: https://docs.atlassian.com/bitbucket-server/rest/7.21.0/bitbucket-rest.html#idp427
+func (p *Provider) fetchUserInfoImpl(ctx context.Context, oauthCtx common.OauthContext, instanceURL, username string) (*vcs.UserInfo, error) {
+	url := fmt.Sprintf("%s/users?filter=%s", p.APIURL(instanceURL), url.QueryEscape(username))
+	code, _, body, err := oauth.Get(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "GET")
+	}
+
+	if code == http.StatusNotFound {
+		return nil, common.Errorf(common.NotFound, "failed to read user info from URL %s", url)
+	} else if code >= 300 {
+		return nil, errors.Errorf("failed to read user info from URL %s, status code: %d, body: %s", url, code, body)
+	}
+
+	var resp struct {
+		Values []User `json:"values"`
+	}
+	if err = json.Unmarshal([]byte(body), &resp); err != nil {
+		return nil, errors.Wrap(err, "unmarshal")
+	}
+	// The filter matches the username, display name and email address by prefix,
+	// thus we need to find the exact match.
+	for _, user := range resp.Values {
+		if user.Name != username {
+			continue
+		}
+		state := vcs.StateActive
+		if !user.Active {
+			state = vcs.StateArchived
+		}
+		return &vcs.UserInfo{
+			PublicEmail: user.EmailAddress,
+			Name:        user.DisplayName,
+			State:       state,
+		}, nil
+	}
+	return nil, common.Errorf(common.NotFound, "failed to find user %q from URL %s", username, url)
+}
+
+// TryLogin tries to fetch the user info from the current OAuth context.
+//
+// Bitbucket Server does not have an API to get the authenticated user, but
+// every API response carries the username in the "X-AUSERNAME" header.
+func (p *Provider) TryLogin(ctx context.Context, oauthCtx common.OauthContext, instanceURL string) (*vcs.UserInfo, error) {
+	url := fmt.Sprintf("%s/application-properties", p.APIURL(instanceURL))
+	code, header, body, err := oauth.Get(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "GET")
+	}
+
+	if code >= 300 {
+		return nil, errors.Errorf("failed to read application properties from URL %s, status code: %d, body: %s", url, code, body)
+	}
+
+	username := header.Get("X-AUSERNAME")
+	if username == "" {
+		return nil, errors.Errorf("failed to get the authenticated user from URL %s, the X-AUSERNAME header is missing", url)
+	}
+	return p.fetchUserInfoImpl(ctx, oauthCtx, instanceURL, username)
+}
+
+// FetchCommitByID fetches the commit data by its ID from the repository.
+//
+// Docs: https://docs.atlassian.com/bitbucket-server/rest/7.21.0/bitbucket-rest.html#idp224
+func (p *Provider) FetchCommitByID(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, commitID string) (*vcs.Commit, error) {
+	repoPath, err := repositoryPath(repositoryID)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := p.fetchCommit(ctx, oauthCtx, instanceURL, repoPath, commitID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vcs.Commit{
+		ID:          commit.ID,
+		AuthorName:  commit.Author.Name,
+		AuthorEmail: commit.Author.EmailAddress,
+		CreatedTs:   commit.AuthorTimestamp / 1000,
+	}, nil
+}
+
+// fetchCommit fetches the commit by its ID from the repository.
+func (p *Provider) fetchCommit(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repoPath, commitID string) (*Commit, error) {
+	url := fmt.Sprintf("%s/%s/commits/%s", p.APIURL(instanceURL), repoPath, commitID)
+	code, _, body, err := oauth.Get(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "GET")
+	}
+
+	if code == http.StatusNotFound {
+		return nil, common.Errorf(common.NotFound, "failed to fetch commit data from URL %s", url)
+	} else if code >= 300 {
+		return nil, errors.Errorf("failed to fetch commit data from URL %s, status code: %d, body: %s", url, code, body)
+	}
+
+	commit := &Commit{}
+	if err := json.Unmarshal([]byte(body), commit); err != nil {
+		return nil, errors.Wrap(err, "unmarshal body")
+	}
+	return commit, nil
+}
+
+// FetchUserInfo fetches user info of given username.
+func (p *Provider) FetchUserInfo(ctx context.Context, oauthCtx common.OauthContext, instanceURL, username string) (*vcs.UserInfo, error) {
+	return p.fetchUserInfoImpl(ctx, oauthCtx, instanceURL, username)
+}
+
+func getRoleAndMappedRole(permission string) (bitbucketRole RepositoryRole, bytebaseRole common.ProjectRole) {
+	// Keep the same mapping as GitHub, where the users who can push to the
+	// repository are the project owners.
+	switch RepositoryRole(permission) {
+	case RepositoryRoleAdmin:
+		return RepositoryRoleAdmin, common.ProjectOwner
+	case RepositoryRoleWrite:
+		return RepositoryRoleWrite, common.ProjectOwner
+	case RepositoryRoleRead:
+		return RepositoryRoleRead, common.ProjectDeveloper
+	}
+	return "", ""
+}
+
+type repositoryUserPermission struct {
+	User       User   `json:"user"`
+	Permission string `json:"permission"`
+}
+
+// FetchRepositoryActiveMemberList fetch all active members of a repository.
+//
+// NOTE: Only the users that are explicitly granted the repository permissions
+// are returned, the permissions inherited from the project or global settings
+// are not included.
+//
+// Docs: https://docs.atlassian.com/bitbucket-server/rest/7.21.0/bitbucket-rest.html#idp300
+func (p *Provider) FetchRepositoryActiveMemberList(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID string) ([]*vcs.RepositoryMember, error) {
+	repoPath, err := repositoryPath(repositoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	var allPermissions []repositoryUserPermission
+	start := 0
+	for {
+		permissions, nextPage, err := p.fetchPaginatedRepositoryUserPermissions(ctx, oauthCtx, instanceURL, repoPath, start)
+		if err != nil {
+			return nil, errors.Wrap(err, "fetch paginated list")
+		}
+		allPermissions = append(allPermissions, permissions...)
+
+		if nextPage.IsLastPage {
+			break
+		}
+		start = nextPage.NextPageStart
+	}
+
+	var emptyEmailUserList []string
+	var allMembers []*vcs.RepositoryMember
+	for _, permission := range allPermissions {
+		if !permission.User.Active {
+			continue
+		}
+		if permission.User.EmailAddress == "" {
+			emptyEmailUserList = append(emptyEmailUserList, permission.User.DisplayName)
+			continue
+		}
+
+		bitbucketRole, bytebaseRole := getRoleAndMappedRole(permission.Permission)
+		if bytebaseRole == "" {
+			continue
+		}
+		allMembers = append(allMembers,
+			&vcs.RepositoryMember{
+				Name:         permission.User.DisplayName,
+				Email:        permission.User.EmailAddress,
+				Role:         bytebaseRole,
+				VCSRole:      string(bitbucketRole),
+				State:        vcs.StateActive,
+				RoleProvider: vcs.BitbucketServer,
+			},
+		)
+	}
+
+	if len(emptyEmailUserList) != 0 {
+		return nil, errors.Errorf("[ %v ] did not configure their email address in Bitbucket, please make sure every members' email is set before syncing", strings.Join(emptyEmailUserList, ", "))
+	}
+
+	return allMembers, nil
+}
+
+// fetchPaginatedRepositoryUserPermissions fetches user permissions of a
+// repository starting from given index. It return the paginated results along
+// with the pagination information.
+func (p *Provider) fetchPaginatedRepositoryUserPermissions(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repoPath string, start int) (permissions []repositoryUserPermission, nextPage page, err error) {
+	url := fmt.Sprintf("%s/%s/permissions/users?start=%d&limit=%d", p.APIURL(instanceURL), repoPath, start, apiPageSize)
+	code, _, body, err := oauth.Get(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return nil, page{}, errors.Wrapf(err, "GET %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return nil, page{}, common.Errorf(common.NotFound, "failed to fetch repository user permissions from URL %s", url)
+	} else if code >= 300 {
+		return nil, page{},
+			errors.Errorf("failed to read repository user permissions from URL %s, status code: %d, body: %s",
+				url,
+				code,
+				body,
+			)
+	}
+
+	var resp struct {
+		page
+		Values []repositoryUserPermission `json:"values"`
+	}
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		return nil, page{}, errors.Wrap(err, "unmarshal body")
+	}
+	return resp.Values, resp.page, nil
+}
+
+// oauthResponse is a Bitbucket Server OAuth response.
+type oauthResponse struct {
+	AccessToken      string `json:"access_token" `
+	RefreshToken     string `json:"refresh_token"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error,omitempty"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// toVCSOAuthToken converts the response to *vcs.OAuthToken.
+func (o oauthResponse) toVCSOAuthToken() *vcs.OAuthToken {
+	oauthToken := &vcs.OAuthToken{
+		AccessToken:  o.AccessToken,
+		RefreshToken: o.RefreshToken,
+		ExpiresIn:    o.ExpiresIn,
+		// Bitbucket Server does not return the creation time of the token.
+		CreatedAt: time.Now().Unix(),
+	}
+	if oauthToken.ExpiresIn != 0 {
+		oauthToken.ExpiresTs = oauthToken.CreatedAt + oauthToken.ExpiresIn
+	}
+	return oauthToken
+}
+
+// ExchangeOAuthToken exchanges OAuth content with the provided authorization code.
+//
+// Docs: https://confluence.atlassian.com/bitbucketserver/bitbucket-oauth-2-0-provider-api-1108483661.html
+func (p *Provider) ExchangeOAuthToken(ctx context.Context, instanceURL string, oauthExchange *common.OAuthExchange) (*vcs.OAuthToken, error) {
+	urlParams := &url.Values{}
+	urlParams.Set("client_id", oauthExchange.ClientID)
+	urlParams.Set("client_secret", oauthExchange.ClientSecret)
+	urlParams.Set("code", oauthExchange.Code)
+	urlParams.Set("redirect_uri", oauthExchange.RedirectURL)
+	urlParams.Set("grant_type", "authorization_code")
+	url := fmt.Sprintf("%s/rest/oauth2/latest/token?%s", instanceURL, urlParams.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		urlParams.Set("client_secret", "**redacted**")
+		redactedURL := fmt.Sprintf("%s/rest/oauth2/latest/token?%s", instanceURL, urlParams.Encode())
+		return nil, errors.Wrapf(err, "construct POST %s", redactedURL)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to exchange OAuth token")
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read OAuth response body, code %v", resp.StatusCode)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	oauthResp := new(oauthResponse)
+	if err := json.Unmarshal(body, oauthResp); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal OAuth response body, code %v", resp.StatusCode)
+	}
+	if oauthResp.Error != "" {
+		return nil, errors.Errorf("failed to exchange OAuth token, error: %v, error_description: %v", oauthResp.Error, oauthResp.ErrorDescription)
+	}
+	return oauthResp.toVCSOAuthToken(), nil
+}
+
+// FetchAllRepositoryList fetches all repositories where the authenticated user
+// has admin permissions, which is required to create webhook in the repository.
+//
+// Docs: https://docs.atlassian.com/bitbucket-server/rest/7.21.0/bitbucket-rest.html#idp403
+func (p *Provider) FetchAllRepositoryList(ctx context.Context, oauthCtx common.OauthContext, instanceURL string) ([]*vcs.Repository, error) {
+	var bitbucketRepos []Repository
+	start := 0
+	for {
+		repos, nextPage, err := p.fetchPaginatedRepositoryList(ctx, oauthCtx, instanceURL, start)
+		if err != nil {
+			return nil, errors.Wrap(err, "fetch paginated list")
+		}
+		bitbucketRepos = append(bitbucketRepos, repos...)
+
+		if nextPage.IsLastPage {
+			break
+		}
+		start = nextPage.NextPageStart
+	}
+
+	var allRepos []*vcs.Repository
+	for _, r := range bitbucketRepos {
+		var webURL string
+		if len(r.Links.Self) > 0 {
+			webURL = r.Links.Self[0].Href
+		}
+		allRepos = append(allRepos,
+			&vcs.Repository{
+				ID:       r.ID,
+				Name:     r.Name,
+				FullPath: fmt.Sprintf("%s/%s", r.Project.Key, r.Slug),
+				WebURL:   webURL,
+			},
+		)
+	}
+	return allRepos, nil
+}
+
+// fetchPaginatedRepositoryList fetches repositories where the authenticated
+// user has admin permissions starting from given index. It returns the
+// paginated results along with the pagination information.
+func (p *Provider) fetchPaginatedRepositoryList(ctx context.Context, oauthCtx common.OauthContext, instanceURL string, start int) (repos []Repository, nextPage page, err error) {
+	url := fmt.Sprintf("%s/repos?permission=%s&start=%d&limit=%d", p.APIURL(instanceURL), RepositoryRoleAdmin, start, apiPageSize)
+	code, _, body, err := oauth.Get(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return nil, page{}, errors.Wrapf(err, "GET %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return nil, page{}, common.Errorf(common.NotFound, "failed to fetch repository list from URL %s", url)
+	} else if code >= 300 {
+		return nil, page{},
+			errors.Errorf("failed to fetch repository list from URL %s, status code: %d, body: %s",
+				url,
+				code,
+				body,
+			)
+	}
+
+	var resp struct {
+		page
+		Values []Repository `json:"values"`
+	}
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		return nil, page{}, errors.Wrap(err, "unmarshal")
+	}
+	return resp.Values, resp.page, nil
+}
+
+// FetchRepositoryFileList fetches the all files under the given path of the
+// repository recursively.
+//
+// Docs: https://docs.atlassian.com/bitbucket-server/rest/7.21.0/bitbucket-rest.html#idp358
+func (p *Provider) FetchRepositoryFileList(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, ref, filePath string) ([]*vcs.RepositoryTreeNode, error) {
+	repoPath, err := repositoryPath(repositoryID)
+	if err != nil {
+		return nil, err
+	}
+	filePath = strings.Trim(filePath, "/")
+
+	var allTreeNodes []*vcs.RepositoryTreeNode
+	start := 0
+	for {
+		files, nextPage, err := p.fetchPaginatedRepositoryFileList(ctx, oauthCtx, instanceURL, repoPath, ref, filePath, start)
+		if err != nil {
+			return nil, errors.Wrap(err, "fetch paginated list")
+		}
+		for _, file := range files {
+			// The returned paths are relative to the requested path.
+			path := file
+			if filePath != "" {
+				path = fmt.Sprintf("%s/%s", filePath, file)
+			}
+			allTreeNodes = append(allTreeNodes,
+				&vcs.RepositoryTreeNode{
+					Path: path,
+					Type: "blob",
+				},
+			)
+		}
+
+		if nextPage.IsLastPage {
+			break
+		}
+		start = nextPage.NextPageStart
+	}
+	return allTreeNodes, nil
+}
+
+// fetchPaginatedRepositoryFileList fetches the files under the given path of
+// the repository starting from given index.
+func (p *Provider) fetchPaginatedRepositoryFileList(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repoPath, ref, filePath string, start int) (files []string, nextPage page, err error) {
+	url := fmt.Sprintf("%s/%s/files/%s?at=%s&start=%d&limit=%d", p.APIURL(instanceURL), repoPath, escapeFilePath(filePath), url.QueryEscape(ref), start, apiPageSize)
+	code, _, body, err := oauth.Get(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return nil, page{}, errors.Wrapf(err, "GET %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return nil, page{}, common.Errorf(common.NotFound, "failed to fetch repository file list from URL %s", url)
+	} else if code >= 300 {
+		return nil, page{},
+			errors.Errorf("failed to fetch repository file list from URL %s, status code: %d, body: %s",
+				url,
+				code,
+				body,
+			)
+	}
+
+	var resp struct {
+		page
+		Values []string `json:"values"`
+	}
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		return nil, page{}, errors.Wrap(err, "unmarshal body")
+	}
+	return resp.Values, resp.page, nil
+}
+
+// CreateFile creates a file at given path in the repository.
+//
+// Docs: https://docs.atlassian.com/bitbucket-server/rest/7.21.0/bitbucket-rest.html#idp218
+func (p *Provider) CreateFile(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, filePath string, fileCommitCreate vcs.FileCommitCreate) error {
+	return p.commitFile(ctx, oauthCtx, instanceURL, repositoryID, filePath, fileCommitCreate)
+}
+
+// OverwriteFile overwrites an existing file at given path in the repository.
+// The LastCommitID must be the latest commit that changed the file, which can
+// be obtained by the ReadFileMeta.
+//
+// Docs: https://docs.atlassian.com/bitbucket-server/rest/7.21.0/bitbucket-rest.html#idp218
+func (p *Provider) OverwriteFile(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, filePath string, fileCommitCreate vcs.FileCommitCreate) error {
+	return p.commitFile(ctx, oauthCtx, instanceURL, repositoryID, filePath, fileCommitCreate)
+}
+
+// commitFile creates or updates the file at given path in the repository, the
+// file is created if the "sourceCommitId" is absent.
+func (p *Provider) commitFile(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, filePath string, fileCommitCreate vcs.FileCommitCreate) error {
+	repoPath, err := repositoryPath(repositoryID)
+	if err != nil {
+		return err
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	fields := []struct {
+		name  string
+		value string
+	}{
+		{name: "branch", value: fileCommitCreate.Branch},
+		{name: "content", value: fileCommitCreate.Content},
+		{name: "message", value: fileCommitCreate.CommitMessage},
+		{name: "sourceCommitId", value: fileCommitCreate.LastCommitID},
+	}
+	for _, field := range fields {
+		if field.value == "" {
+			continue
+		}
+		if err := writer.WriteField(field.name, field.value); err != nil {
+			return errors.Wrapf(err, "write field %q", field.name)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return errors.Wrap(err, "close multipart writer")
+	}
+
+	url := fmt.Sprintf("%s/%s/browse/%s", p.APIURL(instanceURL), repoPath, escapeFilePath(filePath))
+	code, _, resp, err := oauth.PutWithContentType(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		writer.FormDataContentType(),
+		&body,
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "PUT %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return common.Errorf(common.NotFound, "failed to create/update file through URL %s", url)
+	} else if code >= 300 {
+		return errors.Errorf("failed to create/update file through URL %s, status code: %d, body: %s",
+			url,
+			code,
+			resp,
+		)
+	}
+	return nil
+}
+
+// ReadFileMeta reads the metadata of the given file in the repository.
+//
+// Bitbucket Server does not have an API for the file metadata, thus we use the
+// latest commit that changed the file as the LastCommitID, which is required
+// to overwrite the file.
+//
+// Docs: https://docs.atlassian.com/bitbucket-server/rest/7.21.0/bitbucket-rest.html#idp222
+func (p *Provider) ReadFileMeta(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, filePath, ref string) (*vcs.FileMeta, error) {
+	content, err := p.ReadFileContent(ctx, oauthCtx, instanceURL, repositoryID, filePath, ref)
+	if err != nil {
+		return nil, errors.Wrap(err, "read file content")
+	}
+
+	repoPath, err := repositoryPath(repositoryID)
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/%s/commits?path=%s&until=%s&limit=1", p.APIURL(instanceURL), repoPath, url.QueryEscape(filePath), url.QueryEscape(ref))
+	code, _, body, err := oauth.Get(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "GET %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return nil, common.Errorf(common.NotFound, "failed to read file commits from URL %s", url)
+	} else if code >= 300 {
+		return nil, errors.Errorf("failed to read file commits from URL %s, status code: %d, body: %s",
+			url,
+			code,
+			body,
+		)
+	}
+
+	var resp struct {
+		Values []Commit `json:"values"`
+	}
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		return nil, errors.Wrap(err, "unmarshal body")
+	}
+	if len(resp.Values) == 0 {
+		return nil, common.Errorf(common.NotFound, "failed to find commits of file %q from URL %s", filePath, url)
+	}
+
+	name := filePath
+	if i := strings.LastIndex(filePath, "/"); i >= 0 {
+		name = filePath[i+1:]
+	}
+	return &vcs.FileMeta{
+		Name:         name,
+		Path:         filePath,
+		Size:         int64(len(content)),
+		LastCommitID: resp.Values[0].ID,
+	}, nil
+}
+
+// ReadFileContent reads the content of the given file in the repository.
+//
+// Docs: https://docs.atlassian.com/bitbucket-server/rest/7.21.0/bitbucket-rest.html#idp372
+func (p *Provider) ReadFileContent(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, filePath, ref string) (string, error) {
+	repoPath, err := repositoryPath(repositoryID)
+	if err != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf("%s/%s/raw/%s?at=%s", p.APIURL(instanceURL), repoPath, escapeFilePath(filePath), url.QueryEscape(ref))
+	code, _, body, err := oauth.Get(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return "", errors.Wrapf(err, "GET %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return "", common.Errorf(common.NotFound, "failed to read file from URL %s", url)
+	} else if code >= 300 {
+		return "", errors.Errorf("failed to read file from URL %s, status code: %d, body: %s",
+			url,
+			code,
+			body,
+		)
+	}
+	return body, nil
+}
+
+type bitbucketPullRequestRef struct {
+	ID           string `json:"id"`
+	LatestCommit string `json:"latestCommit,omitempty"`
+	Repository   struct {
+		Slug    string  `json:"slug"`
+		Project Project `json:"project"`
+	} `json:"repository"`
+}
+
+type bitbucketPullRequest struct {
+	ID      int                     `json:"id"`
+	FromRef bitbucketPullRequestRef `json:"fromRef"`
+	Links   Links                   `json:"links"`
+}
+
+type bitbucketPullRequestCreate struct {
+	Title       string                  `json:"title"`
+	Description string                  `json:"description"`
+	FromRef     bitbucketPullRequestRef `json:"fromRef"`
+	ToRef       bitbucketPullRequestRef `json:"toRef"`
+}
+
+// ListPullRequestFile lists the changed files in the pull request.
+//
+// Docs: https://docs.atlassian.com/bitbucket-server/rest/7.21.0/bitbucket-rest.html#idp286
+func (p *Provider) ListPullRequestFile(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, pullRequestID string) ([]*vcs.PullRequestFile, error) {
+	repoPath, err := repositoryPath(repositoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The changed files do not carry the ref, thus we use the latest commit of
+	// the source branch for all of them.
+	pr, err := p.getPullRequest(ctx, oauthCtx, instanceURL, repoPath, pullRequestID)
+	if err != nil {
+		return nil, errors.Wrap(err, "get pull request")
+	}
+
+	var allChanges []Change
+	start := 0
+	for {
+		url := fmt.Sprintf("%s/%s/pull-requests/%s/changes?start=%d&limit=%d", p.APIURL(instanceURL), repoPath, pullRequestID, start, apiPageSize)
+		changes, nextPage, err := p.fetchPaginatedChanges(ctx, oauthCtx, instanceURL, url)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to list pull request file")
+		}
+		allChanges = append(allChanges, changes...)
+
+		if nextPage.IsLastPage {
+			break
+		}
+		start = nextPage.NextPageStart
+	}
+
+	var res []*vcs.PullRequestFile
+	for _, change := range allChanges {
+		res = append(res, &vcs.PullRequestFile{
+			Path:         change.Path.ToString,
+			LastCommitID: pr.FromRef.LatestCommit,
+			IsDeleted:    change.Type == ChangeTypeDelete,
+		})
+	}
+	return res, nil
+}
+
+// getPullRequest gets the pull request in the repository.
+//
+// Docs: https://docs.atlassian.com/bitbucket-server/rest/7.21.0/bitbucket-rest.html#idp284
+func (p *Provider) getPullRequest(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repoPath, pullRequestID string) (*bitbucketPullRequest, error) {
+	url := fmt.Sprintf("%s/%s/pull-requests/%s", p.APIURL(instanceURL), repoPath, pullRequestID)
+	code, _, body, err := oauth.Get(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "GET %s", url)
+	}
+	if code == http.StatusNotFound {
+		return nil, common.Errorf(common.NotFound, "failed to get pull request from URL %s", url)
+	} else if code >= 300 {
+		return nil, errors.Errorf("failed to get pull request from URL %s, status code: %d, body: %s",
+			url,
+			code,
+			body,
+		)
+	}
+
+	pr := new(bitbucketPullRequest)
+	if err := json.Unmarshal([]byte(body), pr); err != nil {
+		return nil, err
+	}
+	return pr, nil
+}
+
+// fetchPaginatedChanges fetches the file changes from the given URL, which is
+// either the changes of a pull request or a commit.
+func (p *Provider) fetchPaginatedChanges(ctx context.Context, oauthCtx common.OauthContext, instanceURL, url string) (changes []Change, nextPage page, err error) {
+	code, _, body, err := oauth.Get(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return nil, page{}, errors.Wrapf(err, "GET %s", url)
+	}
+	if code == http.StatusNotFound {
+		return nil, page{}, common.Errorf(common.NotFound, "failed to list changes from URL %s", url)
+	} else if code >= 300 {
+		return nil, page{}, errors.Errorf("failed to list changes from URL %s, status code: %d, body: %s",
+			url,
+			code,
+			body,
+		)
+	}
+
+	var resp struct {
+		page
+		Values []Change `json:"values"`
+	}
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		return nil, page{}, err
+	}
+	return resp.Values, resp.page, nil
+}
+
+type bitbucketBranch struct {
+	ID           string `json:"id"`
+	DisplayID    string `json:"displayId"`
+	LatestCommit string `json:"latestCommit"`
+}
+
+type bitbucketBranchCreate struct {
+	Name string `json:"name"`
+	// StartPoint is the branch or commit SHA to create the branch from.
+	StartPoint string `json:"startPoint"`
+}
+
+// GetBranch gets the given branch in the repository.
+//
+// Docs: https://docs.atlassian.com/bitbucket-server/rest/7.21.0/bitbucket-rest.html#idp211
+func (p *Provider) GetBranch(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, branchName string) (*vcs.BranchInfo, error) {
+	repoPath, err := repositoryPath(repositoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/%s/branches?filterText=%s&limit=%d", p.APIURL(instanceURL), repoPath, url.QueryEscape(branchName), apiPageSize)
+	code, _, body, err := oauth.Get(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "GET %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return nil, common.Errorf(common.NotFound, "failed to get branch from URL %s", url)
+	} else if code >= 300 {
+		return nil, errors.Errorf("failed to get branch from URL %s, status code: %d, body: %s",
+			url,
+			code,
+			body,
+		)
+	}
+
+	var resp struct {
+		Values []bitbucketBranch `json:"values"`
+	}
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		return nil, err
+	}
+	// The filterText matches the branch name by substring, thus we need to find
+	// the exact match.
+	for _, branch := range resp.Values {
+		if branch.DisplayID == branchName {
+			return &vcs.BranchInfo{
+				Name:         branch.DisplayID,
+				LastCommitID: branch.LatestCommit,
+			}, nil
+		}
+	}
+	return nil, common.Errorf(common.NotFound, "failed to find branch %q from URL %s", branchName, url)
+}
+
+// CreateBranch creates the branch in the repository.
+//
+// Docs: https://docs.atlassian.com/bitbucket-server/rest/7.21.0/bitbucket-rest.html#idp212
+func (p *Provider) CreateBranch(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID string, branch *vcs.BranchInfo) error {
+	repoPath, err := repositoryPath(repositoryID)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(
+		bitbucketBranchCreate{
+			Name:       branch.Name,
+			StartPoint: branch.LastCommitID,
+		},
+	)
+	if err != nil {
+		return errors.Wrap(err, "marshal branch create")
+	}
+
+	url := fmt.Sprintf("%s/%s/branches", p.APIURL(instanceURL), repoPath)
+	code, _, resp, err := oauth.Post(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		bytes.NewReader(body),
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "POST %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return common.Errorf(common.NotFound, "failed to create branch from URL %s", url)
+	} else if code >= 300 {
+		return errors.Errorf("failed to create branch from URL %s, status code: %d, body: %s",
+			url,
+			code,
+			resp,
+		)
+	}
+
+	return nil
+}
+
+// CreatePullRequest creates the pull request in the repository.
+//
+// NOTE: Bitbucket Server does not support removing the source branch after
+// merged on creating the pull request, thus the RemoveHeadAfterMerged is
+// ignored.
+//
+// Docs: https://docs.atlassian.com/bitbucket-server/rest/7.21.0/bitbucket-rest.html#idp281
+func (p *Provider) CreatePullRequest(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID string, pullRequestCreate *vcs.PullRequestCreate) (*vcs.PullRequest, error) {
+	repoPath, err := repositoryPath(repositoryID)
+	if err != nil {
+		return nil, err
+	}
+	projectKey, repositorySlug, _ := strings.Cut(repositoryID, "/")
+
+	fromRef := bitbucketPullRequestRef{ID: fmt.Sprintf("refs/heads/%s", pullRequestCreate.Head)}
+	fromRef.Repository.Slug = repositorySlug
+	fromRef.Repository.Project.Key = projectKey
+	toRef := bitbucketPullRequestRef{ID: fmt.Sprintf("refs/heads/%s", pullRequestCreate.Base)}
+	toRef.Repository.Slug = repositorySlug
+	toRef.Repository.Project.Key = projectKey
+	body, err := json.Marshal(
+		bitbucketPullRequestCreate{
+			Title:       pullRequestCreate.Title,
+			Description: pullRequestCreate.Body,
+			FromRef:     fromRef,
+			ToRef:       toRef,
+		},
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal pull request create")
+	}
+
+	url := fmt.Sprintf("%s/%s/pull-requests", p.APIURL(instanceURL), repoPath)
+	code, _, resp, err := oauth.Post(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		bytes.NewReader(body),
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "POST %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return nil, common.Errorf(common.NotFound, "failed to create pull request from URL %s", url)
+	} else if code >= 300 {
+		return nil, errors.Errorf("failed to create pull request from URL %s, status code: %d, body: %s",
+			url,
+			code,
+			resp,
+		)
+	}
+
+	var res bitbucketPullRequest
+	if err := json.Unmarshal([]byte(resp), &res); err != nil {
+		return nil, err
+	}
+
+	var prURL string
+	if len(res.Links.Self) > 0 {
+		prURL = res.Links.Self[0].Href
+	}
+	return &vcs.PullRequest{
+		URL: prURL,
+	}, nil
+}
+
+type bitbucketPullRequestCommentCreate struct {
+	Text   string                             `json:"text"`
+	Anchor *bitbucketPullRequestCommentAnchor `json:"anchor,omitempty"`
+}
+
+type bitbucketPullRequestCommentAnchor struct {
+	Path     string `json:"path"`
+	Line     int    `json:"line"`
+	LineType string `json:"lineType"`
+	FileType string `json:"fileType"`
+	DiffType string `json:"diffType"`
+}
+
+// CreatePullRequestComment creates a comment in the pull request.
+//
+// Docs: https://docs.atlassian.com/bitbucket-server/rest/7.21.0/bitbucket-rest.html#idp294
+func (p *Provider) CreatePullRequestComment(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, pullRequestID, comment string) error {
+	return p.createPullRequestComment(ctx, oauthCtx, instanceURL, repositoryID, pullRequestID, &bitbucketPullRequestCommentCreate{
+		Text: comment,
+	})
+}
+
+// CreatePullRequestReview creates a comment anchored to the line for each line comment and a comment for the review body
+// in the pull request, because Bitbucket Server doesn't create the review with the comments.
+//
+// Docs: https://docs.atlassian.com/bitbucket-server/rest/7.21.0/bitbucket-rest.html#idp294
+func (p *Provider) CreatePullRequestReview(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, pullRequestID string, review *vcs.PullRequestReview) error {
+	for _, comment := range review.CommentList {
+		if err := p.createPullRequestComment(ctx, oauthCtx, instanceURL, repositoryID, pullRequestID, &bitbucketPullRequestCommentCreate{
+			Text: comment.Body,
+			Anchor: &bitbucketPullRequestCommentAnchor{
+				Path: comment.Path,
+				Line: comment.Line,
+				// The migration files are usually added in the pull request, so the lines are added lines in the new version of the file.
+				LineType: "ADDED",
+				FileType: "TO",
+				DiffType: "EFFECTIVE",
+			},
+		}); err != nil {
+			return err
+		}
+	}
+	return p.CreatePullRequestComment(ctx, oauthCtx, instanceURL, repositoryID, pullRequestID, review.Body)
+}
+
+func (p *Provider) createPullRequestComment(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, pullRequestID string, commentCreate *bitbucketPullRequestCommentCreate) error {
+	repoPath, err := repositoryPath(repositoryID)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(commentCreate)
+	if err != nil {
+		return errors.Wrap(err, "marshal pull request comment create")
+	}
+
+	url := fmt.Sprintf("%s/%s/pull-requests/%s/comments", p.APIURL(instanceURL), repoPath, pullRequestID)
+	code, _, resp, err := oauth.Post(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		bytes.NewReader(body),
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "POST %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return common.Errorf(common.NotFound, "failed to create pull request comment from URL %s", url)
+	} else if code >= 300 {
+		return errors.Errorf("failed to create pull request comment from URL %s, status code: %d, body: %s",
+			url,
+			code,
+			resp,
+		)
+	}
+	return nil
+}
+
+type bitbucketBuildStatusCreate struct {
+	State       string `json:"state"`
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	URL         string `json:"url"`
+	Description string `json:"description"`
+}
+
+// Bitbucket Server does not distinguish pending from running for the build
+// status, thus we use INPROGRESS for both.
+var commitStatusStates = map[vcs.CommitStatusState]string{
+	vcs.CommitStatusPending:  "INPROGRESS",
+	vcs.CommitStatusRunning:  "INPROGRESS",
+	vcs.CommitStatusSuccess:  "SUCCESSFUL",
+	vcs.CommitStatusFailure:  "FAILED",
+	vcs.CommitStatusCanceled: "FAILED",
+}
+
+// SetCommitStatus creates or updates the build status of the commit. The
+// repositoryID is unused because the build status is associated with the
+// commit across the repositories in Bitbucket Server.
+//
+// Docs: https://developer.atlassian.com/server/bitbucket/how-tos/updating-build-status-for-commits/
+func (p *Provider) SetCommitStatus(ctx context.Context, oauthCtx common.OauthContext, instanceURL, _, commitID string, status *vcs.CommitStatus) error {
+	state, ok := commitStatusStates[status.State]
+	if !ok {
+		return errors.Errorf("unsupported commit status state %q", status.State)
+	}
+	body, err := json.Marshal(
+		bitbucketBuildStatusCreate{
+			State:       state,
+			Key:         status.Context,
+			Name:        status.Context,
+			URL:         status.TargetURL,
+			Description: status.Description,
+		},
+	)
+	if err != nil {
+		return errors.Wrap(err, "marshal build status create")
+	}
+
+	url := fmt.Sprintf("%s/%s/commits/%s", instanceURL, buildStatusAPIPath, commitID)
+	code, _, resp, err := oauth.Post(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		bytes.NewReader(body),
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "POST %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return common.Errorf(common.NotFound, "failed to set build status from URL %s", url)
+	} else if code >= 300 {
+		return errors.Errorf("failed to set build status from URL %s, status code: %d, body: %s",
+			url,
+			code,
+			resp,
+		)
+	}
+	return nil
+}
+
+// UpsertEnvironmentVariable is not supported because Bitbucket Server does not
+// have a built-in CI, the SQL review pipeline runs in the external CI (e.g.
+// Jenkins) that keeps the secret in its own credential store.
+func (*Provider) UpsertEnvironmentVariable(context.Context, common.OauthContext, string, string, string, string) error {
+	return common.Errorf(common.NotImplemented, "Bitbucket Server does not support environment variables")
+}
+
+// CreateWebhook creates a webhook in the repository with given payload.
+//
+// Docs: https://docs.atlassian.com/bitbucket-server/rest/7.21.0/bitbucket-rest.html#idp398
+func (p *Provider) CreateWebhook(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID string, payload []byte) (string, error) {
+	repoPath, err := repositoryPath(repositoryID)
+	if err != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf("%s/%s/webhooks", p.APIURL(instanceURL), repoPath)
+	code, _, body, err := oauth.Post(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		bytes.NewReader(payload),
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return "", errors.Wrapf(err, "POST %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return "", common.Errorf(common.NotFound, "failed to create webhook through URL %s", url)
+	} else if code >= 300 {
+		return "", errors.Errorf("failed to create webhook through URL %s, status code: %d, body: %s",
+			url,
+			code,
+			body,
+		)
+	}
+
+	var webhookInfo WebhookInfo
+	if err = json.Unmarshal([]byte(body), &webhookInfo); err != nil {
+		return "", errors.Wrap(err, "unmarshal body")
+	}
+	return strconv.Itoa(webhookInfo.ID), nil
+}
+
+// PatchWebhook patches the webhook in the repository with given payload.
+// Bitbucket Server only supports replacing the whole webhook via PUT.
+//
+// Docs: https://docs.atlassian.com/bitbucket-server/rest/7.21.0/bitbucket-rest.html#idp401
+func (p *Provider) PatchWebhook(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, webhookID string, payload []byte) error {
+	repoPath, err := repositoryPath(repositoryID)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/%s/webhooks/%s", p.APIURL(instanceURL), repoPath, webhookID)
+	code, _, body, err := oauth.Put(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		bytes.NewReader(payload),
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "PUT %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return common.Errorf(common.NotFound, "failed to patch webhook through URL %s", url)
+	} else if code >= 300 {
+		return errors.Errorf("failed to patch webhook through URL %s, status code: %d, body: %s",
+			url,
+			code,
+			body,
+		)
+	}
+	return nil
+}
+
+// DeleteWebhook deletes the webhook from the repository.
+//
+// Docs: https://docs.atlassian.com/bitbucket-server/rest/7.21.0/bitbucket-rest.html#idp400
+func (p *Provider) DeleteWebhook(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, webhookID string) error {
+	repoPath, err := repositoryPath(repositoryID)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/%s/webhooks/%s", p.APIURL(instanceURL), repoPath, webhookID)
+	code, _, body, err := oauth.Delete(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "DELETE %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return nil // It is OK if the webhook has already gone
+	} else if code >= 300 {
+		return errors.Errorf("failed to delete webhook through URL %s, status code: %d, body: %s",
+			url,
+			code,
+			body,
+		)
+	}
+	return nil
+}
+
+// fetchPushedCommitList fetches the commits in the ref change ordered from the
+// oldest to the newest, along with the added and modified files of each commit.
+//
+// Docs: https://docs.atlassian.com/bitbucket-server/rest/7.21.0/bitbucket-rest.html#idp222
+func (p *Provider) fetchPushedCommitList(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repoPath string, change WebhookRefChange) ([]vcs.Commit, error) {
+	var commits []Commit
+	start := 0
+	for {
+		url := fmt.Sprintf("%s/%s/commits?until=%s&start=%d&limit=%d", p.APIURL(instanceURL), repoPath, change.ToHash, start, apiPageSize)
+		if change.FromHash != "" && change.FromHash != emptyCommitID {
+			url += fmt.Sprintf("&since=%s", change.FromHash)
+		}
+		code, _, body, err := oauth.Get(
+			ctx,
+			p.client,
+			url,
+			&oauthCtx.AccessToken,
+			tokenRefresher(
+				instanceURL,
+				oauthContext{
+					ClientID:     oauthCtx.ClientID,
+					ClientSecret: oauthCtx.ClientSecret,
+					RefreshToken: oauthCtx.RefreshToken,
+				},
+				oauthCtx.Refresher,
+			),
+		)
+		if err != nil {
+			return nil, errors.Wrapf(err, "GET %s", url)
+		}
+		if code == http.StatusNotFound {
+			return nil, common.Errorf(common.NotFound, "failed to list commits from URL %s", url)
+		} else if code >= 300 {
+			return nil, errors.Errorf("failed to list commits from URL %s, status code: %d, body: %s",
+				url,
+				code,
+				body,
+			)
+		}
+
+		var resp struct {
+			page
+			Values []Commit `json:"values"`
+		}
+		if err := json.Unmarshal([]byte(body), &resp); err != nil {
+			return nil, errors.Wrap(err, "unmarshal body")
+		}
+		commits = append(commits, resp.Values...)
+
+		if resp.IsLastPage {
+			break
+		}
+		start = resp.NextPageStart
+	}
+
+	var commitList []vcs.Commit
+	// Bitbucket Server returns the commits from the newest to the oldest.
+	for i := len(commits) - 1; i >= 0; i-- {
+		commit := commits[i]
+		var changes []Change
+		start := 0
+		for {
+			url := fmt.Sprintf("%s/%s/commits/%s/changes?start=%d&limit=%d", p.APIURL(instanceURL), repoPath, commit.ID, start, apiPageSize)
+			pageChanges, nextPage, err := p.fetchPaginatedChanges(ctx, oauthCtx, instanceURL, url)
+			if err != nil {
+				return nil, errors.Wrapf(err, "list changes of commit %s", commit.ID)
+			}
+			changes = append(changes, pageChanges...)
+
+			if nextPage.IsLastPage {
+				break
+			}
+			start = nextPage.NextPageStart
+		}
+
+		var addedList, modifiedList []string
+		for _, c := range changes {
+			switch c.Type {
+			case ChangeTypeAdd, ChangeTypeCopy, ChangeTypeMove:
+				addedList = append(addedList, c.Path.ToString)
+			case ChangeTypeModify:
+				modifiedList = append(modifiedList, c.Path.ToString)
+			}
+		}
+
+		// Per Git convention, the message title and body are separated by two new line characters.
+		messages := strings.SplitN(commit.Message, "\n\n", 2)
+		messageTitle := strings.TrimSpace(messages[0])
+
+		commitList = append(commitList, vcs.Commit{
+			ID:           commit.ID,
+			Title:        messageTitle,
+			Message:      commit.Message,
+			CreatedTs:    commit.AuthorTimestamp / 1000,
+			URL:          fmt.Sprintf("%s/%s/commits/%s", instanceURL, repoPath, commit.ID),
+			AuthorName:   commit.Author.Name,
+			AuthorEmail:  commit.Author.EmailAddress,
+			AddedList:    addedList,
+			ModifiedList: modifiedList,
+		})
+	}
+	return commitList, nil
+}
+
+// escapeFilePath escapes each segment of the file path, Bitbucket Server
+// requires the path separators to be kept as-is.
+func escapeFilePath(filePath string) string {
+	segments := strings.Split(filePath, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// oauthContext is the request context for refreshing oauth token.
+type oauthContext struct {
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	RefreshToken string `json:"refresh_token"`
+	GrantType    string `json:"grant_type"`
+}
+
+type refreshOAuthResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	// token_type and scope are not used.
+}
+
+func tokenRefresher(instanceURL string, oauthCtx oauthContext, refresher common.TokenRefresher) oauth.TokenRefresher {
+	return func(ctx context.Context, client *http.Client, oldToken *string) error {
+		// Bitbucket Server only accepts the parameters as query string or form.
+		urlParams := &url.Values{}
+		urlParams.Set("client_id", oauthCtx.ClientID)
+		urlParams.Set("client_secret", oauthCtx.ClientSecret)
+		urlParams.Set("refresh_token", oauthCtx.RefreshToken)
+		urlParams.Set("grant_type", "refresh_token")
+		url := fmt.Sprintf("%s/rest/oauth2/latest/token?%s", instanceURL, urlParams.Encode())
+		redactedURL := fmt.Sprintf("%s/rest/oauth2/latest/token", instanceURL)
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+		if err != nil {
+			return errors.Wrapf(err, "construct POST %s", redactedURL)
+		}
+
+		req.Header.Set("Accept", "application/json")
+		resp, err := client.Do(req)
+		if err != nil {
+			return errors.Wrapf(err, "POST %s", redactedURL)
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return errors.Wrapf(err, "read body of POST %s", redactedURL)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return errors.Errorf("non-200 POST %s status code %d with body %q", redactedURL, resp.StatusCode, body)
+		}
+
+		var r refreshOAuthResponse
+		if err = json.Unmarshal(body, &r); err != nil {
+			return errors.Wrapf(err, "unmarshal body from POST %s", redactedURL)
+		}
+
+		// Update the old token to new value for retries.
+		*oldToken = r.AccessToken
+
+		var expireAt int64
+		if r.ExpiresIn != 0 {
+			expireAt = time.Now().Unix() + r.ExpiresIn
+		}
+		return refresher(r.AccessToken, r.RefreshToken, expireAt)
+	}
+}
+
+// ToVCS returns the push event in VCS format for the given ref change. Unlike
+// other VCS providers, the Bitbucket Server push event does not carry the
+// commits, thus they are fetched from the repository.
+func (p WebhookPushEvent) ToVCS(ctx context.Context, oauthCtx common.OauthContext, instanceURL string, change WebhookRefChange) (vcs.PushEvent, error) {
+	return (&Provider{client: &http.Client{}}).toVCSPushEvent(ctx, oauthCtx, instanceURL, p, change)
+}
+
+func (p *Provider) toVCSPushEvent(ctx context.Context, oauthCtx common.OauthContext, instanceURL string, event WebhookPushEvent, change WebhookRefChange) (vcs.PushEvent, error) {
+	repositoryID := fmt.Sprintf("%s/%s", event.Repository.Project.Key, event.Repository.Slug)
+	repoPath, err := repositoryPath(repositoryID)
+	if err != nil {
+		return vcs.PushEvent{}, err
+	}
+
+	commitList, err := p.fetchPushedCommitList(ctx, oauthCtx, instanceURL, repoPath, change)
+	if err != nil {
+		return vcs.PushEvent{}, errors.Wrap(err, "fetch pushed commits")
+	}
+
+	authorName := event.Actor.DisplayName
+	if authorName == "" {
+		authorName = event.Actor.Name
+	}
+	return vcs.PushEvent{
+		Ref:                change.RefID,
+		RepositoryID:       repositoryID,
+		RepositoryURL:      fmt.Sprintf("%s/%s/browse", instanceURL, repoPath),
+		RepositoryFullPath: repositoryID,
+		AuthorName:         authorName,
+		CommitList:         commitList,
+	}, nil
+}
+
+// ToVCS returns the pull request event in VCS format. It returns false if the
+// event is irrelevant, e.g. a reviewer is added to the pull request.
+func (p WebhookPullRequestEvent) ToVCS() (vcs.PullRequestEvent, bool) {
+	var action vcs.PullRequestAction
+	switch p.EventKey {
+	case WebhookPullRequestOpened:
+		action = vcs.PullRequestOpened
+	case WebhookPullRequestFromRefUpdated:
+		action = vcs.PullRequestUpdated
+	case WebhookPullRequestMerged:
+		action = vcs.PullRequestMerged
+	case WebhookPullRequestDeclined:
+		action = vcs.PullRequestClosed
+	default:
+		return vcs.PullRequestEvent{}, false
+	}
+
+	// The pull request is always created in the target repository, and the
+	// source branch may come from a fork.
+	repository := p.PullRequest.ToRef.Repository
+	repositoryID := fmt.Sprintf("%s/%s", repository.Project.Key, repository.Slug)
+	var prURL, repositoryURL string
+	if len(p.PullRequest.Links.Self) > 0 {
+		prURL = p.PullRequest.Links.Self[0].Href
+	}
+	if len(repository.Links.Self) > 0 {
+		repositoryURL = repository.Links.Self[0].Href
+	}
+	authorName := p.Actor.DisplayName
+	if authorName == "" {
+		authorName = p.Actor.Name
+	}
+	return vcs.PullRequestEvent{
+		Action:             action,
+		ID:                 strconv.Itoa(p.PullRequest.ID),
+		URL:                prURL,
+		Title:              p.PullRequest.Title,
+		HeadRef:            p.PullRequest.FromRef.ID,
+		HeadCommitID:       p.PullRequest.FromRef.LatestCommit,
+		BaseBranch:         p.PullRequest.ToRef.DisplayID,
+		RepositoryID:       repositoryID,
+		RepositoryURL:      repositoryURL,
+		RepositoryFullPath: repositoryID,
+		AuthorName:         authorName,
+		AuthorEmail:        p.Actor.EmailAddress,
+	}, true
+}
@@ -0,0 +1,1014 @@
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/plugin/vcs"
+	"github.com/bytebase/bytebase/plugin/vcs/internal/oauth"
+)
+
+const bitbucketURL = "https://bitbucket.example.com"
+
+func TestProvider_TryLogin(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						switch r.URL.Path {
+						case "/rest/api/1.0/application-properties":
+							return &http.Response{
+								StatusCode: http.StatusOK,
+								Header:     http.Header{"X-Ausername": []string{"jcitizen"}},
+								Body:       io.NopCloser(strings.NewReader(`{"version":"7.21.0","buildNumber":"7021000","displayName":"Bitbucket"}`)),
+							}, nil
+						case "/rest/api/1.0/users":
+							assert.Equal(t, "jcitizen", r.URL.Query().Get("filter"))
+							return &http.Response{
+								StatusCode: http.StatusOK,
+								Body: io.NopCloser(strings.NewReader(`
+{
+  "size": 2,
+  "limit": 25,
+  "isLastPage": true,
+  "values": [
+    {"name": "jcitizen2", "emailAddress": "jane2@example.com", "id": 102, "displayName": "Jane Citizen 2", "active": true, "slug": "jcitizen2", "type": "NORMAL"},
+    {"name": "jcitizen", "emailAddress": "jane@example.com", "id": 101, "displayName": "Jane Citizen", "active": true, "slug": "jcitizen", "type": "NORMAL"}
+  ],
+  "start": 0
+}
+`)),
+							}, nil
+						}
+						t.Fatalf("unexpected request path %q", r.URL.Path)
+						return nil, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	got, err := p.TryLogin(ctx, common.OauthContext{}, bitbucketURL)
+	require.NoError(t, err)
+	want := &vcs.UserInfo{
+		PublicEmail: "jane@example.com",
+		Name:        "Jane Citizen",
+		State:       vcs.StateActive,
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestProvider_FetchRepositoryActiveMemberList(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, "/rest/api/1.0/projects/PRJ/repos/my-repo/permissions/users", r.URL.Path)
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body: io.NopCloser(strings.NewReader(`
+{
+  "size": 3,
+  "limit": 100,
+  "isLastPage": true,
+  "values": [
+    {"user": {"name": "jcitizen", "emailAddress": "jane@example.com", "id": 101, "displayName": "Jane Citizen", "active": true}, "permission": "REPO_ADMIN"},
+    {"user": {"name": "bob", "emailAddress": "bob@example.com", "id": 102, "displayName": "Bob", "active": true}, "permission": "REPO_READ"},
+    {"user": {"name": "alice", "emailAddress": "alice@example.com", "id": 103, "displayName": "Alice", "active": false}, "permission": "REPO_WRITE"}
+  ],
+  "start": 0
+}
+`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	got, err := p.FetchRepositoryActiveMemberList(ctx, common.OauthContext{}, bitbucketURL, "PRJ/my-repo")
+	require.NoError(t, err)
+
+	want := []*vcs.RepositoryMember{
+		{
+			Name:         "Jane Citizen",
+			Email:        "jane@example.com",
+			Role:         common.ProjectOwner,
+			VCSRole:      string(RepositoryRoleAdmin),
+			State:        vcs.StateActive,
+			RoleProvider: vcs.BitbucketServer,
+		},
+		{
+			Name:         "Bob",
+			Email:        "bob@example.com",
+			Role:         common.ProjectDeveloper,
+			VCSRole:      string(RepositoryRoleRead),
+			State:        vcs.StateActive,
+			RoleProvider: vcs.BitbucketServer,
+		},
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestProvider_FetchCommitByID(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, "/rest/api/1.0/projects/PRJ/repos/my-repo/commits/def0123abcdef4567abcdef8987abcdef6543abc", r.URL.Path)
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body: io.NopCloser(strings.NewReader(`
+{
+  "id": "def0123abcdef4567abcdef8987abcdef6543abc",
+  "displayId": "def0123abcd",
+  "author": {"name": "charlie", "emailAddress": "charlie@example.com"},
+  "authorTimestamp": 1548720847610,
+  "committer": {"name": "charlie", "emailAddress": "charlie@example.com"},
+  "committerTimestamp": 1548720847610,
+  "message": "More work on feature 1",
+  "parents": [{"id": "abcdef0123abcdef4567abcdef8987abcdef6543", "displayId": "abcdef0"}]
+}
+`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	got, err := p.FetchCommitByID(ctx, common.OauthContext{}, bitbucketURL, "PRJ/my-repo", "def0123abcdef4567abcdef8987abcdef6543abc")
+	require.NoError(t, err)
+
+	want := &vcs.Commit{
+		ID:          "def0123abcdef4567abcdef8987abcdef6543abc",
+		AuthorName:  "charlie",
+		AuthorEmail: "charlie@example.com",
+		CreatedTs:   1548720847,
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestProvider_ExchangeOAuthToken(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, http.MethodPost, r.Method)
+						assert.Equal(t, "/rest/oauth2/latest/token", r.URL.Path)
+						assert.Equal(t, "client_id=test_client_id&client_secret=test_client_secret&code=test_code&grant_type=authorization_code&redirect_uri=http%3A%2F%2Flocalhost%3A3000", r.URL.RawQuery)
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body: io.NopCloser(strings.NewReader(`
+{
+  "scope": "REPO_ADMIN",
+  "access_token": "test_access_token",
+  "token_type": "bearer",
+  "expires_in": 7200,
+  "refresh_token": "test_refresh_token"
+}
+`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	got, err := p.ExchangeOAuthToken(
+		ctx,
+		bitbucketURL,
+		&common.OAuthExchange{
+			ClientID:     "test_client_id",
+			ClientSecret: "test_client_secret",
+			Code:         "test_code",
+			RedirectURL:  "http://localhost:3000",
+		},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "test_access_token", got.AccessToken)
+	assert.Equal(t, "test_refresh_token", got.RefreshToken)
+	assert.Equal(t, got.CreatedAt+7200, got.ExpiresTs)
+}
+
+func TestProvider_FetchAllRepositoryList(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, "/rest/api/1.0/repos", r.URL.Path)
+						assert.Equal(t, "REPO_ADMIN", r.URL.Query().Get("permission"))
+						body := `
+{
+  "size": 1,
+  "limit": 1,
+  "isLastPage": false,
+  "nextPageStart": 1,
+  "values": [
+    {
+      "slug": "my-repo",
+      "id": 1,
+      "name": "My repo",
+      "project": {"key": "PRJ", "id": 1, "name": "My Cool Project"},
+      "links": {"self": [{"href": "https://bitbucket.example.com/projects/PRJ/repos/my-repo/browse"}]}
+    }
+  ],
+  "start": 0
+}
+`
+						if r.URL.Query().Get("start") == "1" {
+							body = `
+{
+  "size": 1,
+  "limit": 1,
+  "isLastPage": true,
+  "values": [
+    {
+      "slug": "other-repo",
+      "id": 2,
+      "name": "Other repo",
+      "project": {"key": "PRJ", "id": 1, "name": "My Cool Project"},
+      "links": {"self": [{"href": "https://bitbucket.example.com/projects/PRJ/repos/other-repo/browse"}]}
+    }
+  ],
+  "start": 1
+}
+`
+						}
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body:       io.NopCloser(strings.NewReader(body)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	got, err := p.FetchAllRepositoryList(ctx, common.OauthContext{}, bitbucketURL)
+	require.NoError(t, err)
+
+	want := []*vcs.Repository{
+		{
+			ID:       1,
+			Name:     "My repo",
+			FullPath: "PRJ/my-repo",
+			WebURL:   "https://bitbucket.example.com/projects/PRJ/repos/my-repo/browse",
+		},
+		{
+			ID:       2,
+			Name:     "Other repo",
+			FullPath: "PRJ/other-repo",
+			WebURL:   "https://bitbucket.example.com/projects/PRJ/repos/other-repo/browse",
+		},
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestProvider_FetchRepositoryFileList(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, "/rest/api/1.0/projects/PRJ/repos/my-repo/files/migration", r.URL.Path)
+						assert.Equal(t, "main", r.URL.Query().Get("at"))
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body: io.NopCloser(strings.NewReader(`
+{
+  "size": 2,
+  "limit": 100,
+  "isLastPage": true,
+  "values": ["prod/db/1.0__init.sql", "prod/db/1.1__add_table.sql"],
+  "start": 0
+}
+`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	got, err := p.FetchRepositoryFileList(ctx, common.OauthContext{}, bitbucketURL, "PRJ/my-repo", "main", "migration/")
+	require.NoError(t, err)
+
+	want := []*vcs.RepositoryTreeNode{
+		{
+			Path: "migration/prod/db/1.0__init.sql",
+			Type: "blob",
+		},
+		{
+			Path: "migration/prod/db/1.1__add_table.sql",
+			Type: "blob",
+		},
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestProvider_OverwriteFile(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, http.MethodPut, r.Method)
+						assert.Equal(t, "/rest/api/1.0/projects/PRJ/repos/my-repo/browse/notes/hello.txt", r.URL.Path)
+
+						mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+						require.NoError(t, err)
+						assert.Equal(t, "multipart/form-data", mediaType)
+
+						form, err := multipart.NewReader(r.Body, params["boundary"]).ReadForm(1 << 20)
+						require.NoError(t, err)
+						want := map[string][]string{
+							"branch":         {"master"},
+							"content":        {"my new file contents"},
+							"message":        {"update file"},
+							"sourceCommitId": {"def0123abcdef4567abcdef8987abcdef6543abc"},
+						}
+						assert.Equal(t, want, form.Value)
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body:       io.NopCloser(strings.NewReader(`{}`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	err := p.OverwriteFile(
+		ctx,
+		common.OauthContext{},
+		bitbucketURL,
+		"PRJ/my-repo",
+		"notes/hello.txt",
+		vcs.FileCommitCreate{
+			Branch:        "master",
+			Content:       "my new file contents",
+			CommitMessage: "update file",
+			LastCommitID:  "def0123abcdef4567abcdef8987abcdef6543abc",
+		},
+	)
+	require.NoError(t, err)
+}
+
+func TestProvider_ReadFileMeta(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						switch r.URL.Path {
+						case "/rest/api/1.0/projects/PRJ/repos/my-repo/raw/notes/hello.txt":
+							return &http.Response{
+								StatusCode: http.StatusOK,
+								Body:       io.NopCloser(strings.NewReader("hello world")),
+							}, nil
+						case "/rest/api/1.0/projects/PRJ/repos/my-repo/commits":
+							assert.Equal(t, "notes/hello.txt", r.URL.Query().Get("path"))
+							assert.Equal(t, "main", r.URL.Query().Get("until"))
+							return &http.Response{
+								StatusCode: http.StatusOK,
+								Body: io.NopCloser(strings.NewReader(`
+{
+  "size": 1,
+  "limit": 1,
+  "isLastPage": false,
+  "nextPageStart": 1,
+  "values": [{"id": "def0123abcdef4567abcdef8987abcdef6543abc", "message": "add hello"}],
+  "start": 0
+}
+`)),
+							}, nil
+						}
+						t.Fatalf("unexpected request path %q", r.URL.Path)
+						return nil, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	got, err := p.ReadFileMeta(ctx, common.OauthContext{}, bitbucketURL, "PRJ/my-repo", "notes/hello.txt", "main")
+	require.NoError(t, err)
+
+	want := &vcs.FileMeta{
+		Name:         "hello.txt",
+		Path:         "notes/hello.txt",
+		Size:         11,
+		LastCommitID: "def0123abcdef4567abcdef8987abcdef6543abc",
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestProvider_ReadFileContent(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, "/rest/api/1.0/projects/PRJ/repos/my-repo/raw/migration/1.0__init.sql", r.URL.Path)
+						assert.Equal(t, "def0123abcdef4567abcdef8987abcdef6543abc", r.URL.Query().Get("at"))
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body:       io.NopCloser(strings.NewReader("CREATE TABLE t(id INT);")),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	got, err := p.ReadFileContent(ctx, common.OauthContext{}, bitbucketURL, "PRJ/my-repo", "migration/1.0__init.sql", "def0123abcdef4567abcdef8987abcdef6543abc")
+	require.NoError(t, err)
+	assert.Equal(t, "CREATE TABLE t(id INT);", got)
+}
+
+func TestProvider_CreateWebhook(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, "/rest/api/1.0/projects/PRJ/repos/my-repo/webhooks", r.URL.Path)
+						return &http.Response{
+							StatusCode: http.StatusCreated,
+							Body: io.NopCloser(strings.NewReader(`
+{
+  "id": 10,
+  "name": "Bytebase GitOps",
+  "createdDate": 1513106011000,
+  "updatedDate": 1513106011000,
+  "events": ["repo:refs_changed"],
+  "configuration": {"secret": "password"},
+  "url": "https://bytebase.example.com/hook/bitbucket/1",
+  "active": true
+}
+`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	got, err := p.CreateWebhook(ctx, common.OauthContext{}, bitbucketURL, "PRJ/my-repo", []byte(""))
+	require.NoError(t, err)
+	assert.Equal(t, "10", got)
+}
+
+func TestProvider_PatchWebhook(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, http.MethodPut, r.Method)
+						assert.Equal(t, "/rest/api/1.0/projects/PRJ/repos/my-repo/webhooks/10", r.URL.Path)
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body:       io.NopCloser(strings.NewReader("")),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	err := p.PatchWebhook(ctx, common.OauthContext{}, bitbucketURL, "PRJ/my-repo", "10", []byte(""))
+	require.NoError(t, err)
+}
+
+func TestProvider_DeleteWebhook(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, http.MethodDelete, r.Method)
+						assert.Equal(t, "/rest/api/1.0/projects/PRJ/repos/my-repo/webhooks/10", r.URL.Path)
+						return &http.Response{
+							StatusCode: http.StatusNoContent,
+							Body:       io.NopCloser(strings.NewReader("")),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	err := p.DeleteWebhook(ctx, common.OauthContext{}, bitbucketURL, "PRJ/my-repo", "10")
+	require.NoError(t, err)
+}
+
+func TestOAuth_RefreshToken(t *testing.T) {
+	ctx := context.Background()
+	client := &http.Client{
+		Transport: &common.MockRoundTripper{
+			MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+				if r.URL.Path == "/rest/oauth2/latest/token" {
+					assert.Equal(t, "refresh_token", r.URL.Query().Get("grant_type"))
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body: io.NopCloser(strings.NewReader(`
+{
+  "scope": "REPO_ADMIN",
+  "access_token": "refreshed_access_token",
+  "token_type": "bearer",
+  "expires_in": 7200,
+  "refresh_token": "refreshed_refresh_token"
+}
+`)),
+					}, nil
+				}
+
+				token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+				if token == "expired" {
+					return &http.Response{
+						StatusCode: http.StatusUnauthorized,
+						Body: io.NopCloser(strings.NewReader(`
+					{"error":"invalid_token","error_description":"Token is expired. You can either do re-authorization or token refresh."}
+					`)),
+					}, nil
+				}
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader(`{}`)),
+				}, nil
+			},
+		},
+	}
+	token := "expired"
+
+	calledRefresher := false
+	refresher := func(accessToken, refreshToken string, expiresTs int64) error {
+		calledRefresher = true
+		assert.Equal(t, "refreshed_access_token", accessToken)
+		assert.Equal(t, "refreshed_refresh_token", refreshToken)
+		assert.NotZero(t, expiresTs)
+		return nil
+	}
+
+	_, _, _, err := oauth.Get(
+		ctx,
+		client,
+		bitbucketURL+"/rest/api/1.0/application-properties",
+		&token,
+		tokenRefresher(
+			bitbucketURL,
+			oauthContext{},
+			refresher,
+		),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "refreshed_access_token", token)
+	assert.True(t, calledRefresher)
+}
+
+func TestProvider_GetBranch(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, "/rest/api/1.0/projects/PRJ/repos/my-repo/branches", r.URL.Path)
+						assert.Equal(t, "main", r.URL.Query().Get("filterText"))
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body: io.NopCloser(strings.NewReader(`
+{
+  "size": 2,
+  "limit": 100,
+  "isLastPage": true,
+  "values": [
+    {"id": "refs/heads/main-backup", "displayId": "main-backup", "type": "BRANCH", "latestCommit": "abcdef0123abcdef4567abcdef8987abcdef6543", "isDefault": false},
+    {"id": "refs/heads/main", "displayId": "main", "type": "BRANCH", "latestCommit": "def0123abcdef4567abcdef8987abcdef6543abc", "isDefault": true}
+  ],
+  "start": 0
+}
+`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	got, err := p.GetBranch(ctx, common.OauthContext{}, bitbucketURL, "PRJ/my-repo", "main")
+	require.NoError(t, err)
+
+	want := &vcs.BranchInfo{
+		Name:         "main",
+		LastCommitID: "def0123abcdef4567abcdef8987abcdef6543abc",
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestProvider_CreateBranch(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, "/rest/api/1.0/projects/PRJ/repos/my-repo/branches", r.URL.Path)
+
+						body, err := io.ReadAll(r.Body)
+						require.NoError(t, err)
+						wantBody := `{"name":"bytebase-sql-review","startPoint":"def0123abcdef4567abcdef8987abcdef6543abc"}`
+						assert.Equal(t, wantBody, string(body))
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body:       io.NopCloser(strings.NewReader(`{}`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	err := p.CreateBranch(ctx, common.OauthContext{}, bitbucketURL, "PRJ/my-repo", &vcs.BranchInfo{
+		Name:         "bytebase-sql-review",
+		LastCommitID: "def0123abcdef4567abcdef8987abcdef6543abc",
+	})
+	require.NoError(t, err)
+}
+
+func TestProvider_CreatePullRequest(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, "/rest/api/1.0/projects/PRJ/repos/my-repo/pull-requests", r.URL.Path)
+
+						body, err := io.ReadAll(r.Body)
+						require.NoError(t, err)
+						wantBody := `{"title":"Add SQL review","description":"body","fromRef":{"id":"refs/heads/feature","repository":{"slug":"my-repo","project":{"key":"PRJ"}}},"toRef":{"id":"refs/heads/main","repository":{"slug":"my-repo","project":{"key":"PRJ"}}}}`
+						assert.Equal(t, wantBody, string(body))
+						return &http.Response{
+							StatusCode: http.StatusCreated,
+							Body: io.NopCloser(strings.NewReader(`
+{
+  "id": 101,
+  "title": "Add SQL review",
+  "state": "OPEN",
+  "links": {"self": [{"href": "https://bitbucket.example.com/projects/PRJ/repos/my-repo/pull-requests/101"}]}
+}
+`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	got, err := p.CreatePullRequest(ctx, common.OauthContext{}, bitbucketURL, "PRJ/my-repo", &vcs.PullRequestCreate{
+		Title: "Add SQL review",
+		Body:  "body",
+		Head:  "feature",
+		Base:  "main",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "https://bitbucket.example.com/projects/PRJ/repos/my-repo/pull-requests/101", got.URL)
+}
+
+func TestProvider_ListPullRequestFile(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						var body string
+						switch r.URL.Path {
+						case "/rest/api/1.0/projects/PRJ/repos/my-repo/pull-requests/101":
+							body = `
+{
+  "id": 101,
+  "fromRef": {"id": "refs/heads/feature", "latestCommit": "def0123abcdef4567abcdef8987abcdef6543abc"},
+  "links": {"self": [{"href": "https://bitbucket.example.com/projects/PRJ/repos/my-repo/pull-requests/101"}]}
+}
+`
+						case "/rest/api/1.0/projects/PRJ/repos/my-repo/pull-requests/101/changes":
+							body = `
+{
+  "fromHash": "def0123abcdef4567abcdef8987abcdef6543abc",
+  "toHash": "abcdef0123abcdef4567abcdef8987abcdef6543",
+  "size": 2,
+  "limit": 100,
+  "isLastPage": true,
+  "values": [
+    {"path": {"toString": "migration/1.0__init.sql"}, "type": "ADD"},
+    {"path": {"toString": "migration/0.9__legacy.sql"}, "type": "DELETE"}
+  ],
+  "start": 0
+}
+`
+						default:
+							t.Fatalf("unexpected request path %q", r.URL.Path)
+						}
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body:       io.NopCloser(strings.NewReader(body)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	got, err := p.ListPullRequestFile(ctx, common.OauthContext{}, bitbucketURL, "PRJ/my-repo", "101")
+	require.NoError(t, err)
+
+	want := []*vcs.PullRequestFile{
+		{
+			Path:         "migration/1.0__init.sql",
+			LastCommitID: "def0123abcdef4567abcdef8987abcdef6543abc",
+			IsDeleted:    false,
+		},
+		{
+			Path:         "migration/0.9__legacy.sql",
+			LastCommitID: "def0123abcdef4567abcdef8987abcdef6543abc",
+			IsDeleted:    true,
+		},
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestProvider_ToVCSPushEvent(t *testing.T) {
+	p := &Provider{
+		client: &http.Client{
+			Transport: &common.MockRoundTripper{
+				MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+					var body string
+					switch r.URL.Path {
+					case "/rest/api/1.0/projects/PRJ/repos/my-repo/commits":
+						assert.Equal(t, "abcdef0123abcdef4567abcdef8987abcdef6543", r.URL.Query().Get("since"))
+						assert.Equal(t, "def0123abcdef4567abcdef8987abcdef6543abc", r.URL.Query().Get("until"))
+						body = `
+{
+  "isLastPage": true,
+  "values": [
+    {"id": "def0123abcdef4567abcdef8987abcdef6543abc", "message": "Update migration", "author": {"name": "charlie", "emailAddress": "charlie@example.com"}, "authorTimestamp": 1548720847610},
+    {"id": "0123abcdef4567abcdef8987abcdef6543abcdef", "message": "Add migration\n\nCreate table.", "author": {"name": "charlie", "emailAddress": "charlie@example.com"}, "authorTimestamp": 1548720747610}
+  ]
+}
+`
+					case "/rest/api/1.0/projects/PRJ/repos/my-repo/commits/0123abcdef4567abcdef8987abcdef6543abcdef/changes":
+						body = `{"isLastPage": true, "values": [{"path": {"toString": "migration/1.0__init.sql"}, "type": "ADD"}]}`
+					case "/rest/api/1.0/projects/PRJ/repos/my-repo/commits/def0123abcdef4567abcdef8987abcdef6543abc/changes":
+						body = `{"isLastPage": true, "values": [{"path": {"toString": "migration/1.0__init.sql"}, "type": "MODIFY"}, {"path": {"toString": "README.md"}, "type": "DELETE"}]}`
+					default:
+						t.Fatalf("unexpected request path %q", r.URL.Path)
+					}
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       io.NopCloser(strings.NewReader(body)),
+					}, nil
+				},
+			},
+		},
+	}
+
+	event := WebhookPushEvent{
+		EventKey: WebhookPush,
+		Actor: User{
+			Name:        "charlie",
+			DisplayName: "Charlie",
+		},
+		Repository: Repository{
+			Slug:    "my-repo",
+			Project: Project{Key: "PRJ"},
+		},
+	}
+	change := WebhookRefChange{
+		RefID:    "refs/heads/main",
+		FromHash: "abcdef0123abcdef4567abcdef8987abcdef6543",
+		ToHash:   "def0123abcdef4567abcdef8987abcdef6543abc",
+		Type:     WebhookRefChangeUpdate,
+	}
+
+	ctx := context.Background()
+	got, err := p.toVCSPushEvent(ctx, common.OauthContext{}, bitbucketURL, event, change)
+	require.NoError(t, err)
+
+	want := vcs.PushEvent{
+		Ref:                "refs/heads/main",
+		RepositoryID:       "PRJ/my-repo",
+		RepositoryURL:      "https://bitbucket.example.com/projects/PRJ/repos/my-repo/browse",
+		RepositoryFullPath: "PRJ/my-repo",
+		AuthorName:         "Charlie",
+		CommitList: []vcs.Commit{
+			{
+				ID:          "0123abcdef4567abcdef8987abcdef6543abcdef",
+				Title:       "Add migration",
+				Message:     "Add migration\n\nCreate table.",
+				CreatedTs:   1548720747,
+				URL:         "https://bitbucket.example.com/projects/PRJ/repos/my-repo/commits/0123abcdef4567abcdef8987abcdef6543abcdef",
+				AuthorName:  "charlie",
+				AuthorEmail: "charlie@example.com",
+				AddedList:   []string{"migration/1.0__init.sql"},
+			},
+			{
+				ID:           "def0123abcdef4567abcdef8987abcdef6543abc",
+				Title:        "Update migration",
+				Message:      "Update migration",
+				CreatedTs:    1548720847,
+				URL:          "https://bitbucket.example.com/projects/PRJ/repos/my-repo/commits/def0123abcdef4567abcdef8987abcdef6543abc",
+				AuthorName:   "charlie",
+				AuthorEmail:  "charlie@example.com",
+				ModifiedList: []string{"migration/1.0__init.sql"},
+			},
+		},
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestProvider_CreatePullRequestComment(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, http.MethodPost, r.Method)
+						assert.Equal(t, "/rest/api/1.0/projects/PRJ/repos/my-repo/pull-requests/101/comments", r.URL.Path)
+
+						body, err := io.ReadAll(r.Body)
+						require.NoError(t, err)
+						assert.Equal(t, `{"text":"All task checks passed."}`, string(body))
+						return &http.Response{
+							StatusCode: http.StatusCreated,
+							Body:       io.NopCloser(strings.NewReader(`{"id": 1, "version": 0, "text": "All task checks passed."}`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	err := p.CreatePullRequestComment(ctx, common.OauthContext{}, bitbucketURL, "PRJ/my-repo", "101", "All task checks passed.")
+	require.NoError(t, err)
+}
+
+func TestProvider_CreatePullRequestReview(t *testing.T) {
+	var bodyList []string
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, http.MethodPost, r.Method)
+						assert.Equal(t, "/rest/api/1.0/projects/PRJ/repos/my-repo/pull-requests/101/comments", r.URL.Path)
+
+						body, err := io.ReadAll(r.Body)
+						require.NoError(t, err)
+						bodyList = append(bodyList, string(body))
+						return &http.Response{
+							StatusCode: http.StatusCreated,
+							Body:       io.NopCloser(strings.NewReader(`{"id": 1, "version": 0}`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	err := p.CreatePullRequestReview(ctx, common.OauthContext{}, bitbucketURL, "PRJ/my-repo", "101", &vcs.PullRequestReview{
+		CommitID: "6dcb09b5b57875f334f61aebed695e2e4193db5e",
+		Body:     "Task checks found problems.",
+		CommentList: []*vcs.PullRequestReviewComment{
+			{Path: "bytebase/db__ver1__migrate__create_t.sql", Line: 2, Body: "**WARN** Require primary key"},
+		},
+	})
+	require.NoError(t, err)
+	want := []string{
+		`{"text":"**WARN** Require primary key","anchor":{"path":"bytebase/db__ver1__migrate__create_t.sql","line":2,"lineType":"ADDED","fileType":"TO","diffType":"EFFECTIVE"}}`,
+		`{"text":"Task checks found problems."}`,
+	}
+	assert.Equal(t, want, bodyList)
+}
+
+func TestProvider_SetCommitStatus(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, http.MethodPost, r.Method)
+						assert.Equal(t, "/rest/build-status/1.0/commits/6dcb09b5b57875f334f61aebed695e2e4193db5e", r.URL.Path)
+
+						body, err := io.ReadAll(r.Body)
+						require.NoError(t, err)
+						wantBody := `{"state":"FAILED","key":"bytebase/task-check","name":"bytebase/task-check","url":"https://bytebase.example.com/issue/1","description":"Task checks failed"}`
+						assert.Equal(t, wantBody, string(body))
+						return &http.Response{
+							StatusCode: http.StatusNoContent,
+							Body:       io.NopCloser(strings.NewReader("")),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	err := p.SetCommitStatus(
+		ctx,
+		common.OauthContext{},
+		bitbucketURL,
+		"PRJ/my-repo",
+		"6dcb09b5b57875f334f61aebed695e2e4193db5e",
+		&vcs.CommitStatus{
+			State:       vcs.CommitStatusFailure,
+			Context:     "bytebase/task-check",
+			Description: "Task checks failed",
+			TargetURL:   "https://bytebase.example.com/issue/1",
+		},
+	)
+	require.NoError(t, err)
+}
+
+func TestWebhookPullRequestEvent_ToVCS(t *testing.T) {
+	var event WebhookPullRequestEvent
+	err := json.Unmarshal([]byte(`
+{
+  "eventKey": "pr:from_ref_updated",
+  "actor": {"name": "admin", "emailAddress": "admin@example.com", "displayName": "Administrator"},
+  "pullRequest": {
+    "id": 101,
+    "title": "Add migration",
+    "fromRef": {
+      "id": "refs/heads/feature",
+      "displayId": "feature",
+      "latestCommit": "6dcb09b5b57875f334f61aebed695e2e4193db5e",
+      "repository": {"slug": "my-repo", "project": {"key": "PRJ"}}
+    },
+    "toRef": {
+      "id": "refs/heads/main",
+      "displayId": "main",
+      "latestCommit": "18f3e63d05582537db6d183d9d557be09e1f90c8",
+      "repository": {
+        "slug": "my-repo",
+        "project": {"key": "PRJ"},
+        "links": {"self": [{"href": "https://bitbucket.example.com/projects/PRJ/repos/my-repo/browse"}]}
+      }
+    },
+    "links": {"self": [{"href": "https://bitbucket.example.com/projects/PRJ/repos/my-repo/pull-requests/101"}]}
+  }
+}
+`), &event)
+	require.NoError(t, err)
+
+	got, ok := event.ToVCS()
+	require.True(t, ok)
+	want := vcs.PullRequestEvent{
+		Action:             vcs.PullRequestUpdated,
+		ID:                 "101",
+		URL:                "https://bitbucket.example.com/projects/PRJ/repos/my-repo/pull-requests/101",
+		Title:              "Add migration",
+		HeadRef:            "refs/heads/feature",
+		HeadCommitID:       "6dcb09b5b57875f334f61aebed695e2e4193db5e",
+		BaseBranch:         "main",
+		RepositoryID:       "PRJ/my-repo",
+		RepositoryURL:      "https://bitbucket.example.com/projects/PRJ/repos/my-repo/browse",
+		RepositoryFullPath: "PRJ/my-repo",
+		AuthorName:         "Administrator",
+		AuthorEmail:        "admin@example.com",
+	}
+	assert.Equal(t, want, got)
+}
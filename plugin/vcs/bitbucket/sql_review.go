@@ -0,0 +1,27 @@
+package bitbucket
+
+import (
+	_ "embed"
+	"fmt"
+
+	"github.com/bytebase/bytebase/plugin/vcs"
+)
+
+// sqlReviewPipeline is the Jenkins pipeline for SQL review in VCS workflow.
+// Bitbucket Server does not have a built-in CI, thus we provide a Jenkins
+// pipeline which is the most common CI used along with Bitbucket Server.
+//
+//go:embed bytebase-sql-review.Jenkinsfile
+var sqlReviewPipeline string
+
+const (
+	// SQLReviewPipelineFilePath is the SQL review pipeline file path.
+	SQLReviewPipelineFilePath = ".bitbucket/bytebase-sql-review.Jenkinsfile"
+)
+
+// SetupSQLReviewCI will setup the SQL review CI content with SQL review
+// endpoint. The repository ID and the instance URL are hardcoded in the
+// pipeline because Jenkins does not expose them in a portable way.
+func SetupSQLReviewCI(endpoint, repositoryID, instanceURL string) string {
+	return fmt.Sprintf(sqlReviewPipeline, vcs.SQLReviewAPISecretName, endpoint, repositoryID, instanceURL)
+}
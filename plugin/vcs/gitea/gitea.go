@@ -0,0 +1,1667 @@
+// Package gitea is the plugin for Gitea.
+package gitea
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/plugin/vcs"
+	"github.com/bytebase/bytebase/plugin/vcs/internal/oauth"
+)
+
+const (
+	// SecretTokenLength is the length of secret token.
+	SecretTokenLength = 16
+
+	// apiPath is the API path.
+	apiPath = "api/v1"
+	// apiPageSize is the default page size when making API requests. Gitea
+	// caps the page size to the MAX_RESPONSE_ITEMS in its config, which defaults
+	// to 50.
+	apiPageSize = 50
+)
+
+func init() {
+	vcs.Register(vcs.GiteaSelfHost, newProvider)
+}
+
+var _ vcs.Provider = (*Provider)(nil)
+
+// Provider is a Gitea self host VCS provider.
+type Provider struct {
+	client *http.Client
+}
+
+func newProvider(config vcs.ProviderConfig) vcs.Provider {
+	if config.Client == nil {
+		config.Client = &http.Client{}
+	}
+	return &Provider{
+		client: config.Client,
+	}
+}
+
+// APIURL returns the API URL path of a Gitea instance.
+func (*Provider) APIURL(instanceURL string) string {
+	return fmt.Sprintf("%s/%s", instanceURL, apiPath)
+}
+
+// RepositoryRole is the permission of the repository collaborator.
+type RepositoryRole string
+
+// The list of Gitea repository permissions.
+const (
+	RepositoryRoleOwner RepositoryRole = "owner"
+	RepositoryRoleAdmin RepositoryRole = "admin"
+	RepositoryRoleWrite RepositoryRole = "write"
+	RepositoryRoleRead  RepositoryRole = "read"
+)
+
+// User represents a Gitea API response for a user.
+type User struct {
+	ID       int64  `json:"id"`
+	Login    string `json:"login"`
+	FullName string `json:"full_name"`
+	Email    string `json:"email"`
+	// Active is only returned to the site admins, thus we do not rely on it.
+	Active bool `json:"active"`
+}
+
+// RepositoryCollaboratorPermission represents a Gitea API response for the
+// permission of a repository collaborator.
+type RepositoryCollaboratorPermission struct {
+	Permission string `json:"permission"`
+}
+
+// Repository represents a Gitea API response for a repository.
+type Repository struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	FullName    string `json:"full_name"`
+	HTMLURL     string `json:"html_url"`
+	Permissions struct {
+		Admin bool `json:"admin"`
+	} `json:"permissions"`
+}
+
+// RepositoryTree represents a Gitea API response for a repository tree.
+type RepositoryTree struct {
+	Tree      []RepositoryTreeNode `json:"tree"`
+	Truncated bool                 `json:"truncated"`
+}
+
+// RepositoryTreeNode represents a Gitea API response for a repository tree
+// node.
+type RepositoryTreeNode struct {
+	Path string `json:"path"`
+	Type string `json:"type"`
+}
+
+// File represents a Gitea API response for a repository file.
+type File struct {
+	Encoding string `json:"encoding"`
+	Size     int64  `json:"size"`
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	Content  string `json:"content"`
+	// SHA is the blob SHA of the file, which is required to update the file.
+	SHA string `json:"sha"`
+}
+
+// FileCommit represents a Gitea API request for committing a file.
+type FileCommit struct {
+	Branch  string `json:"branch,omitempty"`
+	Content string `json:"content"`
+	Message string `json:"message"`
+	SHA     string `json:"sha,omitempty"`
+}
+
+// CommitAuthor represents a Gitea API response for a commit author.
+type CommitAuthor struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	// Date expects corresponding JSON value is a string in RFC 3339 format,
+	// see https://pkg.go.dev/time#Time.MarshalJSON.
+	Date time.Time `json:"date"`
+}
+
+// Commit represents a Gitea API response for a commit.
+type Commit struct {
+	SHA    string `json:"sha"`
+	Commit struct {
+		Author CommitAuthor `json:"author"`
+	} `json:"commit"`
+}
+
+// WebhookType is the Gitea webhook type.
+type WebhookType string
+
+const (
+	// WebhookPush is the webhook type for push.
+	WebhookPush WebhookType = "push"
+	// WebhookPullRequest is the webhook type for pull request.
+	WebhookPullRequest WebhookType = "pull_request"
+)
+
+// WebhookInfo represents a Gitea API response for the webhook information.
+type WebhookInfo struct {
+	ID int `json:"id"`
+}
+
+// WebhookConfig represents the Gitea API message for webhook configuration.
+type WebhookConfig struct {
+	// URL is the URL to which the payloads will be delivered.
+	URL string `json:"url"`
+	// ContentType is the media type used to serialize the payloads. Supported
+	// values include "json" and "form".
+	ContentType string `json:"content_type"`
+	// Secret is the secret will be used as the key to generate the HMAC hex digest
+	// value in the X-Gitea-Signature header.
+	Secret string `json:"secret"`
+}
+
+// WebhookCreateOrUpdate represents a Gitea API request for creating or
+// updating a webhook.
+//
+// NOTE: Gitea only accepts the Type when creating the webhook, and ignores it
+// when updating.
+type WebhookCreateOrUpdate struct {
+	// Type is the type of the webhook, which should be "gitea" for the Gitea
+	// native payloads.
+	Type   string        `json:"type,omitempty"`
+	Config WebhookConfig `json:"config"`
+	// Events determines what events the hook is triggered for.
+	Events []string `json:"events"`
+	Active bool     `json:"active"`
+}
+
+// WebhookRepository is the API message for webhook repository.
+type WebhookRepository struct {
+	ID       int    `json:"id"`
+	FullName string `json:"full_name"`
+	HTMLURL  string `json:"html_url"`
+}
+
+// WebhookCommitAuthor is the API message for webhook commit author.
+type WebhookCommitAuthor struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// WebhookSender is the API message for webhook sender.
+type WebhookSender struct {
+	Login string `json:"login"`
+}
+
+// WebhookCommit is the API message for webhook commit.
+type WebhookCommit struct {
+	ID        string              `json:"id"`
+	Message   string              `json:"message"`
+	Timestamp time.Time           `json:"timestamp"`
+	URL       string              `json:"url"`
+	Author    WebhookCommitAuthor `json:"author"`
+	Added     []string            `json:"added"`
+	Modified  []string            `json:"modified"`
+}
+
+// WebhookPushEvent is the API message for webhook push event.
+type WebhookPushEvent struct {
+	Ref        string            `json:"ref"`
+	Repository WebhookRepository `json:"repository"`
+	Sender     WebhookSender     `json:"sender"`
+	Commits    []WebhookCommit   `json:"commits"`
+}
+
+// WebhookPullRequestAction is the action of the pull request event.
+type WebhookPullRequestAction string
+
+const (
+	// WebhookPullRequestOpened is the action when the pull request is opened.
+	WebhookPullRequestOpened WebhookPullRequestAction = "opened"
+	// WebhookPullRequestReopened is the action when the pull request is reopened.
+	WebhookPullRequestReopened WebhookPullRequestAction = "reopened"
+	// WebhookPullRequestSynchronized is the action when new commits are pushed to the head branch of the pull request.
+	WebhookPullRequestSynchronized WebhookPullRequestAction = "synchronized"
+	// WebhookPullRequestClosed is the action when the pull request is closed, the pull request is merged if the "merged" is true.
+	WebhookPullRequestClosed WebhookPullRequestAction = "closed"
+)
+
+// WebhookPullRequestDetail is the API message for webhook pull request.
+type WebhookPullRequestDetail struct {
+	HTMLURL string                 `json:"html_url"`
+	Title   string                 `json:"title"`
+	Merged  bool                   `json:"merged"`
+	Head    giteaPullRequestBranch `json:"head"`
+	Base    giteaPullRequestBranch `json:"base"`
+}
+
+// WebhookPullRequestEvent is the API message for webhook pull request event.
+type WebhookPullRequestEvent struct {
+	Action      WebhookPullRequestAction `json:"action"`
+	Number      int                      `json:"number"`
+	PullRequest WebhookPullRequestDetail `json:"pull_request"`
+	Repository  WebhookRepository        `json:"repository"`
+	Sender      WebhookSender            `json:"sender"`
+}
+
+// fetchUserInfoImpl fetches user information from the given resourceURI, which
+// should be either "user" or "users/{username}".
+func (p *Provider) fetchUserInfoImpl(ctx context.Context, oauthCtx common.OauthContext, instanceURL, resourceURI string) (*vcs.UserInfo, error) {
+	url := fmt.Sprintf("%s/%s", p.APIURL(instanceURL), resourceURI)
+	code, _, body, err := oauth.Get(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "GET")
+	}
+
+	if code == http.StatusNotFound {
+		return nil, common.Errorf(common.NotFound, "failed to read user info from URL %s", url)
+	} else if code >= 300 {
+		return nil, errors.Errorf("failed to read user info from URL %s, status code: %d, body: %s", url, code, body)
+	}
+
+	var user User
+	if err = json.Unmarshal([]byte(body), &user); err != nil {
+		return nil, errors.Wrap(err, "unmarshal")
+	}
+	name := user.FullName
+	if name == "" {
+		name = user.Login
+	}
+	return &vcs.UserInfo{
+		PublicEmail: user.Email,
+		Name:        name,
+		State:       vcs.StateActive,
+	}, err
+}
+
+// TryLogin tries to fetch the user info from the current OAuth context.
+func (p *Provider) TryLogin(ctx context.Context, oauthCtx common.OauthContext, instanceURL string) (*vcs.UserInfo, error) {
+	return p.fetchUserInfoImpl(ctx, oauthCtx, instanceURL, "user")
+}
+
+// FetchCommitByID fetches the commit data by its ID from the repository.
+//
+// Docs: https://try.gitea.io/api/swagger#/repository/repoGetSingleCommit
+func (p *Provider) FetchCommitByID(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, commitID string) (*vcs.Commit, error) {
+	url := fmt.Sprintf("%s/repos/%s/git/commits/%s", p.APIURL(instanceURL), repositoryID, commitID)
+	code, _, body, err := oauth.Get(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "GET")
+	}
+
+	if code == http.StatusNotFound {
+		return nil, common.Errorf(common.NotFound, "failed to fetch commit data from URL %s", url)
+	} else if code >= 300 {
+		return nil, errors.Errorf("failed to fetch commit data from URL %s, status code: %d, body: %s", url, code, body)
+	}
+
+	commit := &Commit{}
+	if err := json.Unmarshal([]byte(body), commit); err != nil {
+		return nil, errors.Wrap(err, "unmarshal body")
+	}
+
+	return &vcs.Commit{
+		ID:          commit.SHA,
+		AuthorName:  commit.Commit.Author.Name,
+		AuthorEmail: commit.Commit.Author.Email,
+		CreatedTs:   commit.Commit.Author.Date.Unix(),
+	}, nil
+}
+
+// FetchUserInfo fetches user info of given username.
+func (p *Provider) FetchUserInfo(ctx context.Context, oauthCtx common.OauthContext, instanceURL, username string) (*vcs.UserInfo, error) {
+	return p.fetchUserInfoImpl(ctx, oauthCtx, instanceURL, fmt.Sprintf("users/%s", username))
+}
+
+func getRoleAndMappedRole(permission string) (giteaRole RepositoryRole, bytebaseRole common.ProjectRole) {
+	// Keep the same mapping as GitHub, where the collaborators who can push to
+	// the repository are the project owners.
+	switch permission {
+	case "owner":
+		return RepositoryRoleOwner, common.ProjectOwner
+	case "admin":
+		return RepositoryRoleAdmin, common.ProjectOwner
+	case "write":
+		return RepositoryRoleWrite, common.ProjectOwner
+	case "read":
+		return RepositoryRoleRead, common.ProjectDeveloper
+	}
+	return "", ""
+}
+
+// FetchRepositoryActiveMemberList fetch all active members of a repository.
+//
+// NOTE: Gitea does not list the repository owner as a collaborator.
+//
+// Docs: https://try.gitea.io/api/swagger#/repository/repoListCollaborators
+func (p *Provider) FetchRepositoryActiveMemberList(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID string) ([]*vcs.RepositoryMember, error) {
+	var allCollaborators []User
+	page := 1
+	for {
+		collaborators, hasNextPage, err := p.fetchPaginatedRepositoryCollaborators(ctx, oauthCtx, instanceURL, repositoryID, page)
+		if err != nil {
+			return nil, errors.Wrap(err, "fetch paginated list")
+		}
+		allCollaborators = append(allCollaborators, collaborators...)
+
+		if !hasNextPage {
+			break
+		}
+		page++
+	}
+
+	var emptyEmailUserList []string
+	var allMembers []*vcs.RepositoryMember
+	for _, c := range allCollaborators {
+		name := c.FullName
+		if name == "" {
+			name = c.Login
+		}
+		if c.Email == "" {
+			emptyEmailUserList = append(emptyEmailUserList, name)
+			continue
+		}
+
+		permission, err := p.fetchRepositoryCollaboratorPermission(ctx, oauthCtx, instanceURL, repositoryID, c.Login)
+		if err != nil {
+			return nil, errors.Wrapf(err, "fetch collaborator permission, login: %s", c.Login)
+		}
+		giteaRole, bytebaseRole := getRoleAndMappedRole(permission)
+		if bytebaseRole == "" {
+			continue
+		}
+		allMembers = append(allMembers,
+			&vcs.RepositoryMember{
+				Name:         name,
+				Email:        c.Email,
+				Role:         bytebaseRole,
+				VCSRole:      string(giteaRole),
+				State:        vcs.StateActive,
+				RoleProvider: vcs.GiteaSelfHost,
+			},
+		)
+	}
+
+	if len(emptyEmailUserList) != 0 {
+		return nil, errors.Errorf("[ %v ] did not configure their email visibility in Gitea, please make sure every members' email is visible before syncing", strings.Join(emptyEmailUserList, ", "))
+	}
+
+	return allMembers, nil
+}
+
+// fetchPaginatedRepositoryCollaborators fetches collaborators of a repository
+// in given page. It return the paginated results along with a boolean
+// indicating whether the next page exists.
+func (p *Provider) fetchPaginatedRepositoryCollaborators(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID string, page int) (collaborators []User, hasNextPage bool, err error) {
+	url := fmt.Sprintf("%s/repos/%s/collaborators?page=%d&limit=%d", p.APIURL(instanceURL), repositoryID, page, apiPageSize)
+	code, _, body, err := oauth.Get(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "GET %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return nil, false, common.Errorf(common.NotFound, "failed to fetch repository collaborators from URL %s", url)
+	} else if code >= 300 {
+		return nil, false,
+			errors.Errorf("failed to read repository collaborators from URL %s, status code: %d, body: %s",
+				url,
+				code,
+				body,
+			)
+	}
+
+	if err := json.Unmarshal([]byte(body), &collaborators); err != nil {
+		return nil, false, errors.Wrap(err, "unmarshal body")
+	}
+	return collaborators, len(collaborators) >= apiPageSize, nil
+}
+
+// fetchRepositoryCollaboratorPermission fetches the permission of the
+// collaborator in the repository.
+//
+// Docs: https://try.gitea.io/api/swagger#/repository/repoGetRepoPermissions
+func (p *Provider) fetchRepositoryCollaboratorPermission(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, login string) (string, error) {
+	url := fmt.Sprintf("%s/repos/%s/collaborators/%s/permission", p.APIURL(instanceURL), repositoryID, login)
+	code, _, body, err := oauth.Get(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return "", errors.Wrapf(err, "GET %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return "", common.Errorf(common.NotFound, "failed to fetch collaborator permission from URL %s", url)
+	} else if code >= 300 {
+		return "", errors.Errorf("failed to fetch collaborator permission from URL %s, status code: %d, body: %s",
+			url,
+			code,
+			body,
+		)
+	}
+
+	var permission RepositoryCollaboratorPermission
+	if err := json.Unmarshal([]byte(body), &permission); err != nil {
+		return "", errors.Wrap(err, "unmarshal body")
+	}
+	return permission.Permission, nil
+}
+
+// oauthResponse is a Gitea OAuth response.
+type oauthResponse struct {
+	AccessToken      string `json:"access_token" `
+	RefreshToken     string `json:"refresh_token"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error,omitempty"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// toVCSOAuthToken converts the response to *vcs.OAuthToken.
+func (o oauthResponse) toVCSOAuthToken() *vcs.OAuthToken {
+	oauthToken := &vcs.OAuthToken{
+		AccessToken:  o.AccessToken,
+		RefreshToken: o.RefreshToken,
+		ExpiresIn:    o.ExpiresIn,
+		// Gitea does not return the creation time of the token.
+		CreatedAt: time.Now().Unix(),
+	}
+	if oauthToken.ExpiresIn != 0 {
+		oauthToken.ExpiresTs = oauthToken.CreatedAt + oauthToken.ExpiresIn
+	}
+	return oauthToken
+}
+
+// ExchangeOAuthToken exchanges OAuth content with the provided authorization code.
+//
+// Docs: https://docs.gitea.io/en-us/oauth2-provider/
+func (p *Provider) ExchangeOAuthToken(ctx context.Context, instanceURL string, oauthExchange *common.OAuthExchange) (*vcs.OAuthToken, error) {
+	urlParams := &url.Values{}
+	urlParams.Set("client_id", oauthExchange.ClientID)
+	urlParams.Set("client_secret", oauthExchange.ClientSecret)
+	urlParams.Set("code", oauthExchange.Code)
+	urlParams.Set("redirect_uri", oauthExchange.RedirectURL)
+	urlParams.Set("grant_type", "authorization_code")
+	url := fmt.Sprintf("%s/login/oauth/access_token?%s", instanceURL, urlParams.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		urlParams.Set("client_secret", "**redacted**")
+		redactedURL := fmt.Sprintf("%s/login/oauth/access_token?%s", instanceURL, urlParams.Encode())
+		return nil, errors.Wrapf(err, "construct POST %s", redactedURL)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to exchange OAuth token")
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read OAuth response body, code %v", resp.StatusCode)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	oauthResp := new(oauthResponse)
+	if err := json.Unmarshal(body, oauthResp); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal OAuth response body, code %v", resp.StatusCode)
+	}
+	if oauthResp.Error != "" {
+		return nil, errors.Errorf("failed to exchange OAuth token, error: %v, error_description: %v", oauthResp.Error, oauthResp.ErrorDescription)
+	}
+	return oauthResp.toVCSOAuthToken(), nil
+}
+
+// FetchAllRepositoryList fetches all repositories where the authenticated user
+// has admin permissions, which is required to create webhook in the repository.
+//
+// Docs: https://try.gitea.io/api/swagger#/user/userCurrentListRepos
+func (p *Provider) FetchAllRepositoryList(ctx context.Context, oauthCtx common.OauthContext, instanceURL string) ([]*vcs.Repository, error) {
+	var giteaRepos []Repository
+	page := 1
+	for {
+		repos, hasNextPage, err := p.fetchPaginatedRepositoryList(ctx, oauthCtx, instanceURL, page)
+		if err != nil {
+			return nil, errors.Wrap(err, "fetch paginated list")
+		}
+		giteaRepos = append(giteaRepos, repos...)
+
+		if !hasNextPage {
+			break
+		}
+		page++
+	}
+
+	var allRepos []*vcs.Repository
+	for _, r := range giteaRepos {
+		if !r.Permissions.Admin {
+			continue
+		}
+		allRepos = append(allRepos,
+			&vcs.Repository{
+				ID:       r.ID,
+				Name:     r.Name,
+				FullPath: r.FullName,
+				WebURL:   r.HTMLURL,
+			},
+		)
+	}
+	return allRepos, nil
+}
+
+// fetchPaginatedRepositoryList fetches repositories where the authenticated
+// user has access to in given page. It returns the paginated results along
+// with a boolean indicating whether the next page exists.
+func (p *Provider) fetchPaginatedRepositoryList(ctx context.Context, oauthCtx common.OauthContext, instanceURL string, page int) (repos []Repository, hasNextPage bool, err error) {
+	url := fmt.Sprintf("%s/user/repos?page=%d&limit=%d", p.APIURL(instanceURL), page, apiPageSize)
+	code, _, body, err := oauth.Get(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "GET %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return nil, false, common.Errorf(common.NotFound, "failed to fetch repository list from URL %s", url)
+	} else if code >= 300 {
+		return nil, false,
+			errors.Errorf("failed to fetch repository list from URL %s, status code: %d, body: %s",
+				url,
+				code,
+				body,
+			)
+	}
+
+	if err := json.Unmarshal([]byte(body), &repos); err != nil {
+		return nil, false, errors.Wrap(err, "unmarshal")
+	}
+	return repos, len(repos) >= apiPageSize, nil
+}
+
+// FetchRepositoryFileList fetches the all files from the given repository tree
+// recursively.
+//
+// Docs: https://try.gitea.io/api/swagger#/repository/GetTree
+func (p *Provider) FetchRepositoryFileList(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, ref, filePath string) ([]*vcs.RepositoryTreeNode, error) {
+	if filePath != "" && !strings.HasSuffix(filePath, "/") {
+		filePath += "/"
+	}
+
+	var allTreeNodes []*vcs.RepositoryTreeNode
+	page := 1
+	for {
+		repoTree, err := p.fetchPaginatedRepositoryTree(ctx, oauthCtx, instanceURL, repositoryID, ref, page)
+		if err != nil {
+			return nil, errors.Wrap(err, "fetch paginated tree")
+		}
+		for _, n := range repoTree.Tree {
+			// Gitea does not support filtering by path prefix, thus simulating the
+			// behavior here.
+			if n.Type == "blob" && strings.HasPrefix(n.Path, filePath) {
+				allTreeNodes = append(allTreeNodes,
+					&vcs.RepositoryTreeNode{
+						Path: n.Path,
+						Type: n.Type,
+					},
+				)
+			}
+		}
+
+		// Gitea marks the tree as truncated if there are more pages.
+		if !repoTree.Truncated {
+			break
+		}
+		page++
+	}
+	return allTreeNodes, nil
+}
+
+// fetchPaginatedRepositoryTree fetches the repository tree recursively in
+// given page.
+func (p *Provider) fetchPaginatedRepositoryTree(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, ref string, page int) (*RepositoryTree, error) {
+	url := fmt.Sprintf("%s/repos/%s/git/trees/%s?recursive=true&page=%d", p.APIURL(instanceURL), repositoryID, url.PathEscape(ref), page)
+	code, _, body, err := oauth.Get(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "GET %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return nil, common.Errorf(common.NotFound, "failed to fetch repository file list from URL %s", url)
+	} else if code >= 300 {
+		return nil,
+			errors.Errorf("failed to fetch repository file list from URL %s, status code: %d, body: %s",
+				url,
+				code,
+				body,
+			)
+	}
+
+	repoTree := &RepositoryTree{}
+	if err := json.Unmarshal([]byte(body), repoTree); err != nil {
+		return nil, errors.Wrap(err, "unmarshal body")
+	}
+	return repoTree, nil
+}
+
+// CreateFile creates a file at given path in the repository.
+//
+// Docs: https://try.gitea.io/api/swagger#/repository/repoCreateFile
+func (p *Provider) CreateFile(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, filePath string, fileCommitCreate vcs.FileCommitCreate) error {
+	return p.commitFile(ctx, oauthCtx, instanceURL, repositoryID, filePath, fileCommitCreate, false /* overwrite */)
+}
+
+// OverwriteFile overwrites an existing file at given path in the repository.
+//
+// Docs: https://try.gitea.io/api/swagger#/repository/repoUpdateFile
+func (p *Provider) OverwriteFile(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, filePath string, fileCommitCreate vcs.FileCommitCreate) error {
+	return p.commitFile(ctx, oauthCtx, instanceURL, repositoryID, filePath, fileCommitCreate, true /* overwrite */)
+}
+
+// commitFile creates or updates the file at given path in the repository.
+// Unlike GitHub, Gitea uses POST to create and PUT to update a file.
+func (p *Provider) commitFile(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, filePath string, fileCommitCreate vcs.FileCommitCreate, overwrite bool) error {
+	body, err := json.Marshal(
+		FileCommit{
+			Message: fileCommitCreate.CommitMessage,
+			Content: base64.StdEncoding.EncodeToString([]byte(fileCommitCreate.Content)),
+			Branch:  fileCommitCreate.Branch,
+			SHA:     fileCommitCreate.LastCommitID,
+		},
+	)
+	if err != nil {
+		return errors.Wrap(err, "marshal file commit")
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/contents/%s", p.APIURL(instanceURL), repositoryID, escapeFilePath(filePath))
+	request := oauth.Post
+	method := http.MethodPost
+	if overwrite {
+		request = oauth.Put
+		method = http.MethodPut
+	}
+	code, _, resp, err := request(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		bytes.NewReader(body),
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "%s %s", method, url)
+	}
+
+	if code == http.StatusNotFound {
+		return common.Errorf(common.NotFound, "failed to create/update file through URL %s", url)
+	} else if code >= 300 {
+		return errors.Errorf("failed to create/update file through URL %s, status code: %d, body: %s",
+			url,
+			code,
+			resp,
+		)
+	}
+	return nil
+}
+
+// ReadFileMeta reads the metadata of the given file in the repository.
+//
+// Docs: https://try.gitea.io/api/swagger#/repository/repoGetContents
+func (p *Provider) ReadFileMeta(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, filePath, ref string) (*vcs.FileMeta, error) {
+	file, err := p.readFile(ctx, oauthCtx, instanceURL, repositoryID, filePath, ref)
+	if err != nil {
+		return nil, errors.Wrap(err, "read file")
+	}
+
+	return &vcs.FileMeta{
+		Name:         file.Name,
+		Path:         file.Path,
+		Size:         file.Size,
+		LastCommitID: file.SHA,
+	}, nil
+}
+
+// ReadFileContent reads the content of the given file in the repository.
+//
+// Docs: https://try.gitea.io/api/swagger#/repository/repoGetContents
+func (p *Provider) ReadFileContent(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, filePath, ref string) (string, error) {
+	file, err := p.readFile(ctx, oauthCtx, instanceURL, repositoryID, filePath, ref)
+	if err != nil {
+		return "", errors.Wrap(err, "read file")
+	}
+	return file.Content, nil
+}
+
+// readFile reads the given file in the repository.
+func (p *Provider) readFile(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, filePath, ref string) (*File, error) {
+	url := fmt.Sprintf("%s/repos/%s/contents/%s?ref=%s", p.APIURL(instanceURL), repositoryID, escapeFilePath(filePath), url.QueryEscape(ref))
+	code, _, body, err := oauth.Get(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "GET %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return nil, common.Errorf(common.NotFound, "failed to read file from URL %s", url)
+	} else if code >= 300 {
+		return nil,
+			errors.Errorf("failed to read file from URL %s, status code: %d, body: %s",
+				url,
+				code,
+				body,
+			)
+	}
+
+	// This API endpoint returns a JSON array if the path is a directory, and we do
+	// not want that.
+	if body != "" && body[0] == '[' {
+		return nil, errors.Errorf("%q is a directory not a file", filePath)
+	}
+
+	var file File
+	if err = json.Unmarshal([]byte(body), &file); err != nil {
+		return nil, errors.Wrap(err, "unmarshal body")
+	}
+
+	if file.Encoding == "base64" {
+		decodedContent, err := base64.StdEncoding.DecodeString(file.Content)
+		if err != nil {
+			return nil, errors.Wrap(err, "decode file content")
+		}
+		file.Content = string(decodedContent)
+	}
+	return &file, nil
+}
+
+type giteaPullRequestFile struct {
+	FileName string `json:"filename"`
+	// The file status in Gitea PR.
+	// Available values: "added", "deleted", "changed", "renamed", "copied", "unchanged"
+	Status string `json:"status"`
+}
+
+type giteaPullRequestBranch struct {
+	Ref string `json:"ref"`
+	SHA string `json:"sha"`
+}
+
+type giteaPullRequest struct {
+	Number  int                    `json:"number"`
+	HTMLURL string                 `json:"html_url"`
+	Head    giteaPullRequestBranch `json:"head"`
+}
+
+// ListPullRequestFile lists the changed files in the pull request.
+//
+// Docs: https://try.gitea.io/api/swagger#/repository/repoGetPullRequestFiles
+func (p *Provider) ListPullRequestFile(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, pullRequestID string) ([]*vcs.PullRequestFile, error) {
+	// Unlike GitHub, the changed files do not carry the ref, thus we use the head
+	// commit of the pull request for all of them.
+	pr, err := p.getPullRequest(ctx, oauthCtx, instanceURL, repositoryID, pullRequestID)
+	if err != nil {
+		return nil, errors.Wrap(err, "get pull request")
+	}
+
+	var allPRFiles []giteaPullRequestFile
+	page := 1
+	for {
+		fileList, err := p.listPaginatedPullRequestFile(ctx, oauthCtx, instanceURL, repositoryID, pullRequestID, page)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to list pull request file")
+		}
+		allPRFiles = append(allPRFiles, fileList...)
+
+		if len(fileList) < apiPageSize {
+			break
+		}
+		page++
+	}
+
+	var res []*vcs.PullRequestFile
+	for _, file := range allPRFiles {
+		res = append(res, &vcs.PullRequestFile{
+			Path:         file.FileName,
+			LastCommitID: pr.Head.SHA,
+			IsDeleted:    file.Status == "deleted",
+		})
+	}
+	return res, nil
+}
+
+// getPullRequest gets the pull request in the repository.
+//
+// Docs: https://try.gitea.io/api/swagger#/repository/repoGetPullRequest
+func (p *Provider) getPullRequest(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, pullRequestID string) (*giteaPullRequest, error) {
+	url := fmt.Sprintf("%s/repos/%s/pulls/%s", p.APIURL(instanceURL), repositoryID, pullRequestID)
+	code, _, body, err := oauth.Get(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "GET %s", url)
+	}
+	if code == http.StatusNotFound {
+		return nil, common.Errorf(common.NotFound, "failed to get pull request from URL %s", url)
+	} else if code >= 300 {
+		return nil, errors.Errorf("failed to get pull request from URL %s, status code: %d, body: %s",
+			url,
+			code,
+			body,
+		)
+	}
+
+	pr := new(giteaPullRequest)
+	if err := json.Unmarshal([]byte(body), pr); err != nil {
+		return nil, err
+	}
+	return pr, nil
+}
+
+// listPaginatedPullRequestFile lists the changed files in the pull request with pagination.
+func (p *Provider) listPaginatedPullRequestFile(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, pullRequestID string, page int) ([]giteaPullRequestFile, error) {
+	requestURL := fmt.Sprintf("%s/repos/%s/pulls/%s/files?limit=%d&page=%d", p.APIURL(instanceURL), repositoryID, pullRequestID, apiPageSize, page)
+	code, _, body, err := oauth.Get(
+		ctx,
+		p.client,
+		requestURL,
+		&oauthCtx.AccessToken,
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "GET %s", requestURL)
+	}
+	if code == http.StatusNotFound {
+		return nil, common.Errorf(common.NotFound, "failed to list pull request file from URL %s", requestURL)
+	} else if code >= 300 {
+		return nil, errors.Errorf("failed to list pull request file from URL %s, status code: %d, body: %s",
+			requestURL,
+			code,
+			body,
+		)
+	}
+
+	var prFiles []giteaPullRequestFile
+	if err := json.Unmarshal([]byte(body), &prFiles); err != nil {
+		return nil, err
+	}
+	return prFiles, nil
+}
+
+type giteaBranch struct {
+	Name   string `json:"name"`
+	Commit struct {
+		ID string `json:"id"`
+	} `json:"commit"`
+}
+
+type giteaBranchCreate struct {
+	NewBranchName string `json:"new_branch_name"`
+	// OldRefName is the branch, tag or commit SHA to create the branch from.
+	OldRefName string `json:"old_ref_name"`
+}
+
+// GetBranch gets the given branch in the repository.
+//
+// Docs: https://try.gitea.io/api/swagger#/repository/repoGetBranch
+func (p *Provider) GetBranch(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, branchName string) (*vcs.BranchInfo, error) {
+	url := fmt.Sprintf("%s/repos/%s/branches/%s", p.APIURL(instanceURL), repositoryID, url.PathEscape(branchName))
+	code, _, body, err := oauth.Get(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "GET %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return nil, common.Errorf(common.NotFound, "failed to get branch from URL %s", url)
+	} else if code >= 300 {
+		return nil, errors.Errorf("failed to get branch from URL %s, status code: %d, body: %s",
+			url,
+			code,
+			body,
+		)
+	}
+
+	branch := new(giteaBranch)
+	if err := json.Unmarshal([]byte(body), branch); err != nil {
+		return nil, err
+	}
+
+	return &vcs.BranchInfo{
+		Name:         branch.Name,
+		LastCommitID: branch.Commit.ID,
+	}, nil
+}
+
+// CreateBranch creates the branch in the repository.
+//
+// Docs: https://try.gitea.io/api/swagger#/repository/repoCreateBranch
+func (p *Provider) CreateBranch(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID string, branch *vcs.BranchInfo) error {
+	body, err := json.Marshal(
+		giteaBranchCreate{
+			NewBranchName: branch.Name,
+			OldRefName:    branch.LastCommitID,
+		},
+	)
+	if err != nil {
+		return errors.Wrap(err, "marshal branch create")
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/branches", p.APIURL(instanceURL), repositoryID)
+	code, _, resp, err := oauth.Post(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		bytes.NewReader(body),
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "POST %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return common.Errorf(common.NotFound, "failed to create branch from URL %s", url)
+	} else if code >= 300 {
+		return errors.Errorf("failed to create branch from URL %s, status code: %d, body: %s",
+			url,
+			code,
+			resp,
+		)
+	}
+
+	return nil
+}
+
+// CreatePullRequest creates the pull request in the repository.
+//
+// NOTE: Gitea does not support removing the head branch after merged on
+// creating the pull request, thus the RemoveHeadAfterMerged is ignored.
+//
+// Docs: https://try.gitea.io/api/swagger#/repository/repoCreatePullRequest
+func (p *Provider) CreatePullRequest(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID string, pullRequestCreate *vcs.PullRequestCreate) (*vcs.PullRequest, error) {
+	body, err := json.Marshal(pullRequestCreate)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal pull request create")
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/pulls", p.APIURL(instanceURL), repositoryID)
+	code, _, resp, err := oauth.Post(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		bytes.NewReader(body),
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "POST %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return nil, common.Errorf(common.NotFound, "failed to create pull request from URL %s", url)
+	} else if code >= 300 {
+		return nil, errors.Errorf("failed to create pull request from URL %s, status code: %d, body: %s",
+			url,
+			code,
+			resp,
+		)
+	}
+
+	var res giteaPullRequest
+	if err := json.Unmarshal([]byte(resp), &res); err != nil {
+		return nil, err
+	}
+
+	return &vcs.PullRequest{
+		URL: res.HTMLURL,
+	}, nil
+}
+
+type giteaIssueCommentCreate struct {
+	Body string `json:"body"`
+}
+
+// CreatePullRequestComment creates a comment in the pull request.
+//
+// Docs: https://try.gitea.io/api/swagger#/issue/issueCreateComment
+func (p *Provider) CreatePullRequestComment(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, pullRequestID, comment string) error {
+	body, err := json.Marshal(
+		giteaIssueCommentCreate{
+			Body: comment,
+		},
+	)
+	if err != nil {
+		return errors.Wrap(err, "marshal pull request comment create")
+	}
+
+	// Pull requests share the index and the comment API with issues in Gitea.
+	url := fmt.Sprintf("%s/repos/%s/issues/%s/comments", p.APIURL(instanceURL), repositoryID, pullRequestID)
+	code, _, resp, err := oauth.Post(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		bytes.NewReader(body),
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "POST %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return common.Errorf(common.NotFound, "failed to create pull request comment from URL %s", url)
+	} else if code >= 300 {
+		return errors.Errorf("failed to create pull request comment from URL %s, status code: %d, body: %s",
+			url,
+			code,
+			resp,
+		)
+	}
+	return nil
+}
+
+type giteaPullRequestReviewCreate struct {
+	CommitID string                                `json:"commit_id"`
+	Body     string                                `json:"body"`
+	Event    string                                `json:"event"`
+	Comments []giteaPullRequestReviewCommentCreate `json:"comments,omitempty"`
+}
+
+type giteaPullRequestReviewCommentCreate struct {
+	Path        string `json:"path"`
+	NewPosition int    `json:"new_position"`
+	Body        string `json:"body"`
+}
+
+// CreatePullRequestReview creates a review with the line comments in the pull request.
+//
+// Docs: https://try.gitea.io/api/swagger#/repository/repoCreatePullReview
+func (p *Provider) CreatePullRequestReview(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, pullRequestID string, review *vcs.PullRequestReview) error {
+	reviewCreate := giteaPullRequestReviewCreate{
+		CommitID: review.CommitID,
+		Body:     review.Body,
+		// The review only comments, and neither approves nor requests changes.
+		Event: "COMMENT",
+	}
+	for _, comment := range review.CommentList {
+		reviewCreate.Comments = append(reviewCreate.Comments, giteaPullRequestReviewCommentCreate{
+			Path: comment.Path,
+			// The new position is the line number in the new version of the file.
+			NewPosition: comment.Line,
+			Body:        comment.Body,
+		})
+	}
+	body, err := json.Marshal(reviewCreate)
+	if err != nil {
+		return errors.Wrap(err, "marshal pull request review create")
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/pulls/%s/reviews", p.APIURL(instanceURL), repositoryID, pullRequestID)
+	code, _, resp, err := oauth.Post(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		bytes.NewReader(body),
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "POST %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return common.Errorf(common.NotFound, "failed to create pull request review from URL %s", url)
+	} else if code >= 300 {
+		return errors.Errorf("failed to create pull request review from URL %s, status code: %d, body: %s",
+			url,
+			code,
+			resp,
+		)
+	}
+	return nil
+}
+
+type giteaCommitStatusCreate struct {
+	State       string `json:"state"`
+	TargetURL   string `json:"target_url"`
+	Description string `json:"description"`
+	Context     string `json:"context"`
+}
+
+// Gitea does not have a running state for the commit status, thus we use
+// pending for both pending and running.
+var commitStatusStates = map[vcs.CommitStatusState]string{
+	vcs.CommitStatusPending:  "pending",
+	vcs.CommitStatusRunning:  "pending",
+	vcs.CommitStatusSuccess:  "success",
+	vcs.CommitStatusFailure:  "failure",
+	vcs.CommitStatusCanceled: "error",
+}
+
+// SetCommitStatus creates or updates the status of the commit.
+//
+// Docs: https://try.gitea.io/api/swagger#/repository/repoCreateStatus
+func (p *Provider) SetCommitStatus(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, commitID string, status *vcs.CommitStatus) error {
+	state, ok := commitStatusStates[status.State]
+	if !ok {
+		return errors.Errorf("unsupported commit status state %q", status.State)
+	}
+	body, err := json.Marshal(
+		giteaCommitStatusCreate{
+			State:       state,
+			TargetURL:   status.TargetURL,
+			Description: status.Description,
+			Context:     status.Context,
+		},
+	)
+	if err != nil {
+		return errors.Wrap(err, "marshal commit status create")
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/statuses/%s", p.APIURL(instanceURL), repositoryID, commitID)
+	code, _, resp, err := oauth.Post(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		bytes.NewReader(body),
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "POST %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return common.Errorf(common.NotFound, "failed to set commit status from URL %s", url)
+	} else if code >= 300 {
+		return errors.Errorf("failed to set commit status from URL %s, status code: %d, body: %s",
+			url,
+			code,
+			resp,
+		)
+	}
+	return nil
+}
+
+type actionSecret struct {
+	Data string `json:"data"`
+}
+
+// UpsertEnvironmentVariable creates or updates the Gitea Actions secret in the repository.
+//
+// Docs: https://try.gitea.io/api/swagger#/repository/updateRepoSecret
+func (p *Provider) UpsertEnvironmentVariable(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, key, value string) error {
+	body, err := json.Marshal(actionSecret{Data: value})
+	if err != nil {
+		return errors.Wrap(err, "marshal environment variable")
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/actions/secrets/%s", p.APIURL(instanceURL), repositoryID, key)
+	code, _, resp, err := oauth.Put(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		bytes.NewReader(body),
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "PUT %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return common.Errorf(common.NotFound, "failed to upsert environment variable from URL %s", url)
+	} else if code >= 300 {
+		return errors.Errorf("failed to upsert environment variable from URL %s, status code: %d, body: %s",
+			url,
+			code,
+			resp,
+		)
+	}
+
+	return nil
+}
+
+// CreateWebhook creates a webhook in the repository with given payload.
+//
+// Docs: https://try.gitea.io/api/swagger#/repository/repoCreateHook
+func (p *Provider) CreateWebhook(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID string, payload []byte) (string, error) {
+	url := fmt.Sprintf("%s/repos/%s/hooks", p.APIURL(instanceURL), repositoryID)
+	code, _, body, err := oauth.Post(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		bytes.NewReader(payload),
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return "", errors.Wrapf(err, "POST %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return "", common.Errorf(common.NotFound, "failed to create webhook through URL %s", url)
+	} else if code >= 300 {
+		return "", errors.Errorf("failed to create webhook through URL %s, status code: %d, body: %s",
+			url,
+			code,
+			body,
+		)
+	}
+
+	var webhookInfo WebhookInfo
+	if err = json.Unmarshal([]byte(body), &webhookInfo); err != nil {
+		return "", errors.Wrap(err, "unmarshal body")
+	}
+	return strconv.Itoa(webhookInfo.ID), nil
+}
+
+// PatchWebhook patches the webhook in the repository with given payload.
+//
+// Docs: https://try.gitea.io/api/swagger#/repository/repoEditHook
+func (p *Provider) PatchWebhook(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, webhookID string, payload []byte) error {
+	url := fmt.Sprintf("%s/repos/%s/hooks/%s", p.APIURL(instanceURL), repositoryID, webhookID)
+	code, _, body, err := oauth.Patch(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		bytes.NewReader(payload),
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "PATCH %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return common.Errorf(common.NotFound, "failed to patch webhook through URL %s", url)
+	} else if code >= 300 {
+		return errors.Errorf("failed to patch webhook through URL %s, status code: %d, body: %s",
+			url,
+			code,
+			body,
+		)
+	}
+	return nil
+}
+
+// DeleteWebhook deletes the webhook from the repository.
+//
+// Docs: https://try.gitea.io/api/swagger#/repository/repoDeleteHook
+func (p *Provider) DeleteWebhook(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, webhookID string) error {
+	url := fmt.Sprintf("%s/repos/%s/hooks/%s", p.APIURL(instanceURL), repositoryID, webhookID)
+	code, _, body, err := oauth.Delete(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "DELETE %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return nil // It is OK if the webhook has already gone
+	} else if code >= 300 {
+		return errors.Errorf("failed to delete webhook through URL %s, status code: %d, body: %s",
+			url,
+			code,
+			body,
+		)
+	}
+	return nil
+}
+
+// escapeFilePath escapes each segment of the file path, Gitea requires the
+// path separators to be kept as-is in the contents API.
+func escapeFilePath(filePath string) string {
+	segments := strings.Split(filePath, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// oauthContext is the request context for refreshing oauth token.
+type oauthContext struct {
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	RefreshToken string `json:"refresh_token"`
+	GrantType    string `json:"grant_type"`
+}
+
+type refreshOAuthResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	// token_type is not used.
+}
+
+func tokenRefresher(instanceURL string, oauthCtx oauthContext, refresher common.TokenRefresher) oauth.TokenRefresher {
+	return func(ctx context.Context, client *http.Client, oldToken *string) error {
+		url := fmt.Sprintf("%s/login/oauth/access_token", instanceURL)
+		oauthCtx.GrantType = "refresh_token"
+		body, err := json.Marshal(oauthCtx)
+		if err != nil {
+			return err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return errors.Wrapf(err, "construct POST %s", url)
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		resp, err := client.Do(req)
+		if err != nil {
+			return errors.Wrapf(err, "POST %s", url)
+		}
+
+		body, err = io.ReadAll(resp.Body)
+		if err != nil {
+			return errors.Wrapf(err, "read body of POST %s", url)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return errors.Errorf("non-200 POST %s status code %d with body %q", url, resp.StatusCode, body)
+		}
+
+		var r refreshOAuthResponse
+		if err = json.Unmarshal(body, &r); err != nil {
+			return errors.Wrapf(err, "unmarshal body from POST %s", url)
+		}
+
+		// Update the old token to new value for retries.
+		*oldToken = r.AccessToken
+
+		// Gitea access tokens expire in an hour by default, and the response does
+		// not carry the creation time.
+		var expireAt int64
+		if r.ExpiresIn != 0 {
+			expireAt = time.Now().Unix() + r.ExpiresIn
+		}
+		return refresher(r.AccessToken, r.RefreshToken, expireAt)
+	}
+}
+
+// ToVCS returns the push event in VCS format.
+func (p WebhookPushEvent) ToVCS() vcs.PushEvent {
+	var commitList []vcs.Commit
+	for _, commit := range p.Commits {
+		// Per Git convention, the message title and body are separated by two new line characters.
+		messages := strings.SplitN(commit.Message, "\n\n", 2)
+		messageTitle := strings.TrimSpace(messages[0])
+
+		commitList = append(commitList, vcs.Commit{
+			ID:           commit.ID,
+			Title:        messageTitle,
+			Message:      commit.Message,
+			CreatedTs:    commit.Timestamp.Unix(),
+			URL:          commit.URL,
+			AuthorName:   commit.Author.Name,
+			AuthorEmail:  commit.Author.Email,
+			AddedList:    commit.Added,
+			ModifiedList: commit.Modified,
+		})
+	}
+	return vcs.PushEvent{
+		Ref:                p.Ref,
+		RepositoryID:       p.Repository.FullName,
+		RepositoryURL:      p.Repository.HTMLURL,
+		RepositoryFullPath: p.Repository.FullName,
+		AuthorName:         p.Sender.Login,
+		CommitList:         commitList,
+	}
+}
+
+// ToVCS returns the pull request event in VCS format. It returns false if the
+// action of the event is irrelevant, e.g. the pull request is assigned.
+func (p WebhookPullRequestEvent) ToVCS() (vcs.PullRequestEvent, bool) {
+	var action vcs.PullRequestAction
+	switch p.Action {
+	case WebhookPullRequestOpened, WebhookPullRequestReopened:
+		action = vcs.PullRequestOpened
+	case WebhookPullRequestSynchronized:
+		action = vcs.PullRequestUpdated
+	case WebhookPullRequestClosed:
+		action = vcs.PullRequestClosed
+		if p.PullRequest.Merged {
+			action = vcs.PullRequestMerged
+		}
+	default:
+		return vcs.PullRequestEvent{}, false
+	}
+	return vcs.PullRequestEvent{
+		Action:             action,
+		ID:                 strconv.Itoa(p.Number),
+		URL:                p.PullRequest.HTMLURL,
+		Title:              p.PullRequest.Title,
+		HeadRef:            fmt.Sprintf("refs/heads/%s", p.PullRequest.Head.Ref),
+		HeadCommitID:       p.PullRequest.Head.SHA,
+		BaseBranch:         p.PullRequest.Base.Ref,
+		RepositoryID:       p.Repository.FullName,
+		RepositoryURL:      p.Repository.HTMLURL,
+		RepositoryFullPath: p.Repository.FullName,
+		AuthorName:         p.Sender.Login,
+	}, true
+}
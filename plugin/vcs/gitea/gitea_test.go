@@ -0,0 +1,970 @@
+package gitea
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/plugin/vcs"
+	"github.com/bytebase/bytebase/plugin/vcs/internal/oauth"
+)
+
+const giteaURL = "https://gitea.example.com"
+
+func TestProvider_FetchUserInfo(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, "/api/v1/users/octocat", r.URL.Path)
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body: io.NopCloser(strings.NewReader(`
+{
+  "id": 1,
+  "login": "octocat",
+  "full_name": "monalisa octocat",
+  "email": "octocat@example.com",
+  "avatar_url": "https://gitea.example.com/avatars/1",
+  "language": "en-US",
+  "is_admin": false,
+  "active": false,
+  "created": "2008-01-14T04:33:35Z"
+}
+`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	got, err := p.FetchUserInfo(ctx, common.OauthContext{}, giteaURL, "octocat")
+	require.NoError(t, err)
+	want := &vcs.UserInfo{
+		PublicEmail: "octocat@example.com",
+		Name:        "monalisa octocat",
+		State:       vcs.StateActive,
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestProvider_FetchRepositoryActiveMemberList(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						var body string
+						switch r.URL.Path {
+						case "/api/v1/repos/octocat/Hello-World/collaborators":
+							body = `
+[
+  {"id": 1, "login": "octocat", "full_name": "", "email": "octocat@example.com"},
+  {"id": 2, "login": "hubot", "full_name": "Hubot", "email": "hubot@example.com"}
+]
+`
+						case "/api/v1/repos/octocat/Hello-World/collaborators/octocat/permission":
+							body = `{"permission": "admin", "role_name": "admin"}`
+						case "/api/v1/repos/octocat/Hello-World/collaborators/hubot/permission":
+							body = `{"permission": "read", "role_name": "read"}`
+						default:
+							t.Fatalf("unexpected request path %q", r.URL.Path)
+						}
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body:       io.NopCloser(strings.NewReader(body)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	got, err := p.FetchRepositoryActiveMemberList(ctx, common.OauthContext{}, giteaURL, "octocat/Hello-World")
+	require.NoError(t, err)
+
+	want := []*vcs.RepositoryMember{
+		{
+			Name:         "octocat",
+			Email:        "octocat@example.com",
+			Role:         common.ProjectOwner,
+			VCSRole:      string(RepositoryRoleAdmin),
+			State:        vcs.StateActive,
+			RoleProvider: vcs.GiteaSelfHost,
+		},
+		{
+			Name:         "Hubot",
+			Email:        "hubot@example.com",
+			Role:         common.ProjectDeveloper,
+			VCSRole:      string(RepositoryRoleRead),
+			State:        vcs.StateActive,
+			RoleProvider: vcs.GiteaSelfHost,
+		},
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestProvider_FetchCommitByID(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, "/api/v1/repos/octocat/Hello-World/git/commits/7638417db6d59f3c431d3e1f261cc637155684cd", r.URL.Path)
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body: io.NopCloser(strings.NewReader(`
+{
+  "sha": "7638417db6d59f3c431d3e1f261cc637155684cd",
+  "html_url": "https://gitea.example.com/octocat/Hello-World/commit/7638417db6d59f3c431d3e1f261cc637155684cd",
+  "commit": {
+    "author": {
+      "name": "Monalisa Octocat",
+      "email": "octocat@example.com",
+      "date": "2014-11-07T22:01:45Z"
+    },
+    "committer": {
+      "name": "Monalisa Octocat",
+      "email": "octocat@example.com",
+      "date": "2014-11-07T22:01:45Z"
+    },
+    "message": "added readme"
+  }
+}
+`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	got, err := p.FetchCommitByID(ctx, common.OauthContext{}, giteaURL, "octocat/Hello-World", "7638417db6d59f3c431d3e1f261cc637155684cd")
+	require.NoError(t, err)
+
+	want := &vcs.Commit{
+		ID:          "7638417db6d59f3c431d3e1f261cc637155684cd",
+		AuthorName:  "Monalisa Octocat",
+		AuthorEmail: "octocat@example.com",
+		CreatedTs:   1415397705,
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestProvider_ExchangeOAuthToken(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, "/login/oauth/access_token", r.URL.Path)
+						assert.Equal(t, "client_id=test_client_id&client_secret=test_client_secret&code=test_code&grant_type=authorization_code&redirect_uri=http%3A%2F%2Flocalhost%3A3000", r.URL.RawQuery)
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body: io.NopCloser(strings.NewReader(`
+{
+  "access_token": "test_access_token",
+  "token_type": "bearer",
+  "expires_in": 3600,
+  "refresh_token": "test_refresh_token"
+}
+`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	got, err := p.ExchangeOAuthToken(
+		ctx,
+		giteaURL,
+		&common.OAuthExchange{
+			ClientID:     "test_client_id",
+			ClientSecret: "test_client_secret",
+			Code:         "test_code",
+			RedirectURL:  "http://localhost:3000",
+		},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "test_access_token", got.AccessToken)
+	assert.Equal(t, "test_refresh_token", got.RefreshToken)
+	assert.Equal(t, got.CreatedAt+3600, got.ExpiresTs)
+}
+
+func TestProvider_FetchAllRepositoryList(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, "/api/v1/user/repos", r.URL.Path)
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body: io.NopCloser(strings.NewReader(`
+[
+  {
+    "id": 1296269,
+    "name": "Hello-World",
+    "full_name": "octocat/Hello-World",
+    "html_url": "https://gitea.example.com/octocat/Hello-World",
+    "permissions": {"admin": true, "push": true, "pull": true}
+  },
+  {
+    "id": 1296270,
+    "name": "Read-Only",
+    "full_name": "octocat/Read-Only",
+    "html_url": "https://gitea.example.com/octocat/Read-Only",
+    "permissions": {"admin": false, "push": false, "pull": true}
+  }
+]
+`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	got, err := p.FetchAllRepositoryList(ctx, common.OauthContext{}, giteaURL)
+	require.NoError(t, err)
+
+	want := []*vcs.Repository{
+		{
+			ID:       1296269,
+			Name:     "Hello-World",
+			FullPath: "octocat/Hello-World",
+			WebURL:   "https://gitea.example.com/octocat/Hello-World",
+		},
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestProvider_FetchRepositoryFileList(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, "/api/v1/repos/octocat/Hello-World/git/trees/main", r.URL.Path)
+						body := `
+{
+  "sha": "9fb037999f264ba9a7fc6274d15fa3ae2ab98312",
+  "tree": [
+    {"path": "file.rb", "type": "blob", "sha": "44b4fc6d56897b048c772eb4087f854f46256132"},
+    {"path": "subdir", "type": "tree", "sha": "f484d249c660418515fb01c2b9662073663c242e"},
+    {"path": "subdir/file.sql", "type": "blob", "sha": "7c258a9869f33c1e1e1f74fbb32f07c86cb5a75b"}
+  ],
+  "truncated": true,
+  "page": 1,
+  "total_count": 4
+}
+`
+						if r.URL.Query().Get("page") == "2" {
+							body = `
+{
+  "sha": "9fb037999f264ba9a7fc6274d15fa3ae2ab98312",
+  "tree": [
+    {"path": "subdir/other.sql", "type": "blob", "sha": "8c258a9869f33c1e1e1f74fbb32f07c86cb5a75b"}
+  ],
+  "truncated": false,
+  "page": 2,
+  "total_count": 4
+}
+`
+						}
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body:       io.NopCloser(strings.NewReader(body)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	got, err := p.FetchRepositoryFileList(ctx, common.OauthContext{}, giteaURL, "octocat/Hello-World", "main", "subdir")
+	require.NoError(t, err)
+
+	want := []*vcs.RepositoryTreeNode{
+		{
+			Path: "subdir/file.sql",
+			Type: "blob",
+		},
+		{
+			Path: "subdir/other.sql",
+			Type: "blob",
+		},
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestProvider_CreateFile(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, http.MethodPost, r.Method)
+						assert.Equal(t, "/api/v1/repos/octocat/Hello-World/contents/notes/hello.txt", r.URL.Path)
+
+						body, err := io.ReadAll(r.Body)
+						require.NoError(t, err)
+						wantBody := `{"branch":"master","content":"bXkgbmV3IGZpbGUgY29udGVudHM=","message":"my commit message"}`
+						assert.Equal(t, wantBody, string(body))
+						return &http.Response{
+							StatusCode: http.StatusCreated,
+							Body:       io.NopCloser(strings.NewReader(`{}`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	err := p.CreateFile(
+		ctx,
+		common.OauthContext{},
+		giteaURL,
+		"octocat/Hello-World",
+		"notes/hello.txt",
+		vcs.FileCommitCreate{
+			Branch:        "master",
+			Content:       "my new file contents",
+			CommitMessage: "my commit message",
+		},
+	)
+	require.NoError(t, err)
+}
+
+func TestProvider_OverwriteFile(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, http.MethodPut, r.Method)
+						assert.Equal(t, "/api/v1/repos/octocat/Hello-World/contents/notes/hello.txt", r.URL.Path)
+
+						body, err := io.ReadAll(r.Body)
+						require.NoError(t, err)
+						wantBody := `{"branch":"master","content":"bXkgbmV3IGZpbGUgY29udGVudHM=","message":"update file","sha":"7638417db6d59f3c431d3e1f261cc637155684cd"}`
+						assert.Equal(t, wantBody, string(body))
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body:       io.NopCloser(strings.NewReader(`{}`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	err := p.OverwriteFile(
+		ctx,
+		common.OauthContext{},
+		giteaURL,
+		"octocat/Hello-World",
+		"notes/hello.txt",
+		vcs.FileCommitCreate{
+			Branch:        "master",
+			Content:       "my new file contents",
+			CommitMessage: "update file",
+			LastCommitID:  "7638417db6d59f3c431d3e1f261cc637155684cd",
+		},
+	)
+	require.NoError(t, err)
+}
+
+func TestProvider_ReadFileMeta(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, "/api/v1/repos/octocat/Hello-World/contents/README.md", r.URL.Path)
+						assert.Equal(t, "main", r.URL.Query().Get("ref"))
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body: io.NopCloser(strings.NewReader(`
+{
+  "name": "README.md",
+  "path": "README.md",
+  "sha": "3d21ec53a331a6f037a91c368710b99387d012c1",
+  "type": "file",
+  "size": 5362,
+  "encoding": "base64",
+  "content": "ZW5jb2RlZCBjb250ZW50IC4uLg=="
+}
+`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	got, err := p.ReadFileMeta(ctx, common.OauthContext{}, giteaURL, "octocat/Hello-World", "README.md", "main")
+	require.NoError(t, err)
+
+	want := &vcs.FileMeta{
+		Name:         "README.md",
+		Path:         "README.md",
+		Size:         5362,
+		LastCommitID: "3d21ec53a331a6f037a91c368710b99387d012c1",
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestProvider_ReadFileContent(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, "/api/v1/repos/octocat/Hello-World/contents/README.md", r.URL.Path)
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body: io.NopCloser(strings.NewReader(`
+{
+  "name": "README.md",
+  "path": "README.md",
+  "sha": "3d21ec53a331a6f037a91c368710b99387d012c1",
+  "type": "file",
+  "size": 19,
+  "encoding": "base64",
+  "content": "ZW5jb2RlZCBjb250ZW50IC4uLg=="
+}
+`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	got, err := p.ReadFileContent(ctx, common.OauthContext{}, giteaURL, "octocat/Hello-World", "README.md", "main")
+	require.NoError(t, err)
+	assert.Equal(t, "encoded content ...", got)
+}
+
+func TestProvider_CreateWebhook(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, "/api/v1/repos/octocat/Hello-World/hooks", r.URL.Path)
+						return &http.Response{
+							StatusCode: http.StatusCreated,
+							Body: io.NopCloser(strings.NewReader(`
+{
+  "id": 12345678,
+  "type": "gitea",
+  "active": true,
+  "events": ["push"],
+  "config": {
+    "content_type": "json",
+    "url": "https://example.com/webhook"
+  },
+  "updated_at": "2019-06-03T00:57:16Z",
+  "created_at": "2019-06-03T00:57:16Z"
+}
+`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	got, err := p.CreateWebhook(ctx, common.OauthContext{}, giteaURL, "octocat/Hello-World", []byte(""))
+	require.NoError(t, err)
+	assert.Equal(t, "12345678", got)
+}
+
+func TestProvider_PatchWebhook(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, http.MethodPatch, r.Method)
+						assert.Equal(t, "/api/v1/repos/octocat/Hello-World/hooks/1", r.URL.Path)
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body:       io.NopCloser(strings.NewReader("")),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	err := p.PatchWebhook(ctx, common.OauthContext{}, giteaURL, "octocat/Hello-World", "1", []byte(""))
+	require.NoError(t, err)
+}
+
+func TestProvider_DeleteWebhook(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, http.MethodDelete, r.Method)
+						assert.Equal(t, "/api/v1/repos/octocat/Hello-World/hooks/1", r.URL.Path)
+						return &http.Response{
+							StatusCode: http.StatusNotFound,
+							Body:       io.NopCloser(strings.NewReader("")),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	err := p.DeleteWebhook(ctx, common.OauthContext{}, giteaURL, "octocat/Hello-World", "1")
+	require.NoError(t, err)
+}
+
+func TestOAuth_RefreshToken(t *testing.T) {
+	ctx := context.Background()
+	client := &http.Client{
+		Transport: &common.MockRoundTripper{
+			MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+				if r.URL.Path == "/login/oauth/access_token" {
+					body, err := io.ReadAll(r.Body)
+					require.NoError(t, err)
+					assert.Contains(t, string(body), `"grant_type":"refresh_token"`)
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body: io.NopCloser(strings.NewReader(`
+{
+  "access_token": "refreshed_access_token",
+  "token_type": "bearer",
+  "expires_in": 3600,
+  "refresh_token": "refreshed_refresh_token"
+}
+`)),
+					}, nil
+				}
+
+				token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+				if token == "expired" {
+					return &http.Response{
+						StatusCode: http.StatusUnauthorized,
+						Body: io.NopCloser(strings.NewReader(`
+					{"error":"invalid_token","error_description":"Token is expired. You can either do re-authorization or token refresh."}
+					`)),
+					}, nil
+				}
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader(`{}`)),
+				}, nil
+			},
+		},
+	}
+	token := "expired"
+
+	calledRefresher := false
+	refresher := func(accessToken, refreshToken string, expiresTs int64) error {
+		calledRefresher = true
+		assert.Equal(t, "refreshed_access_token", accessToken)
+		assert.Equal(t, "refreshed_refresh_token", refreshToken)
+		assert.NotZero(t, expiresTs)
+		return nil
+	}
+
+	_, _, _, err := oauth.Get(
+		ctx,
+		client,
+		giteaURL+"/api/v1/users/octocat",
+		&token,
+		tokenRefresher(
+			giteaURL,
+			oauthContext{},
+			refresher,
+		),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "refreshed_access_token", token)
+	assert.True(t, calledRefresher)
+}
+
+func TestProvider_GetBranch(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, "/api/v1/repos/octocat/Hello-World/branches/main", r.URL.Path)
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body: io.NopCloser(strings.NewReader(`
+{
+  "name": "main",
+  "commit": {
+    "id": "7fd1a60b01f91b314f59955a4e4d4e80d8edf11d",
+    "message": "Merge pull request #6"
+  },
+  "protected": false
+}
+`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	got, err := p.GetBranch(ctx, common.OauthContext{}, giteaURL, "octocat/Hello-World", "main")
+	require.NoError(t, err)
+
+	want := &vcs.BranchInfo{
+		Name:         "main",
+		LastCommitID: "7fd1a60b01f91b314f59955a4e4d4e80d8edf11d",
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestProvider_CreateBranch(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, "/api/v1/repos/octocat/Hello-World/branches", r.URL.Path)
+
+						body, err := io.ReadAll(r.Body)
+						require.NoError(t, err)
+						wantBody := `{"new_branch_name":"bytebase-sql-review","old_ref_name":"7fd1a60b01f91b314f59955a4e4d4e80d8edf11d"}`
+						assert.Equal(t, wantBody, string(body))
+						return &http.Response{
+							StatusCode: http.StatusCreated,
+							Body:       io.NopCloser(strings.NewReader(`{}`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	err := p.CreateBranch(ctx, common.OauthContext{}, giteaURL, "octocat/Hello-World", &vcs.BranchInfo{
+		Name:         "bytebase-sql-review",
+		LastCommitID: "7fd1a60b01f91b314f59955a4e4d4e80d8edf11d",
+	})
+	require.NoError(t, err)
+}
+
+func TestProvider_CreatePullRequest(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, "/api/v1/repos/octocat/Hello-World/pulls", r.URL.Path)
+						return &http.Response{
+							StatusCode: http.StatusCreated,
+							Body: io.NopCloser(strings.NewReader(`
+{
+  "id": 1,
+  "number": 1347,
+  "html_url": "https://gitea.example.com/octocat/Hello-World/pulls/1347",
+  "state": "open",
+  "title": "Amazing new feature",
+  "head": {"ref": "new-topic", "sha": "6dcb09b5b57875f334f61aebed695e2e4193db5e"},
+  "base": {"ref": "master", "sha": "6dcb09b5b57875f334f61aebed695e2e4193db5e"}
+}
+`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	got, err := p.CreatePullRequest(ctx, common.OauthContext{}, giteaURL, "octocat/Hello-World", &vcs.PullRequestCreate{
+		Title: "Amazing new feature",
+		Head:  "new-topic",
+		Base:  "master",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "https://gitea.example.com/octocat/Hello-World/pulls/1347", got.URL)
+}
+
+func TestProvider_UpsertEnvironmentVariable(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, http.MethodPut, r.Method)
+						assert.Equal(t, "/api/v1/repos/octocat/Hello-World/actions/secrets/SQL_REVIEW_API_SECRET", r.URL.Path)
+
+						body, err := io.ReadAll(r.Body)
+						require.NoError(t, err)
+						assert.Equal(t, `{"data":"secret"}`, string(body))
+						return &http.Response{
+							StatusCode: http.StatusNoContent,
+							Body:       io.NopCloser(strings.NewReader("")),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	err := p.UpsertEnvironmentVariable(ctx, common.OauthContext{}, giteaURL, "octocat/Hello-World", vcs.SQLReviewAPISecretName, "secret")
+	require.NoError(t, err)
+}
+
+func TestProvider_ListPullRequestFile(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						var body string
+						switch r.URL.Path {
+						case "/api/v1/repos/octocat/Hello-World/pulls/1":
+							body = `
+{
+  "number": 1,
+  "html_url": "https://gitea.example.com/octocat/Hello-World/pulls/1",
+  "head": {"ref": "feature", "sha": "6dcb09b5b57875f334f61aebed695e2e4193db5e"}
+}
+`
+						case "/api/v1/repos/octocat/Hello-World/pulls/1/files":
+							body = `
+[
+  {"filename": "migration/1.0__init.sql", "status": "added", "additions": 3, "deletions": 0, "changes": 3},
+  {"filename": "migration/0.9__legacy.sql", "status": "deleted", "additions": 0, "deletions": 5, "changes": 5}
+]
+`
+						default:
+							t.Fatalf("unexpected request path %q", r.URL.Path)
+						}
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body:       io.NopCloser(strings.NewReader(body)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	got, err := p.ListPullRequestFile(ctx, common.OauthContext{}, giteaURL, "octocat/Hello-World", "1")
+	require.NoError(t, err)
+
+	want := []*vcs.PullRequestFile{
+		{
+			Path:         "migration/1.0__init.sql",
+			LastCommitID: "6dcb09b5b57875f334f61aebed695e2e4193db5e",
+			IsDeleted:    false,
+		},
+		{
+			Path:         "migration/0.9__legacy.sql",
+			LastCommitID: "6dcb09b5b57875f334f61aebed695e2e4193db5e",
+			IsDeleted:    true,
+		},
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestWebhookPushEvent_ToVCS(t *testing.T) {
+	event := WebhookPushEvent{
+		Ref: "refs/heads/main",
+		Repository: WebhookRepository{
+			ID:       1,
+			FullName: "octocat/Hello-World",
+			HTMLURL:  "https://gitea.example.com/octocat/Hello-World",
+		},
+		Sender: WebhookSender{
+			Login: "octocat",
+		},
+		Commits: []WebhookCommit{
+			{
+				ID:      "6dcb09b5b57875f334f61aebed695e2e4193db5e",
+				Message: "Add migration\n\nCreate the user table.",
+				URL:     "https://gitea.example.com/octocat/Hello-World/commit/6dcb09b5b57875f334f61aebed695e2e4193db5e",
+				Author: WebhookCommitAuthor{
+					Name:  "Monalisa Octocat",
+					Email: "octocat@example.com",
+				},
+				Added:    []string{"migration/1.0__init.sql"},
+				Modified: []string{},
+			},
+		},
+	}
+
+	got := event.ToVCS()
+	assert.Equal(t, "octocat/Hello-World", got.RepositoryID)
+	assert.Equal(t, "octocat/Hello-World", got.RepositoryFullPath)
+	assert.Equal(t, "octocat", got.AuthorName)
+	require.Len(t, got.CommitList, 1)
+	assert.Equal(t, "Add migration", got.CommitList[0].Title)
+	assert.Equal(t, []string{"migration/1.0__init.sql"}, got.CommitList[0].AddedList)
+}
+
+func TestProvider_CreatePullRequestComment(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, http.MethodPost, r.Method)
+						assert.Equal(t, "/api/v1/repos/octocat/Hello-World/issues/1/comments", r.URL.Path)
+
+						body, err := io.ReadAll(r.Body)
+						require.NoError(t, err)
+						assert.Equal(t, `{"body":"All task checks passed."}`, string(body))
+						return &http.Response{
+							StatusCode: http.StatusCreated,
+							Body:       io.NopCloser(strings.NewReader(`{"id": 1, "body": "All task checks passed."}`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	err := p.CreatePullRequestComment(ctx, common.OauthContext{}, giteaURL, "octocat/Hello-World", "1", "All task checks passed.")
+	require.NoError(t, err)
+}
+
+func TestProvider_CreatePullRequestReview(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, http.MethodPost, r.Method)
+						assert.Equal(t, "/api/v1/repos/octocat/Hello-World/pulls/1/reviews", r.URL.Path)
+
+						body, err := io.ReadAll(r.Body)
+						require.NoError(t, err)
+						wantBody := `{"commit_id":"6dcb09b5b57875f334f61aebed695e2e4193db5e","body":"Task checks found problems.","event":"COMMENT","comments":[{"path":"bytebase/db__ver1__migrate__create_t.sql","new_position":2,"body":"**WARN** Require primary key"}]}`
+						assert.Equal(t, wantBody, string(body))
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body:       io.NopCloser(strings.NewReader(`{"id": 80, "body": "Task checks found problems.", "state": "COMMENTED"}`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	err := p.CreatePullRequestReview(ctx, common.OauthContext{}, giteaURL, "octocat/Hello-World", "1", &vcs.PullRequestReview{
+		CommitID: "6dcb09b5b57875f334f61aebed695e2e4193db5e",
+		Body:     "Task checks found problems.",
+		CommentList: []*vcs.PullRequestReviewComment{
+			{Path: "bytebase/db__ver1__migrate__create_t.sql", Line: 2, Body: "**WARN** Require primary key"},
+		},
+	})
+	require.NoError(t, err)
+}
+
+func TestProvider_SetCommitStatus(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, http.MethodPost, r.Method)
+						assert.Equal(t, "/api/v1/repos/octocat/Hello-World/statuses/6dcb09b5b57875f334f61aebed695e2e4193db5e", r.URL.Path)
+
+						body, err := io.ReadAll(r.Body)
+						require.NoError(t, err)
+						wantBody := `{"state":"success","target_url":"https://bytebase.example.com/issue/1","description":"All task checks passed","context":"bytebase/task-check"}`
+						assert.Equal(t, wantBody, string(body))
+						return &http.Response{
+							StatusCode: http.StatusCreated,
+							Body:       io.NopCloser(strings.NewReader(`{"id": 1, "status": "success", "context": "bytebase/task-check"}`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	err := p.SetCommitStatus(
+		ctx,
+		common.OauthContext{},
+		giteaURL,
+		"octocat/Hello-World",
+		"6dcb09b5b57875f334f61aebed695e2e4193db5e",
+		&vcs.CommitStatus{
+			State:       vcs.CommitStatusSuccess,
+			Context:     "bytebase/task-check",
+			Description: "All task checks passed",
+			TargetURL:   "https://bytebase.example.com/issue/1",
+		},
+	)
+	require.NoError(t, err)
+}
+
+func TestWebhookPullRequestEvent_ToVCS(t *testing.T) {
+	event := WebhookPullRequestEvent{
+		Action: WebhookPullRequestClosed,
+		Number: 1,
+		PullRequest: WebhookPullRequestDetail{
+			HTMLURL: "https://gitea.example.com/octocat/Hello-World/pulls/1",
+			Title:   "Add migration",
+			Merged:  true,
+			Head:    giteaPullRequestBranch{Ref: "feature", SHA: "6dcb09b5b57875f334f61aebed695e2e4193db5e"},
+			Base:    giteaPullRequestBranch{Ref: "main"},
+		},
+		Repository: WebhookRepository{
+			ID:       1,
+			FullName: "octocat/Hello-World",
+			HTMLURL:  "https://gitea.example.com/octocat/Hello-World",
+		},
+		Sender: WebhookSender{
+			Login: "octocat",
+		},
+	}
+
+	got, ok := event.ToVCS()
+	require.True(t, ok)
+	assert.Equal(t, vcs.PullRequestMerged, got.Action)
+	assert.Equal(t, "1", got.ID)
+	assert.Equal(t, "refs/heads/feature", got.HeadRef)
+	assert.Equal(t, "6dcb09b5b57875f334f61aebed695e2e4193db5e", got.HeadCommitID)
+	assert.Equal(t, "main", got.BaseBranch)
+
+	event.Action = "assigned"
+	_, ok = event.ToVCS()
+	assert.False(t, ok)
+}
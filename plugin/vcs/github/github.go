@@ -131,6 +131,8 @@ const (
 	WebhookPush WebhookType = "push"
 	// WebhookPing is the webhook type for ping.
 	WebhookPing WebhookType = "ping"
+	// WebhookPullRequest is the webhook type for pull request.
+	WebhookPullRequest WebhookType = "pull_request"
 )
 
 // WebhookInfo represents a GitHub API response for the webhook information.
@@ -208,6 +210,44 @@ type WebhookPushEvent struct {
 	Commits    []WebhookCommit   `json:"commits"`
 }
 
+// WebhookPullRequestAction is the action of the pull request event.
+type WebhookPullRequestAction string
+
+const (
+	// WebhookPullRequestOpened is the action when the pull request is opened.
+	WebhookPullRequestOpened WebhookPullRequestAction = "opened"
+	// WebhookPullRequestReopened is the action when the pull request is reopened.
+	WebhookPullRequestReopened WebhookPullRequestAction = "reopened"
+	// WebhookPullRequestSynchronize is the action when new commits are pushed to the head branch of the pull request.
+	WebhookPullRequestSynchronize WebhookPullRequestAction = "synchronize"
+	// WebhookPullRequestClosed is the action when the pull request is closed, the pull request is merged if the "merged" is true.
+	WebhookPullRequestClosed WebhookPullRequestAction = "closed"
+)
+
+// WebhookPullRequestBranch is the API message for the head or base branch of the webhook pull request.
+type WebhookPullRequestBranch struct {
+	Ref string `json:"ref"`
+	SHA string `json:"sha"`
+}
+
+// WebhookPullRequestDetail is the API message for webhook pull request.
+type WebhookPullRequestDetail struct {
+	HTMLURL string                   `json:"html_url"`
+	Title   string                   `json:"title"`
+	Merged  bool                     `json:"merged"`
+	Head    WebhookPullRequestBranch `json:"head"`
+	Base    WebhookPullRequestBranch `json:"base"`
+}
+
+// WebhookPullRequestEvent is the API message for webhook pull request event.
+type WebhookPullRequestEvent struct {
+	Action      WebhookPullRequestAction `json:"action"`
+	Number      int                      `json:"number"`
+	PullRequest WebhookPullRequestDetail `json:"pull_request"`
+	Repository  WebhookRepository        `json:"repository"`
+	Sender      WebhookSender            `json:"sender"`
+}
+
 // fetchUserInfoImpl fetches user information from the given resourceURI, which
 // should be either "user" or "users/{username}".
 func (p *Provider) fetchUserInfoImpl(ctx context.Context, oauthCtx common.OauthContext, instanceURL, resourceURI string) (*vcs.UserInfo, error) {
@@ -1054,6 +1094,199 @@ func (p *Provider) CreatePullRequest(ctx context.Context, oauthCtx common.OauthC
 	}, nil
 }
 
+type githubIssueCommentCreate struct {
+	Body string `json:"body"`
+}
+
+// CreatePullRequestComment creates a comment in the pull request.
+//
+// Docs: https://docs.github.com/en/rest/issues/comments#create-an-issue-comment
+func (p *Provider) CreatePullRequestComment(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, pullRequestID, comment string) error {
+	body, err := json.Marshal(
+		githubIssueCommentCreate{
+			Body: comment,
+		},
+	)
+	if err != nil {
+		return errors.Wrap(err, "marshal pull request comment create")
+	}
+
+	// Every pull request is an issue in GitHub, and the conversation comments
+	// of a pull request are created via the issue comment API.
+	url := fmt.Sprintf("%s/repos/%s/issues/%s/comments", p.APIURL(instanceURL), repositoryID, pullRequestID)
+	code, _, resp, err := oauth.Post(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		bytes.NewReader(body),
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "POST %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return common.Errorf(common.NotFound, "failed to create pull request comment from URL %s", url)
+	} else if code >= 300 {
+		return errors.Errorf("failed to create pull request comment from URL %s, status code: %d, body: %s",
+			url,
+			code,
+			resp,
+		)
+	}
+	return nil
+}
+
+type githubPullRequestReviewCreate struct {
+	CommitID string                                 `json:"commit_id"`
+	Body     string                                 `json:"body"`
+	Event    string                                 `json:"event"`
+	Comments []githubPullRequestReviewCommentCreate `json:"comments,omitempty"`
+}
+
+type githubPullRequestReviewCommentCreate struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Side string `json:"side"`
+	Body string `json:"body"`
+}
+
+// CreatePullRequestReview creates a review with the line comments in the pull request.
+//
+// Docs: https://docs.github.com/en/rest/pulls/reviews#create-a-review-for-a-pull-request
+func (p *Provider) CreatePullRequestReview(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, pullRequestID string, review *vcs.PullRequestReview) error {
+	reviewCreate := githubPullRequestReviewCreate{
+		CommitID: review.CommitID,
+		Body:     review.Body,
+		// The review only comments, and neither approves nor requests changes.
+		Event: "COMMENT",
+	}
+	for _, comment := range review.CommentList {
+		reviewCreate.Comments = append(reviewCreate.Comments, githubPullRequestReviewCommentCreate{
+			Path: comment.Path,
+			Line: comment.Line,
+			// The right side of the diff is the new version of the file.
+			Side: "RIGHT",
+			Body: comment.Body,
+		})
+	}
+	body, err := json.Marshal(reviewCreate)
+	if err != nil {
+		return errors.Wrap(err, "marshal pull request review create")
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/pulls/%s/reviews", p.APIURL(instanceURL), repositoryID, pullRequestID)
+	code, _, resp, err := oauth.Post(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		bytes.NewReader(body),
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "POST %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return common.Errorf(common.NotFound, "failed to create pull request review from URL %s", url)
+	} else if code >= 300 {
+		return errors.Errorf("failed to create pull request review from URL %s, status code: %d, body: %s",
+			url,
+			code,
+			resp,
+		)
+	}
+	return nil
+}
+
+type githubCommitStatusCreate struct {
+	State       string `json:"state"`
+	TargetURL   string `json:"target_url"`
+	Description string `json:"description"`
+	Context     string `json:"context"`
+}
+
+// GitHub does not have a running state for the commit status, thus we use
+// pending for both pending and running.
+var commitStatusStates = map[vcs.CommitStatusState]string{
+	vcs.CommitStatusPending:  "pending",
+	vcs.CommitStatusRunning:  "pending",
+	vcs.CommitStatusSuccess:  "success",
+	vcs.CommitStatusFailure:  "failure",
+	vcs.CommitStatusCanceled: "error",
+}
+
+// SetCommitStatus creates or updates the status of the commit.
+//
+// Docs: https://docs.github.com/en/rest/commits/statuses#create-a-commit-status
+func (p *Provider) SetCommitStatus(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, commitID string, status *vcs.CommitStatus) error {
+	state, ok := commitStatusStates[status.State]
+	if !ok {
+		return errors.Errorf("unsupported commit status state %q", status.State)
+	}
+	body, err := json.Marshal(
+		githubCommitStatusCreate{
+			State:       state,
+			TargetURL:   status.TargetURL,
+			Description: status.Description,
+			Context:     status.Context,
+		},
+	)
+	if err != nil {
+		return errors.Wrap(err, "marshal commit status create")
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/statuses/%s", p.APIURL(instanceURL), repositoryID, commitID)
+	code, _, resp, err := oauth.Post(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		bytes.NewReader(body),
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "POST %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return common.Errorf(common.NotFound, "failed to set commit status from URL %s", url)
+	} else if code >= 300 {
+		return errors.Errorf("failed to set commit status from URL %s, status code: %d, body: %s",
+			url,
+			code,
+			resp,
+		)
+	}
+	return nil
+}
+
 type environmentVariable struct {
 	EncryptedValue string `json:"encrypted_value"`
 	KeyID          string `json:"key_id"`
@@ -1429,3 +1662,35 @@ func (p WebhookPushEvent) ToVCS() vcs.PushEvent {
 		CommitList:         commitList,
 	}
 }
+
+// ToVCS returns the pull request event in VCS format. It returns false if the
+// action of the event is irrelevant, e.g. the pull request is labeled.
+func (p WebhookPullRequestEvent) ToVCS() (vcs.PullRequestEvent, bool) {
+	var action vcs.PullRequestAction
+	switch p.Action {
+	case WebhookPullRequestOpened, WebhookPullRequestReopened:
+		action = vcs.PullRequestOpened
+	case WebhookPullRequestSynchronize:
+		action = vcs.PullRequestUpdated
+	case WebhookPullRequestClosed:
+		action = vcs.PullRequestClosed
+		if p.PullRequest.Merged {
+			action = vcs.PullRequestMerged
+		}
+	default:
+		return vcs.PullRequestEvent{}, false
+	}
+	return vcs.PullRequestEvent{
+		Action:             action,
+		ID:                 strconv.Itoa(p.Number),
+		URL:                p.PullRequest.HTMLURL,
+		Title:              p.PullRequest.Title,
+		HeadRef:            fmt.Sprintf("refs/heads/%s", p.PullRequest.Head.Ref),
+		HeadCommitID:       p.PullRequest.Head.SHA,
+		BaseBranch:         p.PullRequest.Base.Ref,
+		RepositoryID:       p.Repository.FullName,
+		RepositoryURL:      p.Repository.HTMLURL,
+		RepositoryFullPath: p.Repository.FullName,
+		AuthorName:         p.Sender.Login,
+	}, true
+}
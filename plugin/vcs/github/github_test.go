@@ -1433,3 +1433,134 @@ func TestProvider_ListPullRequestFile(t *testing.T) {
 	}
 	assert.Equal(t, want, got)
 }
+
+func TestProvider_CreatePullRequestComment(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, "POST", r.Method)
+						assert.Equal(t, "/repos/octocat/Hello-World/issues/1347/comments", r.URL.Path)
+
+						body, err := io.ReadAll(r.Body)
+						require.NoError(t, err)
+						assert.Equal(t, `{"body":"Me too"}`, string(body))
+						return &http.Response{
+							StatusCode: http.StatusCreated,
+							// Example response taken from https://docs.github.com/en/rest/issues/comments#create-an-issue-comment
+							Body: io.NopCloser(strings.NewReader(`
+{
+  "id": 1,
+  "node_id": "MDEyOklzc3VlQ29tbWVudDE=",
+  "url": "https://api.github.com/repos/octocat/Hello-World/issues/comments/1",
+  "html_url": "https://github.com/octocat/Hello-World/issues/1347#issuecomment-1",
+  "body": "Me too",
+  "user": {
+    "login": "octocat",
+    "id": 1
+  },
+  "created_at": "2011-04-14T16:00:49Z",
+  "updated_at": "2011-04-14T16:00:49Z",
+  "issue_url": "https://api.github.com/repos/octocat/Hello-World/issues/1347",
+  "author_association": "COLLABORATOR"
+}
+`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	err := p.CreatePullRequestComment(ctx, common.OauthContext{}, githubComURL, "octocat/Hello-World", "1347", "Me too")
+	require.NoError(t, err)
+}
+
+func TestProvider_CreatePullRequestReview(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, http.MethodPost, r.Method)
+						assert.Equal(t, "/repos/octocat/Hello-World/pulls/1347/reviews", r.URL.Path)
+
+						body, err := io.ReadAll(r.Body)
+						require.NoError(t, err)
+						wantBody := `{"commit_id":"6dcb09b5b57875f334f61aebed695e2e4193db5e","body":"Task checks found problems.","event":"COMMENT","comments":[{"path":"bytebase/db__ver1__migrate__create_t.sql","line":2,"side":"RIGHT","body":"**WARN** Require primary key"}]}`
+						assert.Equal(t, wantBody, string(body))
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body:       io.NopCloser(strings.NewReader(`{"id": 80, "body": "Task checks found problems.", "state": "COMMENTED"}`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	err := p.CreatePullRequestReview(ctx, common.OauthContext{}, githubComURL, "octocat/Hello-World", "1347", &vcs.PullRequestReview{
+		CommitID: "6dcb09b5b57875f334f61aebed695e2e4193db5e",
+		Body:     "Task checks found problems.",
+		CommentList: []*vcs.PullRequestReviewComment{
+			{Path: "bytebase/db__ver1__migrate__create_t.sql", Line: 2, Body: "**WARN** Require primary key"},
+		},
+	})
+	require.NoError(t, err)
+}
+
+func TestProvider_SetCommitStatus(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, "POST", r.Method)
+						assert.Equal(t, "/repos/octocat/Hello-World/statuses/6dcb09b5b57875f334f61aebed695e2e4193db5e", r.URL.Path)
+
+						body, err := io.ReadAll(r.Body)
+						require.NoError(t, err)
+						wantBody := `{"state":"pending","target_url":"https://bytebase.example.com/issue/1","description":"Task checks are running","context":"bytebase/task-check"}`
+						assert.Equal(t, wantBody, string(body))
+						return &http.Response{
+							StatusCode: http.StatusCreated,
+							// Example response taken from https://docs.github.com/en/rest/commits/statuses#create-a-commit-status
+							Body: io.NopCloser(strings.NewReader(`
+{
+  "url": "https://api.github.com/repos/octocat/Hello-World/statuses/6dcb09b5b57875f334f61aebed695e2e4193db5e",
+  "id": 1,
+  "node_id": "MDY6U3RhdHVzMQ==",
+  "state": "pending",
+  "description": "Task checks are running",
+  "target_url": "https://bytebase.example.com/issue/1",
+  "context": "bytebase/task-check",
+  "created_at": "2012-07-20T01:19:13Z",
+  "updated_at": "2012-07-20T01:19:13Z"
+}
+`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	err := p.SetCommitStatus(
+		ctx,
+		common.OauthContext{},
+		githubComURL,
+		"octocat/Hello-World",
+		"6dcb09b5b57875f334f61aebed695e2e4193db5e",
+		&vcs.CommitStatus{
+			State:       vcs.CommitStatusRunning,
+			Context:     "bytebase/task-check",
+			Description: "Task checks are running",
+			TargetURL:   "https://bytebase.example.com/issue/1",
+		},
+	)
+	require.NoError(t, err)
+}
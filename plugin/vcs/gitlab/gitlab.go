@@ -38,6 +38,8 @@ type WebhookType string
 const (
 	// WebhookPush is the webhook type for push.
 	WebhookPush WebhookType = "push"
+	// WebhookMergeRequest is the webhook type for merge request.
+	WebhookMergeRequest WebhookType = "merge_request"
 )
 
 // WebhookInfo represents a GitLab API response for the webhook information.
@@ -51,12 +53,9 @@ type WebhookCreate struct {
 	SecretToken string `json:"token"`
 	// This is set to true
 	PushEvents bool `json:"push_events"`
-	// For now, there is no native dry run DDL support in mysql/postgres. One may wonder if we could wrap the DDL
-	// in a transaction and just not commit at the end, unfortunately there are side effects which are hard to control.
-	// See https://www.postgresql.org/message-id/CAMsr%2BYGiYQ7PYvYR2Voio37YdCpp79j5S%2BcmgVJMOLM2LnRQcA%40mail.gmail.com
-	// So we can't possibly display useful info when reviewing a MR, thus we don't enable this event.
-	// Saying that, delivering a souding dry run solution would be great and hopefully we can achieve that one day.
-	// MergeRequestsEvents  bool   `json:"merge_requests_events"`
+	// MergeRequestsEvents is set to true so that the repository with the pull
+	// request workflow enabled can create draft issues from merge requests.
+	MergeRequestsEvents   bool `json:"merge_requests_events"`
 	EnableSSLVerification bool `json:"enable_ssl_verification"`
 }
 
@@ -94,6 +93,52 @@ type WebhookPushEvent struct {
 	CommitList []WebhookCommit `json:"commits"`
 }
 
+// WebhookMergeRequestAction is the action of the merge request event.
+type WebhookMergeRequestAction string
+
+const (
+	// WebhookMergeRequestOpen is the action when the merge request is opened.
+	WebhookMergeRequestOpen WebhookMergeRequestAction = "open"
+	// WebhookMergeRequestReopen is the action when the merge request is reopened.
+	WebhookMergeRequestReopen WebhookMergeRequestAction = "reopen"
+	// WebhookMergeRequestUpdate is the action when the merge request is updated, e.g. new commits are pushed.
+	WebhookMergeRequestUpdate WebhookMergeRequestAction = "update"
+	// WebhookMergeRequestMerge is the action when the merge request is merged.
+	WebhookMergeRequestMerge WebhookMergeRequestAction = "merge"
+	// WebhookMergeRequestClose is the action when the merge request is closed.
+	WebhookMergeRequestClose WebhookMergeRequestAction = "close"
+)
+
+// WebhookUser is the API message for webhook user.
+type WebhookUser struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// WebhookMergeRequestLastCommit is the API message for the last commit of the merge request.
+type WebhookMergeRequestLastCommit struct {
+	ID string `json:"id"`
+}
+
+// WebhookMergeRequestAttributes is the API message for webhook merge request attributes.
+type WebhookMergeRequestAttributes struct {
+	IID          int                           `json:"iid"`
+	Title        string                        `json:"title"`
+	URL          string                        `json:"url"`
+	SourceBranch string                        `json:"source_branch"`
+	TargetBranch string                        `json:"target_branch"`
+	Action       WebhookMergeRequestAction     `json:"action"`
+	LastCommit   WebhookMergeRequestLastCommit `json:"last_commit"`
+}
+
+// WebhookMergeRequestEvent is the API message for webhook merge request event.
+type WebhookMergeRequestEvent struct {
+	ObjectKind       WebhookType                   `json:"object_kind"`
+	User             WebhookUser                   `json:"user"`
+	Project          WebhookProject                `json:"project"`
+	ObjectAttributes WebhookMergeRequestAttributes `json:"object_attributes"`
+}
+
 // Commit is the API message for commit.
 type Commit struct {
 	ID         string `json:"id"`
@@ -933,7 +978,14 @@ func (p *Provider) CreateBranch(ctx context.Context, oauthCtx common.OauthContex
 }
 
 type gitlabMergeRequest struct {
-	WebURL string `json:"web_url"`
+	WebURL   string                     `json:"web_url"`
+	DiffRefs gitlabMergeRequestDiffRefs `json:"diff_refs"`
+}
+
+type gitlabMergeRequestDiffRefs struct {
+	BaseSHA  string `json:"base_sha"`
+	HeadSHA  string `json:"head_sha"`
+	StartSHA string `json:"start_sha"`
 }
 
 // CreatePullRequest creates the pull request in the repository.
@@ -994,6 +1046,250 @@ func (p *Provider) CreatePullRequest(ctx context.Context, oauthCtx common.OauthC
 	}, nil
 }
 
+type gitlabNoteCreate struct {
+	Body string `json:"body"`
+}
+
+// CreatePullRequestComment creates a comment in the merge request.
+//
+// Docs: https://docs.gitlab.com/ee/api/notes.html#create-new-merge-request-note
+func (p *Provider) CreatePullRequestComment(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, pullRequestID, comment string) error {
+	body, err := json.Marshal(
+		gitlabNoteCreate{
+			Body: comment,
+		},
+	)
+	if err != nil {
+		return errors.Wrap(err, "marshal merge request note create")
+	}
+
+	url := fmt.Sprintf("%s/projects/%s/merge_requests/%s/notes", p.APIURL(instanceURL), repositoryID, pullRequestID)
+	code, _, resp, err := oauth.Post(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		bytes.NewReader(body),
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "POST %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return common.Errorf(common.NotFound, "failed to create merge request note from URL %s", url)
+	} else if code >= 300 {
+		return errors.Errorf("failed to create merge request note from URL %s, status code: %d, body: %s",
+			url,
+			code,
+			resp,
+		)
+	}
+	return nil
+}
+
+type gitlabDiscussionCreate struct {
+	Body     string                   `json:"body"`
+	Position gitlabDiscussionPosition `json:"position"`
+}
+
+type gitlabDiscussionPosition struct {
+	PositionType string `json:"position_type"`
+	BaseSHA      string `json:"base_sha"`
+	StartSHA     string `json:"start_sha"`
+	HeadSHA      string `json:"head_sha"`
+	NewPath      string `json:"new_path"`
+	NewLine      int    `json:"new_line"`
+}
+
+// CreatePullRequestReview creates a discussion for each line comment and a note for the review body in the merge request,
+// because GitLab doesn't create the review with the comments in a single request.
+//
+// Docs: https://docs.gitlab.com/ee/api/discussions.html#create-new-merge-request-thread
+func (p *Provider) CreatePullRequestReview(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, pullRequestID string, review *vcs.PullRequestReview) error {
+	if len(review.CommentList) > 0 {
+		diffRefs, err := p.getMergeRequestDiffRefs(ctx, oauthCtx, instanceURL, repositoryID, pullRequestID)
+		if err != nil {
+			return err
+		}
+		// The line numbers refer to the reviewed commit, which is outdated if new commits are pushed.
+		if diffRefs.HeadSHA != review.CommitID {
+			return errors.Errorf("the head commit of merge request %s is %s, not the reviewed commit %s", pullRequestID, diffRefs.HeadSHA, review.CommitID)
+		}
+		for _, comment := range review.CommentList {
+			if err := p.createMergeRequestDiscussion(ctx, oauthCtx, instanceURL, repositoryID, pullRequestID, &gitlabDiscussionCreate{
+				Body: comment.Body,
+				Position: gitlabDiscussionPosition{
+					PositionType: "text",
+					BaseSHA:      diffRefs.BaseSHA,
+					StartSHA:     diffRefs.StartSHA,
+					HeadSHA:      diffRefs.HeadSHA,
+					NewPath:      comment.Path,
+					NewLine:      comment.Line,
+				},
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return p.CreatePullRequestComment(ctx, oauthCtx, instanceURL, repositoryID, pullRequestID, review.Body)
+}
+
+// getMergeRequestDiffRefs gets the commits of the latest diff version of the merge request.
+//
+// Docs: https://docs.gitlab.com/ee/api/merge_requests.html#get-single-mr
+func (p *Provider) getMergeRequestDiffRefs(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, pullRequestID string) (*gitlabMergeRequestDiffRefs, error) {
+	url := fmt.Sprintf("%s/projects/%s/merge_requests/%s", p.APIURL(instanceURL), repositoryID, pullRequestID)
+	code, _, body, err := oauth.Get(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "GET %s", url)
+	}
+	if code == http.StatusNotFound {
+		return nil, common.Errorf(common.NotFound, "failed to get merge request from URL %s", url)
+	} else if code >= 300 {
+		return nil, errors.Errorf("failed to get merge request from URL %s, status code: %d, body: %s",
+			url,
+			code,
+			body,
+		)
+	}
+
+	var res gitlabMergeRequest
+	if err := json.Unmarshal([]byte(body), &res); err != nil {
+		return nil, err
+	}
+	return &res.DiffRefs, nil
+}
+
+func (p *Provider) createMergeRequestDiscussion(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, pullRequestID string, discussionCreate *gitlabDiscussionCreate) error {
+	body, err := json.Marshal(discussionCreate)
+	if err != nil {
+		return errors.Wrap(err, "marshal merge request discussion create")
+	}
+
+	url := fmt.Sprintf("%s/projects/%s/merge_requests/%s/discussions", p.APIURL(instanceURL), repositoryID, pullRequestID)
+	code, _, resp, err := oauth.Post(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		bytes.NewReader(body),
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "POST %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return common.Errorf(common.NotFound, "failed to create merge request discussion from URL %s", url)
+	} else if code >= 300 {
+		return errors.Errorf("failed to create merge request discussion from URL %s, status code: %d, body: %s",
+			url,
+			code,
+			resp,
+		)
+	}
+	return nil
+}
+
+type gitlabCommitStatusCreate struct {
+	State       string `json:"state"`
+	Name        string `json:"name"`
+	TargetURL   string `json:"target_url"`
+	Description string `json:"description"`
+}
+
+var commitStatusStates = map[vcs.CommitStatusState]string{
+	vcs.CommitStatusPending:  "pending",
+	vcs.CommitStatusRunning:  "running",
+	vcs.CommitStatusSuccess:  "success",
+	vcs.CommitStatusFailure:  "failed",
+	vcs.CommitStatusCanceled: "canceled",
+}
+
+// SetCommitStatus creates or updates the status of the commit.
+//
+// Docs: https://docs.gitlab.com/ee/api/commits.html#post-the-build-status-to-a-commit
+func (p *Provider) SetCommitStatus(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, commitID string, status *vcs.CommitStatus) error {
+	state, ok := commitStatusStates[status.State]
+	if !ok {
+		return errors.Errorf("unsupported commit status state %q", status.State)
+	}
+	body, err := json.Marshal(
+		gitlabCommitStatusCreate{
+			State:       state,
+			Name:        status.Context,
+			TargetURL:   status.TargetURL,
+			Description: status.Description,
+		},
+	)
+	if err != nil {
+		return errors.Wrap(err, "marshal commit status create")
+	}
+
+	url := fmt.Sprintf("%s/projects/%s/statuses/%s", p.APIURL(instanceURL), repositoryID, commitID)
+	code, _, resp, err := oauth.Post(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		bytes.NewReader(body),
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "POST %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return common.Errorf(common.NotFound, "failed to set commit status from URL %s", url)
+	} else if code >= 300 {
+		return errors.Errorf("failed to set commit status from URL %s, status code: %d, body: %s",
+			url,
+			code,
+			resp,
+		)
+	}
+	return nil
+}
+
 type environmentVariable struct {
 	Key   string `json:"key"`
 	Value string `json:"value"`
@@ -1407,3 +1703,35 @@ func (p WebhookPushEvent) ToVCS() (vcs.PushEvent, error) {
 		CommitList:         commitList,
 	}, nil
 }
+
+// ToVCS returns the merge request event in VCS format. It returns false if the
+// action of the event is irrelevant, e.g. the merge request is approved.
+func (p WebhookMergeRequestEvent) ToVCS() (vcs.PullRequestEvent, bool) {
+	var action vcs.PullRequestAction
+	switch p.ObjectAttributes.Action {
+	case WebhookMergeRequestOpen, WebhookMergeRequestReopen:
+		action = vcs.PullRequestOpened
+	case WebhookMergeRequestUpdate:
+		action = vcs.PullRequestUpdated
+	case WebhookMergeRequestMerge:
+		action = vcs.PullRequestMerged
+	case WebhookMergeRequestClose:
+		action = vcs.PullRequestClosed
+	default:
+		return vcs.PullRequestEvent{}, false
+	}
+	return vcs.PullRequestEvent{
+		Action:             action,
+		ID:                 fmt.Sprintf("%d", p.ObjectAttributes.IID),
+		URL:                p.ObjectAttributes.URL,
+		Title:              p.ObjectAttributes.Title,
+		HeadRef:            fmt.Sprintf("refs/heads/%s", p.ObjectAttributes.SourceBranch),
+		HeadCommitID:       p.ObjectAttributes.LastCommit.ID,
+		BaseBranch:         p.ObjectAttributes.TargetBranch,
+		RepositoryID:       fmt.Sprintf("%v", p.Project.ID),
+		RepositoryURL:      p.Project.WebURL,
+		RepositoryFullPath: p.Project.FullPath,
+		AuthorName:         p.User.Name,
+		AuthorEmail:        p.User.Email,
+	}, true
+}
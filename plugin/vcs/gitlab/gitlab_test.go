@@ -2,6 +2,7 @@ package gitlab
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"net/http"
 	"strings"
@@ -1156,3 +1157,176 @@ func TestProvider_ListPullRequestFile(t *testing.T) {
 	}
 	assert.Equal(t, want, got)
 }
+
+func TestProvider_CreatePullRequestComment(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, http.MethodPost, r.Method)
+						assert.Equal(t, "/api/v4/projects/1/merge_requests/2/notes", r.URL.Path)
+
+						body, err := io.ReadAll(r.Body)
+						require.NoError(t, err)
+						assert.Equal(t, `{"body":"All task checks passed."}`, string(body))
+						return &http.Response{
+							StatusCode: http.StatusCreated,
+							// Example response taken from https://docs.gitlab.com/ee/api/notes.html#create-new-merge-request-note
+							Body: io.NopCloser(strings.NewReader(`
+{
+  "id": 302,
+  "body": "All task checks passed.",
+  "attachment": null,
+  "author": {
+    "id": 1,
+    "username": "pipin",
+    "email": "admin@example.com",
+    "name": "Pip",
+    "state": "active",
+    "created_at": "2013-09-30T13:46:01Z"
+  },
+  "created_at": "2013-10-02T09:22:45Z",
+  "updated_at": "2013-10-02T10:22:45Z",
+  "system": false,
+  "noteable_id": 377,
+  "noteable_type": "MergeRequest",
+  "noteable_iid": 2
+}
+`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	err := p.CreatePullRequestComment(ctx, common.OauthContext{}, "", "1", "2", "All task checks passed.")
+	require.NoError(t, err)
+}
+
+func TestProvider_CreatePullRequestReview(t *testing.T) {
+	var requestList []string
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						requestList = append(requestList, fmt.Sprintf("%s %s", r.Method, r.URL.Path))
+						switch r.URL.Path {
+						case "/api/v4/projects/1/merge_requests/2":
+							return &http.Response{
+								StatusCode: http.StatusOK,
+								Body:       io.NopCloser(strings.NewReader(`{"iid": 2, "diff_refs": {"base_sha": "c380d3acebd181f13629a25d2e2acca46ffe1e00", "head_sha": "6dcb09b5b57875f334f61aebed695e2e4193db5e", "start_sha": "c380d3acebd181f13629a25d2e2acca46ffe1e00"}}`)),
+							}, nil
+						case "/api/v4/projects/1/merge_requests/2/discussions":
+							body, err := io.ReadAll(r.Body)
+							require.NoError(t, err)
+							wantBody := `{"body":"**WARN** Require primary key","position":{"position_type":"text","base_sha":"c380d3acebd181f13629a25d2e2acca46ffe1e00","start_sha":"c380d3acebd181f13629a25d2e2acca46ffe1e00","head_sha":"6dcb09b5b57875f334f61aebed695e2e4193db5e","new_path":"bytebase/db__ver1__migrate__create_t.sql","new_line":2}}`
+							assert.Equal(t, wantBody, string(body))
+							return &http.Response{
+								StatusCode: http.StatusCreated,
+								Body:       io.NopCloser(strings.NewReader(`{"id": "6a9c1750b37d513a43987b574953fceb50b03ce7", "notes": []}`)),
+							}, nil
+						case "/api/v4/projects/1/merge_requests/2/notes":
+							body, err := io.ReadAll(r.Body)
+							require.NoError(t, err)
+							assert.Equal(t, `{"body":"Task checks found problems."}`, string(body))
+							return &http.Response{
+								StatusCode: http.StatusCreated,
+								Body:       io.NopCloser(strings.NewReader(`{"id": 302, "body": "Task checks found problems."}`)),
+							}, nil
+						}
+						return nil, errors.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	err := p.CreatePullRequestReview(ctx, common.OauthContext{}, "", "1", "2", &vcs.PullRequestReview{
+		CommitID: "6dcb09b5b57875f334f61aebed695e2e4193db5e",
+		Body:     "Task checks found problems.",
+		CommentList: []*vcs.PullRequestReviewComment{
+			{Path: "bytebase/db__ver1__migrate__create_t.sql", Line: 2, Body: "**WARN** Require primary key"},
+		},
+	})
+	require.NoError(t, err)
+	want := []string{
+		"GET /api/v4/projects/1/merge_requests/2",
+		"POST /api/v4/projects/1/merge_requests/2/discussions",
+		"POST /api/v4/projects/1/merge_requests/2/notes",
+	}
+	assert.Equal(t, want, requestList)
+
+	// The line comments are rejected if the reviewed commit is outdated.
+	err = p.CreatePullRequestReview(ctx, common.OauthContext{}, "", "1", "2", &vcs.PullRequestReview{
+		CommitID: "c380d3acebd181f13629a25d2e2acca46ffe1e00",
+		Body:     "Task checks found problems.",
+		CommentList: []*vcs.PullRequestReviewComment{
+			{Path: "bytebase/db__ver1__migrate__create_t.sql", Line: 2, Body: "**WARN** Require primary key"},
+		},
+	})
+	require.Error(t, err)
+}
+
+func TestProvider_SetCommitStatus(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, http.MethodPost, r.Method)
+						assert.Equal(t, "/api/v4/projects/1/statuses/18f3e63d05582537db6d183d9d557be09e1f90c8", r.URL.Path)
+
+						body, err := io.ReadAll(r.Body)
+						require.NoError(t, err)
+						wantBody := `{"state":"failed","name":"bytebase/task-check","target_url":"https://bytebase.example.com/issue/1","description":"Task checks failed"}`
+						assert.Equal(t, wantBody, string(body))
+						return &http.Response{
+							StatusCode: http.StatusCreated,
+							// Example response taken from https://docs.gitlab.com/ee/api/commits.html#post-the-build-status-to-a-commit
+							Body: io.NopCloser(strings.NewReader(`
+{
+  "author" : {
+    "web_url" : "https://gitlab.example.com/thedude",
+    "name" : "Jeff Lebowski",
+    "avatar_url" : "https://gitlab.example.com/uploads/user/avatar/28/The-Big-Lebowski-400-400.png",
+    "username" : "thedude",
+    "state" : "active",
+    "id" : 28
+  },
+  "name" : "bytebase/task-check",
+  "status" : "failed",
+  "sha" : "18f3e63d05582537db6d183d9d557be09e1f90c8",
+  "target_url" : "https://bytebase.example.com/issue/1",
+  "description" : "Task checks failed",
+  "id" : 93,
+  "created_at" : "2016-01-19T08:40:25.832Z"
+}
+`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	err := p.SetCommitStatus(
+		ctx,
+		common.OauthContext{},
+		"",
+		"1",
+		"18f3e63d05582537db6d183d9d557be09e1f90c8",
+		&vcs.CommitStatus{
+			State:       vcs.CommitStatusFailure,
+			Context:     "bytebase/task-check",
+			Description: "Task checks failed",
+			TargetURL:   "https://bytebase.example.com/issue/1",
+		},
+	)
+	require.NoError(t, err)
+}
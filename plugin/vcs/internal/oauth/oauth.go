@@ -16,14 +16,14 @@ import (
 // the old token upon a successful refresh.
 type TokenRefresher func(ctx context.Context, client *http.Client, oldToken *string) error
 
-func requester(ctx context.Context, client *http.Client, method, url string, token *string, body io.Reader) func() (*http.Response, error) {
+func requester(ctx context.Context, client *http.Client, method, url string, token *string, contentType string, body io.Reader) func() (*http.Response, error) {
 	return func() (*http.Response, error) {
 		req, err := http.NewRequestWithContext(ctx, method, url, body)
 		if err != nil {
 			return nil, errors.Wrapf(err, "construct %s %s", method, url)
 		}
 
-		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Type", contentType)
 		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", *token))
 		resp, err := client.Do(req)
 		if err != nil {
@@ -36,31 +36,38 @@ func requester(ctx context.Context, client *http.Client, method, url string, tok
 // Post makes a HTTP POST request to the given URL using the token. It refreshes
 // token and retries the request in the case of the token has expired.
 func Post(ctx context.Context, client *http.Client, url string, token *string, body io.Reader, tokenRefresher TokenRefresher) (code int, header http.Header, respBody string, err error) {
-	return retry(ctx, client, token, tokenRefresher, requester(ctx, client, http.MethodPost, url, token, body))
+	return retry(ctx, client, token, tokenRefresher, requester(ctx, client, http.MethodPost, url, token, "application/json", body))
 }
 
 // Get makes a HTTP GET request to the given URL using the token. It refreshes
 // token and retries the request in the case of the token has expired.
 func Get(ctx context.Context, client *http.Client, url string, token *string, tokenRefresher TokenRefresher) (code int, header http.Header, respBody string, err error) {
-	return retry(ctx, client, token, tokenRefresher, requester(ctx, client, http.MethodGet, url, token, nil))
+	return retry(ctx, client, token, tokenRefresher, requester(ctx, client, http.MethodGet, url, token, "application/json", nil))
 }
 
 // Put makes a HTTP PUT request to the given URL using the token. It refreshes
 // token and retries the request in the case of the token has expired.
 func Put(ctx context.Context, client *http.Client, url string, token *string, body io.Reader, tokenRefresher TokenRefresher) (code int, header http.Header, respBody string, err error) {
-	return retry(ctx, client, token, tokenRefresher, requester(ctx, client, http.MethodPut, url, token, body))
+	return retry(ctx, client, token, tokenRefresher, requester(ctx, client, http.MethodPut, url, token, "application/json", body))
+}
+
+// PutWithContentType makes a HTTP PUT request to the given URL using the token
+// with the body in given content type, e.g. "multipart/form-data". It refreshes
+// token and retries the request in the case of the token has expired.
+func PutWithContentType(ctx context.Context, client *http.Client, url string, token *string, contentType string, body io.Reader, tokenRefresher TokenRefresher) (code int, header http.Header, respBody string, err error) {
+	return retry(ctx, client, token, tokenRefresher, requester(ctx, client, http.MethodPut, url, token, contentType, body))
 }
 
 // Patch makes a HTTP PATCH request to the given URL using the token. It
 // refreshes token and retries the request in the case of the token has expired.
 func Patch(ctx context.Context, client *http.Client, url string, token *string, body io.Reader, tokenRefresher TokenRefresher) (code int, header http.Header, respBody string, err error) {
-	return retry(ctx, client, token, tokenRefresher, requester(ctx, client, http.MethodPatch, url, token, body))
+	return retry(ctx, client, token, tokenRefresher, requester(ctx, client, http.MethodPatch, url, token, "application/json", body))
 }
 
 // Delete makes a HTTP DELETE request to the given URL using the token. It refreshes
 // token and retries the request in the case of the token has expired.
 func Delete(ctx context.Context, client *http.Client, url string, token *string, tokenRefresher TokenRefresher) (code int, header http.Header, respBody string, err error) {
-	return retry(ctx, client, token, tokenRefresher, requester(ctx, client, http.MethodDelete, url, token, nil))
+	return retry(ctx, client, token, tokenRefresher, requester(ctx, client, http.MethodDelete, url, token, "application/json", nil))
 }
 
 const maxRetries = 3
@@ -22,8 +22,12 @@ const (
 	GitLabSelfHost Type = "GITLAB_SELF_HOST"
 	// GitHubCom is the VCS type for GitHub.com.
 	GitHubCom Type = "GITHUB_COM"
+	// GiteaSelfHost is the VCS type for Gitea self host.
+	GiteaSelfHost Type = "GITEA_SELF_HOST"
+	// BitbucketServer is the VCS type for Bitbucket Server (Data Center).
+	BitbucketServer Type = "BITBUCKET_SERVER"
 
-	// SQLReviewAPISecretName is the api secret name used in GitHub action or GitLab CI workflow.
+	// SQLReviewAPISecretName is the api secret name used in GitHub action, GitLab CI, Gitea action or Jenkins pipeline workflow.
 	SQLReviewAPISecretName = "SQL_REVIEW_API_SECRET"
 )
 
@@ -168,6 +172,86 @@ type PullRequest struct {
 	URL string `json:"url"`
 }
 
+// PullRequestAction is the action of a pull request event.
+type PullRequestAction string
+
+const (
+	// PullRequestOpened is the action when the pull request is opened or reopened.
+	PullRequestOpened PullRequestAction = "OPENED"
+	// PullRequestUpdated is the action when new commits are pushed to the source branch of the pull request.
+	PullRequestUpdated PullRequestAction = "UPDATED"
+	// PullRequestMerged is the action when the pull request is merged.
+	PullRequestMerged PullRequestAction = "MERGED"
+	// PullRequestClosed is the action when the pull request is closed without being merged.
+	PullRequestClosed PullRequestAction = "CLOSED"
+)
+
+// PullRequestEvent is the API message for a VCS pull request (merge request in GitLab) event.
+type PullRequestEvent struct {
+	Action PullRequestAction
+	// ID is the identifier of the pull request used in the VCS API, e.g. the
+	// pull request number in GitHub and the merge request IID in GitLab.
+	ID    string
+	URL   string
+	Title string
+	// HeadRef is the ref of the source branch, e.g. "refs/heads/feature/foo".
+	HeadRef string
+	// HeadCommitID is the latest commit of the source branch.
+	HeadCommitID string
+	// BaseBranch is the name of the target branch, e.g. "main".
+	BaseBranch         string
+	RepositoryID       string
+	RepositoryURL      string
+	RepositoryFullPath string
+	AuthorName         string
+	AuthorEmail        string
+}
+
+// CommitStatusState is the state of a commit status.
+type CommitStatusState string
+
+const (
+	// CommitStatusPending is the commit status state for pending.
+	CommitStatusPending CommitStatusState = "PENDING"
+	// CommitStatusRunning is the commit status state for running.
+	CommitStatusRunning CommitStatusState = "RUNNING"
+	// CommitStatusSuccess is the commit status state for success.
+	CommitStatusSuccess CommitStatusState = "SUCCESS"
+	// CommitStatusFailure is the commit status state for failure.
+	CommitStatusFailure CommitStatusState = "FAILURE"
+	// CommitStatusCanceled is the commit status state for canceled.
+	CommitStatusCanceled CommitStatusState = "CANCELED"
+)
+
+// CommitStatus is the API message for setting the status of a commit.
+type CommitStatus struct {
+	State CommitStatusState
+	// Context distinguishes the status from the ones set by other systems, e.g. "bytebase/task-check".
+	// The status with the same context is overwritten.
+	Context     string
+	Description string
+	TargetURL   string
+}
+
+// PullRequestReview is the API message for reviewing a pull request with the comments on the lines of the changed files.
+type PullRequestReview struct {
+	// CommitID is the commit of the pull request that the line comments refer to.
+	CommitID string
+	// Body is the overall comment of the review in markdown.
+	Body        string
+	CommentList []*PullRequestReviewComment
+}
+
+// PullRequestReviewComment is the API message for a review comment on a line of a changed file in the pull request.
+type PullRequestReviewComment struct {
+	// Path is the file path in the repository.
+	Path string
+	// Line is the line number in the new version of the file, starting from 1.
+	Line int
+	// Body is the comment in markdown.
+	Body string
+}
+
 // Provider is the interface for VCS provider.
 type Provider interface {
 	// Returns the API URL for a given VCS instance URL
@@ -270,6 +354,31 @@ type Provider interface {
 	ListPullRequestFile(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, pullRequestID string) ([]*PullRequestFile, error)
 	// pullRequestCreate: the new pull request info
 	CreatePullRequest(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID string, pullRequestCreate *PullRequestCreate) (*PullRequest, error)
+	// CreatePullRequestComment creates a comment in the pull request.
+	//
+	// oauthCtx: OAuth context to create the comment
+	// instanceURL: VCS instance URL
+	// repositoryID: the repository ID from the external VCS system (note this is NOT the ID of Bytebase's own repository resource)
+	// pullRequestID: the pull request id
+	// comment: the comment content in markdown
+	CreatePullRequestComment(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, pullRequestID, comment string) error
+	// CreatePullRequestReview creates a review with the line comments in the pull request.
+	// The VCS may reject the line comment on a line not in the diff of the pull request.
+	//
+	// oauthCtx: OAuth context to create the review
+	// instanceURL: VCS instance URL
+	// repositoryID: the repository ID from the external VCS system (note this is NOT the ID of Bytebase's own repository resource)
+	// pullRequestID: the pull request id
+	// review: the review with the line comments
+	CreatePullRequestReview(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, pullRequestID string, review *PullRequestReview) error
+	// SetCommitStatus creates or updates the status of the commit with the same status context.
+	//
+	// oauthCtx: OAuth context to set the commit status
+	// instanceURL: VCS instance URL
+	// repositoryID: the repository ID from the external VCS system (note this is NOT the ID of Bytebase's own repository resource)
+	// commitID: the commit ID
+	// status: the commit status
+	SetCommitStatus(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, commitID string, status *CommitStatus) error
 	// UpsertEnvironmentVariable creates or updates the environment variable in the repository.
 	//
 	// oauthCtx: OAuth context to create the webhook
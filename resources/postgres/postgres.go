@@ -211,6 +211,27 @@ func InitDB(pgBinDir, pgDataDir, pgUser string) error {
 	return nil
 }
 
+// ChownDataDir changes the owner of the files in the data directory to the user running the postgres instance.
+// It's needed for the data directory not created by initdb, e.g., the one extracted from a base backup.
+func ChownDataDir(pgDataDir string) error {
+	uid, gid, sameUser, err := shouldSwitchUser()
+	if err != nil {
+		return err
+	}
+	if sameUser {
+		return nil
+	}
+	return filepath.Walk(pgDataDir, func(path string, _ os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := os.Lchown(path, uid, gid); err != nil {
+			return errors.Wrapf(err, "failed to change owner of %q to bytebase", path)
+		}
+		return nil
+	})
+}
+
 func shouldSwitchUser() (int, int, bool, error) {
 	sameUser := true
 	bytebaseUser, err := user.Current()
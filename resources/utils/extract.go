@@ -33,6 +33,11 @@ func ExtractTarXz(tarXzF io.Reader, targetDir string) error {
 	return extractTar(xzR, targetDir)
 }
 
+// ExtractTar extracts the given file as .tar format to the given directory.
+func ExtractTar(tarF io.Reader, targetDir string) error {
+	return extractTar(tarF, targetDir)
+}
+
 func extractTar(r io.Reader, targetDir string) error {
 	tarReader := tar.NewReader(r)
 	for {
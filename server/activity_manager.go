@@ -5,10 +5,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/common"
 	"github.com/bytebase/bytebase/common/log"
+	vcsPlugin "github.com/bytebase/bytebase/plugin/vcs"
 	"github.com/bytebase/bytebase/plugin/webhook"
 	"github.com/bytebase/bytebase/store"
 
@@ -16,22 +19,39 @@ import (
 	"go.uber.org/zap"
 )
 
+// commitStatusTimeout is the timeout of updating the commit statuses of an issue, so that a slow VCS doesn't hold the issue lock forever.
+const commitStatusTimeout = 30 * time.Second
+
 // ActivityManager is the activity manager.
 type ActivityManager struct {
 	s     *Server
 	store *store.Store
+	// commitStatusLockMap serializes the commit status updates of the same issue by issue ID, so that the latest task status wins
+	// when the statuses of the issue change in quick succession. It's guarded by commitStatusLockMapMutex.
+	commitStatusLockMap      map[int]*commitStatusLock
+	commitStatusLockMapMutex sync.Mutex
+}
+
+// commitStatusLock is the lock of updating the commit statuses of an issue.
+// refCount is the number of the updates holding or waiting for the lock, and the lock is removed from the map if it drops to zero.
+type commitStatusLock struct {
+	sync.Mutex
+	refCount int
 }
 
 // ActivityMeta is the activity metadata.
 type ActivityMeta struct {
 	issue *api.Issue
+	// database is set for the database activities not related to any issue, and the webhooks of its project are posted.
+	database *api.Database
 }
 
 // NewActivityManager creates an activity manager.
 func NewActivityManager(server *Server, store *store.Store) *ActivityManager {
 	return &ActivityManager{
-		s:     server,
-		store: store,
+		s:                   server,
+		store:               store,
+		commitStatusLockMap: make(map[int]*commitStatusLock),
 	}
 }
 
@@ -43,6 +63,11 @@ func (m *ActivityManager) CreateActivity(ctx context.Context, create *api.Activi
 	}
 
 	if meta.issue == nil {
+		if meta.database != nil {
+			if err := m.postDatabaseActivityWebhook(ctx, activity, meta.database); err != nil {
+				return nil, err
+			}
+		}
 		return activity, nil
 	}
 	postInbox, err := shouldPostInbox(activity, create.Type)
@@ -55,6 +80,16 @@ func (m *ActivityManager) CreateActivity(ctx context.Context, create *api.Activi
 		}
 	}
 
+	if shouldUpdateCommitStatus(create.Type) {
+		taskID, err := getActivityTaskID(activity)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to update commit status after changing the issue: %s", meta.issue.Name)
+		}
+		// Call the VCS in Go routine to avoid blocking the caller. We don't use the request context because
+		// it's canceled once the response is sent.
+		go m.UpdateCommitStatus(context.Background(), meta.issue.ID, taskID)
+	}
+
 	hookFind := &api.ProjectWebhookFind{
 		ProjectID:    &meta.issue.ProjectID,
 		ActivityType: &create.Type,
@@ -100,6 +135,73 @@ func (m *ActivityManager) CreateActivity(ctx context.Context, create *api.Activi
 	return activity, nil
 }
 
+// postDatabaseActivityWebhook posts the database activity to the webhooks of the project owning the database.
+func (m *ActivityManager) postDatabaseActivityWebhook(ctx context.Context, activity *api.Activity, database *api.Database) error {
+	hookFind := &api.ProjectWebhookFind{
+		ProjectID:    &database.ProjectID,
+		ActivityType: &activity.Type,
+	}
+	webhookList, err := m.s.store.FindProjectWebhook(ctx, hookFind)
+	if err != nil {
+		return errors.Wrapf(err, "failed to find project webhook for database %q", database.Name)
+	}
+	if len(webhookList) == 0 {
+		return nil
+	}
+
+	creator, err := m.s.store.GetPrincipalByID(ctx, activity.CreatorID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to find creator for posting webhook event of database %q", database.Name)
+	}
+	if creator == nil {
+		return errors.Errorf("creator principal not found for ID %v", activity.CreatorID)
+	}
+
+	level := webhook.WebhookInfo
+	switch activity.Level {
+	case api.ActivityWarn:
+		level = webhook.WebhookWarn
+	case api.ActivityError:
+		level = webhook.WebhookError
+	}
+	title := fmt.Sprintf("Database changed - %s", database.Name)
+	if activity.Type == api.ActivityDatabaseBackupVerificationFailed {
+		title = fmt.Sprintf("Backup verification failed - %s", database.Name)
+	}
+	webhookCtx := webhook.Context{
+		Level:        level,
+		ActivityType: string(activity.Type),
+		Title:        title,
+		Project: &webhook.Project{
+			ID:   database.ProjectID,
+			Name: database.Project.Name,
+		},
+		Description:  activity.Comment,
+		Link:         fmt.Sprintf("%s/db/%s", m.s.profile.ExternalURL, api.DatabaseSlug(database)),
+		CreatorID:    creator.ID,
+		CreatorName:  creator.Name,
+		CreatorEmail: creator.Email,
+	}
+
+	// Call external webhook endpoint in Go routine to avoid blocking the caller.
+	go func() {
+		for _, hook := range webhookList {
+			webhookCtx.URL = hook.URL
+			webhookCtx.CreatedTs = time.Now().Unix()
+			if err := webhook.Post(hook.Type, webhookCtx); err != nil {
+				// The external webhook endpoint might be invalid which is out of our code control, so we just emit a warning
+				log.Warn("Failed to post webhook event for database activity",
+					zap.String("webhook_type", hook.Type),
+					zap.String("webhook_name", hook.Name),
+					zap.String("database_name", database.Name),
+					zap.String("activity_type", string(activity.Type)),
+					zap.Error(err))
+			}
+		}
+	}()
+	return nil
+}
+
 func (m *ActivityManager) getWebhookContext(ctx context.Context, activity *api.Activity, meta *ActivityMeta, updater *api.Principal) (webhook.Context, error) {
 	var webhookCtx webhook.Context
 	level := webhook.WebhookInfo
@@ -272,6 +374,218 @@ func (m *ActivityManager) getWebhookContext(ctx context.Context, activity *api.A
 	return webhookCtx, nil
 }
 
+// UpdateCommitStatus sets the status of each environment stage on the commit triggering the issue created from the VCS push event,
+// so that the branch protection can gate on the database deploy results. If taskID is not zero, only the stage containing the task is updated.
+// Errors are only logged because the VCS is out of our control.
+func (m *ActivityManager) UpdateCommitStatus(ctx context.Context, issueID int, taskID int) {
+	unlock := m.lockCommitStatus(issueID)
+	defer unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, commitStatusTimeout)
+	defer cancel()
+	if err := m.updateCommitStatus(ctx, issueID, taskID); err != nil {
+		log.Warn("Failed to update commit status of the issue",
+			zap.Int("issue_id", issueID),
+			zap.Int("task_id", taskID),
+			zap.Error(err))
+	}
+}
+
+// lockCommitStatus locks the commit status updates of the issue, and returns the function to unlock.
+func (m *ActivityManager) lockCommitStatus(issueID int) func() {
+	m.commitStatusLockMapMutex.Lock()
+	lock, ok := m.commitStatusLockMap[issueID]
+	if !ok {
+		lock = &commitStatusLock{}
+		m.commitStatusLockMap[issueID] = lock
+	}
+	lock.refCount++
+	m.commitStatusLockMapMutex.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+		m.commitStatusLockMapMutex.Lock()
+		defer m.commitStatusLockMapMutex.Unlock()
+		lock.refCount--
+		if lock.refCount == 0 {
+			delete(m.commitStatusLockMap, issueID)
+		}
+	}
+}
+
+func (m *ActivityManager) updateCommitStatus(ctx context.Context, issueID int, taskID int) error {
+	// Fetch the issue again to get the latest task status.
+	issue, err := m.store.GetIssueByID(ctx, issueID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to find issue %d", issueID)
+	}
+	if issue == nil || issue.Pipeline == nil {
+		return nil
+	}
+	// The stages of the draft issue cannot run until the pull request is merged, so we don't block the pull request with them.
+	if pullRequest := getIssueVCSPullRequest(issue); pullRequest != nil && pullRequest.Draft {
+		return nil
+	}
+
+	var pushEvent *vcsPlugin.PushEvent
+	for _, stage := range issue.Pipeline.StageList {
+		for _, task := range stage.TaskList {
+			if pushEvent = getTaskVCSPushEvent(task); pushEvent != nil {
+				break
+			}
+		}
+		if pushEvent != nil {
+			break
+		}
+	}
+	// The issue is not created from the VCS push event.
+	if pushEvent == nil || len(pushEvent.CommitList) == 0 {
+		return nil
+	}
+
+	repo, err := m.store.GetRepository(ctx, &api.RepositoryFind{ProjectID: &issue.ProjectID})
+	if err != nil {
+		return errors.Wrapf(err, "failed to find repository of project %d", issue.ProjectID)
+	}
+	// The project might have been unlinked or linked to another repository after the issue is created.
+	if repo == nil || repo.ExternalID != pushEvent.RepositoryID {
+		return nil
+	}
+
+	commitID := pushEvent.CommitList[len(pushEvent.CommitList)-1].ID
+	link := fmt.Sprintf("%s/issue/%s", m.s.profile.ExternalURL, api.IssueSlug(issue))
+	for _, stage := range issue.Pipeline.StageList {
+		if taskID != 0 && !stageContainsTask(stage, taskID) {
+			continue
+		}
+		status := getStageCommitStatus(stage, issue.Status)
+		status.TargetURL = link
+		if err := vcsPlugin.Get(repo.VCS.Type, vcsPlugin.ProviderConfig{}).SetCommitStatus(
+			ctx,
+			common.OauthContext{
+				ClientID:     repo.VCS.ApplicationID,
+				ClientSecret: repo.VCS.Secret,
+				AccessToken:  repo.AccessToken,
+				RefreshToken: repo.RefreshToken,
+				Refresher:    m.s.refreshToken(ctx, repo.WebURL),
+			},
+			repo.VCS.InstanceURL,
+			repo.ExternalID,
+			commitID,
+			status,
+		); err != nil {
+			return errors.Wrapf(err, "failed to set status %q of commit %s", status.Context, commitID)
+		}
+	}
+	return nil
+}
+
+// getStageCommitStatus summarizes the status of the tasks in the stage into the commit status.
+func getStageCommitStatus(stage *api.Stage, issueStatus api.IssueStatus) *vcsPlugin.CommitStatus {
+	var pendingApproval, running, done, failed, canceled int
+	for _, task := range stage.TaskList {
+		switch task.Status {
+		case api.TaskPendingApproval:
+			pendingApproval++
+		case api.TaskRunning:
+			running++
+		case api.TaskDone:
+			done++
+		case api.TaskFailed:
+			failed++
+		case api.TaskCanceled:
+			canceled++
+		}
+	}
+	total := len(stage.TaskList)
+
+	status := &vcsPlugin.CommitStatus{
+		Context: fmt.Sprintf("bytebase/%s", stage.Environment.Name),
+	}
+	switch {
+	case failed > 0:
+		status.State = vcsPlugin.CommitStatusFailure
+		status.Description = fmt.Sprintf("%d of %d task(s) failed", failed, total)
+	case done == total:
+		status.State = vcsPlugin.CommitStatusSuccess
+		status.Description = fmt.Sprintf("%d task(s) done", total)
+	case running > 0:
+		status.State = vcsPlugin.CommitStatusRunning
+		status.Description = fmt.Sprintf("%d of %d task(s) running", running, total)
+	// The unfinished tasks will never run once the issue is canceled.
+	case canceled+done == total || issueStatus == api.IssueCanceled:
+		status.State = vcsPlugin.CommitStatusCanceled
+		status.Description = fmt.Sprintf("%d of %d task(s) canceled", total-done, total)
+	case pendingApproval > 0:
+		status.State = vcsPlugin.CommitStatusPending
+		status.Description = fmt.Sprintf("%d of %d task(s) pending approval", pendingApproval, total)
+	default:
+		status.State = vcsPlugin.CommitStatusPending
+		status.Description = fmt.Sprintf("%d of %d task(s) pending", total-done-canceled, total)
+	}
+	return status
+}
+
+func stageContainsTask(stage *api.Stage, taskID int) bool {
+	for _, task := range stage.TaskList {
+		if task.ID == taskID {
+			return true
+		}
+	}
+	return false
+}
+
+// getTaskVCSPushEvent returns the VCS push event of the migration task, or nil if the task is not created from the VCS.
+func getTaskVCSPushEvent(task *api.Task) *vcsPlugin.PushEvent {
+	var pushEvent *vcsPlugin.PushEvent
+	var err error
+	switch task.Type {
+	case api.TaskDatabaseSchemaUpdate:
+		payload := &api.TaskDatabaseSchemaUpdatePayload{}
+		err = json.Unmarshal([]byte(task.Payload), payload)
+		pushEvent = payload.VCSPushEvent
+	case api.TaskDatabaseSchemaUpdateSDL:
+		payload := &api.TaskDatabaseSchemaUpdateSDLPayload{}
+		err = json.Unmarshal([]byte(task.Payload), payload)
+		pushEvent = payload.VCSPushEvent
+	case api.TaskDatabaseSchemaUpdateGhostSync:
+		payload := &api.TaskDatabaseSchemaUpdateGhostSyncPayload{}
+		err = json.Unmarshal([]byte(task.Payload), payload)
+		pushEvent = payload.VCSPushEvent
+	case api.TaskDatabaseDataUpdate:
+		payload := &api.TaskDatabaseDataUpdatePayload{}
+		err = json.Unmarshal([]byte(task.Payload), payload)
+		pushEvent = payload.VCSPushEvent
+	}
+	if err != nil {
+		log.Warn("Failed to unmarshal task payload", zap.Int("task_id", task.ID), zap.Error(err))
+		return nil
+	}
+	return pushEvent
+}
+
+// shouldUpdateCommitStatus returns whether the activity might change the status of the issue stages.
+func shouldUpdateCommitStatus(createType api.ActivityType) bool {
+	switch createType {
+	case api.ActivityIssueCreate, api.ActivityIssueStatusUpdate, api.ActivityPipelineTaskStatusUpdate:
+		return true
+	}
+	return false
+}
+
+// getActivityTaskID returns the ID of the task whose status is changed by the activity, or zero for other activities.
+func getActivityTaskID(activity *api.Activity) (int, error) {
+	if activity.Type != api.ActivityPipelineTaskStatusUpdate {
+		return 0, nil
+	}
+	update := new(api.ActivityPipelineTaskStatusUpdatePayload)
+	if err := json.Unmarshal([]byte(activity.Payload), update); err != nil {
+		return 0, err
+	}
+	return update.TaskID, nil
+}
+
 func shouldPostInbox(activity *api.Activity, createType api.ActivityType) (bool, error) {
 	switch createType {
 	case api.ActivityIssueCreate:
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bytebase/bytebase/api"
+	vcsPlugin "github.com/bytebase/bytebase/plugin/vcs"
+)
+
+func TestGetStageCommitStatus(t *testing.T) {
+	tests := []struct {
+		name            string
+		taskStatusList  []api.TaskStatus
+		issueStatus     api.IssueStatus
+		wantState       vcsPlugin.CommitStatusState
+		wantDescription string
+	}{
+		{
+			name:            "pending approval",
+			taskStatusList:  []api.TaskStatus{api.TaskPendingApproval, api.TaskPendingApproval},
+			issueStatus:     api.IssueOpen,
+			wantState:       vcsPlugin.CommitStatusPending,
+			wantDescription: "2 of 2 task(s) pending approval",
+		},
+		{
+			name:            "approved",
+			taskStatusList:  []api.TaskStatus{api.TaskDone, api.TaskPending},
+			issueStatus:     api.IssueOpen,
+			wantState:       vcsPlugin.CommitStatusPending,
+			wantDescription: "1 of 2 task(s) pending",
+		},
+		{
+			name:            "running",
+			taskStatusList:  []api.TaskStatus{api.TaskDone, api.TaskRunning, api.TaskPendingApproval},
+			issueStatus:     api.IssueOpen,
+			wantState:       vcsPlugin.CommitStatusRunning,
+			wantDescription: "1 of 3 task(s) running",
+		},
+		{
+			name:            "done",
+			taskStatusList:  []api.TaskStatus{api.TaskDone, api.TaskDone},
+			issueStatus:     api.IssueDone,
+			wantState:       vcsPlugin.CommitStatusSuccess,
+			wantDescription: "2 task(s) done",
+		},
+		{
+			name:            "failed",
+			taskStatusList:  []api.TaskStatus{api.TaskFailed, api.TaskRunning},
+			issueStatus:     api.IssueOpen,
+			wantState:       vcsPlugin.CommitStatusFailure,
+			wantDescription: "1 of 2 task(s) failed",
+		},
+		{
+			name:            "task canceled",
+			taskStatusList:  []api.TaskStatus{api.TaskDone, api.TaskCanceled},
+			issueStatus:     api.IssueOpen,
+			wantState:       vcsPlugin.CommitStatusCanceled,
+			wantDescription: "1 of 2 task(s) canceled",
+		},
+		{
+			name:            "issue canceled",
+			taskStatusList:  []api.TaskStatus{api.TaskPendingApproval, api.TaskPendingApproval},
+			issueStatus:     api.IssueCanceled,
+			wantState:       vcsPlugin.CommitStatusCanceled,
+			wantDescription: "2 of 2 task(s) canceled",
+		},
+	}
+
+	for _, test := range tests {
+		stage := &api.Stage{
+			Environment: &api.Environment{Name: "Prod"},
+		}
+		for _, status := range test.taskStatusList {
+			stage.TaskList = append(stage.TaskList, &api.Task{Status: status})
+		}
+		status := getStageCommitStatus(stage, test.issueStatus)
+		assert.Equal(t, "bytebase/Prod", status.Context, test.name)
+		assert.Equal(t, test.wantState, status.State, test.name)
+		assert.Equal(t, test.wantDescription, status.Description, test.name)
+	}
+}
+
+func TestLockCommitStatus(t *testing.T) {
+	m := NewActivityManager(nil /* server */, nil /* store */)
+
+	unlock1 := m.lockCommitStatus(101)
+	// The updates of other issues are not blocked.
+	unlock2 := m.lockCommitStatus(102)
+	unlock2()
+
+	locked := make(chan struct{})
+	go func() {
+		unlock := m.lockCommitStatus(101)
+		close(locked)
+		unlock()
+	}()
+	select {
+	case <-locked:
+		t.Fatal("the second update of the same issue should wait for the first one")
+	case <-time.After(100 * time.Millisecond):
+	}
+	unlock1()
+	<-locked
+
+	// Wait for the second update to release the lock.
+	assert.Eventually(t, func() bool {
+		m.commitStatusLockMapMutex.Lock()
+		defer m.commitStatusLockMapMutex.Unlock()
+		return len(m.commitStatusLockMap) == 0
+	}, time.Second, 10*time.Millisecond)
+}
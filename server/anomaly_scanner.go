@@ -374,32 +374,26 @@ func (s *AnomalyScanner) checkBackupAnomaly(ctx context.Context, instance *api.I
 		return
 	}
 
-	if backupSetting != nil && backupSetting.Enabled && backupSetting.Hour != -1 {
-		if backupSetting.DayOfWeek == -1 {
-			schedule = api.BackupPlanPolicyScheduleDaily
-		} else {
-			schedule = api.BackupPlanPolicyScheduleWeekly
-		}
+	if backupSetting != nil {
+		schedule = api.GetBackupSettingSchedule(backupSetting.Enabled, backupSetting.Hour, backupSetting.DayOfWeek, backupSetting.ScheduleList)
 	}
 
 	// Check backup policy violation
 	{
 		var backupPolicyAnomalyPayload *api.AnomalyDatabaseBackupPolicyViolationPayload
-		if policyMap[instance.EnvironmentID].Schedule != api.BackupPlanPolicyScheduleUnset {
-			if policyMap[instance.EnvironmentID].Schedule == api.BackupPlanPolicyScheduleDaily &&
-				schedule != api.BackupPlanPolicyScheduleDaily {
-				backupPolicyAnomalyPayload = &api.AnomalyDatabaseBackupPolicyViolationPayload{
-					EnvironmentID:          instance.EnvironmentID,
-					ExpectedBackupSchedule: policyMap[instance.EnvironmentID].Schedule,
-					ActualBackupSchedule:   schedule,
-				}
-			} else if policyMap[instance.EnvironmentID].Schedule == api.BackupPlanPolicyScheduleWeekly &&
-				schedule == api.BackupPlanPolicyScheduleUnset {
-				backupPolicyAnomalyPayload = &api.AnomalyDatabaseBackupPolicyViolationPayload{
-					EnvironmentID:          instance.EnvironmentID,
-					ExpectedBackupSchedule: policyMap[instance.EnvironmentID].Schedule,
-					ActualBackupSchedule:   schedule,
-				}
+		policy := policyMap[instance.EnvironmentID]
+		var missingCronScheduleList []api.BackupSchedule
+		if backupSetting == nil || !backupSetting.Enabled {
+			missingCronScheduleList = policy.CronScheduleList
+		} else {
+			missingCronScheduleList = policy.GetMissingCronScheduleList(backupSetting.ScheduleList)
+		}
+		if !schedule.Satisfies(policy.Schedule) || len(missingCronScheduleList) > 0 {
+			backupPolicyAnomalyPayload = &api.AnomalyDatabaseBackupPolicyViolationPayload{
+				EnvironmentID:           instance.EnvironmentID,
+				ExpectedBackupSchedule:  policy.Schedule,
+				ActualBackupSchedule:    schedule,
+				MissingCronScheduleList: missingCronScheduleList,
 			}
 		}
 
@@ -446,11 +440,14 @@ func (s *AnomalyScanner) checkBackupAnomaly(ctx context.Context, instance *api.I
 		var backupMissingAnomalyPayload *api.AnomalyDatabaseBackupMissingPayload
 		// The anomaly fires if backup is enabled, however no successful backup has been taken during the period.
 		if backupSetting != nil && backupSetting.Enabled {
-			expectedSchedule := api.BackupPlanPolicyScheduleWeekly
-			backupMaxAge := time.Duration(7*24) * time.Hour
-			if backupSetting.DayOfWeek == -1 {
-				expectedSchedule = api.BackupPlanPolicyScheduleDaily
-				backupMaxAge = time.Duration(24) * time.Hour
+			expectedSchedule := schedule
+			if expectedSchedule == api.BackupPlanPolicyScheduleUnset {
+				expectedSchedule = api.BackupPlanPolicyScheduleWeekly
+			}
+			backupMaxAge := expectedSchedule.Interval()
+			if expectedSchedule == api.BackupPlanPolicyScheduleHourly {
+				// Tolerate the delay of the backup runner and the backup task itself.
+				backupMaxAge *= 2
 			}
 
 			// Ignore if backup setting has been changed after the max age.
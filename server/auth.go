@@ -12,6 +12,7 @@ import (
 
 	"github.com/bytebase/bytebase/api"
 	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/plugin/ldap"
 	"github.com/bytebase/bytebase/plugin/vcs"
 )
 
@@ -155,6 +156,52 @@ func (s *Server) registerAuthRoutes(g *echo.Group) {
 					}
 				}
 			}
+		case api.PrincipalAuthProviderLDAP:
+			{
+				if !s.flight(api.FeatureLDAP) || !s.feature(api.FeatureLDAP) {
+					return echo.NewHTTPError(http.StatusForbidden, api.FeatureLDAP.AccessErrorMessage())
+				}
+				// The email field carries the LDAP username, which is matched by the user filter.
+				login := &api.Login{}
+				if err := jsonapi.UnmarshalPayload(c.Request().Body, login); err != nil {
+					return echo.NewHTTPError(http.StatusBadRequest, "Malformed login request").SetInternal(err)
+				}
+				setting, err := s.getLDAPSetting(ctx)
+				if err != nil {
+					return echo.NewHTTPError(http.StatusInternalServerError, "Failed to get LDAP setting").SetInternal(err)
+				}
+				if !setting.Enabled {
+					return echo.NewHTTPError(http.StatusBadRequest, "LDAP authentication is not enabled")
+				}
+
+				client, err := ldap.NewClient(getLDAPConfig(setting))
+				if err != nil {
+					return echo.NewHTTPError(http.StatusInternalServerError, "Failed to connect to LDAP server").SetInternal(err)
+				}
+				defer client.Close()
+				ldapUser, err := client.Authenticate(login.Email, login.Password)
+				if err != nil {
+					if common.ErrorCode(err) == common.NotAuthorized {
+						return echo.NewHTTPError(http.StatusUnauthorized, "Incorrect username or password").SetInternal(err)
+					}
+					return echo.NewHTTPError(http.StatusInternalServerError, "Failed to authenticate user via LDAP").SetInternal(err)
+				}
+				if ldapUser.Email == "" {
+					return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("LDAP user %q has no email, please contact your directory admin.", ldapUser.DN))
+				}
+
+				// The workspace role and project memberships are granted by the LDAP group sync.
+				user, err = s.getOrCreateLDAPPrincipal(ctx, setting, ldapUser)
+				if err != nil {
+					if httpErr, ok := err.(*echo.HTTPError); ok {
+						return httpErr
+					}
+					if common.ErrorCode(err) == common.Conflict {
+						return echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("User %s already exists and is not linked to LDAP, please contact your Bytebase admin.", ldapUser.Email)).SetInternal(err)
+					}
+					return echo.NewHTTPError(http.StatusInternalServerError, "Failed to authenticate user").SetInternal(err)
+				}
+			}
 		default:
 			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Unsupported auth provider: %s", authProvider))
 		}
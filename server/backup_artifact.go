@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/plugin/storage/codec"
+)
+
+// parseBackupArtifactSetting parses and validates the backup artifact setting value, and returns the encryption key if configured.
+func parseBackupArtifactSetting(value string) (*api.BackupArtifactSetting, []byte, error) {
+	setting := &api.BackupArtifactSetting{}
+	if value != "" {
+		if err := json.Unmarshal([]byte(value), setting); err != nil {
+			return nil, nil, common.Wrapf(err, common.Invalid, "invalid backup artifact setting")
+		}
+	}
+	if setting.Compression == "" {
+		setting.Compression = api.BackupCompressionNone
+	}
+	if setting.Encryption == "" {
+		setting.Encryption = api.BackupEncryptionNone
+	}
+	switch setting.Compression {
+	case api.BackupCompressionNone, api.BackupCompressionGzip, api.BackupCompressionZstd:
+	default:
+		return nil, nil, common.Errorf(common.Invalid, "invalid backup compression %q", setting.Compression)
+	}
+
+	var key []byte
+	var err error
+	switch {
+	case setting.EncryptionKeyFile != "":
+		key, err = codec.ReadKeyFile(setting.EncryptionKeyFile)
+	case setting.EncryptionKey != "":
+		key, err = codec.ParseKey(setting.EncryptionKey)
+	}
+	if err != nil {
+		return nil, nil, common.Wrapf(err, common.Invalid, "invalid backup encryption key")
+	}
+	switch setting.Encryption {
+	case api.BackupEncryptionNone:
+	case api.BackupEncryptionAES256GCM:
+		if key == nil {
+			return nil, nil, common.Errorf(common.Invalid, "encryption key or key file is required for %s encryption", setting.Encryption)
+		}
+	default:
+		return nil, nil, common.Errorf(common.Invalid, "invalid backup encryption %q", setting.Encryption)
+	}
+	return setting, key, nil
+}
+
+// getBackupArtifactSetting returns the backup artifact setting of the workspace and the encryption key.
+func (s *Server) getBackupArtifactSetting(ctx context.Context) (*api.BackupArtifactSetting, []byte, error) {
+	settingName := api.SettingBackupArtifact
+	settingList, err := s.store.FindSetting(ctx, &api.SettingFind{Name: &settingName})
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to find setting %s", settingName)
+	}
+	value := ""
+	if len(settingList) > 0 {
+		value = settingList[0].Value
+	}
+	return parseBackupArtifactSetting(value)
+}
+
+// newBackupArtifactReader decodes the backup artifact, and the format is detected from the artifact header.
+// The encryption key in the current setting is used to decrypt the encrypted backups.
+func (s *Server) newBackupArtifactReader(ctx context.Context, r io.Reader) (io.ReadCloser, error) {
+	_, key, err := s.getBackupArtifactSetting(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reader, _, err := codec.NewReader(r, key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode the backup artifact")
+	}
+	return reader, nil
+}
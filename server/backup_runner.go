@@ -8,6 +8,9 @@ import (
 	"net/http"
 	"os"
 	"path"
+	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -19,6 +22,7 @@ import (
 	"github.com/bytebase/bytebase/common/log"
 	"github.com/bytebase/bytebase/plugin/db"
 	"github.com/bytebase/bytebase/plugin/db/mysql"
+	"github.com/bytebase/bytebase/plugin/db/pg"
 )
 
 // NewBackupRunner creates a new backup runner.
@@ -27,6 +31,9 @@ func NewBackupRunner(server *Server, backupRunnerInterval time.Duration) *Backup
 		server:                    server,
 		backupRunnerInterval:      backupRunnerInterval,
 		downloadBinlogInstanceIDs: make(map[int]bool),
+		walReceiverMap:            make(map[int]*walReceiver),
+		walSlotInstanceIDs:        make(map[int]bool),
+		cronCheckTimes:            make(map[int]time.Time),
 	}
 }
 
@@ -38,6 +45,23 @@ type BackupRunner struct {
 	backupWg                  sync.WaitGroup
 	downloadBinlogWg          sync.WaitGroup
 	downloadBinlogMu          sync.Mutex
+	// walReceiverMap is the PostgreSQL instances receiving the WAL with pg_receivewal by instance ID.
+	walReceiverMap map[int]*walReceiver
+	// walSlotInstanceIDs is the PostgreSQL instances which may have the replication slot for archiving the WAL.
+	// The value is false for the instances only checked once for the slot left before Bytebase starts.
+	walSlotInstanceIDs map[int]bool
+	walSlotSwept       bool
+	archiveWALWg       sync.WaitGroup
+	archiveWALMu       sync.Mutex
+	// cronCheckTimes is the time by backup setting ID up to which the fired cron backup schedules have been scheduled.
+	cronCheckTimes map[int]time.Time
+	cronCheckMu    sync.Mutex
+}
+
+// walReceiver is the running pg_receivewal of a PostgreSQL instance.
+type walReceiver struct {
+	replicationSlot bool
+	cancel          context.CancelFunc
 }
 
 // Run is the runner for backup runner.
@@ -63,11 +87,13 @@ func (r *BackupRunner) Run(ctx context.Context, wg *sync.WaitGroup) {
 				}()
 				r.startAutoBackups(ctx, runningTasks, &mu)
 				r.downloadBinlogFiles(ctx)
+				r.archiveWALFiles(ctx)
 				r.purgeExpiredBackupData(ctx)
 			}()
 		case <-ctx.Done(): // if cancel() execute
 			r.backupWg.Wait()
 			r.downloadBinlogWg.Wait()
+			r.archiveWALWg.Wait()
 			return
 		}
 	}
@@ -83,7 +109,7 @@ func (r *BackupRunner) purgeExpiredBackupData(ctx context.Context) {
 	}
 
 	for _, bs := range backupSettingList {
-		if bs.RetentionPeriodTs == api.BackupRetentionPeriodUnset {
+		if bs.RetentionPeriodTs == api.BackupRetentionPeriodUnset && len(bs.ScheduleList) == 0 {
 			continue // next database
 		}
 		statusNormal := api.Normal
@@ -96,8 +122,13 @@ func (r *BackupRunner) purgeExpiredBackupData(ctx context.Context) {
 			return
 		}
 		for _, backup := range backupList {
+			// The backups taken by the cron schedules are retained for the retention period of the schedule.
+			retentionPeriodTs := bs.GetRetentionPeriodTs(backup.Payload.Schedule)
+			if retentionPeriodTs == api.BackupRetentionPeriodUnset {
+				continue
+			}
 			backupTime := time.Unix(backup.UpdatedTs, 0)
-			expireTime := backupTime.Add(time.Duration(bs.RetentionPeriodTs) * time.Second)
+			expireTime := backupTime.Add(time.Duration(retentionPeriodTs) * time.Second)
 			if time.Now().After(expireTime) {
 				log.Debug("Purging expired backup", zap.Int("databaseID", backup.DatabaseID), zap.String("backup", backup.Name), zap.String("storageBackend", string(backup.StorageBackend)))
 				if err := r.purgeBackup(ctx, backup); err != nil {
@@ -114,24 +145,75 @@ func (r *BackupRunner) purgeExpiredBackupData(ctx context.Context) {
 	}
 
 	for _, instance := range instanceList {
-		if instance.Engine != db.MySQL {
+		// The binlog files of MySQL and the WAL files of PostgreSQL are archived in the same instance directory.
+		if instance.Engine != db.MySQL && instance.Engine != db.Postgres {
+			continue
+		}
+		if instance.Engine == db.Postgres {
+			if err := r.purgePostgresBackupFiles(ctx, instance); err != nil {
+				log.Error("Failed to purge base backup and WAL files for instance", zap.String("instance", instance.Name), zap.Error(err))
+			}
 			continue
 		}
-		maxRetentionPeriodTs, err := r.getMaxRetentionPeriodTsForMySQLInstance(ctx, instance)
+		maxRetentionPeriodTs, err := r.getMaxRetentionPeriodTsForInstance(ctx, instance)
 		if err != nil {
-			log.Error("Failed to get max retention period for MySQL instance", zap.String("instance", instance.Name), zap.Error(err))
+			log.Error("Failed to get max retention period for instance", zap.String("instance", instance.Name), zap.Error(err))
 			continue
 		}
 		if maxRetentionPeriodTs == math.MaxInt {
 			continue
 		}
-		if err := r.purgeBinlogFiles(ctx, instance.ID, maxRetentionPeriodTs); err != nil {
+		if err := r.purgeBinlogFiles(ctx, instance.ID, time.Now().Add(-time.Duration(maxRetentionPeriodTs)*time.Second)); err != nil {
 			log.Error("Failed to purge binlog files for instance", zap.String("instance", instance.Name), zap.Int("retentionPeriodTs", maxRetentionPeriodTs), zap.Error(err))
 		}
 	}
 }
 
-func (r *BackupRunner) getMaxRetentionPeriodTsForMySQLInstance(ctx context.Context, instance *api.Instance) (int, error) {
+// purgePostgresBackupFiles purges the base backups and the WAL files of the PostgreSQL instance.
+// The base backups are shared by the database backups of the instance and are the only anchors to restore from,
+// so they are purged by the longest retention period of the instance, and the newest base backup is always kept.
+// The WAL files are kept since the oldest kept base backup.
+func (r *BackupRunner) purgePostgresBackupFiles(ctx context.Context, instance *api.Instance) error {
+	backupSettingList, err := r.server.store.FindBackupSetting(ctx, api.BackupSettingFind{InstanceID: &instance.ID})
+	if err != nil {
+		return errors.Wrapf(err, "failed to find backup settings for instance %q", instance.Name)
+	}
+	if len(backupSettingList) == 0 {
+		return nil
+	}
+	retentionPeriodTs := getBaseBackupRetentionPeriodTs(backupSettingList)
+	if retentionPeriodTs == api.BackupRetentionPeriodUnset {
+		// Some database keeps the backups forever.
+		return nil
+	}
+	expireBefore := time.Now().Add(-time.Duration(retentionPeriodTs) * time.Second)
+	oldestStartTime, err := r.purgeBaseBackupFiles(ctx, instance.ID, expireBefore)
+	if err != nil {
+		return err
+	}
+	if !oldestStartTime.IsZero() && oldestStartTime.Before(expireBefore) {
+		expireBefore = oldestStartTime
+	}
+	return r.purgeBinlogFiles(ctx, instance.ID, expireBefore)
+}
+
+// getBaseBackupRetentionPeriodTs returns the longest retention period of the backup settings,
+// or api.BackupRetentionPeriodUnset if any of them retains the backups forever.
+func getBaseBackupRetentionPeriodTs(backupSettingList []*api.BackupSetting) int {
+	var retentionPeriodTs int
+	for i, bs := range backupSettingList {
+		settingRetentionPeriodTs := getBackupSettingRetentionPeriodTs(bs)
+		if settingRetentionPeriodTs == api.BackupRetentionPeriodUnset {
+			return api.BackupRetentionPeriodUnset
+		}
+		if i == 0 || settingRetentionPeriodTs > retentionPeriodTs {
+			retentionPeriodTs = settingRetentionPeriodTs
+		}
+	}
+	return retentionPeriodTs
+}
+
+func (r *BackupRunner) getMaxRetentionPeriodTsForInstance(ctx context.Context, instance *api.Instance) (int, error) {
 	backupSettingList, err := r.server.store.FindBackupSetting(ctx, api.BackupSettingFind{InstanceID: &instance.ID})
 	if err != nil {
 		log.Error("Failed to find backup settings for instance.", zap.String("instance", instance.Name), zap.Error(err))
@@ -139,49 +221,152 @@ func (r *BackupRunner) getMaxRetentionPeriodTsForMySQLInstance(ctx context.Conte
 	}
 	maxRetentionPeriodTs := math.MaxInt
 	for _, bs := range backupSettingList {
-		if bs.RetentionPeriodTs != api.BackupRetentionPeriodUnset && bs.RetentionPeriodTs < maxRetentionPeriodTs {
-			maxRetentionPeriodTs = bs.RetentionPeriodTs
+		retentionPeriodTs := getBackupSettingRetentionPeriodTs(bs)
+		if retentionPeriodTs != api.BackupRetentionPeriodUnset && retentionPeriodTs < maxRetentionPeriodTs {
+			maxRetentionPeriodTs = retentionPeriodTs
 		}
 	}
 	return maxRetentionPeriodTs, nil
 }
 
-func (r *BackupRunner) purgeBinlogFiles(ctx context.Context, instanceID, retentionPeriodTs int) error {
+// getBackupSettingRetentionPeriodTs returns the longest retention period among the backup setting and its cron schedules.
+// It returns api.BackupRetentionPeriodUnset if any of them retains the backups forever.
+func getBackupSettingRetentionPeriodTs(bs *api.BackupSetting) int {
+	retentionPeriodTs := bs.RetentionPeriodTs
+	for _, schedule := range bs.ScheduleList {
+		if isLongerRetentionPeriod(schedule.RetentionPeriodTs, retentionPeriodTs) {
+			retentionPeriodTs = schedule.RetentionPeriodTs
+		}
+	}
+	return retentionPeriodTs
+}
+
+func (r *BackupRunner) purgeBinlogFiles(ctx context.Context, instanceID int, expireBefore time.Time) error {
 	binlogDir := getBinlogAbsDir(r.server.profile.DataDir, instanceID)
-	switch r.server.profile.BackupStorageBackend {
-	case api.BackupStorageBackendLocal:
-		return r.purgeBinlogFilesLocal(binlogDir, retentionPeriodTs)
-	case api.BackupStorageBackendS3:
-		return r.purgeBinlogFilesOnCloud(ctx, binlogDir, retentionPeriodTs)
-	default:
-		return errors.Errorf("purge binlog files not implemented for storage backend %s", r.server.profile.BackupStorageBackend)
+	if r.server.profile.BackupStorageBackend == api.BackupStorageBackendLocal {
+		return r.purgeBinlogFilesLocal(binlogDir, expireBefore)
+	}
+	return r.purgeBinlogFilesOnCloud(ctx, binlogDir, expireBefore)
+}
+
+// purgeBaseBackupFiles deletes the base backups started before the time except the newest one,
+// and returns the start time of the oldest kept base backup, or the zero time if there is no base backup.
+func (r *BackupRunner) purgeBaseBackupFiles(ctx context.Context, instanceID int, expireBefore time.Time) (time.Time, error) {
+	baseBackupDir := getBaseBackupRelativeDir(instanceID)
+	if r.server.profile.BackupStorageBackend == api.BackupStorageBackendLocal {
+		baseBackupDirLocal := filepath.Join(r.server.profile.DataDir, baseBackupDir)
+		fileList, err := os.ReadDir(baseBackupDirLocal)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return time.Time{}, nil
+			}
+			return time.Time{}, errors.Wrapf(err, "failed to read base backup directory %q", baseBackupDirLocal)
+		}
+		var nameList []string
+		for _, file := range fileList {
+			nameList = append(nameList, file.Name())
+		}
+		expiredList, oldestStartTime := getExpiredBaseBackupList(nameList, expireBefore)
+		for _, name := range expiredList {
+			baseBackupPath := filepath.Join(baseBackupDirLocal, name)
+			if err := os.Remove(baseBackupPath); err != nil {
+				return time.Time{}, errors.Wrapf(err, "failed to remove the expired base backup file %q", baseBackupPath)
+			}
+			log.Info("Deleted expired base backup file.", zap.String("path", baseBackupPath))
+		}
+		return oldestStartTime, nil
+	}
+
+	client, err := r.server.getBackupStorage(r.server.profile.BackupStorageBackend)
+	if err != nil {
+		return time.Time{}, err
+	}
+	listOutput, err := client.ListObjects(ctx, baseBackupDir)
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "failed to list dir %q in the cloud storage", baseBackupDir)
+	}
+	var nameList []string
+	for _, item := range listOutput {
+		nameList = append(nameList, filepath.Base(item.Key))
+	}
+	expiredList, oldestStartTime := getExpiredBaseBackupList(nameList, expireBefore)
+	var purgePathList []string
+	for _, name := range expiredList {
+		purgePathList = append(purgePathList, filepath.Join(baseBackupDir, name))
+	}
+	if len(purgePathList) > 0 {
+		log.Debug(fmt.Sprintf("Deleting %d expired base backup files in %q from the cloud storage.", len(purgePathList), baseBackupDir))
+		if err := client.DeleteObjects(ctx, purgePathList...); err != nil {
+			return time.Time{}, errors.Wrapf(err, "failed to delete %d expired base backup files in %q from the cloud storage", len(purgePathList), baseBackupDir)
+		}
+	}
+	return oldestStartTime, nil
+}
+
+// getExpiredBaseBackupList returns the names of the base backups started before the time except the newest one,
+// and the start time of the oldest kept base backup. The start time is parsed from the name, and the files with
+// unknown names are kept.
+func getExpiredBaseBackupList(nameList []string, expireBefore time.Time) ([]string, time.Time) {
+	type baseBackup struct {
+		name      string
+		startTime time.Time
+	}
+	var baseBackupList []baseBackup
+	for _, name := range nameList {
+		if !strings.HasSuffix(name, baseBackupFileSuffix) {
+			continue
+		}
+		startTime, err := time.ParseInLocation(baseBackupTimeLayout, strings.TrimSuffix(name, baseBackupFileSuffix), time.Local)
+		if err != nil {
+			continue
+		}
+		baseBackupList = append(baseBackupList, baseBackup{name: name, startTime: startTime})
+	}
+	if len(baseBackupList) == 0 {
+		return nil, time.Time{}
 	}
+	sort.Slice(baseBackupList, func(i, j int) bool {
+		return baseBackupList[i].startTime.Before(baseBackupList[j].startTime)
+	})
+
+	var expiredList []string
+	// The newest base backup is always kept.
+	for _, baseBackup := range baseBackupList[:len(baseBackupList)-1] {
+		if !baseBackup.startTime.Before(expireBefore) {
+			return expiredList, baseBackup.startTime
+		}
+		expiredList = append(expiredList, baseBackup.name)
+	}
+	return expiredList, baseBackupList[len(baseBackupList)-1].startTime
 }
 
-func (r *BackupRunner) purgeBinlogFilesOnCloud(ctx context.Context, binlogDir string, retentionPeriodTs int) error {
-	binlogDirOnCloud := common.GetBinlogRelativeDir(binlogDir)
-	listOutput, err := r.server.s3Client.ListObjects(ctx, binlogDirOnCloud)
+func (r *BackupRunner) purgeBinlogFilesOnCloud(ctx context.Context, binlogDir string, expireBefore time.Time) error {
+	client, err := r.server.getBackupStorage(r.server.profile.BackupStorageBackend)
 	if err != nil {
-		return errors.Wrapf(err, "failed to list binlog dir %q in the cloud storage", binlogDirOnCloud)
+		return err
 	}
-	var purgeBinlogPathList []string
+	dirOnCloud := common.GetBinlogRelativeDir(binlogDir)
+	listOutput, err := client.ListObjects(ctx, dirOnCloud)
+	if err != nil {
+		return errors.Wrapf(err, "failed to list dir %q in the cloud storage", dirOnCloud)
+	}
+	var purgePathList []string
 	for _, item := range listOutput {
-		expireTime := item.LastModified.Add(time.Duration(retentionPeriodTs) * time.Second)
-		if time.Now().After(expireTime) {
-			purgeBinlogPathList = append(purgeBinlogPathList, *item.Key)
+		if item.LastModified.Before(expireBefore) {
+			purgePathList = append(purgePathList, item.Key)
 		}
 	}
-	if len(purgeBinlogPathList) > 0 {
-		log.Debug(fmt.Sprintf("Deleting %d expired binlog files from the cloud storage.", len(purgeBinlogPathList)))
-		if _, err := r.server.s3Client.DeleteObjects(ctx, purgeBinlogPathList...); err != nil {
-			return errors.Wrapf(err, "failed to delete %d expired binlog files from the cloud storage", len(purgeBinlogPathList))
+	if len(purgePathList) > 0 {
+		log.Debug(fmt.Sprintf("Deleting %d expired files in %q from the cloud storage.", len(purgePathList), dirOnCloud))
+		if err := client.DeleteObjects(ctx, purgePathList...); err != nil {
+			return errors.Wrapf(err, "failed to delete %d expired files in %q from the cloud storage", len(purgePathList), dirOnCloud)
 		}
 	}
 	return nil
 }
 
 // TODO(dragonly): Remove metadata as well.
-func (*BackupRunner) purgeBinlogFilesLocal(binlogDir string, retentionPeriodTs int) error {
+func (*BackupRunner) purgeBinlogFilesLocal(binlogDir string, expireBefore time.Time) error {
 	binlogFileInfoList, err := os.ReadDir(binlogDir)
 	if err != nil {
 		return errors.Wrapf(err, "failed to read backup directory %q", binlogDir)
@@ -195,8 +380,7 @@ func (*BackupRunner) purgeBinlogFilesLocal(binlogDir string, retentionPeriodTs i
 			log.Warn("Failed to get file info.", zap.String("path", binlogFileInfo.Name()), zap.Error(err))
 			continue
 		}
-		expireTime := fileInfo.ModTime().Add(time.Duration(retentionPeriodTs) * time.Second)
-		if time.Now().After(expireTime) {
+		if fileInfo.ModTime().Before(expireBefore) {
 			binlogFilePath := path.Join(binlogDir, binlogFileInfo.Name())
 			log.Debug("Deleting expired local binlog file for MySQL instance.", zap.String("path", binlogFilePath))
 			if err := os.Remove(binlogFilePath); err != nil {
@@ -228,10 +412,14 @@ func (r *BackupRunner) purgeBackup(ctx context.Context, backup *api.Backup) erro
 			return errors.Wrapf(err, "failed to delete an expired backup file %q", backupFilePath)
 		}
 		log.Debug(fmt.Sprintf("Deleted expired local backup file %s", backupFilePath))
-	case api.BackupStorageBackendS3:
+	default:
+		client, err := r.server.getBackupStorage(backup.StorageBackend)
+		if err != nil {
+			return err
+		}
 		backupFilePath := getBackupRelativeFilePath(backup.DatabaseID, backup.Name)
-		if _, err := r.server.s3Client.DeleteObjects(ctx, backupFilePath); err != nil {
-			return errors.Wrapf(err, "failed to delete backup file %s in the cloud storage", backupFilePath)
+		if err := client.DeleteObjects(ctx, backupFilePath); err != nil {
+			return errors.Wrapf(err, "failed to delete backup file %q in the cloud storage", backupFilePath)
 		}
 		log.Debug(fmt.Sprintf("Deleted expired backup file %s in the cloud storage", backupFilePath))
 	}
@@ -280,15 +468,208 @@ func (r *BackupRunner) downloadBinlogFilesForInstance(ctx context.Context, insta
 		log.Error("Failed to cast driver to mysql.Driver", zap.String("instance", instance.Name))
 		return
 	}
-	if err := mysqlDriver.FetchAllBinlogFiles(ctx, false /* downloadLatestBinlogFile */, r.server.s3Client); err != nil {
+	if err := mysqlDriver.FetchAllBinlogFiles(ctx, false /* downloadLatestBinlogFile */, r.server.backupStorage); err != nil {
 		log.Error("Failed to download all binlog files for instance", zap.String("instance", instance.Name), zap.Error(err))
 		return
 	}
 }
 
+// archiveWALFiles starts receiving the WAL for the PostgreSQL instances opted in to PITR with at least one database backup enabled,
+// stops receiving the WAL and drops the replication slots for the other instances,
+// and uploads the received WAL files to the cloud storage if configured.
+func (r *BackupRunner) archiveWALFiles(ctx context.Context) {
+	pitrSetting, err := r.server.getPITRSetting(ctx)
+	if err != nil {
+		log.Error("Failed to get PITR setting", zap.Error(err))
+		return
+	}
+	instanceList, err := r.server.store.FindInstanceWithDatabaseBackupEnabled(ctx, db.Postgres)
+	if err != nil {
+		log.Error("Failed to retrieve PostgreSQL instance list with at least one database backup enabled", zap.Error(err))
+		return
+	}
+	instanceSettingMap := make(map[int]*api.PITRInstanceSetting)
+	var archiveInstanceList []*api.Instance
+	for _, instance := range instanceList {
+		if instanceSetting := getPITRInstanceSetting(pitrSetting, instance.ID); instanceSetting != nil {
+			instanceSettingMap[instance.ID] = instanceSetting
+			archiveInstanceList = append(archiveInstanceList, instance)
+		}
+	}
+
+	r.archiveWALMu.Lock()
+	defer r.archiveWALMu.Unlock()
+	// The receivers exit asynchronously after canceled, and they are restarted with the new setting by the next run.
+	for instanceID, receiver := range r.walReceiverMap {
+		if instanceSetting, ok := instanceSettingMap[instanceID]; !ok || instanceSetting.ReplicationSlot != receiver.replicationSlot {
+			receiver.cancel()
+		}
+	}
+	r.dropWALArchiveSlots(ctx, instanceSettingMap)
+	for _, instance := range archiveInstanceList {
+		instanceSetting := instanceSettingMap[instance.ID]
+		if _, ok := r.walReceiverMap[instance.ID]; !ok {
+			receiverCtx, cancel := context.WithCancel(ctx)
+			receiver := &walReceiver{replicationSlot: instanceSetting.ReplicationSlot, cancel: cancel}
+			r.walReceiverMap[instance.ID] = receiver
+			if instanceSetting.ReplicationSlot {
+				r.walSlotInstanceIDs[instance.ID] = true
+			}
+			r.archiveWALWg.Add(1)
+			go r.receiveWALForInstance(receiverCtx, instance, receiver)
+		}
+		if r.server.backupStorage != nil {
+			walDir := getBinlogAbsDir(r.server.profile.DataDir, instance.ID)
+			if err := pg.UploadWALFiles(ctx, r.server.backupStorage, walDir); err != nil {
+				log.Error("Failed to upload WAL files for instance", zap.String("instance", instance.Name), zap.Error(err))
+			}
+		}
+	}
+}
+
+// dropWALArchiveSlots drops the replication slots for archiving the WAL of the instances no longer archiving the WAL with them,
+// e.g., the instance opts out of PITR or the replication slot, all database backups of the instance are disabled, or the instance is archived.
+// The slots are kept while Bytebase is down, and the WAL retained by them is bounded by max_slot_wal_keep_size.
+// The caller must hold archiveWALMu.
+func (r *BackupRunner) dropWALArchiveSlots(ctx context.Context, instanceSettingMap map[int]*api.PITRInstanceSetting) {
+	if !r.walSlotSwept {
+		// The instances may be opted out while Bytebase is down, so we check the slot on all the PostgreSQL instances once.
+		instanceList, err := r.server.store.FindInstance(ctx, &api.InstanceFind{})
+		if err != nil {
+			log.Error("Failed to find instances", zap.Error(err))
+			return
+		}
+		for _, instance := range instanceList {
+			if instance.Engine == db.Postgres {
+				r.walSlotInstanceIDs[instance.ID] = false
+			}
+		}
+		r.walSlotSwept = true
+	}
+
+	for instanceID, hasSlot := range r.walSlotInstanceIDs {
+		if instanceSetting, ok := instanceSettingMap[instanceID]; ok && instanceSetting.ReplicationSlot {
+			continue
+		}
+		if _, ok := r.walReceiverMap[instanceID]; ok {
+			// Wait for the receiver to exit and release the slot.
+			continue
+		}
+		if err := r.dropWALArchiveSlot(ctx, instanceID); err != nil {
+			if hasSlot {
+				log.Warn("Failed to drop the replication slot for archiving the WAL, will retry later", zap.Int("instanceID", instanceID), zap.Error(err))
+				continue
+			}
+			log.Debug("Failed to check the replication slot for archiving the WAL", zap.Int("instanceID", instanceID), zap.Error(err))
+		}
+		delete(r.walSlotInstanceIDs, instanceID)
+	}
+}
+
+func (r *BackupRunner) dropWALArchiveSlot(ctx context.Context, instanceID int) error {
+	instance, err := r.server.store.GetInstanceByID(ctx, instanceID)
+	if err != nil {
+		return err
+	}
+	if instance == nil {
+		return nil
+	}
+	driver, err := r.server.getAdminDatabaseDriver(ctx, instance, "" /* databaseName */)
+	if err != nil {
+		return err
+	}
+	defer driver.Close(ctx)
+	pgDriver, ok := driver.(*pg.Driver)
+	if !ok {
+		return errors.Errorf("[internal] cast driver to pg.Driver failed")
+	}
+	return pgDriver.DropWALArchiveSlot(ctx)
+}
+
+// receiveWALForInstance receives the WAL until the connection is lost, the receiver is canceled or the server stops, and the next run of the backup runner restarts it.
+func (r *BackupRunner) receiveWALForInstance(ctx context.Context, instance *api.Instance, receiver *walReceiver) {
+	defer func() {
+		r.archiveWALMu.Lock()
+		delete(r.walReceiverMap, instance.ID)
+		r.archiveWALMu.Unlock()
+		receiver.cancel()
+		r.archiveWALWg.Done()
+	}()
+	driver, err := r.server.getAdminDatabaseDriver(ctx, instance, "" /* databaseName */)
+	if err != nil {
+		if common.ErrorCode(err) == common.DbConnectionFailure {
+			log.Debug("Cannot connect to instance", zap.String("instance", instance.Name), zap.Error(err))
+			return
+		}
+		log.Error("Failed to get driver for PostgreSQL instance when receiving WAL", zap.String("instance", instance.Name), zap.Error(err))
+		return
+	}
+	defer driver.Close(ctx)
+
+	pgDriver, ok := driver.(*pg.Driver)
+	if !ok {
+		log.Error("Failed to cast driver to pg.Driver", zap.String("instance", instance.Name))
+		return
+	}
+	if err := pgDriver.CheckWALArchivingForPITR(ctx); err != nil {
+		log.Warn("Skip receiving WAL since WAL archiving is not available", zap.String("instance", instance.Name), zap.Error(err))
+		return
+	}
+	if err := pgDriver.ReceiveWAL(ctx, getBinlogAbsDir(r.server.profile.DataDir, instance.ID), receiver.replicationSlot); err != nil {
+		log.Error("Failed to receive WAL for instance", zap.String("instance", instance.Name), zap.Error(err))
+		return
+	}
+}
+
+// getPITRInstanceSetting returns the PITR setting of the instance, or nil if the instance is not opted in to PITR.
+func getPITRInstanceSetting(setting *api.PITRSetting, instanceID int) *api.PITRInstanceSetting {
+	for _, instanceSetting := range setting.InstanceList {
+		if instanceSetting.InstanceID == instanceID {
+			return instanceSetting
+		}
+	}
+	return nil
+}
+
+// parsePITRSetting parses and validates the PITR setting value.
+func parsePITRSetting(value string) (*api.PITRSetting, error) {
+	setting := &api.PITRSetting{}
+	if value != "" {
+		if err := json.Unmarshal([]byte(value), setting); err != nil {
+			return nil, common.Wrapf(err, common.Invalid, "invalid PITR setting")
+		}
+	}
+	instanceIDSet := make(map[int]bool)
+	for _, instanceSetting := range setting.InstanceList {
+		if instanceSetting == nil || instanceSetting.InstanceID <= 0 {
+			return nil, common.Errorf(common.Invalid, "invalid instance in PITR setting")
+		}
+		if instanceIDSet[instanceSetting.InstanceID] {
+			return nil, common.Errorf(common.Invalid, "duplicate instance ID %d in PITR setting", instanceSetting.InstanceID)
+		}
+		instanceIDSet[instanceSetting.InstanceID] = true
+	}
+	return setting, nil
+}
+
+// getPITRSetting returns the PITR setting of the workspace.
+func (s *Server) getPITRSetting(ctx context.Context) (*api.PITRSetting, error) {
+	settingName := api.SettingPITR
+	settingList, err := s.store.FindSetting(ctx, &api.SettingFind{Name: &settingName})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find setting %s", settingName)
+	}
+	value := ""
+	if len(settingList) > 0 {
+		value = settingList[0].Value
+	}
+	return parsePITRSetting(value)
+}
+
 func (r *BackupRunner) startAutoBackups(ctx context.Context, runningTasks map[int]bool, mu *sync.RWMutex) {
+	now := time.Now().UTC()
 	// Find all databases that need a backup in this hour.
-	t := time.Now().UTC().Truncate(time.Hour)
+	t := now.Truncate(time.Hour)
 	match := &api.BackupSettingsMatch{
 		Hour:      t.Hour(),
 		DayOfWeek: int(t.Weekday()),
@@ -298,8 +679,66 @@ func (r *BackupRunner) startAutoBackups(ctx context.Context, runningTasks map[in
 		log.Error("Failed to retrieve backup settings match", zap.Error(err))
 		return
 	}
-
+	autoBackupMap := make(map[int]*autoBackup)
 	for _, backupSetting := range backupSettingList {
+		autoBackupMap[backupSetting.ID] = &autoBackup{
+			setting:           backupSetting,
+			slot:              t,
+			retentionPeriodTs: backupSetting.RetentionPeriodTs,
+		}
+	}
+
+	// Find all databases that need a backup by the cron schedules fired since the last check.
+	// If several schedules of a database fire at the same time, we take one backup with the longest retention period.
+	allBackupSettingList, err := r.server.store.FindBackupSetting(ctx, api.BackupSettingFind{})
+	if err != nil {
+		log.Error("Failed to find all the backup settings.", zap.Error(err))
+		return
+	}
+	for _, backupSetting := range allBackupSettingList {
+		if !backupSetting.Enabled || len(backupSetting.ScheduleList) == 0 {
+			continue
+		}
+		from, err := r.getCronCheckTime(ctx, backupSetting)
+		if err != nil {
+			log.Error("Failed to get the cron check time of backup setting", zap.Int("databaseID", backupSetting.DatabaseID), zap.Error(err))
+			continue
+		}
+		fired := false
+		for _, schedule := range backupSetting.ScheduleList {
+			slot, err := getBackupScheduleLastFireTime(schedule.Cron, from, now)
+			if err != nil {
+				log.Error("Failed to evaluate backup schedule",
+					zap.Int("databaseID", backupSetting.DatabaseID),
+					zap.String("schedule", schedule.Cron),
+					zap.Error(err))
+				continue
+			}
+			if slot.IsZero() {
+				continue
+			}
+			fired = true
+			if ab, ok := autoBackupMap[backupSetting.ID]; ok && !isLongerRetentionPeriod(schedule.RetentionPeriodTs, ab.retentionPeriodTs) {
+				continue
+			}
+			autoBackupMap[backupSetting.ID] = &autoBackup{
+				setting:           backupSetting,
+				schedule:          schedule.Cron,
+				slot:              slot,
+				retentionPeriodTs: schedule.RetentionPeriodTs,
+			}
+		}
+		// The check time is only advanced after the fired schedule is scheduled,
+		// so that the schedules fired while a backup is running are picked up by the later runs.
+		if !fired {
+			r.setCronCheckTime(backupSetting.ID, now)
+			continue
+		}
+		autoBackupMap[backupSetting.ID].cronFired = true
+	}
+
+	for _, ab := range autoBackupMap {
+		backupSetting := ab.setting
 		mu.Lock()
 		if _, ok := runningTasks[backupSetting.ID]; ok {
 			mu.Unlock()
@@ -313,8 +752,9 @@ func (r *BackupRunner) startAutoBackups(ctx context.Context, runningTasks map[in
 			// Skip backup job for wildcard database `*`.
 			continue
 		}
-		backupName := fmt.Sprintf("%s-%s-%s-autobackup", api.ProjectShortSlug(db.Project), api.EnvSlug(db.Instance.Environment), t.Format("20060102T030405"))
-		go func(database *api.Database, backupSettingID int, backupName string, hookURL string) {
+		backupName := fmt.Sprintf("%s-%s-%s-autobackup", api.ProjectShortSlug(db.Project), api.EnvSlug(db.Instance.Environment), ab.slot.Format("20060102T150405"))
+		r.backupWg.Add(1)
+		go func(database *api.Database, backupSettingID int, backupName string, schedule string, hookURL string, cronFired bool) {
 			defer func() {
 				mu.Lock()
 				delete(runningTasks, backupSettingID)
@@ -324,13 +764,17 @@ func (r *BackupRunner) startAutoBackups(ctx context.Context, runningTasks map[in
 			log.Debug("Schedule auto backup",
 				zap.String("database", database.Name),
 				zap.String("backup", backupName),
+				zap.String("schedule", schedule),
 			)
-			if _, err := r.server.scheduleBackupTask(ctx, database, backupName, api.BackupTypeAutomatic, api.SystemBotID); err != nil {
+			if _, err := r.server.scheduleBackupTask(ctx, database, backupName, api.BackupTypeAutomatic, schedule, api.SystemBotID); err != nil {
 				log.Error("Failed to create automatic backup for database",
 					zap.Int("databaseID", database.ID),
 					zap.Error(err))
 				return
 			}
+			if cronFired {
+				r.setCronCheckTime(backupSettingID, now)
+			}
 			// Backup succeeded. POST hook URL.
 			if hookURL == "" {
 				return
@@ -341,12 +785,78 @@ func (r *BackupRunner) startAutoBackups(ctx context.Context, runningTasks map[in
 					zap.Int("databaseID", database.ID),
 					zap.Error(err))
 			}
-		}(db, backupSetting.ID, backupName, backupSetting.HookURL)
-		r.backupWg.Add(1)
+		}(db, backupSetting.ID, backupName, ab.schedule, backupSetting.HookURL, ab.cronFired)
+	}
+}
+
+// getCronCheckTime returns the time since which the fired cron backup schedules of the backup setting are not scheduled yet.
+// The check time is not kept across restarts, so we resume from the latest automatic backup of the database
+// to catch up with the schedules fired during the downtime.
+func (r *BackupRunner) getCronCheckTime(ctx context.Context, backupSetting *api.BackupSetting) (time.Time, error) {
+	r.cronCheckMu.Lock()
+	checkTime, ok := r.cronCheckTimes[backupSetting.ID]
+	r.cronCheckMu.Unlock()
+	if ok {
+		return checkTime, nil
+	}
+
+	// The schedules fired before the last update of the backup setting are discarded.
+	checkTs := backupSetting.UpdatedTs
+	backupList, err := r.server.store.FindBackup(ctx, &api.BackupFind{DatabaseID: &backupSetting.DatabaseID})
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "failed to find backups of database %d", backupSetting.DatabaseID)
+	}
+	for _, backup := range backupList {
+		if backup.Type == api.BackupTypeAutomatic && backup.CreatedTs > checkTs {
+			checkTs = backup.CreatedTs
+		}
+	}
+	checkTime = time.Unix(checkTs, 0).UTC()
+	r.setCronCheckTime(backupSetting.ID, checkTime)
+	return checkTime, nil
+}
+
+func (r *BackupRunner) setCronCheckTime(backupSettingID int, checkTime time.Time) {
+	r.cronCheckMu.Lock()
+	defer r.cronCheckMu.Unlock()
+	if checkTime.After(r.cronCheckTimes[backupSettingID]) {
+		r.cronCheckTimes[backupSettingID] = checkTime
+	}
+}
+
+// autoBackup is an automatic backup to take for a database in this run.
+type autoBackup struct {
+	setting *api.BackupSetting
+	// schedule is the cron expression firing the backup, empty for the hour and day of week schedule.
+	schedule          string
+	slot              time.Time
+	retentionPeriodTs int
+	// cronFired is true if any cron schedule has fired since the last check, even if the backup is taken for another schedule.
+	cronFired bool
+}
+
+// isLongerRetentionPeriod returns true if the retention period a is longer than b, where unset means forever.
+func isLongerRetentionPeriod(a, b int) bool {
+	if b == api.BackupRetentionPeriodUnset {
+		return false
+	}
+	return a == api.BackupRetentionPeriodUnset || a > b
+}
+
+// getBackupScheduleLastFireTime returns the last fire time of the cron expression in (from, to], or the zero time if it does not fire.
+func getBackupScheduleLastFireTime(expr string, from, to time.Time) (time.Time, error) {
+	schedule, err := api.ParseBackupScheduleCron(expr)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var last time.Time
+	for next := schedule.Next(from); !next.IsZero() && !next.After(to); next = schedule.Next(next) {
+		last = next
 	}
+	return last, nil
 }
 
-func (s *Server) scheduleBackupTask(ctx context.Context, database *api.Database, backupName string, backupType api.BackupType, creatorID int) (*api.Backup, error) {
+func (s *Server) scheduleBackupTask(ctx context.Context, database *api.Database, backupName string, backupType api.BackupType, schedule string, creatorID int) (*api.Backup, error) {
 	// Store the migration history version if exists.
 	driver, err := s.getAdminDatabaseDriver(ctx, database.Instance, database.Name)
 	if err != nil {
@@ -383,6 +893,7 @@ func (s *Server) scheduleBackupTask(ctx context.Context, database *api.Database,
 
 	payload := api.TaskDatabaseBackupPayload{
 		BackupID: backupNew.ID,
+		Schedule: schedule,
 	}
 	bytes, err := json.Marshal(payload)
 	if err != nil {
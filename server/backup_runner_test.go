@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bytebase/bytebase/api"
+)
+
+func TestGetBaseBackupRetentionPeriodTs(t *testing.T) {
+	a := require.New(t)
+	day := int((24 * time.Hour).Seconds())
+	shortSetting := &api.BackupSetting{DatabaseID: 1, RetentionPeriodTs: 7 * day}
+	longSetting := &api.BackupSetting{DatabaseID: 2, RetentionPeriodTs: 7 * day, ScheduleList: []api.BackupSchedule{{Cron: "0 0 1 * *", RetentionPeriodTs: 90 * day}}}
+	foreverSetting := &api.BackupSetting{DatabaseID: 3, RetentionPeriodTs: api.BackupRetentionPeriodUnset}
+
+	a.Equal(90*day, getBaseBackupRetentionPeriodTs([]*api.BackupSetting{shortSetting, longSetting}))
+	a.Equal(90*day, getBaseBackupRetentionPeriodTs([]*api.BackupSetting{longSetting, shortSetting}))
+	a.Equal(api.BackupRetentionPeriodUnset, getBaseBackupRetentionPeriodTs([]*api.BackupSetting{shortSetting, foreverSetting, longSetting}))
+}
+
+func TestPurgeBaseBackupFiles(t *testing.T) {
+	a := require.New(t)
+	dataDir := t.TempDir()
+	r := &BackupRunner{
+		server: &Server{
+			profile: Profile{DataDir: dataDir, BackupStorageBackend: api.BackupStorageBackendLocal},
+		},
+	}
+	baseBackupDir := filepath.Join(dataDir, getBaseBackupRelativeDir(1))
+	a.NoError(os.MkdirAll(baseBackupDir, os.ModePerm))
+
+	now := time.Now().Truncate(time.Second)
+	day := 24 * time.Hour
+	startTimeList := []time.Time{now.Add(-100 * day), now.Add(-30 * day), now.Add(-1 * day)}
+	for _, startTime := range startTimeList {
+		a.NoError(os.WriteFile(filepath.Join(dataDir, getBaseBackupRelativeFilePath(1, startTime)), nil, 0600))
+	}
+	a.NoError(os.WriteFile(filepath.Join(baseBackupDir, "unknown"), nil, 0600))
+
+	listBaseBackups := func() []string {
+		fileList, err := os.ReadDir(baseBackupDir)
+		a.NoError(err)
+		var nameList []string
+		for _, file := range fileList {
+			nameList = append(nameList, file.Name())
+		}
+		sort.Strings(nameList)
+		return nameList
+	}
+	baseName := func(startTime time.Time) string {
+		return filepath.Base(getBaseBackupRelativeFilePath(1, startTime))
+	}
+
+	// The databases on the instance retain the backups for 7 days and 90 days, so the base backups are kept for 90 days.
+	day7 := int((7 * day).Seconds())
+	day90 := int((90 * day).Seconds())
+	retentionPeriodTs := getBaseBackupRetentionPeriodTs([]*api.BackupSetting{
+		{DatabaseID: 1, RetentionPeriodTs: day7},
+		{DatabaseID: 2, RetentionPeriodTs: day90},
+	})
+	oldestStartTime, err := r.purgeBaseBackupFiles(context.Background(), 1, now.Add(-time.Duration(retentionPeriodTs)*time.Second))
+	a.NoError(err)
+	a.True(oldestStartTime.Equal(startTimeList[1]))
+	a.Equal([]string{baseName(startTimeList[1]), baseName(startTimeList[2]), "unknown"}, listBaseBackups())
+
+	// The newest base backup is always kept.
+	oldestStartTime, err = r.purgeBaseBackupFiles(context.Background(), 1, now)
+	a.NoError(err)
+	a.True(oldestStartTime.Equal(startTimeList[2]))
+	a.Equal([]string{baseName(startTimeList[2]), "unknown"}, listBaseBackups())
+}
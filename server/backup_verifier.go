@@ -0,0 +1,498 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/common/log"
+	"github.com/bytebase/bytebase/plugin/db"
+	"github.com/bytebase/bytebase/plugin/db/util"
+)
+
+const (
+	// The verifier wakes up regularly and verifies the databases whose last verification is older than the configured interval.
+	backupVerifierInterval = time.Duration(1) * time.Hour
+	// defaultBackupVerificationIntervalTs is the default interval between two verifications of the same database, which is one day.
+	defaultBackupVerificationIntervalTs = 24 * 60 * 60
+	// The recorded row counts are estimated by the database engine, and the data keeps changing after the backup is taken.
+	// So we only report the row count mismatch if the difference exceeds both the relative and the absolute tolerance.
+	backupVerificationRowCountTolerance = 0.2
+	backupVerificationRowCountMinDiff   = 100
+)
+
+// NewBackupVerifier creates a backup verifier.
+func NewBackupVerifier(server *Server) *BackupVerifier {
+	return &BackupVerifier{
+		server:         server,
+		lastVerifiedTs: make(map[int]int64),
+	}
+}
+
+// BackupVerifier is the backup verifier.
+// It test-restores the most recent backup of each database into a scratch database,
+// and compares the restored database with the synced schema and the table stats of the source database.
+type BackupVerifier struct {
+	server *Server
+	// lastVerifiedTs is the last verification time by database ID.
+	lastVerifiedTs map[int]int64
+}
+
+// Run will run the backup verifier.
+func (v *BackupVerifier) Run(ctx context.Context, wg *sync.WaitGroup) {
+	ticker := time.NewTicker(backupVerifierInterval)
+	defer ticker.Stop()
+	defer wg.Done()
+	log.Debug(fmt.Sprintf("Backup verifier started and will run every %v", backupVerifierInterval))
+	for {
+		select {
+		case <-ticker.C:
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						err, ok := r.(error)
+						if !ok {
+							err = errors.Errorf("%v", r)
+						}
+						log.Error("Backup verifier PANIC RECOVER", zap.Error(err), zap.Stack("panic-stack"))
+					}
+				}()
+				v.verifyAllDatabases(ctx)
+			}()
+		case <-ctx.Done(): // if cancel() execute
+			return
+		}
+	}
+}
+
+func (v *BackupVerifier) verifyAllDatabases(ctx context.Context) {
+	setting, err := v.server.getBackupVerificationSetting(ctx)
+	if err != nil {
+		log.Error("Failed to get backup verification setting", zap.Error(err))
+		return
+	}
+	if !setting.Enabled {
+		return
+	}
+	var sandbox *api.Instance
+	if setting.SandboxInstanceID != 0 {
+		sandbox, err = v.server.store.GetInstanceByID(ctx, setting.SandboxInstanceID)
+		if err != nil {
+			log.Error("Failed to find the backup verification sandbox instance", zap.Int("instanceID", setting.SandboxInstanceID), zap.Error(err))
+			return
+		}
+		if sandbox == nil || sandbox.RowStatus != api.Normal {
+			log.Error("The backup verification sandbox instance is not found or archived", zap.Int("instanceID", setting.SandboxInstanceID))
+			return
+		}
+	}
+
+	rowStatus := api.Normal
+	instanceList, err := v.server.store.FindInstance(ctx, &api.InstanceFind{RowStatus: &rowStatus})
+	if err != nil {
+		log.Error("Failed to retrieve instance list", zap.Error(err))
+		return
+	}
+	for _, instance := range instanceList {
+		if !isBackupVerificationSupported(instance.Engine) {
+			continue
+		}
+		target := instance
+		if sandbox != nil {
+			// Only the databases with the same engine as the sandbox instance are verified.
+			if sandbox.Engine != instance.Engine {
+				continue
+			}
+			target = sandbox
+		}
+		dbList, err := v.server.store.FindDatabase(ctx, &api.DatabaseFind{InstanceID: &instance.ID})
+		if err != nil {
+			log.Error("Failed to retrieve database list", zap.String("instance", instance.Name), zap.Error(err))
+			continue
+		}
+		for _, database := range dbList {
+			if ctx.Err() != nil {
+				return
+			}
+			if time.Now().Unix()-v.lastVerifiedTs[database.ID] < int64(setting.IntervalTs) {
+				continue
+			}
+			// Do NOT use go-routine, the test restore is expensive and we verify the backups one by one.
+			v.verifyDatabase(ctx, instance, target, database)
+		}
+	}
+}
+
+func (v *BackupVerifier) verifyDatabase(ctx context.Context, instance, target *api.Instance, database *api.Database) {
+	normalStatus := api.Normal
+	doneStatus := api.BackupStatusDone
+	backupList, err := v.server.store.FindBackup(ctx, &api.BackupFind{
+		DatabaseID: &database.ID,
+		RowStatus:  &normalStatus,
+		Status:     &doneStatus,
+	})
+	if err != nil {
+		log.Error("Failed to retrieve backup list",
+			zap.String("instance", instance.Name),
+			zap.String("database", database.Name),
+			zap.Error(err))
+		return
+	}
+	if len(backupList) == 0 {
+		return
+	}
+	// The backup list is ordered by the updated time in descending order.
+	backup := backupList[0]
+	v.lastVerifiedTs[database.ID] = time.Now().Unix()
+
+	log.Debug("Verify backup",
+		zap.String("instance", instance.Name),
+		zap.String("database", database.Name),
+		zap.String("backup", backup.Name),
+		zap.String("target", target.Name))
+	verifyErr := v.verifyBackup(ctx, target, database, backup)
+	if verifyErr == nil {
+		if err := v.server.store.ArchiveAnomaly(ctx, &api.AnomalyArchive{
+			DatabaseID: &database.ID,
+			Type:       api.AnomalyDatabaseBackupVerificationFailed,
+		}); err != nil && common.ErrorCode(err) != common.NotFound {
+			log.Error("Failed to close anomaly",
+				zap.String("instance", instance.Name),
+				zap.String("database", database.Name),
+				zap.String("type", string(api.AnomalyDatabaseBackupVerificationFailed)),
+				zap.Error(err))
+		}
+		return
+	}
+	if ctx.Err() != nil {
+		// The server is shutting down, it's not a verification failure.
+		return
+	}
+
+	log.Warn("Backup verification failed",
+		zap.String("instance", instance.Name),
+		zap.String("database", database.Name),
+		zap.String("backup", backup.Name),
+		zap.Error(verifyErr))
+	anomalyPayload := api.AnomalyDatabaseBackupVerificationFailedPayload{
+		BackupID:   backup.ID,
+		BackupName: backup.Name,
+		Detail:     verifyErr.Error(),
+	}
+	payload, err := json.Marshal(anomalyPayload)
+	if err != nil {
+		log.Error("Failed to marshal anomaly payload",
+			zap.String("instance", instance.Name),
+			zap.String("database", database.Name),
+			zap.String("type", string(api.AnomalyDatabaseBackupVerificationFailed)),
+			zap.Error(err))
+		return
+	}
+	// The verifier retries the failed backup on every run, so we only notify when the failure is new or the backup has changed.
+	notified, err := v.isBackupVerificationFailureNotified(ctx, database, backup)
+	if err != nil {
+		log.Error("Failed to find anomaly",
+			zap.String("instance", instance.Name),
+			zap.String("database", database.Name),
+			zap.String("type", string(api.AnomalyDatabaseBackupVerificationFailed)),
+			zap.Error(err))
+	}
+	if _, err := v.server.store.UpsertActiveAnomaly(ctx, &api.AnomalyUpsert{
+		CreatorID:  api.SystemBotID,
+		InstanceID: instance.ID,
+		DatabaseID: &database.ID,
+		Type:       api.AnomalyDatabaseBackupVerificationFailed,
+		Payload:    string(payload),
+	}); err != nil {
+		log.Error("Failed to create anomaly",
+			zap.String("instance", instance.Name),
+			zap.String("database", database.Name),
+			zap.String("type", string(api.AnomalyDatabaseBackupVerificationFailed)),
+			zap.Error(err))
+	}
+	if notified {
+		return
+	}
+
+	activityPayload, err := json.Marshal(api.ActivityDatabaseBackupVerificationFailedPayload{
+		DatabaseID:   database.ID,
+		BackupID:     backup.ID,
+		DatabaseName: database.Name,
+		BackupName:   backup.Name,
+	})
+	if err != nil {
+		log.Error("Failed to marshal activity payload", zap.String("database", database.Name), zap.Error(err))
+		return
+	}
+	activityCreate := &api.ActivityCreate{
+		CreatorID:   api.SystemBotID,
+		ContainerID: database.ProjectID,
+		Type:        api.ActivityDatabaseBackupVerificationFailed,
+		Level:       api.ActivityError,
+		Payload:     string(activityPayload),
+		Comment:     fmt.Sprintf("Failed to verify backup %q of database %q in instance %q: %s", backup.Name, database.Name, instance.Name, verifyErr.Error()),
+	}
+	if _, err := v.server.ActivityManager.CreateActivity(ctx, activityCreate, &ActivityMeta{database: database}); err != nil {
+		log.Error("Failed to create backup verification activity", zap.String("database", database.Name), zap.Error(err))
+	}
+}
+
+// isBackupVerificationFailureNotified returns true if the active backup verification failed anomaly of the database is on the same backup,
+// whose activity has been created when the anomaly was created.
+func (v *BackupVerifier) isBackupVerificationFailureNotified(ctx context.Context, database *api.Database, backup *api.Backup) (bool, error) {
+	normalStatus := api.Normal
+	anomalyType := api.AnomalyDatabaseBackupVerificationFailed
+	anomalyList, err := v.server.store.FindAnomaly(ctx, &api.AnomalyFind{
+		RowStatus:  &normalStatus,
+		DatabaseID: &database.ID,
+		Type:       &anomalyType,
+	})
+	if err != nil {
+		return false, err
+	}
+	for _, anomaly := range anomalyList {
+		var payload api.AnomalyDatabaseBackupVerificationFailedPayload
+		if err := json.Unmarshal([]byte(anomaly.Payload), &payload); err != nil {
+			return false, errors.Wrapf(err, "failed to unmarshal anomaly payload %q", anomaly.Payload)
+		}
+		if payload.BackupID == backup.ID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// verifyBackup restores the backup into a scratch database on the target instance, compares it with the source database
+// and drops the scratch database at last.
+func (v *BackupVerifier) verifyBackup(ctx context.Context, target *api.Instance, database *api.Database, backup *api.Backup) error {
+	driver, err := v.server.getAdminDatabaseDriver(ctx, target, "")
+	if err != nil {
+		return errors.Wrapf(err, "failed to connect instance %q", target.Name)
+	}
+	defer driver.Close(ctx)
+	conn, err := driver.GetDBConnection(ctx, db.BytebaseDatabase)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get connection for instance %q", target.Name)
+	}
+
+	scratchDatabaseName := util.GetBackupVerificationDatabaseName(database.Name, time.Now().Unix())
+	quotedName := quoteBackupVerificationIdentifier(target.Engine, scratchDatabaseName)
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("CREATE DATABASE %s;", quotedName)); err != nil {
+		return errors.Wrapf(err, "failed to create the scratch database %q", scratchDatabaseName)
+	}
+	defer func() {
+		// Use a new context so that the scratch database is dropped even if the server is shutting down.
+		if _, err := conn.ExecContext(context.Background(), fmt.Sprintf("DROP DATABASE IF EXISTS %s;", quotedName)); err != nil {
+			log.Error("Failed to drop the backup verification scratch database",
+				zap.String("instance", target.Name),
+				zap.String("database", scratchDatabaseName),
+				zap.Error(err))
+		}
+	}()
+
+	schema, rowCounts, err := v.restoreScratchDatabase(ctx, target, scratchDatabaseName, backup)
+	if err != nil {
+		return err
+	}
+
+	tableList, err := v.server.store.FindTable(ctx, &api.TableFind{DatabaseID: &database.ID})
+	if err != nil {
+		return errors.Wrapf(err, "failed to find tables of database %q", database.Name)
+	}
+	columnList, err := v.server.store.FindColumn(ctx, &api.ColumnFind{DatabaseID: &database.ID})
+	if err != nil {
+		return errors.Wrapf(err, "failed to find columns of database %q", database.Name)
+	}
+	// The schema may be changed after the backup is taken, so we only compare the schema if the schema version is unchanged.
+	compareSchema := backup.MigrationHistoryVersion == database.SchemaVersion
+	if mismatchList := compareRestoredDatabase(tableList, columnList, schema, rowCounts, compareSchema); len(mismatchList) > 0 {
+		return errors.Errorf("the restored backup doesn't match the database: %s", strings.Join(mismatchList, "; "))
+	}
+	return nil
+}
+
+// restoreScratchDatabase restores the backup into the scratch database, and returns the restored schema and the row count of each table.
+func (v *BackupVerifier) restoreScratchDatabase(ctx context.Context, target *api.Instance, scratchDatabaseName string, backup *api.Backup) (*db.Schema, map[string]int64, error) {
+	driver, err := v.server.getAdminDatabaseDriver(ctx, target, scratchDatabaseName)
+	if err != nil {
+		return nil, nil, err
+	}
+	// The connections must be closed before dropping the scratch database in Postgres.
+	defer driver.Close(ctx)
+
+	backupAbsPathLocal := filepath.Join(v.server.profile.DataDir, backup.Path)
+	if backup.StorageBackend != api.BackupStorageBackendLocal {
+		client, err := v.server.getBackupStorage(backup.StorageBackend)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := downloadBackupFileFromCloud(ctx, client, backup.Path, backupAbsPathLocal); err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to download backup %q from %s", backup.Path, backup.StorageBackend)
+		}
+		defer os.Remove(backupAbsPathLocal)
+	}
+	backupFile, err := os.Open(backupAbsPathLocal)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to open backup file at %s", backupAbsPathLocal)
+	}
+	defer backupFile.Close()
+	backupReader, err := v.server.newBackupArtifactReader(ctx, backupFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer backupReader.Close()
+	if err := driver.Restore(ctx, backupReader); err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to restore backup %q", backup.Name)
+	}
+
+	schema, err := driver.SyncDBSchema(ctx, scratchDatabaseName)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to sync the schema of the scratch database %q", scratchDatabaseName)
+	}
+	conn, err := driver.GetDBConnection(ctx, scratchDatabaseName)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to get connection for the scratch database %q", scratchDatabaseName)
+	}
+	// The row counts in the synced schema are estimated, so we count the rows exactly.
+	rowCounts := make(map[string]int64)
+	for _, table := range schema.TableList {
+		var count int64
+		query := fmt.Sprintf("SELECT COUNT(*) FROM %s;", quoteBackupVerificationIdentifier(target.Engine, table.Name))
+		if err := conn.QueryRowContext(ctx, query).Scan(&count); err != nil {
+			return nil, nil, util.FormatErrorWithQuery(err, query)
+		}
+		rowCounts[table.Name] = count
+	}
+	return schema, rowCounts, nil
+}
+
+// compareRestoredDatabase compares the restored database with the synced tables and columns of the source database, and returns the mismatches.
+// If compareSchema is false, only the row counts of the tables existing in both databases are compared.
+func compareRestoredDatabase(tableList []*api.Table, columnList []*api.Column, schema *db.Schema, rowCounts map[string]int64, compareSchema bool) []string {
+	var mismatchList []string
+	restoredTableMap := make(map[string]db.Table)
+	for _, table := range schema.TableList {
+		restoredTableMap[table.Name] = table
+	}
+	tableColumnMap := make(map[int][]*api.Column)
+	for _, column := range columnList {
+		tableColumnMap[column.TableID] = append(tableColumnMap[column.TableID], column)
+	}
+
+	sort.Slice(tableList, func(i, j int) bool {
+		return tableList[i].Name < tableList[j].Name
+	})
+	tableMap := make(map[string]bool)
+	for _, table := range tableList {
+		tableMap[table.Name] = true
+		restoredTable, ok := restoredTableMap[table.Name]
+		if !ok {
+			if compareSchema {
+				mismatchList = append(mismatchList, fmt.Sprintf("table %q is missing", table.Name))
+			}
+			continue
+		}
+		if compareSchema {
+			restoredColumnMap := make(map[string]db.Column)
+			for _, column := range restoredTable.ColumnList {
+				restoredColumnMap[column.Name] = column
+			}
+			for _, column := range tableColumnMap[table.ID] {
+				restoredColumn, ok := restoredColumnMap[column.Name]
+				if !ok {
+					mismatchList = append(mismatchList, fmt.Sprintf("column %q of table %q is missing", column.Name, table.Name))
+					continue
+				}
+				if restoredColumn.Type != column.Type {
+					mismatchList = append(mismatchList, fmt.Sprintf("column %q of table %q has type %q, expected %q", column.Name, table.Name, restoredColumn.Type, column.Type))
+				}
+			}
+		}
+		if isRowCountMismatch(table.RowCount, rowCounts[table.Name]) {
+			mismatchList = append(mismatchList, fmt.Sprintf("table %q has %d rows, expected about %d rows", table.Name, rowCounts[table.Name], table.RowCount))
+		}
+	}
+	if compareSchema {
+		var unexpectedTableList []string
+		for name := range restoredTableMap {
+			if !tableMap[name] {
+				unexpectedTableList = append(unexpectedTableList, name)
+			}
+		}
+		sort.Strings(unexpectedTableList)
+		for _, name := range unexpectedTableList {
+			mismatchList = append(mismatchList, fmt.Sprintf("table %q is unexpected", name))
+		}
+	}
+	return mismatchList
+}
+
+func isRowCountMismatch(expected, actual int64) bool {
+	diff := expected - actual
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff > backupVerificationRowCountMinDiff && float64(diff) > backupVerificationRowCountTolerance*float64(expected)
+}
+
+func isBackupVerificationSupported(engine db.Type) bool {
+	switch engine {
+	case db.MySQL, db.TiDB, db.Postgres:
+		return true
+	}
+	return false
+}
+
+// quoteBackupVerificationIdentifier quotes the database or table name, and the Postgres table name is in the form of "schema.table".
+func quoteBackupVerificationIdentifier(engine db.Type, name string) string {
+	if engine == db.Postgres {
+		var parts []string
+		for _, part := range strings.SplitN(name, ".", 2) {
+			parts = append(parts, fmt.Sprintf(`"%s"`, strings.ReplaceAll(part, `"`, `""`)))
+		}
+		return strings.Join(parts, ".")
+	}
+	return fmt.Sprintf("`%s`", strings.ReplaceAll(name, "`", "``"))
+}
+
+// parseBackupVerificationSetting parses and validates the backup verification setting value.
+func parseBackupVerificationSetting(value string) (*api.BackupVerificationSetting, error) {
+	setting := &api.BackupVerificationSetting{}
+	if value != "" {
+		if err := json.Unmarshal([]byte(value), setting); err != nil {
+			return nil, common.Wrapf(err, common.Invalid, "invalid backup verification setting")
+		}
+	}
+	if setting.IntervalTs < 0 {
+		return nil, common.Errorf(common.Invalid, "invalid backup verification interval %d", setting.IntervalTs)
+	}
+	if setting.IntervalTs == 0 {
+		setting.IntervalTs = defaultBackupVerificationIntervalTs
+	}
+	return setting, nil
+}
+
+// getBackupVerificationSetting returns the backup verification setting of the workspace.
+func (s *Server) getBackupVerificationSetting(ctx context.Context) (*api.BackupVerificationSetting, error) {
+	settingName := api.SettingBackupVerification
+	settingList, err := s.store.FindSetting(ctx, &api.SettingFind{Name: &settingName})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find setting %s", settingName)
+	}
+	value := ""
+	if len(settingList) > 0 {
+		value = settingList[0].Value
+	}
+	return parseBackupVerificationSetting(value)
+}
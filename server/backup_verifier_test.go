@@ -0,0 +1,86 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/plugin/db"
+)
+
+func TestCompareRestoredDatabase(t *testing.T) {
+	tableList := []*api.Table{
+		{ID: 1, Name: "user", RowCount: 1000},
+		{ID: 2, Name: "order", RowCount: 50},
+	}
+	columnList := []*api.Column{
+		{TableID: 1, Name: "id", Type: "int"},
+		{TableID: 1, Name: "name", Type: "varchar(255)"},
+		{TableID: 2, Name: "id", Type: "int"},
+	}
+	tests := []struct {
+		name          string
+		schema        *db.Schema
+		rowCounts     map[string]int64
+		compareSchema bool
+		want          []string
+	}{
+		{
+			name: "match",
+			schema: &db.Schema{TableList: []db.Table{
+				{Name: "user", ColumnList: []db.Column{{Name: "id", Type: "int"}, {Name: "name", Type: "varchar(255)"}}},
+				{Name: "order", ColumnList: []db.Column{{Name: "id", Type: "int"}}},
+			}},
+			// The recorded row counts are estimated.
+			rowCounts:     map[string]int64{"user": 950, "order": 0},
+			compareSchema: true,
+			want:          nil,
+		},
+		{
+			name: "schemaMismatch",
+			schema: &db.Schema{TableList: []db.Table{
+				{Name: "user", ColumnList: []db.Column{{Name: "id", Type: "bigint"}}},
+				{Name: "product", ColumnList: []db.Column{{Name: "id", Type: "int"}}},
+			}},
+			rowCounts:     map[string]int64{"user": 1000, "product": 10},
+			compareSchema: true,
+			want: []string{
+				`table "order" is missing`,
+				`column "id" of table "user" has type "bigint", expected "int"`,
+				`column "name" of table "user" is missing`,
+				`table "product" is unexpected`,
+			},
+		},
+		{
+			name: "rowCountMismatch",
+			schema: &db.Schema{TableList: []db.Table{
+				{Name: "user", ColumnList: []db.Column{{Name: "id", Type: "int"}, {Name: "name", Type: "varchar(255)"}}},
+				{Name: "order", ColumnList: []db.Column{{Name: "id", Type: "int"}}},
+			}},
+			rowCounts:     map[string]int64{"user": 0, "order": 50},
+			compareSchema: true,
+			want: []string{
+				`table "user" has 0 rows, expected about 1000 rows`,
+			},
+		},
+		{
+			name: "schemaChangedAfterBackup",
+			schema: &db.Schema{TableList: []db.Table{
+				{Name: "user", ColumnList: []db.Column{{Name: "id", Type: "int"}}},
+			}},
+			rowCounts:     map[string]int64{"user": 10},
+			compareSchema: false,
+			want: []string{
+				`table "user" has 10 rows, expected about 1000 rows`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := compareRestoredDatabase(tableList, columnList, test.schema, test.rowCounts, test.compareSchema)
+			require.Equal(t, test.want, got)
+		})
+	}
+}
@@ -51,9 +51,12 @@ type Profile struct {
 	BackupStorageBackend api.BackupStorageBackend
 
 	// Cloud backup related fields
-	BackupRegion         string
+	BackupRegion string
+	// BackupBucket is the bucket for S3 and GCS, the container for Azure Blob Storage, or the directory for the file system backend.
 	BackupBucket         string
 	BackupCredentialFile string
+	// BackupEndpoint is the optional endpoint of the S3 compatible service, e.g., MinIO.
+	BackupEndpoint string
 
 	// Version is the bytebase's version
 	Version string
@@ -22,9 +22,24 @@ func isMatchExpression(labels map[string]string, expression *api.LabelSelectorRe
 			}
 		}
 		return false
+	case api.NotInOperatorType:
+		// Databases without the label key also match NotIn, which is the same as Kubernetes.
+		value, ok := labels[expression.Key]
+		if !ok {
+			return true
+		}
+		for _, exprValue := range expression.Values {
+			if exprValue == value {
+				return false
+			}
+		}
+		return true
 	case api.ExistsOperatorType:
 		_, ok := labels[expression.Key]
 		return ok
+	case api.DoesNotExistOperatorType:
+		_, ok := labels[expression.Key]
+		return !ok
 	default:
 		return false
 	}
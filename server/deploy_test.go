@@ -241,6 +241,96 @@ func TestGetDatabaseMatrixFromDeploymentSchedule(t *testing.T) {
 				{dbs[5], dbs[6]},
 			},
 		},
+		{
+			"notInOperator",
+			&api.DeploymentSchedule{
+				Deployments: []*api.Deployment{
+					{
+						Spec: &api.DeploymentSpec{
+							Selector: &api.LabelSelector{
+								MatchExpressions: []*api.LabelSelectorRequirement{
+									{
+										Key:      "bb.location",
+										Operator: "NotIn",
+										Values:   []string{"earth"},
+									},
+								},
+							},
+						},
+					},
+					{
+						Spec: &api.DeploymentSpec{
+							Selector: &api.LabelSelector{
+								MatchExpressions: []*api.LabelSelectorRequirement{
+									{
+										Key:      "bb.tenant",
+										Operator: "NotIn",
+										Values:   []string{"bytebase"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"hello",
+			"{{DB_NAME}}",
+			[]*api.Database{
+				dbs[0], dbs[1], dbs[2], dbs[3],
+			},
+			[][]*api.Database{
+				{dbs[0], dbs[2]},
+				// Databases without the label key also match NotIn.
+				{dbs[3]},
+			},
+		},
+		{
+			"doesNotExistOperator",
+			&api.DeploymentSchedule{
+				Deployments: []*api.Deployment{
+					{
+						Spec: &api.DeploymentSpec{
+							Selector: &api.LabelSelector{
+								MatchExpressions: []*api.LabelSelectorRequirement{
+									{
+										Key:      "bb.tenant",
+										Operator: "DoesNotExist",
+										Values:   nil,
+									},
+								},
+							},
+						},
+					},
+					{
+						Spec: &api.DeploymentSpec{
+							Selector: &api.LabelSelector{
+								MatchExpressions: []*api.LabelSelectorRequirement{
+									{
+										Key:      "bb.tenant",
+										Operator: "Exists",
+										Values:   nil,
+									},
+									{
+										Key:      "bb.location",
+										Operator: "NotIn",
+										Values:   []string{"us-central1"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"hello",
+			"{{DB_NAME}}",
+			[]*api.Database{
+				dbs[0], dbs[1], dbs[2], dbs[3],
+			},
+			[][]*api.Database{
+				{dbs[3]},
+				{dbs[1], dbs[2]},
+			},
+		},
 	}
 
 	for _, test := range tests {
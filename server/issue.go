@@ -30,6 +30,9 @@ func (s *Server) registerIssueRoutes(g *echo.Group) {
 		if err := jsonapi.UnmarshalPayload(c.Request().Body, issueCreate); err != nil {
 			return echo.NewHTTPError(http.StatusBadRequest, "Malformed create issue request").SetInternal(err)
 		}
+		if err := validateIssuePayloadFromClient(nil /* issue */, issueCreate.Payload); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
 
 		issue, err := s.createIssue(ctx, issueCreate, c.Get(getPrincipalIDContextKey()).(int))
 		if err != nil {
@@ -184,6 +187,11 @@ func (s *Server) registerIssueRoutes(g *echo.Group) {
 		if issue == nil {
 			return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Unable to find issue ID to update: %d", id))
 		}
+		if issuePatch.Payload != nil {
+			if err := validateIssuePayloadFromClient(issue, *issuePatch.Payload); err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+			}
+		}
 
 		if issuePatch.AssigneeID != nil {
 			stage := getActiveStage(issue.Pipeline.StageList)
@@ -818,6 +826,9 @@ func (s *Server) getPipelineCreateForDatabaseSchemaUpdateGhost(ctx context.Conte
 		if database == nil {
 			return nil, echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("database ID not found: %d", detail.DatabaseID))
 		}
+		if database.Instance.Engine != db.MySQL && database.Instance.Engine != db.Postgres {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("online schema change doesn't support database type %s", database.Instance.Engine))
+		}
 
 		taskCreateList, taskIndexDAGList, err := createGhostTaskList(database, c.VCSPushEvent, detail, schemaVersion)
 		if err != nil {
@@ -1109,6 +1120,17 @@ func checkCharacterSetCollationOwner(dbType db.Type, characterSet, collation, ow
 		if collation != "" {
 			return errors.Errorf("Snowflake does not support collation, but got %s", collation)
 		}
+	case db.MSSQL:
+		// SQL Server uses the instance default collation if the collation is not specified.
+		if characterSet != "" {
+			return errors.Errorf("SQL Server does not support character set, but got %s", characterSet)
+		}
+	case db.MongoDB:
+		// MongoDB creates the database implicitly on the first write, so there is no statement to create an empty database.
+		return errors.Errorf("creating database is not supported for MongoDB, the database is created when the first collection is created")
+	case db.Oracle:
+		// Databases are schemas (users) in Oracle, which require the privileges and tablespace settings out of the scope of Bytebase.
+		return errors.Errorf("creating database is not supported for Oracle, please create the schema (user) in Oracle directly")
 	case db.Postgres:
 		if owner == "" {
 			return errors.Errorf("database owner is required for PostgreSQL")
@@ -1181,6 +1203,16 @@ func getDatabaseNameAndStatement(dbType db.Type, createDatabaseContext api.Creat
 		if schema != "" {
 			stmt = fmt.Sprintf("%s\nUSE DATABASE %s;\n%s", stmt, databaseName, schema)
 		}
+	case db.MSSQL:
+		stmt = fmt.Sprintf("CREATE DATABASE [%s]", databaseName)
+		if createDatabaseContext.Collation != "" {
+			stmt = fmt.Sprintf("%s COLLATE %s", stmt, createDatabaseContext.Collation)
+		}
+		stmt += ";"
+		if schema != "" {
+			// The schema dumped from SQL Server is separated by the "GO" batch separator.
+			stmt = fmt.Sprintf("%s\nGO\nUSE [%s];\nGO\n%s", stmt, databaseName, schema)
+		}
 	case db.SQLite:
 		// This is a fake CREATE DATABASE and USE statement since a single SQLite file represents a database. Engine driver will recognize it and establish a connection to create the sqlite file representing the database.
 		stmt = fmt.Sprintf("CREATE DATABASE '%s';", databaseName)
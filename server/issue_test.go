@@ -115,6 +115,38 @@ func TestCheckCharacterSetCollationOwner(t *testing.T) {
 			expectError: false,
 		},
 
+		/* SQL Server */
+		// With character set
+		{
+			dbType:       db.MSSQL,
+			characterSet: "utf8mb4",
+			expectError:  true,
+		},
+		// Normal
+		{
+			dbType:      db.MSSQL,
+			collation:   "SQL_Latin1_General_CP1_CI_AS",
+			expectError: false,
+		},
+		{
+			dbType:      db.MSSQL,
+			expectError: false,
+		},
+
+		/* MongoDB */
+		// Creating database is not supported
+		{
+			dbType:      db.MongoDB,
+			expectError: true,
+		},
+
+		/* Oracle */
+		// Creating database is not supported
+		{
+			dbType:      db.Oracle,
+			expectError: true,
+		},
+
 		/* PostgreSQL */
 		// Without owner
 		{
@@ -166,3 +198,31 @@ func TestCheckCharacterSetCollationOwner(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateIssuePayloadFromClient(t *testing.T) {
+	pullRequestIssue := &api.Issue{
+		Name:    "pull request issue",
+		Payload: `{"vcsPullRequest":{"repositoryId":1,"pullRequestId":"7","url":"https://github.com/a/b/pull/7","draft":true}}`,
+	}
+	tests := []struct {
+		issue   *api.Issue
+		payload string
+		wantErr bool
+	}{
+		{issue: nil, payload: "", wantErr: false},
+		{issue: nil, payload: `{"foo":"bar"}`, wantErr: false},
+		{issue: nil, payload: `{"vcsPullRequest":{"draft":false}}`, wantErr: true},
+		{issue: &api.Issue{Payload: "{}"}, payload: `{"vcsPullRequest":{"draft":true}}`, wantErr: true},
+		{issue: pullRequestIssue, payload: `{"vcsPullRequest":{"draft":false}}`, wantErr: true},
+		// The payload can't drop the pull request either.
+		{issue: pullRequestIssue, payload: "{}", wantErr: true},
+	}
+	for _, test := range tests {
+		err := validateIssuePayloadFromClient(test.issue, test.payload)
+		if test.wantErr {
+			require.Error(t, err, test.payload)
+		} else {
+			require.NoError(t, err, test.payload)
+		}
+	}
+}
@@ -0,0 +1,531 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/common/log"
+	"github.com/bytebase/bytebase/plugin/ldap"
+)
+
+const (
+	// The syncer wakes up regularly and syncs the LDAP groups if the last sync is older than the configured interval.
+	ldapGroupSyncerInterval = time.Duration(1) * time.Minute
+	// defaultLDAPGroupSyncIntervalTs is the default interval between two group syncs, which is one hour.
+	defaultLDAPGroupSyncIntervalTs = 60 * 60
+)
+
+// workspaceRolePrecedence is the precedence of the workspace roles if a user is in multiple mapped groups.
+var workspaceRolePrecedence = map[api.Role]int{
+	api.Developer: 1,
+	api.DBA:       2,
+	api.Owner:     3,
+}
+
+// NewLDAPGroupSyncer creates a LDAP group syncer.
+func NewLDAPGroupSyncer(server *Server) *LDAPGroupSyncer {
+	return &LDAPGroupSyncer{
+		server: server,
+	}
+}
+
+// LDAPGroupSyncer is the LDAP group syncer.
+// It grants the workspace roles and the project memberships to the members of the mapped LDAP groups,
+// and creates the principals for the members who have never logged in.
+type LDAPGroupSyncer struct {
+	server     *Server
+	lastSyncTs int64
+}
+
+// Run will run the LDAP group syncer.
+func (g *LDAPGroupSyncer) Run(ctx context.Context, wg *sync.WaitGroup) {
+	ticker := time.NewTicker(ldapGroupSyncerInterval)
+	defer ticker.Stop()
+	defer wg.Done()
+	log.Debug(fmt.Sprintf("LDAP group syncer started and will run every %v", ldapGroupSyncerInterval))
+	for {
+		select {
+		case <-ticker.C:
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						err, ok := r.(error)
+						if !ok {
+							err = errors.Errorf("%v", r)
+						}
+						log.Error("LDAP group syncer PANIC RECOVER", zap.Error(err), zap.Stack("panic-stack"))
+					}
+				}()
+				g.syncGroups(ctx)
+			}()
+		case <-ctx.Done(): // if cancel() execute
+			return
+		}
+	}
+}
+
+func (g *LDAPGroupSyncer) syncGroups(ctx context.Context) {
+	if !g.server.flight(api.FeatureLDAP) || !g.server.feature(api.FeatureLDAP) {
+		return
+	}
+	setting, err := g.server.getLDAPSetting(ctx)
+	if err != nil {
+		log.Error("Failed to get LDAP setting", zap.Error(err))
+		return
+	}
+	if !setting.Enabled || (len(setting.RoleMappingList) == 0 && len(setting.ProjectMappingList) == 0) {
+		return
+	}
+	if time.Now().Unix()-g.lastSyncTs < int64(setting.GroupSyncIntervalTs) {
+		return
+	}
+	g.lastSyncTs = time.Now().Unix()
+
+	client, err := ldap.NewClient(getLDAPConfig(setting))
+	if err != nil {
+		log.Error("Failed to connect to LDAP server", zap.String("host", setting.Host), zap.Error(err))
+		return
+	}
+	defer client.Close()
+
+	// The groups failed to list are absent from the map, and the mappings referring to them are skipped,
+	// so that a transient failure doesn't revoke the roles of their members.
+	groupMemberMap := make(map[string][]*ldap.User)
+	var groupDNList []string
+	for _, mapping := range setting.RoleMappingList {
+		groupDNList = append(groupDNList, mapping.GroupDN)
+	}
+	for _, mapping := range setting.ProjectMappingList {
+		groupDNList = append(groupDNList, mapping.GroupDN)
+	}
+	for _, groupDN := range groupDNList {
+		if _, ok := groupMemberMap[groupDN]; ok {
+			continue
+		}
+		userList, err := client.ListGroupMember(groupDN)
+		if err != nil {
+			log.Error("Failed to list LDAP group members", zap.String("group", groupDN), zap.Error(err))
+			continue
+		}
+		groupMemberMap[groupDN] = userList
+	}
+
+	// We only lower the roles of the LDAP principals in none of the mapped groups if all the mapped groups are listed.
+	roleMappingListed := len(setting.RoleMappingList) > 0
+	for _, mapping := range setting.RoleMappingList {
+		if _, ok := groupMemberMap[mapping.GroupDN]; !ok {
+			roleMappingListed = false
+		}
+	}
+	g.syncWorkspaceRoles(ctx, setting, getLDAPWorkspaceRoleMap(setting.RoleMappingList, groupMemberMap), groupMemberMap, roleMappingListed)
+	for projectID, roleMap := range getLDAPProjectRoleMap(setting.ProjectMappingList, groupMemberMap) {
+		if ctx.Err() != nil {
+			return
+		}
+		g.syncProjectMembers(ctx, setting, projectID, roleMap, groupMemberMap)
+	}
+}
+
+// syncWorkspaceRoles updates the workspace roles of the members in the mapped groups.
+// If all the mapped groups are listed, the LDAP principals in none of the mapped groups are lowered to Developer.
+// The principals not linked to LDAP are left untouched.
+func (g *LDAPGroupSyncer) syncWorkspaceRoles(ctx context.Context, setting *api.LDAPSetting, roleMap map[string]api.Role, groupMemberMap map[string][]*ldap.User, roleMappingListed bool) {
+	syncedPrincipalIDs := make(map[int]bool)
+	for email, role := range roleMap {
+		principal, err := g.getOrCreatePrincipal(ctx, setting, email, groupMemberMap)
+		if err != nil {
+			if common.ErrorCode(err) != common.Conflict {
+				// We cannot tell if the principal is linked to LDAP, so don't lower the roles of anyone.
+				roleMappingListed = false
+			}
+			log.Error("Failed to get or create the principal of LDAP user", zap.String("email", email), zap.Error(err))
+			continue
+		}
+		syncedPrincipalIDs[principal.ID] = true
+		g.updateWorkspaceRole(ctx, principal, role)
+	}
+	if !roleMappingListed {
+		return
+	}
+
+	principalIDList, err := g.server.store.FindLDAPPrincipalIDList(ctx)
+	if err != nil {
+		log.Error("Failed to find LDAP principals", zap.Error(err))
+		return
+	}
+	for _, principalID := range principalIDList {
+		if syncedPrincipalIDs[principalID] {
+			continue
+		}
+		principal, err := g.server.store.GetPrincipalByID(ctx, principalID)
+		if err != nil {
+			log.Error("Failed to find principal", zap.Int("principal_id", principalID), zap.Error(err))
+			continue
+		}
+		if principal == nil {
+			continue
+		}
+		g.updateWorkspaceRole(ctx, principal, api.Developer)
+	}
+}
+
+// updateWorkspaceRole updates the workspace role of the principal, except demoting the only remaining owner.
+func (g *LDAPGroupSyncer) updateWorkspaceRole(ctx context.Context, principal *api.Principal, role api.Role) {
+	member, err := g.server.store.GetMemberByPrincipalID(ctx, principal.ID)
+	if err != nil {
+		log.Error("Failed to find member", zap.String("email", principal.Email), zap.Error(err))
+		return
+	}
+	if member == nil || member.Role == role {
+		return
+	}
+	// Make sure there are other active owners when demoting an owner.
+	if member.Role == api.Owner {
+		countList, err := g.server.store.CountMemberGroupByRoleAndStatus(ctx)
+		if err != nil {
+			log.Error("Failed to count members", zap.Error(err))
+			return
+		}
+		isLastOwner := false
+		for _, count := range countList {
+			if count.Role == api.Owner && count.RowStatus == api.Normal && count.Count == 1 {
+				isLastOwner = true
+			}
+		}
+		if isLastOwner {
+			log.Warn("Skip demoting the only remaining owner in workspace by LDAP group sync", zap.String("email", principal.Email), zap.String("role", string(role)))
+			return
+		}
+	}
+
+	roleStr := string(role)
+	updatedMember, err := g.server.store.PatchMember(ctx, &api.MemberPatch{
+		ID:        member.ID,
+		UpdaterID: api.SystemBotID,
+		Role:      &roleStr,
+	})
+	if err != nil {
+		log.Error("Failed to update member role", zap.String("email", principal.Email), zap.String("role", roleStr), zap.Error(err))
+		return
+	}
+	bytes, err := json.Marshal(api.ActivityMemberRoleUpdatePayload{
+		PrincipalID:    updatedMember.PrincipalID,
+		PrincipalName:  principal.Name,
+		PrincipalEmail: principal.Email,
+		OldRole:        member.Role,
+		NewRole:        updatedMember.Role,
+	})
+	if err != nil {
+		log.Error("Failed to marshal activity payload", zap.Error(err))
+		return
+	}
+	if _, err := g.server.ActivityManager.CreateActivity(ctx, &api.ActivityCreate{
+		CreatorID:   api.SystemBotID,
+		ContainerID: updatedMember.ID,
+		Type:        api.ActivityMemberRoleUpdate,
+		Level:       api.ActivityInfo,
+		Comment:     "Synced from LDAP groups.",
+		Payload:     string(bytes),
+	}, &ActivityMeta{}); err != nil {
+		log.Warn("Failed to create activity after changing member role",
+			zap.Int("member_id", updatedMember.ID),
+			zap.String("old_role", string(member.Role)),
+			zap.String("new_role", string(updatedMember.Role)),
+			zap.Error(err))
+	}
+}
+
+// syncProjectMembers replaces the members provided by LDAP in the project,
+// and switches the role provider of the project to LDAP.
+func (g *LDAPGroupSyncer) syncProjectMembers(ctx context.Context, setting *api.LDAPSetting, projectID int, roleMap map[string]common.ProjectRole, groupMemberMap map[string][]*ldap.User) {
+	project, err := g.server.store.GetProjectByID(ctx, projectID)
+	if err != nil {
+		log.Error("Failed to find project", zap.Int("project_id", projectID), zap.Error(err))
+		return
+	}
+	if project == nil || project.RowStatus == api.Archived {
+		return
+	}
+
+	payload, err := json.Marshal(&api.ProjectRoleProviderPayload{
+		LastSyncTs: time.Now().Unix(),
+	})
+	if err != nil {
+		log.Error("Failed to marshal role provider payload", zap.Error(err))
+		return
+	}
+	var createList []*api.ProjectMemberCreate
+	for email, role := range roleMap {
+		principal, err := g.getOrCreatePrincipal(ctx, setting, email, groupMemberMap)
+		if err != nil {
+			if common.ErrorCode(err) == common.Conflict {
+				// The principal is not linked to LDAP, so it's not a member provided by LDAP.
+				log.Warn("Skip granting the project role to the principal not linked to LDAP", zap.String("email", email), zap.Error(err))
+				continue
+			}
+			log.Error("Failed to get or create the principal of LDAP user", zap.String("email", email), zap.Error(err))
+			// Abort the sync to avoid revoking the membership.
+			return
+		}
+		createList = append(createList, &api.ProjectMemberCreate{
+			CreatorID:    api.SystemBotID,
+			ProjectID:    projectID,
+			Role:         role,
+			PrincipalID:  principal.ID,
+			RoleProvider: api.ProjectRoleProviderLDAP,
+			Payload:      string(payload),
+		})
+	}
+	createdMemberList, deletedMemberList, err := g.server.store.BatchUpdateProjectMember(ctx, &api.ProjectMemberBatchUpdate{
+		ID:           projectID,
+		UpdaterID:    api.SystemBotID,
+		RoleProvider: api.ProjectRoleProviderLDAP,
+		List:         createList,
+	})
+	if err != nil {
+		log.Error("Failed to sync project members from LDAP", zap.Int("project_id", projectID), zap.Error(err))
+		return
+	}
+	if project.RoleProvider != api.ProjectRoleProviderLDAP {
+		roleProvider := string(api.ProjectRoleProviderLDAP)
+		if _, err := g.server.store.PatchProject(ctx, &api.ProjectPatch{
+			ID:           projectID,
+			UpdaterID:    api.SystemBotID,
+			RoleProvider: &roleProvider,
+		}); err != nil {
+			log.Error("Failed to switch the project role provider to LDAP", zap.Int("project_id", projectID), zap.Error(err))
+		}
+	}
+
+	deletedMemberMap := make(map[int]*api.ProjectMember)
+	for _, deletedMember := range deletedMemberList {
+		deletedMemberMap[deletedMember.PrincipalID] = deletedMember
+	}
+	var activityCreateList []*api.ActivityCreate
+	for _, createdMember := range createdMemberList {
+		principal := createdMember.Principal
+		if deletedMember, ok := deletedMemberMap[createdMember.PrincipalID]; ok {
+			delete(deletedMemberMap, createdMember.PrincipalID)
+			if createdMember.Role == deletedMember.Role {
+				continue
+			}
+			activityCreateList = append(activityCreateList, &api.ActivityCreate{
+				Type: api.ActivityProjectMemberRoleUpdate,
+				Comment: fmt.Sprintf("Changed %s (%s) from %s to %s (synced from LDAP).",
+					principal.Name, principal.Email, deletedMember.Role, createdMember.Role),
+			})
+			continue
+		}
+		activityCreateList = append(activityCreateList, &api.ActivityCreate{
+			Type: api.ActivityProjectMemberCreate,
+			Comment: fmt.Sprintf("Granted %s to %s (%s) (synced from LDAP).",
+				principal.Name, principal.Email, createdMember.Role),
+		})
+	}
+	for _, deletedMember := range deletedMemberMap {
+		principal := deletedMember.Principal
+		activityCreateList = append(activityCreateList, &api.ActivityCreate{
+			Type: api.ActivityProjectMemberDelete,
+			Comment: fmt.Sprintf("Revoked %s from %s (%s). Because this member does not belong to the LDAP groups.",
+				principal.Name, principal.Email, deletedMember.Role),
+		})
+	}
+	for _, activityCreate := range activityCreateList {
+		activityCreate.CreatorID = api.SystemBotID
+		activityCreate.ContainerID = projectID
+		activityCreate.Level = api.ActivityInfo
+		if _, err := g.server.ActivityManager.CreateActivity(ctx, activityCreate, &ActivityMeta{}); err != nil {
+			log.Warn("Failed to create project activity after syncing members from LDAP",
+				zap.Int("project_id", projectID),
+				zap.String("type", string(activityCreate.Type)),
+				zap.Error(err))
+		}
+	}
+}
+
+// getOrCreatePrincipal returns the principal of the LDAP user with the email in the groups.
+func (g *LDAPGroupSyncer) getOrCreatePrincipal(ctx context.Context, setting *api.LDAPSetting, email string, groupMemberMap map[string][]*ldap.User) (*api.Principal, error) {
+	for _, userList := range groupMemberMap {
+		for _, user := range userList {
+			if user.Email == email {
+				return g.server.getOrCreateLDAPPrincipal(ctx, setting, user)
+			}
+		}
+	}
+	return nil, errors.Errorf("LDAP user with email %q not found in the groups", email)
+}
+
+// getOrCreateLDAPPrincipal returns the principal linked to the LDAP user, and creates it if the LDAP user has never logged in.
+// The existing principal with the same email but not linked to LDAP is only linked if the admin allows it in the LDAP setting,
+// otherwise the LDAP user would take over the principal who logs in via other providers. It returns the Conflict error in this case.
+func (s *Server) getOrCreateLDAPPrincipal(ctx context.Context, setting *api.LDAPSetting, user *ldap.User) (*api.Principal, error) {
+	principal, err := s.store.GetPrincipalByEmail(ctx, user.Email)
+	if err != nil {
+		return nil, err
+	}
+	if principal == nil {
+		// The principal logs in via LDAP, so the password is never used.
+		password, err := common.RandomString(20)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to generate random password")
+		}
+		name := user.Name
+		if name == "" {
+			name = user.Email
+		}
+		createdPrincipal, httpErr := trySignUp(ctx, s, &api.SignUp{
+			Email:    user.Email,
+			Password: password,
+			Name:     name,
+		}, api.SystemBotID)
+		if httpErr != nil {
+			return nil, httpErr
+		}
+		principal = createdPrincipal
+	} else {
+		dn, err := s.store.GetPrincipalLDAPDN(ctx, principal.ID)
+		if err != nil {
+			return nil, err
+		}
+		if dn == user.DN {
+			return principal, nil
+		}
+		if dn == "" && !setting.AllowLinkExistingPrincipal {
+			return nil, common.Errorf(common.Conflict, "user %q already exists and is not linked to LDAP", user.Email)
+		}
+	}
+	// Link the principal to the LDAP user. The DN of a linked principal changes if the user is moved in the directory.
+	if err := s.store.SetPrincipalLDAPDN(ctx, principal.ID, user.DN); err != nil {
+		return nil, errors.Wrapf(err, "failed to link user %q to LDAP", user.Email)
+	}
+	return principal, nil
+}
+
+// getLDAPWorkspaceRoleMap returns the workspace role by email of the members in the mapped groups.
+// The highest role is granted if a member is in multiple groups.
+func getLDAPWorkspaceRoleMap(mappingList []*api.LDAPRoleMapping, groupMemberMap map[string][]*ldap.User) map[string]api.Role {
+	roleMap := make(map[string]api.Role)
+	for _, mapping := range mappingList {
+		userList, ok := groupMemberMap[mapping.GroupDN]
+		if !ok {
+			continue
+		}
+		for _, user := range userList {
+			if role, ok := roleMap[user.Email]; ok && workspaceRolePrecedence[role] >= workspaceRolePrecedence[mapping.Role] {
+				continue
+			}
+			roleMap[user.Email] = mapping.Role
+		}
+	}
+	return roleMap
+}
+
+// getLDAPProjectRoleMap returns the project role by email by project ID of the members in the mapped groups.
+// The owner role is granted if a member is in multiple groups of the same project.
+// The projects with any mapped group failed to list are skipped.
+func getLDAPProjectRoleMap(mappingList []*api.LDAPProjectMapping, groupMemberMap map[string][]*ldap.User) map[int]map[string]common.ProjectRole {
+	projectRoleMap := make(map[int]map[string]common.ProjectRole)
+	skipped := make(map[int]bool)
+	for _, mapping := range mappingList {
+		if skipped[mapping.ProjectID] {
+			continue
+		}
+		userList, ok := groupMemberMap[mapping.GroupDN]
+		if !ok {
+			skipped[mapping.ProjectID] = true
+			delete(projectRoleMap, mapping.ProjectID)
+			continue
+		}
+		roleMap, ok := projectRoleMap[mapping.ProjectID]
+		if !ok {
+			roleMap = make(map[string]common.ProjectRole)
+			projectRoleMap[mapping.ProjectID] = roleMap
+		}
+		for _, user := range userList {
+			if roleMap[user.Email] == common.ProjectOwner {
+				continue
+			}
+			roleMap[user.Email] = mapping.Role
+		}
+	}
+	return projectRoleMap
+}
+
+func getLDAPConfig(setting *api.LDAPSetting) ldap.Config {
+	return ldap.Config{
+		Host:             setting.Host,
+		Port:             setting.Port,
+		SecurityProtocol: setting.SecurityProtocol,
+		SkipTLSVerify:    setting.SkipTLSVerify,
+		BindDN:           setting.BindDN,
+		BindPassword:     setting.BindPassword,
+		BaseDN:           setting.BaseDN,
+		UserFilter:       setting.UserFilter,
+		EmailAttribute:   setting.EmailAttribute,
+		NameAttribute:    setting.NameAttribute,
+	}
+}
+
+// parseLDAPSetting parses and validates the LDAP setting value.
+func parseLDAPSetting(value string) (*api.LDAPSetting, error) {
+	setting := &api.LDAPSetting{}
+	if value != "" {
+		if err := json.Unmarshal([]byte(value), setting); err != nil {
+			return nil, common.Wrapf(err, common.Invalid, "invalid LDAP setting")
+		}
+	}
+	if setting.Enabled {
+		config := getLDAPConfig(setting)
+		if err := config.Validate(); err != nil {
+			return nil, common.Wrapf(err, common.Invalid, "invalid LDAP setting")
+		}
+	}
+	if setting.GroupSyncIntervalTs < 0 {
+		return nil, common.Errorf(common.Invalid, "invalid LDAP group sync interval %d", setting.GroupSyncIntervalTs)
+	}
+	if setting.GroupSyncIntervalTs == 0 {
+		setting.GroupSyncIntervalTs = defaultLDAPGroupSyncIntervalTs
+	}
+	for _, mapping := range setting.RoleMappingList {
+		if mapping.GroupDN == "" {
+			return nil, common.Errorf(common.Invalid, "LDAP group DN is required in the role mapping")
+		}
+		if _, ok := workspaceRolePrecedence[mapping.Role]; !ok {
+			return nil, common.Errorf(common.Invalid, "invalid workspace role %q for LDAP group %q", mapping.Role, mapping.GroupDN)
+		}
+	}
+	for _, mapping := range setting.ProjectMappingList {
+		if mapping.GroupDN == "" {
+			return nil, common.Errorf(common.Invalid, "LDAP group DN is required in the project mapping")
+		}
+		if mapping.ProjectID <= 0 {
+			return nil, common.Errorf(common.Invalid, "invalid project ID %d for LDAP group %q", mapping.ProjectID, mapping.GroupDN)
+		}
+		if mapping.Role != common.ProjectOwner && mapping.Role != common.ProjectDeveloper {
+			return nil, common.Errorf(common.Invalid, "invalid project role %q for LDAP group %q", mapping.Role, mapping.GroupDN)
+		}
+	}
+	return setting, nil
+}
+
+// getLDAPSetting returns the LDAP setting of the workspace.
+func (s *Server) getLDAPSetting(ctx context.Context) (*api.LDAPSetting, error) {
+	settingName := api.SettingLDAP
+	settingList, err := s.store.FindSetting(ctx, &api.SettingFind{Name: &settingName})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find setting %s", settingName)
+	}
+	value := ""
+	if len(settingList) > 0 {
+		value = settingList[0].Value
+	}
+	return parseLDAPSetting(value)
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/plugin/ldap"
+)
+
+var testLDAPGroupMemberMap = map[string][]*ldap.User{
+	"cn=owner,dc=example,dc=com": {
+		{Email: "alice@example.com"},
+	},
+	"cn=dba,dc=example,dc=com": {
+		{Email: "alice@example.com"},
+		{Email: "bob@example.com"},
+	},
+	"cn=developer,dc=example,dc=com": {
+		{Email: "bob@example.com"},
+		{Email: "carol@example.com"},
+	},
+}
+
+func TestGetLDAPWorkspaceRoleMap(t *testing.T) {
+	mappingList := []*api.LDAPRoleMapping{
+		{GroupDN: "cn=developer,dc=example,dc=com", Role: api.Developer},
+		{GroupDN: "cn=owner,dc=example,dc=com", Role: api.Owner},
+		{GroupDN: "cn=dba,dc=example,dc=com", Role: api.DBA},
+		// The group failed to list is skipped.
+		{GroupDN: "cn=unknown,dc=example,dc=com", Role: api.Owner},
+	}
+	got := getLDAPWorkspaceRoleMap(mappingList, testLDAPGroupMemberMap)
+	want := map[string]api.Role{
+		"alice@example.com": api.Owner,
+		"bob@example.com":   api.DBA,
+		"carol@example.com": api.Developer,
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestGetLDAPProjectRoleMap(t *testing.T) {
+	mappingList := []*api.LDAPProjectMapping{
+		{GroupDN: "cn=dba,dc=example,dc=com", ProjectID: 101, Role: common.ProjectOwner},
+		{GroupDN: "cn=developer,dc=example,dc=com", ProjectID: 101, Role: common.ProjectDeveloper},
+		{GroupDN: "cn=developer,dc=example,dc=com", ProjectID: 102, Role: common.ProjectDeveloper},
+		// The project with any group failed to list is skipped.
+		{GroupDN: "cn=owner,dc=example,dc=com", ProjectID: 103, Role: common.ProjectOwner},
+		{GroupDN: "cn=unknown,dc=example,dc=com", ProjectID: 103, Role: common.ProjectDeveloper},
+	}
+	got := getLDAPProjectRoleMap(mappingList, testLDAPGroupMemberMap)
+	want := map[int]map[string]common.ProjectRole{
+		101: {
+			"alice@example.com": common.ProjectOwner,
+			"bob@example.com":   common.ProjectOwner,
+			"carol@example.com": common.ProjectDeveloper,
+		},
+		102: {
+			"bob@example.com":   common.ProjectDeveloper,
+			"carol@example.com": common.ProjectDeveloper,
+		},
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestParseLDAPSetting(t *testing.T) {
+	setting, err := parseLDAPSetting("")
+	require.NoError(t, err)
+	assert.Equal(t, &api.LDAPSetting{GroupSyncIntervalTs: defaultLDAPGroupSyncIntervalTs}, setting)
+
+	setting, err = parseLDAPSetting(`{"enabled":true,"host":"ad.example.com","port":636,"securityProtocol":"ldaps","baseDn":"dc=example,dc=com","userFilter":"(sAMAccountName=%s)","allowLinkExistingPrincipal":true,"roleMappingList":[{"groupDn":"cn=dba,dc=example,dc=com","role":"DBA"}]}`)
+	require.NoError(t, err)
+	assert.Equal(t, ldap.SecurityProtocolLDAPS, setting.SecurityProtocol)
+	assert.True(t, setting.AllowLinkExistingPrincipal)
+	assert.Equal(t, defaultLDAPGroupSyncIntervalTs, setting.GroupSyncIntervalTs)
+
+	invalidList := []string{
+		`{"enabled":true,"host":"ad.example.com","port":636,"baseDn":"dc=example,dc=com","userFilter":"(sAMAccountName=alice)"}`,
+		`{"groupSyncIntervalTs":-1}`,
+		`{"roleMappingList":[{"groupDn":"cn=dba,dc=example,dc=com","role":"ADMIN"}]}`,
+		`{"roleMappingList":[{"role":"DBA"}]}`,
+		`{"projectMappingList":[{"groupDn":"cn=dba,dc=example,dc=com","projectId":0,"role":"OWNER"}]}`,
+		`{"projectMappingList":[{"groupDn":"cn=dba,dc=example,dc=com","projectId":101,"role":"DBA"}]}`,
+	}
+	for _, value := range invalidList {
+		_, err := parseLDAPSetting(value)
+		require.Error(t, err, value)
+		assert.Equal(t, common.Invalid, common.ErrorCode(err), value)
+	}
+}
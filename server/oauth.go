@@ -55,7 +55,9 @@ func (s *Server) registerOAuthRoutes(g *echo.Group) {
 			}
 		} else {
 			vcsType = req.Type
-			if vcsType != vcsPlugin.GitLabSelfHost && vcsType != vcsPlugin.GitHubCom {
+			switch vcsType {
+			case vcsPlugin.GitLabSelfHost, vcsPlugin.GitHubCom, vcsPlugin.GiteaSelfHost, vcsPlugin.BitbucketServer:
+			default:
 				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Unexpected VCS type: %s", vcsType))
 			}
 
@@ -42,6 +42,7 @@ func (c *catalogService) GetFinder() *catalog.Finder {
 func (s *Server) registerOpenAPIRoutes(g *echo.Group) {
 	g.POST("/sql/advise", s.sqlCheckController)
 	g.POST("/sql/schema/diff", s.schemaDiff)
+	g.POST("/sql/format", s.sqlFormat)
 }
 
 type sqlCheckRequestBody struct {
@@ -232,3 +233,59 @@ func (s *Server) schemaDiff(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, diff)
 }
+
+type sqlFormatRequestBody struct {
+	EngineType    parser.EngineType    `json:"engineType"`
+	Statement     string               `json:"statement"`
+	KeywordCase   parser.KeywordCase   `json:"keywordCase"`
+	IndentWidth   int                  `json:"indentWidth"`
+	CommaPosition parser.CommaPosition `json:"commaPosition"`
+}
+
+// sqlFormat godoc
+// @Summary  Format the SQL statement.
+// @Description  Format the SQL statement and keep the comments.
+// @Accept  */*
+// @Tags  SQL format
+// @Produce  json
+// @Param  engineType     body  string  true   "The database engine type."  Enums(MYSQL, POSTGRES, TIDB)
+// @Param  statement      body  string  true   "The SQL statement."
+// @Param  keywordCase    body  string  false  "The case of the keywords, default to UPPER."  Enums(UPPER, LOWER, PRESERVE)
+// @Param  indentWidth    body  int     false  "The number of spaces for one level of indentation, default to 2."
+// @Param  commaPosition  body  string  false  "The position of the commas in the lists broken into lines, default to TRAILING."  Enums(TRAILING, LEADING)
+// @Success  200  {string}  the formatted statement
+// @Failure  400  {object}  echo.HTTPError
+// @Router  /sql/format  [post].
+func (*Server) sqlFormat(c echo.Context) error {
+	request := &sqlFormatRequestBody{}
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Failed to read request body").SetInternal(err)
+	}
+	if err := json.Unmarshal(body, request); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Cannot format request body").SetInternal(err)
+	}
+
+	var engine parser.EngineType
+	switch request.EngineType {
+	case parser.EngineType(db.Postgres):
+		engine = parser.Postgres
+	case parser.EngineType(db.MySQL):
+		engine = parser.MySQL
+	case parser.EngineType(db.TiDB):
+		engine = parser.TiDB
+	default:
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid database engine %s", request.EngineType))
+	}
+
+	formatted, err := parser.Format(engine, request.Statement, parser.FormatOption{
+		KeywordCase:   request.KeywordCase,
+		IndentWidth:   request.IndentWidth,
+		CommaPosition: request.CommaPosition,
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Failed to format the statement: %v", err)).SetInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, formatted)
+}
@@ -20,6 +20,8 @@ import (
 	"github.com/bytebase/bytebase/common"
 	"github.com/bytebase/bytebase/common/log"
 	vcsPlugin "github.com/bytebase/bytebase/plugin/vcs"
+	"github.com/bytebase/bytebase/plugin/vcs/bitbucket"
+	"github.com/bytebase/bytebase/plugin/vcs/gitea"
 	"github.com/bytebase/bytebase/plugin/vcs/github"
 	"github.com/bytebase/bytebase/plugin/vcs/gitlab"
 )
@@ -270,6 +272,14 @@ func (s *Server) registerProjectRoutes(g *echo.Group) {
 			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Malformed create linked repository request: %s", err.Error()))
 		}
 
+		if err := api.ValidateRepositoryChangelogPathTemplate(repositoryCreate.ChangelogPathTemplate, project.TenantMode); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Malformed create linked repository request: %s", err.Error()))
+		}
+
+		if err := api.ValidateRepositoryChangelogCommitMessageTemplate(repositoryCreate.ChangelogCommitMessageTemplate); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Malformed create linked repository request: %s", err.Error()))
+		}
+
 		vcs, err := s.store.GetVCSByID(ctx, repositoryCreate.VCSID)
 		if err != nil {
 			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to find VCS for creating repository: %d", repositoryCreate.VCSID)).SetInternal(err)
@@ -400,6 +410,18 @@ func (s *Server) registerProjectRoutes(g *echo.Group) {
 			}
 		}
 
+		if repoPatch.ChangelogPathTemplate != nil {
+			if err := api.ValidateRepositoryChangelogPathTemplate(*repoPatch.ChangelogPathTemplate, project.TenantMode); err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Malformed patch linked repository request: %s", err.Error()))
+			}
+		}
+
+		if repoPatch.ChangelogCommitMessageTemplate != nil {
+			if err := api.ValidateRepositoryChangelogCommitMessageTemplate(*repoPatch.ChangelogCommitMessageTemplate); err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Malformed patch linked repository request: %s", err.Error()))
+			}
+		}
+
 		// Remove enclosing /
 		if repoPatch.BaseDirectory != nil {
 			baseDir := strings.Trim(*repoPatch.BaseDirectory, "/")
@@ -443,6 +465,13 @@ func (s *Server) registerProjectRoutes(g *echo.Group) {
 			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to update repository for project ID: %d", projectID)).SetInternal(err)
 		}
 
+		// The webhook created before the pull request workflow was supported doesn't subscribe to the pull request events.
+		if !repo.EnablePullRequestWorkflow && updatedRepo.EnablePullRequestWorkflow && s.flight(api.FeatureVCSPullRequestWorkflow) && s.feature(api.FeatureVCSPullRequestWorkflow) {
+			if err := s.patchVCSWebhook(ctx, updatedRepo); err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, "Failed to subscribe the webhook to the pull request events").SetInternal(err)
+			}
+		}
+
 		//	Setup SQL review CI for VCS
 		if !repo.EnableSQLReviewCI && updatedRepo.EnableSQLReviewCI && s.flight(api.FeatureVCSSQLReviewWorkflow) && s.feature(api.FeatureVCSSQLReviewWorkflow) {
 			pullRequest, err := s.setupVCSSQLReviewCI(ctx, updatedRepo)
@@ -607,25 +636,30 @@ func (s *Server) setupVCSSQLReviewCI(ctx context.Context, repository *api.Reposi
 		return nil, err
 	}
 
-	if err := vcsPlugin.Get(repository.VCS.Type, vcsPlugin.ProviderConfig{}).UpsertEnvironmentVariable(
-		ctx,
-		common.OauthContext{
-			ClientID:     repository.VCS.ApplicationID,
-			ClientSecret: repository.VCS.Secret,
-			AccessToken:  repository.AccessToken,
-			RefreshToken: repository.RefreshToken,
-			Refresher:    s.refreshToken(ctx, repository.WebURL),
-		},
-		repository.VCS.InstanceURL,
-		repository.ExternalID,
-		vcsPlugin.SQLReviewAPISecretName,
-		repository.WebhookSecretToken,
-	); err != nil {
-		return nil, err
+	// Bitbucket Server does not have a built-in CI, the secret is kept in the
+	// credential store of the external CI and configured by the user.
+	if repository.VCS.Type != vcsPlugin.BitbucketServer {
+		if err := vcsPlugin.Get(repository.VCS.Type, vcsPlugin.ProviderConfig{}).UpsertEnvironmentVariable(
+			ctx,
+			common.OauthContext{
+				ClientID:     repository.VCS.ApplicationID,
+				ClientSecret: repository.VCS.Secret,
+				AccessToken:  repository.AccessToken,
+				RefreshToken: repository.RefreshToken,
+				Refresher:    s.refreshToken(ctx, repository.WebURL),
+			},
+			repository.VCS.InstanceURL,
+			repository.ExternalID,
+			vcsPlugin.SQLReviewAPISecretName,
+			repository.WebhookSecretToken,
+		); err != nil {
+			return nil, err
+		}
 	}
 
 	sqlReviewEndpoint := fmt.Sprintf("%s/hook/sql-review/%s", s.profile.ExternalURL, repository.WebhookEndpointID)
 
+	pullRequestBody := "This pull request is auto-generated by Bytebase for GitOps workflow."
 	switch repository.VCS.Type {
 	case vcsPlugin.GitHubCom:
 		if err := s.setupVCSSQLReviewCIForGitHub(ctx, repository, branch, sqlReviewEndpoint); err != nil {
@@ -635,6 +669,27 @@ func (s *Server) setupVCSSQLReviewCI(ctx context.Context, repository *api.Reposi
 		if err := s.setupVCSSQLReviewCIForGitLab(ctx, repository, branch, sqlReviewEndpoint); err != nil {
 			return nil, err
 		}
+	case vcsPlugin.GiteaSelfHost:
+		if err := s.createOrUpdateVCSSQLReviewFile(ctx, repository, branch, gitea.SQLReviewActionFilePath, func(_ *vcsPlugin.FileMeta) (string, error) {
+			return gitea.SetupSQLReviewCI(sqlReviewEndpoint), nil
+		}); err != nil {
+			return nil, err
+		}
+	case vcsPlugin.BitbucketServer:
+		if err := s.createOrUpdateVCSSQLReviewFile(ctx, repository, branch, bitbucket.SQLReviewPipelineFilePath, func(_ *vcsPlugin.FileMeta) (string, error) {
+			return bitbucket.SetupSQLReviewCI(sqlReviewEndpoint, repository.ExternalID, repository.VCS.InstanceURL), nil
+		}); err != nil {
+			return nil, err
+		}
+		// We do not put the secret in the pull request because it is visible to
+		// everyone who can read the repository, the repository admins can copy it
+		// from the webhook settings instead.
+		pullRequestBody += fmt.Sprintf(
+			"\n\nTo finish the setup, run %s in a Jenkins multibranch pipeline, and add a Jenkins secret text credential %q with the secret of the %q webhook in the repository settings.",
+			bitbucket.SQLReviewPipelineFilePath,
+			vcsPlugin.SQLReviewAPISecretName,
+			bitbucket.WebhookName,
+		)
 	}
 
 	return vcsPlugin.Get(repository.VCS.Type, vcsPlugin.ProviderConfig{}).CreatePullRequest(
@@ -650,7 +705,7 @@ func (s *Server) setupVCSSQLReviewCI(ctx context.Context, repository *api.Reposi
 		repository.ExternalID,
 		&vcsPlugin.PullRequestCreate{
 			Title:                 sqlReviewInVCSPRTitle,
-			Body:                  "This pull request is auto-generated by Bytebase for GitOps workflow.",
+			Body:                  pullRequestBody,
 			Head:                  branch.Name,
 			Base:                  repository.BranchFilter,
 			RemoveHeadAfterMerged: true,
@@ -756,7 +811,7 @@ func (s *Server) setupVCSSQLReviewCIForGitHub(ctx context.Context, repository *a
 // setupVCSSQLReviewCIForGitLab will create or update SQL review related files in GitLab to setup SQL review CI.
 func (s *Server) setupVCSSQLReviewCIForGitLab(ctx context.Context, repository *api.Repository, branch *vcsPlugin.BranchInfo, sqlReviewEndpoint string) error {
 	// create or update the .gitlab-ci.yml
-	if err := s.createOrUpdateVCSSQLReviewFileForGitLab(ctx, repository, branch, gitlab.CIFilePath, func(fileMeta *vcsPlugin.FileMeta) (string, error) {
+	if err := s.createOrUpdateVCSSQLReviewFile(ctx, repository, branch, gitlab.CIFilePath, func(fileMeta *vcsPlugin.FileMeta) (string, error) {
 		content := make(map[string]interface{})
 
 		if fileMeta != nil {
@@ -793,13 +848,13 @@ func (s *Server) setupVCSSQLReviewCIForGitLab(ctx context.Context, repository *a
 	}
 
 	// create or update the SQL review CI.
-	return s.createOrUpdateVCSSQLReviewFileForGitLab(ctx, repository, branch, gitlab.SQLReviewCIFilePath, func(_ *vcsPlugin.FileMeta) (string, error) {
+	return s.createOrUpdateVCSSQLReviewFile(ctx, repository, branch, gitlab.SQLReviewCIFilePath, func(_ *vcsPlugin.FileMeta) (string, error) {
 		return gitlab.SetupSQLReviewCI(sqlReviewEndpoint), nil
 	})
 }
 
-// createOrUpdateVCSSQLReviewFileForGitLab will create or update SQL review file for GitLab CI.
-func (s *Server) createOrUpdateVCSSQLReviewFileForGitLab(
+// createOrUpdateVCSSQLReviewFile will create or update SQL review file for the VCS CI.
+func (s *Server) createOrUpdateVCSSQLReviewFile(
 	ctx context.Context,
 	repository *api.Repository,
 	branch *vcsPlugin.BranchInfo,
@@ -884,7 +939,57 @@ func (s *Server) createOrUpdateVCSSQLReviewFileForGitLab(
 
 func (s *Server) createVCSWebhook(ctx context.Context, vcsType vcsPlugin.Type, webhookEndpointID, secretToken, accessToken, instanceURL, externalRepoID string) (string, error) {
 	// Create a new webhook and retrieve the created webhook ID
-	var webhookCreatePayload []byte
+	webhookCreatePayload, err := s.getVCSWebhookPayload(vcsType, webhookEndpointID, secretToken)
+	if err != nil {
+		return "", err
+	}
+	webhookID, err := vcsPlugin.Get(vcsType, vcsPlugin.ProviderConfig{}).CreateWebhook(
+		ctx,
+		common.OauthContext{
+			AccessToken: accessToken,
+			// We use refreshTokenNoop() because the repository isn't created yet.
+			Refresher: refreshTokenNoop(),
+		},
+		instanceURL,
+		externalRepoID,
+		webhookCreatePayload,
+	)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create webhook")
+	}
+	return webhookID, nil
+}
+
+// patchVCSWebhook patches the existing webhook of the repository with the latest payload, e.g. the webhook
+// created before the pull request workflow was supported only subscribes to the push event.
+func (s *Server) patchVCSWebhook(ctx context.Context, repository *api.Repository) error {
+	webhookPatchPayload, err := s.getVCSWebhookPayload(repository.VCS.Type, repository.WebhookEndpointID, repository.WebhookSecretToken)
+	if err != nil {
+		return err
+	}
+	if err := vcsPlugin.Get(repository.VCS.Type, vcsPlugin.ProviderConfig{}).PatchWebhook(
+		ctx,
+		common.OauthContext{
+			ClientID:     repository.VCS.ApplicationID,
+			ClientSecret: repository.VCS.Secret,
+			AccessToken:  repository.AccessToken,
+			RefreshToken: repository.RefreshToken,
+			Refresher:    s.refreshToken(ctx, repository.WebURL),
+		},
+		repository.VCS.InstanceURL,
+		repository.ExternalID,
+		repository.ExternalWebhookID,
+		webhookPatchPayload,
+	); err != nil {
+		return errors.Wrap(err, "failed to patch webhook")
+	}
+	return nil
+}
+
+// getVCSWebhookPayload returns the payload for creating or patching the webhook, which subscribes to the push
+// and pull request events.
+func (s *Server) getVCSWebhookPayload(vcsType vcsPlugin.Type, webhookEndpointID, secretToken string) ([]byte, error) {
+	var webhookPayload []byte
 	var err error
 	switch vcsType {
 	case vcsPlugin.GitLabSelfHost:
@@ -892,11 +997,12 @@ func (s *Server) createVCSWebhook(ctx context.Context, vcsType vcsPlugin.Type, w
 			URL:                   fmt.Sprintf("%s/hook/gitlab/%s", s.profile.ExternalURL, webhookEndpointID),
 			SecretToken:           secretToken,
 			PushEvents:            true,
+			MergeRequestsEvents:   true,
 			EnableSSLVerification: false, // TODO(tianzhou): This is set to false, be lax to not enable_ssl_verification
 		}
-		webhookCreatePayload, err = json.Marshal(webhookCreate)
+		webhookPayload, err = json.Marshal(webhookCreate)
 		if err != nil {
-			return "", errors.Wrap(err, "failed to marshal request body for creating webhook")
+			return nil, errors.Wrap(err, "failed to marshal request body for creating webhook")
 		}
 	case vcsPlugin.GitHubCom:
 		webhookPost := github.WebhookCreateOrUpdate{
@@ -906,28 +1012,49 @@ func (s *Server) createVCSWebhook(ctx context.Context, vcsType vcsPlugin.Type, w
 				Secret:      secretToken,
 				InsecureSSL: 1, // TODO: Allow user to specify this value through api.RepositoryCreate
 			},
-			Events: []string{"push"},
+			Events: []string{string(github.WebhookPush), string(github.WebhookPullRequest)},
 		}
-		webhookCreatePayload, err = json.Marshal(webhookPost)
+		webhookPayload, err = json.Marshal(webhookPost)
 		if err != nil {
-			return "", errors.Wrap(err, "failed to marshal request body for creating webhook")
+			return nil, errors.Wrap(err, "failed to marshal request body for creating webhook")
+		}
+	case vcsPlugin.GiteaSelfHost:
+		webhookPost := gitea.WebhookCreateOrUpdate{
+			Type: "gitea",
+			Config: gitea.WebhookConfig{
+				URL:         fmt.Sprintf("%s/hook/gitea/%s", s.profile.ExternalURL, webhookEndpointID),
+				ContentType: "json",
+				Secret:      secretToken,
+			},
+			Events: []string{string(gitea.WebhookPush), string(gitea.WebhookPullRequest)},
+			Active: true,
+		}
+		webhookPayload, err = json.Marshal(webhookPost)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to marshal request body for creating webhook")
+		}
+	case vcsPlugin.BitbucketServer:
+		webhookPost := bitbucket.WebhookCreateOrUpdate{
+			Name:   bitbucket.WebhookName,
+			URL:    fmt.Sprintf("%s/hook/bitbucket/%s", s.profile.ExternalURL, webhookEndpointID),
+			Active: true,
+			Events: []string{
+				string(bitbucket.WebhookPush),
+				string(bitbucket.WebhookPullRequestOpened),
+				string(bitbucket.WebhookPullRequestFromRefUpdated),
+				string(bitbucket.WebhookPullRequestMerged),
+				string(bitbucket.WebhookPullRequestDeclined),
+			},
+			Configuration: bitbucket.WebhookConfiguration{
+				Secret: secretToken,
+			},
+		}
+		webhookPayload, err = json.Marshal(webhookPost)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to marshal request body for creating webhook")
 		}
 	}
-	webhookID, err := vcsPlugin.Get(vcsType, vcsPlugin.ProviderConfig{}).CreateWebhook(
-		ctx,
-		common.OauthContext{
-			AccessToken: accessToken,
-			// We use refreshTokenNoop() because the repository isn't created yet.
-			Refresher: refreshTokenNoop(),
-		},
-		instanceURL,
-		externalRepoID,
-		webhookCreatePayload,
-	)
-	if err != nil {
-		return "", errors.Wrap(err, "failed to create webhook")
-	}
-	return webhookID, nil
+	return webhookPayload, nil
 }
 
 // refreshToken is a token refresher that stores the latest access token configuration to repository.
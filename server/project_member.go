@@ -126,6 +126,10 @@ func (s *Server) registerProjectMemberRoutes(g *echo.Group) {
 			roleProvider = api.ProjectRoleProviderGitLabSelfHost
 		case vcsPlugin.GitHubCom:
 			roleProvider = api.ProjectRoleProviderGitHubCom
+		case vcsPlugin.GiteaSelfHost:
+			roleProvider = api.ProjectRoleProviderGiteaSelfHost
+		case vcsPlugin.BitbucketServer:
+			roleProvider = api.ProjectRoleProviderBitbucketServer
 		default:
 			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Unrecognized VCS type %q", vcs.Type))
 		}
@@ -0,0 +1,607 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/common/log"
+	"github.com/bytebase/bytebase/plugin/vcs"
+)
+
+const (
+	// pullRequestTaskCheckStatusContext is the context of the commit status for
+	// the task check results of the draft issues.
+	pullRequestTaskCheckStatusContext = "bytebase/task-check"
+)
+
+// processPullRequestEvent handles the pull request event for the repositories with the pull request workflow enabled.
+// 1. Opening or updating the pull request creates draft issue(s) from the migration files in the pull request, and
+// supersedes the previous draft issue(s) of the same pull request.
+// 2. Merging the pull request promotes the draft issue(s) to be runnable.
+// 3. Closing the pull request without merging cancels the draft issue(s).
+func (s *Server) processPullRequestEvent(ctx context.Context, repositoryList []*api.Repository, pullRequestEvent vcs.PullRequestEvent) ([]string, error) {
+	var filteredRepositoryList []*api.Repository
+	for _, repo := range repositoryList {
+		if repo.Project.RowStatus == api.Archived {
+			log.Debug("Skip repository as the associated project is archived", zap.Int("repository_id", repo.ID))
+			continue
+		}
+		if !repo.EnablePullRequestWorkflow {
+			log.Debug("Skip repository as the pull request workflow is not enabled", zap.Int("repository_id", repo.ID))
+			continue
+		}
+		filteredRepositoryList = append(filteredRepositoryList, repo)
+	}
+	if len(filteredRepositoryList) == 0 {
+		return nil, nil
+	}
+	if !s.flight(api.FeatureVCSPullRequestWorkflow) || !s.feature(api.FeatureVCSPullRequestWorkflow) {
+		return nil, echo.NewHTTPError(http.StatusForbidden, api.FeatureVCSPullRequestWorkflow.AccessErrorMessage())
+	}
+
+	switch pullRequestEvent.Action {
+	case vcs.PullRequestOpened, vcs.PullRequestUpdated:
+		return s.createPullRequestDraftIssue(ctx, filteredRepositoryList, pullRequestEvent)
+	case vcs.PullRequestMerged:
+		return s.promotePullRequestDraftIssue(ctx, filteredRepositoryList, pullRequestEvent)
+	case vcs.PullRequestClosed:
+		return s.cancelPullRequestDraftIssue(ctx, filteredRepositoryList, pullRequestEvent, "Canceled because the pull request is closed.")
+	}
+	return nil, nil
+}
+
+// respondPullRequestEvent processes the pull request event for the repositories matched by the filter, and
+// responds to the webhook request.
+func (s *Server) respondPullRequestEvent(c echo.Context, filter repositoryFilter, pullRequestEvent vcs.PullRequestEvent) error {
+	ctx := c.Request().Context()
+	repositoryList, err := s.filterRepository(ctx, c.Param("id"), pullRequestEvent.RepositoryID, filter)
+	if err != nil {
+		return err
+	}
+	if len(repositoryList) == 0 {
+		log.Debug("Empty handle repo list. Ignore this pull request event.")
+		return c.String(http.StatusOK, "OK")
+	}
+
+	messages, err := s.processPullRequestEvent(ctx, repositoryList, pullRequestEvent)
+	if err != nil {
+		return err
+	}
+	if len(messages) == 0 {
+		return c.String(http.StatusOK, "OK")
+	}
+	return c.String(http.StatusOK, strings.Join(messages, "\n"))
+}
+
+func (s *Server) createPullRequestDraftIssue(ctx context.Context, repositoryList []*api.Repository, pullRequestEvent vcs.PullRequestEvent) ([]string, error) {
+	var pendingRepositoryList []*api.Repository
+	for _, repo := range repositoryList {
+		issueList, err := s.findPullRequestDraftIssue(ctx, repo, pullRequestEvent.ID)
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to find draft issues of the pull request").SetInternal(err)
+		}
+		// The pull request event may be delivered more than once for the same
+		// head commit, e.g. GitLab sends the "update" action when the title of
+		// the merge request is changed.
+		upToDate := false
+		for _, issue := range issueList {
+			if getIssueVCSPullRequest(issue).HeadCommitID == pullRequestEvent.HeadCommitID {
+				upToDate = true
+				break
+			}
+		}
+		if upToDate {
+			log.Debug("Skip repository as the draft issue is up to date",
+				zap.Int("repository_id", repo.ID),
+				zap.String("pull_request", pullRequestEvent.ID),
+				zap.String("head_commit", pullRequestEvent.HeadCommitID),
+			)
+			continue
+		}
+		if _, err := s.cancelPullRequestDraftIssue(ctx, []*api.Repository{repo}, pullRequestEvent, fmt.Sprintf("Superseded by commit %s of the pull request.", pullRequestEvent.HeadCommitID)); err != nil {
+			return nil, err
+		}
+		pendingRepositoryList = append(pendingRepositoryList, repo)
+	}
+	if len(pendingRepositoryList) == 0 {
+		return nil, nil
+	}
+
+	repo := pendingRepositoryList[0]
+	prFiles, err := vcs.Get(repo.VCS.Type, vcs.ProviderConfig{}).ListPullRequestFile(
+		ctx,
+		common.OauthContext{
+			ClientID:     repo.VCS.ApplicationID,
+			ClientSecret: repo.VCS.Secret,
+			AccessToken:  repo.AccessToken,
+			RefreshToken: repo.RefreshToken,
+			Refresher:    s.refreshToken(ctx, repo.WebURL),
+		},
+		repo.VCS.InstanceURL,
+		pullRequestEvent.RepositoryID,
+		pullRequestEvent.ID,
+	)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to list pull request file").SetInternal(err)
+	}
+
+	// The pull request is treated as a single commit of its head, and all the
+	// changed files are considered as added against the base branch.
+	commit := vcs.Commit{
+		ID:          pullRequestEvent.HeadCommitID,
+		Title:       pullRequestEvent.Title,
+		Message:     pullRequestEvent.Title,
+		CreatedTs:   time.Now().Unix(),
+		URL:         pullRequestEvent.URL,
+		AuthorName:  pullRequestEvent.AuthorName,
+		AuthorEmail: pullRequestEvent.AuthorEmail,
+	}
+	var distinctFileList []vcs.DistinctFileItem
+	for _, prFile := range prFiles {
+		if prFile.IsDeleted {
+			continue
+		}
+		commit.AddedList = append(commit.AddedList, prFile.Path)
+		distinctFileList = append(distinctFileList, vcs.DistinctFileItem{
+			CreatedTs: commit.CreatedTs,
+			Commit:    commit,
+			FileName:  prFile.Path,
+			ItemType:  vcs.FileItemTypeAdded,
+		})
+	}
+	// The files are read at the head commit, while the ref is the base branch, since the tasks only run after the
+	// pull request is merged and the latest schema and the changelog are written back to the base branch.
+	baseVCSPushEvent := vcs.PushEvent{
+		Ref:                fmt.Sprintf("refs/heads/%s", pullRequestEvent.BaseBranch),
+		RepositoryID:       pullRequestEvent.RepositoryID,
+		RepositoryURL:      pullRequestEvent.RepositoryURL,
+		RepositoryFullPath: pullRequestEvent.RepositoryFullPath,
+		AuthorName:         pullRequestEvent.AuthorName,
+		CommitList:         []vcs.Commit{commit},
+	}
+
+	var createdMessageList []string
+	repoID2FileItemList := groupFileInfoByRepo(distinctFileList, pendingRepositoryList)
+	for _, fileInfoListInRepo := range repoID2FileItemList {
+		dbID2FileInfoList := groupFileInfoByDatabase(fileInfoListInRepo)
+		for _, fileInfoListInDB := range dbID2FileInfoList {
+			fileInfoListSorted := sortFilesBySchemaVersion(fileInfoListInDB)
+			repository := fileInfoListSorted[0].repository
+			pushEvent := baseVCSPushEvent
+			pushEvent.VCSType = repository.VCS.Type
+			pushEvent.BaseDirectory = repository.BaseDirectory
+			var pullRequestFileList []*api.IssueVCSPullRequestFile
+			for _, fileInfo := range fileInfoListSorted {
+				// The statements of the tasks created from the schema files are the schema diff, whose lines don't match the file.
+				if fileInfo.fType != migrationFileType {
+					continue
+				}
+				pullRequestFileList = append(pullRequestFileList, &api.IssueVCSPullRequestFile{
+					Path:          fileInfo.item.FileName,
+					SchemaVersion: fileInfo.migrationInfo.Version,
+				})
+			}
+			createdMessage, created, activityCreateList, err := s.processFilesInProject(
+				ctx,
+				pushEvent,
+				repository,
+				fileInfoListSorted,
+				&api.IssueVCSPullRequestPayload{
+					RepositoryID:  repository.ID,
+					PullRequestID: pullRequestEvent.ID,
+					URL:           pullRequestEvent.URL,
+					HeadCommitID:  pullRequestEvent.HeadCommitID,
+					Draft:         true,
+					FileList:      pullRequestFileList,
+				},
+			)
+			if err != nil {
+				return nil, err
+			}
+			if created {
+				createdMessageList = append(createdMessageList, createdMessage)
+			} else {
+				for _, activityCreate := range activityCreateList {
+					if _, err := s.ActivityManager.CreateActivity(ctx, activityCreate, &ActivityMeta{}); err != nil {
+						log.Warn("Failed to create project activity for the ignored repository files", zap.Error(err))
+					}
+				}
+			}
+		}
+	}
+	return createdMessageList, nil
+}
+
+func (s *Server) promotePullRequestDraftIssue(ctx context.Context, repositoryList []*api.Repository, pullRequestEvent vcs.PullRequestEvent) ([]string, error) {
+	var promotedMessageList []string
+	for _, repo := range repositoryList {
+		issueList, err := s.findPullRequestDraftIssue(ctx, repo, pullRequestEvent.ID)
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to find draft issues of the pull request").SetInternal(err)
+		}
+		for _, issue := range issueList {
+			pullRequest := getIssueVCSPullRequest(issue)
+			pullRequest.Draft = false
+			if err := s.patchIssueVCSPullRequest(ctx, issue, pullRequest); err != nil {
+				return nil, echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to promote draft issue %q", issue.Name)).SetInternal(err)
+			}
+
+			activityPayload, err := json.Marshal(api.ActivityIssueCommentCreatePayload{
+				IssueName: issue.Name,
+			})
+			if err != nil {
+				return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to construct activity payload").SetInternal(err)
+			}
+			activityCreate := &api.ActivityCreate{
+				CreatorID:   api.SystemBotID,
+				ContainerID: issue.ID,
+				Type:        api.ActivityIssueCommentCreate,
+				Level:       api.ActivityInfo,
+				Comment:     fmt.Sprintf("Promoted from draft because the pull request %s is merged.", pullRequest.URL),
+				Payload:     string(activityPayload),
+			}
+			if _, err := s.ActivityManager.CreateActivity(ctx, activityCreate, &ActivityMeta{issue: issue}); err != nil {
+				log.Warn("Failed to create issue activity after promoting the draft issue", zap.Int("issue_id", issue.ID), zap.Error(err))
+			}
+
+			pipeline, err := s.store.GetPipelineByID(ctx, issue.PipelineID)
+			if err != nil {
+				return nil, echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch pipeline of issue %q", issue.Name)).SetInternal(err)
+			}
+			if pipeline == nil {
+				return nil, echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Pipeline not found for issue %q", issue.Name))
+			}
+			if err := s.ScheduleActiveStage(ctx, pipeline); err != nil {
+				return nil, echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to schedule pipeline of issue %q", issue.Name)).SetInternal(err)
+			}
+			// The stage statuses are not reported while the issue is draft.
+			go s.ActivityManager.UpdateCommitStatus(context.Background(), issue.ID, 0 /* taskID */)
+			promotedMessageList = append(promotedMessageList, fmt.Sprintf("Promoted draft issue %q", issue.Name))
+		}
+	}
+	return promotedMessageList, nil
+}
+
+func (s *Server) cancelPullRequestDraftIssue(ctx context.Context, repositoryList []*api.Repository, pullRequestEvent vcs.PullRequestEvent, comment string) ([]string, error) {
+	var canceledMessageList []string
+	for _, repo := range repositoryList {
+		issueList, err := s.findPullRequestDraftIssue(ctx, repo, pullRequestEvent.ID)
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to find draft issues of the pull request").SetInternal(err)
+		}
+		for _, issue := range issueList {
+			// The stripped issue does not have the pipeline which is required to cancel the running tasks.
+			fullIssue, err := s.store.GetIssueByID(ctx, issue.ID)
+			if err != nil {
+				return nil, echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch issue %q", issue.Name)).SetInternal(err)
+			}
+			if fullIssue == nil {
+				continue
+			}
+			if _, err := s.changeIssueStatus(ctx, fullIssue, api.IssueCanceled, api.SystemBotID, comment); err != nil {
+				return nil, echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to cancel draft issue %q", issue.Name)).SetInternal(err)
+			}
+			canceledMessageList = append(canceledMessageList, fmt.Sprintf("Canceled draft issue %q", issue.Name))
+		}
+	}
+	return canceledMessageList, nil
+}
+
+// findPullRequestDraftIssue finds the open draft issues created from the pull request of the repository.
+func (s *Server) findPullRequestDraftIssue(ctx context.Context, repo *api.Repository, pullRequestID string) ([]*api.Issue, error) {
+	issueList, err := s.store.FindIssueStripped(ctx, &api.IssueFind{
+		ProjectID:  &repo.ProjectID,
+		StatusList: []api.IssueStatus{api.IssueOpen},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var draftIssueList []*api.Issue
+	for _, issue := range issueList {
+		pullRequest := getIssueVCSPullRequest(issue)
+		if pullRequest == nil || !pullRequest.Draft {
+			continue
+		}
+		if pullRequest.RepositoryID != repo.ID || pullRequest.PullRequestID != pullRequestID {
+			continue
+		}
+		draftIssueList = append(draftIssueList, issue)
+	}
+	return draftIssueList, nil
+}
+
+// getIssueVCSPullRequest returns the VCS pull request that the issue is created from, or nil if the issue
+// is not created from a pull request.
+func getIssueVCSPullRequest(issue *api.Issue) *api.IssueVCSPullRequestPayload {
+	if issue.Payload == "" {
+		return nil
+	}
+	payload := &api.IssuePayload{}
+	if err := json.Unmarshal([]byte(issue.Payload), payload); err != nil {
+		// The payload of other kinds of issues is owned by the frontend, which may not be an object.
+		return nil
+	}
+	return payload.VCSPullRequest
+}
+
+// getDraftIssueByPipelineID returns the issue containing the pipeline if it's a draft of an unmerged VCS
+// pull request, otherwise nil.
+func (s *Server) getDraftIssueByPipelineID(ctx context.Context, pipelineID int) (*api.Issue, error) {
+	issueList, err := s.store.FindIssueStripped(ctx, &api.IssueFind{PipelineID: &pipelineID})
+	if err != nil {
+		return nil, err
+	}
+	for _, issue := range issueList {
+		if pullRequest := getIssueVCSPullRequest(issue); pullRequest != nil && pullRequest.Draft {
+			return issue, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *Server) patchIssueVCSPullRequest(ctx context.Context, issue *api.Issue, pullRequest *api.IssueVCSPullRequestPayload) error {
+	if _, err := s.store.PatchIssue(ctx, &api.IssuePatch{
+		ID:             issue.ID,
+		UpdaterID:      api.SystemBotID,
+		VCSPullRequest: pullRequest,
+	}); err != nil {
+		return errors.Wrapf(err, "failed to patch issue %d", issue.ID)
+	}
+	return nil
+}
+
+// validateIssuePayloadFromClient rejects the issue payload from the client touching the pull request of the issue,
+// which is owned by the server to keep the draft issue from being approved before the pull request is merged.
+func validateIssuePayloadFromClient(issue *api.Issue, payload string) error {
+	if issue != nil && getIssueVCSPullRequest(issue) != nil {
+		return errors.Errorf("the payload of issue %q is owned by the pull request %s", issue.Name, getIssueVCSPullRequest(issue).URL)
+	}
+	issuePayload := &api.IssuePayload{}
+	if err := json.Unmarshal([]byte(payload), issuePayload); err != nil {
+		// The payload of other kinds of issues is owned by the frontend, which may not be an object.
+		return nil
+	}
+	if issuePayload.VCSPullRequest != nil {
+		return errors.Errorf("the pull request of an issue can only be set from the VCS")
+	}
+	return nil
+}
+
+// reportPullRequestTaskCheck reports the task check results of the draft issue containing the task back to the
+// pull request as a comment and a commit status, once all the task checks of the draft issue have finished.
+// The results are reported at most once for each head commit of the pull request.
+func (s *Server) reportPullRequestTaskCheck(ctx context.Context, taskID int) error {
+	task, err := s.store.GetTaskByID(ctx, taskID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to fetch task %d", taskID)
+	}
+	if task == nil {
+		return nil
+	}
+	draftIssue, err := s.getDraftIssueByPipelineID(ctx, task.PipelineID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to fetch issue by pipeline %d", task.PipelineID)
+	}
+	if draftIssue == nil || draftIssue.Status != api.IssueOpen {
+		return nil
+	}
+	pullRequest := getIssueVCSPullRequest(draftIssue)
+	if pullRequest.ReportedCommitID == pullRequest.HeadCommitID {
+		return nil
+	}
+	// The stripped issue does not have the pipeline which contains the task check runs.
+	issue, err := s.store.GetIssueByID(ctx, draftIssue.ID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to fetch issue %d", draftIssue.ID)
+	}
+	if issue == nil {
+		return nil
+	}
+
+	// The advisor results with the line number are commented on the lines of the migration files, and the others
+	// are listed in the review body.
+	var failedTaskList []string
+	var resultList []string
+	var commentList []*vcs.PullRequestReviewComment
+	commentSet := make(map[string]bool)
+	for _, stage := range issue.Pipeline.StageList {
+		for _, task := range stage.TaskList {
+			latestRunList := getLatestTaskCheckRunList(task.TaskCheckRunList)
+			for _, run := range latestRunList {
+				if run.Status == api.TaskCheckRunRunning {
+					// Wait for all the task checks to finish.
+					return nil
+				}
+			}
+			taskResultList, hasError := getTaskCheckRunResultList(latestRunList)
+			if hasError {
+				failedTaskList = append(failedTaskList, task.Name)
+			}
+			filePath := getPullRequestTaskFilePath(pullRequest, task)
+			var taskResultItemList []string
+			for _, result := range taskResultList {
+				if filePath == "" || result.Line <= 0 {
+					taskResultItemList = append(taskResultItemList, fmt.Sprintf("- %s %s: %s", result.Status, result.Title, result.Content))
+					continue
+				}
+				comment := &vcs.PullRequestReviewComment{
+					Path: filePath,
+					Line: result.Line,
+					Body: fmt.Sprintf("**%s** %s: %s", result.Status, result.Title, result.Content),
+				}
+				// The tasks created from the same file for multiple databases usually have the same results, so we comment once.
+				key := fmt.Sprintf("%s:%d:%s", comment.Path, comment.Line, comment.Body)
+				if commentSet[key] {
+					continue
+				}
+				commentSet[key] = true
+				commentList = append(commentList, comment)
+			}
+			if len(taskResultItemList) == 0 {
+				continue
+			}
+			resultList = append(resultList, fmt.Sprintf("**%s** (%s)", task.Name, stage.Name))
+			resultList = append(resultList, taskResultItemList...)
+		}
+	}
+
+	repo, err := s.store.GetRepository(ctx, &api.RepositoryFind{ID: &pullRequest.RepositoryID})
+	if err != nil {
+		return errors.Wrapf(err, "failed to fetch repository %d", pullRequest.RepositoryID)
+	}
+	if repo == nil {
+		return errors.Errorf("repository %d not found", pullRequest.RepositoryID)
+	}
+
+	issueURL := fmt.Sprintf("%s/issue/%s", s.profile.ExternalURL, api.IssueSlug(issue))
+	status := &vcs.CommitStatus{
+		State:       vcs.CommitStatusSuccess,
+		Context:     pullRequestTaskCheckStatusContext,
+		Description: "All task checks passed",
+		TargetURL:   issueURL,
+	}
+	if len(failedTaskList) > 0 {
+		status.State = vcs.CommitStatusFailure
+		status.Description = fmt.Sprintf("Task checks failed for %d task(s)", len(failedTaskList))
+	}
+	review := &vcs.PullRequestReview{
+		CommitID:    pullRequest.HeadCommitID,
+		Body:        fmt.Sprintf("Bytebase task checks passed for the draft issue [%s](%s) at commit %s.", issue.Name, issueURL, pullRequest.HeadCommitID),
+		CommentList: commentList,
+	}
+	if len(resultList) > 0 || len(commentList) > 0 {
+		review.Body = fmt.Sprintf("Bytebase task checks found problems in the draft issue [%s](%s) at commit %s.", issue.Name, issueURL, pullRequest.HeadCommitID)
+		if len(resultList) > 0 {
+			review.Body = fmt.Sprintf("%s\n\n%s", review.Body, strings.Join(resultList, "\n"))
+		}
+	}
+
+	oauthCtx := common.OauthContext{
+		ClientID:     repo.VCS.ApplicationID,
+		ClientSecret: repo.VCS.Secret,
+		AccessToken:  repo.AccessToken,
+		RefreshToken: repo.RefreshToken,
+		Refresher:    s.refreshToken(ctx, repo.WebURL),
+	}
+	provider := vcs.Get(repo.VCS.Type, vcs.ProviderConfig{})
+	if err := provider.CreatePullRequestReview(ctx, oauthCtx, repo.VCS.InstanceURL, repo.ExternalID, pullRequest.PullRequestID, review); err != nil {
+		if len(review.CommentList) == 0 {
+			return errors.Wrapf(err, "failed to review pull request %s", pullRequest.URL)
+		}
+		// The VCS rejects the line comments on the lines not in the diff, e.g. the unchanged lines of a modified file,
+		// or if new commits are pushed, so we fall back to listing them in the comment.
+		log.Warn("Failed to review pull request with line comments, fall back to the comment",
+			zap.String("pull_request", pullRequest.URL),
+			zap.Error(err),
+		)
+		if err := provider.CreatePullRequestComment(ctx, oauthCtx, repo.VCS.InstanceURL, repo.ExternalID, pullRequest.PullRequestID, formatPullRequestReviewAsComment(review)); err != nil {
+			return errors.Wrapf(err, "failed to comment on pull request %s", pullRequest.URL)
+		}
+	}
+	if err := provider.SetCommitStatus(ctx, oauthCtx, repo.VCS.InstanceURL, repo.ExternalID, pullRequest.HeadCommitID, status); err != nil {
+		// The review has been posted, so we still record the report to avoid reviewing repeatedly.
+		log.Warn("Failed to set commit status of the pull request",
+			zap.String("pull_request", pullRequest.URL),
+			zap.String("commit", pullRequest.HeadCommitID),
+			zap.Error(err),
+		)
+	}
+
+	pullRequest.ReportedCommitID = pullRequest.HeadCommitID
+	return s.patchIssueVCSPullRequest(ctx, issue, pullRequest)
+}
+
+// getLatestTaskCheckRunList returns the latest task check run of each check type.
+func getLatestTaskCheckRunList(taskCheckRunList []*api.TaskCheckRun) []*api.TaskCheckRun {
+	latestRunMap := make(map[api.TaskCheckType]*api.TaskCheckRun)
+	for _, run := range taskCheckRunList {
+		if latest, ok := latestRunMap[run.Type]; !ok || latest.ID < run.ID {
+			latestRunMap[run.Type] = run
+		}
+	}
+	var latestRunList []*api.TaskCheckRun
+	for _, run := range latestRunMap {
+		latestRunList = append(latestRunList, run)
+	}
+	sort.Slice(latestRunList, func(i, j int) bool {
+		return latestRunList[i].ID < latestRunList[j].ID
+	})
+	return latestRunList
+}
+
+// getTaskCheckRunResultList returns the non-success results of the task check runs, and the failed task check run
+// is returned as an error result. It also returns true if any of the task check runs failed or has an error result.
+func getTaskCheckRunResultList(taskCheckRunList []*api.TaskCheckRun) ([]api.TaskCheckResult, bool) {
+	var resultList []api.TaskCheckResult
+	hasError := false
+	for _, run := range taskCheckRunList {
+		payload := &api.TaskCheckRunResultPayload{}
+		if run.Result != "" {
+			if err := json.Unmarshal([]byte(run.Result), payload); err != nil {
+				log.Warn("Failed to unmarshal task check run result", zap.Int("task_check_run_id", run.ID), zap.Error(err))
+				continue
+			}
+		}
+		if run.Status == api.TaskCheckRunFailed {
+			hasError = true
+			resultList = append(resultList, api.TaskCheckResult{
+				Status:  api.TaskCheckStatusError,
+				Title:   string(run.Type),
+				Content: payload.Detail,
+			})
+			continue
+		}
+		for _, result := range payload.ResultList {
+			if result.Status == api.TaskCheckStatusSuccess {
+				continue
+			}
+			if result.Status == api.TaskCheckStatusError {
+				hasError = true
+			}
+			resultList = append(resultList, result)
+		}
+	}
+	return resultList, hasError
+}
+
+// getPullRequestTaskFilePath returns the path of the migration file in the pull request that the task is created from,
+// or empty if not found.
+func getPullRequestTaskFilePath(pullRequest *api.IssueVCSPullRequestPayload, task *api.Task) string {
+	// The task payloads of the database changes share the schemaVersion field.
+	payload := &struct {
+		SchemaVersion string `json:"schemaVersion"`
+	}{}
+	if err := json.Unmarshal([]byte(task.Payload), payload); err != nil || payload.SchemaVersion == "" {
+		return ""
+	}
+	for _, file := range pullRequest.FileList {
+		if file.SchemaVersion == payload.SchemaVersion {
+			return file.Path
+		}
+	}
+	return ""
+}
+
+// formatPullRequestReviewAsComment formats the review as a single comment, with the line comments listed at the end.
+func formatPullRequestReviewAsComment(review *vcs.PullRequestReview) string {
+	var itemList []string
+	for _, comment := range review.CommentList {
+		itemList = append(itemList, fmt.Sprintf("- `%s:%d` %s", comment.Path, comment.Line, comment.Body))
+	}
+	if len(itemList) == 0 {
+		return review.Body
+	}
+	return fmt.Sprintf("%s\n\n%s", review.Body, strings.Join(itemList, "\n"))
+}
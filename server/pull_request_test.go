@@ -0,0 +1,79 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/plugin/vcs"
+)
+
+func TestGetTaskCheckRunResultList(t *testing.T) {
+	runList := []*api.TaskCheckRun{
+		{
+			Type:   api.TaskCheckDatabaseStatementAdvise,
+			Status: api.TaskCheckRunDone,
+			Result: `{"resultList":[{"status":"SUCCESS","title":"OK"},{"status":"WARN","title":"Require primary key","content":"Table t requires PRIMARY KEY","line":2}]}`,
+		},
+		{
+			Type:   api.TaskCheckDatabaseStatementSyntax,
+			Status: api.TaskCheckRunFailed,
+			Result: `{"detail":"connection refused"}`,
+		},
+	}
+	resultList, hasError := getTaskCheckRunResultList(runList)
+	assert.True(t, hasError)
+	assert.Equal(t, []api.TaskCheckResult{
+		{Status: api.TaskCheckStatusWarn, Title: "Require primary key", Content: "Table t requires PRIMARY KEY", Line: 2},
+		{Status: api.TaskCheckStatusError, Title: string(api.TaskCheckDatabaseStatementSyntax), Content: "connection refused"},
+	}, resultList)
+
+	resultList, hasError = getTaskCheckRunResultList(runList[:1])
+	assert.False(t, hasError)
+	assert.Len(t, resultList, 1)
+}
+
+func TestGetPullRequestTaskFilePath(t *testing.T) {
+	pullRequest := &api.IssueVCSPullRequestPayload{
+		FileList: []*api.IssueVCSPullRequestFile{
+			{Path: "bytebase/prod/db__ver1__migrate__create_t.sql", SchemaVersion: "ver1"},
+			{Path: "bytebase/prod/db__ver2__data__insert_t.sql", SchemaVersion: "ver2"},
+		},
+	}
+	tests := []struct {
+		payload string
+		want    string
+	}{
+		{
+			payload: `{"statement":"INSERT INTO t VALUES (1)","schemaVersion":"ver2"}`,
+			want:    "bytebase/prod/db__ver2__data__insert_t.sql",
+		},
+		{
+			payload: `{"statement":"CREATE TABLE t2 (id INT)","schemaVersion":"ver3"}`,
+			want:    "",
+		},
+		{
+			payload: `{}`,
+			want:    "",
+		},
+	}
+	for _, test := range tests {
+		got := getPullRequestTaskFilePath(pullRequest, &api.Task{Payload: test.payload})
+		assert.Equal(t, test.want, got)
+	}
+}
+
+func TestFormatPullRequestReviewAsComment(t *testing.T) {
+	review := &vcs.PullRequestReview{
+		Body: "Bytebase task checks found problems.",
+		CommentList: []*vcs.PullRequestReviewComment{
+			{Path: "bytebase/prod/db__ver1__migrate__create_t.sql", Line: 2, Body: "**WARN** Require primary key: Table t requires PRIMARY KEY"},
+		},
+	}
+	want := "Bytebase task checks found problems.\n\n- `bytebase/prod/db__ver1__migrate__create_t.sql:2` **WARN** Require primary key: Table t requires PRIMARY KEY"
+	assert.Equal(t, want, formatPullRequestReviewAsComment(review))
+
+	review.CommentList = nil
+	assert.Equal(t, "Bytebase task checks found problems.", formatPullRequestReviewAsComment(review))
+}
@@ -32,6 +32,10 @@ import (
 	enterpriseService "github.com/bytebase/bytebase/enterprise/service"
 	"github.com/bytebase/bytebase/metric"
 	metricCollector "github.com/bytebase/bytebase/metric/collector"
+	"github.com/bytebase/bytebase/plugin/storage"
+	"github.com/bytebase/bytebase/plugin/storage/azure"
+	"github.com/bytebase/bytebase/plugin/storage/fs"
+	"github.com/bytebase/bytebase/plugin/storage/gcs"
 	s3bb "github.com/bytebase/bytebase/plugin/storage/s3"
 	"github.com/bytebase/bytebase/resources/mysqlutil"
 	"github.com/bytebase/bytebase/resources/postgres"
@@ -50,6 +54,8 @@ type Server struct {
 	SchemaSyncer       *SchemaSyncer
 	BackupRunner       *BackupRunner
 	AnomalyScanner     *AnomalyScanner
+	BackupVerifier     *BackupVerifier
+	LDAPGroupSyncer    *LDAPGroupSyncer
 	runnerWG           sync.WaitGroup
 
 	ActivityManager *ActivityManager
@@ -67,7 +73,10 @@ type Server struct {
 	workspaceID     string
 	errorRecordRing api.ErrorRecordRing
 
-	s3Client *s3bb.Client
+	// backupStorage is nil if the backups are stored in the local data directory.
+	backupStorage storage.Client
+	// baseBackupMap is the *sharedBaseBackup by PostgreSQL instance ID.
+	baseBackupMap sync.Map
 
 	// boot specifies that whether the server boot correctly
 	cancel context.CancelFunc
@@ -124,6 +133,7 @@ func NewServer(ctx context.Context, prof Profile) (*Server, error) {
 	log.Info(fmt.Sprintf("backupStorageBackend=%s", prof.BackupStorageBackend))
 	log.Info(fmt.Sprintf("backupBucket=%s", prof.BackupBucket))
 	log.Info(fmt.Sprintf("backupRegion=%s", prof.BackupRegion))
+	log.Info(fmt.Sprintf("backupEndpoint=%s", prof.BackupEndpoint))
 	log.Info(fmt.Sprintf("backupCredentialFile=%s", prof.BackupCredentialFile))
 	log.Info("-----Config END-------")
 
@@ -208,17 +218,11 @@ func NewServer(ctx context.Context, prof Profile) (*Server, error) {
 	embedFrontend(e)
 	s.e = e
 
-	if prof.BackupBucket != "" {
-		credentials, err := s3bb.GetCredentialsFromFile(ctx, prof.BackupCredentialFile)
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to get credentials from file")
-		}
-		s3Client, err := s3bb.NewClient(ctx, prof.BackupRegion, prof.BackupBucket, credentials)
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to create AWS S3 client")
-		}
-		s.s3Client = s3Client
+	backupStorage, err := newBackupStorage(ctx, prof)
+	if err != nil {
+		return nil, err
 	}
+	s.backupStorage = backupStorage
 
 	if !prof.Readonly {
 		// Task scheduler
@@ -259,6 +263,12 @@ func NewServer(ctx context.Context, prof Profile) (*Server, error) {
 		statementTypeExecutor := NewTaskCheckStatementTypeExecutor()
 		taskCheckScheduler.Register(api.TaskCheckDatabaseStatementType, statementTypeExecutor)
 
+		statementDryRunExecutor := NewTaskCheckStatementDryRunExecutor()
+		taskCheckScheduler.Register(api.TaskCheckDatabaseStatementDryRun, statementDryRunExecutor)
+
+		statementLockImpactExecutor := NewTaskCheckStatementLockImpactExecutor()
+		taskCheckScheduler.Register(api.TaskCheckDatabaseStatementLockImpact, statementLockImpactExecutor)
+
 		databaseConnectExecutor := NewTaskCheckDatabaseConnectExecutor()
 		taskCheckScheduler.Register(api.TaskCheckDatabaseConnect, databaseConnectExecutor)
 
@@ -274,6 +284,9 @@ func NewServer(ctx context.Context, prof Profile) (*Server, error) {
 		pitrMySQLExecutor := NewTaskCheckPITRMySQLExecutor()
 		taskCheckScheduler.Register(api.TaskCheckPITRMySQL, pitrMySQLExecutor)
 
+		pitrPostgresExecutor := NewTaskCheckPITRPostgresExecutor()
+		taskCheckScheduler.Register(api.TaskCheckPITRPostgres, pitrPostgresExecutor)
+
 		s.TaskCheckScheduler = taskCheckScheduler
 
 		// Schema syncer
@@ -285,6 +298,12 @@ func NewServer(ctx context.Context, prof Profile) (*Server, error) {
 		// Anomaly scanner
 		s.AnomalyScanner = NewAnomalyScanner(s)
 
+		// Backup verifier
+		s.BackupVerifier = NewBackupVerifier(s)
+
+		// LDAP group syncer
+		s.LDAPGroupSyncer = NewLDAPGroupSyncer(s)
+
 		// Metric reporter
 		s.initMetricReporter(config.workspaceID)
 	}
@@ -407,6 +426,57 @@ func (s *Server) initMetricReporter(workspaceID string) {
 	}
 }
 
+// newBackupStorage creates the client of the cloud storage backend for backups and binlog files.
+// It returns nil if the backups are stored in the local data directory.
+func newBackupStorage(ctx context.Context, prof Profile) (storage.Client, error) {
+	switch prof.BackupStorageBackend {
+	case api.BackupStorageBackendLocal:
+		return nil, nil
+	case api.BackupStorageBackendS3:
+		credentials, err := s3bb.GetCredentialsFromFile(ctx, prof.BackupCredentialFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get credentials from file")
+		}
+		client, err := s3bb.NewClient(ctx, prof.BackupRegion, prof.BackupBucket, prof.BackupEndpoint, credentials)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create AWS S3 client")
+		}
+		return client, nil
+	case api.BackupStorageBackendGCS:
+		client, err := gcs.NewClient(ctx, prof.BackupBucket, prof.BackupCredentialFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create Google Cloud Storage client")
+		}
+		return client, nil
+	case api.BackupStorageBackendAzure:
+		connectionString, err := azure.GetConnectionStringFromFile(prof.BackupCredentialFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get connection string from file")
+		}
+		client, err := azure.NewClient(prof.BackupBucket, connectionString)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create Azure Blob Storage client")
+		}
+		return client, nil
+	case api.BackupStorageBackendFileSystem:
+		client, err := fs.NewClient(prof.BackupBucket)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create file system storage client")
+		}
+		return client, nil
+	default:
+		return nil, errors.Errorf("unsupported backup storage backend %s", prof.BackupStorageBackend)
+	}
+}
+
+// getBackupStorage returns the storage client for backups stored in the given storage backend.
+func (s *Server) getBackupStorage(storageBackend api.BackupStorageBackend) (storage.Client, error) {
+	if storageBackend != s.profile.BackupStorageBackend || s.backupStorage == nil {
+		return nil, errors.Errorf("the backup is stored in %s, but the current backup storage backend is %s", storageBackend, s.profile.BackupStorageBackend)
+	}
+	return s.backupStorage, nil
+}
+
 func getInitSetting(ctx context.Context, store *store.Store) (*config, error) {
 	// initial branding
 	_, err := store.CreateSettingIfNotExist(ctx, &api.SettingCreate{
@@ -449,6 +519,56 @@ func getInitSetting(ctx context.Context, store *store.Store) (*config, error) {
 	}
 	conf.workspaceID = workspaceSetting.Value
 
+	// initial backup artifact setting
+	if _, err = store.CreateSettingIfNotExist(ctx, &api.SettingCreate{
+		CreatorID:   api.SystemBotID,
+		Name:        api.SettingBackupArtifact,
+		Value:       fmt.Sprintf(`{"compression":%q,"encryption":%q}`, api.BackupCompressionNone, api.BackupEncryptionNone),
+		Description: "The compression and encryption of the backup artifacts.",
+	}); err != nil {
+		return nil, err
+	}
+
+	// initial backup verification setting
+	if _, err = store.CreateSettingIfNotExist(ctx, &api.SettingCreate{
+		CreatorID:   api.SystemBotID,
+		Name:        api.SettingBackupVerification,
+		Value:       fmt.Sprintf(`{"enabled":false,"intervalTs":%d}`, defaultBackupVerificationIntervalTs),
+		Description: "The scheduled verification restoring the most recent backups into scratch databases.",
+	}); err != nil {
+		return nil, err
+	}
+
+	// initial PITR setting
+	if _, err = store.CreateSettingIfNotExist(ctx, &api.SettingCreate{
+		CreatorID:   api.SystemBotID,
+		Name:        api.SettingPITR,
+		Value:       `{"instanceList":[]}`,
+		Description: "The PostgreSQL instances taking base backups and archiving the WAL for PITR.",
+	}); err != nil {
+		return nil, err
+	}
+
+	// initial lock impact threshold setting
+	if _, err = store.CreateSettingIfNotExist(ctx, &api.SettingCreate{
+		CreatorID:   api.SystemBotID,
+		Name:        api.SettingLockImpactThreshold,
+		Value:       fmt.Sprintf(`{"rowCount":%d,"dataSize":%d,"durationTs":%d}`, defaultLockImpactRowCountThreshold, defaultLockImpactDataSizeThreshold, defaultLockImpactDurationTsThreshold),
+		Description: "The thresholds of the table size and the estimated duration to warn the statements blocking the writes.",
+	}); err != nil {
+		return nil, err
+	}
+
+	// initial LDAP setting
+	if _, err = store.CreateSettingIfNotExist(ctx, &api.SettingCreate{
+		CreatorID:   api.SystemBotID,
+		Name:        api.SettingLDAP,
+		Value:       fmt.Sprintf(`{"enabled":false,"groupSyncIntervalTs":%d}`, defaultLDAPGroupSyncIntervalTs),
+		Description: "The LDAP authentication and the sync of workspace roles and project members from the LDAP groups.",
+	}); err != nil {
+		return nil, err
+	}
+
 	// initial license
 	if _, err = store.CreateSettingIfNotExist(ctx, &api.SettingCreate{
 		CreatorID:   api.SystemBotID,
@@ -481,6 +601,10 @@ func (s *Server) Run(ctx context.Context, port int) error {
 		go s.BackupRunner.Run(ctx, &s.runnerWG)
 		s.runnerWG.Add(1)
 		go s.AnomalyScanner.Run(ctx, &s.runnerWG)
+		s.runnerWG.Add(1)
+		go s.BackupVerifier.Run(ctx, &s.runnerWG)
+		s.runnerWG.Add(1)
+		go s.LDAPGroupSyncer.Run(ctx, &s.runnerWG)
 
 		if s.MetricReporter != nil {
 			s.runnerWG.Add(1)
@@ -9,6 +9,7 @@ import (
 
 	"github.com/bytebase/bytebase/api"
 	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/plugin/db"
 )
 
 var (
@@ -59,6 +60,73 @@ func (s *Server) registerSettingRoutes(g *echo.Group) {
 			return echo.NewHTTPError(http.StatusBadRequest, "Malformed update setting request").SetInternal(err)
 		}
 
+		if settingPatch.Name == api.SettingBackupArtifact {
+			if _, _, err := parseBackupArtifactSetting(settingPatch.Value); err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+			}
+		}
+		if settingPatch.Name == api.SettingLockImpactThreshold {
+			if _, err := parseLockImpactThresholdSetting(settingPatch.Value); err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+			}
+		}
+		if settingPatch.Name == api.SettingBackupVerification {
+			setting, err := parseBackupVerificationSetting(settingPatch.Value)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+			}
+			if setting.SandboxInstanceID != 0 {
+				instance, err := s.store.GetInstanceByID(ctx, setting.SandboxInstanceID)
+				if err != nil {
+					return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to find instance ID: %d", setting.SandboxInstanceID)).SetInternal(err)
+				}
+				if instance == nil {
+					return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Sandbox instance ID not found: %d", setting.SandboxInstanceID))
+				}
+				if !isBackupVerificationSupported(instance.Engine) {
+					return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Backup verification is not supported for %s sandbox instance", instance.Engine))
+				}
+			}
+		}
+
+		if settingPatch.Name == api.SettingPITR {
+			setting, err := parsePITRSetting(settingPatch.Value)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+			}
+			for _, instanceSetting := range setting.InstanceList {
+				instance, err := s.store.GetInstanceByID(ctx, instanceSetting.InstanceID)
+				if err != nil {
+					return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to find instance ID: %d", instanceSetting.InstanceID)).SetInternal(err)
+				}
+				if instance == nil {
+					return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Instance ID not found: %d", instanceSetting.InstanceID))
+				}
+				if instance.Engine != db.Postgres {
+					return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("PITR setting is only for PostgreSQL instances, but instance %q is %s", instance.Name, instance.Engine))
+				}
+			}
+		}
+
+		if settingPatch.Name == api.SettingLDAP {
+			if !s.feature(api.FeatureLDAP) {
+				return echo.NewHTTPError(http.StatusForbidden, api.FeatureLDAP.AccessErrorMessage())
+			}
+			setting, err := parseLDAPSetting(settingPatch.Value)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+			}
+			for _, mapping := range setting.ProjectMappingList {
+				project, err := s.store.GetProjectByID(ctx, mapping.ProjectID)
+				if err != nil {
+					return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to find project ID: %d", mapping.ProjectID)).SetInternal(err)
+				}
+				if project == nil {
+					return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Project ID not found: %d", mapping.ProjectID))
+				}
+			}
+		}
+
 		setting, err := s.store.PatchSetting(ctx, settingPatch)
 		if err != nil {
 			if common.ErrorCode(err) == common.NotFound {
@@ -225,6 +225,10 @@ func (s *Server) registerSheetRoutes(g *echo.Group) {
 				sheetSource = api.SheetFromGitLabSelfHost
 			case vcsPlugin.GitHubCom:
 				sheetSource = api.SheetFromGitHubCom
+			case vcsPlugin.GiteaSelfHost:
+				sheetSource = api.SheetFromGiteaSelfHost
+			case vcsPlugin.BitbucketServer:
+				sheetSource = api.SheetFromBitbucketServer
 			}
 			vscSheetType := api.SheetForSQL
 			sheetFind := &api.SheetFind{
@@ -483,6 +483,52 @@ func (s *Server) patchTask(ctx context.Context, task *api.Task, taskPatch *api.T
 					)
 				}
 			}
+
+			if task.Type == api.TaskDatabaseSchemaUpdate && api.IsStatementDryRunSupported(task.Instance.Engine) {
+				payload, err := json.Marshal(api.TaskCheckDatabaseStatementDryRunPayload{
+					Statement: *taskPatch.Statement,
+					DbType:    task.Instance.Engine,
+				})
+				if err != nil {
+					return nil, echo.NewHTTPError(http.StatusInternalServerError, errors.Wrapf(err, "failed to marshal check statement dry run payload: %v", task.Name))
+				}
+				if _, err := s.store.CreateTaskCheckRunIfNeeded(ctx, &api.TaskCheckRunCreate{
+					CreatorID: api.SystemBotID,
+					TaskID:    task.ID,
+					Type:      api.TaskCheckDatabaseStatementDryRun,
+					Payload:   string(payload),
+				}); err != nil {
+					// It's OK if we failed to trigger a check, just emit an error log
+					log.Error("Failed to trigger statement dry run check after changing the task statement",
+						zap.Int("task_id", task.ID),
+						zap.String("task_name", task.Name),
+						zap.Error(err),
+					)
+				}
+			}
+
+			if task.Type == api.TaskDatabaseSchemaUpdate && api.IsStatementLockImpactSupported(task.Instance.Engine) {
+				payload, err := json.Marshal(api.TaskCheckDatabaseStatementLockImpactPayload{
+					Statement: *taskPatch.Statement,
+					DbType:    task.Instance.Engine,
+				})
+				if err != nil {
+					return nil, echo.NewHTTPError(http.StatusInternalServerError, errors.Wrapf(err, "failed to marshal check statement lock impact payload: %v", task.Name))
+				}
+				if _, err := s.store.CreateTaskCheckRunIfNeeded(ctx, &api.TaskCheckRunCreate{
+					CreatorID: api.SystemBotID,
+					TaskID:    task.ID,
+					Type:      api.TaskCheckDatabaseStatementLockImpact,
+					Payload:   string(payload),
+				}); err != nil {
+					// It's OK if we failed to trigger a check, just emit an error log
+					log.Error("Failed to trigger statement lock impact check after changing the task statement",
+						zap.Int("task_id", task.ID),
+						zap.String("task_name", task.Name),
+						zap.Error(err),
+					)
+				}
+			}
 		}
 	}
 
@@ -675,6 +721,19 @@ func (s *Server) patchTaskStatus(ctx context.Context, task *api.Task, taskStatus
 		}
 	}
 
+	if task.Status == api.TaskPendingApproval && taskStatusPatch.Status == api.TaskPending {
+		issue, err := s.getDraftIssueByPipelineID(ctx, task.PipelineID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to fetch containing issue before approving the task: %v", task.Name)
+		}
+		if issue != nil {
+			return nil, &common.Error{
+				Code: common.Invalid,
+				Err:  errors.Errorf("cannot approve task %v of the draft issue %q until the pull request %s is merged", task.Name, issue.Name, getIssueVCSPullRequest(issue).URL),
+			}
+		}
+	}
+
 	if taskStatusPatch.Status == api.TaskCanceled {
 		if !taskCancellationImplemented[task.Type] {
 			return nil, common.Errorf(common.NotImplemented, "Canceling task type %s is not supported", task.Type)
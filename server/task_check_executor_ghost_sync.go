@@ -3,12 +3,15 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"strings"
 
 	"github.com/github/gh-ost/go/logic"
 	"github.com/pkg/errors"
 
 	"github.com/bytebase/bytebase/api"
 	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/plugin/db"
+	"github.com/bytebase/bytebase/plugin/db/pg"
 )
 
 // NewTaskCheckGhostSyncExecutor creates a task check gh-ost sync executor.
@@ -59,6 +62,10 @@ func (*TaskCheckGhostSyncExecutor) Run(ctx context.Context, server *Server, task
 		return nil, common.Errorf(common.Internal, "failed to find database %d", task.DatabaseID)
 	}
 
+	if task.Instance.Engine == db.Postgres {
+		return checkPostgresOnlineSchemaChange(ctx, server, task)
+	}
+
 	adminDataSource := api.DataSourceFromInstanceWithType(task.Instance, api.Admin)
 	if adminDataSource == nil {
 		return nil, common.Errorf(common.Internal, "admin data source not found for instance %d", task.InstanceID)
@@ -110,3 +117,50 @@ func (*TaskCheckGhostSyncExecutor) Run(ctx context.Context, server *Server, task
 		},
 	}, nil
 }
+
+// checkPostgresOnlineSchemaChange checks if the table can be migrated by the Postgres online schema change.
+func checkPostgresOnlineSchemaChange(ctx context.Context, server *Server, task *api.Task) ([]api.TaskCheckResult, error) {
+	payload := &api.TaskDatabaseSchemaUpdateGhostSyncPayload{}
+	if err := json.Unmarshal([]byte(task.Payload), payload); err != nil {
+		return nil, common.Wrapf(err, common.Internal, "invalid database schema update gh-ost sync payload")
+	}
+
+	driver, err := server.getAdminDatabaseDriver(ctx, task.Instance, task.Database.Name)
+	if err != nil {
+		return nil, err
+	}
+	defer driver.Close(ctx)
+	pgDriver, ok := driver.(*pg.Driver)
+	if !ok {
+		return nil, common.Errorf(common.Internal, "failed to cast driver to pg.Driver")
+	}
+
+	checkErr := func() error {
+		osc, err := pg.NewOnlineSchemaChange(pgDriver, task.Database.Name, strings.TrimSpace(payload.Statement))
+		if err != nil {
+			return err
+		}
+		return osc.Check(ctx)
+	}()
+	if checkErr != nil {
+		return []api.TaskCheckResult{
+			{
+				Status:    api.TaskCheckStatusError,
+				Namespace: api.BBNamespace,
+				Code:      common.Internal.Int(),
+				Title:     "Online schema change check failed",
+				Content:   checkErr.Error(),
+			},
+		}, nil
+	}
+
+	return []api.TaskCheckResult{
+		{
+			Status:    api.TaskCheckStatusSuccess,
+			Namespace: api.BBNamespace,
+			Code:      common.Ok.Int(),
+			Title:     "OK",
+			Content:   "Online schema change check succeeded",
+		},
+	}, nil
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/plugin/db/pg"
+)
+
+// NewTaskCheckPITRPostgresExecutor creates a task check PostgreSQL PITR executor.
+func NewTaskCheckPITRPostgresExecutor() TaskCheckExecutor {
+	return &TaskCheckPITRPostgresExecutor{}
+}
+
+// TaskCheckPITRPostgresExecutor is the task check PostgreSQL PITR executor.
+// It checks the prerequisites of archiving the WAL on the source instance.
+type TaskCheckPITRPostgresExecutor struct {
+}
+
+// Run will run the task check PostgreSQL PITR executor once.
+func (*TaskCheckPITRPostgresExecutor) Run(ctx context.Context, server *Server, taskCheckRun *api.TaskCheckRun) (result []api.TaskCheckResult, err error) {
+	task, err := server.store.GetTaskByID(ctx, taskCheckRun.TaskID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get task by ID %d", taskCheckRun.TaskID)
+	}
+	if task == nil {
+		return nil, errors.Errorf("task with ID %d not found", taskCheckRun.TaskID)
+	}
+
+	payload := api.TaskDatabasePITRRestorePayload{}
+	if err := json.Unmarshal([]byte(task.Payload), &payload); err != nil {
+		return nil, errors.Wrapf(err, "invalid PITR restore payload: %s", task.Payload)
+	}
+
+	if payload.BackupID != nil {
+		return []api.TaskCheckResult{
+			{
+				Status:    api.TaskCheckStatusSuccess,
+				Namespace: api.BBNamespace,
+				Code:      common.Ok.Int(),
+				Title:     "OK",
+				Content:   "Ready to do backup restore",
+			},
+		}, nil
+	}
+
+	pitrSetting, err := server.getPITRSetting(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if getPITRInstanceSetting(pitrSetting, task.Instance.ID) == nil {
+		return wrapTaskCheckError(errors.Errorf("instance %q is not opted in to PITR in the %s setting", task.Instance.Name, api.SettingPITR)), nil
+	}
+
+	// The base backups and the WAL are taken from the source instance, while the target database is restored from a dump.
+	// So we only check the source instance.
+	driver, err := server.getAdminDatabaseDriver(ctx, task.Instance, "" /* databaseName */)
+	if err != nil {
+		return nil, err
+	}
+	defer driver.Close(ctx)
+	pgDriver, ok := driver.(*pg.Driver)
+	if !ok {
+		return nil, errors.Errorf("Failed to cast driver to pg.Driver")
+	}
+
+	if err := pgDriver.CheckWALArchivingForPITR(ctx); err != nil {
+		return wrapTaskCheckError(err), nil
+	}
+
+	backupStatus := api.BackupStatusDone
+	backupList, err := server.store.FindBackup(ctx, &api.BackupFind{DatabaseID: task.DatabaseID, Status: &backupStatus})
+	if err != nil {
+		return nil, err
+	}
+	if findLatestBaseBackup(backupList, *payload.PointInTimeTs) == nil {
+		return wrapTaskCheckError(errors.Errorf("no backup with a base backup is taken before the target time, please take a backup after opting in to PITR")), nil
+	}
+
+	return []api.TaskCheckResult{
+		{
+			Status:    api.TaskCheckStatusSuccess,
+			Namespace: api.BBNamespace,
+			Code:      common.Ok.Int(),
+			Title:     "OK",
+			Content:   "Ready to do PITR",
+		},
+	}, nil
+}
@@ -95,6 +95,7 @@ func (*TaskCheckStatementAdvisorCompositeExecutor) Run(ctx context.Context, serv
 			Code:      advice.Code.Int(),
 			Title:     advice.Title,
 			Content:   advice.Content,
+			Line:      advice.Line,
 		})
 	}
 
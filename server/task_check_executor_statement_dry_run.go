@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/plugin/db"
+)
+
+const (
+	// dryRunStatementTimeout is the timeout of a single statement in the dry run.
+	dryRunStatementTimeout = 10 * time.Second
+	// dryRunLockTimeout is the timeout of waiting for a lock in the dry run, so that the dry run won't queue up the workload behind it.
+	dryRunLockTimeout = 3 * time.Second
+	// dryRunTimeout is the timeout of the whole dry run, so that the dry run won't hold the locks of the statements for long.
+	dryRunTimeout = 1 * time.Minute
+)
+
+// NewTaskCheckStatementDryRunExecutor creates a task check statement dry run executor.
+func NewTaskCheckStatementDryRunExecutor() TaskCheckExecutor {
+	return &TaskCheckStatementDryRunExecutor{}
+}
+
+// TaskCheckStatementDryRunExecutor is the task check statement dry run executor.
+// It runs the statement against the target database and discards the changes.
+type TaskCheckStatementDryRunExecutor struct {
+}
+
+// Run will run the task check statement dry run executor once.
+func (*TaskCheckStatementDryRunExecutor) Run(ctx context.Context, server *Server, taskCheckRun *api.TaskCheckRun) (result []api.TaskCheckResult, err error) {
+	task, err := server.store.GetTaskByID(ctx, taskCheckRun.TaskID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get task by ID %d", taskCheckRun.TaskID)
+	}
+	if task == nil {
+		return nil, errors.Errorf("task with ID %d not found", taskCheckRun.TaskID)
+	}
+	if task.Database == nil {
+		return nil, errors.Errorf("database not found for task %d", task.ID)
+	}
+
+	payload := &api.TaskCheckDatabaseStatementDryRunPayload{}
+	if err := json.Unmarshal([]byte(taskCheckRun.Payload), payload); err != nil {
+		return nil, common.Wrapf(err, common.Invalid, "invalid check statement dry run payload")
+	}
+	if !api.IsStatementDryRunSupported(payload.DbType) {
+		return nil, common.Errorf(common.Invalid, "invalid check statement dry run database type: %s", payload.DbType)
+	}
+
+	driver, err := server.getAdminDatabaseDriver(ctx, task.Instance, task.Database.Name)
+	if err != nil {
+		return wrapTaskCheckError(err), nil
+	}
+	defer driver.Close(ctx)
+	dryRunExecutor, ok := driver.(db.DryRunExecutor)
+	if !ok {
+		return nil, errors.Errorf("dry run is not supported by the %s driver", payload.DbType)
+	}
+
+	dryRunResult, err := dryRunExecutor.DryRun(ctx, payload.Statement, db.DryRunOption{
+		StatementTimeout: dryRunStatementTimeout,
+		LockTimeout:      dryRunLockTimeout,
+		Timeout:          dryRunTimeout,
+	})
+	if err != nil {
+		return wrapTaskCheckError(err), nil
+	}
+
+	return convertDryRunResult(dryRunResult), nil
+}
+
+func convertDryRunResult(dryRunResult *db.DryRunResult) []api.TaskCheckResult {
+	var result []api.TaskCheckResult
+	duration := dryRunResult.Duration.Round(time.Millisecond)
+	if dryRunResult.Error != "" {
+		result = append(result, api.TaskCheckResult{
+			Status:    api.TaskCheckStatusError,
+			Namespace: api.BBNamespace,
+			Code:      common.DbExecutionError.Int(),
+			Title:     fmt.Sprintf("Statement at line %d failed in the dry run", dryRunResult.Line),
+			Content:   fmt.Sprintf("%q failed after %v with error: %s", dryRunResult.Statement, duration, dryRunResult.Error),
+		})
+	}
+	if len(dryRunResult.SkippedStatementList) > 0 {
+		result = append(result, api.TaskCheckResult{
+			Status:    api.TaskCheckStatusWarn,
+			Namespace: api.BBNamespace,
+			Code:      common.Ok.Int(),
+			Title:     fmt.Sprintf("%d statement(s) skipped in the dry run", len(dryRunResult.SkippedStatementList)),
+			Content:   strings.Join(dryRunResult.SkippedStatementList, "\n"),
+		})
+	}
+	if dryRunResult.Error == "" {
+		result = append(result, api.TaskCheckResult{
+			Status:    api.TaskCheckStatusSuccess,
+			Namespace: api.BBNamespace,
+			Code:      common.Ok.Int(),
+			Title:     "OK",
+			Content:   fmt.Sprintf("Dry run finished in %v", duration),
+		})
+	}
+	return result
+}
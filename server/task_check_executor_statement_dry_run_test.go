@@ -0,0 +1,63 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/plugin/db"
+)
+
+func TestConvertDryRunResult(t *testing.T) {
+	tests := []struct {
+		dryRunResult *db.DryRunResult
+		want         []api.TaskCheckResult
+	}{
+		{
+			dryRunResult: &db.DryRunResult{
+				Duration: 1500 * time.Microsecond,
+			},
+			want: []api.TaskCheckResult{
+				{
+					Status:    api.TaskCheckStatusSuccess,
+					Namespace: api.BBNamespace,
+					Code:      common.Ok.Int(),
+					Title:     "OK",
+					Content:   "Dry run finished in 2ms",
+				},
+			},
+		},
+		{
+			dryRunResult: &db.DryRunResult{
+				Statement:            "ALTER TABLE t ADD COLUMN a int;",
+				Line:                 3,
+				Error:                `pq: column "a" of relation "t" already exists`,
+				Duration:             20 * time.Millisecond,
+				SkippedStatementList: []string{"CREATE INDEX CONCURRENTLY idx_t_a ON t(a);"},
+			},
+			want: []api.TaskCheckResult{
+				{
+					Status:    api.TaskCheckStatusError,
+					Namespace: api.BBNamespace,
+					Code:      common.DbExecutionError.Int(),
+					Title:     "Statement at line 3 failed in the dry run",
+					Content:   `"ALTER TABLE t ADD COLUMN a int;" failed after 20ms with error: pq: column "a" of relation "t" already exists`,
+				},
+				{
+					Status:    api.TaskCheckStatusWarn,
+					Namespace: api.BBNamespace,
+					Code:      common.Ok.Int(),
+					Title:     "1 statement(s) skipped in the dry run",
+					Content:   "CREATE INDEX CONCURRENTLY idx_t_a ON t(a);",
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		require.Equal(t, test.want, convertDryRunResult(test.dryRunResult))
+	}
+}
@@ -0,0 +1,177 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/plugin/advisor/catalog"
+	advisorDB "github.com/bytebase/bytebase/plugin/advisor/db"
+	"github.com/bytebase/bytebase/plugin/advisor/impact"
+	"github.com/bytebase/bytebase/store"
+)
+
+const (
+	// defaultLockImpactRowCountThreshold is the default threshold of the table row count for the lock impact check.
+	defaultLockImpactRowCountThreshold = 1_000_000
+	// defaultLockImpactDataSizeThreshold is the default threshold of the table data size for the lock impact check, which is 1 GiB.
+	defaultLockImpactDataSizeThreshold = 1 << 30
+	// defaultLockImpactDurationTsThreshold is the default threshold of the estimated duration for the lock impact check, which is 10 seconds.
+	defaultLockImpactDurationTsThreshold = 10
+)
+
+// NewTaskCheckStatementLockImpactExecutor creates a task check statement lock impact executor.
+func NewTaskCheckStatementLockImpactExecutor() TaskCheckExecutor {
+	return &TaskCheckStatementLockImpactExecutor{}
+}
+
+// TaskCheckStatementLockImpactExecutor is the task check statement lock impact executor.
+// It classifies the statements by the lock taken on the tables and by the work done on the table data,
+// and warns the statements blocking the writes on the large tables for long.
+type TaskCheckStatementLockImpactExecutor struct {
+}
+
+// Run will run the task check statement lock impact executor once.
+func (*TaskCheckStatementLockImpactExecutor) Run(ctx context.Context, server *Server, taskCheckRun *api.TaskCheckRun) (result []api.TaskCheckResult, err error) {
+	task, err := server.store.GetTaskByID(ctx, taskCheckRun.TaskID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get task by ID %d", taskCheckRun.TaskID)
+	}
+	if task == nil {
+		return nil, errors.Errorf("task with ID %d not found", taskCheckRun.TaskID)
+	}
+	if task.DatabaseID == nil {
+		return nil, errors.Errorf("database not found for task %d", task.ID)
+	}
+
+	payload := &api.TaskCheckDatabaseStatementLockImpactPayload{}
+	if err := json.Unmarshal([]byte(taskCheckRun.Payload), payload); err != nil {
+		return nil, common.Wrapf(err, common.Invalid, "invalid check statement lock impact payload")
+	}
+	if !api.IsStatementLockImpactSupported(payload.DbType) {
+		return nil, common.Errorf(common.Invalid, "invalid check statement lock impact database type: %s", payload.DbType)
+	}
+	dbType, err := advisorDB.ConvertToAdvisorDBType(string(payload.DbType))
+	if err != nil {
+		return nil, err
+	}
+
+	threshold, err := server.getLockImpactThresholdSetting(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c, err := server.store.NewCatalog(ctx, *task.DatabaseID, payload.DbType)
+	if err != nil {
+		return nil, common.Wrapf(err, common.Internal, "failed to create a catalog")
+	}
+	var database *catalog.Database
+	if storeCatalog, ok := c.(*store.Catalog); ok {
+		database = storeCatalog.Database
+	}
+
+	impactList, err := impact.Analyze(impact.Context{
+		DbType:        dbType,
+		EngineVersion: task.Instance.EngineVersion,
+		Database:      database,
+	}, payload.Statement)
+	if err != nil {
+		return wrapTaskCheckError(err), nil
+	}
+	return convertLockImpactList(impactList, threshold), nil
+}
+
+func convertLockImpactList(impactList []*impact.Impact, threshold *api.LockImpactThresholdSetting) []api.TaskCheckResult {
+	var result []api.TaskCheckResult
+	for _, lockImpact := range impactList {
+		if lockImpact.Lock == impact.LockLevelNone || lockImpact.Operation == impact.OperationMetadata {
+			continue
+		}
+		exceeded := isLockImpactThresholdExceeded(lockImpact, threshold)
+		if len(exceeded) == 0 {
+			continue
+		}
+		table := lockImpact.Table
+		if lockImpact.Schema != "" {
+			table = fmt.Sprintf("%s.%s", lockImpact.Schema, lockImpact.Table)
+		}
+		result = append(result, api.TaskCheckResult{
+			Status:    api.TaskCheckStatusWarn,
+			Namespace: api.BBNamespace,
+			Code:      common.Ok.Int(),
+			Title:     fmt.Sprintf("Statement at line %d blocks the %s on table %q during a table %s", lockImpact.Line, lockImpactBlockedAccess(lockImpact.Lock), table, strings.ToLower(string(lockImpact.Operation))),
+			Content: fmt.Sprintf("%q takes %s and the table exceeds the threshold of %s. Estimated duration: %v. %s.",
+				lockImpact.Statement, lockImpact.LockMode, strings.Join(exceeded, ", "), lockImpact.EstimatedDuration, strings.Join(lockImpact.ReasonList, "; ")),
+		})
+	}
+	if len(result) == 0 {
+		result = append(result, api.TaskCheckResult{
+			Status:    api.TaskCheckStatusSuccess,
+			Namespace: api.BBNamespace,
+			Code:      common.Ok.Int(),
+			Title:     "OK",
+			Content:   "No statement blocks the writes on a large table for long",
+		})
+	}
+	return result
+}
+
+// isLockImpactThresholdExceeded returns the thresholds exceeded by the table of the impact.
+func isLockImpactThresholdExceeded(lockImpact *impact.Impact, threshold *api.LockImpactThresholdSetting) []string {
+	var exceeded []string
+	if threshold.RowCount > 0 && lockImpact.RowCount >= threshold.RowCount {
+		exceeded = append(exceeded, fmt.Sprintf("%d rows", threshold.RowCount))
+	}
+	if threshold.DataSize > 0 && lockImpact.DataSize >= threshold.DataSize {
+		exceeded = append(exceeded, fmt.Sprintf("%d bytes", threshold.DataSize))
+	}
+	if threshold.DurationTs > 0 && lockImpact.EstimatedDuration >= time.Duration(threshold.DurationTs)*time.Second {
+		exceeded = append(exceeded, fmt.Sprintf("%d seconds", threshold.DurationTs))
+	}
+	return exceeded
+}
+
+func lockImpactBlockedAccess(lock impact.LockLevel) string {
+	if lock == impact.LockLevelExclusive {
+		return "reads and writes"
+	}
+	return "writes"
+}
+
+// parseLockImpactThresholdSetting parses and validates the lock impact threshold setting value.
+func parseLockImpactThresholdSetting(value string) (*api.LockImpactThresholdSetting, error) {
+	if value == "" {
+		return &api.LockImpactThresholdSetting{
+			RowCount:   defaultLockImpactRowCountThreshold,
+			DataSize:   defaultLockImpactDataSizeThreshold,
+			DurationTs: defaultLockImpactDurationTsThreshold,
+		}, nil
+	}
+	setting := &api.LockImpactThresholdSetting{}
+	if err := json.Unmarshal([]byte(value), setting); err != nil {
+		return nil, common.Wrapf(err, common.Invalid, "invalid lock impact threshold setting")
+	}
+	if setting.RowCount < 0 || setting.DataSize < 0 || setting.DurationTs < 0 {
+		return nil, common.Errorf(common.Invalid, "invalid lock impact threshold setting %q, the thresholds must not be negative", value)
+	}
+	return setting, nil
+}
+
+// getLockImpactThresholdSetting returns the lock impact threshold setting of the workspace.
+func (s *Server) getLockImpactThresholdSetting(ctx context.Context) (*api.LockImpactThresholdSetting, error) {
+	settingName := api.SettingLockImpactThreshold
+	settingList, err := s.store.FindSetting(ctx, &api.SettingFind{Name: &settingName})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find setting %s", settingName)
+	}
+	value := ""
+	if len(settingList) > 0 {
+		value = settingList[0].Value
+	}
+	return parseLockImpactThresholdSetting(value)
+}
@@ -0,0 +1,130 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/plugin/advisor/impact"
+)
+
+func TestConvertLockImpactList(t *testing.T) {
+	threshold := &api.LockImpactThresholdSetting{
+		RowCount:   1_000_000,
+		DurationTs: 10,
+	}
+	tests := []struct {
+		impactList []*impact.Impact
+		want       []api.TaskCheckResult
+	}{
+		{
+			impactList: []*impact.Impact{
+				{
+					Statement:  "ALTER TABLE t ADD COLUMN a int",
+					Line:       1,
+					Schema:     "public",
+					Table:      "t",
+					Lock:       impact.LockLevelExclusive,
+					LockMode:   "ACCESS EXCLUSIVE",
+					Operation:  impact.OperationMetadata,
+					ReasonList: []string{`ADD COLUMN "a" only changes the metadata`},
+					RowCount:   10_000_000,
+				},
+				{
+					Statement:         "CREATE INDEX CONCURRENTLY idx_t_a ON t (a)",
+					Line:              2,
+					Schema:            "public",
+					Table:             "t",
+					Lock:              impact.LockLevelNone,
+					LockMode:          "SHARE UPDATE EXCLUSIVE",
+					Operation:         impact.OperationScan,
+					RowCount:          10_000_000,
+					EstimatedDuration: 20 * time.Second,
+				},
+				{
+					Statement:         "ALTER TABLE small ALTER COLUMN a TYPE bigint",
+					Line:              3,
+					Schema:            "public",
+					Table:             "small",
+					Lock:              impact.LockLevelExclusive,
+					LockMode:          "ACCESS EXCLUSIVE",
+					Operation:         impact.OperationRewrite,
+					RowCount:          1000,
+					EstimatedDuration: 0,
+				},
+			},
+			want: []api.TaskCheckResult{
+				{
+					Status:    api.TaskCheckStatusSuccess,
+					Namespace: api.BBNamespace,
+					Code:      common.Ok.Int(),
+					Title:     "OK",
+					Content:   "No statement blocks the writes on a large table for long",
+				},
+			},
+		},
+		{
+			impactList: []*impact.Impact{
+				{
+					Statement:         "ALTER TABLE t ALTER COLUMN a TYPE bigint",
+					Line:              4,
+					Schema:            "public",
+					Table:             "t",
+					Lock:              impact.LockLevelExclusive,
+					LockMode:          "ACCESS EXCLUSIVE",
+					Operation:         impact.OperationRewrite,
+					ReasonList:        []string{`ALTER COLUMN "a" TYPE rewrites the table`},
+					RowCount:          10_000_000,
+					EstimatedDuration: 100 * time.Second,
+				},
+				{
+					Statement:         "ALTER TABLE t2 ADD INDEX idx_a (a), LOCK=SHARED",
+					Line:              5,
+					Table:             "t2",
+					Lock:              impact.LockLevelShared,
+					LockMode:          "ALGORITHM=INPLACE, LOCK=SHARED",
+					Operation:         impact.OperationScan,
+					ReasonList:        []string{"ADD INDEX builds the index without blocking the writes", "LOCK=SHARED blocks the writes"},
+					RowCount:          100_000,
+					EstimatedDuration: 12 * time.Second,
+				},
+			},
+			want: []api.TaskCheckResult{
+				{
+					Status:    api.TaskCheckStatusWarn,
+					Namespace: api.BBNamespace,
+					Code:      common.Ok.Int(),
+					Title:     `Statement at line 4 blocks the reads and writes on table "public.t" during a table rewrite`,
+					Content:   `"ALTER TABLE t ALTER COLUMN a TYPE bigint" takes ACCESS EXCLUSIVE and the table exceeds the threshold of 1000000 rows, 10 seconds. Estimated duration: 1m40s. ALTER COLUMN "a" TYPE rewrites the table.`,
+				},
+				{
+					Status:    api.TaskCheckStatusWarn,
+					Namespace: api.BBNamespace,
+					Code:      common.Ok.Int(),
+					Title:     `Statement at line 5 blocks the writes on table "t2" during a table scan`,
+					Content:   `"ALTER TABLE t2 ADD INDEX idx_a (a), LOCK=SHARED" takes ALGORITHM=INPLACE, LOCK=SHARED and the table exceeds the threshold of 10 seconds. Estimated duration: 12s. ADD INDEX builds the index without blocking the writes; LOCK=SHARED blocks the writes.`,
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		require.Equal(t, test.want, convertLockImpactList(test.impactList, threshold))
+	}
+}
+
+func TestParseLockImpactThresholdSetting(t *testing.T) {
+	setting, err := parseLockImpactThresholdSetting("")
+	require.NoError(t, err)
+	require.Equal(t, &api.LockImpactThresholdSetting{RowCount: defaultLockImpactRowCountThreshold, DataSize: defaultLockImpactDataSizeThreshold, DurationTs: defaultLockImpactDurationTsThreshold}, setting)
+
+	setting, err = parseLockImpactThresholdSetting(`{"rowCount":0,"dataSize":1024,"durationTs":60}`)
+	require.NoError(t, err)
+	require.Equal(t, &api.LockImpactThresholdSetting{RowCount: 0, DataSize: 1024, DurationTs: 60}, setting)
+
+	_, err = parseLockImpactThresholdSetting(`{"rowCount":-1}`)
+	require.Error(t, err)
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/bytebase/bytebase/api"
 	"github.com/bytebase/bytebase/common"
 	"github.com/bytebase/bytebase/common/log"
+	"github.com/bytebase/bytebase/plugin/db"
 )
 
 // NewTaskCheckScheduler creates a task check scheduler.
@@ -26,6 +27,9 @@ func NewTaskCheckScheduler(server *Server) *TaskCheckScheduler {
 // TaskCheckScheduler is the task check scheduler.
 type TaskCheckScheduler struct {
 	executors map[api.TaskCheckType]TaskCheckExecutor
+	// pullRequestReportMutex serializes reporting the task check results to the VCS pull requests,
+	// so that the results of the same head commit are reported only once.
+	pullRequestReportMutex sync.Mutex
 
 	server *Server
 }
@@ -158,6 +162,16 @@ func (s *TaskCheckScheduler) Run(ctx context.Context, wg *sync.WaitGroup) {
 								)
 							}
 						}
+
+						s.pullRequestReportMutex.Lock()
+						defer s.pullRequestReportMutex.Unlock()
+						if err := s.server.reportPullRequestTaskCheck(ctx, taskCheckRun.TaskID); err != nil {
+							log.Warn("Failed to report task check results to the pull request",
+								zap.Int("id", taskCheckRun.ID),
+								zap.Int("task_id", taskCheckRun.TaskID),
+								zap.Error(err),
+							)
+						}
 					}(taskCheckRun)
 				}
 			}()
@@ -224,6 +238,14 @@ func (s *TaskCheckScheduler) ScheduleCheckIfNeeded(ctx context.Context, task *ap
 		return nil, errors.Wrap(err, "failed to schedule statement type task check")
 	}
 
+	if err := s.scheduleStmtDryRunTaskCheck(ctx, task, creatorID, database, statement); err != nil {
+		return nil, errors.Wrap(err, "failed to schedule statement dry run task check")
+	}
+
+	if err := s.scheduleStmtLockImpactTaskCheck(ctx, task, creatorID, database, statement); err != nil {
+		return nil, errors.Wrap(err, "failed to schedule statement lock impact task check")
+	}
+
 	taskCheckRunFind := &api.TaskCheckRunFind{
 		TaskID: &task.ID,
 	}
@@ -291,6 +313,58 @@ func (s *TaskCheckScheduler) scheduleStmtTypeTaskCheck(ctx context.Context, task
 	return nil
 }
 
+func (s *TaskCheckScheduler) scheduleStmtDryRunTaskCheck(ctx context.Context, task *api.Task, creatorID int, database *api.Database, statement string) error {
+	// The statement of the other task types isn't a plain schema migration, e.g. the SDL is the desired schema.
+	if task.Type != api.TaskDatabaseSchemaUpdate {
+		return nil
+	}
+	if !api.IsStatementDryRunSupported(database.Instance.Engine) {
+		return nil
+	}
+	payload, err := json.Marshal(api.TaskCheckDatabaseStatementDryRunPayload{
+		Statement: statement,
+		DbType:    database.Instance.Engine,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal statement dry run payload: %v", task.Name)
+	}
+	if _, err := s.server.store.CreateTaskCheckRunIfNeeded(ctx, &api.TaskCheckRunCreate{
+		CreatorID: creatorID,
+		TaskID:    task.ID,
+		Type:      api.TaskCheckDatabaseStatementDryRun,
+		Payload:   string(payload),
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *TaskCheckScheduler) scheduleStmtLockImpactTaskCheck(ctx context.Context, task *api.Task, creatorID int, database *api.Database, statement string) error {
+	// The statement of the other task types isn't a plain schema migration, e.g. the SDL is the desired schema.
+	if task.Type != api.TaskDatabaseSchemaUpdate {
+		return nil
+	}
+	if !api.IsStatementLockImpactSupported(database.Instance.Engine) {
+		return nil
+	}
+	payload, err := json.Marshal(api.TaskCheckDatabaseStatementLockImpactPayload{
+		Statement: statement,
+		DbType:    database.Instance.Engine,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal statement lock impact payload: %v", task.Name)
+	}
+	if _, err := s.server.store.CreateTaskCheckRunIfNeeded(ctx, &api.TaskCheckRunCreate{
+		CreatorID: creatorID,
+		TaskID:    task.ID,
+		Type:      api.TaskCheckDatabaseStatementLockImpact,
+		Payload:   string(payload),
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
 func (s *TaskCheckScheduler) scheduleSQLReviewTaskCheck(ctx context.Context, task *api.Task, creatorID int, database *api.Database, statement string) error {
 	if !api.IsSQLReviewSupported(database.Instance.Engine, s.server.profile.Mode) {
 		return nil
@@ -382,10 +456,14 @@ func (s *TaskCheckScheduler) schedulePITRTaskCheck(ctx context.Context, task *ap
 	if task.Type != api.TaskDatabaseRestorePITRRestore {
 		return nil
 	}
+	checkType := api.TaskCheckPITRMySQL
+	if task.Instance.Engine == db.Postgres {
+		checkType = api.TaskCheckPITRPostgres
+	}
 	if _, err := s.server.store.CreateTaskCheckRunIfNeeded(ctx, &api.TaskCheckRunCreate{
 		CreatorID: creatorID,
 		TaskID:    task.ID,
-		Type:      api.TaskCheckPITRMySQL,
+		Type:      checkType,
 	}); err != nil {
 		return err
 	}
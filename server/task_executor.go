@@ -2,11 +2,14 @@ package server
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
@@ -124,13 +127,28 @@ func preMigration(ctx context.Context, server *Server, task *api.Task, migration
 
 func executeMigration(ctx context.Context, server *Server, task *api.Task, statement string, mi *db.MigrationInfo) (migrationID int64, schema string, err error) {
 	statement = strings.TrimSpace(statement)
+	driver, err := getMigrationDriver(ctx, server, task, statement, mi)
+	if err != nil {
+		return 0, "", err
+	}
+	defer driver.Close(ctx)
+
+	migrationID, schema, err = driver.ExecuteMigration(ctx, mi, statement)
+	if err != nil {
+		return 0, "", err
+	}
+	return migrationID, schema, nil
+}
+
+// getMigrationDriver opens the admin driver of the task database and checks the migration schema.
+// The caller should close the returned driver.
+func getMigrationDriver(ctx context.Context, server *Server, task *api.Task, statement string, mi *db.MigrationInfo) (db.Driver, error) {
 	databaseName := task.Database.Name
 
 	driver, err := server.getAdminDatabaseDriver(ctx, task.Instance, databaseName)
 	if err != nil {
-		return 0, "", err
+		return nil, err
 	}
-	defer driver.Close(ctx)
 
 	log.Debug("Start migration...",
 		zap.String("instance", task.Instance.Name),
@@ -142,17 +160,14 @@ func executeMigration(ctx context.Context, server *Server, task *api.Task, state
 
 	setup, err := driver.NeedsSetupMigration(ctx)
 	if err != nil {
-		return 0, "", errors.Wrapf(err, "failed to check migration setup for instance %q", task.Instance.Name)
+		driver.Close(ctx)
+		return nil, errors.Wrapf(err, "failed to check migration setup for instance %q", task.Instance.Name)
 	}
 	if setup {
-		return 0, "", common.Errorf(common.MigrationSchemaMissing, "missing migration schema for instance %q", task.Instance.Name)
+		driver.Close(ctx)
+		return nil, common.Errorf(common.MigrationSchemaMissing, "missing migration schema for instance %q", task.Instance.Name)
 	}
-
-	migrationID, schema, err = driver.ExecuteMigration(ctx, mi, statement)
-	if err != nil {
-		return 0, "", err
-	}
-	return migrationID, schema, nil
+	return driver, nil
 }
 
 func postMigration(ctx context.Context, server *Server, task *api.Task, vcsPushEvent *vcsPlugin.PushEvent, mi *db.MigrationInfo, migrationID int64, schema string) (bool, *api.TaskRunResultPayload, error) {
@@ -178,27 +193,16 @@ func postMigration(ctx context.Context, server *Server, task *api.Task, vcsPushE
 		return true, nil, err
 	}
 	if writeBack && issue != nil {
-		if project.TenantMode == api.TenantModeTenant {
-			// For tenant mode project, we will only write back once and we happen to write back on lastTask done.
-			var lastTask *api.Task
-			for i := len(issue.Pipeline.StageList) - 1; i >= 0; i-- {
-				stage := issue.Pipeline.StageList[i]
-				if len(stage.TaskList) > 0 {
-					lastTask = stage.TaskList[len(stage.TaskList)-1]
-					break
-				}
-			}
-			// Not the last task yet.
-			if lastTask != nil && task.ID != lastTask.ID {
-				writeBack = false
-			}
-		}
+		writeBack = isWriteBackTask(project, issue, task)
 	}
+	// We also write back the changelog after migration for VCS-based projects if the changelog path template is specified.
+	writeBackChangelog := (vcsPushEvent != nil) && (repo.ChangelogPathTemplate != "") && issue != nil && isWriteBackTask(project, issue, task)
 
 	log.Debug("Post migration...",
 		zap.String("instance", task.Instance.Name),
 		zap.String("database", databaseName),
 		zap.Bool("writeBack", writeBack),
+		zap.Bool("writeBackChangelog", writeBackChangelog),
 	)
 
 	if writeBack {
@@ -235,46 +239,19 @@ func postMigration(ctx context.Context, server *Server, task *api.Task, vcsPushE
 			return true, nil, err
 		}
 
-		// Create file commit activity
-		{
-			payload, err := json.Marshal(api.ActivityPipelineTaskFileCommitPayload{
-				TaskID:             task.ID,
-				VCSInstanceURL:     repo.VCS.InstanceURL,
-				RepositoryFullPath: vcsPushEvent.RepositoryFullPath,
-				Branch:             branch,
-				FilePath:           latestSchemaFile,
-				CommitID:           commitID,
-			})
-			if err != nil {
-				log.Error("Failed to marshal file commit activity after writing back the latest schema",
-					zap.Int("task_id", task.ID),
-					zap.String("repository", repo.WebURL),
-					zap.String("file_path", latestSchemaFile),
-					zap.Error(err),
-				)
-			}
-
-			activityCreate := &api.ActivityCreate{
-				CreatorID:   task.CreatorID,
-				ContainerID: task.PipelineID,
-				Type:        api.ActivityPipelineTaskFileCommit,
-				Level:       api.ActivityInfo,
-				Comment: fmt.Sprintf("Committed the latest schema after applying migration version %s to %q.",
-					mi.Version,
-					dbName,
-				),
-				Payload: string(payload),
-			}
+		createFileCommitActivity(ctx, server, task, repo, vcsPushEvent, branch, latestSchemaFile, commitID,
+			fmt.Sprintf("Committed the latest schema after applying migration version %s to %q.", mi.Version, dbName),
+		)
+	}
 
-			_, err = server.ActivityManager.CreateActivity(ctx, activityCreate, &ActivityMeta{})
-			if err != nil {
-				log.Error("Failed to create file commit activity after writing back the latest schema",
-					zap.Int("task_id", task.ID),
-					zap.String("repository", repo.WebURL),
-					zap.String("file_path", latestSchemaFile),
-					zap.Error(err),
-				)
-			}
+	if writeBackChangelog {
+		// The migration has been applied, so we only log the error instead of failing the task.
+		if err := writeBackTaskChangelog(ctx, server, task, repo, issue, vcsPushEvent, project, mi, migrationID); err != nil {
+			log.Error("Failed to write back the changelog after migration",
+				zap.Int("task_id", task.ID),
+				zap.String("repository", repo.WebURL),
+				zap.Error(err),
+			)
 		}
 	}
 
@@ -482,3 +459,226 @@ func writeBackLatestSchema(ctx context.Context, server *Server, repository *api.
 	}
 	return schemaFileMeta.LastCommitID, nil
 }
+
+// isWriteBackTask returns whether the files should be written back to the repository after the task completes.
+// For tenant mode project, we will only write back once and we happen to write back on lastTask done.
+func isWriteBackTask(project *api.Project, issue *api.Issue, task *api.Task) bool {
+	if project.TenantMode != api.TenantModeTenant {
+		return true
+	}
+	var lastTask *api.Task
+	for i := len(issue.Pipeline.StageList) - 1; i >= 0; i-- {
+		stage := issue.Pipeline.StageList[i]
+		if len(stage.TaskList) > 0 {
+			lastTask = stage.TaskList[len(stage.TaskList)-1]
+			break
+		}
+	}
+	// Not the last task yet.
+	return lastTask == nil || task.ID == lastTask.ID
+}
+
+// createFileCommitActivity creates the activity for the file committed to the repository after the task completes.
+func createFileCommitActivity(ctx context.Context, server *Server, task *api.Task, repo *api.Repository, pushEvent *vcsPlugin.PushEvent, branch, filePath, commitID, comment string) {
+	payload, err := json.Marshal(api.ActivityPipelineTaskFileCommitPayload{
+		TaskID:             task.ID,
+		VCSInstanceURL:     repo.VCS.InstanceURL,
+		RepositoryFullPath: pushEvent.RepositoryFullPath,
+		Branch:             branch,
+		FilePath:           filePath,
+		CommitID:           commitID,
+	})
+	if err != nil {
+		log.Error("Failed to marshal file commit activity after writing back the file",
+			zap.Int("task_id", task.ID),
+			zap.String("repository", repo.WebURL),
+			zap.String("file_path", filePath),
+			zap.Error(err),
+		)
+	}
+
+	activityCreate := &api.ActivityCreate{
+		CreatorID:   task.CreatorID,
+		ContainerID: task.PipelineID,
+		Type:        api.ActivityPipelineTaskFileCommit,
+		Level:       api.ActivityInfo,
+		Comment:     comment,
+		Payload:     string(payload),
+	}
+
+	if _, err := server.ActivityManager.CreateActivity(ctx, activityCreate, &ActivityMeta{}); err != nil {
+		log.Error("Failed to create file commit activity after writing back the file",
+			zap.Int("task_id", task.ID),
+			zap.String("repository", repo.WebURL),
+			zap.String("file_path", filePath),
+			zap.Error(err),
+		)
+	}
+}
+
+// writeBackTaskChangelog adds the entry of the applied migration to the changelog of the database,
+// and commits the changelog to the same branch as the push event.
+func writeBackTaskChangelog(ctx context.Context, server *Server, task *api.Task, repository *api.Repository, issue *api.Issue, pushEvent *vcsPlugin.PushEvent, project *api.Project, mi *db.MigrationInfo, migrationID int64) error {
+	dbName, err := api.GetBaseDatabaseName(mi.Database, project.DBNameTemplate, task.Database.Labels)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get BaseDatabaseName for instance %q, database %q", task.Instance.Name, task.Database.Name)
+	}
+	changelogFile := filepath.Join(repository.BaseDirectory, repository.ChangelogPathTemplate)
+	changelogFile = strings.ReplaceAll(changelogFile, api.EnvironmentToken, mi.Environment)
+	changelogFile = strings.ReplaceAll(changelogFile, api.DBNameToken, dbName)
+
+	history, err := getMigrationHistory(ctx, server, task, migrationID)
+	if err != nil {
+		return err
+	}
+	branch, err := vcsPlugin.Branch(pushEvent.Ref)
+	if err != nil {
+		return err
+	}
+
+	// Retrieve the latest AccessToken and RefreshToken before each VCS call as the previous VCS call may have
+	// updated the stored token pair. VCS will fetch and store the new token pair if the existing token pair has expired.
+	getOauthContext := func() (common.OauthContext, error) {
+		repo, err := server.store.GetRepository(ctx, &api.RepositoryFind{ID: &repository.ID})
+		if err != nil {
+			return common.OauthContext{}, errors.Wrap(err, "failed to fetch repository for changelog write-back")
+		}
+		if repo == nil {
+			return common.OauthContext{}, errors.Errorf("repository not found for changelog write-back: %v", repository.ID)
+		}
+		return common.OauthContext{
+			ClientID:     repo.VCS.ApplicationID,
+			ClientSecret: repo.VCS.Secret,
+			AccessToken:  repo.AccessToken,
+			RefreshToken: repo.RefreshToken,
+			Refresher:    server.refreshToken(ctx, repo.WebURL),
+		}, nil
+	}
+	provider := vcsPlugin.Get(repository.VCS.Type, vcsPlugin.ProviderConfig{})
+
+	oauthCtx, err := getOauthContext()
+	if err != nil {
+		return err
+	}
+	content := ""
+	lastCommitID := ""
+	changelogFileMeta, err := provider.ReadFileMeta(ctx, oauthCtx, repository.VCS.InstanceURL, repository.ExternalID, changelogFile, branch)
+	if err != nil {
+		if common.ErrorCode(err) != common.NotFound {
+			return errors.Wrap(err, "failed to fetch changelog")
+		}
+	} else {
+		lastCommitID = changelogFileMeta.LastCommitID
+		if oauthCtx, err = getOauthContext(); err != nil {
+			return err
+		}
+		if content, err = provider.ReadFileContent(ctx, oauthCtx, repository.VCS.InstanceURL, repository.ExternalID, changelogFile, branch); err != nil {
+			return errors.Wrap(err, "failed to read changelog")
+		}
+	}
+
+	issueURL := fmt.Sprintf("%s/issue/%s", server.profile.ExternalURL, api.IssueSlug(issue))
+	changelogFileCommit := vcsPlugin.FileCommitCreate{
+		Branch:        branch,
+		CommitMessage: getChangelogCommitMessage(repository.ChangelogCommitMessageTemplate, dbName, mi, issue, issueURL),
+		Content:       insertChangelogEntry(content, dbName, buildChangelogEntry(history, mi.Environment, issue, issueURL)),
+		LastCommitID:  lastCommitID,
+	}
+	if oauthCtx, err = getOauthContext(); err != nil {
+		return err
+	}
+	if lastCommitID == "" {
+		log.Debug("Create changelog file", zap.String("changelog_file", changelogFile))
+		if err := provider.CreateFile(ctx, oauthCtx, repository.VCS.InstanceURL, repository.ExternalID, changelogFile, changelogFileCommit); err != nil {
+			return errors.Wrapf(err, "failed to create changelog after applying migration %s to %q", mi.Version, mi.Database)
+		}
+	} else {
+		log.Debug("Update changelog file", zap.String("changelog_file", changelogFile))
+		if err := provider.OverwriteFile(ctx, oauthCtx, repository.VCS.InstanceURL, repository.ExternalID, changelogFile, changelogFileCommit); err != nil {
+			return errors.Wrapf(err, "failed to update changelog after applying migration %s to %q", mi.Version, mi.Database)
+		}
+	}
+
+	// VCS such as GitLab API doesn't return the commit on write, so we have to call ReadFileMeta again
+	if oauthCtx, err = getOauthContext(); err != nil {
+		return err
+	}
+	changelogFileMeta, err = provider.ReadFileMeta(ctx, oauthCtx, repository.VCS.InstanceURL, repository.ExternalID, changelogFile, branch)
+	if err != nil {
+		return errors.Wrapf(err, "failed to fetch changelog file %s after update", changelogFile)
+	}
+
+	createFileCommitActivity(ctx, server, task, repository, pushEvent, branch, changelogFile, changelogFileMeta.LastCommitID,
+		fmt.Sprintf("Committed the changelog after applying migration version %s to %q.", mi.Version, dbName),
+	)
+	return nil
+}
+
+// getMigrationHistory gets the migration history of the task database by ID.
+func getMigrationHistory(ctx context.Context, server *Server, task *api.Task, migrationID int64) (*db.MigrationHistory, error) {
+	driver, err := server.getAdminDatabaseDriver(ctx, task.Instance, "" /* databaseName */)
+	if err != nil {
+		return nil, err
+	}
+	defer driver.Close(ctx)
+
+	id := int(migrationID)
+	list, err := driver.FindMigrationHistoryList(ctx, &db.MigrationHistoryFind{
+		ID:       &id,
+		Database: &task.Database.Name,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to fetch migration history %d", migrationID)
+	}
+	if len(list) == 0 {
+		return nil, errors.Errorf("migration history not found with ID %d", migrationID)
+	}
+	return list[0], nil
+}
+
+// buildChangelogEntry builds the Markdown changelog entry of the migration history.
+func buildChangelogEntry(history *db.MigrationHistory, environment string, issue *api.Issue, issueURL string) string {
+	digest := sha256.Sum256([]byte(history.Statement))
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s\n\n", history.Version)
+	fmt.Fprintf(&b, "- Type: %s\n", history.Type)
+	fmt.Fprintf(&b, "- Environment: %s\n", environment)
+	fmt.Fprintf(&b, "- Issue: [#%d %s](%s)\n", issue.ID, issue.Name, issueURL)
+	fmt.Fprintf(&b, "- Author: %s\n", history.Creator)
+	fmt.Fprintf(&b, "- Applied at: %s\n", time.Unix(history.CreatedTs, 0).UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "- Duration: %s\n", time.Duration(history.ExecutionDurationNs).Round(time.Millisecond))
+	fmt.Fprintf(&b, "- Statement digest: `sha256:%s`\n", hex.EncodeToString(digest[:]))
+	return b.String()
+}
+
+// insertChangelogEntry inserts the entry before the existing entries, so the latest migration goes first.
+// The changelog is created with the title if it's empty.
+func insertChangelogEntry(content, dbName, entry string) string {
+	if strings.TrimSpace(content) == "" {
+		return fmt.Sprintf("# Changelog of %s\n\nTHIS FILE IS AUTO-GENERATED BY BYTEBASE\n\n%s", dbName, entry)
+	}
+	if strings.HasPrefix(content, "## ") {
+		return entry + "\n" + content
+	}
+	if i := strings.Index(content, "\n## "); i >= 0 {
+		return content[:i+1] + entry + "\n" + content[i+1:]
+	}
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return content + "\n" + entry
+}
+
+// getChangelogCommitMessage renders the commit message template for writing back the changelog, or
+// returns the default commit message if the template is empty.
+func getChangelogCommitMessage(commitMessageTemplate, dbName string, mi *db.MigrationInfo, issue *api.Issue, issueURL string) string {
+	if commitMessageTemplate == "" {
+		return fmt.Sprintf("[Bytebase] Update changelog for %q after migration %s\n\nTHIS COMMIT IS AUTO-GENERATED BY BYTEBASE\n\n%s", dbName, mi.Version, issueURL)
+	}
+	return strings.NewReplacer(
+		api.DBNameToken, dbName,
+		api.EnvironmentToken, mi.Environment,
+		api.VersionToken, mi.Version,
+		api.IssueIDToken, strconv.Itoa(issue.ID),
+	).Replace(commitMessageTemplate)
+}
@@ -3,6 +3,8 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"strings"
 	"sync/atomic"
 
 	"github.com/pkg/errors"
@@ -28,6 +30,10 @@ func (*DataUpdateTaskExecutor) RunOnce(ctx context.Context, server *Server, task
 		return true, nil, errors.Wrap(err, "invalid database data update payload")
 	}
 
+	// We snapshot the affected rows to generate the rollback statement for MySQL and Postgres.
+	if task.Instance.Engine == db.MySQL || task.Instance.Engine == db.Postgres {
+		return runDataUpdateWithRollback(ctx, server, task, payload)
+	}
 	return runMigration(ctx, server, task, db.Data, payload.Statement, payload.SchemaVersion, payload.VCSPushEvent)
 }
 
@@ -40,3 +46,41 @@ func (exec *DataUpdateTaskExecutor) IsCompleted() bool {
 func (*DataUpdateTaskExecutor) GetProgress() api.Progress {
 	return api.Progress{}
 }
+
+func runDataUpdateWithRollback(ctx context.Context, server *Server, task *api.Task, payload *api.TaskDatabaseDataUpdatePayload) (terminated bool, result *api.TaskRunResultPayload, err error) {
+	mi, err := preMigration(ctx, server, task, db.Data, payload.Statement, payload.SchemaVersion, payload.VCSPushEvent)
+	if err != nil {
+		return true, nil, err
+	}
+
+	statement := strings.TrimSpace(payload.Statement)
+	driver, err := getMigrationDriver(ctx, server, task, statement, mi)
+	if err != nil {
+		return true, nil, err
+	}
+	defer driver.Close(ctx)
+
+	var migrationID int64
+	var schema string
+	var rollback *db.RollbackResult
+	if executor, ok := driver.(db.RollbackExecutor); ok {
+		migrationID, schema, rollback, err = executor.ExecuteMigrationWithRollback(ctx, mi, statement)
+	} else {
+		migrationID, schema, err = driver.ExecuteMigration(ctx, mi, statement)
+	}
+	if err != nil {
+		return true, nil, err
+	}
+
+	terminated, result, err = postMigration(ctx, server, task, payload.VCSPushEvent, mi, migrationID, schema)
+	if err != nil || result == nil || rollback == nil {
+		return terminated, result, err
+	}
+	if rollback.Error != "" {
+		result.Detail = fmt.Sprintf("%s Failed to generate the rollback statement: %s.", result.Detail, rollback.Error)
+	} else {
+		result.Detail = fmt.Sprintf("%s Changed %d rows.", result.Detail, rollback.AffectedRowCount)
+		result.RollbackStatement = rollback.Statement
+	}
+	return terminated, result, nil
+}
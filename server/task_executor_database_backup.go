@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
@@ -15,6 +17,9 @@ import (
 	"github.com/bytebase/bytebase/api"
 	"github.com/bytebase/bytebase/common/log"
 	"github.com/bytebase/bytebase/plugin/db"
+	"github.com/bytebase/bytebase/plugin/db/pg"
+	"github.com/bytebase/bytebase/plugin/storage"
+	"github.com/bytebase/bytebase/plugin/storage/codec"
 )
 
 const (
@@ -70,7 +75,7 @@ func (exec *DatabaseBackupTaskExecutor) RunOnce(ctx context.Context, server *Ser
 	}
 
 	log.Debug("Start database backup.", zap.String("instance", task.Instance.Name), zap.String("database", task.Database.Name), zap.String("backup", backup.Name))
-	backupPayload, backupErr := exec.backupDatabase(ctx, server, task.Instance, task.Database.Name, backup)
+	backupPayload, backupErr := exec.backupDatabase(ctx, server, task.Instance, task.Database.Name, backup, payload.Schedule)
 	backupStatus := string(api.BackupStatusDone)
 	comment := ""
 	if backupErr != nil {
@@ -127,21 +132,51 @@ func getAvailableFSSpace(path string) (uint64, error) {
 	return stat.Bavail * uint64(stat.Bsize), nil
 }
 
-func dumpBackupFile(ctx context.Context, driver db.Driver, databaseName, backupFilePath string) (string, error) {
+func dumpBackupFile(ctx context.Context, driver db.Driver, databaseName, backupFilePath, schedule string, setting *api.BackupArtifactSetting, key []byte) (string, error) {
 	backupFile, err := os.Create(backupFilePath)
 	if err != nil {
 		return "", errors.Errorf("failed to open backup path %q", backupFilePath)
 	}
 	defer backupFile.Close()
-	payload, err := driver.Dump(ctx, databaseName, backupFile, false /* schemaOnly */)
+	artifactWriter, err := codec.NewWriter(backupFile, setting.Compression, setting.Encryption, key)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to create backup artifact writer for %q", backupFilePath)
+	}
+	payload, err := driver.Dump(ctx, databaseName, artifactWriter, false /* schemaOnly */)
 	if err != nil {
 		return "", errors.Wrapf(err, "failed to dump database %q to local backup file %q", databaseName, backupFilePath)
 	}
-	return payload, nil
+	if err := artifactWriter.Close(); err != nil {
+		return "", errors.Wrapf(err, "failed to write local backup file %q", backupFilePath)
+	}
+	if err := backupFile.Sync(); err != nil {
+		return "", errors.Wrapf(err, "failed to sync local backup file %q", backupFilePath)
+	}
+
+	// Record the codec and the schedule in the backup payload, so that we know how the backup artifact is encoded and when it expires.
+	backupPayload := api.BackupPayload{}
+	if payload != "" {
+		if err := json.Unmarshal([]byte(payload), &backupPayload); err != nil {
+			return "", errors.Wrapf(err, "failed to unmarshal backup payload %q", payload)
+		}
+	}
+	backupPayload.Compression = setting.Compression
+	backupPayload.Encryption = setting.Encryption
+	backupPayload.Schedule = schedule
+	bytes, err := json.Marshal(backupPayload)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal backup payload")
+	}
+	return string(bytes), nil
 }
 
 // backupDatabase will take a backup of a database.
-func (*DatabaseBackupTaskExecutor) backupDatabase(ctx context.Context, server *Server, instance *api.Instance, databaseName string, backup *api.Backup) (string, error) {
+func (*DatabaseBackupTaskExecutor) backupDatabase(ctx context.Context, server *Server, instance *api.Instance, databaseName string, backup *api.Backup, schedule string) (string, error) {
+	setting, key, err := server.getBackupArtifactSetting(ctx)
+	if err != nil {
+		return "", err
+	}
+
 	driver, err := server.getAdminDatabaseDriver(ctx, instance, databaseName)
 	if err != nil {
 		return "", err
@@ -149,36 +184,155 @@ func (*DatabaseBackupTaskExecutor) backupDatabase(ctx context.Context, server *S
 	defer driver.Close(ctx)
 
 	backupFilePathLocal := filepath.Join(server.profile.DataDir, backup.Path)
-	payload, err := dumpBackupFile(ctx, driver, databaseName, backupFilePathLocal)
+	payload, err := dumpBackupFile(ctx, driver, databaseName, backupFilePathLocal, schedule, setting, key)
 	if err != nil {
 		return "", errors.Wrapf(err, "failed to dump backup file %q", backupFilePathLocal)
 	}
+	if instance.Engine == db.Postgres {
+		if payload, err = takeBaseBackup(ctx, server, instance, driver, backup, payload, setting, key); err != nil {
+			return "", err
+		}
+	}
+
+	if backup.StorageBackend == api.BackupStorageBackendLocal {
+		return payload, nil
+	}
+
+	client, err := server.getBackupStorage(backup.StorageBackend)
+	if err != nil {
+		return "", err
+	}
+	if err := uploadBackupFileToCloud(ctx, client, server.profile.DataDir, backup.Path); err != nil {
+		return "", errors.Wrapf(err, "failed to upload backup to %s", backup.StorageBackend)
+	}
+	return payload, nil
+}
+
+func uploadBackupFileToCloud(ctx context.Context, client storage.Client, dataDir, filePath string) error {
+	filePathLocal := filepath.Join(dataDir, filePath)
+	log.Debug("Uploading backup to the cloud storage.", zap.String("bucket", client.GetBucket()), zap.String("path", filePathLocal))
+	bucketFileToUpload, err := os.Open(filePathLocal)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open backup file %q for uploading to the cloud storage", filePathLocal)
+	}
+	defer bucketFileToUpload.Close()
+
+	if err := client.UploadObject(ctx, filepath.ToSlash(filePath), bucketFileToUpload); err != nil {
+		return err
+	}
+	log.Debug("Successfully uploaded backup to the cloud storage.")
+
+	if err := os.Remove(filePathLocal); err != nil {
+		log.Warn("Failed to remove the local backup file after uploading to the cloud storage.", zap.String("path", filePathLocal), zap.Error(err))
+	} else {
+		log.Debug("Successfully removed the local backup file after uploading to the cloud storage.", zap.String("path", filePathLocal))
+	}
+	return nil
+}
+
+// sharedBaseBackup is the latest base backup of a PostgreSQL instance, which is shared by the database backups of the instance in the same hour,
+// i.e., the same slot of the backup schedules.
+type sharedBaseBackup struct {
+	mu      sync.Mutex
+	hour    time.Time
+	walInfo *api.WALInfo
+}
 
-	switch backup.StorageBackend {
-	case api.BackupStorageBackendLocal:
+// takeBaseBackup records the base backup of the PostgreSQL instance in the backup payload if the instance is opted in to PITR,
+// so that the database can be recovered to any point in time after the base backup.
+// The base backup is taken if there is none of the instance taken in this hour, otherwise the backup shares it.
+func takeBaseBackup(ctx context.Context, server *Server, instance *api.Instance, driver db.Driver, backup *api.Backup, payload string, setting *api.BackupArtifactSetting, key []byte) (string, error) {
+	pitrSetting, err := server.getPITRSetting(ctx)
+	if err != nil {
+		return "", err
+	}
+	if getPITRInstanceSetting(pitrSetting, instance.ID) == nil {
+		return payload, nil
+	}
+	pgDriver, ok := driver.(*pg.Driver)
+	if !ok {
+		return "", errors.Errorf("[internal] cast driver to pg.Driver failed")
+	}
+	if err := pgDriver.CheckWALArchivingForPITR(ctx); err != nil {
+		log.Warn("Skip taking the base backup since WAL archiving is not available.", zap.String("instance", instance.Name), zap.String("backup", backup.Name), zap.Error(err))
 		return payload, nil
-	case api.BackupStorageBackendS3:
-		log.Debug("Uploading backup to s3 bucket.", zap.String("bucket", server.s3Client.GetBucket()), zap.String("path", backupFilePathLocal))
-		bucketFileToUpload, err := os.Open(backupFilePathLocal)
+	}
+
+	value, _ := server.baseBackupMap.LoadOrStore(instance.ID, &sharedBaseBackup{})
+	shared := value.(*sharedBaseBackup)
+	shared.mu.Lock()
+	defer shared.mu.Unlock()
+	now := time.Now().UTC()
+	if shared.walInfo == nil || !shared.hour.Equal(now.Truncate(time.Hour)) {
+		walInfo, err := takeInstanceBaseBackup(ctx, server, instance, pgDriver, backup.StorageBackend, now, setting, key)
 		if err != nil {
-			return "", errors.Wrapf(err, "failed to open backup file %q for uploading to s3 bucket", backupFilePathLocal)
+			return "", err
 		}
-		defer bucketFileToUpload.Close()
+		shared.hour = now.Truncate(time.Hour)
+		shared.walInfo = walInfo
+	}
 
-		if _, err := server.s3Client.UploadObject(ctx, backup.Path, bucketFileToUpload); err != nil {
-			return "", errors.Wrapf(err, "failed to upload backup to AWS S3")
+	backupPayload := api.BackupPayload{}
+	if err := json.Unmarshal([]byte(payload), &backupPayload); err != nil {
+		return "", errors.Wrapf(err, "failed to unmarshal backup payload %q", payload)
+	}
+	backupPayload.WALInfo = shared.walInfo
+	bytes, err := json.Marshal(backupPayload)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal backup payload")
+	}
+	return string(bytes), nil
+}
+
+// takeInstanceBaseBackup takes a base backup of the PostgreSQL instance and uploads it to the cloud storage if configured.
+func takeInstanceBaseBackup(ctx context.Context, server *Server, instance *api.Instance, driver *pg.Driver, storageBackend api.BackupStorageBackend, now time.Time, setting *api.BackupArtifactSetting, key []byte) (*api.WALInfo, error) {
+	baseBackupPath := getBaseBackupRelativeFilePath(instance.ID, now)
+	baseBackupPathLocal := filepath.Join(server.profile.DataDir, baseBackupPath)
+	if err := os.MkdirAll(filepath.Dir(baseBackupPathLocal), os.ModePerm); err != nil {
+		return nil, errors.Wrapf(err, "failed to create base backup directory %q", filepath.Dir(baseBackupPathLocal))
+	}
+	startWALFileName, err := func() (string, error) {
+		baseBackupFile, err := os.Create(baseBackupPathLocal)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to create base backup file %q", baseBackupPathLocal)
+		}
+		defer baseBackupFile.Close()
+		artifactWriter, err := codec.NewWriter(baseBackupFile, setting.Compression, setting.Encryption, key)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to create backup artifact writer for %q", baseBackupPathLocal)
 		}
-		log.Debug("Successfully uploaded backup to s3 bucket.")
+		startWALFileName, err := driver.BaseBackup(ctx, artifactWriter)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to take base backup to %q", baseBackupPathLocal)
+		}
+		if err := artifactWriter.Close(); err != nil {
+			return "", errors.Wrapf(err, "failed to write base backup file %q", baseBackupPathLocal)
+		}
+		if err := baseBackupFile.Sync(); err != nil {
+			return "", errors.Wrapf(err, "failed to sync base backup file %q", baseBackupPathLocal)
+		}
+		return startWALFileName, nil
+	}()
+	if err != nil {
+		if err := os.Remove(baseBackupPathLocal); err != nil && !os.IsNotExist(err) {
+			log.Warn("Failed to remove the incomplete base backup file.", zap.String("path", baseBackupPathLocal), zap.Error(err))
+		}
+		return nil, err
+	}
 
-		if err := os.Remove(backupFilePathLocal); err != nil {
-			log.Warn("Failed to remove the local backup file after uploading to s3 bucket.", zap.String("path", backupFilePathLocal), zap.Error(err))
-		} else {
-			log.Debug("Successfully removed the local backup file after uploading to s3 bucket.", zap.String("path", backupFilePathLocal))
+	if storageBackend != api.BackupStorageBackendLocal {
+		client, err := server.getBackupStorage(storageBackend)
+		if err != nil {
+			return nil, err
+		}
+		if err := uploadBackupFileToCloud(ctx, client, server.profile.DataDir, baseBackupPath); err != nil {
+			return nil, errors.Wrapf(err, "failed to upload base backup to %s", storageBackend)
 		}
-		return payload, nil
-	default:
-		return "", errors.Errorf("backup to %s not implemented yet", backup.StorageBackend)
 	}
+	return &api.WALInfo{
+		BaseBackupPath:   baseBackupPath,
+		StartWALFileName: startWALFileName,
+	}, nil
 }
 
 // Get backup dir relative to the data dir.
@@ -191,6 +345,22 @@ func getBackupRelativeFilePath(databaseID int, name string) string {
 	return filepath.Join(dir, fmt.Sprintf("%s.sql", name))
 }
 
+// getBaseBackupRelativeDir returns the directory of the PostgreSQL base backups of the instance, relative to the data dir.
+func getBaseBackupRelativeDir(instanceID int) string {
+	return filepath.Join("backup", "base", fmt.Sprintf("%d", instanceID))
+}
+
+const (
+	// baseBackupTimeLayout is the layout of the start time in the PostgreSQL base backup file name.
+	baseBackupTimeLayout = "20060102T150405"
+	baseBackupFileSuffix = ".base.tar"
+)
+
+// getBaseBackupRelativeFilePath returns the path of the PostgreSQL base backup of the instance taken at the time.
+func getBaseBackupRelativeFilePath(instanceID int, t time.Time) string {
+	return filepath.Join(getBaseBackupRelativeDir(instanceID), fmt.Sprintf("%s%s", t.Format(baseBackupTimeLayout), baseBackupFileSuffix))
+}
+
 func getBackupAbsFilePath(dataDir string, databaseID int, name string) string {
 	path := getBackupRelativeFilePath(databaseID, name)
 	return filepath.Join(dataDir, path)
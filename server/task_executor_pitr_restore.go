@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path"
 	"path/filepath"
@@ -21,7 +22,7 @@ import (
 	"github.com/bytebase/bytebase/plugin/db/mysql"
 	"github.com/bytebase/bytebase/plugin/db/pg"
 	"github.com/bytebase/bytebase/plugin/db/util"
-	bbs3 "github.com/bytebase/bytebase/plugin/storage/s3"
+	"github.com/bytebase/bytebase/plugin/storage"
 	"github.com/bytebase/bytebase/store"
 )
 
@@ -64,6 +65,11 @@ func (exec *PITRRestoreTaskExecutor) RunOnce(ctx context.Context, server *Server
 		return true, resultPayload, err
 	}
 
+	if task.Instance.Engine == db.Postgres {
+		resultPayload, err := exec.doPITRRestorePostgres(ctx, server, task, payload)
+		return true, resultPayload, err
+	}
+
 	resultPayload, err := exec.doPITRRestore(ctx, server, task, payload)
 	return true, resultPayload, err
 }
@@ -211,13 +217,13 @@ func (exec *PITRRestoreTaskExecutor) doPITRRestore(ctx context.Context, server *
 	}
 
 	log.Debug("Downloading all binlog files")
-	if err := mysqlSourceDriver.FetchAllBinlogFiles(ctx, true /* downloadLatestBinlogFile */, server.s3Client); err != nil {
+	if err := mysqlSourceDriver.FetchAllBinlogFiles(ctx, true /* downloadLatestBinlogFile */, server.backupStorage); err != nil {
 		return nil, err
 	}
 
 	targetTs := *payload.PointInTimeTs
 	log.Debug("Getting latest backup before or equal to targetTs", zap.Int64("targetTs", targetTs))
-	backup, targetBinlogInfo, err := mysqlSourceDriver.GetLatestBackupBeforeOrEqualTs(ctx, backupList, targetTs, server.s3Client)
+	backup, targetBinlogInfo, err := mysqlSourceDriver.GetLatestBackupBeforeOrEqualTs(ctx, backupList, targetTs, server.backupStorage)
 	if err != nil {
 		targetTsHuman := time.Unix(targetTs, 0).Format(time.RFC822)
 		log.Error("Failed to get backup before or equal to time",
@@ -231,14 +237,18 @@ func (exec *PITRRestoreTaskExecutor) doPITRRestore(ctx context.Context, server *
 	log.Debug("Got latest backup before or equal to targetTs", zap.String("backup", backup.Name))
 
 	backupAbsPathLocal := getBackupAbsFilePath(server.profile.DataDir, backup.DatabaseID, backup.Name)
-	if backup.StorageBackend == api.BackupStorageBackendS3 {
-		if err := downloadBackupFileFromCloud(ctx, server, backup.Path, backupAbsPathLocal); err != nil {
-			return nil, errors.Wrapf(err, "failed to download backup %q from S3", backup.Path)
+	if backup.StorageBackend != api.BackupStorageBackendLocal {
+		client, err := server.getBackupStorage(backup.StorageBackend)
+		if err != nil {
+			return nil, err
+		}
+		if err := downloadBackupFileFromCloud(ctx, client, backup.Path, backupAbsPathLocal); err != nil {
+			return nil, errors.Wrapf(err, "failed to download backup %q from %s", backup.Path, backup.StorageBackend)
 		}
 		defer os.Remove(backupAbsPathLocal)
-		replayBinlogPathList, err := downloadBinlogFilesFromCloud(ctx, server.s3Client, startBinlogInfo, *targetBinlogInfo, binlogDir)
+		replayBinlogPathList, err := downloadBinlogFilesFromCloud(ctx, client, startBinlogInfo, *targetBinlogInfo, binlogDir)
 		if err != nil {
-			return nil, errors.Wrapf(err, "failed to download binlog files from %s to %s from S3", startBinlogInfo.FileName, targetBinlogInfo.FileName)
+			return nil, errors.Wrapf(err, "failed to download binlog files from %s to %s from %s", startBinlogInfo.FileName, targetBinlogInfo.FileName, backup.StorageBackend)
 		}
 		defer func() {
 			for _, binlogPath := range replayBinlogPathList {
@@ -264,10 +274,15 @@ func (exec *PITRRestoreTaskExecutor) doPITRRestore(ctx context.Context, server *
 	if err := exec.updateProgress(ctx, mysqlTargetDriver, backupFile, startBinlogInfo, *targetBinlogInfo, binlogDir); err != nil {
 		return nil, errors.Wrap(err, "failed to setup progress update process")
 	}
+	backupReader, err := server.newBackupArtifactReader(ctx, backupFile)
+	if err != nil {
+		return nil, err
+	}
+	defer backupReader.Close()
 
 	if payload.DatabaseName != nil {
 		// case 1: PITR to a new database.
-		if err := mysqlTargetDriver.RestoreBackupToDatabase(ctx, backupFile, *payload.DatabaseName); err != nil {
+		if err := mysqlTargetDriver.RestoreBackupToDatabase(ctx, backupReader, *payload.DatabaseName); err != nil {
 			log.Error("failed to restore full backup in the new database",
 				zap.Int("issueID", issue.ID),
 				zap.String("databaseName", *payload.DatabaseName),
@@ -283,7 +298,7 @@ func (exec *PITRRestoreTaskExecutor) doPITRRestore(ctx context.Context, server *
 		}
 	} else {
 		// case 2: in-place PITR.
-		if err := mysqlTargetDriver.RestoreBackupToPITRDatabase(ctx, backupFile, task.Database.Name, issue.CreatedTs); err != nil {
+		if err := mysqlTargetDriver.RestoreBackupToPITRDatabase(ctx, backupReader, task.Database.Name, issue.CreatedTs); err != nil {
 			log.Error("failed to restore full backup in the PITR database",
 				zap.Int("issueID", issue.ID),
 				zap.String("databaseName", task.Database.Name),
@@ -309,7 +324,7 @@ func (exec *PITRRestoreTaskExecutor) doPITRRestore(ctx context.Context, server *
 	}, nil
 }
 
-func downloadBinlogFilesFromCloud(ctx context.Context, client *bbs3.Client, startBinlogInfo, targetBinlogInfo api.BinlogInfo, binlogDir string) ([]string, error) {
+func downloadBinlogFilesFromCloud(ctx context.Context, client storage.Client, startBinlogInfo, targetBinlogInfo api.BinlogInfo, binlogDir string) ([]string, error) {
 	replayBinlogPathList, err := mysql.GetBinlogReplayList(startBinlogInfo, targetBinlogInfo, binlogDir)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to get binlog replay list in directory %s", binlogDir)
@@ -317,7 +332,7 @@ func downloadBinlogFilesFromCloud(ctx context.Context, client *bbs3.Client, star
 	for _, binlogFilePath := range replayBinlogPathList {
 		// Use path.Join to compose a path on cloud which always uses / as the separator.
 		filePathOnCloud := path.Join(common.GetBinlogRelativeDir(binlogDir), filepath.Base(binlogFilePath))
-		if err := client.DownloadFileFromCloud(ctx, binlogFilePath, filePathOnCloud); err != nil {
+		if err := storage.DownloadFileFromCloud(ctx, client, binlogFilePath, filePathOnCloud); err != nil {
 			return nil, errors.Wrapf(err, "failed to download binlog file %s from the cloud storage", binlogFilePath)
 		}
 	}
@@ -325,10 +340,6 @@ func downloadBinlogFilesFromCloud(ctx context.Context, client *bbs3.Client, star
 }
 
 func (*PITRRestoreTaskExecutor) doRestoreInPlacePostgres(ctx context.Context, server *Server, issue *api.Issue, task *api.Task, payload api.TaskDatabasePITRRestorePayload) (*api.TaskRunResultPayload, error) {
-	if payload.BackupID == nil {
-		return nil, errors.Errorf("PITR for Postgres is not implemented")
-	}
-
 	backup, err := server.store.GetBackupByID(ctx, *payload.BackupID)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to find backup with ID %d", *payload.BackupID)
@@ -349,42 +360,201 @@ func (*PITRRestoreTaskExecutor) doRestoreInPlacePostgres(ctx context.Context, se
 	}
 	defer driver.Close(ctx)
 
+	pitrDatabaseName, err := createPostgresPITRDatabase(ctx, driver, task.Database.Name, issue.CreatedTs)
+	if err != nil {
+		return nil, err
+	}
+	backupReader, err := server.newBackupArtifactReader(ctx, backupFile)
+	if err != nil {
+		return nil, err
+	}
+	defer backupReader.Close()
+	if err := driver.Restore(ctx, backupReader); err != nil {
+		return nil, errors.Wrapf(err, "failed to restore backup to the PITR database %q", pitrDatabaseName)
+	}
+	return &api.TaskRunResultPayload{
+		Detail: fmt.Sprintf("Restored backup %q to the temporary PITR database %q", backup.Name, pitrDatabaseName),
+	}, nil
+}
+
+// createPostgresPITRDatabase creates the PITR database with the same owner as the original database, and switches the driver connection to it.
+func createPostgresPITRDatabase(ctx context.Context, driver db.Driver, databaseName string, issueCreatedTs int64) (string, error) {
 	pgDriver, ok := driver.(*pg.Driver)
 	if !ok {
 		log.Error("Failed to cast driver to pg.Driver")
-		return nil, errors.Errorf("[internal] cast driver to pg.Driver failed")
+		return "", errors.Errorf("[internal] cast driver to pg.Driver failed")
+	}
+	if _, err := driver.GetDBConnection(ctx, databaseName); err != nil {
+		return "", errors.Wrapf(err, "failed to switch connection to database %q", databaseName)
 	}
 	originalOwner, err := pgDriver.GetCurrentDatabaseOwner()
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to get the OWNER of database %q", task.Database.Name)
+		return "", errors.Wrapf(err, "failed to get the OWNER of database %q", databaseName)
 	}
 
 	db, err := driver.GetDBConnection(ctx, db.BytebaseDatabase)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to get connection for PostgreSQL")
+		return "", errors.Wrap(err, "failed to get connection for PostgreSQL")
 	}
-	pitrDatabaseName := util.GetPITRDatabaseName(task.Database.Name, issue.CreatedTs)
+	pitrDatabaseName := util.GetPITRDatabaseName(databaseName, issueCreatedTs)
 	// If there's already a PITR database, it means there's a failed trial before this task execution.
 	// We need to clean up the dirty state and start clean for idempotent task execution.
 	if _, err := db.ExecContext(ctx, fmt.Sprintf("DROP DATABASE IF EXISTS %s;", pitrDatabaseName)); err != nil {
-		return nil, errors.Wrapf(err, "failed to drop the dirty PITR database %q left from a former task execution", pitrDatabaseName)
+		return "", errors.Wrapf(err, "failed to drop the dirty PITR database %q left from a former task execution", pitrDatabaseName)
 	}
 	if _, err := db.ExecContext(ctx, fmt.Sprintf("CREATE DATABASE %s WITH OWNER %s;", pitrDatabaseName, originalOwner)); err != nil {
-		return nil, errors.Wrapf(err, "failed to create the PITR database %q", pitrDatabaseName)
+		return "", errors.Wrapf(err, "failed to create the PITR database %q", pitrDatabaseName)
 	}
 	// Switch to the PITR database.
 	// TODO(dragonly): This is a trick, needs refactor.
 	if _, err := driver.GetDBConnection(ctx, pitrDatabaseName); err != nil {
-		return nil, errors.Wrapf(err, "failed to switch connection to database %q", pitrDatabaseName)
+		return "", errors.Wrapf(err, "failed to switch connection to database %q", pitrDatabaseName)
 	}
-	if err := driver.Restore(ctx, backupFile); err != nil {
-		return nil, errors.Wrapf(err, "failed to restore backup to the PITR database %q", pitrDatabaseName)
+	return pitrDatabaseName, nil
+}
+
+// doPITRRestorePostgres recovers a temporary cluster from the latest base backup before the target time and the archived WAL,
+// and then restores the database from the recovered cluster to the new database or the PITR database.
+func (*PITRRestoreTaskExecutor) doPITRRestorePostgres(ctx context.Context, server *Server, task *api.Task, payload api.TaskDatabasePITRRestorePayload) (*api.TaskRunResultPayload, error) {
+	issue, err := getIssueByPipelineID(ctx, server.store, task.PipelineID)
+	if err != nil {
+		return nil, err
+	}
+
+	backupStatus := api.BackupStatusDone
+	backupList, err := server.store.FindBackup(ctx, &api.BackupFind{DatabaseID: task.DatabaseID, Status: &backupStatus})
+	if err != nil {
+		return nil, err
+	}
+	targetTs := *payload.PointInTimeTs
+	backup := findLatestBaseBackup(backupList, targetTs)
+	if backup == nil {
+		return nil, errors.Errorf("no backup with a base backup is taken before %s", time.Unix(targetTs, 0).Format(time.RFC822))
+	}
+	log.Debug("Got latest base backup before or equal to targetTs", zap.Int64("targetTs", targetTs), zap.String("backup", backup.Name))
+
+	sourceDriver, err := server.getAdminDatabaseDriver(ctx, task.Instance, "" /* databaseName */)
+	if err != nil {
+		return nil, err
+	}
+	defer sourceDriver.Close(ctx)
+	pgSourceDriver, ok := sourceDriver.(*pg.Driver)
+	if !ok {
+		log.Error("Failed to cast driver to pg.Driver")
+		return nil, errors.Errorf("[internal] cast driver to pg.Driver failed")
+	}
+
+	// The base backup, the WAL files and the data directory of the recovery cluster are placed in a temporary directory.
+	recoveryDir := filepath.Join(server.profile.DataDir, "backup", "pitr", strconv.Itoa(task.ID))
+	if err := os.RemoveAll(recoveryDir); err != nil {
+		return nil, errors.Wrapf(err, "failed to remove the dirty recovery directory %q left from a former task execution", recoveryDir)
 	}
+	defer func() {
+		if err := os.RemoveAll(recoveryDir); err != nil {
+			log.Warn("Failed to remove the recovery directory after PITR", zap.String("path", recoveryDir), zap.Error(err))
+		}
+	}()
+	walReplayDir := filepath.Join(recoveryDir, "wal")
+	walDir := getBinlogAbsDir(server.profile.DataDir, task.Instance.ID)
+	if err := pg.PrepareWALReplay(ctx, server.backupStorage, walDir, walReplayDir, backup.Payload.WALInfo.StartWALFileName); err != nil {
+		return nil, errors.Wrap(err, "failed to prepare the WAL files to replay")
+	}
+
+	baseBackupPathLocal := filepath.Join(server.profile.DataDir, backup.Payload.WALInfo.BaseBackupPath)
+	if backup.StorageBackend != api.BackupStorageBackendLocal {
+		client, err := server.getBackupStorage(backup.StorageBackend)
+		if err != nil {
+			return nil, err
+		}
+		baseBackupPathLocal = filepath.Join(recoveryDir, filepath.Base(backup.Payload.WALInfo.BaseBackupPath))
+		if err := downloadBackupFileFromCloud(ctx, client, backup.Payload.WALInfo.BaseBackupPath, baseBackupPathLocal); err != nil {
+			return nil, errors.Wrapf(err, "failed to download base backup %q from %s", backup.Payload.WALInfo.BaseBackupPath, backup.StorageBackend)
+		}
+	}
+	baseBackupFile, err := os.Open(baseBackupPathLocal)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open base backup file %q", baseBackupPathLocal)
+	}
+	defer baseBackupFile.Close()
+	baseBackupReader, err := server.newBackupArtifactReader(ctx, baseBackupFile)
+	if err != nil {
+		return nil, err
+	}
+	defer baseBackupReader.Close()
+
+	cluster, err := pgSourceDriver.NewRecoveryCluster(ctx, filepath.Join(recoveryDir, "data"))
+	if err != nil {
+		return nil, err
+	}
+	log.Debug("Start recovering the base backup", zap.String("backup", backup.Name), zap.Int64("targetTs", targetTs))
+	if err := cluster.Recover(ctx, baseBackupReader, walReplayDir, targetTs); err != nil {
+		return nil, errors.Wrapf(err, "failed to recover base backup of %q to %s", backup.Name, time.Unix(targetTs, 0).Format(time.RFC822))
+	}
+	defer func() {
+		if err := cluster.Stop(); err != nil {
+			log.Warn("Failed to stop the recovery cluster after PITR", zap.Error(err))
+		}
+	}()
+
+	targetInstance := task.Instance
+	if payload.TargetInstanceID != nil {
+		if targetInstance, err = server.store.GetInstanceByID(ctx, *payload.TargetInstanceID); err != nil {
+			return nil, err
+		}
+	}
+	targetDriver, err := server.getAdminDatabaseDriver(ctx, targetInstance, "" /* databaseName */)
+	if err != nil {
+		return nil, err
+	}
+	defer targetDriver.Close(ctx)
+
+	var targetDatabaseName string
+	if payload.DatabaseName != nil {
+		// case 1: PITR to a new database.
+		targetDatabaseName = *payload.DatabaseName
+		if _, err := targetDriver.GetDBConnection(ctx, targetDatabaseName); err != nil {
+			return nil, errors.Wrapf(err, "failed to switch connection to database %q", targetDatabaseName)
+		}
+	} else {
+		// case 2: in-place PITR.
+		if targetDatabaseName, err = createPostgresPITRDatabase(ctx, targetDriver, task.Database.Name, issue.CreatedTs); err != nil {
+			return nil, err
+		}
+	}
+
+	dumpReader, dumpWriter := io.Pipe()
+	defer dumpReader.Close()
+	go func() {
+		dumpWriter.CloseWithError(cluster.Dump(ctx, task.Database.Name, dumpWriter))
+	}()
+	if err := targetDriver.Restore(ctx, dumpReader); err != nil {
+		log.Error("failed to restore the recovered database",
+			zap.Int("issueID", issue.ID),
+			zap.String("databaseName", targetDatabaseName),
+			zap.Error(err))
+		return nil, errors.Wrapf(err, "failed to restore the recovered database to %q", targetDatabaseName)
+	}
+
+	log.Info("PITR restore success", zap.String("target database", targetDatabaseName))
 	return &api.TaskRunResultPayload{
-		Detail: fmt.Sprintf("Restored backup %q to the temporary PITR database %q", backup.Name, pitrDatabaseName),
+		Detail: fmt.Sprintf("PITR restore success for target database %q", targetDatabaseName),
 	}, nil
 }
 
+// findLatestBaseBackup returns the latest backup with a PostgreSQL base backup which is done before or at targetTs.
+func findLatestBaseBackup(backupList []*api.Backup, targetTs int64) *api.Backup {
+	var latest *api.Backup
+	for _, backup := range backupList {
+		if backup.Payload.WALInfo == nil || backup.UpdatedTs > targetTs {
+			continue
+		}
+		if latest == nil || backup.UpdatedTs > latest.UpdatedTs {
+			latest = backup
+		}
+	}
+	return latest
+}
+
 func (exec *PITRRestoreTaskExecutor) updateProgress(ctx context.Context, driver *mysql.Driver, backupFile *os.File, startBinlogInfo, targetBinlogInfo api.BinlogInfo, binlogDir string) error {
 	backupFileInfo, err := backupFile.Stat()
 	if err != nil {
@@ -452,9 +622,13 @@ func (*PITRRestoreTaskExecutor) restoreDatabase(ctx context.Context, server *Ser
 
 	backupAbsPathLocal := filepath.Join(server.profile.DataDir, backup.Path)
 
-	if backup.StorageBackend == api.BackupStorageBackendS3 {
-		if err := downloadBackupFileFromCloud(ctx, server, backup.Path, backupAbsPathLocal); err != nil {
-			return errors.Wrapf(err, "failed to download backup %q from S3", backup.Path)
+	if backup.StorageBackend != api.BackupStorageBackendLocal {
+		client, err := server.getBackupStorage(backup.StorageBackend)
+		if err != nil {
+			return err
+		}
+		if err := downloadBackupFileFromCloud(ctx, client, backup.Path, backupAbsPathLocal); err != nil {
+			return errors.Wrapf(err, "failed to download backup %q from %s", backup.Path, backup.StorageBackend)
 		}
 		defer os.Remove(backupAbsPathLocal)
 	}
@@ -464,25 +638,30 @@ func (*PITRRestoreTaskExecutor) restoreDatabase(ctx context.Context, server *Ser
 		return errors.Wrapf(err, "failed to open backup file at %s", backupAbsPathLocal)
 	}
 	defer backupFileLocal.Close()
+	backupReader, err := server.newBackupArtifactReader(ctx, backupFileLocal)
+	if err != nil {
+		return err
+	}
+	defer backupReader.Close()
 
-	if err := driver.Restore(ctx, backupFileLocal); err != nil {
+	if err := driver.Restore(ctx, backupReader); err != nil {
 		return errors.Wrap(err, "failed to restore backup")
 	}
 
 	return nil
 }
 
-func downloadBackupFileFromCloud(ctx context.Context, server *Server, backupPath, backupAbsPathLocal string) error {
-	log.Debug("Downloading backup file from s3 bucket.", zap.String("path", backupPath))
+func downloadBackupFileFromCloud(ctx context.Context, client storage.Client, backupPath, backupAbsPathLocal string) error {
+	log.Debug("Downloading backup file from the cloud storage.", zap.String("path", backupPath))
 	backupFileDownload, err := os.Create(backupAbsPathLocal)
 	if err != nil {
-		return errors.Wrapf(err, "failed to create local backup file %q for downloading from s3 bucket", backupAbsPathLocal)
+		return errors.Wrapf(err, "failed to create local backup file %q for downloading from the cloud storage", backupAbsPathLocal)
 	}
 	defer backupFileDownload.Close()
-	if _, err := server.s3Client.DownloadObject(ctx, backupPath, backupFileDownload); err != nil {
-		return errors.Wrapf(err, "failed to download backup file %q from s3 bucket", backupPath)
+	if _, err := client.DownloadObject(ctx, filepath.ToSlash(backupPath), backupFileDownload); err != nil {
+		return errors.Wrapf(err, "failed to download backup file %q from the cloud storage", backupPath)
 	}
-	log.Debug("Successfully downloaded backup file from s3 bucket.")
+	log.Debug("Successfully downloaded backup file from the cloud storage.")
 	return nil
 }
 
@@ -17,6 +17,7 @@ import (
 	"github.com/bytebase/bytebase/common"
 	"github.com/bytebase/bytebase/common/log"
 	"github.com/bytebase/bytebase/plugin/db"
+	"github.com/bytebase/bytebase/plugin/db/pg"
 	"github.com/bytebase/bytebase/plugin/db/util"
 	vcsPlugin "github.com/bytebase/bytebase/plugin/vcs"
 )
@@ -52,6 +53,11 @@ func (exec *SchemaUpdateGhostCutoverTaskExecutor) RunOnce(ctx context.Context, s
 		return true, nil, errors.Wrap(err, "invalid database schema update gh-ost sync payload")
 	}
 
+	// The shadow table of Postgres is kept in sync by the trigger in the database, so there's no state shared by the sync task.
+	if task.Instance.Engine == db.Postgres {
+		return cutover(ctx, server, task, payload.Statement, payload.SchemaVersion, payload.VCSPushEvent, &postgresTableSwitcher{})
+	}
+
 	tableName, err := getTableNameFromStatement(payload.Statement)
 	if err != nil {
 		return true, nil, errors.Wrap(err, "failed to parse table name from statement")
@@ -65,10 +71,67 @@ func (exec *SchemaUpdateGhostCutoverTaskExecutor) RunOnce(ctx context.Context, s
 	}
 	sharedGhost := value.(sharedGhostState)
 
-	return cutover(ctx, server, task, payload.Statement, payload.SchemaVersion, payload.VCSPushEvent, postponeFilename, sharedGhost.migrationContext, sharedGhost.errCh)
+	return cutover(ctx, server, task, payload.Statement, payload.SchemaVersion, payload.VCSPushEvent, &ghostTableSwitcher{
+		postponeFilename: postponeFilename,
+		migrationContext: sharedGhost.migrationContext,
+		errCh:            sharedGhost.errCh,
+	})
+}
+
+// tableSwitcher switches the original table and the shadow table in the cutover.
+type tableSwitcher interface {
+	// waitForCutover waits until the shadow table is ready for the cutover, and returns true if it's cancelled.
+	waitForCutover(ctx context.Context) bool
+	// switchTable switches the original table and the shadow table.
+	switchTable(ctx context.Context, driver db.Driver, database string, statement string) error
+}
+
+// ghostTableSwitcher switches the tables by removing the postpone flag file of the running gh-ost migration.
+type ghostTableSwitcher struct {
+	postponeFilename string
+	migrationContext *base.MigrationContext
+	errCh            <-chan error
+}
+
+func (s *ghostTableSwitcher) waitForCutover(ctx context.Context) bool {
+	return waitForCutover(ctx, s.migrationContext)
+}
+
+func (s *ghostTableSwitcher) switchTable(context.Context, db.Driver, string, string) error {
+	if err := os.Remove(s.postponeFilename); err != nil {
+		return errors.Wrap(err, "failed to remove postpone flag file")
+	}
+	if migrationErr := <-s.errCh; migrationErr != nil {
+		return errors.Wrapf(migrationErr, "failed to run gh-ost migration")
+	}
+	return nil
+}
+
+// postgresTableSwitcher switches the tables of the Postgres online schema change.
+type postgresTableSwitcher struct {
+}
+
+// waitForCutover returns immediately because the trigger syncs the change in the same transaction, and there's no lag.
+func (*postgresTableSwitcher) waitForCutover(context.Context) bool {
+	return false
 }
 
-func cutover(ctx context.Context, server *Server, task *api.Task, statement, schemaVersion string, vcsPushEvent *vcsPlugin.PushEvent, postponeFilename string, migrationContext *base.MigrationContext, errCh <-chan error) (terminated bool, result *api.TaskRunResultPayload, err error) {
+func (*postgresTableSwitcher) switchTable(ctx context.Context, driver db.Driver, database string, statement string) error {
+	pgDriver, ok := driver.(*pg.Driver)
+	if !ok {
+		return errors.Errorf("failed to cast driver to pg.Driver")
+	}
+	osc, err := pg.NewOnlineSchemaChange(pgDriver, database, statement)
+	if err != nil {
+		return err
+	}
+	if err := osc.Cutover(ctx); err != nil {
+		return errors.Wrap(err, "failed to cut over the online schema change")
+	}
+	return nil
+}
+
+func cutover(ctx context.Context, server *Server, task *api.Task, statement, schemaVersion string, vcsPushEvent *vcsPlugin.PushEvent, switcher tableSwitcher) (terminated bool, result *api.TaskRunResultPayload, err error) {
 	statement = strings.TrimSpace(statement)
 
 	mi, err := preMigration(ctx, server, task, db.Migrate, statement, schemaVersion, vcsPushEvent)
@@ -98,7 +161,7 @@ func cutover(ctx context.Context, server *Server, task *api.Task, statement, sch
 
 		// wait for heartbeat lag.
 		// try to make the time gap between the migration history insertion and the actual cutover as close as possible.
-		cancelled := waitForCutover(ctx, migrationContext)
+		cancelled := switcher.waitForCutover(ctx)
 		if cancelled {
 			return -1, "", errors.Errorf("cutover poller cancelled")
 		}
@@ -121,12 +184,8 @@ func cutover(ctx context.Context, server *Server, task *api.Task, statement, sch
 			}
 		}()
 
-		if err := os.Remove(postponeFilename); err != nil {
-			return -1, "", errors.Wrap(err, "failed to remove postpone flag file")
-		}
-
-		if migrationErr := <-errCh; migrationErr != nil {
-			return -1, "", errors.Wrapf(migrationErr, "failed to run gh-ost migration")
+		if err := switcher.switchTable(ctx, driver, mi.Database, statement); err != nil {
+			return -1, "", err
 		}
 
 		var afterSchemaBuf bytes.Buffer
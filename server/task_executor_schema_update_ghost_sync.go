@@ -18,6 +18,8 @@ import (
 	"github.com/bytebase/bytebase/api"
 	"github.com/bytebase/bytebase/common"
 	"github.com/bytebase/bytebase/common/log"
+	"github.com/bytebase/bytebase/plugin/db"
+	"github.com/bytebase/bytebase/plugin/db/pg"
 )
 
 // NewSchemaUpdateGhostSyncTaskExecutor creates a schema update (gh-ost) sync task executor.
@@ -38,6 +40,9 @@ func (exec *SchemaUpdateGhostSyncTaskExecutor) RunOnce(ctx context.Context, serv
 	if err := json.Unmarshal([]byte(task.Payload), payload); err != nil {
 		return true, nil, errors.Wrap(err, "invalid database schema update gh-ost sync payload")
 	}
+	if task.Instance.Engine == db.Postgres {
+		return exec.runPostgresOnlineSchemaChange(ctx, server, task, payload.Statement)
+	}
 	return exec.runGhostMigration(ctx, server, task, payload.Statement)
 }
 
@@ -295,3 +300,51 @@ func (exec *SchemaUpdateGhostSyncTaskExecutor) runGhostMigration(ctx context.Con
 		return true, nil, errors.New("task canceled")
 	}
 }
+
+// runPostgresOnlineSchemaChange syncs the shadow table of the Postgres online schema change.
+// The trigger keeps the shadow table in sync after the task is done, so the cutover is postponed until the cutover task runs.
+func (exec *SchemaUpdateGhostSyncTaskExecutor) runPostgresOnlineSchemaChange(ctx context.Context, server *Server, task *api.Task, statement string) (terminated bool, result *api.TaskRunResultPayload, err error) {
+	driver, err := server.getAdminDatabaseDriver(ctx, task.Instance, task.Database.Name)
+	if err != nil {
+		return true, nil, err
+	}
+	defer driver.Close(ctx)
+	pgDriver, ok := driver.(*pg.Driver)
+	if !ok {
+		return true, nil, errors.Errorf("failed to cast driver to pg.Driver")
+	}
+	osc, err := pg.NewOnlineSchemaChange(pgDriver, task.Database.Name, strings.TrimSpace(statement))
+	if err != nil {
+		return true, nil, err
+	}
+
+	childCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func(childCtx context.Context) {
+		ticker := time.NewTicker(1 * time.Second)
+		defer ticker.Stop()
+		createdTs := time.Now().Unix()
+		for {
+			select {
+			case <-ticker.C:
+				totalUnit, completedUnit := osc.GetProgress()
+				exec.progress.Store(api.Progress{
+					TotalUnit:     totalUnit,
+					CompletedUnit: completedUnit,
+					CreatedTs:     createdTs,
+					UpdatedTs:     time.Now().Unix(),
+				})
+			case <-childCtx.Done():
+				return
+			}
+		}
+	}(childCtx)
+
+	if err := osc.Sync(ctx); err != nil {
+		if ctx.Err() != nil {
+			return true, nil, errors.New("task canceled")
+		}
+		return true, nil, err
+	}
+	return true, &api.TaskRunResultPayload{Detail: "sync done"}, nil
+}
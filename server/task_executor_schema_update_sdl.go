@@ -73,13 +73,17 @@ func (s *Server) computeDatabaseSchemaDiff(ctx context.Context, database *api.Da
 		engine = parser.Postgres
 	case db.MySQL:
 		engine = parser.MySQL
+	case db.TiDB:
+		engine = parser.TiDB
+	case db.ClickHouse:
+		engine = parser.ClickHouse
 	default:
 		return "", errors.Errorf("unsupported database engine %q", database.Instance.Engine)
 	}
 
 	diff, err := differ.SchemaDiff(engine, schema.String(), newSchemaStr)
 	if err != nil {
-		return "", errors.New("compute schema diff")
+		return "", errors.Wrap(err, "compute schema diff")
 	}
 	return diff, nil
 }
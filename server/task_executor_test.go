@@ -0,0 +1,82 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/plugin/db"
+)
+
+func TestBuildChangelogEntry(t *testing.T) {
+	history := &db.MigrationHistory{
+		Creator:             "Alice",
+		CreatedTs:           1666828800,
+		Type:                db.Migrate,
+		Version:             "0002",
+		Statement:           "CREATE TABLE t(id INT);",
+		ExecutionDurationNs: 1234567890,
+	}
+	issue := &api.Issue{ID: 101, Name: "[db] Alter schema"}
+	want := "## 0002\n\n" +
+		"- Type: MIGRATE\n" +
+		"- Environment: Prod\n" +
+		"- Issue: [#101 [db] Alter schema](https://bytebase.example.com/issue/db-alter-schema-101)\n" +
+		"- Author: Alice\n" +
+		"- Applied at: 2022-10-27T00:00:00Z\n" +
+		"- Duration: 1.235s\n" +
+		"- Statement digest: `sha256:0d3da698092ce12216f7063c680c19d408aa1aac06c00a1d1820eb5abaf2bf6e`\n"
+	got := buildChangelogEntry(history, "Prod", issue, "https://bytebase.example.com/issue/db-alter-schema-101")
+	assert.Equal(t, want, got)
+}
+
+func TestInsertChangelogEntry(t *testing.T) {
+	entry := "## 0002\n\n- Author: Alice\n"
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "new changelog",
+			content: "",
+			want:    "# Changelog of db\n\nTHIS FILE IS AUTO-GENERATED BY BYTEBASE\n\n## 0002\n\n- Author: Alice\n",
+		},
+		{
+			name:    "existing entries",
+			content: "# Changelog of db\n\nTHIS FILE IS AUTO-GENERATED BY BYTEBASE\n\n## 0001\n\n- Author: Bob\n",
+			want:    "# Changelog of db\n\nTHIS FILE IS AUTO-GENERATED BY BYTEBASE\n\n## 0002\n\n- Author: Alice\n\n## 0001\n\n- Author: Bob\n",
+		},
+		{
+			name:    "entries without title",
+			content: "## 0001\n\n- Author: Bob\n",
+			want:    "## 0002\n\n- Author: Alice\n\n## 0001\n\n- Author: Bob\n",
+		},
+		{
+			name:    "title only",
+			content: "# Changelog",
+			want:    "# Changelog\n\n## 0002\n\n- Author: Alice\n",
+		},
+	}
+
+	for _, test := range tests {
+		got := insertChangelogEntry(test.content, "db", entry)
+		assert.Equal(t, test.want, got, test.name)
+	}
+}
+
+func TestGetChangelogCommitMessage(t *testing.T) {
+	mi := &db.MigrationInfo{
+		Version:     "0002",
+		Environment: "Prod",
+	}
+	issue := &api.Issue{ID: 101}
+	issueURL := "https://bytebase.example.com/issue/101"
+
+	got := getChangelogCommitMessage("", "db", mi, issue, issueURL)
+	assert.Equal(t, "[Bytebase] Update changelog for \"db\" after migration 0002\n\nTHIS COMMIT IS AUTO-GENERATED BY BYTEBASE\n\nhttps://bytebase.example.com/issue/101", got)
+
+	got = getChangelogCommitMessage("chore({{DB_NAME}}): {{VERSION}} applied to {{ENV_NAME}} by #{{ISSUE_ID}}", "db", mi, issue, issueURL)
+	assert.Equal(t, "chore(db): 0002 applied to Prod by #101", got)
+}
@@ -431,7 +431,15 @@ func (s *TaskScheduler) passAllCheck(ctx context.Context, task *api.Task, allowe
 }
 
 // auto transit PendingApproval to Pending if all required task checks pass.
+// The tasks of a draft issue are never approved until the pull request is merged.
 func (s *TaskScheduler) canAutoApprove(ctx context.Context, task *api.Task) (bool, error) {
+	issue, err := s.server.getDraftIssueByPipelineID(ctx, task.PipelineID)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to fetch containing issue of task %v", task.Name)
+	}
+	if issue != nil {
+		return false, nil
+	}
 	return s.passAllCheck(ctx, task, api.TaskCheckStatusSuccess)
 }
 
@@ -26,6 +26,8 @@ import (
 	advisorDB "github.com/bytebase/bytebase/plugin/advisor/db"
 	"github.com/bytebase/bytebase/plugin/db"
 	"github.com/bytebase/bytebase/plugin/vcs"
+	"github.com/bytebase/bytebase/plugin/vcs/bitbucket"
+	"github.com/bytebase/bytebase/plugin/vcs/gitea"
 	"github.com/bytebase/bytebase/plugin/vcs/github"
 	"github.com/bytebase/bytebase/plugin/vcs/gitlab"
 )
@@ -69,7 +71,24 @@ func (s *Server) registerWebhookRoutes(g *echo.Group) {
 		if err := json.Unmarshal(body, &pushEvent); err != nil {
 			return echo.NewHTTPError(http.StatusBadRequest, "Malformed push event").SetInternal(err)
 		}
-		// This shouldn't happen as we only setup webhook to receive push event, just in case.
+		if pushEvent.ObjectKind == gitlab.WebhookMergeRequest {
+			var mergeRequestEvent gitlab.WebhookMergeRequestEvent
+			if err := json.Unmarshal(body, &mergeRequestEvent); err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, "Malformed merge request event").SetInternal(err)
+			}
+			pullRequestEvent, ok := mergeRequestEvent.ToVCS()
+			if !ok {
+				return c.String(http.StatusOK, "OK")
+			}
+			filter := func(repo *api.Repository) (bool, error) {
+				if c.Request().Header.Get("X-Gitlab-Token") != repo.WebhookSecretToken {
+					return false, nil
+				}
+				return pullRequestEvent.BaseBranch == repo.BranchFilter, nil
+			}
+			return s.respondPullRequestEvent(c, filter, pullRequestEvent)
+		}
+		// This shouldn't happen as we only setup webhook to receive push and merge request events, just in case.
 		if pushEvent.ObjectKind != gitlab.WebhookPush {
 			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid webhook event type, got %s, want push", pushEvent.ObjectKind))
 		}
@@ -106,7 +125,6 @@ func (s *Server) registerWebhookRoutes(g *echo.Group) {
 	g.POST("/github/:id", func(c echo.Context) error {
 		ctx := c.Request().Context()
 
-		// This shouldn't happen as we only setup webhook to receive push event, just in case.
 		eventType := github.WebhookType(c.Request().Header.Get("X-GitHub-Event"))
 		// https://docs.github.com/en/developers/webhooks-and-events/webhooks/about-webhooks#ping-event
 		// When we create a new webhook, GitHub will send us a simple ping event to let us know we've set up the webhook correctly.
@@ -114,7 +132,8 @@ func (s *Server) registerWebhookRoutes(g *echo.Group) {
 		if eventType == github.WebhookPing {
 			return c.String(http.StatusOK, "OK")
 		}
-		if eventType != github.WebhookPush {
+		// This shouldn't happen as we only setup webhook to receive push and pull request events, just in case.
+		if eventType != github.WebhookPush && eventType != github.WebhookPullRequest {
 			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid webhook event type, got %s, want %s", eventType, github.WebhookPush))
 		}
 
@@ -122,6 +141,24 @@ func (s *Server) registerWebhookRoutes(g *echo.Group) {
 		if err != nil {
 			return echo.NewHTTPError(http.StatusBadRequest, "Failed to read webhook request").SetInternal(err)
 		}
+		if eventType == github.WebhookPullRequest {
+			var event github.WebhookPullRequestEvent
+			if err := json.Unmarshal(body, &event); err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, "Malformed pull request event").SetInternal(err)
+			}
+			pullRequestEvent, ok := event.ToVCS()
+			if !ok {
+				return c.String(http.StatusOK, "OK")
+			}
+			filter := func(repo *api.Repository) (bool, error) {
+				ok, err := validateGitHubWebhookSignature256(c.Request().Header.Get("X-Hub-Signature-256"), repo.WebhookSecretToken, body)
+				if err != nil {
+					return false, echo.NewHTTPError(http.StatusInternalServerError, "Failed to validate GitHub webhook signature").SetInternal(err)
+				}
+				return ok && pullRequestEvent.BaseBranch == repo.BranchFilter, nil
+			}
+			return s.respondPullRequestEvent(c, filter, pullRequestEvent)
+		}
 		var pushEvent github.WebhookPushEvent
 		if err := json.Unmarshal(body, &pushEvent); err != nil {
 			return echo.NewHTTPError(http.StatusBadRequest, "Malformed push event").SetInternal(err)
@@ -157,8 +194,174 @@ func (s *Server) registerWebhookRoutes(g *echo.Group) {
 		return c.String(http.StatusOK, strings.Join(createdMessages, "\n"))
 	})
 
+	g.POST("/gitea/:id", func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		// This shouldn't happen as we only setup webhook to receive push and pull request events, just in case.
+		eventType := gitea.WebhookType(c.Request().Header.Get("X-Gitea-Event"))
+		if eventType != gitea.WebhookPush && eventType != gitea.WebhookPullRequest {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid webhook event type, got %s, want %s", eventType, gitea.WebhookPush))
+		}
+
+		body, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Failed to read webhook request").SetInternal(err)
+		}
+		if eventType == gitea.WebhookPullRequest {
+			var event gitea.WebhookPullRequestEvent
+			if err := json.Unmarshal(body, &event); err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, "Malformed pull request event").SetInternal(err)
+			}
+			pullRequestEvent, ok := event.ToVCS()
+			if !ok {
+				return c.String(http.StatusOK, "OK")
+			}
+			filter := func(repo *api.Repository) (bool, error) {
+				ok, err := validateGitHubWebhookSignature256(c.Request().Header.Get("X-Gitea-Signature"), repo.WebhookSecretToken, body)
+				if err != nil {
+					return false, echo.NewHTTPError(http.StatusInternalServerError, "Failed to validate Gitea webhook signature").SetInternal(err)
+				}
+				return ok && pullRequestEvent.BaseBranch == repo.BranchFilter, nil
+			}
+			return s.respondPullRequestEvent(c, filter, pullRequestEvent)
+		}
+		var pushEvent gitea.WebhookPushEvent
+		if err := json.Unmarshal(body, &pushEvent); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Malformed push event").SetInternal(err)
+		}
+		repositoryID := pushEvent.Repository.FullName
+
+		filter := func(repo *api.Repository) (bool, error) {
+			// Gitea signs the payload the same way as GitHub, but without the "sha256=" prefix.
+			ok, err := validateGitHubWebhookSignature256(c.Request().Header.Get("X-Gitea-Signature"), repo.WebhookSecretToken, body)
+			if err != nil {
+				return false, echo.NewHTTPError(http.StatusInternalServerError, "Failed to validate Gitea webhook signature").SetInternal(err)
+			}
+			if !ok {
+				return false, nil
+			}
+
+			return s.isWebhookEventBranch(pushEvent.Ref, repo.BranchFilter)
+		}
+		repositoryList, err := s.filterRepository(ctx, c.Param("id"), repositoryID, filter)
+		if err != nil {
+			return err
+		}
+		if len(repositoryList) == 0 {
+			log.Debug("Empty handle repo list. Ignore this push event.")
+			return c.String(http.StatusOK, "OK")
+		}
+
+		baseVCSPushEvent := pushEvent.ToVCS()
+
+		createdMessages, err := s.processPushEvent(ctx, repositoryList, baseVCSPushEvent)
+		if err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, strings.Join(createdMessages, "\n"))
+	})
+
+	g.POST("/bitbucket/:id", func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		eventType := bitbucket.WebhookType(c.Request().Header.Get("X-Event-Key"))
+		// Bitbucket Server sends a ping event when the user tests the connection of the webhook.
+		if eventType == bitbucket.WebhookPing {
+			return c.String(http.StatusOK, "OK")
+		}
+		body, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Failed to read webhook request").SetInternal(err)
+		}
+		switch eventType {
+		case bitbucket.WebhookPullRequestOpened, bitbucket.WebhookPullRequestFromRefUpdated, bitbucket.WebhookPullRequestMerged, bitbucket.WebhookPullRequestDeclined:
+			var event bitbucket.WebhookPullRequestEvent
+			if err := json.Unmarshal(body, &event); err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, "Malformed pull request event").SetInternal(err)
+			}
+			pullRequestEvent, ok := event.ToVCS()
+			if !ok {
+				return c.String(http.StatusOK, "OK")
+			}
+			filter := func(repo *api.Repository) (bool, error) {
+				ok, err := validateGitHubWebhookSignature256(c.Request().Header.Get("X-Hub-Signature"), repo.WebhookSecretToken, body)
+				if err != nil {
+					return false, echo.NewHTTPError(http.StatusInternalServerError, "Failed to validate Bitbucket webhook signature").SetInternal(err)
+				}
+				return ok && pullRequestEvent.BaseBranch == repo.BranchFilter, nil
+			}
+			return s.respondPullRequestEvent(c, filter, pullRequestEvent)
+		}
+		// This shouldn't happen as we only setup webhook to receive push and pull request events, just in case.
+		if eventType != bitbucket.WebhookPush {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid webhook event type, got %s, want %s", eventType, bitbucket.WebhookPush))
+		}
+		var pushEvent bitbucket.WebhookPushEvent
+		if err := json.Unmarshal(body, &pushEvent); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Malformed push event").SetInternal(err)
+		}
+		repositoryID := fmt.Sprintf("%s/%s", pushEvent.Repository.Project.Key, pushEvent.Repository.Slug)
+
+		var createdMessages []string
+		// A single push may change multiple refs, e.g. "git push --all".
+		for _, change := range pushEvent.Changes {
+			if change.Ref.Type != "BRANCH" || change.Type == bitbucket.WebhookRefChangeDelete {
+				continue
+			}
+
+			filter := func(repo *api.Repository) (bool, error) {
+				ok, err := validateGitHubWebhookSignature256(c.Request().Header.Get("X-Hub-Signature"), repo.WebhookSecretToken, body)
+				if err != nil {
+					return false, echo.NewHTTPError(http.StatusInternalServerError, "Failed to validate Bitbucket webhook signature").SetInternal(err)
+				}
+				if !ok {
+					return false, nil
+				}
+
+				return s.isWebhookEventBranch(change.RefID, repo.BranchFilter)
+			}
+			repositoryList, err := s.filterRepository(ctx, c.Param("id"), repositoryID, filter)
+			if err != nil {
+				return err
+			}
+			if len(repositoryList) == 0 {
+				log.Debug("Empty handle repo list. Ignore this ref change.", zap.String("ref", change.RefID))
+				continue
+			}
+
+			// The push event does not carry the commits, thus we fetch them with
+			// the OAuth context of the first matched repository.
+			repo := repositoryList[0]
+			baseVCSPushEvent, err := pushEvent.ToVCS(
+				ctx,
+				common.OauthContext{
+					ClientID:     repo.VCS.ApplicationID,
+					ClientSecret: repo.VCS.Secret,
+					AccessToken:  repo.AccessToken,
+					RefreshToken: repo.RefreshToken,
+					Refresher:    s.refreshToken(ctx, repo.WebURL),
+				},
+				repo.VCS.InstanceURL,
+				change,
+			)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, "Failed to convert Bitbucket commits").SetInternal(err)
+			}
+
+			messages, err := s.processPushEvent(ctx, repositoryList, baseVCSPushEvent)
+			if err != nil {
+				return err
+			}
+			createdMessages = append(createdMessages, messages...)
+		}
+		if len(createdMessages) == 0 {
+			return c.String(http.StatusOK, "OK")
+		}
+		return c.String(http.StatusOK, strings.Join(createdMessages, "\n"))
+	})
+
 	// id is the webhookEndpointID in repository
-	// This endpoint is generated and injected into GitHub action & GitLab CI during the VCS setup.
+	// This endpoint is generated and injected into GitHub action, GitLab CI, Gitea action & Jenkins pipeline during the VCS setup.
 	g.POST("/sql-review/:id", func(c echo.Context) error {
 		body, err := io.ReadAll(c.Request().Body)
 		if err != nil {
@@ -263,7 +466,9 @@ func (s *Server) registerWebhookRoutes(g *echo.Group) {
 
 		response := &vcsSQLReviewResult{}
 		switch repo.VCS.Type {
-		case vcs.GitHubCom:
+		case vcs.GitHubCom, vcs.GiteaSelfHost, vcs.BitbucketServer:
+			// Gitea actions are compatible with the GitHub action workflow commands,
+			// and the Jenkins pipeline for Bitbucket Server prints them as-is.
 			response = convertSQLAdiceToGitHubActionResult(sqlCheckAdvice)
 		case vcs.GitLabSelfHost:
 			response = convertSQLAdviceToGitLabCIResult(sqlCheckAdvice)
@@ -464,6 +669,24 @@ func parseBranchNameFromRefs(ref string) (string, error) {
 }
 
 func (s *Server) processPushEvent(ctx context.Context, repositoryList []*api.Repository, baseVCSPushEvent vcs.PushEvent) ([]string, error) {
+	// The issues of the repository with the pull request workflow enabled are
+	// created from the pull requests and promoted once merged, thus the push
+	// events to the branch are ignored to avoid creating duplicate issues.
+	if s.flight(api.FeatureVCSPullRequestWorkflow) && s.feature(api.FeatureVCSPullRequestWorkflow) {
+		var filteredRepositoryList []*api.Repository
+		for _, repo := range repositoryList {
+			if repo.EnablePullRequestWorkflow {
+				log.Debug("Skip repository as the pull request workflow is enabled", zap.Int("repository_id", repo.ID))
+				continue
+			}
+			filteredRepositoryList = append(filteredRepositoryList, repo)
+		}
+		if len(filteredRepositoryList) == 0 {
+			return nil, nil
+		}
+		repositoryList = filteredRepositoryList
+	}
+
 	distinctFileList := baseVCSPushEvent.GetDistinctFileList()
 	if len(distinctFileList) == 0 {
 		var commitIDs []string
@@ -495,6 +718,7 @@ func (s *Server) processPushEvent(ctx context.Context, repositoryList []*api.Rep
 				pushEvent,
 				repository,
 				fileInfoListSorted,
+				nil, /* pullRequest */
 			)
 			if err != nil {
 				return nil, err
@@ -636,10 +860,11 @@ func getFileInfo(fileItem vcs.DistinctFileItem, repositoryList []*api.Repository
 // processFilesInProject attempts to create new issue(s) according to the repository type.
 // 1. For a state based project, we create one issue per schema file, and one issue for all of the rest migration files (if any).
 // 2. For a migration based project, we create one issue for all of the migration files. All schema files are ignored.
+// If the pullRequest is not nil, the issues are created as drafts of the pull request.
 // It returns "created=true" when new issue(s) has been created,
 // along with the creation message to be presented in the UI. An *echo.HTTPError
 // is returned in case of the error during the process.
-func (s *Server) processFilesInProject(ctx context.Context, pushEvent vcs.PushEvent, repo *api.Repository, fileInfoList []fileInfo) (string, bool, []*api.ActivityCreate, *echo.HTTPError) {
+func (s *Server) processFilesInProject(ctx context.Context, pushEvent vcs.PushEvent, repo *api.Repository, fileInfoList []fileInfo, pullRequest *api.IssueVCSPullRequestPayload) (string, bool, []*api.ActivityCreate, *echo.HTTPError) {
 	if repo.Project.TenantMode == api.TenantModeTenant && !s.feature(api.FeatureMultiTenancy) {
 		return "", false, nil, echo.NewHTTPError(http.StatusForbidden, api.FeatureMultiTenancy.AccessErrorMessage())
 	}
@@ -660,7 +885,7 @@ func (s *Server) processFilesInProject(ctx context.Context, pushEvent vcs.PushEv
 					databaseName := fileInfo.migrationInfo.Database
 					issueName := fmt.Sprintf(issueNameTemplate, databaseName, "Alter schema")
 					issueDescription := fmt.Sprintf("Apply schema diff by file %s", strings.TrimPrefix(fileInfo.item.FileName, repo.BaseDirectory+"/"))
-					if err := s.createIssueFromMigrationDetailList(ctx, issueName, issueDescription, pushEvent, creatorID, repo.ProjectID, migrationDetailListForFile); err != nil {
+					if err := s.createIssueFromMigrationDetailList(ctx, issueName, issueDescription, pushEvent, creatorID, repo.ProjectID, migrationDetailListForFile, pullRequest); err != nil {
 						return "", false, activityCreateList, echo.NewHTTPError(http.StatusInternalServerError, "Failed to create issue").SetInternal(err)
 					}
 					createdIssueList = append(createdIssueList, issueName)
@@ -700,11 +925,14 @@ func (s *Server) processFilesInProject(ctx context.Context, pushEvent vcs.PushEv
 	databaseName := fileInfoList[0].migrationInfo.Database
 	issueName := fmt.Sprintf(issueNameTemplate, databaseName, migrateType)
 	issueDescription := fmt.Sprintf("By VCS files %s", strings.Join(fileNameList, ", "))
-	if err := s.createIssueFromMigrationDetailList(ctx, issueName, issueDescription, pushEvent, creatorID, repo.ProjectID, migrationDetailList); err != nil {
+	if err := s.createIssueFromMigrationDetailList(ctx, issueName, issueDescription, pushEvent, creatorID, repo.ProjectID, migrationDetailList, pullRequest); err != nil {
 		return "", len(createdIssueList) != 0, activityCreateList, echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to create issue %s", issueName)).SetInternal(err)
 	}
 	createdIssueList = append(createdIssueList, issueName)
 
+	if pullRequest != nil {
+		return fmt.Sprintf("Created draft issue %q from pull request %s", strings.Join(createdIssueList, ","), pullRequest.URL), true, activityCreateList, nil
+	}
 	return fmt.Sprintf("Created issue %q from push event", strings.Join(createdIssueList, ",")), true, activityCreateList, nil
 }
 
@@ -719,7 +947,7 @@ func sortFilesBySchemaVersion(fileInfoList []fileInfo) []fileInfo {
 	return ret
 }
 
-func (s *Server) createIssueFromMigrationDetailList(ctx context.Context, issueName, issueDescription string, pushEvent vcs.PushEvent, creatorID, projectID int, migrationDetailList []*api.MigrationDetail) error {
+func (s *Server) createIssueFromMigrationDetailList(ctx context.Context, issueName, issueDescription string, pushEvent vcs.PushEvent, creatorID, projectID int, migrationDetailList []*api.MigrationDetail, pullRequest *api.IssueVCSPullRequestPayload) error {
 	createContext, err := json.Marshal(
 		&api.MigrationContext{
 			VCSPushEvent: &pushEvent,
@@ -745,6 +973,15 @@ func (s *Server) createIssueFromMigrationDetailList(ctx context.Context, issueNa
 		AssigneeID:    api.SystemBotID,
 		CreateContext: string(createContext),
 	}
+	if pullRequest != nil {
+		payload, err := json.Marshal(&api.IssuePayload{
+			VCSPullRequest: pullRequest,
+		})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to marshal issue payload").SetInternal(err)
+		}
+		issueCreate.Payload = string(payload)
+	}
 	issue, err := s.createIssue(ctx, issueCreate, creatorID)
 	if err != nil {
 		errMsg := "Failed to create schema update issue"
@@ -89,7 +89,8 @@ type backupSettingRaw struct {
 	DayOfWeek         int
 	RetentionPeriodTs int
 	// HookURL is the callback url to be requested (using HTTP GET) after a successful backup.
-	HookURL string
+	HookURL      string
+	ScheduleList []api.BackupSchedule
 }
 
 // toBackupSetting creates an instance of BackupSetting based on the backupSettingRaw.
@@ -113,7 +114,8 @@ func (raw *backupSettingRaw) toBackupSetting() *api.BackupSetting {
 		DayOfWeek:         raw.DayOfWeek,
 		RetentionPeriodTs: raw.RetentionPeriodTs,
 		// HookURL is the callback url to be requested (using HTTP GET) after a successful backup.
-		HookURL: raw.HookURL,
+		HookURL:      raw.HookURL,
+		ScheduleList: raw.ScheduleList,
 	}
 }
 
@@ -430,8 +432,11 @@ func (s *Store) validateBackupSettingUpsert(ctx context.Context, upsert *api.Bac
 	if err != nil {
 		return err
 	}
+	if err := api.ValidateBackupScheduleList(upsert.ScheduleList); err != nil {
+		return &common.Error{Code: common.Invalid, Err: err}
+	}
 	// Backup plan policy check for backup setting mutation.
-	if backupPlanPolicy.Schedule != api.BackupPlanPolicyScheduleUnset {
+	if backupPlanPolicy.Schedule != api.BackupPlanPolicyScheduleUnset || len(backupPlanPolicy.CronScheduleList) > 0 {
 		if !upsert.Enabled {
 			return &common.Error{Code: common.Invalid, Err: errors.Errorf("backup setting should not be disabled for backup plan policy schedule %q", backupPlanPolicy.Schedule)}
 		}
@@ -444,6 +449,13 @@ func (s *Store) validateBackupSettingUpsert(ctx context.Context, upsert *api.Bac
 			if upsert.DayOfWeek == -1 {
 				return &common.Error{Code: common.Invalid, Err: errors.Errorf("backup setting DayOfWeek should be set for backup plan policy schedule %q", backupPlanPolicy.Schedule)}
 			}
+		case api.BackupPlanPolicyScheduleHourly:
+			if schedule := api.GetBackupSettingSchedule(upsert.Enabled, upsert.Hour, upsert.DayOfWeek, upsert.ScheduleList); !schedule.Satisfies(backupPlanPolicy.Schedule) {
+				return &common.Error{Code: common.Invalid, Err: errors.Errorf("backup setting should have an hourly schedule for backup plan policy schedule %q", backupPlanPolicy.Schedule)}
+			}
+		}
+		if missingList := backupPlanPolicy.GetMissingCronScheduleList(upsert.ScheduleList); len(missingList) > 0 {
+			return &common.Error{Code: common.Invalid, Err: errors.Errorf("backup setting should have the schedule %q with retention period of at least %d seconds required by the backup plan policy", missingList[0].Cron, missingList[0].RetentionPeriodTs)}
 		}
 	}
 	return nil
@@ -691,7 +703,8 @@ func (s *Store) findBackupSettingRaw(ctx context.Context, find api.BackupSetting
 			bs.hour,
 			bs.day_of_week,
 			bs.retention_period_ts,
-			bs.hook_url
+			bs.hook_url,
+			bs.schedule_list
 		FROM backup_setting AS bs
 		JOIN db on db.id = bs.database_id
 		WHERE `+strings.Join(where, " AND "), args...)
@@ -704,6 +717,7 @@ func (s *Store) findBackupSettingRaw(ctx context.Context, find api.BackupSetting
 	var backupSettingRawList []*backupSettingRaw
 	for rows.Next() {
 		var backupSettingRaw backupSettingRaw
+		var scheduleList []byte
 		if err := rows.Scan(
 			&backupSettingRaw.ID,
 			&backupSettingRaw.CreatorID,
@@ -716,9 +730,13 @@ func (s *Store) findBackupSettingRaw(ctx context.Context, find api.BackupSetting
 			&backupSettingRaw.DayOfWeek,
 			&backupSettingRaw.RetentionPeriodTs,
 			&backupSettingRaw.HookURL,
+			&scheduleList,
 		); err != nil {
 			return nil, FormatError(err)
 		}
+		if err := json.Unmarshal(scheduleList, &backupSettingRaw.ScheduleList); err != nil {
+			return nil, FormatError(err)
+		}
 
 		backupSettingRawList = append(backupSettingRawList, &backupSettingRaw)
 	}
@@ -751,7 +769,8 @@ func (*Store) findBackupSettingImpl(ctx context.Context, tx *Tx, find *api.Backu
 			hour,
 			day_of_week,
 			retention_period_ts,
-			hook_url
+			hook_url,
+			schedule_list
 		FROM backup_setting
 		WHERE `+strings.Join(where, " AND "),
 		args...,
@@ -765,6 +784,7 @@ func (*Store) findBackupSettingImpl(ctx context.Context, tx *Tx, find *api.Backu
 	var backupSettingRawList []*backupSettingRaw
 	for rows.Next() {
 		var backupSettingRaw backupSettingRaw
+		var scheduleList []byte
 		if err := rows.Scan(
 			&backupSettingRaw.ID,
 			&backupSettingRaw.CreatorID,
@@ -777,9 +797,13 @@ func (*Store) findBackupSettingImpl(ctx context.Context, tx *Tx, find *api.Backu
 			&backupSettingRaw.DayOfWeek,
 			&backupSettingRaw.RetentionPeriodTs,
 			&backupSettingRaw.HookURL,
+			&scheduleList,
 		); err != nil {
 			return nil, FormatError(err)
 		}
+		if err := json.Unmarshal(scheduleList, &backupSettingRaw.ScheduleList); err != nil {
+			return nil, FormatError(err)
+		}
 
 		backupSettingRawList = append(backupSettingRawList, &backupSettingRaw)
 	}
@@ -801,18 +825,29 @@ func (*Store) upsertBackupSettingImpl(ctx context.Context, tx *Tx, upsert *api.B
 			hour,
 			day_of_week,
 			retention_period_ts,
-			hook_url
+			hook_url,
+			schedule_list
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		ON CONFLICT(database_id) DO UPDATE SET
 				enabled = EXCLUDED.enabled,
 				hour = EXCLUDED.hour,
 				day_of_week = EXCLUDED.day_of_week,
 				retention_period_ts = EXCLUDED.retention_period_ts,
-				hook_url = EXCLUDED.hook_url
-		RETURNING id, creator_id, created_ts, updater_id, updated_ts, database_id, enabled, hour, day_of_week, retention_period_ts, hook_url
+				hook_url = EXCLUDED.hook_url,
+				schedule_list = EXCLUDED.schedule_list
+		RETURNING id, creator_id, created_ts, updater_id, updated_ts, database_id, enabled, hour, day_of_week, retention_period_ts, hook_url, schedule_list
 	`
+	upsertScheduleList := upsert.ScheduleList
+	if upsertScheduleList == nil {
+		upsertScheduleList = []api.BackupSchedule{}
+	}
+	scheduleListBytes, err := json.Marshal(upsertScheduleList)
+	if err != nil {
+		return nil, FormatError(err)
+	}
 	var backupSettingRaw backupSettingRaw
+	var scheduleList []byte
 	if err := tx.QueryRowContext(ctx, query,
 		upsert.UpdaterID,
 		upsert.UpdaterID,
@@ -822,6 +857,7 @@ func (*Store) upsertBackupSettingImpl(ctx context.Context, tx *Tx, upsert *api.B
 		upsert.DayOfWeek,
 		upsert.RetentionPeriodTs,
 		upsert.HookURL,
+		string(scheduleListBytes),
 	).Scan(
 		&backupSettingRaw.ID,
 		&backupSettingRaw.CreatorID,
@@ -834,12 +870,16 @@ func (*Store) upsertBackupSettingImpl(ctx context.Context, tx *Tx, upsert *api.B
 		&backupSettingRaw.DayOfWeek,
 		&backupSettingRaw.RetentionPeriodTs,
 		&backupSettingRaw.HookURL,
+		&scheduleList,
 	); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, common.FormatDBErrorEmptyRowWithQuery(query)
 		}
 		return nil, FormatError(err)
 	}
+	if err := json.Unmarshal(scheduleList, &backupSettingRaw.ScheduleList); err != nil {
+		return nil, FormatError(err)
+	}
 	return &backupSettingRaw, nil
 }
 
@@ -863,7 +903,8 @@ func (s *Store) findBackupSettingsMatchImpl(ctx context.Context, match *api.Back
 			hour,
 			day_of_week,
 			retention_period_ts,
-			hook_url
+			hook_url,
+			schedule_list
 		FROM backup_setting
 		WHERE
 			enabled = true
@@ -886,6 +927,7 @@ func (s *Store) findBackupSettingsMatchImpl(ctx context.Context, match *api.Back
 	var backupSettingRawList []*backupSettingRaw
 	for rows.Next() {
 		var backupSettingRaw backupSettingRaw
+		var scheduleList []byte
 		if err := rows.Scan(
 			&backupSettingRaw.ID,
 			&backupSettingRaw.CreatorID,
@@ -898,9 +940,13 @@ func (s *Store) findBackupSettingsMatchImpl(ctx context.Context, match *api.Back
 			&backupSettingRaw.DayOfWeek,
 			&backupSettingRaw.RetentionPeriodTs,
 			&backupSettingRaw.HookURL,
+			&scheduleList,
 		); err != nil {
 			return nil, FormatError(err)
 		}
+		if err := json.Unmarshal(scheduleList, &backupSettingRaw.ScheduleList); err != nil {
+			return nil, FormatError(err)
+		}
 
 		backupSettingRawList = append(backupSettingRawList, &backupSettingRaw)
 	}
@@ -309,7 +309,7 @@ func (s *Store) createDatabaseRawTx(ctx context.Context, tx *Tx, create *api.Dat
 	}
 
 	// Enable automatic backup setting based on backup plan policy.
-	if backupPlanPolicy.Schedule != api.BackupPlanPolicyScheduleUnset && databaseRaw.Name != api.AllDatabaseName {
+	if (backupPlanPolicy.Schedule != api.BackupPlanPolicyScheduleUnset || len(backupPlanPolicy.CronScheduleList) > 0) && databaseRaw.Name != api.AllDatabaseName {
 		backupSettingUpsert := &api.BackupSettingUpsert{
 			UpdaterID:         api.SystemBotID,
 			DatabaseID:        databaseRaw.ID,
@@ -318,11 +318,27 @@ func (s *Store) createDatabaseRawTx(ctx context.Context, tx *Tx, create *api.Dat
 			RetentionPeriodTs: 7 * 24 * 3600,
 			HookURL:           "",
 		}
+		for _, schedule := range backupPlanPolicy.CronScheduleList {
+			// Keep the backups for the default retention period if the policy does not require one.
+			if schedule.RetentionPeriodTs == api.BackupRetentionPeriodUnset {
+				schedule.RetentionPeriodTs = backupSettingUpsert.RetentionPeriodTs
+			}
+			backupSettingUpsert.ScheduleList = append(backupSettingUpsert.ScheduleList, schedule)
+		}
 		switch backupPlanPolicy.Schedule {
 		case api.BackupPlanPolicyScheduleDaily:
 			backupSettingUpsert.DayOfWeek = -1
-		case api.BackupPlanPolicyScheduleWeekly:
+		case api.BackupPlanPolicyScheduleWeekly, api.BackupPlanPolicyScheduleMonthly, api.BackupPlanPolicyScheduleUnset:
 			backupSettingUpsert.DayOfWeek = rand.Intn(7)
+		case api.BackupPlanPolicyScheduleHourly:
+			backupSettingUpsert.DayOfWeek = -1
+			// Add an hourly schedule unless the required cron schedules are hourly already.
+			if api.GetBackupSettingSchedule(true, -1, -1, backupSettingUpsert.ScheduleList) != api.BackupPlanPolicyScheduleHourly {
+				backupSettingUpsert.ScheduleList = append(backupSettingUpsert.ScheduleList, api.BackupSchedule{
+					Cron:              fmt.Sprintf("%d * * * *", rand.Intn(60)),
+					RetentionPeriodTs: backupSettingUpsert.RetentionPeriodTs,
+				})
+			}
 		}
 		if _, err := s.upsertBackupSettingImpl(ctx, tx, backupSettingUpsert); err != nil {
 			return nil, err
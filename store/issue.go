@@ -736,6 +736,13 @@ func (*Store) patchIssueImpl(ctx context.Context, tx *Tx, patch *api.IssuePatch)
 		}
 		set, args = append(set, fmt.Sprintf("payload = $%d", len(args)+1)), append(args, payload)
 	}
+	if v := patch.VCSPullRequest; v != nil {
+		pullRequest, err := json.Marshal(v)
+		if err != nil {
+			return nil, FormatError(err)
+		}
+		set, args = append(set, fmt.Sprintf("payload = jsonb_set(payload, '{vcsPullRequest}', $%d)", len(args)+1)), append(args, pullRequest)
+	}
 
 	args = append(args, patch.ID)
 
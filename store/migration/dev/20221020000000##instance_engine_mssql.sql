@@ -0,0 +1,2 @@
+ALTER TABLE instance DROP CONSTRAINT IF EXISTS instance_engine_check;
+ALTER TABLE instance ADD CONSTRAINT instance_engine_check CHECK (engine IN ('MYSQL', 'POSTGRES', 'TIDB', 'CLICKHOUSE', 'SNOWFLAKE', 'SQLITE', 'MSSQL'));
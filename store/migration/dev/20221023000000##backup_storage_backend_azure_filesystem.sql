@@ -0,0 +1,2 @@
+ALTER TABLE backup DROP CONSTRAINT IF EXISTS backup_storage_backend_check;
+ALTER TABLE backup ADD CONSTRAINT backup_storage_backend_check CHECK (storage_backend IN ('LOCAL', 'S3', 'GCS', 'OSS', 'AZURE', 'FILESYSTEM'));
@@ -0,0 +1 @@
+ALTER TABLE backup_setting ADD COLUMN schedule_list JSONB NOT NULL DEFAULT '[]';
@@ -0,0 +1 @@
+ALTER TABLE repository ADD COLUMN IF NOT EXISTS enable_pull_request_workflow BOOLEAN NOT NULL DEFAULT false;
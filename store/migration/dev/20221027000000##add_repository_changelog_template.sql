@@ -0,0 +1,2 @@
+ALTER TABLE repository ADD COLUMN IF NOT EXISTS changelog_path_template TEXT NOT NULL DEFAULT '';
+ALTER TABLE repository ADD COLUMN IF NOT EXISTS changelog_commit_message_template TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,5 @@
+ALTER TABLE project DROP CONSTRAINT IF EXISTS project_role_provider_check;
+ALTER TABLE project ADD CONSTRAINT project_role_provider_check CHECK (role_provider IN ('BYTEBASE', 'GITLAB_SELF_HOST', 'GITHUB_COM', 'GITEA_SELF_HOST', 'BITBUCKET_SERVER', 'LDAP'));
+
+ALTER TABLE project_member DROP CONSTRAINT IF EXISTS project_member_role_provider_check;
+ALTER TABLE project_member ADD CONSTRAINT project_member_role_provider_check CHECK (role_provider IN ('BYTEBASE', 'GITLAB_SELF_HOST', 'GITHUB_COM', 'GITEA_SELF_HOST', 'BITBUCKET_SERVER', 'LDAP'));
@@ -0,0 +1 @@
+ALTER TABLE principal ADD COLUMN IF NOT EXISTS ldap_dn TEXT NOT NULL DEFAULT '';
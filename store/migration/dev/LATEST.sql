@@ -21,7 +21,8 @@ CREATE TABLE principal (
     type TEXT NOT NULL CHECK (type IN ('END_USER', 'SYSTEM_BOT')),
     name TEXT NOT NULL,
     email TEXT NOT NULL,
-    password_hash TEXT NOT NULL
+    password_hash TEXT NOT NULL,
+    ldap_dn TEXT NOT NULL DEFAULT ''
 );
 
 CREATE UNIQUE INDEX idx_principal_unique_email ON principal(email);
@@ -168,7 +169,7 @@ CREATE TABLE project (
     -- db_name_template is only used when a project is in tenant mode.
     -- Empty value means {{DB_NAME}}.
     db_name_template TEXT NOT NULL,
-    role_provider TEXT NOT NULL CHECK (role_provider IN ('BYTEBASE', 'GITLAB_SELF_HOST', 'GITHUB_COM')) DEFAULT 'BYTEBASE',
+    role_provider TEXT NOT NULL CHECK (role_provider IN ('BYTEBASE', 'GITLAB_SELF_HOST', 'GITHUB_COM', 'GITEA_SELF_HOST', 'BITBUCKET_SERVER', 'LDAP')) DEFAULT 'BYTEBASE',
     schema_version_type TEXT NOT NULL CHECK (schema_version_type IN ('TIMESTAMP', 'SEMANTIC')) DEFAULT 'TIMESTAMP',
     schema_change_type TEXT NOT NULL CHECK (schema_change_type IN ('DDL', 'SDL')) DEFAULT 'DDL',
     lgtm_check JSONB NOT NULL DEFAULT '{}'
@@ -220,7 +221,7 @@ CREATE TABLE project_member (
     project_id INTEGER NOT NULL REFERENCES project (id),
     role TEXT NOT NULL CHECK (role IN ('OWNER', 'DEVELOPER')),
     principal_id INTEGER NOT NULL REFERENCES principal (id),
-    role_provider TEXT NOT NULL CHECK (role_provider IN ('BYTEBASE', 'GITLAB_SELF_HOST', 'GITHUB_COM')) DEFAULT 'BYTEBASE',
+    role_provider TEXT NOT NULL CHECK (role_provider IN ('BYTEBASE', 'GITLAB_SELF_HOST', 'GITHUB_COM', 'GITEA_SELF_HOST', 'BITBUCKET_SERVER', 'LDAP')) DEFAULT 'BYTEBASE',
     -- payload is determined by the type of role_provider
     payload JSONB NOT NULL DEFAULT '{}'
 );
@@ -272,7 +273,7 @@ CREATE TABLE instance (
     updated_ts BIGINT NOT NULL DEFAULT extract(epoch from now()),
     environment_id INTEGER NOT NULL REFERENCES environment (id),
     name TEXT NOT NULL,
-    engine TEXT NOT NULL CHECK (engine IN ('MYSQL', 'POSTGRES', 'TIDB', 'CLICKHOUSE', 'SNOWFLAKE', 'SQLITE')),
+    engine TEXT NOT NULL CHECK (engine IN ('MYSQL', 'POSTGRES', 'TIDB', 'CLICKHOUSE', 'SNOWFLAKE', 'SQLITE', 'MSSQL', 'ORACLE', 'MONGODB')),
     engine_version TEXT NOT NULL DEFAULT '',
     host TEXT NOT NULL,
     port TEXT NOT NULL,
@@ -545,7 +546,7 @@ CREATE TABLE backup (
     name TEXT NOT NULL,
     status TEXT NOT NULL CHECK (status IN ('PENDING_CREATE', 'DONE', 'FAILED')),
     type TEXT NOT NULL CHECK (type IN ('MANUAL', 'AUTOMATIC', 'PITR')),
-    storage_backend TEXT NOT NULL CHECK (storage_backend IN ('LOCAL', 'S3', 'GCS', 'OSS')),
+    storage_backend TEXT NOT NULL CHECK (storage_backend IN ('LOCAL', 'S3', 'GCS', 'OSS', 'AZURE', 'FILESYSTEM')),
     migration_history_version TEXT NOT NULL,
     path TEXT NOT NULL,
     comment TEXT NOT NULL DEFAULT '',
@@ -582,7 +583,9 @@ CREATE TABLE backup_setting (
     -- retention_period_ts == 0 means unset retention period and we do not delete any data.
     retention_period_ts INTEGER NOT NULL DEFAULT 0 CHECK (retention_period_ts >= 0),
     -- hook_url is the callback url to be requested after a successful backup.
-    hook_url TEXT NOT NULL
+    hook_url TEXT NOT NULL,
+    -- schedule_list is the additional backup schedules in cron expressions, each with its own retention period.
+    schedule_list JSONB NOT NULL DEFAULT '[]'
 );
 
 CREATE UNIQUE INDEX idx_backup_setting_unique_database_id ON backup_setting(database_id);
@@ -881,7 +884,7 @@ CREATE TABLE vcs (
     updater_id INTEGER NOT NULL REFERENCES principal (id),
     updated_ts BIGINT NOT NULL DEFAULT extract(epoch from now()),
     name TEXT NOT NULL,
-    type TEXT NOT NULL CHECK (type IN ('GITLAB_SELF_HOST', 'GITHUB_COM')),
+    type TEXT NOT NULL CHECK (type IN ('GITLAB_SELF_HOST', 'GITHUB_COM', 'GITEA_SELF_HOST', 'BITBUCKET_SERVER')),
     instance_url TEXT NOT NULL CHECK ((instance_url LIKE 'http://%' OR instance_url LIKE 'https://%') AND instance_url = rtrim(instance_url, '/')),
     api_url TEXT NOT NULL CHECK ((api_url LIKE 'http://%' OR api_url LIKE 'https://%') AND api_url = rtrim(api_url, '/')),
     application_id TEXT NOT NULL,
@@ -931,6 +934,13 @@ CREATE TABLE repository (
     sheet_path_template TEXT NOT NULL DEFAULT '',
     -- If enable the SQL review CI in VCS repository.
     enable_sql_review_ci BOOLEAN NOT NULL DEFAULT false,
+    -- If create draft issues from the pull requests instead of the pushes in VCS repository.
+    enable_pull_request_workflow BOOLEAN NOT NULL DEFAULT false,
+    -- The file path template for storing the changelog auto-generated by Bytebase after migration.
+    -- If empty, then Bytebase won't auto generate it.
+    changelog_path_template TEXT NOT NULL DEFAULT '',
+    -- The commit message template for writing back the changelog.
+    changelog_commit_message_template TEXT NOT NULL DEFAULT '',
     -- Repository id from the corresponding VCS provider.
     -- For GitLab, this is the project id. e.g. 123
     external_id TEXT NOT NULL,
@@ -1095,7 +1105,7 @@ CREATE TABLE sheet (
     name TEXT NOT NULL,
     statement TEXT NOT NULL,
     visibility TEXT NOT NULL CHECK (visibility IN ('PRIVATE', 'PROJECT', 'PUBLIC')) DEFAULT 'PRIVATE',
-    source TEXT NOT NULL CHECK (source IN ('BYTEBASE', 'GITLAB_SELF_HOST', 'GITHUB_COM')) DEFAULT 'BYTEBASE',
+    source TEXT NOT NULL CHECK (source IN ('BYTEBASE', 'GITLAB_SELF_HOST', 'GITHUB_COM', 'GITEA_SELF_HOST', 'BITBUCKET_SERVER')) DEFAULT 'BYTEBASE',
     type TEXT NOT NULL CHECK (type IN ('SQL')) DEFAULT 'SQL',
     payload JSONB NOT NULL DEFAULT '{}'
 );
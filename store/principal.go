@@ -132,6 +132,58 @@ func (s *Store) GetPrincipalByID(ctx context.Context, id int) (*api.Principal, e
 	return principal, nil
 }
 
+// The ldap_dn column only exists in the dev schema until LDAP is released, so it's kept out of the principal queries above.
+
+// GetPrincipalLDAPDN returns the DN of the LDAP user linked to the principal, or empty if the principal is not linked to LDAP.
+func (s *Store) GetPrincipalLDAPDN(ctx context.Context, id int) (string, error) {
+	var dn string
+	if err := s.db.db.QueryRowContext(ctx, `SELECT ldap_dn FROM principal WHERE id = $1`, id).Scan(&dn); err != nil {
+		if err == sql.ErrNoRows {
+			return "", &common.Error{Code: common.NotFound, Err: errors.Errorf("principal ID not found: %d", id)}
+		}
+		return "", FormatError(err)
+	}
+	return dn, nil
+}
+
+// SetPrincipalLDAPDN links the principal to the LDAP user with the DN.
+func (s *Store) SetPrincipalLDAPDN(ctx context.Context, id int, dn string) error {
+	result, err := s.db.db.ExecContext(ctx, `UPDATE principal SET ldap_dn = $1 WHERE id = $2`, dn, id)
+	if err != nil {
+		return FormatError(err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return FormatError(err)
+	}
+	if rows == 0 {
+		return &common.Error{Code: common.NotFound, Err: errors.Errorf("principal ID not found: %d", id)}
+	}
+	return nil
+}
+
+// FindLDAPPrincipalIDList returns the IDs of the principals linked to LDAP users.
+func (s *Store) FindLDAPPrincipalIDList(ctx context.Context) ([]int, error) {
+	rows, err := s.db.db.QueryContext(ctx, `SELECT id FROM principal WHERE ldap_dn != '' ORDER BY id`)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer rows.Close()
+
+	var idList []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, FormatError(err)
+		}
+		idList = append(idList, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, FormatError(err)
+	}
+	return idList, nil
+}
+
 //
 // private functions
 //
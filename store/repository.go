@@ -28,23 +28,26 @@ type repositoryRaw struct {
 	ProjectID int
 
 	// Domain specific fields
-	Name               string
-	FullPath           string
-	WebURL             string
-	BranchFilter       string
-	BaseDirectory      string
-	FilePathTemplate   string
-	SchemaPathTemplate string
-	SheetPathTemplate  string
-	EnableSQLReviewCI  bool
-	ExternalID         string
-	ExternalWebhookID  string
-	WebhookURLHost     string
-	WebhookEndpointID  string
-	WebhookSecretToken string
-	AccessToken        string
-	ExpiresTs          int64
-	RefreshToken       string
+	Name                           string
+	FullPath                       string
+	WebURL                         string
+	BranchFilter                   string
+	BaseDirectory                  string
+	FilePathTemplate               string
+	SchemaPathTemplate             string
+	SheetPathTemplate              string
+	EnableSQLReviewCI              bool
+	EnablePullRequestWorkflow      bool
+	ChangelogPathTemplate          string
+	ChangelogCommitMessageTemplate string
+	ExternalID                     string
+	ExternalWebhookID              string
+	WebhookURLHost                 string
+	WebhookEndpointID              string
+	WebhookSecretToken             string
+	AccessToken                    string
+	ExpiresTs                      int64
+	RefreshToken                   string
 }
 
 // toRepository creates an instance of Repository based on the repositoryRaw.
@@ -61,23 +64,26 @@ func (raw *repositoryRaw) toRepository() *api.Repository {
 		VCSID:     raw.VCSID,
 		ProjectID: raw.ProjectID,
 
-		Name:               raw.Name,
-		FullPath:           raw.FullPath,
-		WebURL:             raw.WebURL,
-		BranchFilter:       raw.BranchFilter,
-		BaseDirectory:      raw.BaseDirectory,
-		FilePathTemplate:   raw.FilePathTemplate,
-		SchemaPathTemplate: raw.SchemaPathTemplate,
-		SheetPathTemplate:  raw.SheetPathTemplate,
-		EnableSQLReviewCI:  raw.EnableSQLReviewCI,
-		ExternalID:         raw.ExternalID,
-		ExternalWebhookID:  raw.ExternalWebhookID,
-		WebhookURLHost:     raw.WebhookURLHost,
-		WebhookEndpointID:  raw.WebhookEndpointID,
-		WebhookSecretToken: raw.WebhookSecretToken,
-		AccessToken:        raw.AccessToken,
-		ExpiresTs:          raw.ExpiresTs,
-		RefreshToken:       raw.RefreshToken,
+		Name:                           raw.Name,
+		FullPath:                       raw.FullPath,
+		WebURL:                         raw.WebURL,
+		BranchFilter:                   raw.BranchFilter,
+		BaseDirectory:                  raw.BaseDirectory,
+		FilePathTemplate:               raw.FilePathTemplate,
+		SchemaPathTemplate:             raw.SchemaPathTemplate,
+		SheetPathTemplate:              raw.SheetPathTemplate,
+		EnableSQLReviewCI:              raw.EnableSQLReviewCI,
+		EnablePullRequestWorkflow:      raw.EnablePullRequestWorkflow,
+		ChangelogPathTemplate:          raw.ChangelogPathTemplate,
+		ChangelogCommitMessageTemplate: raw.ChangelogCommitMessageTemplate,
+		ExternalID:                     raw.ExternalID,
+		ExternalWebhookID:              raw.ExternalWebhookID,
+		WebhookURLHost:                 raw.WebhookURLHost,
+		WebhookEndpointID:              raw.WebhookEndpointID,
+		WebhookSecretToken:             raw.WebhookSecretToken,
+		AccessToken:                    raw.AccessToken,
+		ExpiresTs:                      raw.ExpiresTs,
+		RefreshToken:                   raw.RefreshToken,
 	}
 }
 
@@ -307,6 +313,9 @@ func (s *Store) createRepositoryImpl(ctx context.Context, tx *Tx, create *api.Re
 				schema_path_template,
 				sheet_path_template,
 				enable_sql_review_ci,
+				enable_pull_request_workflow,
+				changelog_path_template,
+				changelog_commit_message_template,
 				external_id,
 				external_webhook_id,
 				webhook_url_host,
@@ -316,8 +325,8 @@ func (s *Store) createRepositoryImpl(ctx context.Context, tx *Tx, create *api.Re
 				expires_ts,
 				refresh_token
 			)
-			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)
-			RETURNING id, creator_id, created_ts, updater_id, updated_ts, vcs_id, project_id, name, full_path, web_url, branch_filter, base_directory, file_path_template, schema_path_template, sheet_path_template, enable_sql_review_ci, external_id, external_webhook_id, webhook_url_host, webhook_endpoint_id, webhook_secret_token, access_token, expires_ts, refresh_token
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24)
+			RETURNING id, creator_id, created_ts, updater_id, updated_ts, vcs_id, project_id, name, full_path, web_url, branch_filter, base_directory, file_path_template, schema_path_template, sheet_path_template, enable_sql_review_ci, enable_pull_request_workflow, changelog_path_template, changelog_commit_message_template, external_id, external_webhook_id, webhook_url_host, webhook_endpoint_id, webhook_secret_token, access_token, expires_ts, refresh_token
 		`
 		if err := tx.QueryRowContext(ctx, query,
 			create.CreatorID,
@@ -333,6 +342,9 @@ func (s *Store) createRepositoryImpl(ctx context.Context, tx *Tx, create *api.Re
 			create.SchemaPathTemplate,
 			create.SheetPathTemplate,
 			create.EnableSQLReviewCI,
+			create.EnablePullRequestWorkflow,
+			create.ChangelogPathTemplate,
+			create.ChangelogCommitMessageTemplate,
 			create.ExternalID,
 			create.ExternalWebhookID,
 			create.WebhookURLHost,
@@ -358,6 +370,9 @@ func (s *Store) createRepositoryImpl(ctx context.Context, tx *Tx, create *api.Re
 			&repository.SchemaPathTemplate,
 			&repository.SheetPathTemplate,
 			&repository.EnableSQLReviewCI,
+			&repository.EnablePullRequestWorkflow,
+			&repository.ChangelogPathTemplate,
+			&repository.ChangelogCommitMessageTemplate,
 			&repository.ExternalID,
 			&repository.ExternalWebhookID,
 			&repository.WebhookURLHost,
@@ -489,6 +504,9 @@ func (s *Store) findRepositoryImpl(ctx context.Context, tx *Tx, find *api.Reposi
 			schema_path_template,
 			sheet_path_template,
 			enable_sql_review_ci,
+			enable_pull_request_workflow,
+			changelog_path_template,
+			changelog_commit_message_template,
 			external_id,
 			external_webhook_id,
 			webhook_url_host,
@@ -527,6 +545,9 @@ func (s *Store) findRepositoryImpl(ctx context.Context, tx *Tx, find *api.Reposi
 				&repository.SchemaPathTemplate,
 				&repository.SheetPathTemplate,
 				&repository.EnableSQLReviewCI,
+				&repository.EnablePullRequestWorkflow,
+				&repository.ChangelogPathTemplate,
+				&repository.ChangelogCommitMessageTemplate,
 				&repository.ExternalID,
 				&repository.ExternalWebhookID,
 				&repository.WebhookURLHost,
@@ -666,6 +687,15 @@ func (s *Store) patchRepositoryImpl(ctx context.Context, tx *Tx, patch *api.Repo
 		if v := patch.EnableSQLReviewCI; v != nil {
 			set, args = append(set, fmt.Sprintf("enable_sql_review_ci = $%d", len(args)+1)), append(args, *v)
 		}
+		if v := patch.EnablePullRequestWorkflow; v != nil {
+			set, args = append(set, fmt.Sprintf("enable_pull_request_workflow = $%d", len(args)+1)), append(args, *v)
+		}
+		if v := patch.ChangelogPathTemplate; v != nil {
+			set, args = append(set, fmt.Sprintf("changelog_path_template = $%d", len(args)+1)), append(args, *v)
+		}
+		if v := patch.ChangelogCommitMessageTemplate; v != nil {
+			set, args = append(set, fmt.Sprintf("changelog_commit_message_template = $%d", len(args)+1)), append(args, *v)
+		}
 
 		var repository repositoryRaw
 		// Execute update query with RETURNING.
@@ -673,7 +703,7 @@ func (s *Store) patchRepositoryImpl(ctx context.Context, tx *Tx, patch *api.Repo
 		UPDATE repository
 		SET `+strings.Join(set, ", ")+`
 		WHERE `+strings.Join(where, " AND ")+`
-		RETURNING id, creator_id, created_ts, updater_id, updated_ts, vcs_id, project_id, name, full_path, web_url, branch_filter, base_directory, file_path_template, schema_path_template, sheet_path_template, enable_sql_review_ci, external_id, external_webhook_id, webhook_url_host, webhook_endpoint_id, webhook_secret_token, access_token, expires_ts, refresh_token
+		RETURNING id, creator_id, created_ts, updater_id, updated_ts, vcs_id, project_id, name, full_path, web_url, branch_filter, base_directory, file_path_template, schema_path_template, sheet_path_template, enable_sql_review_ci, enable_pull_request_workflow, changelog_path_template, changelog_commit_message_template, external_id, external_webhook_id, webhook_url_host, webhook_endpoint_id, webhook_secret_token, access_token, expires_ts, refresh_token
 		`,
 			args...,
 		).Scan(
@@ -693,6 +723,9 @@ func (s *Store) patchRepositoryImpl(ctx context.Context, tx *Tx, patch *api.Repo
 			&repository.SchemaPathTemplate,
 			&repository.SheetPathTemplate,
 			&repository.EnableSQLReviewCI,
+			&repository.EnablePullRequestWorkflow,
+			&repository.ChangelogPathTemplate,
+			&repository.ChangelogCommitMessageTemplate,
 			&repository.ExternalID,
 			&repository.ExternalWebhookID,
 			&repository.WebhookURLHost,
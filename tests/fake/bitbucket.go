@@ -0,0 +1,431 @@
+package fake
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/pkg/errors"
+
+	"github.com/bytebase/bytebase/plugin/vcs/bitbucket"
+)
+
+// Bitbucket is a fake implementation of Bitbucket Server VCS provider.
+type Bitbucket struct {
+	port int
+	echo *echo.Echo
+
+	client *http.Client
+
+	nextWebhookID int
+	nextCommitID  int
+	repositories  map[string]*bitbucketRepositoryData
+}
+
+type bitbucketRepositoryData struct {
+	webhooks []*bitbucket.WebhookCreateOrUpdate
+	// files is a map that the full file path is the key and the file content is the
+	// value.
+	files map[string]string
+	// commits is the list of commits from the oldest to the newest.
+	commits []*bitbucketCommitData
+	// pushedCommits is the number of commits that have been sent out by the
+	// webhook push events.
+	pushedCommits int
+}
+
+type bitbucketCommitData struct {
+	commit  bitbucket.Commit
+	changes []bitbucket.Change
+}
+
+// NewBitbucket creates a new fake implementation of Bitbucket Server VCS provider.
+func NewBitbucket(port int) VCSProvider {
+	e := echo.New()
+	e.Use(middleware.Logger())
+	e.Use(middleware.Recover())
+
+	bb := &Bitbucket{
+		port:          port,
+		echo:          e,
+		client:        &http.Client{},
+		nextWebhookID: 20221025,
+		nextCommitID:  1,
+		repositories:  make(map[string]*bitbucketRepositoryData),
+	}
+
+	g := e.Group("/rest/api/1.0/projects/:key/repos/:slug")
+	g.POST("/webhooks", bb.createRepositoryWebhook)
+	g.GET("/commits", bb.listRepositoryCommits)
+	g.GET("/commits/:commitID", bb.getRepositoryCommit)
+	g.GET("/commits/:commitID/changes", bb.listRepositoryCommitChanges)
+	g.GET("/files/*", bb.listRepositoryFiles)
+	g.GET("/raw/*", bb.readRepositoryFile)
+	g.PUT("/browse/*", bb.commitRepositoryFile)
+	return bb
+}
+
+func (bb *Bitbucket) getRepository(c echo.Context) (*bitbucketRepositoryData, error) {
+	repositoryID := fmt.Sprintf("%s/%s", c.Param("key"), c.Param("slug"))
+	r, ok := bb.repositories[repositoryID]
+	if !ok {
+		return nil, c.String(http.StatusNotFound, fmt.Sprintf("Bitbucket repository %q does not exist", repositoryID))
+	}
+	return r, nil
+}
+
+func (bb *Bitbucket) createRepositoryWebhook(c echo.Context) error {
+	r, err := bb.getRepository(c)
+	if r == nil {
+		return err
+	}
+
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, fmt.Sprintf("failed to read request body for creating repository webhook: %v", err))
+	}
+
+	var webhookCreate bitbucket.WebhookCreateOrUpdate
+	if err = json.Unmarshal(body, &webhookCreate); err != nil {
+		return c.String(http.StatusInternalServerError, fmt.Sprintf("failed to unmarshal request body for creating repository webhook: %v", err))
+	}
+	r.webhooks = append(r.webhooks, &webhookCreate)
+
+	buf, err := json.Marshal(bitbucket.WebhookInfo{ID: bb.nextWebhookID})
+	if err != nil {
+		return c.String(http.StatusInternalServerError, fmt.Sprintf("failed to marshal response body for creating repository webhook: %v", err))
+	}
+	bb.nextWebhookID++
+	return c.String(http.StatusCreated, string(buf))
+}
+
+// listRepositoryCommits lists the commits from the newest to the oldest in the
+// range of "since" (exclusive) and "until" (inclusive), optionally only the
+// commits that changed the "path".
+func (bb *Bitbucket) listRepositoryCommits(c echo.Context) error {
+	r, err := bb.getRepository(c)
+	if r == nil {
+		return err
+	}
+
+	until := c.QueryParam("until")
+	since := c.QueryParam("since")
+	filePath := c.QueryParam("path")
+
+	var values []bitbucket.Commit
+	started := until == ""
+	for i := len(r.commits) - 1; i >= 0; i-- {
+		commit := r.commits[i]
+		if commit.commit.ID == since {
+			break
+		}
+		if commit.commit.ID == until {
+			started = true
+		}
+		if !started {
+			continue
+		}
+		if filePath != "" && !commit.changedPath(filePath) {
+			continue
+		}
+		values = append(values, commit.commit)
+	}
+
+	buf, err := json.Marshal(
+		map[string]interface{}{
+			"isLastPage": true,
+			"values":     values,
+		},
+	)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, fmt.Sprintf("failed to marshal response body for listing repository commits: %v", err))
+	}
+	return c.String(http.StatusOK, string(buf))
+}
+
+func (bb *Bitbucket) getRepositoryCommit(c echo.Context) error {
+	r, err := bb.getRepository(c)
+	if r == nil {
+		return err
+	}
+
+	commit := r.findCommit(c.Param("commitID"))
+	if commit == nil {
+		return c.String(http.StatusNotFound, fmt.Sprintf("commit %q not found", c.Param("commitID")))
+	}
+	buf, err := json.Marshal(commit.commit)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, fmt.Sprintf("failed to marshal response body for getting repository commit: %v", err))
+	}
+	return c.String(http.StatusOK, string(buf))
+}
+
+func (bb *Bitbucket) listRepositoryCommitChanges(c echo.Context) error {
+	r, err := bb.getRepository(c)
+	if r == nil {
+		return err
+	}
+
+	commit := r.findCommit(c.Param("commitID"))
+	if commit == nil {
+		return c.String(http.StatusNotFound, fmt.Sprintf("commit %q not found", c.Param("commitID")))
+	}
+	buf, err := json.Marshal(
+		map[string]interface{}{
+			"isLastPage": true,
+			"values":     commit.changes,
+		},
+	)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, fmt.Sprintf("failed to marshal response body for listing repository commit changes: %v", err))
+	}
+	return c.String(http.StatusOK, string(buf))
+}
+
+func (bb *Bitbucket) listRepositoryFiles(c echo.Context) error {
+	r, err := bb.getRepository(c)
+	if r == nil {
+		return err
+	}
+
+	dir, err := url.PathUnescape(c.Param("*"))
+	if err != nil {
+		return c.String(http.StatusBadRequest, fmt.Sprintf("failed to unescape directory %q: %v", c.Param("*"), err))
+	}
+	dir = strings.Trim(dir, "/")
+
+	// Paths in the response are relative to the requested directory.
+	values := []string{}
+	for filePath := range r.files {
+		if dir == "" {
+			values = append(values, filePath)
+		} else if strings.HasPrefix(filePath, dir+"/") {
+			values = append(values, strings.TrimPrefix(filePath, dir+"/"))
+		}
+	}
+	sort.Strings(values)
+
+	buf, err := json.Marshal(
+		map[string]interface{}{
+			"isLastPage": true,
+			"values":     values,
+		},
+	)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, fmt.Sprintf("failed to marshal response body for listing repository files: %v", err))
+	}
+	return c.String(http.StatusOK, string(buf))
+}
+
+func (bb *Bitbucket) readRepositoryFile(c echo.Context) error {
+	r, err := bb.getRepository(c)
+	if r == nil {
+		return err
+	}
+
+	filePath, err := url.PathUnescape(c.Param("*"))
+	if err != nil {
+		return c.String(http.StatusBadRequest, fmt.Sprintf("failed to unescape file path %q: %v", c.Param("*"), err))
+	}
+
+	content, ok := r.files[filePath]
+	if !ok {
+		return c.String(http.StatusNotFound, fmt.Sprintf("file %q not found", filePath))
+	}
+	return c.String(http.StatusOK, content)
+}
+
+func (bb *Bitbucket) commitRepositoryFile(c echo.Context) error {
+	r, err := bb.getRepository(c)
+	if r == nil {
+		return err
+	}
+
+	filePath, err := url.PathUnescape(c.Param("*"))
+	if err != nil {
+		return c.String(http.StatusBadRequest, fmt.Sprintf("failed to unescape file path %q: %v", c.Param("*"), err))
+	}
+
+	_, exists := r.files[filePath]
+	sourceCommitID := c.FormValue("sourceCommitId")
+	if exists && sourceCommitID == "" {
+		return c.String(http.StatusConflict, fmt.Sprintf("file %q already exists", filePath))
+	} else if !exists && sourceCommitID != "" {
+		return c.String(http.StatusNotFound, fmt.Sprintf("file %q not found", filePath))
+	}
+
+	bb.commitFiles(r, c.FormValue("message"), map[string]string{filePath: c.FormValue("content")})
+	return c.String(http.StatusOK, "{}")
+}
+
+// commitFiles saves or overwrites given files in the repository and records a
+// new commit for the changes.
+func (bb *Bitbucket) commitFiles(r *bitbucketRepositoryData, message string, files map[string]string) {
+	commit := &bitbucketCommitData{}
+	commit.commit.ID = fmt.Sprintf("fake_bitbucket_commit_%d", bb.nextCommitID)
+	commit.commit.Message = message
+	commit.commit.Author.Name = "fake_bitbucket_author"
+	commit.commit.AuthorTimestamp = time.Now().UnixMilli()
+	bb.nextCommitID++
+
+	for filePath, content := range files {
+		change := bitbucket.Change{Type: bitbucket.ChangeTypeAdd}
+		if _, ok := r.files[filePath]; ok {
+			change.Type = bitbucket.ChangeTypeModify
+		}
+		change.Path.ToString = filePath
+		commit.changes = append(commit.changes, change)
+		r.files[filePath] = content
+	}
+	r.commits = append(r.commits, commit)
+}
+
+func (r *bitbucketRepositoryData) findCommit(commitID string) *bitbucketCommitData {
+	for _, commit := range r.commits {
+		if commit.commit.ID == commitID {
+			return commit
+		}
+	}
+	return nil
+}
+
+func (c *bitbucketCommitData) changedPath(filePath string) bool {
+	for _, change := range c.changes {
+		if change.Path.ToString == filePath {
+			return true
+		}
+	}
+	return false
+}
+
+// Run starts the Bitbucket Server VCS provider server.
+func (bb *Bitbucket) Run() error {
+	return bb.echo.Start(fmt.Sprintf(":%d", bb.port))
+}
+
+// Close shuts down the Bitbucket Server VCS provider server.
+func (bb *Bitbucket) Close() error {
+	return bb.echo.Close()
+}
+
+// ListenerAddr returns the Bitbucket Server VCS provider server listener address.
+func (bb *Bitbucket) ListenerAddr() net.Addr {
+	return bb.echo.ListenerAddr()
+}
+
+// APIURL returns the Bitbucket Server VCS provider API URL.
+func (*Bitbucket) APIURL(instanceURL string) string {
+	return fmt.Sprintf("%s/rest/api/1.0", instanceURL)
+}
+
+// CreateRepository creates a Bitbucket Server repository with given ID in the
+// form of "{projectKey}/{repositorySlug}".
+func (bb *Bitbucket) CreateRepository(id string) {
+	bb.repositories[id] = &bitbucketRepositoryData{
+		files: make(map[string]string),
+	}
+}
+
+// SendWebhookPush sends out a webhook for a push event for the Bitbucket Server
+// repository using given payload. Because Bitbucket Server push events do not
+// carry commits, the "fromHash" and "toHash" of each change in the payload are
+// filled with the range of commits that have not been pushed yet.
+func (bb *Bitbucket) SendWebhookPush(repositoryID string, payload []byte) error {
+	r, ok := bb.repositories[repositoryID]
+	if !ok {
+		return errors.Errorf("Bitbucket repository %q does not exist", repositoryID)
+	}
+
+	var pushEvent bitbucket.WebhookPushEvent
+	if err := json.Unmarshal(payload, &pushEvent); err != nil {
+		return errors.Wrap(err, "failed to unmarshal payload")
+	}
+	fromHash := strings.Repeat("0", 40)
+	if r.pushedCommits > 0 {
+		fromHash = r.commits[r.pushedCommits-1].commit.ID
+	}
+	toHash := fromHash
+	if len(r.commits) > 0 {
+		toHash = r.commits[len(r.commits)-1].commit.ID
+	}
+	for i := range pushEvent.Changes {
+		pushEvent.Changes[i].FromHash = fromHash
+		pushEvent.Changes[i].ToHash = toHash
+	}
+	r.pushedCommits = len(r.commits)
+
+	payload, err := json.Marshal(pushEvent)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal payload")
+	}
+
+	// Trigger all webhooks
+	for _, webhook := range r.webhooks {
+		req, err := http.NewRequest("POST", webhook.URL, bytes.NewReader(payload))
+		if err != nil {
+			return errors.Wrapf(err, "failed to create a new POST request to %q", webhook.URL)
+		}
+
+		m := hmac.New(sha256.New, []byte(webhook.Configuration.Secret))
+		if _, err := m.Write(payload); err != nil {
+			return errors.Wrap(err, "failed to calculate SHA256 of the webhook secret")
+		}
+		req.Header.Set("X-Hub-Signature", "sha256="+hex.EncodeToString(m.Sum(nil)))
+		req.Header.Set("X-Event-Key", string(bitbucket.WebhookPush))
+
+		resp, err := bb.client.Do(req)
+		if err != nil {
+			return errors.Wrapf(err, "failed to send POST request to %q", webhook.URL)
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return errors.Wrap(err, "failed to read response body")
+		}
+		if resp.StatusCode != http.StatusOK {
+			return errors.Errorf("unexpected response status code %d, body: %s", resp.StatusCode, body)
+		}
+		bb.echo.Logger.Infof("SendWebhookPush response body %s\n", body)
+	}
+	return nil
+}
+
+// AddFiles adds given files to the Bitbucket Server repository in a new commit.
+func (bb *Bitbucket) AddFiles(repositoryID string, files map[string]string) error {
+	r, ok := bb.repositories[repositoryID]
+	if !ok {
+		return errors.Errorf("Bitbucket repository %q does not exist", repositoryID)
+	}
+
+	bb.commitFiles(r, "Add files", files)
+	return nil
+}
+
+// GetFiles returns files with given paths from the Bitbucket Server repository.
+func (bb *Bitbucket) GetFiles(repositoryID string, filePaths ...string) (map[string]string, error) {
+	r, ok := bb.repositories[repositoryID]
+	if !ok {
+		return nil, errors.Errorf("Bitbucket repository %q does not exist", repositoryID)
+	}
+
+	// Get files
+	files := make(map[string]string)
+	for _, path := range filePaths {
+		if content, ok := r.files[path]; ok {
+			files[path] = content
+		}
+	}
+	return files, nil
+}
@@ -16,6 +16,8 @@ import (
 	"github.com/bytebase/bytebase/api"
 	"github.com/bytebase/bytebase/plugin/db"
 	"github.com/bytebase/bytebase/plugin/vcs"
+	"github.com/bytebase/bytebase/plugin/vcs/bitbucket"
+	"github.com/bytebase/bytebase/plugin/vcs/gitea"
 	"github.com/bytebase/bytebase/plugin/vcs/github"
 	"github.com/bytebase/bytebase/plugin/vcs/gitlab"
 	"github.com/bytebase/bytebase/resources/postgres"
@@ -333,6 +335,71 @@ func TestVCS(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:               "Gitea",
+			vcsProviderCreator: fake.NewGitea,
+			vcsType:            vcs.GiteaSelfHost,
+			externalID:         "octocat/Hello-World",
+			repositoryFullPath: "octocat/Hello-World",
+			newWebhookPushEvent: func(added []string, modified []string) interface{} {
+				return gitea.WebhookPushEvent{
+					Ref: "refs/heads/feature/foo",
+					Repository: gitea.WebhookRepository{
+						ID:       211,
+						FullName: "octocat/Hello-World",
+						HTMLURL:  "https://gitea.example.com/octocat/Hello-World",
+					},
+					Sender: gitea.WebhookSender{
+						Login: "fake_gitea_author",
+					},
+					Commits: []gitea.WebhookCommit{
+						{
+							ID:        "fake_gitea_commit_id",
+							Message:   "Fake Gitea commit message",
+							Timestamp: time.Now(),
+							URL:       "https://gitea.example.com/octocat/Hello-World/commit/fake_gitea_commit_id",
+							Author: gitea.WebhookCommitAuthor{
+								Name:  "fake_gitea_author",
+								Email: "fake_gitea_author@localhost",
+							},
+							Added:    added,
+							Modified: modified,
+						},
+					},
+				}
+			},
+		},
+		{
+			name:               "Bitbucket",
+			vcsProviderCreator: fake.NewBitbucket,
+			vcsType:            vcs.BitbucketServer,
+			externalID:         "PRJ/my-repo",
+			repositoryFullPath: "PRJ/my-repo",
+			// The fake Bitbucket Server fills the commit range of the changes, and
+			// the added and modified files are fetched through the API.
+			newWebhookPushEvent: func([]string, []string) interface{} {
+				change := bitbucket.WebhookRefChange{
+					RefID: "refs/heads/feature/foo",
+					Type:  bitbucket.WebhookRefChangeUpdate,
+				}
+				change.Ref.ID = "refs/heads/feature/foo"
+				change.Ref.DisplayID = "feature/foo"
+				change.Ref.Type = "BRANCH"
+				return bitbucket.WebhookPushEvent{
+					EventKey: bitbucket.WebhookPush,
+					Actor: bitbucket.User{
+						Name:        "fake_bitbucket_author",
+						DisplayName: "Fake Bitbucket Author",
+					},
+					Repository: bitbucket.Repository{
+						ID:      211,
+						Slug:    "my-repo",
+						Project: bitbucket.Project{Key: "PRJ"},
+					},
+					Changes: []bitbucket.WebhookRefChange{change},
+				}
+			},
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
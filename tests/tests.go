@@ -122,6 +122,8 @@ func getTestPort(testName string) int {
 		"TestSchemaAndDataUpdate",
 		"TestVCS/GitLab",
 		"TestVCS/GitHub",
+		"TestVCS/Gitea",
+		"TestVCS/Bitbucket",
 		"TestVCS_SDL/GitLab",
 		"TestVCS_SDL/GitHub",
 		"TestWildcardInVCSFilePathTemplate/emptyBaseAndMixAsterisks",